@@ -0,0 +1,194 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/mcp"
+	"github.com/k-sub1995/g/internal/prompt"
+	"github.com/k-sub1995/g/internal/subagent"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// subagentRunner runs custom agents for the task tool. Each run is a fresh
+// conversation with the agent's prompt, limited to the tools it lists.
+type subagentRunner struct {
+	provider     api.Provider
+	project      string
+	model        string // used when the agent does not name one
+	defs         map[string]subagent.Definition
+	opts         tools.RegistryOptions
+	extra        *extraTools
+	mcp          *mcp.Manager
+	config       agent.Config // approval, redaction and audit as in the main loop
+	declarations string       // tool declaration mode unless the agent sets one
+}
+
+func newSubagentRunner(provider api.Provider, project, model string, defs []subagent.Definition, opts tools.RegistryOptions, extra *extraTools, mcpManager *mcp.Manager) *subagentRunner {
+	r := &subagentRunner{provider: provider, project: project, model: model, defs: map[string]subagent.Definition{}, opts: opts, extra: extra, mcp: mcpManager}
+	for _, d := range defs {
+		r.defs[d.Name] = d
+	}
+	return r
+}
+
+// tool returns the task tool for the runner's agents.
+func (r *subagentRunner) tool() *tools.TaskTool {
+	var infos []tools.AgentInfo
+	for _, d := range r.defs {
+		infos = append(infos, tools.AgentInfo{Name: d.Name, Description: d.Description})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return tools.NewTaskTool(infos, r.run)
+}
+
+func (r *subagentRunner) run(ctx context.Context, name, task string) (string, error) {
+	def, ok := r.defs[name]
+	if !ok {
+		return "", fmt.Errorf("unknown agent %q", name)
+	}
+	opts := r.opts
+	opts.IncludeTools = def.Tools
+	registry := tools.NewRegistry(opts)
+	mcpDecls := r.extra.register(registry, def.Tools)
+	decls := append(registry.AllDeclarations(), mcpDecls...)
+
+	model := def.Model
+	if model == "" {
+		model = r.model
+	}
+	cfg := r.config
+	cfg.Streaming = true
+	cfg.History = nil
+	capture := &captureFormatter{agent: name}
+	loop := agent.NewLoop(r.provider, registry, r.mcp, capture, cfg)
+	req := &api.GenerateRequest{
+		Model:        model,
+		Project:      r.project,
+		UserPromptID: fmt.Sprintf("g-%d", time.Now().UnixNano()),
+		Request: api.InnerRequest{
+			SystemInstruction: prompt.BuildSystemInstruction(prompt.Options{WorkDir: opts.WorkDir, Shell: shellName(opts.WindowsShell), Agent: &def}),
+			Contents: []api.Content{{
+				Role:  "user",
+				Parts: []api.Part{{Text: task}},
+			}},
+			Config: api.GenerationConfig{
+				Temperature:     1.0,
+				TopP:            0.95,
+				MaxOutputTokens: api.Model(model).MaxOutputTokens,
+			},
+			Tools: []api.Tool{{FunctionDeclarations: toolDeclarations(nil, cmp.Or(def.Declarations, r.declarations), decls)}},
+		},
+	}
+	fmt.Fprintf(os.Stderr, "\033[2m[%s] started\033[0m\n", name)
+	if err := loop.Run(ctx, req); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(os.Stderr, "\033[2m[%s] done\033[0m\n", name)
+	return strings.TrimSpace(capture.answer.String()), nil
+}
+
+// extraTools are the tools of a run besides the built-in ones: extension
+// and tools.d executables and the tools of the MCP servers.
+type extraTools struct {
+	tools []extraTool
+	mcp   []mcpTool
+}
+
+type extraTool struct {
+	tool tools.Tool
+	from string // where it was declared, for the conflict message
+}
+
+type mcpTool struct {
+	server, tool string
+	decl         api.FunctionDecl // declared as mcp.ToolName(server, tool)
+}
+
+// register adds the tools an agent's tool list names, or all of them when
+// the list is empty, to registry and returns the declarations of the MCP
+// tools among them.
+func (x *extraTools) register(registry *tools.Registry, allow []string) []api.FunctionDecl {
+	if x == nil {
+		return nil
+	}
+	allowed := func(name string) bool { return len(allow) == 0 || slices.Contains(allow, name) }
+	for _, t := range x.tools {
+		if !allowed(t.tool.Name()) {
+			continue
+		}
+		if !registry.Register(t.tool) && debug {
+			fmt.Fprintf(os.Stderr, "%s: tool %q conflicts with an existing tool, skipped\n", t.from, t.tool.Name())
+		}
+	}
+	var decls []api.FunctionDecl
+	for _, t := range x.mcp {
+		if allowed(t.decl.Name) {
+			registry.RegisterMCPTool(t.server, t.decl.Name, t.tool)
+			decls = append(decls, t.decl)
+		}
+	}
+	return decls
+}
+
+// captureFormatter keeps a sub-agent's last reply as its answer and shows
+// its tool use on stderr.
+type captureFormatter struct {
+	agent  string
+	answer strings.Builder
+}
+
+func (f *captureFormatter) WriteResponse(resp *api.GenerateResponse) error {
+	f.answer.Reset()
+	if len(resp.Response.Candidates) > 0 {
+		for _, p := range resp.Response.Candidates[0].Content.Parts {
+			f.answer.WriteString(p.Text)
+		}
+	}
+	return nil
+}
+
+func (f *captureFormatter) WriteStreamEvent(event *api.StreamEvent) error {
+	switch event.Type {
+	case "start":
+		f.answer.Reset()
+	case "content":
+		f.answer.WriteString(event.Text)
+	}
+	return nil
+}
+
+func (f *captureFormatter) WriteError(err error) error {
+	_, werr := fmt.Fprintf(os.Stderr, "[%s] Error: %v\n", f.agent, err)
+	return werr
+}
+
+func (f *captureFormatter) WriteToolCall(name string, args map[string]interface{}) error {
+	_, err := fmt.Fprintf(os.Stderr, "  ⚡ [%s] %s\n", f.agent, name)
+	return err
+}
+
+func (f *captureFormatter) WriteToolResult(name string, result map[string]interface{}, isError bool) error {
+	if isError {
+		if errMsg, ok := result["error"]; ok {
+			_, err := fmt.Fprintf(os.Stderr, "  ✗ [%s] %s: %v\n", f.agent, name, errMsg)
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *captureFormatter) WriteProgress(stage, detail string) error {
+	_, err := fmt.Fprintf(os.Stderr, "  ⏳ [%s] %s\n", f.agent, detail)
+	return err
+}
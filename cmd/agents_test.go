@@ -0,0 +1,49 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+func TestExtraToolsRegister(t *testing.T) {
+	extra := &extraTools{mcp: []mcpTool{
+		{server: "db", tool: "query", decl: api.FunctionDecl{Name: "db__query"}},
+		{server: "db", tool: "drop", decl: api.FunctionDecl{Name: "db__drop"}},
+	}}
+	for _, name := range []string{"lint", "deploy"} {
+		extra.tools = append(extra.tools, extraTool{from: "[ext] test", tool: tools.NewExtensionTool(tools.ExtensionToolSpec{
+			Name:       name,
+			Parameters: json.RawMessage(`{"type":"object"}`),
+			Command:    "true",
+		}, tools.Timeouts{})})
+	}
+
+	for _, tt := range []struct {
+		allow []string
+		want  []string
+	}{
+		{nil, []string{"db__drop", "db__query", "deploy", "lint", "read_file"}},
+		{[]string{"read_file", "lint", "db__query"}, []string{"db__query", "lint", "read_file"}},
+	} {
+		registry := tools.NewRegistry(tools.RegistryOptions{WorkDir: t.TempDir(), IncludeTools: []string{"read_file"}})
+		mcpDecls := extra.register(registry, tt.allow)
+		var got []string
+		for _, d := range append(registry.AllDeclarations(), mcpDecls...) {
+			got = append(got, d.Name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("allow %v declared %v, want %v", tt.allow, got, tt.want)
+		}
+		if _, ok := registry.GetMCPRef("db__drop"); ok != (tt.allow == nil) {
+			t.Errorf("allow %v: db__drop registered = %v", tt.allow, ok)
+		}
+	}
+}
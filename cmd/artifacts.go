@@ -0,0 +1,39 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/k-sub1995/g/internal/artifacts"
+	"github.com/k-sub1995/g/internal/output"
+)
+
+// reportArtifacts lists the files saved in dir (relative to the working
+// directory): on stderr for text output, as an "artifacts" event for
+// stream-json. JSON output carries them in the response instead (see
+// watchArtifacts).
+func reportArtifacts(formatter output.Formatter, dir string) {
+	files := artifacts.List(absDir(dir))
+	if len(files) == 0 {
+		return
+	}
+	if w, ok := formatter.(interface{ WriteArtifacts([]string) error }); ok {
+		w.WriteArtifacts(files)
+	}
+}
+
+// watchArtifacts makes a JSON formatter include the files in dir in its
+// response.
+func watchArtifacts(formatter output.Formatter, dir string) {
+	if jf, ok := formatter.(*output.JSONFormatter); ok {
+		jf.Artifacts = func() []string { return artifacts.List(absDir(dir)) }
+	}
+}
+
+func absDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
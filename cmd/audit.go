@@ -0,0 +1,248 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/audit"
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/k-sub1995/g/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditLimit   int
+	auditJSON    bool
+	auditSession string
+	auditSince   string
+)
+
+var auditCmd = &cobra.Command{
+	Use:     "audit",
+	Aliases: []string{"logs"},
+	Short:   "Inspect the tool execution audit log",
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show recent tool executions",
+	RunE:  runAuditShow,
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the audit log's hash chain for tampering",
+	RunE:  runAuditVerify,
+}
+
+var auditStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize tool calls, failures and result sizes per tool",
+	Long: `Summarize the logged tool calls per tool: how often each was called,
+failed, was denied or found nothing, and how many tokens its results cost,
+followed by hints on ignore files and prompts for tools that often miss.`,
+	Args: cobra.NoArgs,
+	RunE: runAuditStats,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditShowCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+	auditCmd.AddCommand(auditStatsCmd)
+
+	auditShowCmd.Flags().IntVarP(&auditLimit, "limit", "n", 20, "Number of entries to show (0 for all)")
+	auditShowCmd.Flags().BoolVar(&auditJSON, "json", false, "Print raw JSONL entries")
+	auditStatsCmd.Flags().StringVar(&auditSession, "session", "", "Only count calls of this session")
+	auditStatsCmd.Flags().StringVar(&auditSince, "since", "", "Only count calls newer than this age (e.g. 7d, 12h)")
+	auditStatsCmd.Flags().BoolVar(&auditJSON, "json", false, "Print the statistics as JSON")
+}
+
+// auditPath returns the configured audit log location.
+func auditPath(cfg *config.Config) (string, error) {
+	if cfg != nil && cfg.Security.Audit.Path != "" {
+		path := cfg.Security.Audit.Path
+		if strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			path = filepath.Join(home, path[2:])
+		}
+		return path, nil
+	}
+	return audit.DefaultPath()
+}
+
+// openAuditLog returns the audit log the settings enable, tagging entries
+// with session, or nil when it is disabled.
+func openAuditLog(cfg *config.Config, session string) (*audit.Log, error) {
+	ac := cfg.Security.Audit
+	if ac.Enabled != nil && !*ac.Enabled {
+		return nil, nil
+	}
+	path, err := auditPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	log := audit.Open(path, session)
+	log.MaxSize = ac.MaxSize
+	return log, nil
+}
+
+// newRedactor returns the redactor for tool results and what is logged of
+// them, or nil when security.redaction is turned off.
+func newRedactor(cfg *config.Config) (*redact.Redactor, error) {
+	rc := cfg.Security.Redaction
+	if rc.Enabled != nil && !*rc.Enabled {
+		return nil, nil
+	}
+	return redact.New(rc.Patterns)
+}
+
+func loadAuditPath() (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	return auditPath(cfg)
+}
+
+func runAuditShow(cmd *cobra.Command, args []string) error {
+	path, err := loadAuditPath()
+	if err != nil {
+		return err
+	}
+	entries, err := audit.Read(path)
+	if os.IsNotExist(err) {
+		fmt.Println("No tool executions recorded.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if auditLimit > 0 && len(entries) > auditLimit {
+		entries = entries[len(entries)-auditLimit:]
+	}
+
+	for _, e := range entries {
+		if auditJSON {
+			data, _ := json.Marshal(e)
+			fmt.Println(string(data))
+			continue
+		}
+		status := e.Status
+		if e.ExitCode != nil {
+			status = fmt.Sprintf("%s (exit %d)", status, *e.ExitCode)
+		}
+		fmt.Printf("#%d %s %s %s\n", e.Seq, e.Time.Local().Format("2006-01-02 15:04:05"), e.Tool, status)
+		if len(e.Args) > 0 {
+			fmt.Printf("  args: %s\n", truncateLine(string(e.Args), 200))
+		}
+		fmt.Printf("  cwd: %s  approval: %s  session: %s\n", e.CWD, e.Approval, e.Session)
+		if e.Error != "" {
+			fmt.Printf("  error: %s\n", truncateLine(e.Error, 200))
+		}
+	}
+	return nil
+}
+
+func runAuditVerify(cmd *cobra.Command, args []string) error {
+	path, err := loadAuditPath()
+	if err != nil {
+		return err
+	}
+	n, err := audit.Verify(path)
+	if os.IsNotExist(err) {
+		fmt.Println("No audit log found.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: verification failed after %d valid entries: %w", path, n, err)
+	}
+	fmt.Printf("%s: %d entries, hash chain intact\n", path, n)
+	return nil
+}
+
+func runAuditStats(cmd *cobra.Command, args []string) error {
+	filter := audit.Filter{Session: auditSession}
+	if auditSince != "" {
+		age, err := parseAge(auditSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: %w", auditSince, err)
+		}
+		filter.Since = time.Now().Add(-age)
+	}
+	path, err := loadAuditPath()
+	if err != nil {
+		return err
+	}
+	stats, err := audit.Stats(path, filter)
+	if os.IsNotExist(err) {
+		stats, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+	recs := audit.Recommendations(stats)
+
+	if auditJSON {
+		type toolJSON struct {
+			audit.ToolStats
+			ErrorRate float64 `json:"errorRate"`
+			EmptyRate float64 `json:"emptyRate"`
+			AvgTokens int     `json:"avgTokens"`
+		}
+		out := struct {
+			Tools           []toolJSON `json:"tools"`
+			Recommendations []string   `json:"recommendations"`
+		}{Tools: []toolJSON{}, Recommendations: recs}
+		for _, s := range stats {
+			out.Tools = append(out.Tools, toolJSON{s, s.ErrorRate(), s.EmptyRate(), s.AvgTokens()})
+		}
+		if out.Recommendations == nil {
+			out.Recommendations = []string{}
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(stats) == 0 {
+		fmt.Println("No tool executions recorded.")
+		return nil
+	}
+	fmt.Printf("%-24s %6s %7s %7s %7s %10s %10s\n", "TOOL", "CALLS", "FAILED", "DENIED", "EMPTY", "AVG TOKENS", "TOKENS")
+	for _, s := range stats {
+		fmt.Printf("%-24s %6d %7s %7d %7s %10s %10s\n", s.Tool, s.Calls, percent(s.ErrorRate()), s.Denied, percent(s.EmptyRate()),
+			formatTokens(s.AvgTokens()), formatTokens(s.Tokens))
+	}
+	if len(recs) > 0 {
+		fmt.Println()
+		for _, r := range recs {
+			fmt.Printf("- %s\n", r)
+		}
+	}
+	return nil
+}
+
+func percent(r float64) string {
+	return fmt.Sprintf("%.0f%%", r*100)
+}
+
+func truncateLine(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
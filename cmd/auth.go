@@ -0,0 +1,98 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/k-sub1995/g/internal/auth"
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage stored credentials",
+}
+
+var authKeychainCmd = &cobra.Command{
+	Use:   "keychain",
+	Short: "Move oauth_creds.json into the OS keychain",
+	Long: `Move the OAuth credentials in ~/.gemini/oauth_creds.json into the OS
+keychain (macOS Keychain, or the Secret Service via secret-tool on Linux)
+and delete the plaintext file. g reads and refreshes the token there from
+then on. The Gemini CLI itself will ask you to sign in again unless it also
+uses the keychain.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := auth.NewManager()
+		if err != nil {
+			return err
+		}
+		path, err := mgr.MoveToKeychain()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Credentials moved to the OS keychain; removed %s\n", path)
+		return nil
+	},
+}
+
+var authProjectReset bool
+
+var authProjectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Show or reset the cached Code Assist project",
+	Long: `Show the Google account in use and the Code Assist project cached for it.
+Projects are cached per account. With --reset, the cached project is
+forgotten and looked up again on the next run; use --project or G_PROJECT
+to pick a project explicitly.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, account, err := geminiHTTPClient()
+		if err != nil {
+			return err
+		}
+		state, err := config.LoadCachedState()
+		if err != nil {
+			return err
+		}
+
+		if authProjectReset {
+			if state.ResetAccount(account) {
+				if err := config.SaveCachedState(state); err != nil {
+					return err
+				}
+				fmt.Printf("Forgot the cached project for %s\n", account)
+			} else {
+				fmt.Printf("No project cached for %s\n", account)
+			}
+			return nil
+		}
+
+		fmt.Printf("Account: %s\n", account)
+		cached := state.Account(account)
+		switch {
+		case projectOverride != "":
+			fmt.Printf("Project: %s (from --project or G_PROJECT)\n", projectOverride)
+			if cached.ProjectID != "" {
+				fmt.Printf("Cached:  %s\n", cached.ProjectID)
+			}
+		case cached.ProjectID != "":
+			fmt.Printf("Project: %s (cached)\n", cached.ProjectID)
+		default:
+			fmt.Println("Project: not cached yet; it is looked up on the next run")
+		}
+		if cached.UserTier != "" {
+			fmt.Printf("Tier:    %s\n", cached.UserTier)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authKeychainCmd)
+	authProjectCmd.Flags().BoolVar(&authProjectReset, "reset", false, "Forget the cached project for the current account")
+	authCmd.AddCommand(authProjectCmd)
+}
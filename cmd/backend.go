@@ -0,0 +1,437 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/anthropic"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/auth"
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/k-sub1995/g/internal/dlp"
+	"github.com/k-sub1995/g/internal/memory"
+	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/prompt"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// loadCredentials loads the Gemini CLI credentials, refreshing them if they
+// have expired.
+func loadCredentials() (*auth.Manager, *auth.Credentials, error) {
+	authMgr, err := auth.NewManager()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize auth: %w", err)
+	}
+
+	creds, err := authMgr.LoadCredentials()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Refresh if expired
+	if creds.IsExpired() {
+		if debug {
+			fmt.Fprintln(os.Stderr, "Token expired, refreshing...")
+		}
+		creds, err = authMgr.RefreshToken(creds)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return authMgr, creds, nil
+}
+
+// geminiHTTPClient returns the HTTP client for the Gemini APIs and the
+// account it authenticates as, which keys the cached Code Assist project.
+// The Gemini CLI's OAuth login is used unless the settings select
+// Application Default Credentials ("compute-default-credentials") or there
+// is no login but a service account key or gcloud credentials are
+// available, as in CI.
+func geminiHTTPClient() (*http.Client, string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+	adc := auth.ADCPath()
+	if cfg.Security.Auth.SelectedType == "compute-default-credentials" {
+		if adc == "" {
+			return nil, "", fmt.Errorf("no application default credentials: set %s or run 'gcloud auth application-default login'", auth.ADCEnv)
+		}
+		return adcHTTPClient(adc)
+	}
+
+	authMgr, creds, err := loadCredentials()
+	if err != nil {
+		if adc != "" {
+			if debug {
+				fmt.Fprintf(os.Stderr, "%v; using application default credentials %s\n", err, adc)
+			}
+			return adcHTTPClient(adc)
+		}
+		return nil, "", err
+	}
+	account := authMgr.Account()
+	if account == "" {
+		account = "oauth"
+	}
+	return authMgr.RefreshingHTTPClient(creds), account, nil
+}
+
+func adcHTTPClient(path string) (*http.Client, string, error) {
+	client, err := auth.ADCHTTPClient(path)
+	if err != nil {
+		return nil, "", err
+	}
+	account := auth.ADCAccount(path)
+	if account == "" {
+		account = "adc"
+	}
+	return client, account, nil
+}
+
+// backend is the model provider for a command plus the Gemini services
+// (Code Assist project, web search) when Gemini models are in use.
+type backend struct {
+	provider  api.Provider
+	gemini    *api.Client // nil when only Claude models are used
+	account   string      // the Gemini account, keying the cached project
+	projectID string
+	dlp       *dlp.Filter // outbound content rules; nil when none are configured
+}
+
+// newBackend sets up the providers the given models need without any
+// network calls. Claude models ("claude-*") use ANTHROPIC_API_KEY; other
+// models use the Gemini CLI credentials or Application Default Credentials,
+// whose token is refreshed in the background for as long as the process
+// runs. Requests to either pass the configured data-loss-prevention
+// filter.
+func newBackend(models ...string) (*backend, error) {
+	if offline {
+		return nil, &offlineError{what: "the model API"}
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	filter, err := dlp.New(cfg.Security.DLP)
+	if err != nil {
+		return nil, err
+	}
+	router := &api.Router{}
+	b := &backend{provider: dlp.Wrap(router, filter), dlp: filter}
+
+	needGemini := len(models) == 0
+	for _, m := range models {
+		if !strings.HasPrefix(m, anthropic.ModelPrefix) {
+			needGemini = true
+		}
+	}
+	if c := anthropic.NewFromEnv(); c != nil {
+		router.Route(anthropic.ModelPrefix, c)
+	} else if !needGemini {
+		return nil, fmt.Errorf("ANTHROPIC_API_KEY must be set to use Claude models")
+	}
+
+	if needGemini {
+		httpClient, account, err := geminiHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		b.gemini = api.NewClient(httpClient)
+		b.account = account
+		router.Default = b.gemini
+	}
+	return b, nil
+}
+
+// connect resolves the Code Assist project when Gemini is in use.
+func (b *backend) connect(ctx context.Context) error {
+	if b.gemini == nil || b.projectID != "" {
+		return nil
+	}
+	projectID, err := resolveProjectID(ctx, b.gemini, b.account)
+	if err != nil {
+		return err
+	}
+	b.projectID = projectID
+	return nil
+}
+
+// webSearch returns the google_web_search callback, or nil without Gemini.
+func (b *backend) webSearch(model string) tools.WebSearchFunc {
+	if b.gemini == nil {
+		return nil
+	}
+	if strings.HasPrefix(model, anthropic.ModelPrefix) {
+		model = "" // search always runs on Gemini
+	}
+	search := newWebSearchFunc(b.gemini, b.projectID, model)
+	if b.dlp == nil {
+		return search
+	}
+	return func(ctx context.Context, query string) (string, []tools.WebSource, error) {
+		query, err := b.dlp.Text(ctx, query)
+		if err != nil {
+			return "", nil, err
+		}
+		return search(ctx, query)
+	}
+}
+
+// resolveProjectID returns the Code Assist project: --project or
+// G_PROJECT when set, otherwise the value cached for account, loading and
+// caching it when there is none.
+func resolveProjectID(ctx context.Context, apiClient *api.Client, account string) (string, error) {
+	if projectOverride != "" {
+		if debug {
+			fmt.Fprintf(os.Stderr, "Using Project ID from --project: %s\n", projectOverride)
+		}
+		return projectOverride, nil
+	}
+
+	// Try to load cached project ID first
+	cachedState, err := config.LoadCachedState()
+	if err != nil {
+		cachedState = &config.CachedState{}
+	}
+	legacy := cachedState.ProjectID != ""
+	projectID := cachedState.Account(account).ProjectID
+	if projectID != "" {
+		if legacy {
+			_ = config.SaveCachedState(cachedState)
+		}
+		if debug {
+			fmt.Fprintf(os.Stderr, "Using cached Project ID: %s\n", projectID)
+		}
+		return projectID, nil
+	}
+
+	// If no cached project ID, fetch from API
+	if debug {
+		fmt.Fprintln(os.Stderr, "Loading Code Assist status...")
+	}
+	loadResp, err := apiClient.LoadCodeAssist(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load Code Assist: %w", err)
+	}
+	projectID = loadResp.CloudAICompanionProject
+
+	if projectID == "" {
+		if len(loadResp.IneligibleTiers) > 0 {
+			var reasons []string
+			for _, tier := range loadResp.IneligibleTiers {
+				if tier.ReasonMessage != "" {
+					reasons = append(reasons, tier.ReasonMessage)
+				}
+			}
+			if len(reasons) > 0 {
+				return "", fmt.Errorf("unable to use Gemini: %s", strings.Join(reasons, ", "))
+			}
+		}
+		return "", fmt.Errorf("unable to use Gemini: no project ID available. Please run 'gemini' to set up your account")
+	}
+
+	// Cache the project ID
+	userTier := ""
+	if loadResp.CurrentTier != nil {
+		userTier = loadResp.CurrentTier.ID
+	}
+	cachedState.SetAccount(account, config.AccountState{
+		ProjectID: projectID,
+		UserTier:  userTier,
+	})
+	_ = config.SaveCachedState(cachedState)
+	if debug {
+		fmt.Fprintf(os.Stderr, "Project ID: %s (cached)\n", projectID)
+	}
+	return projectID, nil
+}
+
+// toolLimits returns the tool output limits from settings.
+func toolLimits(cfg *config.Config) tools.Limits {
+	if cfg == nil {
+		return tools.Limits{}
+	}
+	l := cfg.Tools.Limits
+	return tools.Limits{
+		ShellOutputBytes: l.ShellOutputBytes,
+		ReadFileLines:    l.ReadFileLines,
+		GrepMatches:      l.GrepMatches,
+		WebFetchBytes:    l.WebFetchBytes,
+	}
+}
+
+// toolTimeouts converts the tools.timeouts setting.
+func toolTimeouts(cfg *config.Config) tools.Timeouts {
+	if cfg == nil || len(cfg.Tools.Timeouts) == 0 {
+		return nil
+	}
+	t := tools.Timeouts{}
+	for name, ms := range cfg.Tools.Timeouts {
+		t[name] = time.Duration(ms) * time.Millisecond
+	}
+	return t
+}
+
+// applyModelSettings layers the models setting over the built-in model
+// capabilities.
+func applyModelSettings(cfg *config.Config) error {
+	if cfg == nil || len(cfg.Models) == 0 {
+		return nil
+	}
+	m := map[string]api.Capabilities{}
+	for name, mc := range cfg.Models {
+		m[name] = api.Capabilities{ContextWindow: mc.ContextWindow, MaxOutputTokens: mc.MaxOutputTokens, Thinking: mc.Thinking}
+	}
+	if err := api.OverrideModels(m); err != nil {
+		return fmt.Errorf("invalid models setting: %w", err)
+	}
+	return nil
+}
+
+// applyContextSettings sets the memory files from the context settings.
+func applyContextSettings(cfg *config.Config) error {
+	if cfg == nil {
+		return nil
+	}
+	if err := memory.Configure(cfg.Context.FileName, cfg.Context.MemoryFile, cfg.Context.MemoryMaxBytes); err != nil {
+		return fmt.Errorf("invalid context setting: %w", err)
+	}
+	return nil
+}
+
+// windowsShell returns the shell run_shell_command uses on Windows.
+func windowsShell(cfg *config.Config) tools.WindowsShell {
+	if cfg == nil {
+		return ""
+	}
+	sh, err := tools.ParseWindowsShell(cfg.Tools.Shell)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tools.shell: %v\n", err)
+		return ""
+	}
+	return sh
+}
+
+// shellName is the shell named in the system prompt: on Windows the one
+// run_shell_command uses, elsewhere "" for $SHELL.
+func shellName(sh tools.WindowsShell) string {
+	if runtime.GOOS != "windows" {
+		return ""
+	}
+	return sh.Name()
+}
+
+// readOnlyRun describes a one-shot agent run with read-only workspace tools.
+type readOnlyRun struct {
+	Model       string
+	MaxTurns    int
+	Temperature float64
+	Prompt      string
+	Tools       []tools.Tool // extra tools, typically one the model reports through
+	Output      io.Writer    // where model text streams (default stderr)
+}
+
+// runReadOnlyAgent connects to the backend and runs the prompt with the
+// read-only built-in tools plus run.Tools. Progress is written to stderr so
+// stdout stays free for the command's own output; model text goes to
+// run.Output when set.
+func runReadOnlyAgent(ctx context.Context, run readOnlyRun) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	b, err := newBackend(run.Model)
+	if err != nil {
+		return err
+	}
+	if err := b.connect(ctx); err != nil {
+		return err
+	}
+
+	workDir, _ := os.Getwd()
+	registry := tools.NewRegistry(tools.RegistryOptions{WorkDir: workDir, ReadOnly: true, Debug: debug, Limits: toolLimits(cfg), Timeouts: toolTimeouts(cfg)})
+	for _, t := range run.Tools {
+		registry.Register(t)
+	}
+
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		return err
+	}
+
+	out := run.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	formatter, err := output.NewFormatter("text", out, os.Stderr, true)
+	if err != nil {
+		return err
+	}
+	loop := agent.NewLoop(b.provider, registry, nil, formatter, agent.Config{
+		MaxTurns:         run.MaxTurns,
+		Streaming:        true,
+		Debug:            debug,
+		Redactor:         redactor,
+		ToolOutputTokens: cfg.Tools.Limits.TurnOutputTokens,
+	})
+
+	req := &api.GenerateRequest{
+		Model:        run.Model,
+		Project:      b.projectID,
+		UserPromptID: fmt.Sprintf("g-%d", time.Now().UnixNano()),
+		Request: api.InnerRequest{
+			SystemInstruction: prompt.BuildSystemInstruction(prompt.Options{WorkDir: workDir}),
+			Contents: []api.Content{{
+				Role:  "user",
+				Parts: []api.Part{{Text: run.Prompt}},
+			}},
+			Config: api.GenerationConfig{
+				Temperature:     run.Temperature,
+				TopP:            0.95,
+				MaxOutputTokens: api.Model(run.Model).MaxOutputTokens,
+			},
+			Tools: []api.Tool{{FunctionDeclarations: toolDeclarations(cfg, "", registry.AllDeclarations())}},
+		},
+	}
+	err = loop.Run(ctx, req)
+	fmt.Fprintln(os.Stderr)
+	return err
+}
+
+// newWebSearchFunc returns the google_web_search callback, which performs
+// grounded search through the Code Assist API. The answer is returned with
+// citation markers numbering its sources, whose redirect links are
+// resolved to the pages themselves.
+func newWebSearchFunc(apiClient *api.Client, projectID, model string) tools.WebSearchFunc {
+	return func(ctx context.Context, query string) (string, []tools.WebSource, error) {
+		resp, err := apiClient.WebSearch(ctx, projectID, model, query)
+		if err != nil {
+			return "", nil, err
+		}
+		var text string
+		var sources []tools.WebSource
+		if len(resp.Response.Candidates) > 0 {
+			cand := resp.Response.Candidates[0]
+			for _, part := range cand.Content.Parts {
+				text += part.Text
+			}
+			api.ResolveGroundingURIs(ctx, cand.GroundingMetadata)
+			var cited []api.Source
+			text, cited = api.Cite(text, cand.GroundingMetadata)
+			for _, src := range cited {
+				sources = append(sources, tools.WebSource{Title: src.Title, URI: src.URI})
+			}
+		}
+		return text, sources, nil
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import "sync"
+
+// sessionCleanup stops, when the session ends, what its initialization
+// started: MCP servers and the sandbox container. Initialization runs in
+// the background and may finish after the session has ended; what it hands
+// over then is stopped at once.
+type sessionCleanup struct {
+	mu    sync.Mutex
+	ended bool
+	stops []func()
+}
+
+// add hands stop over to the session, or calls it if the session has ended.
+func (c *sessionCleanup) add(stop func()) {
+	c.mu.Lock()
+	if !c.ended {
+		c.stops = append(c.stops, stop)
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+	stop()
+}
+
+// run ends the session and calls what was handed over, last first.
+func (c *sessionCleanup) run() {
+	c.mu.Lock()
+	c.ended = true
+	stops := c.stops
+	c.stops = nil
+	c.mu.Unlock()
+	for i := len(stops) - 1; i >= 0; i-- {
+		stops[i]()
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSessionCleanup(t *testing.T) {
+	var c sessionCleanup
+	var got []string
+	c.add(func() { got = append(got, "mcp") })
+	c.add(func() { got = append(got, "container") })
+	if got != nil {
+		t.Fatalf("stopped before the session ended: %v", got)
+	}
+	c.run()
+	if want := []string{"container", "mcp"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("stopped %v, want %v", got, want)
+	}
+
+	// Handed over after the end: stopped at once, and only once
+	c.add(func() { got = append(got, "late") })
+	c.run()
+	if want := []string{"container", "mcp", "late"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("stopped %v, want %v", got, want)
+	}
+}
+
+func TestSessionCleanupConcurrent(t *testing.T) {
+	var c sessionCleanup
+	var mu sync.Mutex
+	stopped := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.add(func() {
+				mu.Lock()
+				stopped++
+				mu.Unlock()
+			})
+		}()
+	}
+	c.run()
+	wg.Wait()
+	if stopped != 50 {
+		t.Fatalf("stopped %d of 50", stopped)
+	}
+}
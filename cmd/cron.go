@@ -0,0 +1,229 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/audit"
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/k-sub1995/g/internal/cron"
+	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/prompt"
+	"github.com/k-sub1995/g/internal/redact"
+	"github.com/k-sub1995/g/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cronTaskfile string
+	cronOnly     []string
+	cronEvery    time.Duration
+)
+
+var cronCmd = &cobra.Command{
+	Use:   "cron --task <taskfile.yaml>",
+	Short: "Run the tasks in a task file headlessly",
+	Long: `Cron runs each task in a task file as a non-interactive agent run and
+delivers its result to a file and/or webhook. A lock file prevents
+overlapping runs, so it is safe to invoke from cron or a systemd timer;
+--every keeps the process running and repeats the tasks itself.
+
+Tools never prompt: risky shell commands are refused, and MCP servers are
+not started. Each task chooses its tools with "tools" (none, read-only or
+all) plus includeTools/excludeTools.
+
+Example taskfile.yaml:
+
+  name: nightly
+  timeout: 20m
+  tasks:
+    - name: deps
+      prompt: Update outdated Go dependencies and run the tests.
+      tools: all
+      yolo: true
+      output:
+        file: reports/{{task}}-{{date}}.md
+    - name: summary
+      prompt: Summarize yesterday's commits for the team.
+      output:
+        webhook: $SLACK_WEBHOOK_URL`,
+	Args: cobra.NoArgs,
+	RunE: runCron,
+}
+
+func init() {
+	rootCmd.AddCommand(cronCmd)
+	cronCmd.Flags().StringVar(&cronTaskfile, "task", "", "Task file to run (required)")
+	cronCmd.Flags().StringSliceVar(&cronOnly, "only", nil, "Run only these tasks")
+	cronCmd.Flags().DurationVar(&cronEvery, "every", 0, "Repeat the tasks at this interval instead of exiting")
+	_ = cronCmd.MarkFlagRequired("task")
+}
+
+// cronBackend is shared by all task runs.
+type cronBackend struct {
+	*backend
+	cfg      *config.Config
+	redactor *redact.Redactor
+	audit    *audit.Log
+}
+
+func runCron(cmd *cobra.Command, args []string) error {
+	tf, err := cron.Load(cronTaskfile)
+	if err != nil {
+		return err
+	}
+	tasks, err := tf.Select(cronOnly)
+	if err != nil {
+		return err
+	}
+
+	lock, err := cron.AcquireLock(tf.Lock)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	var models []string
+	for _, task := range tasks {
+		models = append(models, task.Model)
+	}
+	be, err := newBackend(models...)
+	if err != nil {
+		return err
+	}
+	if err := be.connect(ctx); err != nil {
+		return err
+	}
+	b := &cronBackend{backend: be, cfg: cfg}
+	if b.redactor, err = newRedactor(cfg); err != nil {
+		return err
+	}
+	if b.audit, err = openAuditLog(cfg, fmt.Sprintf("g-cron-%d", time.Now().UnixNano())); err != nil {
+		return err
+	}
+
+	name := tf.Name
+	if name == "" {
+		name = cronTaskfile
+	}
+	for {
+		failed := 0
+		for _, task := range tasks {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Fprintf(os.Stderr, "[cron] %s/%s: starting\n", name, task.Name)
+			started := time.Now()
+			out, runErr := b.run(ctx, task)
+			result := cron.NewResult(name, task.Name, started, out, runErr)
+			if err := cron.Deliver(ctx, task.Output, result); err != nil {
+				fmt.Fprintf(os.Stderr, "[cron] %s/%s: %v\n", name, task.Name, err)
+				if runErr == nil {
+					runErr = err
+				}
+			}
+			if runErr != nil {
+				failed++
+			}
+			fmt.Fprintf(os.Stderr, "[cron] %s\n", result.Text)
+		}
+
+		if cronEvery <= 0 {
+			if failed > 0 {
+				return fmt.Errorf("%d of %d tasks failed", failed, len(tasks))
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(cronEvery):
+		}
+	}
+}
+
+// run executes one task and returns the model's text output.
+func (b *cronBackend) run(ctx context.Context, task cron.Task) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(task.Timeout))
+	defer cancel()
+
+	registry := tools.NewRegistry(tools.RegistryOptions{
+		WorkDir:      task.WorkDir,
+		AutoApprove:  task.Yolo,
+		Sandbox:      true,
+		Debug:        debug,
+		WebSearch:    b.webSearch(task.Model),
+		WindowsShell: windowsShell(b.cfg),
+		Network: tools.NetworkPolicy{
+			Disabled:     b.cfg.Network.Disabled,
+			AllowedHosts: b.cfg.Network.AllowedHosts,
+		},
+		ReadOnly:     task.Tools == cron.ToolsReadOnly,
+		IncludeTools: task.IncludeTools,
+		ExcludeTools: task.ExcludeTools,
+		Limits:       toolLimits(b.cfg),
+		Timeouts:     toolTimeouts(b.cfg),
+	})
+
+	var buf bytes.Buffer
+	formatter, err := output.NewFormatter("text", io.MultiWriter(&buf, os.Stderr), os.Stderr, true)
+	if err != nil {
+		return "", err
+	}
+	loop := agent.NewLoop(b.provider, registry, nil, formatter, agent.Config{
+		MaxTurns:         task.MaxTurns,
+		Streaming:        true,
+		Debug:            debug,
+		Redactor:         b.redactor,
+		Audit:            b.audit,
+		AutoApprove:      task.Yolo,
+		ToolOutputTokens: b.cfg.Tools.Limits.TurnOutputTokens,
+	})
+
+	req := &api.GenerateRequest{
+		Model:        task.Model,
+		Project:      b.projectID,
+		UserPromptID: fmt.Sprintf("g-%d", time.Now().UnixNano()),
+		Request: api.InnerRequest{
+			SystemInstruction: prompt.BuildSystemInstruction(prompt.Options{WorkDir: task.WorkDir, Shell: shellName(windowsShell(b.cfg))}),
+			Contents: []api.Content{{
+				Role:  "user",
+				Parts: []api.Part{{Text: task.Prompt}},
+			}},
+			Config: api.GenerationConfig{
+				Temperature:     1.0,
+				TopP:            0.95,
+				MaxOutputTokens: api.Model(task.Model).MaxOutputTokens,
+			},
+		},
+	}
+	if task.Tools != cron.ToolsNone {
+		req.Request.Tools = []api.Tool{{FunctionDeclarations: toolDeclarations(b.cfg, "", registry.AllDeclarations())}}
+	}
+	err = loop.Run(ctx, req)
+	fmt.Fprintln(os.Stderr)
+	return buf.String(), err
+}
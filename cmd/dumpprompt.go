@@ -0,0 +1,108 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// promptDump is the --dump-prompt file in JSON: the first request as sent,
+// and the files its system instruction was built from.
+type promptDump struct {
+	Model             string               `json:"model"`
+	ContextFiles      []string             `json:"contextFiles"`
+	SystemInstruction *api.Content         `json:"systemInstruction,omitempty"`
+	Contents          []api.Content        `json:"contents"`
+	Tools             []api.Tool           `json:"tools,omitempty"`
+	GenerationConfig  api.GenerationConfig `json:"generationConfig"`
+}
+
+// dumpPrompt writes the system instruction, contents and tools of req to
+// path, as Markdown when path ends in .md and as JSON otherwise.
+func dumpPrompt(path string, req *api.GenerateRequest, contextFiles []string) error {
+	d := promptDump{
+		Model:             req.Model,
+		ContextFiles:      contextFiles,
+		SystemInstruction: req.Request.SystemInstruction,
+		Contents:          req.Request.Contents,
+		Tools:             req.Request.Tools,
+		GenerationConfig:  req.Request.Config,
+	}
+	if d.ContextFiles == nil {
+		d.ContextFiles = []string{}
+	}
+	var data []byte
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".md" || ext == ".markdown" {
+		data = []byte(d.markdown())
+	} else {
+		var err error
+		if data, err = json.MarshalIndent(d, "", "  "); err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing the prompt dump: %w", err)
+	}
+	return nil
+}
+
+func (d promptDump) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Prompt for %s\n\n## Context files\n\n", d.Model)
+	if len(d.ContextFiles) == 0 {
+		b.WriteString("None.\n\n")
+	}
+	for _, f := range d.ContextFiles {
+		fmt.Fprintf(&b, "- %s\n", f)
+	}
+	if len(d.ContextFiles) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## System instruction\n\n")
+	if d.SystemInstruction == nil {
+		b.WriteString("None.\n\n")
+	} else {
+		writeMarkdownParts(&b, d.SystemInstruction.Parts)
+	}
+
+	b.WriteString("## Contents\n\n")
+	for i, c := range d.Contents {
+		fmt.Fprintf(&b, "### %d. %s\n\n", i+1, c.Role)
+		writeMarkdownParts(&b, c.Parts)
+	}
+
+	var names []string
+	for _, t := range d.Tools {
+		for _, decl := range t.FunctionDeclarations {
+			names = append(names, decl.Name)
+		}
+	}
+	if len(names) > 0 {
+		fmt.Fprintf(&b, "## Tools\n\n%s\n", strings.Join(names, ", "))
+	}
+	return b.String()
+}
+
+// writeMarkdownParts writes text as is and other parts as JSON blocks.
+func writeMarkdownParts(b *strings.Builder, parts []api.Part) {
+	for _, p := range parts {
+		switch {
+		case p.Text != "":
+			b.WriteString(strings.TrimRight(p.Text, "\n"))
+			b.WriteString("\n\n")
+		case p.InlineData != nil:
+			fmt.Fprintf(b, "[%s attachment, %d bytes base64]\n\n", p.InlineData.MimeType, len(p.InlineData.Data))
+		default:
+			data, _ := json.MarshalIndent(p, "", "  ")
+			fmt.Fprintf(b, "```json\n%s\n```\n\n", data)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/k-sub1995/g/internal/explain"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainDepth   string
+	explainModel   string
+	explainTimeout time.Duration
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <path|symbol>",
+	Short: "Explain a file or symbol with its imports and call sites",
+	Long: `Explain gathers the context a good explanation needs - the file or the
+symbol's definition, what it imports, and where it is used (found by
+searching the repository) - and asks the model to explain it.
+
+A symbol may be a bare name or qualified, e.g. Open, store.Open or
+Server.Handle. --depth deep lets the model read callers and dependencies for
+an architecture-level explanation.
+
+Examples:
+  g explain internal/agent/loop.go
+  g explain Registry.Register --depth deep`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().StringVar(&explainDepth, "depth", "quick", "Explanation depth: quick or deep")
+	explainCmd.Flags().StringVarP(&explainModel, "model", "m", "gemini-2.5-flash", "Model to use")
+	explainCmd.Flags().DurationVarP(&explainTimeout, "timeout", "t", 5*time.Minute, "Timeout")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	depth, err := explain.ParseDepth(explainDepth)
+	if err != nil {
+		return err
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	target, err := explain.Resolve(workDir, args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	maxTurns := 4
+	if depth == explain.Deep {
+		maxTurns = 15
+	}
+	return runReadOnlyAgent(ctx, readOnlyRun{
+		Model:       explainModel,
+		MaxTurns:    maxTurns,
+		Temperature: 0.3,
+		Prompt:      explain.Prompt(target, depth),
+		Output:      os.Stdout,
+	})
+}
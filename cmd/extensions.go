@@ -0,0 +1,152 @@
+// Package cmd provides extension management commands for g.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/k-sub1995/g/internal/extension"
+	"github.com/spf13/cobra"
+)
+
+var extensionsCmd = &cobra.Command{
+	Use:     "extensions",
+	Aliases: []string{"extension", "ext"},
+	Short:   "Manage Gemini CLI extensions",
+}
+
+var extensionsInstallCmd = &cobra.Command{
+	Use:   "install <path-or-git-url>",
+	Short: "Install an extension and configure its variables",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runExtensionsInstall,
+}
+
+var extensionsConfigCmd = &cobra.Command{
+	Use:   "config <name>",
+	Short: "Re-enter the variables declared by an installed extension",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runExtensionsConfig,
+}
+
+var extensionsUninstallCmd = &cobra.Command{
+	Use:   "uninstall <name>",
+	Short: "Remove an installed extension and its stored secrets",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := extension.Uninstall(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Uninstalled extension %s\n", args[0])
+		return nil
+	},
+}
+
+var extensionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List enabled extensions",
+	RunE:  runExtensionsList,
+}
+
+func init() {
+	rootCmd.AddCommand(extensionsCmd)
+	extensionsCmd.AddCommand(extensionsInstallCmd)
+	extensionsCmd.AddCommand(extensionsConfigCmd)
+	extensionsCmd.AddCommand(extensionsUninstallCmd)
+	extensionsCmd.AddCommand(extensionsListCmd)
+}
+
+func runExtensionsInstall(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stat(args[0]); err != nil && offline {
+		return &offlineError{what: "installing from " + args[0]}
+	}
+	ext, err := extension.Install(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installed extension %s v%s to %s\n", ext.Name, ext.Version, ext.Path)
+	return promptExtensionVariables(ext, false)
+}
+
+func runExtensionsConfig(cmd *cobra.Command, args []string) error {
+	cwd, _ := os.Getwd()
+	extensions, err := extension.LoadAll(cwd)
+	if err != nil {
+		return err
+	}
+	for i := range extensions {
+		if extensions[i].Name == args[0] {
+			return promptExtensionVariables(&extensions[i], true)
+		}
+	}
+	return fmt.Errorf("extension %q not found", args[0])
+}
+
+func runExtensionsList(cmd *cobra.Command, args []string) error {
+	cwd, _ := os.Getwd()
+	extensions, err := extension.LoadAll(cwd)
+	if err != nil {
+		return err
+	}
+	if len(extensions) == 0 {
+		fmt.Println("No extensions installed.")
+		return nil
+	}
+	for _, ext := range extensions {
+		fmt.Printf("%s v%s\n", ext.Name, ext.Version)
+		fmt.Printf("  Path: %s\n", ext.Path)
+		for _, v := range ext.Variables {
+			status := "set"
+			if !extension.HasVariable(ext.Name, v) {
+				status = "missing"
+			}
+			fmt.Printf("  Variable %s: %s\n", v.Name, status)
+		}
+	}
+	return nil
+}
+
+// promptExtensionVariables asks for each declared variable and stores the
+// answers. Unless force is set, variables that already have a value are
+// skipped.
+func promptExtensionVariables(ext *extension.Extension, force bool) error {
+	for _, v := range ext.Variables {
+		if !force && extension.HasVariable(ext.Name, v) {
+			continue
+		}
+		label := v.Name
+		if v.Description != "" {
+			label = fmt.Sprintf("%s (%s)", v.Name, v.Description)
+		}
+		if v.Default != "" && !v.Secret {
+			label += fmt.Sprintf(" [%s]", v.Default)
+		}
+
+		var value string
+		if v.Secret {
+			b, err := readline.Password(label + ": ")
+			if err != nil {
+				return err
+			}
+			value = string(b)
+		} else {
+			line, err := readline.Line(label + ": ")
+			if err != nil {
+				return err
+			}
+			value = line
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if err := extension.SetVariable(ext.Name, v.Name, value); err != nil {
+			return fmt.Errorf("failed to store %s: %w", v.Name, err)
+		}
+	}
+	return nil
+}
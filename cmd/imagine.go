@@ -0,0 +1,139 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/k-sub1995/g/internal/anthropic"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/termimage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	imagineOut       string
+	imagineCount     int
+	imagineAspect    string
+	imagineSize      string
+	imagineModel     string
+	imagineTimeout   time.Duration
+	imagineNoPreview bool
+)
+
+var imagineCmd = &cobra.Command{
+	Use:   "imagine <prompt>",
+	Short: "Generate images from a prompt",
+	Long: `Imagine generates images with a Gemini image model, using the same
+credentials as the rest of g, and writes them to --out. With --count above 1
+the files are numbered (img-1.png, img-2.png, ...). In kitty, Ghostty, iTerm2
+and WezTerm the images are also shown in the terminal.
+
+Examples:
+  g imagine "a lighthouse at dusk, watercolor" --out lighthouse.png
+  g imagine "app icon for a note-taking tool" -n 4 --aspect 1:1`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImagine,
+}
+
+func init() {
+	rootCmd.AddCommand(imagineCmd)
+	imagineCmd.Flags().StringVarP(&imagineOut, "out", "o", "", "Output file (default image-<time>.png)")
+	imagineCmd.Flags().IntVarP(&imagineCount, "count", "n", 1, "Number of images to generate (1-8)")
+	imagineCmd.Flags().StringVar(&imagineAspect, "aspect", "", "Aspect ratio: "+strings.Join(api.AspectRatios, ", "))
+	imagineCmd.Flags().StringVar(&imagineSize, "size", "", "Resolution: 1K, 2K or 4K (supported by some models)")
+	imagineCmd.Flags().StringVarP(&imagineModel, "model", "m", api.DefaultImageModel, "Image model to use")
+	imagineCmd.Flags().DurationVarP(&imagineTimeout, "timeout", "t", 5*time.Minute, "Timeout")
+	imagineCmd.Flags().BoolVar(&imagineNoPreview, "no-preview", false, "Do not show the images in the terminal")
+}
+
+func runImagine(cmd *cobra.Command, args []string) error {
+	if imagineCount < 1 || imagineCount > 8 {
+		return fmt.Errorf("--count must be between 1 and 8")
+	}
+	if imagineAspect != "" && !slices.Contains(api.AspectRatios, imagineAspect) {
+		return fmt.Errorf("unknown aspect ratio %q (use %s)", imagineAspect, strings.Join(api.AspectRatios, ", "))
+	}
+	size := strings.ToUpper(imagineSize)
+	if size != "" && size != "1K" && size != "2K" && size != "4K" {
+		return fmt.Errorf("unknown size %q (use 1K, 2K or 4K)", imagineSize)
+	}
+	if strings.HasPrefix(imagineModel, anthropic.ModelPrefix) {
+		return fmt.Errorf("image generation requires a Gemini image model")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), imagineTimeout)
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	be, err := newBackend(imagineModel)
+	if err != nil {
+		return err
+	}
+	if err := be.connect(ctx); err != nil {
+		return err
+	}
+
+	preview := termimage.None
+	if stat, err := os.Stdout.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 && !imagineNoPreview {
+		preview = termimage.Detect()
+	}
+
+	prompt, err := be.dlp.Text(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	// Image models return one image per request, so ask count times
+	cfg := api.ImageConfig{AspectRatio: imagineAspect, ImageSize: size}
+	stamp := time.Now().Format("20060102-150405")
+	for i := 1; i <= imagineCount; i++ {
+		images, text, err := be.gemini.GenerateImage(ctx, be.projectID, imagineModel, prompt, cfg)
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			fmt.Fprintln(os.Stderr, text)
+		}
+		img := images[0]
+		path := imagePath(imagineOut, stamp, img.MimeType, i, imagineCount)
+		if err := os.WriteFile(path, img.Data, 0644); err != nil {
+			return err
+		}
+		if err := termimage.Write(os.Stdout, preview, img.MimeType, img.Data); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+// imagePath names the n-th of count images. Without out the file is called
+// image-<stamp> with an extension matching mimeType.
+func imagePath(out, stamp, mimeType string, n, count int) string {
+	if out == "" {
+		ext := "." + strings.TrimPrefix(mimeType, "image/")
+		if ext == ".jpeg" {
+			ext = ".jpg"
+		}
+		out = "image-" + stamp + ext
+	}
+	if count == 1 {
+		return out
+	}
+	ext := filepath.Ext(out)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(out, ext), n, ext)
+}
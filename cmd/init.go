@@ -0,0 +1,107 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/k-sub1995/g/internal/bootstrap"
+	"github.com/k-sub1995/g/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initForce    bool
+	initModel    string
+	initMaxTurns int
+	initTimeout  time.Duration
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate GEMINI.md and .geminiignore for this repository",
+	Long: `Init analyzes the repository (build files, test commands, structure) with a
+short read-only agent run and writes a tailored GEMINI.md plus a .geminiignore
+starter to the current directory. Existing files are kept unless --force is
+given.`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite existing GEMINI.md and .geminiignore")
+	initCmd.Flags().StringVarP(&initModel, "model", "m", "gemini-2.5-flash", "Model to use")
+	initCmd.Flags().IntVar(&initMaxTurns, "max-turns", 12, "Maximum agent loop turns")
+	initCmd.Flags().DurationVarP(&initTimeout, "timeout", "t", 5*time.Minute, "Analysis timeout")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	mdPath := filepath.Join(workDir, "GEMINI.md")
+	ignorePath := filepath.Join(workDir, ".geminiignore")
+
+	writeMD := initForce || !fileExists(mdPath)
+	writeIgnore := initForce || !fileExists(ignorePath)
+	if !writeMD && !writeIgnore {
+		return fmt.Errorf("GEMINI.md and .geminiignore already exist (use --force to regenerate)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), initTimeout)
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	found := bootstrap.Detect(workDir)
+	contextTool := &bootstrap.ContextTool{}
+	err = runReadOnlyAgent(ctx, readOnlyRun{
+		Model:       initModel,
+		MaxTurns:    initMaxTurns,
+		Temperature: 0.3,
+		Prompt:      bootstrap.Prompt(workDir, found),
+		Tools:       []tools.Tool{contextTool},
+	})
+	if err != nil {
+		return err
+	}
+	result, ok := contextTool.Result()
+	if !ok {
+		return fmt.Errorf("analysis did not complete: the model did not produce GEMINI.md")
+	}
+
+	if writeMD {
+		if err := os.WriteFile(mdPath, []byte(result.GeminiMD), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", mdPath)
+	} else {
+		fmt.Printf("Kept existing %s\n", mdPath)
+	}
+	if writeIgnore {
+		if err := os.WriteFile(ignorePath, []byte(bootstrap.IgnoreFile(found, result.IgnorePatterns)), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", ignorePath)
+	} else {
+		fmt.Printf("Kept existing %s\n", ignorePath)
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/input"
+)
+
+// maxListedSources bounds the parts named when the input is too large.
+const maxListedSources = 5
+
+// checkInputSize fails when the input is larger than --max-input-tokens or
+// the model's context window, naming its largest parts, rather than
+// letting the request fail or the content be cut. When show is set, the
+// estimated size of attached files and stdin is printed to stderr.
+func checkInputSize(sources []input.Source, show bool) error {
+	total := 0
+	attached := false
+	for _, s := range sources {
+		total += s.Tokens
+		attached = attached || s.Name != "prompt"
+	}
+	window := api.Model(model).ContextWindow
+
+	limit, what := maxInputTokens, "--max-input-tokens"
+	if limit <= 0 || limit > window {
+		limit, what = window, model+"'s context window"
+	}
+	if total > limit {
+		largest := append([]input.Source(nil), sources...)
+		sort.SliceStable(largest, func(i, j int) bool { return largest[i].Tokens > largest[j].Tokens })
+		if len(largest) > maxListedSources {
+			largest = largest[:maxListedSources]
+		}
+		return fmt.Errorf("input is ~%s tokens, over the %s tokens of %s; largest parts: %s", formatTokens(total), formatTokens(limit), what, describeSources(largest))
+	}
+
+	if show && attached {
+		fmt.Fprintf(os.Stderr, "Input: ~%s tokens (%.1f%% of the context window): %s\n", formatTokens(total), 100*float64(total)/float64(window), describeSources(sources))
+	}
+	return nil
+}
+
+func describeSources(sources []input.Source) string {
+	parts := make([]string, len(sources))
+	for i, s := range sources {
+		parts[i] = fmt.Sprintf("%s ~%s", s.Name, formatTokens(s.Tokens))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatTokens shortens a token count: 950, 12.3k, 1.2M.
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
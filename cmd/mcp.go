@@ -4,12 +4,16 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/k-sub1995/g/internal/api"
 	"github.com/k-sub1995/g/internal/config"
 	"github.com/k-sub1995/g/internal/extension"
 	"github.com/k-sub1995/g/internal/mcp"
@@ -26,9 +30,14 @@ interacting with external tools and services via the Model Context Protocol.`,
 var mcpListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available MCP servers and their tools",
-	RunE:  runMCPList,
+	Long: `List the configured MCP servers (including those of extensions), connecting
+to each to list its tools. With --json, print each server's status and its
+tools with their full input schemas.`,
+	RunE: runMCPList,
 }
 
+var mcpListJSON bool
+
 var mcpCallCmd = &cobra.Command{
 	Use:   "call <server> <tool> [args...]",
 	Short: "Call an MCP tool",
@@ -36,10 +45,87 @@ var mcpCallCmd = &cobra.Command{
 	RunE:  runMCPCall,
 }
 
+var mcpAuthCmd = &cobra.Command{
+	Use:   "auth <server>",
+	Short: "Authorize with a remote MCP server via OAuth",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMCPAuth,
+}
+
+var mcpAuthLogout bool
+
+var mcpAddCmd = &cobra.Command{
+	Use:   "add <name> [-- server args...]",
+	Short: "Add an MCP server to settings",
+	Long: `Add an MCP server to settings.json. Use --command for a local stdio server
+(arguments after the name are passed to it) or --url for a remote server.
+
+Examples:
+  g mcp add fs --command npx -- -y @modelcontextprotocol/server-filesystem .
+  g mcp add remote --url https://example.com/mcp
+  g mcp add legacy --url https://example.com/sse --transport sse`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMCPAdd,
+}
+
+var mcpRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an MCP server from settings",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMCPRemove,
+}
+
+var mcpTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Connect to an MCP server and verify it responds",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runMCPTest,
+}
+
+var (
+	mcpScope       string
+	mcpCommand     string
+	mcpURL         string
+	mcpTransport   string
+	mcpEnv         []string
+	mcpHeaders     []string
+	mcpCWD         string
+	mcpTimeoutMS   int
+	mcpTrust       bool
+	mcpInclude     []string
+	mcpExclude     []string
+	mcpTestTimeout time.Duration
+)
+
 func init() {
 	rootCmd.AddCommand(mcpCmd)
 	mcpCmd.AddCommand(mcpListCmd)
 	mcpCmd.AddCommand(mcpCallCmd)
+	mcpCmd.AddCommand(mcpAuthCmd)
+
+	mcpCmd.AddCommand(mcpAddCmd)
+	mcpCmd.AddCommand(mcpRemoveCmd)
+	mcpCmd.AddCommand(mcpTestCmd)
+
+	mcpListCmd.Flags().BoolVar(&mcpListJSON, "json", false, "Print servers, their status and tool schemas as JSON")
+
+	mcpAuthCmd.Flags().BoolVar(&mcpAuthLogout, "logout", false, "Remove the stored token instead of authorizing")
+
+	mcpAddCmd.Flags().StringVarP(&mcpScope, "scope", "s", "user", "Settings scope: user or project")
+	mcpAddCmd.Flags().StringVar(&mcpCommand, "command", "", "Command that starts a stdio server")
+	mcpAddCmd.Flags().StringVar(&mcpURL, "url", "", "URL of a remote server")
+	mcpAddCmd.Flags().StringVar(&mcpTransport, "transport", "http", "Remote transport: http or sse")
+	mcpAddCmd.Flags().StringArrayVarP(&mcpEnv, "env", "e", nil, "Environment variable for the server (KEY=VALUE)")
+	mcpAddCmd.Flags().StringArrayVarP(&mcpHeaders, "header", "H", nil, "HTTP header for remote servers (Name: value)")
+	mcpAddCmd.Flags().StringVar(&mcpCWD, "cwd", "", "Working directory for a stdio server")
+	mcpAddCmd.Flags().IntVar(&mcpTimeoutMS, "timeout", 0, "Request timeout in milliseconds")
+	mcpAddCmd.Flags().BoolVar(&mcpTrust, "trust", false, "Trust the server (skip tool confirmations)")
+	mcpAddCmd.Flags().StringSliceVar(&mcpInclude, "include-tools", nil, "Only expose these tools")
+	mcpAddCmd.Flags().StringSliceVar(&mcpExclude, "exclude-tools", nil, "Hide these tools")
+
+	mcpRemoveCmd.Flags().StringVarP(&mcpScope, "scope", "s", "user", "Settings scope: user or project")
+
+	mcpTestCmd.Flags().DurationVar(&mcpTestTimeout, "timeout", 30*time.Second, "Connection timeout")
 }
 
 func mergeExtensionMCPServers(cfg *config.Config) {
@@ -54,6 +140,97 @@ func mergeExtensionMCPServers(cfg *config.Config) {
 	}
 }
 
+// mcpServerNotFound explains why name is not among the configured servers.
+func mcpServerNotFound(cfg *config.Config, name string) error {
+	for _, untrusted := range cfg.UntrustedMCPServers {
+		if untrusted == name {
+			return fmt.Errorf("MCP server '%s' is in .gemini/settings.json, but this folder is not trusted (run 'g trust' to trust it)", name)
+		}
+	}
+	return fmt.Errorf("MCP server '%s' not found in config or extensions", name)
+}
+
+// warnUntrustedMCPServers notes the project's MCP servers that were not
+// loaded because the folder is not trusted.
+func warnUntrustedMCPServers(cfg *config.Config) {
+	if len(cfg.UntrustedMCPServers) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: MCP servers in .gemini/settings.json not started because this folder is not trusted: %s (run 'g trust' to trust it)\n", strings.Join(cfg.UntrustedMCPServers, ", "))
+	}
+}
+
+// mcpToolSelector returns a function that sets the tools of req to decls
+// less the MCP servers that neither the latest prompt needs nor an earlier
+// call in the conversation used.
+func mcpToolSelector(req *api.GenerateRequest, decls []api.FunctionDecl, m *mcp.Manager) func() {
+	names, serverTools := m.Tools()
+	keywords := map[string][]string{}
+	serverOf := map[string]string{} // declaration name to server
+	for _, name := range names {
+		cfg, _ := m.Config(name)
+		serverTools[name] = mcp.FilterTools(serverTools[name], cfg)
+		keywords[name] = cfg.Keywords
+		for _, t := range serverTools[name] {
+			serverOf[mcp.ToolName(name, t.Name)] = name
+		}
+	}
+	router := mcp.NewRouter(names, serverTools, keywords)
+
+	return func() {
+		attach := map[string]bool{}
+		for _, c := range req.Request.Contents {
+			for _, p := range c.Parts {
+				if p.FunctionCall != nil && serverOf[p.FunctionCall.Name] != "" {
+					attach[serverOf[p.FunctionCall.Name]] = true
+				}
+			}
+		}
+		if n := len(req.Request.Contents); n > 0 && req.Request.Contents[n-1].Role == "user" {
+			var prompt strings.Builder
+			for _, p := range req.Request.Contents[n-1].Parts {
+				prompt.WriteString(p.Text + "\n")
+			}
+			for _, name := range router.Route(prompt.String()) {
+				attach[name] = true
+			}
+		}
+
+		var selected []api.FunctionDecl
+		for _, d := range decls {
+			if server := serverOf[d.Name]; server == "" || attach[server] {
+				selected = append(selected, d)
+			}
+		}
+		req.Request.Tools = []api.Tool{{FunctionDeclarations: selected}}
+		if debug {
+			var skipped []string
+			for _, name := range names {
+				if !attach[name] {
+					skipped = append(skipped, name)
+				}
+			}
+			fmt.Fprintf(os.Stderr, "[mcp] %d of %d tool declarations sent; servers left out for this prompt: %s\n",
+				len(selected), len(decls), cmp.Or(strings.Join(skipped, ", "), "none"))
+		}
+	}
+}
+
+// mcpServerInfo is a server in 'g mcp list --json'.
+type mcpServerInfo struct {
+	Name    string        `json:"name"`
+	Status  string        `json:"status"` // "connected" or "error"
+	Error   string        `json:"error,omitempty"`
+	Server  string        `json:"server,omitempty"` // name and version the server reports
+	Version string        `json:"version,omitempty"`
+	Tools   []mcpToolInfo `json:"tools"`
+}
+
+type mcpToolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema,omitempty"`
+	Enabled     bool            `json:"enabled"` // false when includeTools/excludeTools filter it out
+}
+
 func runMCPList(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
@@ -61,8 +238,9 @@ func runMCPList(cmd *cobra.Command, args []string) error {
 	}
 
 	mergeExtensionMCPServers(cfg)
+	warnUntrustedMCPServers(cfg)
 
-	if len(cfg.MCPServers) == 0 {
+	if len(cfg.MCPServers) == 0 && !mcpListJSON {
 		fmt.Println("No MCP servers configured.")
 		fmt.Println("Add servers to ~/.gemini/settings.json under 'mcpServers' or install extensions")
 		return nil
@@ -70,38 +248,64 @@ func runMCPList(cmd *cobra.Command, args []string) error {
 
 	ctx := context.Background()
 
-	for name, serverCfg := range cfg.MCPServers {
-		fmt.Printf("=== %s ===\n", name)
+	names := make([]string, 0, len(cfg.MCPServers))
+	for name := range cfg.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-		if serverCfg.Command == "" {
-			fmt.Printf("  (HTTP/SSE transport - not yet supported)\n\n")
-			continue
-		}
+	servers := []mcpServerInfo{}
+	for _, name := range names {
+		serverCfg := cfg.MCPServers[name]
+		info := mcpServerInfo{Name: name, Status: "connected", Tools: []mcpToolInfo{}}
 
-		client, err := mcp.NewClient(serverCfg.Command, serverCfg.Args, serverCfg.Env, serverCfg.CWD)
+		client, err := mcp.NewClientFromConfig(name, serverCfg)
+		if err == nil {
+			if err = client.Initialize(ctx); err != nil {
+				err = fmt.Errorf("initializing: %w", err)
+			} else {
+				info.Server, info.Version = client.ServerName, client.ServerVersion
+				for _, tool := range client.Tools {
+					info.Tools = append(info.Tools, mcpToolInfo{
+						Name:        tool.Name,
+						Description: tool.Description,
+						InputSchema: tool.InputSchema,
+						Enabled:     mcp.ToolEnabled(serverCfg, tool.Name),
+					})
+				}
+			}
+			client.Close()
+		}
 		if err != nil {
-			fmt.Printf("  Error: %v\n\n", err)
-			continue
+			info.Status, info.Error = "error", err.Error()
 		}
+		servers = append(servers, info)
+	}
 
-		if err := client.Initialize(ctx); err != nil {
-			fmt.Printf("  Error initializing: %v\n\n", err)
-			client.Close()
+	if mcpListJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(servers)
+	}
+	for _, info := range servers {
+		fmt.Printf("=== %s ===\n", info.Name)
+		if info.Error != "" {
+			fmt.Printf("  Error: %s\n\n", info.Error)
 			continue
 		}
-
-		fmt.Printf("  Server: %s %s\n", client.ServerName, client.ServerVersion)
+		fmt.Printf("  Server: %s %s\n", info.Server, info.Version)
 		fmt.Printf("  Tools:\n")
-		for _, tool := range client.Tools {
+		for _, tool := range info.Tools {
 			fmt.Printf("    - %s", tool.Name)
 			if tool.Description != "" {
 				fmt.Printf(": %s", tool.Description)
 			}
+			if !tool.Enabled {
+				fmt.Printf(" (filtered)")
+			}
 			fmt.Println()
 		}
 		fmt.Println()
-
-		client.Close()
 	}
 
 	return nil
@@ -135,16 +339,12 @@ func runMCPCall(cmd *cobra.Command, args []string) error {
 
 	serverCfg, ok := cfg.MCPServers[serverName]
 	if !ok {
-		return fmt.Errorf("MCP server '%s' not found in config or extensions", serverName)
-	}
-
-	if serverCfg.Command == "" {
-		return fmt.Errorf("HTTP/SSE transport not yet supported")
+		return mcpServerNotFound(cfg, serverName)
 	}
 
 	ctx := context.Background()
 
-	client, err := mcp.NewClient(serverCfg.Command, serverCfg.Args, serverCfg.Env, serverCfg.CWD)
+	client, err := mcp.NewClientFromConfig(serverName, serverCfg)
 	if err != nil {
 		return fmt.Errorf("failed to start MCP server: %w", err)
 	}
@@ -158,11 +358,154 @@ func runMCPCall(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Calling %s.%s with args: %v\n", serverName, toolName, toolArgs)
 	}
 
-	result, err := client.CallTool(ctx, toolName, toolArgs)
+	result, err := client.CallToolResult(ctx, toolName, toolArgs)
 	if err != nil {
 		return fmt.Errorf("tool call failed: %w", err)
 	}
 
-	fmt.Println(result)
+	fmt.Println(result.Text)
+	for _, blob := range result.Blobs {
+		fmt.Printf("[%s, %d bytes base64]\n", blob.MimeType, len(blob.Data))
+	}
+	return nil
+}
+
+func runMCPAuth(cmd *cobra.Command, args []string) error {
+	serverName := args[0]
+
+	if mcpAuthLogout {
+		if err := mcp.Logout(serverName); err != nil {
+			return fmt.Errorf("failed to remove token: %w", err)
+		}
+		fmt.Printf("Removed stored OAuth token for %s\n", serverName)
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	mergeExtensionMCPServers(cfg)
+
+	serverCfg, ok := cfg.MCPServers[serverName]
+	if !ok {
+		return mcpServerNotFound(cfg, serverName)
+	}
+
+	if err := mcp.Authenticate(context.Background(), serverName, serverCfg); err != nil {
+		return err
+	}
+	fmt.Printf("Authorized %s\n", serverName)
+	return nil
+}
+
+func runMCPAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if (mcpCommand == "") == (mcpURL == "") {
+		return fmt.Errorf("exactly one of --command or --url is required")
+	}
+
+	server := config.MCPServerConfig{
+		Timeout:      mcpTimeoutMS,
+		Trust:        mcpTrust,
+		IncludeTools: mcpInclude,
+		ExcludeTools: mcpExclude,
+	}
+
+	if mcpCommand != "" {
+		server.Command = mcpCommand
+		server.Args = args[1:]
+		server.CWD = mcpCWD
+		if len(mcpEnv) > 0 {
+			server.Env = make(map[string]string)
+			for _, kv := range mcpEnv {
+				k, v, ok := strings.Cut(kv, "=")
+				if !ok || k == "" {
+					return fmt.Errorf("invalid --env %q: expected KEY=VALUE", kv)
+				}
+				server.Env[k] = v
+			}
+		}
+	} else {
+		if len(args) > 1 {
+			return fmt.Errorf("server arguments are only valid with --command")
+		}
+		switch mcpTransport {
+		case "http":
+			server.HTTPURL = mcpURL
+		case "sse":
+			server.URL = mcpURL
+			server.Type = "sse"
+		default:
+			return fmt.Errorf("unknown transport %q (use http or sse)", mcpTransport)
+		}
+		if len(mcpHeaders) > 0 {
+			server.Headers = make(map[string]string)
+			for _, h := range mcpHeaders {
+				k, v, ok := strings.Cut(h, ":")
+				if !ok || strings.TrimSpace(k) == "" {
+					return fmt.Errorf("invalid --header %q: expected 'Name: value'", h)
+				}
+				server.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+
+	path, err := config.SettingsPath(mcpScope)
+	if err != nil {
+		return err
+	}
+	if err := config.SetMCPServer(path, name, server); err != nil {
+		return err
+	}
+	fmt.Printf("Added MCP server %s to %s\n", name, path)
+	return nil
+}
+
+func runMCPRemove(cmd *cobra.Command, args []string) error {
+	path, err := config.SettingsPath(mcpScope)
+	if err != nil {
+		return err
+	}
+	if err := config.RemoveMCPServer(path, args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Removed MCP server %s from %s\n", args[0], path)
+	return nil
+}
+
+func runMCPTest(cmd *cobra.Command, args []string) error {
+	serverName := args[0]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	mergeExtensionMCPServers(cfg)
+
+	serverCfg, ok := cfg.MCPServers[serverName]
+	if !ok {
+		return mcpServerNotFound(cfg, serverName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpTestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	client, err := mcp.NewClientFromConfig(serverName, serverCfg)
+	if err != nil {
+		return fmt.Errorf("✗ %s: failed to connect: %w", serverName, err)
+	}
+	defer client.Close()
+
+	if err := client.Initialize(ctx); err != nil {
+		return fmt.Errorf("✗ %s: failed to initialize: %w", serverName, err)
+	}
+
+	fmt.Printf("✓ %s: connected to %s %s in %s (%d tools)\n",
+		serverName, client.ServerName, client.ServerVersion,
+		time.Since(start).Round(time.Millisecond), len(client.Tools))
 	return nil
 }
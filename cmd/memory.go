@@ -0,0 +1,309 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/history"
+	"github.com/k-sub1995/g/internal/memory"
+	"github.com/k-sub1995/g/internal/session"
+	"github.com/k-sub1995/g/internal/summary"
+	"github.com/spf13/cobra"
+)
+
+var (
+	memoryTag     string
+	memoryBefore  string
+	memoryYes     bool
+	memorySession string
+	memoryModel   string
+)
+
+// projectCommand matches shell commands whose success is worth
+// remembering: how the project is built, tested and checked.
+var projectCommand = regexp.MustCompile(`\b(build|test|lint|vet|check|make|compile|tox|pytest|cargo|gradle|mvn)\b`)
+
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Review and prune what save_memory remembered",
+}
+
+var memoryShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "List the memory files in the prompt and the saved memories",
+	Args:  cobra.NoArgs,
+	RunE:  runMemoryShow,
+}
+
+var memoryEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the memory file in $VISUAL or $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE:  runMemoryEdit,
+}
+
+var memoryClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove saved memories, all or by --tag and --before",
+	Long: `Remove the entries save_memory added to the memory file, all of them or
+those matching --tag and --before. Text written by hand outside the
+"` + memory.Section + `" section is kept; use 'g memory edit' for it.`,
+	Args: cobra.NoArgs,
+	RunE: runMemoryClear,
+}
+
+var memoryRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Propose project notes for GEMINI.md from a saved session",
+	Long: `Ask the model which durable facts about the project a saved session found
+(build and test commands that worked, where code and tests live, conventions
+and pitfalls) that the memory files do not say yet, and add them to the
+project's GEMINI.md under "` + memory.ProjectSection + `" once you confirm.
+In the interactive mode, /memory refresh does the same for the current
+conversation.`,
+	Args: cobra.NoArgs,
+	RunE: runMemoryRefresh,
+}
+
+func init() {
+	rootCmd.AddCommand(memoryCmd)
+	memoryCmd.AddCommand(memoryShowCmd)
+	memoryCmd.AddCommand(memoryEditCmd)
+	memoryCmd.AddCommand(memoryClearCmd)
+	memoryCmd.AddCommand(memoryRefreshCmd)
+	memoryRefreshCmd.Flags().StringVar(&memorySession, "session", "latest", "Session to learn from (ID, prefix or latest)")
+	memoryRefreshCmd.Flags().StringVarP(&memoryModel, "model", "m", "", "Model that proposes the notes (default: the session's)")
+	memoryRefreshCmd.Flags().BoolVarP(&memoryYes, "yes", "y", false, "Add the proposed notes without asking")
+	memoryShowCmd.Flags().StringVar(&memoryTag, "tag", "", "Only list memories with this tag")
+	memoryClearCmd.Flags().StringVar(&memoryTag, "tag", "", "Only remove memories with this tag")
+	memoryClearCmd.Flags().StringVar(&memoryBefore, "before", "", "Only remove memories saved before this date (YYYY-MM-DD); undated ones count as older")
+	memoryClearCmd.Flags().BoolVarP(&memoryYes, "yes", "y", false, "Do not ask for confirmation")
+}
+
+func runMemoryShow(cmd *cobra.Command, args []string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	userFile, err := memory.UserFile()
+	if err != nil {
+		return err
+	}
+
+	files := memory.Files(workDir)
+	if len(files) == 0 {
+		fmt.Println("No memory files are added to the prompt here.")
+	} else {
+		fmt.Println("Added to every prompt:")
+		total := 0
+		for _, path := range files {
+			data, _ := os.ReadFile(path)
+			tokens := history.EstimateText(string(data))
+			total += tokens
+			fmt.Printf("  %-8s ~%s tokens  %s\n", formatSize(int64(len(data))), formatTokens(tokens), path)
+		}
+		fmt.Printf("  total ~%s tokens\n", formatTokens(total))
+	}
+
+	entries, err := memory.Entries(userFile)
+	if err != nil {
+		return err
+	}
+	var size int64
+	if info, err := os.Stat(userFile); err == nil {
+		size = info.Size()
+	}
+	fmt.Printf("\nSaved memories in %s (%s of %s):\n", userFile, formatSize(size), formatSize(int64(memory.MaxBytes())))
+	shown := 0
+	for _, e := range entries {
+		if memoryTag != "" && !e.HasTag(memoryTag) {
+			continue
+		}
+		fmt.Printf("  %s\n", strings.TrimPrefix(e.String(), "- "))
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("  none")
+	}
+	return nil
+}
+
+func runMemoryEdit(cmd *cobra.Command, args []string) error {
+	path, err := memory.UserFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// The editor setting may carry arguments, as in "code --wait"
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", editor, err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > int64(memory.MaxBytes()) {
+		fmt.Fprintf(os.Stderr, "Warning: %s is %s, over the %s save_memory allows; new memories will not be saved\n", path, formatSize(info.Size()), formatSize(int64(memory.MaxBytes())))
+	}
+	return nil
+}
+
+func runMemoryClear(cmd *cobra.Command, args []string) error {
+	var before time.Time
+	if memoryBefore != "" {
+		var err error
+		if before, err = time.Parse("2006-01-02", memoryBefore); err != nil {
+			return fmt.Errorf("invalid --before %q (use YYYY-MM-DD)", memoryBefore)
+		}
+	}
+	match := func(e memory.Entry) bool {
+		if memoryTag != "" && !e.HasTag(memoryTag) {
+			return false
+		}
+		return before.IsZero() || e.Date.Before(before)
+	}
+
+	path, err := memory.UserFile()
+	if err != nil {
+		return err
+	}
+	entries, err := memory.Entries(path)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for _, e := range entries {
+		if match(e) {
+			n++
+		}
+	}
+	if n == 0 {
+		fmt.Println("No memories to remove.")
+		return nil
+	}
+
+	if !memoryYes {
+		if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+			return fmt.Errorf("would remove %d memories from %s; pass --yes to confirm", n, path)
+		}
+		fmt.Printf("Remove %d memories from %s? [y/N] ", n, path)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Kept.")
+			return nil
+		}
+	}
+	removed, err := memory.Remove(match)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d memories from %s\n", removed, path)
+	return nil
+}
+
+func runMemoryRefresh(cmd *cobra.Command, args []string) error {
+	s, err := session.Load(memorySession)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	m := memoryModel
+	if m == "" {
+		m = s.Model
+	}
+	if m == "" {
+		m = model
+	}
+	be, err := newBackend(m)
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if err := be.connect(ctx); err != nil {
+		return err
+	}
+	req := &api.GenerateRequest{
+		Model:        m,
+		Project:      be.projectID,
+		UserPromptID: fmt.Sprintf("g-%d", time.Now().UnixNano()),
+		Request:      api.InnerRequest{Contents: s.Contents},
+	}
+	return refreshMemory(ctx, be.provider, req, s.WorkDir, func(question string) bool {
+		if memoryYes {
+			return true
+		}
+		if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+			fmt.Fprintln(os.Stderr, "Pass --yes to add them.")
+			return false
+		}
+		fmt.Fprint(os.Stderr, question)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		a := strings.ToLower(strings.TrimSpace(answer))
+		return a == "y" || a == "yes"
+	})
+}
+
+// refreshMemory proposes notes about the project in workDir from the
+// conversation in req and adds those confirm accepts to its memory file.
+func refreshMemory(ctx context.Context, p api.Provider, req *api.GenerateRequest, workDir string, confirm func(question string) bool) error {
+	path := memory.ProjectFile(workDir)
+	fmt.Fprintf(os.Stderr, "Looking for project facts worth keeping in %s...\n", path)
+	known := memory.Load(workDir)
+	notes, err := history.ProposeNotes(ctx, p, req, req.Model, known)
+	if err != nil {
+		return err
+	}
+	notes = memory.NewNotes(known, notes)
+	if len(notes) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing new to add")
+		return nil
+	}
+	fmt.Fprintln(os.Stderr)
+	for _, n := range notes {
+		fmt.Fprintf(os.Stderr, "  - %s\n", n)
+	}
+	fmt.Fprintln(os.Stderr)
+	if !confirm(fmt.Sprintf("Add these notes to %s? [y/N] ", path)) {
+		fmt.Fprintln(os.Stderr, "Not added")
+		return nil
+	}
+	added, err := memory.AddProjectNotes(path, notes)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Added %d notes to %s\n", len(added), path)
+	return nil
+}
+
+// learnedProject reports whether a run found out how the project is built
+// or tested, which makes it worth offering a memory refresh.
+func learnedProject(s summary.Run) bool {
+	for _, c := range s.Commands {
+		if c.ExitCode != nil && *c.ExitCode == 0 && projectCommand.MatchString(c.Command) {
+			return true
+		}
+	}
+	return false
+}
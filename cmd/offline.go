@@ -0,0 +1,53 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// offline is set by --offline.
+var offline bool
+
+// offlineError reports something --offline does not allow.
+type offlineError struct {
+	what string
+}
+
+func (e *offlineError) Error() string {
+	return fmt.Sprintf("offline mode: %s needs the network (run without --offline)", e.what)
+}
+
+// offlineTransport refuses requests to other machines at once, instead of
+// waiting for DNS or connection timeouts. Servers on this machine, such as
+// a local MCP server, stay reachable.
+type offlineTransport struct {
+	next http.RoundTripper
+}
+
+func (t offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isLocalHost(req.URL.Hostname()) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, &offlineError{what: "the request to " + req.URL.Host}
+	}
+	return t.next.RoundTrip(req)
+}
+
+func isLocalHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// goOffline makes every HTTP client that uses the default transport, which
+// includes the API, auth, MCP and upgrade clients, fail fast.
+func goOffline() {
+	http.DefaultTransport = offlineTransport{next: http.DefaultTransport}
+}
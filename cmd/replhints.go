@@ -0,0 +1,146 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/chzyer/readline"
+	"github.com/chzyer/readline/runes"
+)
+
+const (
+	replPrompt      = "> "
+	replPlaceholder = "Type your message or @path/to/file"
+)
+
+// replCommands are the REPL's slash commands, completed with Tab and
+// ghosted while typed. A command's first entry is the one ghosted.
+var replCommands = []string{
+	"/compress", "/exit", "/memory refresh", "/pin", "/quit",
+	"/think", "/think off", "/think low", "/think medium", "/think high",
+	"/unpin",
+}
+
+// replScreenWidth returns the terminal's columns.
+var replScreenWidth = readline.GetScreenWidth
+
+// replHints draws, while a prompt is typed, what a richer line editor
+// would: a placeholder in the empty line, the rest of a slash command in
+// gray, and the model and approval mode at the right edge. They are drawn
+// after the text and the cursor is moved back, so they are only shown
+// when they fit on the line.
+type replHints struct {
+	mode  string       // the approval mode
+	model atomic.Value // string; set between turns, read while readline paints
+	off   atomic.Bool  // while a question is asked at the prompt
+}
+
+// status returns the model and approval mode shown at the right edge.
+func (h *replHints) status() string {
+	if model, _ := h.model.Load().(string); model != "" {
+		return model + " · " + h.mode
+	}
+	return h.mode
+}
+
+// Paint implements readline.Painter.
+func (h *replHints) Paint(line []rune, pos int) []rune {
+	if h.off.Load() || len(line) > 0 && line[len(line)-1] == '\n' {
+		return line // the line was entered
+	}
+	var ghost string
+	switch {
+	case len(line) == 0:
+		// After painting an empty line readline writes a space at the
+		// cursor, so the placeholder starts a column later
+		ghost = " " + replPlaceholder
+	case pos == len(line):
+		ghost = h.completion(string(line))
+	}
+	status := h.status()
+	statusWidth := runes.WidthAll([]rune(status))
+
+	free := replScreenWidth() - 1 - len(replPrompt) - runes.WidthAll(line)
+	if len(ghost) > free {
+		ghost = ""
+	}
+	free -= len(ghost)
+	if statusWidth+2 > free {
+		status = ""
+	}
+
+	out := append([]rune{}, line...)
+	back := 0
+	if ghost != "" {
+		out = append(out, []rune("\033[2m"+ghost+"\033[0m")...)
+		back += len(ghost)
+	}
+	if status != "" {
+		pad := free - statusWidth
+		out = append(out, []rune(strings.Repeat(" ", pad)+"\033[2m"+status+"\033[0m")...)
+		back += pad + statusWidth
+	}
+	if back > 0 {
+		out = append(out, []rune(fmt.Sprintf("\033[%dD", back))...)
+	}
+	return out
+}
+
+// completion returns what the first slash command starting with line adds
+// to it.
+func (h *replHints) completion(line string) string {
+	if !strings.HasPrefix(line, "/") {
+		return ""
+	}
+	for _, c := range replCommands {
+		if len(c) > len(line) && strings.HasPrefix(c, line) {
+			return c[len(line):]
+		}
+	}
+	return ""
+}
+
+// Do implements readline.AutoCompleter: Tab completes slash commands.
+func (h *replHints) Do(line []rune, pos int) ([][]rune, int) {
+	prefix := string(line[:pos])
+	if !strings.HasPrefix(prefix, "/") {
+		return nil, 0
+	}
+	var candidates [][]rune
+	for _, c := range replCommands {
+		if len(c) > len(prefix) && strings.HasPrefix(c, prefix) {
+			candidates = append(candidates, []rune(c[len(prefix):]))
+		}
+	}
+	return candidates, len([]rune(prefix))
+}
+
+// OnChange implements readline.Listener: → at the end of the line accepts
+// the ghosted command.
+func (h *replHints) OnChange(line []rune, pos int, key rune) ([]rune, int, bool) {
+	if key != readline.CharForward || pos != len(line) || len(line) == 0 {
+		return nil, 0, false
+	}
+	rest := h.completion(string(line))
+	if rest == "" {
+		return nil, 0, false
+	}
+	line = append(line, []rune(rest)...)
+	return line, len(line), true
+}
+
+// replAsk asks a question at the REPL prompt and returns the answer, with
+// the hints hidden meanwhile.
+func replAsk(rl *readline.Instance, question string) (string, error) {
+	if h, ok := rl.Config.Painter.(*replHints); ok {
+		h.off.Store(true)
+		defer h.off.Store(false)
+	}
+	rl.SetPrompt(question)
+	defer rl.SetPrompt(replPrompt)
+	answer, err := rl.Readline()
+	return strings.TrimSpace(answer), err
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReplHintsCompletion(t *testing.T) {
+	h := &replHints{}
+	for line, want := range map[string]string{
+		"/co":       "mpress",
+		"/think":    " off",
+		"/think h":  "igh",
+		"/compress": "",
+		"/nope":     "",
+		"co":        "",
+		"":          "",
+	} {
+		if got := h.completion(line); got != want {
+			t.Errorf("completion(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestReplHintsDo(t *testing.T) {
+	h := &replHints{}
+	candidates, length := h.Do([]rune("/thi and more"), 4)
+	var got []string
+	for _, c := range candidates {
+		got = append(got, string(c))
+	}
+	want := []string{"nk", "nk off", "nk low", "nk medium", "nk high"}
+	if !reflect.DeepEqual(got, want) || length != 4 {
+		t.Errorf("Do = %q, %d; want %q, 4", got, length, want)
+	}
+	if candidates, _ := h.Do([]rune("fix /think"), 10); candidates != nil {
+		t.Errorf("completed a line not starting with /: %q", candidates)
+	}
+}
+
+func TestReplHintsPaint(t *testing.T) {
+	defer func(w func() int) { replScreenWidth = w }(replScreenWidth)
+	replScreenWidth = func() int { return 80 }
+
+	h := &replHints{mode: "yolo"}
+	h.model.Store("gemini-2.5-pro")
+
+	// The hints fill the line after the prompt and the cursor goes back to
+	// where it was
+	back := func(out []rune, line string) string {
+		free := 80 - 1 - len(replPrompt) - len(line)
+		want := fmt.Sprintf("\033[%dD", free)
+		if !strings.HasSuffix(string(out), want) {
+			t.Errorf("Paint(%q) = %q, want it to end with %q", line, string(out), want)
+		}
+		return string(out)
+	}
+	if out := back(h.Paint(nil, 0), ""); !strings.Contains(out, replPlaceholder) || !strings.Contains(out, "gemini-2.5-pro · yolo") {
+		t.Errorf("empty line painted as %q", out)
+	}
+	if out := back(h.Paint([]rune("/co"), 3), "/co"); !strings.HasPrefix(out, "/co\033[2mmpress\033[0m") {
+		t.Errorf("/co painted as %q", out)
+	}
+	if out := back(h.Paint([]rune("/co"), 1), "/co"); strings.Contains(out, "mpress") {
+		t.Errorf("ghosted a completion with the cursor inside the line: %q", out)
+	}
+
+	// Hints that do not fit are left out
+	long := strings.Repeat("x", 70)
+	if out := string(h.Paint([]rune(long), 70)); out != long {
+		t.Errorf("long line painted as %q", out)
+	}
+	replScreenWidth = func() int { return -1 } // not a terminal
+	if out := string(h.Paint(nil, 0)); out != "" {
+		t.Errorf("painted %q without a terminal width", out)
+	}
+	replScreenWidth = func() int { return 80 }
+
+	// Entered lines and questions are left alone
+	if out := string(h.Paint([]rune("/co\n"), 4)); out != "/co\n" {
+		t.Errorf("entered line painted as %q", out)
+	}
+	h.off.Store(true)
+	if out := string(h.Paint(nil, 0)); out != "" {
+		t.Errorf("question painted as %q", out)
+	}
+}
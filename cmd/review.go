@@ -0,0 +1,176 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/k-sub1995/g/internal/review"
+	"github.com/k-sub1995/g/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+// maxReviewDiffBytes bounds the diff sent to the model.
+const maxReviewDiffBytes = 400 * 1024
+
+var (
+	reviewDiff     string
+	reviewPR       int
+	reviewFormat   string
+	reviewFailOn   string
+	reviewModel    string
+	reviewMaxTurns int
+	reviewTimeout  time.Duration
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Review a change set and report structured findings",
+	Long: `Review runs a read-only agent pass over a change set and reports findings
+with severity, file, line and suggestion.
+
+Without flags the uncommitted changes (git diff HEAD) are reviewed.
+
+Each finding quotes the code it is about and is placed on the line of the
+new file where that code appears in the diff; a line that cannot be
+verified is dropped, so annotations never point at the wrong place.
+
+Examples:
+  g review
+  g review --diff main..HEAD --format sarif > review.sarif
+  g review --pr 42 --format github --fail-on high`,
+	Args: cobra.NoArgs,
+	RunE: runReview,
+}
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+	reviewCmd.Flags().StringVar(&reviewDiff, "diff", "", "Revision range to review (e.g. main..HEAD)")
+	reviewCmd.Flags().IntVar(&reviewPR, "pr", 0, "GitHub pull request number to review (requires gh)")
+	reviewCmd.Flags().StringVarP(&reviewFormat, "format", "o", "text", "Output format: "+strings.Join(review.Formats, ", "))
+	reviewCmd.Flags().StringVar(&reviewFailOn, "fail-on", "none", "Exit non-zero if a finding has at least this severity: none, info, low, medium, high, critical")
+	reviewCmd.Flags().StringVarP(&reviewModel, "model", "m", "gemini-2.5-pro", "Model to use")
+	reviewCmd.Flags().IntVar(&reviewMaxTurns, "max-turns", 20, "Maximum agent loop turns")
+	reviewCmd.Flags().DurationVarP(&reviewTimeout, "timeout", "t", 10*time.Minute, "Review timeout")
+}
+
+func runReview(cmd *cobra.Command, args []string) error {
+	if reviewDiff != "" && reviewPR != 0 {
+		return fmt.Errorf("--diff and --pr are mutually exclusive")
+	}
+	failOn := review.Severity(-1)
+	if reviewFailOn != "none" {
+		s, err := review.ParseSeverity(reviewFailOn)
+		if err != nil {
+			return err
+		}
+		failOn = s
+	}
+	if err := review.Write(&bytes.Buffer{}, reviewFormat, review.Result{}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reviewTimeout)
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	source, diff, err := reviewChangeSet(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return fmt.Errorf("no changes to review (%s is empty)", source)
+	}
+	if len(diff) > maxReviewDiffBytes {
+		diff = truncateDiff(diff, maxReviewDiffBytes) + "\n... [diff truncated]\n"
+	}
+
+	collector := &review.Collector{}
+	err = runReadOnlyAgent(ctx, readOnlyRun{
+		Model:       reviewModel,
+		MaxTurns:    reviewMaxTurns,
+		Temperature: 0.2,
+		Prompt:      review.Prompt(source, diff),
+		Tools:       []tools.Tool{review.NewReportTool(collector)},
+	})
+	if err != nil {
+		return err
+	}
+
+	result, ok := collector.Result()
+	if !ok {
+		return fmt.Errorf("review did not complete: the model did not report findings")
+	}
+	review.Anchor(&result, diff)
+	if err := review.Write(os.Stdout, reviewFormat, result); err != nil {
+		return err
+	}
+
+	if failOn >= 0 {
+		if n := result.CountAtLeast(failOn); n > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("review found %d finding(s) at or above %s severity", n, failOn)
+		}
+	}
+	return nil
+}
+
+// reviewChangeSet returns a description of the change set and its diff.
+func reviewChangeSet(ctx context.Context) (source, diff string, err error) {
+	switch {
+	case reviewPR != 0:
+		if _, err := exec.LookPath("gh"); err != nil {
+			return "", "", fmt.Errorf("--pr requires the GitHub CLI (gh)")
+		}
+		source = "pull request #" + strconv.Itoa(reviewPR)
+		diff, err = commandOutput(ctx, "gh", "pr", "diff", strconv.Itoa(reviewPR))
+	case reviewDiff != "":
+		source = "git diff " + reviewDiff
+		diff, err = commandOutput(ctx, "git", "diff", reviewDiff, "--")
+	default:
+		source = "uncommitted changes"
+		diff, err = commandOutput(ctx, "git", "diff", "HEAD", "--")
+	}
+	return source, diff, err
+}
+
+func commandOutput(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, name, args...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("%s %s: %v: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// truncateDiff cuts diff to at most n bytes at the end of a line, or at a
+// rune boundary if the first line is longer than that.
+func truncateDiff(diff string, n int) string {
+	if len(diff) <= n {
+		return diff
+	}
+	if i := strings.LastIndexByte(diff[:n], '\n'); i > 0 {
+		return diff[:i]
+	}
+	for n > 0 && !utf8.RuneStart(diff[n]) {
+		n--
+	}
+	return diff[:n]
+}
@@ -0,0 +1,21 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import "testing"
+
+func TestTruncateDiff(t *testing.T) {
+	for _, tt := range []struct {
+		diff string
+		n    int
+		want string
+	}{
+		{"+a\n+b\n", 10, "+a\n+b\n"},
+		{"+a\n+bcd\n", 6, "+a"},
+		{"+日本語", 5, "+日"},
+	} {
+		if got := truncateDiff(tt.diff, tt.n); got != tt.want {
+			t.Errorf("truncateDiff(%q, %d) = %q, want %q", tt.diff, tt.n, got, tt.want)
+		}
+	}
+}
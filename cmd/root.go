@@ -6,26 +6,46 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/chzyer/readline"
 	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/anthropic"
 	"github.com/k-sub1995/g/internal/api"
-	"github.com/k-sub1995/g/internal/auth"
+	"github.com/k-sub1995/g/internal/approval"
+	"github.com/k-sub1995/g/internal/artifacts"
+	"github.com/k-sub1995/g/internal/audit"
+	"github.com/k-sub1995/g/internal/bestof"
 	"github.com/k-sub1995/g/internal/config"
 	"github.com/k-sub1995/g/internal/extension"
+	"github.com/k-sub1995/g/internal/filewatch"
+	"github.com/k-sub1995/g/internal/history"
 	"github.com/k-sub1995/g/internal/input"
 	"github.com/k-sub1995/g/internal/mcp"
+	"github.com/k-sub1995/g/internal/memory"
 	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/plugin"
 	"github.com/k-sub1995/g/internal/prompt"
+	"github.com/k-sub1995/g/internal/redact"
+	sandboxpkg "github.com/k-sub1995/g/internal/sandbox"
+	"github.com/k-sub1995/g/internal/skills"
+	"github.com/k-sub1995/g/internal/subagent"
+	"github.com/k-sub1995/g/internal/summary"
 	"github.com/k-sub1995/g/internal/tools"
+	"github.com/k-sub1995/g/internal/worktree"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
@@ -35,14 +55,42 @@ var (
 	model               string
 	outputFormat        string
 	files               []string
+	dumpPromptPath      string
+	maxInputTokens      int
 	timeout             time.Duration
 	debug               bool
 	rawOutput           bool
 	acceptRawOutputRisk bool
 	maxTurns            int
 	yolo                bool
-	sandbox             bool
+	sandbox             string
+	sandboxRuntime      string
+	sandboxImage        string
+	sandboxNetwork      bool
 	noAgent             bool
+	resume              string
+	worktreeMode        bool
+	thinking            string
+	grounding           bool
+	urlContext          bool
+	codeExecution       bool
+	candidates          int
+	pick                string
+	agentName           string
+	approvalWebhook     string
+	approvalFIFO        string
+	fallbackModel       string
+	projectOverride     string
+	proposeEdits        bool
+	eventStream         string
+	streamInclude       []string
+	extractMode         string
+	verifyCommand       string
+	verifyRetries       int
+	maxChangedFiles     int
+	maxChangedBytes     string
+	toolDeclarationMode string
+	selectMCPTools      bool
 )
 
 var rootCmd = &cobra.Command{
@@ -67,15 +115,64 @@ func init() {
 	rootCmd.Flags().StringVarP(&prompt_, "prompt", "p", "", "Prompt to send to Gemini (required)")
 	rootCmd.Flags().StringVarP(&model, "model", "m", "gemini-2.5-flash", "Model to use")
 	rootCmd.Flags().StringVarP(&outputFormat, "output-format", "o", "text", "Output format: text, json, stream-json")
+	rootCmd.Flags().StringVar(&eventStream, "event-stream", "", "Where tool calls and progress go: stdout, stderr or fd:N (default: stderr for text, stdout for stream-json, nowhere for json)")
+	rootCmd.Flags().StringVar(&verifyCommand, "verify", "", "When the agent is done, run this check (bare: verify.command from settings) and hand failures back to it")
+	rootCmd.Flags().Lookup("verify").NoOptDefVal = "true"
+	rootCmd.Flags().IntVar(&verifyRetries, "verify-retries", defaultVerifyRetries, "Turns the agent gets to fix a failing --verify check")
+	rootCmd.Flags().IntVar(&maxChangedFiles, "max-changed-files", 0, "Ask, or stop the run without a terminal, before edits change more files than this")
+	rootCmd.Flags().StringVar(&maxChangedBytes, "max-changed-bytes", "", "Ask, or stop the run without a terminal, before edits change more bytes than this (e.g. 200KB)")
+	rootCmd.Flags().StringVar(&extractMode, "extract", "", "Print only part of the final response: code (first code block), last-block or json")
+	rootCmd.Flags().StringSliceVar(&streamInclude, "stream-include", nil, "Only emit these stream-json event types, e.g. content,tool_call,usage")
 	rootCmd.Flags().StringArrayVarP(&files, "file", "f", nil, "Files to include in context")
+	rootCmd.Flags().StringVar(&dumpPromptPath, "dump-prompt", "", "Write the system instruction, context files and contents of the first request to this file (Markdown for .md, else JSON)")
+	rootCmd.Flags().IntVar(&maxInputTokens, "max-input-tokens", 0, "Fail if the prompt, stdin and -f files are estimated at more tokens than this (default: the model's context window)")
 	rootCmd.Flags().DurationVarP(&timeout, "timeout", "t", 5*time.Minute, "API timeout")
 	rootCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug output")
 	rootCmd.Flags().BoolVar(&rawOutput, "raw-output", false, "Disable sanitization of model output (allow ANSI escape sequences)")
 	rootCmd.Flags().BoolVar(&acceptRawOutputRisk, "accept-raw-output-risk", false, "Suppress security warning when using --raw-output")
 	rootCmd.Flags().IntVar(&maxTurns, "max-turns", 25, "Maximum agent loop turns")
-	rootCmd.Flags().BoolVar(&yolo, "yolo", false, "Auto-approve shell commands (no confirmation)")
-	rootCmd.Flags().BoolVar(&sandbox, "sandbox", false, "Restrict file writes to working directory")
+	rootCmd.Flags().BoolVar(&yolo, "yolo", false, "Auto-approve tool calls (no confirmation)")
+	rootCmd.Flags().StringVar(&sandbox, "sandbox", "", "Restrict file writes to working directory (=docker or =podman: also run shell commands in that container)")
+	rootCmd.Flags().Lookup("sandbox").NoOptDefVal = "true"
+	rootCmd.Flags().StringVar(&sandboxRuntime, "sandbox-runtime", "", "Same as --sandbox=docker|podman")
+	rootCmd.Flags().StringVar(&sandboxImage, "sandbox-image", os.Getenv("G_SANDBOX_IMAGE"), "Container image for --sandbox=docker|podman (default "+sandboxpkg.DefaultImage+")")
+	rootCmd.Flags().BoolVar(&sandboxNetwork, "sandbox-network", false, "Allow network access inside the sandbox container")
 	rootCmd.Flags().BoolVar(&noAgent, "no-agent", false, "Disable agent mode (single-turn, no tools)")
+	rootCmd.Flags().StringVarP(&resume, "resume", "r", "", "Resume a saved session by ID, ID prefix, or \"latest\"")
+	rootCmd.Flags().BoolVar(&grounding, "grounding", false, "Answer from Google Search with citations (disables local tools)")
+	rootCmd.Flags().BoolVar(&urlContext, "url-context", false, "Let the API fetch URLs in the prompt itself (disables local tools)")
+	rootCmd.Flags().BoolVar(&codeExecution, "code-execution", false, "Let the model run Python in the API's sandbox (disables local tools)")
+	rootCmd.Flags().IntVar(&candidates, "candidates", 1, "Generate several answers in one request (implies --no-agent)")
+	rootCmd.Flags().StringVar(&pick, "pick", "all", "With --candidates: all, shortest, longest or best (model-ranked)")
+	rootCmd.Flags().StringVar(&agentName, "agent", "", "Run as a custom agent from .gemini/agents/<name>.md")
+	rootCmd.Flags().StringVar(&approvalWebhook, "approval-webhook", "", "POST tool approvals and ask_user questions to this URL and wait for the reply")
+	rootCmd.Flags().StringVar(&approvalFIFO, "approval-fifo", "", "Print tool approvals and ask_user questions to stderr and read replies from this named pipe")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", os.Getenv("G_OFFLINE") != "", "Make no network requests; commands that need the model API fail at once (or G_OFFLINE)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if offline {
+			goOffline()
+			cmd.SilenceUsage = true // failures are about the network, not usage
+		}
+		// Errors loading the settings are reported by the commands using them
+		if cfg, err := config.Load(); err == nil {
+			if err := applyModelSettings(cfg); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if err := applyContextSettings(cfg); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+		}
+		return nil
+	}
+	rootCmd.PersistentFlags().StringVar(&projectOverride, "project", os.Getenv("G_PROJECT"), "Google Cloud project for Code Assist instead of the cached one (or G_PROJECT)")
+	rootCmd.Flags().BoolVar(&proposeEdits, "propose-edits", false, "Emit file edits as stream-json edit_proposal events instead of writing them")
+	rootCmd.Flags().StringVar(&fallbackModel, "fallback-model", "", "Switch to this model when the model's daily quota is exhausted")
+	rootCmd.Flags().StringVar(&thinking, "thinking", "", "Reasoning effort: off, low, medium, high (default: the model's own)")
+	rootCmd.Flags().StringVar(&toolDeclarationMode, "tool-declarations", "", "How tools are described to the model: full or compact (default: tools.declarations from settings)")
+	rootCmd.Flags().BoolVar(&selectMCPTools, "select-mcp-tools", false, "Send MCP tool declarations only with prompts that plausibly need them (or tools.selectMcpTools)")
+	rootCmd.Flags().BoolVar(&worktreeMode, "worktree", false, "Run in a new git worktree and branch, then merge, keep or discard the changes")
 }
 
 // Execute runs the root command
@@ -116,10 +213,44 @@ func run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create formatter
-	formatter, err := output.NewFormatter(outputFormat, os.Stdout, os.Stderr, sanitize)
+	var (
+		formatter output.Formatter
+		err       error
+	)
+	switch {
+	case extractMode != "" && outputFormat != "text":
+		return fmt.Errorf("--extract requires --output-format text")
+	case extractMode != "":
+		formatter, err = output.NewExtractFormatter(extractMode, os.Stdout, os.Stderr, sanitize)
+	default:
+		formatter, err = output.NewFormatter(outputFormat, os.Stdout, os.Stderr, sanitize)
+	}
 	if err != nil {
 		return err
 	}
+	if _, err := tools.Declarations(toolDeclarationMode, nil); err != nil {
+		formatter.WriteError(err)
+		return err
+	}
+	if eventStream != "" {
+		events, err := eventWriter(eventStream)
+		if err != nil {
+			return err
+		}
+		if f, ok := formatter.(interface{ SetEventWriter(io.Writer) }); ok {
+			f.SetEventWriter(events)
+		}
+	}
+	if len(streamInclude) > 0 {
+		f, ok := formatter.(interface{ SetInclude([]string) error })
+		if !ok {
+			return fmt.Errorf("--stream-include requires --output-format stream-json")
+		}
+		if err := f.SetInclude(streamInclude); err != nil {
+			formatter.WriteError(err)
+			return err
+		}
+	}
 
 	// Load config
 	cfg, err := config.Load()
@@ -128,37 +259,101 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Load credentials
-	authMgr, err := auth.NewManager()
-	if err != nil {
-		formatter.WriteError(fmt.Errorf("failed to initialize auth: %w", err))
+	apiTools := builtinTools()
+	if len(apiTools) > 0 && strings.HasPrefix(model, anthropic.ModelPrefix) {
+		err := fmt.Errorf("--grounding, --url-context and --code-execution require a Gemini model")
+		formatter.WriteError(err)
 		return err
 	}
 
-	creds, err := authMgr.LoadCredentials()
-	if err != nil {
+	// Headless runs can route approvals and questions to a human elsewhere
+	var remoteApprover *approval.Remote
+	switch {
+	case approvalWebhook != "" && approvalFIFO != "":
+		err := fmt.Errorf("use only one of --approval-webhook and --approval-fifo")
 		formatter.WriteError(err)
 		return err
+	case approvalWebhook != "":
+		remoteApprover = approval.NewWebhook(approvalWebhook)
+	case approvalFIFO != "":
+		remoteApprover = approval.NewFIFO(approvalFIFO, os.Stderr)
 	}
 
-	// Refresh if expired
-	if creds.IsExpired() {
-		if debug {
-			fmt.Fprintln(os.Stderr, "Token expired, refreshing...")
+	// Edits go to an editor's review UI instead of the disk
+	var proposeEdit tools.ProposeEditFunc
+	if proposeEdits {
+		sf, ok := formatter.(*output.StreamJSONFormatter)
+		if !ok {
+			err := fmt.Errorf("--propose-edits requires --output-format stream-json")
+			formatter.WriteError(err)
+			return err
 		}
-		creds, err = authMgr.RefreshToken(creds)
-		if err != nil {
+		proposeEdit = func(ctx context.Context, p tools.EditProposal) error {
+			return sf.WriteEditProposal(p.FilePath, p.OldContent, p.NewContent)
+		}
+	}
+
+	restrictWrites, containerRuntime, err := sandboxMode(sandbox, sandboxRuntime)
+	if err != nil {
+		formatter.WriteError(err)
+		return err
+	}
+
+	// A custom agent brings its own prompt, tools and possibly model
+	var activeAgent *subagent.Definition
+	if agentName != "" {
+		cwd, _ := os.Getwd()
+		if activeAgent, err = subagent.Find(cwd, agentName); err != nil {
 			formatter.WriteError(err)
 			return err
 		}
+		if activeAgent.Model != "" && !cmd.Flags().Changed("model") {
+			model = activeAgent.Model
+		}
+	}
+
+	pickMode, err := bestof.ParseMode(pick)
+	if err == nil && candidates > 1 {
+		switch {
+		case candidates > 8:
+			err = fmt.Errorf("--candidates must be at most 8")
+		case strings.HasPrefix(model, anthropic.ModelPrefix):
+			err = fmt.Errorf("--candidates requires a Gemini model")
+		case outputFormat == "stream-json":
+			err = fmt.Errorf("--candidates supports text and json output")
+		}
+		noAgent = true
+	}
+	if err != nil {
+		formatter.WriteError(err)
+		return err
+	}
+
+	// Load credentials for the model's provider
+	models := []string{model}
+	if fallbackModel != "" {
+		models = append(models, fallbackModel)
+	}
+	be, err := newBackend(models...)
+	if err != nil {
+		formatter.WriteError(err)
+		return err
+	}
+	if fallbackModel != "" {
+		be.provider = &api.Fallback{Provider: be.provider, Model: fallbackModel}
 	}
 
 	// Prepare input
-	inputText, err := input.PrepareInput(prompt_, files)
+	inputText, sources, err := input.PrepareInput(prompt_, files)
 	if err != nil {
 		formatter.WriteError(err)
 		return err
 	}
+	if err := checkInputSize(sources, outputFormat == "text"); err != nil {
+		cmd.SilenceUsage = true
+		formatter.WriteError(err)
+		return err
+	}
 
 	// Determine mode: REPL if no input and no files provided
 	isREPL := inputText == "" && len(files) == 0
@@ -169,18 +364,113 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Bounds on what the edit tools may change in this run
+	var changes *tools.ChangeBudget
+	if maxChangedFiles != 0 || maxChangedBytes != "" {
+		var maxBytes int64
+		if maxChangedBytes != "" {
+			if maxBytes, err = strconv.ParseInt(maxChangedBytes, 10, 64); err != nil {
+				if maxBytes, err = parseSize(maxChangedBytes); err != nil {
+					err = fmt.Errorf("--max-changed-bytes: %w", err)
+				}
+			}
+		}
+		if err == nil && (maxChangedFiles < 0 || maxBytes < 0) {
+			err = fmt.Errorf("--max-changed-files and --max-changed-bytes must not be negative")
+		}
+		if err != nil {
+			formatter.WriteError(err)
+			return err
+		}
+		changes = tools.NewChangeBudget(maxChangedFiles, maxBytes)
+	}
+
+	// A check that must pass before the agent is done
+	vf, err := newVerifier(cfg, verifyCommand, verifyRetries, cmd.Flags().Changed("verify-retries"))
+	if err == nil && vf != nil && (isREPL || noAgent) {
+		err = fmt.Errorf("--verify needs a prompt and agent mode")
+	}
+	if err != nil {
+		formatter.WriteError(err)
+		return err
+	}
+
+	// Make changes on a separate branch that can be merged or discarded
+	var wt *worktree.Worktree
+	if worktreeMode {
+		w, origDir, err := startWorktree()
+		if err != nil {
+			formatter.WriteError(err)
+			return err
+		}
+		wt = w
+		defer finishWorktree(wt, origDir)
+	}
+
+	// Persist the conversation so it can be resumed with --resume
+	sess, err := openSession(cfg, resume)
+	if err != nil {
+		formatter.WriteError(err)
+		return err
+	}
+
 	// State for lazy initialization
 	var (
-		apiClient  *api.Client
-		projectID  string
-		agentLoop  *agent.Loop
-		mcpClients agent.MCPClients
-		registry   *tools.Registry
-		isInit     bool
-		req        *api.GenerateRequest
+		agentLoop *agent.Loop
+		registry  *tools.Registry
+		isInit    bool
+		req       *api.GenerateRequest
+
+		contextFiles []string // memory and extension files in the system instruction
+		promptDumped bool
+		selectTools  func() // leaves out MCP tools the prompt does not need
 	)
 
-	// Lazy initialization function
+	// MCP servers live for the whole session (reused across REPL turns)
+	// and are shut down when run returns.
+	var cleanup sessionCleanup
+	defer cleanup.run()
+
+	// Old tool results are shortened once the conversation outgrows this
+	historyTokens := history.Budget(api.Model(model).ContextWindow)
+	if cfg != nil && cfg.History.MaxTokens > 0 {
+		historyTokens = cfg.History.MaxTokens
+	}
+	hist := history.New(historyTokens)
+
+	// Generate a simple user prompt ID
+	userPromptID := fmt.Sprintf("g-%d", time.Now().UnixNano())
+
+	// A single-prompt agent run ends with what it changed, ran and used
+	if !isREPL && !noAgent {
+		cwd, _ := os.Getwd()
+		watcher := filewatch.New(cwd)
+		collect := func() *summary.Run {
+			if agentLoop == nil {
+				return nil
+			}
+			s := agentLoop.Summary()
+			changes := watcher.Diff()
+			s.Files = summary.Files(cwd, changes)
+			s.FilesIncomplete = changes.Incomplete
+			return &s
+		}
+		watchSummary(formatter, collect)
+		defer reportSummary(formatter, collect)
+	}
+
+	// Reports and exports go to the session's artifacts directory, listed
+	// when the run ends
+	artifactsDir := artifacts.Dir(userPromptID)
+	if sess != nil {
+		artifactsDir = artifacts.Dir(sess.ID)
+	}
+	watchArtifacts(formatter, artifactsDir)
+	defer reportArtifacts(formatter, artifactsDir)
+
+	// Lazy initialization function. Independent phases (Code Assist
+	// project lookup, extension loading and MCP startup, the sandbox
+	// container, skill discovery) run concurrently.
 	initialize := func(ctx context.Context) error {
 		if isInit {
 			return nil // Already initialized
@@ -189,95 +479,62 @@ func run(cmd *cobra.Command, args []string) error {
 		if debug {
 			fmt.Fprintln(os.Stderr, "Initializing backend...")
 		}
-
-		// Create API client
-		httpClient := authMgr.HTTPClient(creds)
-		apiClient = api.NewClient(httpClient)
-
-		// Try to load cached project ID first
-		cachedState, _ := config.LoadCachedState()
-		projectID = cachedState.ProjectID
-
-		// If no cached project ID, fetch from API
-		if projectID == "" {
-			if debug {
-				fmt.Fprintln(os.Stderr, "Loading Code Assist status...")
-			}
-			loadResp, err := apiClient.LoadCodeAssist(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to load Code Assist: %w", err)
-			}
-			projectID = loadResp.CloudAICompanionProject
-
-			if projectID == "" {
-				if len(loadResp.IneligibleTiers) > 0 {
-					var reasons []string
-					for _, tier := range loadResp.IneligibleTiers {
-						if tier.ReasonMessage != "" {
-							reasons = append(reasons, tier.ReasonMessage)
-						}
-					}
-					if len(reasons) > 0 {
-						return fmt.Errorf("unable to use Gemini: %s", strings.Join(reasons, ", "))
-					}
+		// What this attempt starts is handed over to the session when it
+		// succeeds, and stopped when it fails so a retry starts afresh
+		var (
+			mcpManager *mcp.Manager
+			container  *sandboxpkg.Container
+		)
+		defer func() {
+			stop := func() {
+				if mcpManager != nil {
+					mcpManager.Close()
+				}
+				if container != nil {
+					container.Close()
 				}
-				return fmt.Errorf("unable to use Gemini: no project ID available. Please run 'gemini' to set up your account")
-			}
-
-			// Cache the project ID
-			userTier := ""
-			if loadResp.CurrentTier != nil {
-				userTier = loadResp.CurrentTier.ID
 			}
-			_ = config.SaveCachedState(&config.CachedState{
-				ProjectID: projectID,
-				UserTier:  userTier,
-			})
-			if debug {
-				fmt.Fprintf(os.Stderr, "Project ID: %s (cached)\n", projectID)
+			if isInit {
+				cleanup.add(stop)
+			} else {
+				stop()
 			}
-		} else if debug {
-			fmt.Fprintf(os.Stderr, "Using cached Project ID: %s\n", projectID)
-		}
+		}()
+
+		timer := newPhaseTimer()
+		g, gctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			defer timer.track("connect")()
+			return be.connect(gctx)
+		})
 
 		// --- Agent Setup ---
 		if !noAgent {
-			// Web search callback
-			webSearchFn := func(ctx context.Context, query string) (string, []tools.WebSource, error) {
-				resp, err := apiClient.WebSearch(ctx, projectID, model, query)
-				if err != nil {
-					return "", nil, err
-				}
-				var text string
-				var sources []tools.WebSource
-				if len(resp.Response.Candidates) > 0 {
-					cand := resp.Response.Candidates[0]
-					for _, part := range cand.Content.Parts {
-						text += part.Text
-					}
-					if cand.GroundingMetadata != nil {
-						for _, chunk := range cand.GroundingMetadata.GroundingChunks {
-							if chunk.Web != nil {
-								sources = append(sources, tools.WebSource{
-									Title: chunk.Web.Title,
-									URI:   chunk.Web.URI,
-								})
-							}
-						}
-					}
-				}
-				return text, sources, nil
-			}
-
 			// Get working directory for extensions
 			workDir, _ := os.Getwd()
 
-			// Load extensions
-			extensions, extErr := extension.LoadAll(workDir)
-			if extErr != nil && debug {
-				fmt.Fprintf(os.Stderr, "[ext] failed to load extensions: %v\n", extErr)
-			}
+			// Network egress policy
+			var networkPolicy tools.NetworkPolicy
 			if cfg != nil {
+				networkPolicy = tools.NetworkPolicy{
+					Disabled:     cfg.Network.Disabled,
+					AllowedHosts: cfg.Network.AllowedHosts,
+				}
+			}
+
+			// Extensions contribute MCP servers, so MCP starts after them
+			var extensions []extension.Extension
+			g.Go(func() error {
+				stop := timer.track("extensions")
+				var extErr error
+				extensions, extErr = extension.LoadAll(workDir)
+				if extErr != nil && debug {
+					fmt.Fprintf(os.Stderr, "[ext] failed to load extensions: %v\n", extErr)
+				}
+				stop()
+				if cfg == nil {
+					return nil
+				}
 				for _, ext := range extensions {
 					for serverName, serverCfg := range ext.MCPServers {
 						if _, exists := cfg.MCPServers[serverName]; !exists {
@@ -285,55 +542,174 @@ func run(cmd *cobra.Command, args []string) error {
 						}
 					}
 				}
-			}
 
-			// Registry
-			registry = tools.NewRegistry(tools.RegistryOptions{
-				WorkDir:     workDir,
-				AutoApprove: yolo,
-				Sandbox:     sandbox,
-				Debug:       debug,
-				WebSearch:   webSearchFn,
+				// MCP servers (started in parallel; lazy servers deferred)
+				defer timer.track("mcp")()
+				warnUntrustedMCPServers(cfg)
+				servers := make(map[string]config.MCPServerConfig, len(cfg.MCPServers))
+				for name, serverCfg := range cfg.MCPServers {
+					remote := serverCfg.HTTPURL
+					if remote == "" {
+						remote = serverCfg.URL
+					}
+					if remote != "" && networkPolicy.Restricted() {
+						if err := networkPolicy.CheckURL(remote); err != nil {
+							fmt.Fprintf(os.Stderr, "Warning: MCP server %s skipped: %v\n", name, err)
+							continue
+						}
+					}
+					servers[name] = serverCfg
+				}
+				mcpManager = mcp.NewManager(servers, mcp.ManagerOptions{Debug: debug})
+				mcpManager.Start(gctx)
+				return nil
 			})
 
-			// MCP Clients
-			mcpClients = make(agent.MCPClients)
-			var mcpDecls []api.FunctionDecl
+			// Container sandbox for shell commands
+			if containerRuntime != "" {
+				g.Go(func() error {
+					defer timer.track("sandbox")()
+					c, err := sandboxpkg.New(sandboxpkg.Options{
+						Runtime: containerRuntime,
+						Image:   sandboxImage,
+						WorkDir: workDir,
+						Network: sandboxNetwork,
+						Debug:   debug,
+					})
+					container = c
+					return err
+				})
+			}
 
-			if cfg != nil {
-				for serverName, serverCfg := range cfg.MCPServers {
-					if serverCfg.Command == "" {
-						continue
+			// Skills the model can load with activate_skill
+			var availableSkills []skills.Skill
+			g.Go(func() error {
+				defer timer.track("skills")()
+				var skillProblems []skills.Problem
+				availableSkills, skillProblems = skills.Discover(workDir)
+				if debug {
+					for _, p := range skillProblems {
+						fmt.Fprintf(os.Stderr, "[skills] skipping invalid skill: %v\n", p)
 					}
-					client, err := mcp.NewClient(serverCfg.Command, serverCfg.Args, serverCfg.Env, serverCfg.CWD)
-					if err != nil {
-						if debug {
-							fmt.Fprintf(os.Stderr, "[mcp] failed to create client for %s: %v\n", serverName, err)
-						}
-						continue
+				}
+				return nil
+			})
+
+			// Custom agents the model can delegate to with the task tool
+			var agents []subagent.Definition
+			g.Go(func() error {
+				defer timer.track("agents")()
+				found, problems := subagent.Discover(workDir)
+				for _, d := range found {
+					if activeAgent == nil || d.Name != activeAgent.Name {
+						agents = append(agents, d)
 					}
-					if err := client.Initialize(ctx); err != nil {
-						if debug {
-							fmt.Fprintf(os.Stderr, "[mcp] failed to initialize %s: %v\n", serverName, err)
-						}
-						client.Close()
-						continue
+				}
+				if debug {
+					for _, p := range problems {
+						fmt.Fprintf(os.Stderr, "[agents] skipping invalid agent: %v\n", p)
+					}
+				}
+				return nil
+			})
+
+			// Executable tools in tools.d
+			var plugins []plugin.Plugin
+			g.Go(func() error {
+				defer timer.track("plugins")()
+				dir, err := plugin.Dir()
+				if err != nil {
+					return nil
+				}
+				var problems []plugin.Problem
+				plugins, problems = plugin.Discover(dir)
+				if debug {
+					for _, p := range problems {
+						fmt.Fprintf(os.Stderr, "[plugins] skipping tool: %v\n", p)
 					}
-					mcpClients[serverName] = client
-					// We can't defer close here easily, so we rely on process exit or explicit close if we add shutdown logic
-
-					for _, tool := range client.Tools {
-						prefixedName := serverName + "__" + tool.Name
-						registry.RegisterMCPTool(serverName, prefixedName)
-						mcpDecls = append(mcpDecls, api.FunctionDecl{
-							Name:        prefixedName,
+				}
+				return nil
+			})
+
+			if err := g.Wait(); err != nil {
+				return err
+			}
+
+			// Registry (web search needs the project from connect)
+			registryOpts := tools.RegistryOptions{
+				WorkDir:      workDir,
+				AutoApprove:  yolo,
+				Sandbox:      restrictWrites,
+				Container:    container,
+				WindowsShell: windowsShell(cfg),
+				Network:      networkPolicy,
+				Debug:        debug,
+				WebSearch:    be.webSearch(model),
+				Limits:       toolLimits(cfg),
+				Timeouts:     toolTimeouts(cfg),
+				ProposeEdit:  proposeEdit,
+				ArtifactsDir: artifactsDir,
+				Changes:      changes,
+			}
+			// ask_user prompts on the terminal unless input is piped, or
+			// goes to the external approver
+			if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+				registryOpts.Ask = approval.Question
+			}
+			if remoteApprover != nil {
+				registryOpts.Ask = remoteApprover.Question
+			}
+			// Extension-declared executables, tools.d and MCP tools
+			extra := &extraTools{}
+			for _, ext := range extensions {
+				for _, tc := range ext.Tools {
+					extra.tools = append(extra.tools, extraTool{from: "[ext] " + ext.Name, tool: tools.NewExtensionTool(tools.ExtensionToolSpec{
+						Name:        tc.Name,
+						Description: tc.Description,
+						Parameters:  tc.Parameters,
+						Command:     tc.Command,
+						Args:        tc.Args,
+						Dir:         ext.Path,
+						Timeout:     time.Duration(tc.Timeout) * time.Millisecond,
+					}, registryOpts.Timeouts)})
+				}
+			}
+			for _, p := range plugins {
+				extra.tools = append(extra.tools, extraTool{from: "[plugins] " + p.Path, tool: pluginTool(p, workDir, registryOpts.Timeouts)})
+			}
+			if mcpManager != nil {
+				serverNames, serverTools := mcpManager.Tools()
+				for _, serverName := range serverNames {
+					serverCfg, _ := mcpManager.Config(serverName)
+					for _, tool := range mcp.FilterTools(serverTools[serverName], serverCfg) {
+						extra.mcp = append(extra.mcp, mcpTool{server: serverName, tool: tool.Name, decl: api.FunctionDecl{
+							Name:        mcp.ToolName(serverName, tool.Name),
 							Description: tool.Description,
 							Parameters:  json.RawMessage(tool.InputSchema),
-						})
+						}})
 					}
 				}
 			}
 
+			// A custom agent's tool list limits all of them
+			var agentTools []string
+			if activeAgent != nil {
+				agentTools = activeAgent.Tools
+			}
+			mainOpts := registryOpts
+			mainOpts.IncludeTools = agentTools
+			registry = tools.NewRegistry(mainOpts)
+
+			// The task tool, unless a custom agent's tool list leaves it out
+			var runner *subagentRunner
+			if len(agents) > 0 && (len(agentTools) == 0 || slices.Contains(agentTools, "task")) {
+				runner = newSubagentRunner(be.provider, be.projectID, model, agents, registryOpts, extra, mcpManager)
+				registry.Register(runner.tool())
+			} else {
+				agents = nil
+			}
+			mcpDecls := extra.register(registry, agentTools)
+
 			// Extension contexts
 			var extContextFiles []string
 			for _, ext := range extensions {
@@ -341,32 +717,95 @@ func run(cmd *cobra.Command, args []string) error {
 			}
 
 			// System Instruction
-			req.Request.SystemInstruction = prompt.BuildSystemInstruction(prompt.Options{
+			promptOpts := prompt.Options{
 				WorkDir:           workDir,
+				Shell:             shellName(registryOpts.WindowsShell),
 				ExtensionContexts: extContextFiles,
-			})
+				Skills:            availableSkills,
+				Agents:            agents,
+				Agent:             activeAgent,
+				ArtifactsDir:      artifactsDir,
+			}
+			req.Request.SystemInstruction = prompt.BuildSystemInstruction(promptOpts)
+			contextFiles = prompt.ContextFiles(promptOpts)
 
 			// Tools
 			allDecls := registry.AllDeclarations()
 			allDecls = append(allDecls, mcpDecls...)
-			req.Request.Tools = []api.Tool{{FunctionDeclarations: allDecls}}
+			declMode := toolDeclarationMode
+			if declMode == "" && activeAgent != nil {
+				declMode = activeAgent.Declarations
+			}
+			allDecls = toolDeclarations(cfg, declMode, allDecls)
+			// The API does not combine its own tools with function calls
+			if len(apiTools) == 0 {
+				req.Request.Tools = []api.Tool{{FunctionDeclarations: allDecls}}
+				if mcpManager != nil && (selectMCPTools || cfg != nil && cfg.Tools.SelectMCPTools) {
+					selectTools = mcpToolSelector(req, allDecls, mcpManager)
+				}
+			}
 
 			// Agent Loop
+			var allowedTools []string
+			var redactor *redact.Redactor
+			var auditLog *audit.Log
+			if cfg != nil {
+				var err error
+				if auditLog, err = openAuditLog(cfg, userPromptID); err != nil {
+					return err
+				}
+				if redactor, err = newRedactor(cfg); err != nil {
+					return err
+				}
+				allowedTools = cfg.Tools.Allowed
+			}
+			if remoteApprover != nil {
+				remoteApprover.Redactor = redactor
+			}
+			var turnOutputTokens int
+			if cfg != nil {
+				turnOutputTokens = cfg.Tools.Limits.TurnOutputTokens
+			}
+			var prompter approval.Prompter = approval.NewTTYPrompter()
+			if remoteApprover != nil {
+				prompter = remoteApprover
+			}
 			streaming := outputFormat != "json"
-			agentLoop = agent.NewLoop(apiClient, registry, mcpClients, formatter, agent.Config{
-				MaxTurns:  maxTurns,
-				Streaming: streaming,
-				Debug:     debug,
-			})
+			loopConfig := agent.Config{
+				MaxTurns:         maxTurns,
+				Streaming:        streaming,
+				Debug:            debug,
+				Redactor:         redactor,
+				Audit:            auditLog,
+				History:          hist,
+				ToolOutputTokens: turnOutputTokens,
+				AutoApprove:      yolo,
+				AllowedTools:     allowedTools,
+				Prompter:         prompter,
+				PersistAllow: func(name string) error {
+					path, err := config.SettingsPath("user")
+					if err != nil {
+						return err
+					}
+					return config.AllowTool(path, name)
+				},
+			}
+			if runner != nil {
+				runner.config = loopConfig
+				if cfg != nil {
+					runner.declarations = cfg.Tools.Declarations
+				}
+			}
+			agentLoop = agent.NewLoop(be.provider, registry, mcpManager, formatter, loopConfig)
+		} else if err := g.Wait(); err != nil {
+			return err
 		}
+		timer.report()
 
 		isInit = true
 		return nil
 	}
 
-	// Generate a simple user prompt ID
-	userPromptID := fmt.Sprintf("g-%d", time.Now().UnixNano())
-
 	// Build base request
 	req = &api.GenerateRequest{
 		Model:        model,
@@ -377,34 +816,135 @@ func run(cmd *cobra.Command, args []string) error {
 			Config: api.GenerationConfig{
 				Temperature:     1.0,
 				TopP:            0.95,
-				MaxOutputTokens: 65536,
+				MaxOutputTokens: api.Model(model).MaxOutputTokens,
 			},
 		},
 	}
+	if len(apiTools) > 0 {
+		req.Request.Tools = apiTools
+	}
+	if candidates > 1 {
+		req.Request.Config.CandidateCount = candidates
+	}
+	if req.Request.Config.ThinkingConfig, err = api.Thinking(model, thinking); err != nil {
+		formatter.WriteError(err)
+		return err
+	}
 
-	// Execution Logic
-	runTurn := func(ctx context.Context) error {
-		// Ensure initialized
-		if !isInit {
-			if err := initialize(ctx); err != nil {
-				return err
-			}
-			// Update project ID in request
-			req.Project = projectID
-		}
+	if sess != nil {
+		req.Request.Contents = append(req.Request.Contents, sess.Contents...)
+	}
 
+	// Execution Logic
+	generate := func(ctx context.Context) error {
 		if !noAgent {
 			return agentLoop.Run(ctx, req)
 		}
 
 		// Legacy mode
+		ctx = api.WithProgress(ctx, func(stage, detail string) {
+			formatter.WriteProgress(stage, detail)
+		})
+		if n := hist.Fit(req.Request.Contents); n > 0 {
+			formatter.WriteProgress("history", fmt.Sprintf("shortened %d old parts of the conversation to fit the context budget", n))
+		}
+		if candidates > 1 {
+			return runCandidates(ctx, be.provider, req, formatter, pickMode)
+		}
 		switch outputFormat {
 		case "json":
-			return runNonStreaming(ctx, apiClient, req, formatter)
+			return runNonStreaming(ctx, be.provider, req, formatter)
 		default:
-			return runStreaming(ctx, apiClient, req, formatter)
+			return runStreaming(ctx, be.provider, req, formatter)
 		}
 	}
+	// Initialization runs in the background so that in the REPL it overlaps
+	// with the user typing; each turn waits for it. A failed attempt is
+	// retried by the next turn.
+	type initAttempt struct {
+		done chan struct{}
+		err  error
+	}
+	var (
+		initMu  sync.Mutex
+		attempt *initAttempt
+	)
+	startInit := func() *initAttempt {
+		initMu.Lock()
+		defer initMu.Unlock()
+		if attempt != nil {
+			select {
+			case <-attempt.done:
+				if attempt.err == nil {
+					return attempt
+				}
+			default:
+				return attempt
+			}
+		}
+		a := &initAttempt{done: make(chan struct{})}
+		attempt = a
+		go func() {
+			a.err = initialize(ctx)
+			close(a.done)
+		}()
+		return a
+	}
+	runTurn := func(turnCtx context.Context) error {
+		// Ensure initialized, explaining the wait if it is noticeable
+		a := startInit()
+		select {
+		case <-a.done:
+		case <-time.After(time.Second):
+			formatter.WriteProgress("init", "waiting for the API connection and MCP servers")
+			select {
+			case <-a.done:
+			case <-turnCtx.Done():
+				if errors.Is(turnCtx.Err(), context.Canceled) {
+					writeCancelled(formatter)
+				}
+				return turnCtx.Err()
+			}
+		}
+		if a.err != nil {
+			return a.err
+		}
+		// Update project ID in request
+		req.Project = be.projectID
+		if selectTools != nil {
+			selectTools()
+		}
+
+		if dumpPromptPath != "" && !promptDumped {
+			promptDumped = true
+			if err := dumpPrompt(dumpPromptPath, req, contextFiles); err != nil {
+				return err
+			}
+		}
+
+		err := generate(turnCtx)
+		if err != nil && errors.Is(turnCtx.Err(), context.Canceled) {
+			writeCancelled(formatter)
+		}
+		if ef, ok := formatter.(*output.ExtractFormatter); ok && err == nil {
+			if err = ef.Flush(); err != nil {
+				cmd.SilenceUsage = true
+			}
+		}
+		var apiErr *api.APIError
+		if debug && errors.As(err, &apiErr) {
+			fmt.Fprintf(os.Stderr, "[api] error response: %s\n", apiErr.Body)
+		}
+		if sess != nil {
+			if saveErr := sess.Save(req.Request.Contents); saveErr != nil && debug {
+				fmt.Fprintf(os.Stderr, "[session] failed to save %s: %v\n", sess.ID, saveErr)
+			}
+		}
+		if wt != nil {
+			checkpointWorktree(wt, req.Request.Contents)
+		}
+		return err
+	}
 
 	if isREPL {
 		// Check home directory warning
@@ -428,24 +968,61 @@ func run(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "%d %s\n\n", len(files), fileLabel)
 		}
 
-		// use readline
+		// Start connecting and loading tools while the user types
+		startInit()
+
+		// use readline, with a placeholder, slash command completion and
+		// the model and approval mode drawn into the line
+		hints := &replHints{mode: "confirm tools"}
+		if yolo {
+			hints.mode = "yolo"
+		}
 		rl, err := readline.NewEx(&readline.Config{
-			Prompt:          "> ",
+			Prompt:          replPrompt,
 			HistoryFile:     filepath.Join(os.TempDir(), "gmn_history"),
 			InterruptPrompt: "^C",
 			EOFPrompt:       "exit",
+			Painter:         hints,
+			AutoComplete:    hints,
+			Listener:        hints,
 		})
 		if err != nil {
 			return err
 		}
 		defer rl.Close()
 
-		// Placeholder hint (simulated)
-		// readline doesn't support placeholder text easily without prompt manipulation,
-		// but we can print a dim instruction once
-		fmt.Fprintln(os.Stderr, "\033[2mType your message or @path/to/file\033[0m")
+		// Unblock Readline on SIGINT/SIGTERM so deferred cleanup runs
+		go func() {
+			<-ctx.Done()
+			rl.Close()
+		}()
+
+		// Notice files the user edits between turns
+		cwd, _ := os.Getwd()
+		watcher := filewatch.New(cwd)
+
+		// Sessions that found out how the project is built or tested end
+		// with an offer to note it in GEMINI.md
+		refreshed := false
+		defer func() {
+			if refreshed || agentLoop == nil || ctx.Err() != nil || !learnedProject(agentLoop.Summary()) {
+				return
+			}
+			question := fmt.Sprintf("This session ran the project's build or test commands. Propose notes for %s? [y/N] ", filepath.Base(memory.ProjectFile(cwd)))
+			if !replConfirm(rl)(question) {
+				return
+			}
+			turnCtx, turnCancel := context.WithTimeout(context.Background(), timeout)
+			defer turnCancel()
+			if err := refreshMemory(turnCtx, be.provider, req, cwd, replConfirm(rl)); err != nil {
+				formatter.WriteError(err)
+			}
+		}()
 
+		var lastPrompt string
 		for {
+			hints.model.Store(req.Model) // a quota fallback may have switched it
+			readStart := time.Now()
 			line, err := rl.Readline()
 			if err != nil {
 				// EOF or Ctrl+C
@@ -455,20 +1032,77 @@ func run(cmd *cobra.Command, args []string) error {
 			if line == "" {
 				continue
 			}
-			if line == "exit" || line == "quit" {
+			if line == "exit" || line == "quit" || line == "/exit" || line == "/quit" {
 				break
 			}
+			if line == "/think" || strings.HasPrefix(line, "/think ") {
+				thinkCommand(strings.TrimSpace(strings.TrimPrefix(line, "/think")), req)
+				continue
+			}
+			if line == "/pin" || line == "/unpin" {
+				pinCommand(line, hist, req.Request.Contents)
+				continue
+			}
+			if line == "/memory refresh" {
+				a := startInit()
+				<-a.done
+				if a.err != nil {
+					formatter.WriteError(a.err)
+					continue
+				}
+				req.Project = be.projectID
+				turnCtx, turnCancel := context.WithTimeout(context.Background(), timeout)
+				if err := refreshMemory(turnCtx, be.provider, req, cwd, replConfirm(rl)); err != nil {
+					formatter.WriteError(err)
+				}
+				turnCancel()
+				refreshed = true
+				continue
+			}
+			if line == "/compress" {
+				a := startInit()
+				<-a.done
+				if a.err != nil {
+					formatter.WriteError(a.err)
+					continue
+				}
+				req.Project = be.projectID
+				turnCtx, turnCancel := context.WithTimeout(context.Background(), timeout)
+				if compressCommand(turnCtx, rl, be.provider, req, compressModel(cfg, req.Model)) {
+					hist.UnpinAll()
+					if sess != nil {
+						if saveErr := sess.Save(req.Request.Contents); saveErr != nil && debug {
+							fmt.Fprintf(os.Stderr, "[session] failed to save %s: %v\n", sess.ID, saveErr)
+						}
+					}
+				}
+				turnCancel()
+				continue
+			}
+
+			// The same prompt again right away is most likely a paste
+			// glitch or a repeated Enter, and may cost a whole agent run
+			if !resendPrompt(line, lastPrompt, time.Since(readStart), replConfirm(rl)) {
+				continue
+			}
+			lastPrompt = line
 
-			// Add user input to context
+			// Add user input to context, after a note on files changed
+			// outside the agent
+			parts := []api.Part{{Text: line}}
+			if note := watcher.Changes().Note(); note != "" {
+				parts = append([]api.Part{{Text: note}}, parts...)
+			}
 			req.Request.Contents = append(req.Request.Contents, api.Content{
 				Role:  "user",
-				Parts: []api.Part{{Text: line}},
+				Parts: parts,
 			})
 
 			// Create a per-turn context with timeout
 			turnCtx, turnCancel := context.WithTimeout(context.Background(), timeout)
 			err = runTurn(turnCtx)
 			turnCancel()
+			watcher.Snapshot()
 
 			if err != nil {
 				formatter.WriteError(err)
@@ -493,10 +1127,41 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no input provided")
 	}
 
-	return runTurn(ctx)
+	if err := runTurn(ctx); err != nil || vf == nil {
+		return err
+	}
+	// Hand a failing check back to the agent until it passes or the
+	// retries are used up
+	for attempt := 0; ; attempt++ {
+		formatter.WriteProgress("verify", "running "+vf.command)
+		code, out, err := vf.run(ctx)
+		if err != nil {
+			cmd.SilenceUsage = true
+			formatter.WriteError(err)
+			return err
+		}
+		if code == 0 {
+			formatter.WriteProgress("verify", "passed")
+			return nil
+		}
+		if attempt == vf.retries {
+			cmd.SilenceUsage = true
+			err := fmt.Errorf("verification still failing after %d retries: %s exited with code %d", vf.retries, vf.command, code)
+			formatter.WriteError(err)
+			return err
+		}
+		formatter.WriteProgress("verify", fmt.Sprintf("failed with exit code %d; handing the output back to the agent (retry %d of %d)", code, attempt+1, vf.retries))
+		req.Request.Contents = append(req.Request.Contents, api.Content{
+			Role:  "user",
+			Parts: []api.Part{{Text: vf.feedback(code, out)}},
+		})
+		if err := runTurn(ctx); err != nil {
+			return err
+		}
+	}
 }
 
-func runNonStreaming(ctx context.Context, client *api.Client, req *api.GenerateRequest, formatter output.Formatter) error {
+func runNonStreaming(ctx context.Context, client api.Provider, req *api.GenerateRequest, formatter output.Formatter) error {
 	resp, err := client.Generate(ctx, req)
 	if err != nil {
 		formatter.WriteError(err)
@@ -505,7 +1170,41 @@ func runNonStreaming(ctx context.Context, client *api.Client, req *api.GenerateR
 	return formatter.WriteResponse(resp)
 }
 
-func runStreaming(ctx context.Context, client *api.Client, req *api.GenerateRequest, formatter output.Formatter) error {
+// runCandidates requests several candidates and shows all of them, or the
+// one chosen by mode.
+func runCandidates(ctx context.Context, client api.Provider, req *api.GenerateRequest, formatter output.Formatter, mode bestof.Mode) error {
+	resp, err := client.Generate(ctx, req)
+	if err != nil {
+		formatter.WriteError(err)
+		return err
+	}
+	cands := resp.Response.Candidates
+	if mode == bestof.All {
+		if outputFormat == "json" || len(cands) < 2 {
+			return formatter.WriteResponse(resp)
+		}
+		for i, c := range cands {
+			one := *resp
+			one.Response.Candidates = []api.Candidate{c}
+			fmt.Fprintf(os.Stderr, "\033[2m── %d/%d ──\033[0m\n", i+1, len(cands))
+			if err := formatter.WriteResponse(&one); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	i, err := bestof.Select(ctx, client, req, bestof.Texts(resp), mode)
+	if err != nil {
+		formatter.WriteError(err)
+		return err
+	}
+	one := *resp
+	one.Response.Candidates = []api.Candidate{cands[i]}
+	return formatter.WriteResponse(&one)
+}
+
+func runStreaming(ctx context.Context, client api.Provider, req *api.GenerateRequest, formatter output.Formatter) error {
 	stream, err := client.GenerateStream(ctx, req)
 	if err != nil {
 		formatter.WriteError(err)
@@ -524,3 +1223,180 @@ func runStreaming(ctx context.Context, client *api.Client, req *api.GenerateRequ
 
 	return nil
 }
+
+// writeCancelled ends the output of a turn that was cancelled: partial
+// text is finished, stream-json gets a "cancelled" event and JSON output a
+// response marked cancelled.
+func writeCancelled(formatter output.Formatter) {
+	if w, ok := formatter.(interface{ WriteCancelled() error }); ok {
+		w.WriteCancelled()
+	}
+}
+
+// pinCommand handles the REPL's /pin and /unpin. /pin protects the latest
+// exchange, from the last typed message to the reply, from history eviction.
+func pinCommand(line string, hist *history.Manager, contents []api.Content) {
+	if line == "/unpin" {
+		hist.UnpinAll()
+		fmt.Fprintln(os.Stderr, "Unpinned all messages")
+		return
+	}
+	start := -1
+	for i := len(contents) - 1; i >= 0; i-- {
+		if c := contents[i]; c.Role == "user" && len(c.Parts) > 0 && c.Parts[0].FunctionResp == nil {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to pin yet")
+		return
+	}
+	for i := start; i < len(contents); i++ {
+		hist.Pin(i)
+	}
+	fmt.Fprintf(os.Stderr, "Pinned %d messages (%d pinned in total)\n", len(contents)-start, hist.Pinned())
+}
+
+// compressCommand handles the REPL's /compress: it summarizes the
+// conversation with model, shows the summary and, once the user confirms,
+// replaces the history with it. It reports whether the history changed.
+func compressCommand(ctx context.Context, rl *readline.Instance, p api.Provider, req *api.GenerateRequest, model string) bool {
+	if len(req.Request.Contents) < 2 {
+		fmt.Fprintln(os.Stderr, "Nothing to compress yet")
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "Summarizing the conversation with %s...\n", model)
+	summary, err := history.Summarize(ctx, p, req, model)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	before := history.EstimateTokens(req.Request.Contents)
+	compressed := history.Compressed(summary)
+	after := history.EstimateTokens(compressed)
+	fmt.Fprintf(os.Stderr, "\n%s\n\n", summary)
+	if after >= before {
+		fmt.Fprintf(os.Stderr, "The summary (~%d tokens) is not smaller than the history (~%d tokens); keeping the history\n", after, before)
+		return false
+	}
+
+	answer, err := replAsk(rl, fmt.Sprintf("Replace the history (~%d tokens) with this summary (~%d tokens)? [y/N] ", before, after))
+	if err != nil || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		fmt.Fprintln(os.Stderr, "History kept")
+		return false
+	}
+	req.Request.Contents = compressed
+	fmt.Fprintf(os.Stderr, "History compressed: ~%d → ~%d tokens (saved ~%d)\n", before, after, before-after)
+	return true
+}
+
+// sandboxMode interprets --sandbox and --sandbox-runtime: whether file
+// writes are restricted to the working directory and the container runtime
+// shell commands run in, if any. A bare --sandbox only restricts writes;
+// a runtime implies it.
+func sandboxMode(flag, runtime string) (bool, string, error) {
+	switch flag {
+	case "", "false":
+	case "true":
+		return true, runtime, nil
+	case "docker", "podman":
+		if runtime != "" && runtime != flag {
+			return false, "", fmt.Errorf("--sandbox=%s conflicts with --sandbox-runtime %s", flag, runtime)
+		}
+		runtime = flag
+	default:
+		return false, "", fmt.Errorf("unsupported --sandbox value %q (use --sandbox, --sandbox=docker or --sandbox=podman)", flag)
+	}
+	return runtime != "", runtime, nil
+}
+
+// duplicatePromptWindow is how soon after the previous turn a repeated
+// prompt needs confirming.
+const duplicatePromptWindow = 5 * time.Second
+
+// resendPrompt reports whether line, typed in waited after the previous
+// turn, should be sent: a repeat of lastPrompt within duplicatePromptWindow
+// only when confirm says so.
+func resendPrompt(line, lastPrompt string, waited time.Duration, confirm func(question string) bool) bool {
+	if line != lastPrompt || waited >= duplicatePromptWindow {
+		return true
+	}
+	return confirm("You just sent this prompt. Send it again? [y/N] ")
+}
+
+// replConfirm asks yes/no questions at the REPL prompt.
+func replConfirm(rl *readline.Instance) func(question string) bool {
+	return func(question string) bool {
+		answer, err := replAsk(rl, question)
+		return err == nil && (strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes"))
+	}
+}
+
+// compressModel returns the model /compress summarizes with: the
+// configured one, or a small model of the provider serving model.
+func compressModel(cfg *config.Config, model string) string {
+	if cfg != nil && cfg.History.CompressModel != "" {
+		return cfg.History.CompressModel
+	}
+	if strings.HasPrefix(model, anthropic.ModelPrefix) {
+		return "claude-haiku-4-5"
+	}
+	return "gemini-2.5-flash-lite"
+}
+
+// thinkCommand handles the REPL's /think, which shows or sets the reasoning
+// effort for the following turns.
+func thinkCommand(level string, req *api.GenerateRequest) {
+	if level == "" {
+		current := thinking
+		if current == "" {
+			current = "model default"
+		}
+		fmt.Fprintf(os.Stderr, "Thinking: %s (set with /think %s)\n", current, strings.Join(api.ThinkingLevels, "|"))
+		return
+	}
+	cfg, err := api.Thinking(req.Model, level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	thinking = strings.ToLower(level)
+	req.Request.Config.ThinkingConfig = cfg
+	fmt.Fprintf(os.Stderr, "Thinking: %s\n", thinking)
+}
+
+// eventWriter opens the --event-stream destination.
+func eventWriter(spec string) (io.Writer, error) {
+	switch spec {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(spec, "fd:"))
+	if !strings.HasPrefix(spec, "fd:") || err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid --event-stream %q (use stdout, stderr or fd:N)", spec)
+	}
+	f := os.NewFile(uintptr(n), "fd:"+strconv.Itoa(n))
+	if _, err := f.Stat(); err != nil {
+		return nil, fmt.Errorf("--event-stream %s: file descriptor %d is not open", spec, n)
+	}
+	return f, nil
+}
+
+// builtinTools returns the API-side tools selected by --grounding,
+// --url-context and --code-execution.
+func builtinTools() []api.Tool {
+	var out []api.Tool
+	if grounding {
+		out = append(out, api.Tool{GoogleSearch: &api.GoogleSearch{}})
+	}
+	if urlContext {
+		out = append(out, api.Tool{URLContext: &api.URLContext{}})
+	}
+	if codeExecution {
+		out = append(out, api.Tool{CodeExecution: &api.CodeExecution{}})
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResendPrompt(t *testing.T) {
+	tests := []struct {
+		name       string
+		line, last string
+		waited     time.Duration
+		answer     bool
+		want, asks bool
+	}{
+		{"repeat confirmed", "fix it", "fix it", time.Second, true, true, true},
+		{"repeat declined", "fix it", "fix it", time.Second, false, false, true},
+		{"changed prompt", "fix it again", "fix it", time.Second, false, true, false},
+		{"first prompt", "fix it", "", 0, false, true, false},
+		{"repeat later", "fix it", "fix it", duplicatePromptWindow, false, true, false},
+	}
+	for _, tt := range tests {
+		asked := false
+		got := resendPrompt(tt.line, tt.last, tt.waited, func(question string) bool {
+			asked = true
+			return tt.answer
+		})
+		if got != tt.want || asked != tt.asks {
+			t.Errorf("%s: sent %v, asked %v; want %v, %v", tt.name, got, asked, tt.want, tt.asks)
+		}
+	}
+}
+
+func TestSandboxMode(t *testing.T) {
+	for _, tt := range []struct {
+		flag, runtime string
+		restrict      bool
+		container     string
+		fails         bool
+	}{
+		{"", "", false, "", false},
+		{"true", "", true, "", false},
+		{"docker", "", true, "docker", false},
+		{"", "podman", true, "podman", false},
+		{"true", "podman", true, "podman", false},
+		{"docker", "podman", false, "", true},
+		{"lxc", "", false, "", true},
+	} {
+		restrict, container, err := sandboxMode(tt.flag, tt.runtime)
+		if restrict != tt.restrict || container != tt.container || (err != nil) != tt.fails {
+			t.Errorf("sandboxMode(%q, %q) = %v, %q, %v", tt.flag, tt.runtime, restrict, container, err)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/approval"
+	"github.com/k-sub1995/g/internal/audit"
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/k-sub1995/g/internal/ide"
+	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/prompt"
+	"github.com/k-sub1995/g/internal/redact"
+	"github.com/k-sub1995/g/internal/server"
+	"github.com/k-sub1995/g/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+const defaultServeAddr = "127.0.0.1:8080"
+
+var (
+	serveHTTP     string
+	serveIDE      bool
+	serveModel    string
+	serveTools    string
+	serveYolo     bool
+	serveToken    string
+	serveMaxTurns int
+	serveTimeout  time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve g over an OpenAI-compatible HTTP API or to editors over stdio",
+	Long: `Serve exposes g on a local HTTP server (--http):
+
+  GET  /v1/models             List models
+  POST /v1/chat/completions   OpenAI-compatible chat (streaming supported)
+  POST /v1/g/run              Run the agent, streaming text, tool calls and
+                              tool results as newline-delimited JSON
+
+Requests without client-defined tools run g's agent loop with the tools
+selected by --tools; requests that define tools get tool_calls back.
+
+Without --token the server only answers local programs: requests from web
+pages (with an Origin header), for a host name other than a loopback
+address, or with a body that is not application/json are refused.
+
+With --ide, g speaks the Agent Client Protocol (JSON-RPC over stdin and
+stdout) so editors such as Zed, Neovim and VS Code can use it as their agent.
+Each session runs in the editor's working directory with all tools, and
+file edits and shell commands are sent to the editor for approval.
+
+Examples:
+  g serve --http
+  g serve --http 127.0.0.1:9000 --tools all --token "$G_SERVE_TOKEN"
+  g serve --ide`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveHTTP, "http", "", "Listen address for the HTTP API (default "+defaultServeAddr+")")
+	serveCmd.Flags().Lookup("http").NoOptDefVal = defaultServeAddr
+	serveCmd.Flags().BoolVar(&serveIDE, "ide", false, "Serve the Agent Client Protocol over stdio for editors")
+	serveCmd.Flags().StringVarP(&serveModel, "model", "m", "gemini-2.5-flash", "Default model")
+	serveCmd.Flags().StringVar(&serveTools, "tools", "read-only", "Agent tools: none, read-only or all (default all with --ide)")
+	serveCmd.Flags().BoolVar(&serveYolo, "yolo", false, "Run tools that normally need confirmation (risky shell commands are still refused)")
+	serveCmd.Flags().StringVar(&serveToken, "token", os.Getenv("G_SERVE_TOKEN"), "Require this bearer token (or G_SERVE_TOKEN)")
+	serveCmd.Flags().IntVar(&serveMaxTurns, "max-turns", 25, "Maximum agent loop turns per request")
+	serveCmd.Flags().DurationVarP(&serveTimeout, "timeout", "t", 10*time.Minute, "Per-request timeout")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if serveHTTP == "" && !serveIDE {
+		return fmt.Errorf("choose a transport: --http [addr] or --ide")
+	}
+	if serveHTTP != "" && serveIDE {
+		return fmt.Errorf("--http and --ide cannot be combined")
+	}
+	if serveIDE && !cmd.Flags().Changed("tools") {
+		serveTools = "all"
+	}
+	switch serveTools {
+	case "none", "read-only", "all":
+	default:
+		return fmt.Errorf("unknown --tools value %q (use none, read-only or all)", serveTools)
+	}
+	if host, _, err := net.SplitHostPort(serveHTTP); err == nil && serveToken == "" {
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			fmt.Fprintf(os.Stderr, "Warning: listening on %s without --token exposes your Gemini account to the network\n", serveHTTP)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	be, err := newBackend(serveModel)
+	if err != nil {
+		return err
+	}
+	if err := be.connect(ctx); err != nil {
+		return err
+	}
+
+	var redactor *redact.Redactor
+	var auditLog *audit.Log
+	if serveTools != "none" {
+		if redactor, err = newRedactor(cfg); err != nil {
+			return err
+		}
+		if auditLog, err = openAuditLog(cfg, fmt.Sprintf("g-serve-%d", time.Now().UnixNano())); err != nil {
+			return err
+		}
+	}
+	registryOptions := func(workDir string) tools.RegistryOptions {
+		return tools.RegistryOptions{
+			WorkDir:      workDir,
+			AutoApprove:  serveYolo,
+			Sandbox:      true,
+			Debug:        debug,
+			WebSearch:    be.webSearch(serveModel),
+			WindowsShell: windowsShell(cfg),
+			Network: tools.NetworkPolicy{
+				Disabled:     cfg.Network.Disabled,
+				AllowedHosts: cfg.Network.AllowedHosts,
+			},
+			ReadOnly: serveTools == "read-only",
+			Limits:   toolLimits(cfg),
+			Timeouts: toolTimeouts(cfg),
+		}
+	}
+
+	if serveIDE {
+		return serveStdio(ctx, cfg, be, registryOptions, redactor, auditLog)
+	}
+
+	workDir, _ := os.Getwd()
+	models := api.Models(be.provider)
+	if !slices.Contains(models, serveModel) {
+		models = append([]string{serveModel}, models...)
+	}
+	opts := server.Options{
+		Client:       be.provider,
+		Project:      be.projectID,
+		DefaultModel: serveModel,
+		Models:       models,
+		Token:        serveToken,
+		Timeout:      serveTimeout,
+		Debug:        debug,
+	}
+
+	if serveTools != "none" {
+		registryOpts := registryOptions(workDir)
+		opts.SystemInstruction = prompt.BuildSystemInstruction(prompt.Options{WorkDir: workDir, Shell: shellName(registryOpts.WindowsShell)})
+		opts.NewAgent = func(f output.Formatter) (*agent.Loop, []api.FunctionDecl) {
+			registry := tools.NewRegistry(registryOpts)
+			loop := agent.NewLoop(be.provider, registry, nil, f, agent.Config{
+				MaxTurns:         serveMaxTurns,
+				Streaming:        true,
+				Debug:            debug,
+				Redactor:         redactor,
+				Audit:            auditLog,
+				AutoApprove:      serveYolo,
+				ToolOutputTokens: cfg.Tools.Limits.TurnOutputTokens,
+			})
+			return loop, toolDeclarations(cfg, "", registry.AllDeclarations())
+		}
+	}
+
+	httpServer := &http.Server{
+		Addr:              serveHTTP,
+		Handler:           server.New(opts).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "g serving on http://%s (tools: %s, model: %s)\n", serveHTTP, serveTools, serveModel)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// serveStdio runs the Agent Client Protocol on stdin and stdout. Each editor
+// session gets its own tool registry rooted at the session's directory;
+// edits and shell commands are confirmed through the editor unless --yolo.
+func serveStdio(ctx context.Context, cfg *config.Config, be *backend, registryOptions func(string) tools.RegistryOptions, redactor *redact.Redactor, auditLog *audit.Log) error {
+	var confirm []string
+	if !serveYolo {
+		confirm = []string{"write_file", "replace", "insert", "run_shell_command"}
+	}
+	srv := ide.New(ide.Options{
+		NewSession: func(cwd string, f output.Formatter, p approval.Prompter) (*agent.Loop, *api.GenerateRequest, error) {
+			registry := tools.NewRegistry(registryOptions(cwd))
+			loop := agent.NewLoop(be.provider, registry, nil, f, agent.Config{
+				MaxTurns:         serveMaxTurns,
+				Streaming:        true,
+				Debug:            debug,
+				Redactor:         redactor,
+				Audit:            auditLog,
+				AutoApprove:      serveYolo,
+				ToolOutputTokens: cfg.Tools.Limits.TurnOutputTokens,
+				AllowedTools:     cfg.Tools.Allowed,
+				ConfirmTools:     confirm,
+				Prompter:         p,
+				PersistAllow: func(name string) error {
+					path, err := config.SettingsPath("user")
+					if err != nil {
+						return err
+					}
+					return config.AllowTool(path, name)
+				},
+			})
+			req := &api.GenerateRequest{
+				Model:        serveModel,
+				Project:      be.projectID,
+				UserPromptID: fmt.Sprintf("g-ide-%d", time.Now().UnixNano()),
+				Request: api.InnerRequest{
+					SystemInstruction: prompt.BuildSystemInstruction(prompt.Options{WorkDir: cwd, Shell: shellName(windowsShell(cfg))}),
+					Config: api.GenerationConfig{
+						Temperature:     1.0,
+						TopP:            0.95,
+						MaxOutputTokens: api.Model(serveModel).MaxOutputTokens,
+					},
+				},
+			}
+			if serveTools != "none" {
+				req.Request.Tools = []api.Tool{{FunctionDeclarations: toolDeclarations(cfg, "", registry.AllDeclarations())}}
+			}
+			return loop, req, nil
+		},
+		Debug: debug,
+	})
+
+	fmt.Fprintf(os.Stderr, "g serving ACP on stdio (tools: %s, model: %s)\n", serveTools, serveModel)
+	return srv.Serve(ctx, os.Stdin, os.Stdout)
+}
@@ -0,0 +1,351 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/k-sub1995/g/internal/session"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultSessionMaxAgeDays = 30
+	defaultSessionMaxSizeMB  = 200
+)
+
+var (
+	sessionsLimit  int
+	sessionsFormat string
+	sessionsOutput string
+	sessionsMaxAge string
+	sessionsMaxSz  string
+	sessionsDryRun bool
+	sessionsTag    string
+	sessionsInFmt  string
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Manage saved conversations",
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved sessions, most recent first",
+	Args:  cobra.NoArgs,
+	RunE:  runSessionsList,
+}
+
+var sessionsShowCmd = &cobra.Command{
+	Use:   "show <id|latest>",
+	Short: "Print a session's conversation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := session.Load(args[0])
+		if err != nil {
+			return err
+		}
+		return session.Export(os.Stdout, s, "markdown")
+	},
+}
+
+var sessionsDeleteCmd = &cobra.Command{
+	Use:   "delete <id>...",
+	Short: "Delete saved sessions",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, id := range args {
+			deleted, err := session.Delete(id)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Deleted session %s\n", deleted)
+		}
+		return nil
+	},
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <id|latest>",
+	Short: "Export a session as Markdown, JSON or a Gemini CLI checkpoint",
+	Long: `Export a session as Markdown, as JSON, or with --format gemini-cli as a chat
+checkpoint of the official Gemini CLI. --tag saves the checkpoint where the
+official CLI finds it for the session's directory, so /chat resume <tag>
+continues the conversation there.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionsExport,
+}
+
+var sessionsImportCmd = &cobra.Command{
+	Use:   "import [file|-]",
+	Short: "Import a Gemini CLI checkpoint or an exported session",
+	Long: `Import a conversation as a new session, which g --resume continues. The file is
+a chat checkpoint of the official Gemini CLI, or with --format json a session
+exported by g. --tag reads the checkpoint /chat save <tag> wrote for the
+current directory instead of a file.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSessionsImport,
+}
+
+var sessionsGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove old sessions beyond the age and size limits",
+	Args:  cobra.NoArgs,
+	RunE:  runSessionsGC,
+}
+
+func init() {
+	rootCmd.AddCommand(sessionsCmd)
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsShowCmd)
+	sessionsCmd.AddCommand(sessionsDeleteCmd)
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	sessionsCmd.AddCommand(sessionsImportCmd)
+	sessionsCmd.AddCommand(sessionsGCCmd)
+
+	sessionsListCmd.Flags().IntVarP(&sessionsLimit, "limit", "n", 20, "Number of sessions to show (0 for all)")
+	sessionsExportCmd.Flags().StringVar(&sessionsFormat, "format", "markdown", "Export format: "+strings.Join(session.ExportFormats, ", "))
+	sessionsExportCmd.Flags().StringVarP(&sessionsOutput, "output", "o", "", "Write to a file instead of stdout")
+	sessionsExportCmd.Flags().StringVar(&sessionsTag, "tag", "", "Save as the Gemini CLI checkpoint with this tag (implies --format gemini-cli)")
+	sessionsImportCmd.Flags().StringVar(&sessionsInFmt, "format", "gemini-cli", "Import format: "+strings.Join(session.ImportFormats, ", "))
+	sessionsImportCmd.Flags().StringVar(&sessionsTag, "tag", "", "Import the Gemini CLI checkpoint with this tag from the current directory")
+	sessionsGCCmd.Flags().StringVar(&sessionsMaxAge, "max-age", "", "Remove sessions not updated within this age, e.g. 30d or 12h (default from settings)")
+	sessionsGCCmd.Flags().StringVar(&sessionsMaxSz, "max-size", "", "Keep the store under this size, e.g. 200MB (default from settings)")
+	sessionsGCCmd.Flags().BoolVar(&sessionsDryRun, "dry-run", false, "Only print what would be removed")
+}
+
+// openSession returns the session the conversation is saved to: the one
+// named by resume, or a new one. It is nil when sessions are disabled.
+// Expired sessions are collected on the way.
+func openSession(cfg *config.Config, resume string) (*session.Session, error) {
+	if resume != "" {
+		s, err := session.Load(resume)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Resuming session %s (%d messages)\n", s.ID, len(s.Contents))
+		return s, nil
+	}
+	if cfg != nil && cfg.Sessions.Enabled != nil && !*cfg.Sessions.Enabled {
+		return nil, nil
+	}
+	maxAge, maxSize := sessionLimits(cfg)
+	if _, err := session.GC(maxAge, maxSize, ""); err != nil && debug {
+		fmt.Fprintf(os.Stderr, "[session] gc failed: %v\n", err)
+	}
+	cwd, _ := os.Getwd()
+	return session.New(cwd, model), nil
+}
+
+// sessionLimits returns the configured retention limits.
+func sessionLimits(cfg *config.Config) (time.Duration, int64) {
+	days, mb := defaultSessionMaxAgeDays, defaultSessionMaxSizeMB
+	if cfg != nil {
+		if cfg.Sessions.MaxAgeDays > 0 {
+			days = cfg.Sessions.MaxAgeDays
+		}
+		if cfg.Sessions.MaxSizeMB > 0 {
+			mb = cfg.Sessions.MaxSizeMB
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour, int64(mb) << 20
+}
+
+func runSessionsList(cmd *cobra.Command, args []string) error {
+	infos, err := session.List()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		fmt.Println("No saved sessions.")
+		return nil
+	}
+	if sessionsLimit > 0 && len(infos) > sessionsLimit {
+		infos = infos[:sessionsLimit]
+	}
+	for _, info := range infos {
+		fmt.Printf("%s  %s  %3d msgs  %7s  %s\n", info.ID,
+			info.Updated.Local().Format("2006-01-02 15:04"), info.Messages,
+			formatSize(info.Size), truncateLine(info.Title, 60))
+		fmt.Printf("    %s (%s)\n", info.WorkDir, info.Model)
+	}
+	return nil
+}
+
+func runSessionsExport(cmd *cobra.Command, args []string) error {
+	s, err := session.Load(args[0])
+	if err != nil {
+		return err
+	}
+	output := sessionsOutput
+	if sessionsTag != "" {
+		if cmd.Flags().Changed("format") && sessionsFormat != "gemini-cli" {
+			return fmt.Errorf("--tag saves a Gemini CLI checkpoint and cannot be used with --format %s", sessionsFormat)
+		}
+		if output != "" {
+			return fmt.Errorf("--tag and --output cannot be used together")
+		}
+		sessionsFormat = "gemini-cli"
+		if output, err = session.CheckpointPath(s.WorkDir, sessionsTag); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(output), 0o700); err != nil {
+			return err
+		}
+	}
+	if output == "" {
+		return session.Export(os.Stdout, s, sessionsFormat)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := session.Export(f, s, sessionsFormat); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if sessionsTag != "" {
+		fmt.Fprintf(os.Stderr, "Saved as checkpoint %q; run /chat resume %s in Gemini CLI in %s\n", sessionsTag, sessionsTag, s.WorkDir)
+	}
+	return nil
+}
+
+func runSessionsImport(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	var path string
+	switch {
+	case sessionsTag != "" && len(args) > 0:
+		return fmt.Errorf("give either a file or --tag, not both")
+	case sessionsTag != "":
+		if path, err = session.CheckpointPath(cwd, sessionsTag); err != nil {
+			return err
+		}
+	case len(args) == 0:
+		return fmt.Errorf("give the file to import, - for stdin, or --tag")
+	case args[0] != "-":
+		path = args[0]
+	}
+
+	in := os.Stdin
+	if path != "" {
+		f, err := os.Open(path)
+		if os.IsNotExist(err) && sessionsTag != "" {
+			return fmt.Errorf("no Gemini CLI checkpoint %q for %s", sessionsTag, cwd)
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	s, err := session.Import(in, sessionsInFmt, cwd, model)
+	if err != nil {
+		return err
+	}
+	if err := s.Save(s.Contents); err != nil {
+		return err
+	}
+	fmt.Printf("Imported session %s (%d messages); continue it with g --resume %s\n", s.ID, len(s.Contents), s.ID)
+	return nil
+}
+
+func runSessionsGC(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	maxAge, maxSize := sessionLimits(cfg)
+	if sessionsMaxAge != "" {
+		if maxAge, err = parseAge(sessionsMaxAge); err != nil {
+			return err
+		}
+	}
+	if sessionsMaxSz != "" {
+		if maxSize, err = parseSize(sessionsMaxSz); err != nil {
+			return err
+		}
+	}
+
+	if sessionsDryRun {
+		infos, err := session.List()
+		if err != nil {
+			return err
+		}
+		var total int64
+		cutoff := time.Now().Add(-maxAge)
+		for _, info := range infos {
+			if info.Updated.Before(cutoff) || total+info.Size > maxSize {
+				fmt.Printf("Would remove %s (%s)\n", info.ID, formatSize(info.Size))
+				continue
+			}
+			total += info.Size
+		}
+		return nil
+	}
+
+	removed, err := session.GC(maxAge, maxSize, "")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d session(s)\n", len(removed))
+	return nil
+}
+
+// parseAge parses a duration that may also use a "d" (days) suffix.
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30d or 12h)", s)
+	}
+	return d, nil
+}
+
+// parseSize parses a byte size such as 200MB, 1GB or 512KB.
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		shift  uint
+	}{{"GB", 30}, {"MB", 20}, {"KB", 10}, {"B", 0}}
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(upper, u.suffix); ok {
+			n, err := strconv.ParseInt(strings.TrimSpace(num), 10, 64)
+			if err != nil || n <= 0 {
+				break
+			}
+			return n << u.shift, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid size %q (use e.g. 200MB)", s)
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/k-sub1995/g/internal/skills"
+	"github.com/spf13/cobra"
+)
+
+var (
+	skillsDescription string
+	skillsScope       string
+)
+
+var skillsCmd = &cobra.Command{
+	Use:   "skills",
+	Short: "Manage agent skills in .gemini/skills/",
+}
+
+var skillsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available skills and report invalid ones",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workDir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		found, problems := skills.Discover(workDir)
+		if len(found) == 0 && len(problems) == 0 {
+			fmt.Println("No skills found. Create one with: g skills create <name> -d <description>")
+			return nil
+		}
+		for _, s := range found {
+			fmt.Printf("%s (%s)\n  %s\n", s.Name, s.Scope, s.Description)
+		}
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "invalid: %v\n", p)
+		}
+		return nil
+	},
+}
+
+var skillsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print a skill's instructions",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workDir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		s, err := skills.Find(workDir, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s (%s)\n%s\n%s\n\n%s\n", s.Name, s.Scope, s.Path(), s.Description, s.Body)
+		return nil
+	},
+}
+
+var skillsCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new skill skeleton",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workDir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		var dir string
+		for _, d := range skills.Dirs(workDir) {
+			if d.Scope == skillsScope {
+				dir = d.Dir
+			}
+		}
+		if dir == "" {
+			return fmt.Errorf("invalid scope %q (use project or user)", skillsScope)
+		}
+		path, err := skills.Create(dir, args[0], skillsDescription)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(skillsCmd)
+	skillsCmd.AddCommand(skillsListCmd)
+	skillsCmd.AddCommand(skillsShowCmd)
+	skillsCmd.AddCommand(skillsCreateCmd)
+
+	skillsCreateCmd.Flags().StringVarP(&skillsDescription, "description", "d", "", "When the model should use the skill (required)")
+	skillsCreateCmd.Flags().StringVarP(&skillsScope, "scope", "s", "project", "Where to create the skill: project or user")
+	_ = skillsCreateCmd.MarkFlagRequired("description")
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/summary"
+)
+
+// watchSummary makes a JSON formatter include the run summary in its
+// response.
+func watchSummary(formatter output.Formatter, collect func() *summary.Run) {
+	if jf, ok := formatter.(*output.JSONFormatter); ok {
+		jf.Summary = collect
+	}
+}
+
+// reportSummary writes the run summary when the run ends: as a "summary"
+// event for stream-json, and on stderr for text output when the agent
+// used tools. JSON output carries it in the response instead (see
+// watchSummary).
+func reportSummary(formatter output.Formatter, collect func() *summary.Run) {
+	w, ok := formatter.(interface{ WriteSummary(*summary.Run) error })
+	if !ok {
+		return
+	}
+	s := collect()
+	if s == nil {
+		return
+	}
+	if _, stream := formatter.(*output.StreamJSONFormatter); !stream && s.ToolCalls == 0 {
+		return
+	}
+	w.WriteSummary(s)
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// phaseTimer records how long startup phases take, reported with --debug.
+type phaseTimer struct {
+	start  time.Time
+	mu     sync.Mutex
+	phases []string
+}
+
+func newPhaseTimer() *phaseTimer {
+	return &phaseTimer{start: time.Now()}
+}
+
+// track starts timing a phase and returns the function that ends it.
+func (t *phaseTimer) track(name string) func() {
+	begin := time.Now()
+	return func() {
+		d := time.Since(begin).Round(time.Millisecond)
+		t.mu.Lock()
+		t.phases = append(t.phases, fmt.Sprintf("%s %v", name, d))
+		t.mu.Unlock()
+	}
+}
+
+// report prints the phases in the order they finished and the wall time.
+func (t *phaseTimer) report() {
+	if !debug {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "[init] %s (total %v)\n", strings.Join(t.phases, ", "), time.Since(t.start).Round(time.Millisecond))
+}
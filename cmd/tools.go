@@ -0,0 +1,130 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/k-sub1995/g/internal/history"
+	"github.com/k-sub1995/g/internal/plugin"
+	"github.com/k-sub1995/g/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+var (
+	toolsJSON         bool
+	toolsDeclarations string
+)
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Inspect the built-in and plugin tools",
+}
+
+var toolsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in and plugin tools, or print their schemas with --json",
+	Long: `List the built-in tools the agent can call and the plugins in tools.d
+(~/.config/g/tools.d), with the executables there that failed their
+--describe handshake. With --json, print each tool's name, description and
+JSON schema of its parameters, exactly as sent to the model in the
+tools.declarations mode or the one --declarations names. The list ends with
+an estimate of the tokens the declarations cost in every request. MCP tools
+are listed by 'g mcp list'.`,
+	Args: cobra.NoArgs,
+	RunE: runToolsList,
+}
+
+func init() {
+	rootCmd.AddCommand(toolsCmd)
+	toolsCmd.AddCommand(toolsListCmd)
+	toolsListCmd.Flags().BoolVar(&toolsJSON, "json", false, "Print names, descriptions and parameter schemas as JSON")
+	toolsListCmd.Flags().StringVar(&toolsDeclarations, "declarations", "", "Print the declarations as this mode sends them: full or compact")
+}
+
+func runToolsList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	registry := tools.NewRegistry(tools.RegistryOptions{
+		WorkDir:      workDir,
+		WindowsShell: windowsShell(cfg),
+		Limits:       toolLimits(cfg),
+		Timeouts:     toolTimeouts(cfg),
+	})
+	var problems []plugin.Problem
+	if dir, err := plugin.Dir(); err == nil {
+		var plugins []plugin.Plugin
+		plugins, problems = plugin.Discover(dir)
+		for _, p := range plugins {
+			registry.Register(pluginTool(p, workDir, toolTimeouts(cfg)))
+		}
+	}
+	mode := cmp.Or(toolsDeclarations, cfg.Tools.Declarations)
+	decls, err := tools.Declarations(mode, registry.AllDeclarations())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+
+	if toolsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(decls)
+	}
+	for _, d := range decls {
+		desc, _, _ := strings.Cut(d.Description, ". ")
+		fmt.Printf("%-22s %s\n", d.Name, strings.TrimSuffix(desc, "."))
+	}
+	compact, _ := tools.Declarations("compact", registry.AllDeclarations())
+	fmt.Printf("\nDeclarations: ~%d tokens per request in full, ~%d compact (%s)\n",
+		declarationTokens(registry.AllDeclarations()), declarationTokens(compact), cmp.Or(mode, "full"))
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "Warning: plugin skipped: %v\n", p)
+	}
+	return nil
+}
+
+// declarationTokens estimates what decls cost in a request.
+func declarationTokens(decls []api.FunctionDecl) int {
+	data, _ := json.Marshal(decls)
+	return history.EstimateText(string(data))
+}
+
+// toolDeclarations returns decls as mode says to send them, or else as the
+// tools.declarations setting does. An invalid setting sends them in full.
+func toolDeclarations(cfg *config.Config, mode string, decls []api.FunctionDecl) []api.FunctionDecl {
+	if mode == "" && cfg != nil {
+		mode = cfg.Tools.Declarations
+	}
+	out, err := tools.Declarations(mode, decls)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tools.declarations: %v\n", err)
+		return decls
+	}
+	return out
+}
+
+// pluginTool runs a tools.d executable in the working directory.
+func pluginTool(p plugin.Plugin, workDir string, timeouts tools.Timeouts) tools.Tool {
+	return tools.NewExtensionTool(tools.ExtensionToolSpec{
+		Name:        p.Name,
+		Description: p.Description,
+		Parameters:  p.Parameters,
+		Command:     p.Path,
+		Dir:         workDir,
+		Timeout:     time.Duration(p.Timeout) * time.Millisecond,
+	}, timeouts)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trustParent bool
+	trustNever  bool
+	trustRemove bool
+	trustList   bool
+)
+
+var trustCmd = &cobra.Command{
+	Use:   "trust [dir]",
+	Short: "Trust a folder to start the MCP servers its settings configure",
+	Long: `Trust a folder (default: the current directory) and everything under it, so
+that the MCP servers in its .gemini/settings.json are started. Servers there
+are ignored in folders that are not trusted, since a cloned repository could
+otherwise run any command. Rules are kept in ~/.gemini/trustedFolders.json,
+shared with the Gemini CLI.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTrust,
+}
+
+func init() {
+	rootCmd.AddCommand(trustCmd)
+	trustCmd.Flags().BoolVar(&trustParent, "parent", false, "Trust the folder's parent and everything under it")
+	trustCmd.Flags().BoolVar(&trustNever, "never", false, "Never trust the folder, even inside a trusted parent")
+	trustCmd.Flags().BoolVar(&trustRemove, "remove", false, "Remove the folder's rule")
+	trustCmd.Flags().BoolVar(&trustList, "list", false, "List the folder rules")
+	trustCmd.MarkFlagsMutuallyExclusive("parent", "never", "remove", "list")
+}
+
+func runTrust(cmd *cobra.Command, args []string) error {
+	if trustList {
+		dirs, rules, err := config.TrustedFolders()
+		if err != nil {
+			return err
+		}
+		if len(dirs) == 0 {
+			fmt.Println("No trusted folders.")
+		}
+		for _, dir := range dirs {
+			fmt.Printf("%-14s %s\n", rules[dir], dir)
+		}
+		return nil
+	}
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dir); err != nil && !trustRemove {
+		return err
+	} else if err == nil && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	level := config.TrustFolder
+	switch {
+	case trustParent:
+		level = config.TrustParent
+	case trustNever:
+		level = config.DoNotTrust
+	case trustRemove:
+		level = ""
+	}
+	if err := config.SetFolderTrust(dir, level); err != nil {
+		return err
+	}
+	switch level {
+	case config.TrustFolder:
+		fmt.Printf("Trusted %s\n", dir)
+	case config.TrustParent:
+		fmt.Printf("Trusted %s\n", filepath.Dir(dir))
+	case config.DoNotTrust:
+		fmt.Printf("%s will not be trusted\n", dir)
+	default:
+		fmt.Printf("Removed the rule for %s\n", dir)
+	}
+	return nil
+}
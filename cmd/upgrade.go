@@ -0,0 +1,84 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/k-sub1995/g/internal/upgrade"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeCheckOnly bool
+	upgradeForce     bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Update g to the latest release",
+	Long: `Download the latest g release from GitHub, verify it against the
+release checksums and their signature, and replace the running binary.
+Builds without the release signing key cannot verify releases and refuse.
+
+Set GITHUB_TOKEN to avoid GitHub API rate limits.`,
+	Args: cobra.NoArgs,
+	RunE: runUpgrade,
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().BoolVar(&upgradeCheckOnly, "check-only", false, "Only report whether an update is available")
+	upgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, "Install the latest release even if it is not newer (e.g. over a dev build)")
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	u := upgrade.New()
+	rel, err := u.Latest(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := version
+	available := current != "dev" && upgrade.Newer(rel.Version(), current)
+	switch {
+	case available:
+		fmt.Printf("g v%s is available (current v%s)\n", rel.Version(), current)
+	case current == "dev":
+		fmt.Printf("Latest release is v%s (this is a development build)\n", rel.Version())
+	default:
+		fmt.Printf("g v%s is up to date\n", current)
+	}
+	if upgradeCheckOnly {
+		if available && rel.HTMLURL != "" {
+			fmt.Printf("Release notes: %s\n", rel.HTMLURL)
+		}
+		return nil
+	}
+	if !available && !upgradeForce {
+		if current == "dev" {
+			fmt.Println("Use --force to replace it with the release.")
+		}
+		return nil
+	}
+
+	binary, err := u.Download(ctx, rel)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := upgrade.Replace(exe, binary); err != nil {
+		return err
+	}
+	fmt.Printf("Upgraded %s to v%s\n", exe, rel.Version())
+	return nil
+}
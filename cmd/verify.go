@@ -0,0 +1,111 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+const (
+	defaultVerifyRetries = 3
+	defaultVerifyTimeout = 10 * time.Minute
+	// verifyOutputBytes is how much of a failed check's output the agent
+	// gets back. The end of test and build output says what failed.
+	verifyOutputBytes = 16 * 1024
+)
+
+// verifier runs the --verify check after the agent is done.
+type verifier struct {
+	command string
+	retries int
+	timeout time.Duration
+	shell   tools.WindowsShell
+}
+
+// newVerifier returns the check --verify asked for, or nil without
+// --verify. A bare --verify runs verify.command from the settings.
+func newVerifier(cfg *config.Config, flag string, retries int, retriesSet bool) (*verifier, error) {
+	if flag == "" {
+		return nil, nil
+	}
+	v := &verifier{
+		command: flag,
+		retries: defaultVerifyRetries,
+		timeout: defaultVerifyTimeout,
+		shell:   windowsShell(cfg),
+	}
+	if flag == "true" {
+		v.command = cfg.Verify.Command
+	}
+	if strings.TrimSpace(v.command) == "" {
+		return nil, fmt.Errorf("--verify needs a command, as in --verify \"go test ./...\", or verify.command in settings.json")
+	}
+	if cfg.Verify.Retries != nil {
+		v.retries = *cfg.Verify.Retries
+	}
+	if retriesSet {
+		v.retries = retries
+	}
+	if v.retries < 0 {
+		return nil, fmt.Errorf("--verify-retries must not be negative")
+	}
+	if cfg.Verify.Timeout > 0 {
+		v.timeout = time.Duration(cfg.Verify.Timeout) * time.Second
+	}
+	return v, nil
+}
+
+// run runs the check in the working directory and returns its exit code
+// and the tail of its combined output. The error is for a check that
+// could not be run at all.
+func (v *verifier) run(ctx context.Context) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		var err error
+		if cmd, err = v.shell.Command(ctx, v.command); err != nil {
+			return 0, "", err
+		}
+	} else {
+		cmd = exec.CommandContext(ctx, "bash", "-c", v.command)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = 2 * time.Second
+
+	err := cmd.Run()
+	output := out.String()
+	if len(output) > verifyOutputBytes {
+		output = "[... earlier output omitted ...]\n" + output[len(output)-verifyOutputBytes:]
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return -1, output + fmt.Sprintf("\n[timed out after %s]", v.timeout), nil
+	case errors.As(err, &exitErr):
+		return exitErr.ExitCode(), output, nil
+	case err != nil:
+		return 0, "", fmt.Errorf("running the verify command: %w", err)
+	}
+	return 0, output, nil
+}
+
+// feedback is the message that sends a failed check back to the agent.
+func (v *verifier) feedback(code int, output string) string {
+	return fmt.Sprintf("You said the task is done, but the verification command failed (exit code %d):\n\n$ %s\n%s\n\n"+
+		"Find the cause and fix it. The command will be run again when you finish.",
+		code, v.command, strings.TrimRight(output, "\n"))
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/approval"
+	"github.com/k-sub1995/g/internal/worktree"
+)
+
+// startWorktree creates a worktree for this run and changes into it, so that
+// every tool works on the new branch instead of the user's checkout.
+func startWorktree() (*worktree.Worktree, string, error) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		return nil, "", err
+	}
+	wt, err := worktree.Create(origDir)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := os.Chdir(wt.Dir(origDir)); err != nil {
+		_ = wt.Remove(true)
+		return nil, "", err
+	}
+	fmt.Fprintf(os.Stderr, "Working in worktree %s on branch %s\n", wt.Path, wt.Branch)
+	if wt.Dirty {
+		fmt.Fprintln(os.Stderr, "Note: uncommitted changes in your checkout are not included.")
+	}
+	return wt, origDir, nil
+}
+
+// checkpointWorktree commits the changes made during a turn, labelled with
+// the prompt that caused them.
+func checkpointWorktree(wt *worktree.Worktree, contents []api.Content) {
+	label := "g: checkpoint"
+	for i := len(contents) - 1; i >= 0; i-- {
+		if c := contents[i]; c.Role == "user" && len(c.Parts) > 0 && c.Parts[0].Text != "" {
+			line, _, _ := strings.Cut(strings.TrimSpace(c.Parts[0].Text), "\n")
+			label = "g: " + truncateLine(line, 72)
+			break
+		}
+	}
+	if _, err := wt.Checkpoint(label); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to checkpoint worktree: %v\n", err)
+	}
+}
+
+// finishWorktree offers to merge, keep or discard the run's branch. Without a
+// terminal the branch is kept. Branches without changes are removed.
+func finishWorktree(wt *worktree.Worktree, origDir string) {
+	_ = os.Chdir(origDir)
+	if _, err := wt.Checkpoint("g: final changes"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to checkpoint worktree: %v\n", err)
+	}
+	n, err := wt.Commits()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; worktree left at %s\n", err, wt.Path)
+		return
+	}
+	if n == 0 {
+		if err := wt.Remove(true); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "\nNo changes were made; removed branch %s.\n", wt.Branch)
+		return
+	}
+
+	stat, _ := wt.DiffStat()
+	fmt.Fprintf(os.Stderr, "\nBranch %s has %d commit(s):\n%s\n", wt.Branch, n, stat)
+	choice, err := approval.Ask(context.Background(), "Merge the changes into your checkout?", []approval.Choice{
+		{Key: "m", Label: "merge"},
+		{Key: "k", Label: "keep branch"},
+		{Key: "d", Label: "discard"},
+	})
+	if err != nil && !errors.Is(err, approval.ErrNoTerminal) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	switch choice {
+	case "m":
+		if err := wt.Merge(); err != nil {
+			fmt.Fprintf(os.Stderr, "Merge failed: %v\n", err)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "Merged %s.\n", wt.Branch)
+		return
+	case "d":
+		if err := wt.Remove(true); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to discard worktree: %v\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Discarded %s.\n", wt.Branch)
+		return
+	}
+	if err := wt.Remove(false); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "Kept branch %s; apply it with: git merge %s\n", wt.Branch, wt.Branch)
+}
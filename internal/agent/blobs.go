@@ -0,0 +1,46 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/mcp"
+)
+
+// saveBlob decodes a binary tool result and writes it under dir, returning
+// the file path.
+func saveBlob(dir, toolName string, index int, blob mcp.Blob) (string, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "g-blobs")
+	}
+	data, err := base64.StdEncoding.DecodeString(blob.Data)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 data: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	ext := ".bin"
+	if blob.URI != "" && filepath.Ext(blob.URI) != "" {
+		ext = filepath.Ext(blob.URI)
+	} else if exts, _ := mime.ExtensionsByType(blob.MimeType); len(exts) > 0 {
+		ext = exts[0]
+	}
+	name := fmt.Sprintf("%s-%s-%d%s",
+		strings.ReplaceAll(toolName, string(filepath.Separator), "_"),
+		time.Now().Format("20060102-150405"), index, ext)
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
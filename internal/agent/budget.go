@@ -0,0 +1,99 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// DefaultToolOutputTokens bounds the tool results sent back in one turn.
+const DefaultToolOutputTokens = 100_000
+
+// bytesPerToken is a rough estimate for JSON-encoded tool output.
+const bytesPerToken = 4
+
+// budgetNote marks a result shortened by fitToolResults.
+const budgetNote = "Output shortened to fit the per-turn tool output budget; narrow the request to see more."
+
+// fitToolResults shortens the function responses in parts so that together
+// they stay within maxTokens. Each result gets an equal share, and what small
+// results leave unused goes to the larger ones. Long string fields are cut in
+// the middle, keeping their head and tail. It returns the number of results
+// it changed.
+func fitToolResults(parts []api.Part, maxTokens int) int {
+	if maxTokens <= 0 {
+		maxTokens = DefaultToolOutputTokens
+	}
+	type entry struct {
+		resp *api.FunctionResp
+		size int
+	}
+	var entries []entry
+	total := 0
+	for _, p := range parts {
+		if p.FunctionResp == nil {
+			continue
+		}
+		data, _ := json.Marshal(p.FunctionResp.Response)
+		entries = append(entries, entry{p.FunctionResp, len(data)})
+		total += len(data)
+	}
+	budget := maxTokens * bytesPerToken
+	if total <= budget {
+		return 0
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].size < entries[j].size })
+	changed := 0
+	for i, e := range entries {
+		share := budget / (len(entries) - i)
+		if e.size <= share {
+			budget -= e.size
+			continue
+		}
+		shrink(e.resp, e.size-share)
+		budget -= share
+		changed++
+	}
+	return changed
+}
+
+// shrink removes about excess bytes from the string fields of resp, longest
+// first.
+func shrink(resp *api.FunctionResp, excess int) {
+	keys := make([]string, 0, len(resp.Response))
+	for k, v := range resp.Response {
+		if _, ok := v.(string); ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(resp.Response[keys[i]].(string)) > len(resp.Response[keys[j]].(string))
+	})
+
+	out := make(map[string]interface{}, len(resp.Response)+1)
+	for k, v := range resp.Response {
+		out[k] = v
+	}
+	for _, k := range keys {
+		if excess <= 0 {
+			break
+		}
+		s := out[k].(string)
+		keep := len(s) - excess - 64 // room for the truncation marker
+		if keep < 256 {
+			keep = 256
+		}
+		if keep >= len(s) {
+			continue
+		}
+		out[k] = tools.TruncateMiddle(s, keep)
+		excess -= len(s) - keep
+	}
+	out["budget_note"] = budgetNote
+	resp.Response = out
+}
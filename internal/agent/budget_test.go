@@ -0,0 +1,41 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+func TestFitToolResults(t *testing.T) {
+	log := "START " + strings.Repeat("x", 20_000) + " FATAL: disk full"
+	parts := []api.Part{
+		{FunctionResp: &api.FunctionResp{Name: "run_shell_command", Response: map[string]interface{}{"stdout": log, "exit_code": 1}}},
+		{FunctionResp: &api.FunctionResp{Name: "read_file", Response: map[string]interface{}{"content": "small"}}},
+		{InlineData: &api.Blob{MimeType: "image/png", Data: "AAAA"}},
+	}
+
+	if n := fitToolResults(parts, 10_000); n != 0 {
+		t.Fatalf("results within budget were changed (%d)", n)
+	}
+	if n := fitToolResults(parts, 1_000); n != 1 {
+		t.Fatalf("changed %d results, want 1", n)
+	}
+
+	shell := parts[0].FunctionResp.Response
+	stdout := shell["stdout"].(string)
+	if len(stdout) > 4_000 {
+		t.Errorf("stdout is %d bytes, want at most the 4000-byte budget", len(stdout))
+	}
+	if !strings.HasPrefix(stdout, "START ") || !strings.HasSuffix(stdout, "FATAL: disk full") || !strings.Contains(stdout, "bytes truncated") {
+		t.Errorf("stdout should keep head and tail: %q...%q", stdout[:20], stdout[len(stdout)-20:])
+	}
+	if shell["exit_code"] != 1 || shell["budget_note"] == nil {
+		t.Errorf("shell result = %v", shell)
+	}
+	if parts[1].FunctionResp.Response["content"] != "small" || parts[1].FunctionResp.Response["budget_note"] != nil {
+		t.Errorf("small result was changed: %v", parts[1].FunctionResp.Response)
+	}
+}
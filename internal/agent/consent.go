@@ -0,0 +1,165 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/approval"
+	"github.com/k-sub1995/g/internal/risk"
+)
+
+// consent tracks which tools the user has approved. Approval is asked
+// once per tool per session; "always" answers are persisted via Config.
+type consent struct {
+	mu      sync.Mutex
+	allowed map[string]bool // tool names approved for this session
+	trusted map[string]bool // servers trusted for this session
+}
+
+func newConsent(allowed []string) *consent {
+	c := &consent{
+		allowed: make(map[string]bool),
+		trusted: make(map[string]bool),
+	}
+	for _, name := range allowed {
+		c.allowed[name] = true
+	}
+	return c
+}
+
+// Approval outcomes recorded in the audit log.
+const (
+	approvalNotRequired = "not_required"
+	approvalYolo        = "yolo"
+	approvalTrusted     = "trusted_server"
+	approvalAllowed     = "allowed"
+	approvalUser        = "user"
+	approvalDenied      = "denied"
+)
+
+// approve decides whether a tool call may run and returns how it was
+// approved. MCP tools, tools listed in Config.ConfirmTools and risky shell
+// commands require confirmation.
+func (l *Loop) approve(ctx context.Context, fc api.FunctionCall) (string, error) {
+	if fc.Name == "run_shell_command" {
+		command, _ := fc.Args["command"].(string)
+		if findings := risk.ShellCommand(command); len(findings) > 0 {
+			return l.confirmRisky(ctx, fc, command, findings)
+		}
+	}
+	if ref, ok := l.registry.GetMCPRef(fc.Name); ok && l.mcp != nil {
+		return l.confirmTool(ctx, fc.Name, ref.ServerName, fc.Args)
+	}
+	for _, name := range l.config.ConfirmTools {
+		if name == fc.Name {
+			return l.confirmTool(ctx, fc.Name, "", fc.Args)
+		}
+	}
+	return approvalNotRequired, nil
+}
+
+// confirmRisky asks before running a shell command the analyzer flagged.
+// This applies even with --yolo; there is no way to remember the answer.
+func (l *Loop) confirmRisky(ctx context.Context, fc api.FunctionCall, command string, findings []risk.Finding) (string, error) {
+	var reasons []string
+	for _, f := range findings {
+		reasons = append(reasons, f.Reason)
+	}
+	reason := strings.Join(reasons, "; ")
+
+	if l.config.Prompter == nil {
+		return approvalDenied, fmt.Errorf("refusing dangerous command (%s); run it yourself if intended", reason)
+	}
+	decision, err := l.config.Prompter.Confirm(ctx, approval.Request{
+		Tool:    fc.Name,
+		Args:    fc.Args,
+		Summary: "$ " + truncate(command, 200),
+		Reason:  reason,
+		Options: []approval.Decision{approval.AllowOnce},
+	})
+	if errors.Is(err, approval.ErrNoTerminal) {
+		return approvalDenied, fmt.Errorf("refusing dangerous command (%s): confirmation required but no terminal is available", reason)
+	}
+	if err != nil {
+		return approvalDenied, err
+	}
+	if decision != approval.AllowOnce {
+		return approvalDenied, fmt.Errorf("user denied dangerous command (%s)", reason)
+	}
+	return approvalUser + ":once", nil
+}
+
+// confirmTool checks whether the tool may run, asking the user on first
+// use unless the call is pre-approved by --yolo, a trusted server or the
+// tools.allowed setting. server is empty for built-in tools.
+func (l *Loop) confirmTool(ctx context.Context, name, server string, args map[string]interface{}) (string, error) {
+	if l.config.AutoApprove {
+		return approvalYolo, nil
+	}
+	if server != "" {
+		if cfg, ok := l.mcp.Config(server); ok && cfg.Trust {
+			return approvalTrusted, nil
+		}
+	}
+
+	c := l.consent
+	c.mu.Lock()
+	ok := c.allowed[name] || c.trusted[server]
+	c.mu.Unlock()
+	if ok {
+		return approvalAllowed, nil
+	}
+
+	hint := "rerun with --yolo or add it to tools.allowed"
+	options := []approval.Decision{approval.AllowOnce, approval.AllowSession, approval.AllowAlways}
+	if server != "" {
+		hint = fmt.Sprintf("rerun with --yolo, set \"trust\": true for server %q, or add it to tools.allowed", server)
+		options = []approval.Decision{approval.AllowSession, approval.AllowAlways, approval.TrustServer}
+	}
+	if l.config.Prompter == nil {
+		return approvalDenied, fmt.Errorf("tool %s requires confirmation; %s", name, hint)
+	}
+	decision, err := l.config.Prompter.Confirm(ctx, approval.Request{
+		Tool:    name,
+		Server:  server,
+		Args:    args,
+		Summary: fmt.Sprintf("args: %s", truncate(fmt.Sprintf("%v", args), 200)),
+		Options: options,
+	})
+	if errors.Is(err, approval.ErrNoTerminal) {
+		return approvalDenied, fmt.Errorf("tool %s requires confirmation but no terminal is available; %s", name, hint)
+	}
+	if err != nil {
+		return approvalDenied, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch decision {
+	case approval.AllowOnce:
+		return approvalUser + ":once", nil
+	case approval.AllowSession:
+		c.allowed[name] = true
+		return approvalUser + ":session", nil
+	case approval.AllowAlways:
+		c.allowed[name] = true
+		if l.config.PersistAllow != nil {
+			if err := l.config.PersistAllow(name); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save approval for %s: %v\n", name, err)
+			}
+		}
+		return approvalUser + ":always", nil
+	case approval.TrustServer:
+		c.trusted[server] = true
+		return approvalUser + ":trust_server", nil
+	default:
+		return approvalDenied, fmt.Errorf("user denied execution of %s", name)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/approval"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// answeringPrompter gives the same answer to every confirmation and counts
+// how often it was asked.
+type answeringPrompter struct {
+	decision approval.Decision
+	err      error
+	asked    int
+}
+
+func (p *answeringPrompter) Confirm(ctx context.Context, req approval.Request) (approval.Decision, error) {
+	p.asked++
+	return p.decision, p.err
+}
+
+func consentLoop(t *testing.T, config Config) *Loop {
+	t.Helper()
+	config.ConfirmTools = []string{"write_file"}
+	return NewLoop(nil, tools.NewRegistry(tools.RegistryOptions{WorkDir: t.TempDir()}), nil, nil, config)
+}
+
+func writeCall() api.FunctionCall {
+	return api.FunctionCall{Name: "write_file", Args: map[string]interface{}{"file_path": "a.txt"}}
+}
+
+func TestConsent(t *testing.T) {
+	tests := []struct {
+		name      string
+		decision  approval.Decision
+		want      string // approval recorded for the first call
+		wantAsked int    // confirmations after two calls
+	}{
+		{"once asks every time", approval.AllowOnce, "user:once", 2},
+		{"session asks once", approval.AllowSession, "user:session", 1},
+		{"always asks once", approval.AllowAlways, "user:always", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &answeringPrompter{decision: tt.decision}
+			var persisted []string
+			l := consentLoop(t, Config{Prompter: p, PersistAllow: func(name string) error {
+				persisted = append(persisted, name)
+				return nil
+			}})
+			got, err := l.approve(context.Background(), writeCall())
+			if err != nil || got != tt.want {
+				t.Fatalf("approve = %q, %v; want %q", got, err, tt.want)
+			}
+			if _, err := l.approve(context.Background(), writeCall()); err != nil {
+				t.Fatal(err)
+			}
+			if p.asked != tt.wantAsked {
+				t.Errorf("asked %d times, want %d", p.asked, tt.wantAsked)
+			}
+			var wantPersisted []string
+			if tt.decision == approval.AllowAlways {
+				wantPersisted = []string{"write_file"}
+			}
+			if !reflect.DeepEqual(persisted, wantPersisted) {
+				t.Errorf("persisted %v, want %v", persisted, wantPersisted)
+			}
+		})
+	}
+}
+
+func TestConsentDenied(t *testing.T) {
+	p := &answeringPrompter{decision: approval.Deny}
+	l := consentLoop(t, Config{Prompter: p})
+	got, err := l.approve(context.Background(), writeCall())
+	if err == nil || got != approvalDenied {
+		t.Fatalf("approve = %q, %v; want denied", got, err)
+	}
+	// A denial is not remembered: the next call asks again
+	l.approve(context.Background(), writeCall())
+	if p.asked != 2 {
+		t.Errorf("asked %d times, want 2", p.asked)
+	}
+}
+
+func TestConsentWithoutTerminal(t *testing.T) {
+	for name, config := range map[string]Config{
+		"no prompter": {},
+		"no terminal": {Prompter: &answeringPrompter{decision: approval.AllowOnce, err: approval.ErrNoTerminal}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := consentLoop(t, config).approve(context.Background(), writeCall())
+			if err == nil || got != approvalDenied || !strings.Contains(err.Error(), "tools.allowed") {
+				t.Errorf("approve = %q, %v; want denied with a hint", got, err)
+			}
+		})
+	}
+}
+
+func TestConsentPreapproved(t *testing.T) {
+	p := &answeringPrompter{decision: approval.Deny}
+	if got, err := consentLoop(t, Config{Prompter: p, AutoApprove: true}).approve(context.Background(), writeCall()); err != nil || got != approvalYolo {
+		t.Errorf("--yolo: approve = %q, %v", got, err)
+	}
+	if got, err := consentLoop(t, Config{Prompter: p, AllowedTools: []string{"write_file"}}).approve(context.Background(), writeCall()); err != nil || got != approvalAllowed {
+		t.Errorf("tools.allowed: approve = %q, %v", got, err)
+	}
+	if got, err := consentLoop(t, Config{Prompter: p}).approve(context.Background(), api.FunctionCall{Name: "read_file"}); err != nil || got != approvalNotRequired {
+		t.Errorf("read_file: approve = %q, %v", got, err)
+	}
+	if p.asked != 0 {
+		t.Errorf("asked %d times for pre-approved calls", p.asked)
+	}
+}
+
+func TestConsentRiskyCommand(t *testing.T) {
+	// Risky commands are confirmed even with --yolo, and never remembered
+	p := &answeringPrompter{decision: approval.AllowOnce}
+	l := consentLoop(t, Config{Prompter: p, AutoApprove: true})
+	call := api.FunctionCall{Name: "run_shell_command", Args: map[string]interface{}{"command": "rm -rf /"}}
+	for i := 0; i < 2; i++ {
+		if got, err := l.approve(context.Background(), call); err != nil || got != "user:once" {
+			t.Fatalf("approve = %q, %v", got, err)
+		}
+	}
+	if p.asked != 2 {
+		t.Errorf("asked %d times, want 2", p.asked)
+	}
+	if _, err := consentLoop(t, Config{AutoApprove: true}).approve(context.Background(), call); err == nil {
+		t.Error("risky command without a prompter should be refused")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// minStoredContent is the smallest file content worth replacing with a
+// reference when it is read again.
+const minStoredContent = 2048
+
+// contentStore remembers, by path and hash, the file contents read_file
+// has sent in the session and where in the conversation each is. Reading a
+// file again unchanged then sends a short reference to the earlier result
+// instead of the same content, which saves most of a read-modify-verify
+// loop. Another file with the same content is sent in full, so the model
+// is never told a file holds what it read from a different one. Contents
+// that were since shortened, evicted or compressed out of the conversation
+// are sent in full again.
+type contentStore struct {
+	sent map[readKey]location
+}
+
+type readKey struct {
+	path string
+	sum  [sha256.Size]byte
+}
+
+type location struct {
+	content int // index of the message in the conversation
+	part    int
+}
+
+func newContentStore() *contentStore {
+	return &contentStore{sent: map[readKey]location{}}
+}
+
+// shortHash is the prefix of a content hash shown to the model.
+func shortHash(sum [sha256.Size]byte) string {
+	return hex.EncodeToString(sum[:6])
+}
+
+// dedupe handles the result of a read_file call that will be at loc. A
+// large content is labelled with its hash; when the same content is still
+// in contents from an earlier read, the result is replaced by a reference
+// to it.
+func (s *contentStore) dedupe(result map[string]interface{}, contents []api.Content, loc location) map[string]interface{} {
+	content, ok := result["content"].(string)
+	if !ok || len(content) < minStoredContent {
+		return result
+	}
+	path, _ := result["file_path"].(string)
+	sum := sha256.Sum256([]byte(content))
+	key := readKey{path: path, sum: sum}
+	if prev, ok := s.sent[key]; ok && s.holds(contents, prev, sum) {
+		ref := map[string]interface{}{
+			"file_path": result["file_path"],
+			"unchanged": true,
+			"sha256":    shortHash(sum),
+			"message":   fmt.Sprintf("Unchanged since it was last read: the content is identical to the earlier read_file result with sha256 %s in this conversation, so it is not repeated. Use that result.", shortHash(sum)),
+		}
+		for _, key := range []string{"truncated", "total_lines", "next_offset"} {
+			if v, ok := result[key]; ok {
+				ref[key] = v
+			}
+		}
+		return ref
+	}
+	s.sent[key] = loc
+	result["sha256"] = shortHash(sum)
+	return result
+}
+
+// holds reports whether the result at loc still carries the content
+// hashing to sum.
+func (s *contentStore) holds(contents []api.Content, loc location, sum [sha256.Size]byte) bool {
+	if loc.content >= len(contents) {
+		return false
+	}
+	parts := contents[loc.content].Parts
+	if loc.part >= len(parts) || parts[loc.part].FunctionResp == nil {
+		return false
+	}
+	content, ok := parts[loc.part].FunctionResp.Response["content"].(string)
+	return ok && sha256.Sum256([]byte(content)) == sum
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// resultCache remembers where the results of read-only tool calls are in
+// the conversation, so that a call repeating one exactly is answered with a
+// reference instead of running again and sending the same output twice.
+// Any other tool may change what they read, so running one clears the
+// cache, and a cache lasts one run of the loop: between runs the user may
+// edit files.
+type resultCache struct {
+	entries map[string]cachedResult
+}
+
+type cachedResult struct {
+	turn    int // 1-based turn of the run that produced the result
+	content int // index in the conversation of the message holding it
+	part    int
+}
+
+func newResultCache() *resultCache {
+	return &resultCache{entries: map[string]cachedResult{}}
+}
+
+func callKey(fc api.FunctionCall) string {
+	args, _ := json.Marshal(fc.Args) // map keys are sorted
+	return fc.Name + "\x00" + string(args)
+}
+
+// lookup returns the reference result for a repeat of an earlier call, or
+// nil when fc has to run. A result that was since evicted from contents is
+// not referred to.
+func (c *resultCache) lookup(fc api.FunctionCall, contents []api.Content) map[string]interface{} {
+	e, ok := c.entries[callKey(fc)]
+	if !ok {
+		return nil
+	}
+	if e.content < len(contents) {
+		parts := contents[e.content].Parts
+		if e.part >= len(parts) || parts[e.part].FunctionResp == nil || parts[e.part].FunctionResp.Response["evicted"] == true {
+			delete(c.entries, callKey(fc))
+			return nil
+		}
+	}
+	return map[string]interface{}{
+		"duplicate_of_turn": e.turn,
+		"message":           fmt.Sprintf("Identical to the result of the same %s call in turn %d above; nothing has changed since, so it was not run again.", fc.Name, e.turn),
+	}
+}
+
+// add records where the result of fc will be.
+func (c *resultCache) add(fc api.FunctionCall, turn, content, part int) {
+	c.entries[callKey(fc)] = cachedResult{turn: turn, content: content, part: part}
+}
+
+func (c *resultCache) clear() {
+	c.entries = map[string]cachedResult{}
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// scriptedProvider replies with one function call per request, then text.
+type scriptedProvider struct {
+	calls []api.FunctionCall
+}
+
+func (p *scriptedProvider) Generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	part := api.Part{Text: "done"}
+	if len(p.calls) > 0 {
+		part = api.Part{FunctionCall: &p.calls[0]}
+		p.calls = p.calls[1:]
+	}
+	resp := &api.GenerateResponse{}
+	resp.Response.Candidates = []api.Candidate{{Content: api.Content{Role: "model", Parts: []api.Part{part}}}}
+	return resp, nil
+}
+
+func (p *scriptedProvider) GenerateStream(ctx context.Context, req *api.GenerateRequest) (<-chan api.StreamEvent, error) {
+	panic("not used")
+}
+
+func TestRepeatedReadIsDeduplicated(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	read := api.FunctionCall{Name: "read_file", Args: map[string]interface{}{"file_path": path}}
+	write := api.FunctionCall{Name: "write_file", Args: map[string]interface{}{"file_path": filepath.Join(dir, "b.txt"), "content": "new\n"}}
+	p := &scriptedProvider{calls: []api.FunctionCall{read, read, write, read}}
+	formatter, _ := output.NewFormatter("json", io.Discard, io.Discard, true)
+	loop := NewLoop(p, tools.NewRegistry(tools.RegistryOptions{WorkDir: dir, AutoApprove: true}), nil, formatter, Config{MaxTurns: 10})
+
+	req := &api.GenerateRequest{}
+	req.Request.Contents = []api.Content{{Role: "user", Parts: []api.Part{{Text: "read a.txt"}}}}
+	if err := loop.Run(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	results := toolResults(req.Request.Contents)
+	if len(results) != 4 {
+		t.Fatalf("got %d tool results, want 4", len(results))
+	}
+	if results[0]["duplicate_of_turn"] != nil {
+		t.Errorf("first read was deduplicated: %v", results[0])
+	}
+	if results[1]["duplicate_of_turn"] != 1 {
+		t.Errorf("repeated read = %v, want a reference to turn 1", results[1])
+	}
+	// The write may have changed what is read, so the read runs again
+	if results[3]["duplicate_of_turn"] != nil {
+		t.Errorf("read after a write was deduplicated: %v", results[3])
+	}
+}
+
+func TestUnchangedFileIsReferenced(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.txt")
+	big := strings.Repeat("some line of text\n", 500)
+	if err := os.WriteFile(path, []byte(big), 0644); err != nil {
+		t.Fatal(err)
+	}
+	read := api.FunctionCall{Name: "read_file", Args: map[string]interface{}{"file_path": path}}
+	writeOther := api.FunctionCall{Name: "write_file", Args: map[string]interface{}{"file_path": filepath.Join(dir, "b.txt"), "content": "new\n"}}
+	p := &scriptedProvider{calls: []api.FunctionCall{read, writeOther, read}}
+	formatter, _ := output.NewFormatter("json", io.Discard, io.Discard, true)
+	loop := NewLoop(p, tools.NewRegistry(tools.RegistryOptions{WorkDir: dir, AutoApprove: true}), nil, formatter, Config{MaxTurns: 10})
+
+	req := &api.GenerateRequest{}
+	req.Request.Contents = []api.Content{{Role: "user", Parts: []api.Part{{Text: "read big.txt"}}}}
+	if err := loop.Run(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	results := toolResults(req.Request.Contents)
+	first, second := results[0], results[2]
+	if first["content"] == nil || first["sha256"] == nil {
+		t.Fatalf("first read = %v", first)
+	}
+	if second["unchanged"] != true || second["sha256"] != first["sha256"] || second["content"] != nil {
+		t.Errorf("unchanged re-read = %v", second)
+	}
+
+	// A changed file is sent in full
+	if err := os.WriteFile(path, []byte(big+"more\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p.calls = []api.FunctionCall{read}
+	req.Request.Contents = append(req.Request.Contents, api.Content{Role: "user", Parts: []api.Part{{Text: "again"}}})
+	if err := loop.Run(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	results = toolResults(req.Request.Contents)
+	if third := results[len(results)-1]; third["unchanged"] != nil || third["content"] == nil {
+		t.Errorf("re-read of a changed file = %v", third)
+	}
+}
+
+func TestSameContentInAnotherFileIsSent(t *testing.T) {
+	dir := t.TempDir()
+	big := strings.Repeat("some line of text\n", 500)
+	var calls []api.FunctionCall
+	for _, name := range []string{"a.txt", "b.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(big), 0644); err != nil {
+			t.Fatal(err)
+		}
+		calls = append(calls, api.FunctionCall{Name: "read_file", Args: map[string]interface{}{"file_path": path}})
+	}
+	p := &scriptedProvider{calls: calls}
+	formatter, _ := output.NewFormatter("json", io.Discard, io.Discard, true)
+	loop := NewLoop(p, tools.NewRegistry(tools.RegistryOptions{WorkDir: dir, AutoApprove: true}), nil, formatter, Config{MaxTurns: 10})
+
+	req := &api.GenerateRequest{}
+	req.Request.Contents = []api.Content{{Role: "user", Parts: []api.Part{{Text: "read both"}}}}
+	if err := loop.Run(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if second := toolResults(req.Request.Contents)[1]; second["unchanged"] != nil || second["content"] == nil {
+		t.Errorf("read of a copy = %v", second)
+	}
+}
+
+func toolResults(contents []api.Content) []map[string]interface{} {
+	var results []map[string]interface{}
+	for _, c := range contents {
+		for _, part := range c.Parts {
+			if part.FunctionResp != nil {
+				results = append(results, part.FunctionResp.Response)
+			}
+		}
+	}
+	return results
+}
@@ -5,12 +5,20 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/approval"
+	"github.com/k-sub1995/g/internal/audit"
+	"github.com/k-sub1995/g/internal/history"
 	"github.com/k-sub1995/g/internal/mcp"
 	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/redact"
+	"github.com/k-sub1995/g/internal/summary"
 	"github.com/k-sub1995/g/internal/tools"
 )
 
@@ -23,34 +31,54 @@ type Config struct {
 	MaxTurns  int
 	Streaming bool
 	Debug     bool
+	BlobDir   string           // where binary MCP results (e.g. screenshots) are saved
+	Redactor  *redact.Redactor // masks secrets in tool results; nil disables
+	Audit     *audit.Log       // records every tool execution; nil disables
+	History   *history.Manager // keeps the conversation within budget; nil disables
+
+	// ToolOutputTokens bounds the tool results of one turn (0: the default)
+	ToolOutputTokens int
+
+	// Tool consent (MCP tools and ConfirmTools)
+	AutoApprove  bool                        // --yolo: run every tool without asking
+	AllowedTools []string                    // tools.allowed from settings
+	ConfirmTools []string                    // built-in tools that also need approval
+	Prompter     approval.Prompter           // asks the user; nil denies unapproved tools
+	PersistAllow func(toolName string) error // records an "always allow" answer
 }
 
-// MCPClients maps server names to initialized MCP clients.
-type MCPClients map[string]*mcp.Client
-
 // Loop runs the agentic loop.
 type Loop struct {
-	apiClient  *api.Client
-	registry   *tools.Registry
-	mcpClients MCPClients
-	formatter  output.Formatter
-	config     Config
+	apiClient api.Provider
+	registry  *tools.Registry
+	mcp       *mcp.Manager
+	formatter output.Formatter
+	config    Config
+	consent   *consent
+	sent      *contentStore // file contents sent in the session
+	summary   summary.Run   // what the loop did across its runs, without files
 }
 
 // NewLoop creates a new agent loop.
-func NewLoop(apiClient *api.Client, registry *tools.Registry,
-	mcpClients MCPClients, formatter output.Formatter, config Config) *Loop {
+func NewLoop(apiClient api.Provider, registry *tools.Registry,
+	mcpManager *mcp.Manager, formatter output.Formatter, config Config) *Loop {
 	return &Loop{
-		apiClient:  apiClient,
-		registry:   registry,
-		mcpClients: mcpClients,
-		formatter:  formatter,
-		config:     config,
+		apiClient: apiClient,
+		registry:  registry,
+		mcp:       mcpManager,
+		formatter: formatter,
+		config:    config,
+		consent:   newConsent(config.AllowedTools),
+		sent:      newContentStore(),
 	}
 }
 
 // Run executes the agent loop with the given request.
 func (l *Loop) Run(ctx context.Context, req *api.GenerateRequest) error {
+	ctx = api.WithProgress(ctx, func(stage, detail string) {
+		l.formatter.WriteProgress(stage, detail)
+	})
+	results := newResultCache()
 	for turn := 0; turn < l.config.MaxTurns; turn++ {
 		select {
 		case <-ctx.Done():
@@ -62,7 +90,11 @@ func (l *Loop) Run(ctx context.Context, req *api.GenerateRequest) error {
 			fmt.Fprintf(os.Stderr, "[agent] turn %d/%d\n", turn+1, l.config.MaxTurns)
 		}
 
-		// Step 1: Call the API
+		// Step 1: Call the API, first shrinking old history if needed
+		if n := l.config.History.Fit(req.Request.Contents); n > 0 {
+			l.formatter.WriteProgress("history", fmt.Sprintf("shortened %d old parts of the conversation to fit the context budget", n))
+		}
+		l.summary.Turns++
 		modelParts, err := l.callModel(ctx, req)
 		if err != nil {
 			return err
@@ -96,8 +128,15 @@ func (l *Loop) Run(ctx context.Context, req *api.GenerateRequest) error {
 		})
 
 		// Step 5: Execute all function calls and collect results
-		var resultParts []api.Part
+		var resultParts, blobParts []api.Part
+		var stop error // a tool ended the run, e.g. at the change limit
 		for _, fc := range functionCalls {
+			if stop != nil {
+				resultParts = append(resultParts, api.Part{
+					FunctionResp: &api.FunctionResp{Name: fc.Name, Response: map[string]interface{}{"error": "not run: " + stop.Error()}},
+				})
+				continue
+			}
 			if l.config.Debug {
 				fmt.Fprintf(os.Stderr, "[agent] calling tool: %s\n", fc.Name)
 			}
@@ -105,10 +144,39 @@ func (l *Loop) Run(ctx context.Context, req *api.GenerateRequest) error {
 			// Write tool call to formatter
 			l.formatter.WriteToolCall(fc.Name, fc.Args)
 
-			result, execErr := l.executeTool(ctx, fc)
+			// An exact repeat of an earlier read refers to its result
+			if ref := results.lookup(fc, req.Request.Contents); ref != nil {
+				l.formatter.WriteToolResult(fc.Name, ref, false)
+				resultParts = append(resultParts, api.Part{
+					FunctionResp: &api.FunctionResp{Name: fc.Name, Response: ref},
+				})
+				continue
+			}
+
+			var result map[string]interface{}
+			var extraParts []api.Part
+			approvedBy, execErr := l.approve(ctx, fc)
+			if execErr == nil {
+				result, extraParts, execErr = l.executeTool(ctx, fc)
+			}
 			if execErr != nil {
 				result = map[string]interface{}{"error": execErr.Error()}
 			}
+			if errors.Is(execErr, tools.ErrChangeLimit) {
+				stop = execErr
+			}
+			// Secrets must not reach the terminal log or the API
+			result = l.config.Redactor.Map(result)
+			l.recordAudit(fc, result, approvedBy, execErr)
+			l.summary.ToolCalls++
+			if command, _ := fc.Args["command"].(string); fc.Name == "run_shell_command" && approvedBy != approvalDenied {
+				c := summary.Command{Command: l.config.Redactor.String(command), ExitCode: exitCode(result)}
+				c.Error, _ = result["error"].(string)
+				l.summary.Commands = append(l.summary.Commands, c)
+			}
+			if fc.Name == "read_file" && execErr == nil {
+				result = l.sent.dedupe(result, req.Request.Contents, location{content: len(req.Request.Contents), part: len(resultParts)})
+			}
 
 			if l.config.Debug {
 				fmt.Fprintf(os.Stderr, "[agent] tool %s result keys: ", fc.Name)
@@ -121,19 +189,34 @@ func (l *Loop) Run(ctx context.Context, req *api.GenerateRequest) error {
 			// Write tool result to formatter
 			l.formatter.WriteToolResult(fc.Name, result, execErr != nil)
 
+			if _, isBuiltin := l.registry.Get(fc.Name); !isBuiltin || !tools.IsReadOnly(fc.Name) {
+				results.clear()
+			} else if _, failed := result["error"]; execErr == nil && !failed {
+				results.add(fc, turn+1, len(req.Request.Contents), len(resultParts))
+			}
+
 			resultParts = append(resultParts, api.Part{
 				FunctionResp: &api.FunctionResp{
 					Name:     fc.Name,
 					Response: result,
 				},
 			})
+			blobParts = append(blobParts, extraParts...)
 		}
+		if n := fitToolResults(resultParts, l.config.ToolOutputTokens); n > 0 && l.config.Debug {
+			fmt.Fprintf(os.Stderr, "[agent] shortened %d tool results to fit the turn budget\n", n)
+		}
+		// Binary content follows the function responses it belongs to
+		resultParts = append(resultParts, blobParts...)
 
 		// Step 6: Append tool results as "user" role (Gemini API convention)
 		req.Request.Contents = append(req.Request.Contents, api.Content{
 			Role:  "user",
 			Parts: resultParts,
 		})
+		if stop != nil {
+			return stop
+		}
 
 		// Loop continues to next turn
 	}
@@ -160,6 +243,18 @@ func (l *Loop) callModelStreaming(ctx context.Context, req *api.GenerateRequest)
 	var currentText string
 	var lastTextSignature string
 
+	// flushText ends the current text part
+	flushText := func() {
+		if currentText != "" {
+			parts = append(parts, api.Part{
+				Text:             currentText,
+				ThoughtSignature: lastTextSignature,
+			})
+			currentText = ""
+			lastTextSignature = ""
+		}
+	}
+
 	for event := range stream {
 		switch event.Type {
 		case "error":
@@ -174,14 +269,7 @@ func (l *Loop) callModelStreaming(ctx context.Context, req *api.GenerateRequest)
 			}
 		case "tool_call":
 			// Flush accumulated text as a part before adding tool call
-			if currentText != "" {
-				parts = append(parts, api.Part{
-					Text:             currentText,
-					ThoughtSignature: lastTextSignature,
-				})
-				currentText = ""
-				lastTextSignature = ""
-			}
+			flushText()
 			if event.ToolCall != nil {
 				part := api.Part{FunctionCall: event.ToolCall}
 				if event.ThoughtSignature != "" {
@@ -189,7 +277,20 @@ func (l *Loop) callModelStreaming(ctx context.Context, req *api.GenerateRequest)
 				}
 				parts = append(parts, part)
 			}
+		case "code":
+			// Code run by the API's code execution tool
+			flushText()
+			parts = append(parts, api.Part{
+				ExecutableCode:      event.Code,
+				CodeExecutionResult: event.CodeResult,
+				ThoughtSignature:    event.ThoughtSignature,
+			})
+			l.formatter.WriteStreamEvent(&event)
 		case "done":
+			l.debugIDs(event.TraceID, event.RequestID)
+			if event.Usage != nil {
+				l.addUsage(*event.Usage)
+			}
 			l.formatter.WriteStreamEvent(&event)
 		case "start":
 			l.formatter.WriteStreamEvent(&event)
@@ -219,11 +320,21 @@ func (l *Loop) callModelStreaming(ctx context.Context, req *api.GenerateRequest)
 	return parts, nil
 }
 
+// debugIDs prints the IDs of a model response, which Google or Anthropic
+// support can use to find the request.
+func (l *Loop) debugIDs(traceID, requestID string) {
+	if l.config.Debug && (traceID != "" || requestID != "") {
+		fmt.Fprintf(os.Stderr, "[api] trace ID %q, request ID %q\n", traceID, requestID)
+	}
+}
+
 func (l *Loop) callModelNonStreaming(ctx context.Context, req *api.GenerateRequest) ([]api.Part, error) {
 	resp, err := l.apiClient.Generate(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	l.debugIDs(resp.TraceID, resp.RequestID)
+	l.addUsage(resp.Response.UsageMetadata)
 
 	var parts []api.Part
 	hasFunctionCalls := false
@@ -245,31 +356,121 @@ func (l *Loop) callModelNonStreaming(ctx context.Context, req *api.GenerateReque
 	return parts, nil
 }
 
-// executeTool dispatches to built-in or MCP tools.
-func (l *Loop) executeTool(ctx context.Context, fc api.FunctionCall) (map[string]interface{}, error) {
+// executeTool dispatches to built-in or MCP tools. Binary MCP content is
+// returned as inlineData parts for the model and saved under BlobDir.
+func (l *Loop) executeTool(ctx context.Context, fc api.FunctionCall) (map[string]interface{}, []api.Part, error) {
 	// Try built-in tools first
 	if tool, ok := l.registry.Get(fc.Name); ok {
 		result, err := tool.Execute(ctx, fc.Args)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		return result.Content, nil
+		return result.Content, nil, nil
 	}
 
 	// Try MCP tools
 	if ref, ok := l.registry.GetMCPRef(fc.Name); ok {
-		client, ok := l.mcpClients[ref.ServerName]
-		if !ok {
-			return nil, fmt.Errorf("MCP server %q not connected", ref.ServerName)
+		if l.mcp == nil {
+			return nil, nil, fmt.Errorf("MCP server %q not connected", ref.ServerName)
+		}
+		client, err := l.mcp.Client(ctx, ref.ServerName)
+		if err != nil {
+			return nil, nil, err
 		}
-		resultText, err := client.CallTool(ctx, ref.ToolName, fc.Args)
+		callResult, err := client.CallToolResult(ctx, ref.ToolName, fc.Args)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		result := map[string]interface{}{"result": callResult.Text}
+		if len(callResult.Blobs) == 0 {
+			return result, nil, nil
+		}
+
+		var parts []api.Part
+		var saved []string
+		for i, blob := range callResult.Blobs {
+			parts = append(parts, api.Part{InlineData: &api.Blob{MimeType: blob.MimeType, Data: blob.Data}})
+			if path, err := saveBlob(l.config.BlobDir, fc.Name, i, blob); err == nil {
+				saved = append(saved, path)
+			} else if l.config.Debug {
+				fmt.Fprintf(os.Stderr, "[agent] failed to save %s output: %v\n", fc.Name, err)
+			}
+		}
+		result["attachments"] = len(parts)
+		if len(saved) > 0 {
+			result["saved_files"] = saved
+		}
+		return result, parts, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown tool: %s", fc.Name)
+}
+
+// recordAudit appends the tool execution to the audit log. Arguments are
+// redacted like results so the log does not become a store of secrets.
+func (l *Loop) recordAudit(fc api.FunctionCall, result map[string]interface{}, approvedBy string, execErr error) {
+	if l.config.Audit == nil {
+		return
+	}
+	entry := audit.Entry{
+		Tool:     fc.Name,
+		Approval: approvedBy,
+		Status:   "ok",
+	}
+	entry.Args, _ = json.Marshal(l.config.Redactor.Map(fc.Args))
+	entry.CWD, _ = os.Getwd()
+	if dir, ok := fc.Args["dir_path"].(string); ok && dir != "" {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(entry.CWD, dir)
 		}
-		return map[string]interface{}{"result": resultText}, nil
+		entry.CWD = dir
+	}
+	entry.ExitCode = exitCode(result)
+	if errMsg, ok := result["error"].(string); ok {
+		entry.Status = "error"
+		entry.Error = errMsg
+	}
+	if approvedBy == approvalDenied {
+		entry.Status = "denied"
+	}
+	if data, err := json.Marshal(result); err == nil {
+		entry.Tokens = history.EstimateText(string(data))
+	}
+	// grep_search, glob, list_directory and others report what they found
+	if count, ok := result["count"].(int); ok && count == 0 {
+		entry.Empty = true
+	}
+	if err := l.config.Audit.Record(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write audit log: %v\n", err)
 	}
+}
+
+// exitCode returns the exit code a shell tool reported, or nil.
+func exitCode(result map[string]interface{}) *int {
+	switch code := result["exit_code"].(type) {
+	case int:
+		return &code
+	case float64:
+		c := int(code)
+		return &c
+	}
+	return nil
+}
+
+// addUsage adds the token counts of one model response to the summary.
+func (l *Loop) addUsage(u api.UsageMetadata) {
+	l.summary.Tokens.Input += u.PromptTokenCount
+	l.summary.Tokens.Output += u.CandidatesTokenCount
+	l.summary.Tokens.Total += u.TotalTokenCount
+}
 
-	return nil, fmt.Errorf("unknown tool: %s", fc.Name)
+// Summary returns the turns, tool calls, tokens and shell commands of the
+// loop's runs so far. Files are left to the caller, which can see the
+// workspace before and after.
+func (l *Loop) Summary() summary.Run {
+	s := l.summary
+	s.Commands = append([]summary.Command{}, s.Commands...)
+	return s
 }
 
 // ensureThoughtSignatures adds synthetic thought signatures to FunctionCall parts
@@ -0,0 +1,141 @@
+// Package anthropic implements api.Provider on the Anthropic Messages API,
+// mapping tool_use/tool_result blocks onto g's Gemini content model.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+const (
+	// DefaultBaseURL is the Anthropic API endpoint.
+	DefaultBaseURL = "https://api.anthropic.com"
+	apiVersion     = "2023-06-01"
+
+	// ModelPrefix identifies models served by this provider.
+	ModelPrefix = "claude-"
+
+	defaultMaxTokens = 16384
+	maxRetries       = 4
+)
+
+// Client is an Anthropic Messages API client.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+	APIKey     string
+	// MaxTokens caps max_tokens, which the Messages API requires and which
+	// is lower for most Claude models than g's Gemini default.
+	MaxTokens int
+}
+
+// NewFromEnv creates a client from ANTHROPIC_API_KEY (and optionally
+// ANTHROPIC_BASE_URL). It returns nil when no key is set.
+func NewFromEnv() *Client {
+	key := os.Getenv("ANTHROPIC_API_KEY")
+	if key == "" {
+		return nil
+	}
+	base := os.Getenv("ANTHROPIC_BASE_URL")
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	return &Client{HTTPClient: http.DefaultClient, BaseURL: base, APIKey: key, MaxTokens: defaultMaxTokens}
+}
+
+// models are the Claude models a Client serves, as /v1/models lists them.
+var models = []string{"claude-opus-4-1", "claude-sonnet-4-5", "claude-haiku-4-5"}
+
+// Models implements api.ModelLister.
+func (c *Client) Models() []string {
+	return append([]string(nil), models...)
+}
+
+// Generate implements api.Provider.
+func (c *Client) Generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	resp, err := c.post(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var msg message
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	out := msg.toGenerateResponse()
+	out.RequestID = api.RequestID(resp.Header)
+	return out, nil
+}
+
+// post sends a Messages request, retrying on rate limits and overload.
+func (c *Client) post(ctx context.Context, req *api.GenerateRequest, stream bool) (*http.Response, error) {
+	body, err := json.Marshal(c.buildRequest(req, stream))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/v1/messages", bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("x-api-key", c.APIKey)
+		httpReq.Header.Set("anthropic-version", apiVersion)
+
+		resp, err := c.HTTPClient.Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == 529 || resp.StatusCode >= 500
+		if !retryable || attempt == maxRetries {
+			return nil, apiError(resp, data)
+		}
+		delay := time.Duration(1<<attempt) * time.Second
+		if s, err := strconv.Atoi(resp.Header.Get("retry-after")); err == nil && s > 0 {
+			delay = time.Duration(s) * time.Second
+		}
+		api.ReportProgress(ctx, "retry", fmt.Sprintf("%v (retry %d/%d); retrying in %s", apiError(resp, data), attempt+1, maxRetries, delay))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func apiError(resp *http.Response, body []byte) error {
+	status := resp.StatusCode
+	var ids string
+	if id := api.RequestID(resp.Header); id != "" {
+		ids = " [request ID " + id + "]"
+	}
+	var e struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &e) == nil && e.Error.Message != "" {
+		return fmt.Errorf("anthropic API error (%d %s): %s%s", status, e.Error.Type, e.Error.Message, ids)
+	}
+	return fmt.Errorf("anthropic API error (%d): %s%s", status, string(body), ids)
+}
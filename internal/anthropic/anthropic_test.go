@@ -0,0 +1,136 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+func toolConversation() *api.GenerateRequest {
+	return &api.GenerateRequest{
+		Model: "claude-sonnet-4-5",
+		Request: api.InnerRequest{
+			SystemInstruction: &api.Content{Parts: []api.Part{{Text: "Be brief."}}},
+			Contents: []api.Content{
+				{Role: "user", Parts: []api.Part{{Text: "List files"}}},
+				{Role: "model", Parts: []api.Part{
+					{Text: "Checking."},
+					{FunctionCall: &api.FunctionCall{Name: "list_directory", Args: map[string]interface{}{"dir_path": "."}}},
+				}},
+				{Role: "user", Parts: []api.Part{
+					{InlineData: &api.Blob{MimeType: "image/png", Data: "aGk="}},
+					{FunctionResp: &api.FunctionResp{Name: "list_directory", Response: map[string]interface{}{"entries": "go.mod"}}},
+				}},
+			},
+			Config: api.GenerationConfig{Temperature: 1.0, TopP: 0.95, MaxOutputTokens: 65536},
+			Tools: []api.Tool{{FunctionDeclarations: []api.FunctionDecl{
+				{Name: "list_directory", Description: "List", Parameters: json.RawMessage(`{"type":"object"}`)},
+			}}},
+		},
+	}
+}
+
+func TestBuildRequest(t *testing.T) {
+	c := &Client{}
+	req := c.buildRequest(toolConversation(), false)
+
+	if req.System != "Be brief." || req.MaxTokens != defaultMaxTokens || *req.Temperature != 1.0 {
+		t.Errorf("request = %+v", req)
+	}
+	if len(req.Messages) != 3 {
+		t.Fatalf("messages = %+v", req.Messages)
+	}
+	use := req.Messages[1].Content[1]
+	if req.Messages[1].Role != "assistant" || use.Type != "tool_use" || string(use.Input) != `{"dir_path":"."}` {
+		t.Errorf("tool_use = %+v", use)
+	}
+	result := req.Messages[2].Content[0]
+	if result.Type != "tool_result" || result.ToolUseID != use.ID || result.Content != `{"entries":"go.mod"}` {
+		t.Errorf("tool_result must come first and match the tool_use: %+v", req.Messages[2].Content)
+	}
+	if req.Messages[2].Content[1].Type != "image" {
+		t.Errorf("image block = %+v", req.Messages[2].Content[1])
+	}
+	if len(req.Tools) != 1 || string(req.Tools[0].InputSchema) != `{"type":"object"}` {
+		t.Errorf("tools = %+v", req.Tools)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("missing auth headers: %v", r.Header)
+		}
+		fmt.Fprint(w, `{"id":"msg_1","content":[{"type":"text","text":"Let me look."},{"type":"tool_use","id":"toolu_1","name":"read_file","input":{"file_path":"go.mod"}}],"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":5}}`)
+	}))
+	defer srv.Close()
+
+	c := &Client{HTTPClient: srv.Client(), BaseURL: srv.URL, APIKey: "key"}
+	resp, err := c.Generate(context.Background(), toolConversation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cand := resp.Response.Candidates[0]
+	if len(cand.Content.Parts) != 2 || cand.Content.Parts[1].FunctionCall.Args["file_path"] != "go.mod" {
+		t.Errorf("parts = %+v", cand.Content.Parts)
+	}
+	if cand.FinishReason != "STOP" || resp.Response.UsageMetadata.TotalTokenCount != 15 {
+		t.Errorf("response = %+v", resp.Response)
+	}
+}
+
+func TestGenerateStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range []string{
+			`{"type":"message_start","message":{"usage":{"input_tokens":12}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hel"}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"lo"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"glob","input":{}}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"pattern\":"}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"*.go\"}"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":7}}`,
+			`{"type":"message_stop"}`,
+		} {
+			fmt.Fprintf(w, "event: x\ndata: %s\n\n", ev)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{HTTPClient: srv.Client(), BaseURL: srv.URL, APIKey: "key"}
+	events, err := c.GenerateStream(context.Background(), toolConversation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text string
+	var call *api.FunctionCall
+	var done api.StreamEvent
+	for ev := range events {
+		switch ev.Type {
+		case "content":
+			text += ev.Text
+		case "tool_call":
+			call = ev.ToolCall
+		case "done":
+			done = ev
+		case "error":
+			t.Fatal(ev.Error)
+		}
+	}
+	if text != "Hello" || call == nil || call.Name != "glob" || call.Args["pattern"] != "*.go" {
+		t.Errorf("text %q call %+v", text, call)
+	}
+	if done.Usage == nil || done.Usage.TotalTokenCount != 19 || done.FinishReason != "STOP" {
+		t.Errorf("done = %+v", done)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+type request struct {
+	Model         string         `json:"model"`
+	MaxTokens     int            `json:"max_tokens"`
+	System        string         `json:"system,omitempty"`
+	Messages      []messageParam `json:"messages"`
+	Tools         []toolParam    `json:"tools,omitempty"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	TopK          int            `json:"top_k,omitempty"`
+	StopSequences []string       `json:"stop_sequences,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+}
+
+type messageParam struct {
+	Role    string  `json:"role"`
+	Content []block `json:"content"`
+}
+
+// block is a content block of any type; unused fields are omitted.
+type block struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+	Source    *mediaSource    `json:"source,omitempty"`
+}
+
+type mediaSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type toolParam struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type message struct {
+	ID         string  `json:"id"`
+	Model      string  `json:"model"`
+	Content    []block `json:"content"`
+	StopReason string  `json:"stop_reason"`
+	Usage      usage   `json:"usage"`
+}
+
+type usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// buildRequest translates a Gemini-shaped request into a Messages request.
+func (c *Client) buildRequest(req *api.GenerateRequest, stream bool) request {
+	cfg := req.Request.Config
+	out := request{
+		Model:         req.Model,
+		MaxTokens:     cfg.MaxOutputTokens,
+		Messages:      convertContents(req.Request.Contents),
+		TopK:          cfg.TopK,
+		StopSequences: cfg.StopSequences,
+		Stream:        stream,
+	}
+	limit := c.MaxTokens
+	if limit <= 0 {
+		limit = defaultMaxTokens
+	}
+	if out.MaxTokens <= 0 || out.MaxTokens > limit {
+		out.MaxTokens = limit
+	}
+	// top_p is left out: several Claude models reject it alongside temperature
+	if cfg.Temperature > 0 {
+		t := cfg.Temperature
+		if t > 1 {
+			t = 1
+		}
+		out.Temperature = &t
+	}
+	if si := req.Request.SystemInstruction; si != nil {
+		var texts []string
+		for _, p := range si.Parts {
+			if p.Text != "" {
+				texts = append(texts, p.Text)
+			}
+		}
+		out.System = strings.Join(texts, "\n\n")
+	}
+	for _, t := range req.Request.Tools {
+		for _, d := range t.FunctionDeclarations {
+			schema := d.Parameters
+			if len(schema) == 0 || string(schema) == "null" {
+				schema = json.RawMessage(`{"type":"object","properties":{}}`)
+			}
+			out.Tools = append(out.Tools, toolParam{Name: d.Name, Description: d.Description, InputSchema: schema})
+		}
+	}
+	return out
+}
+
+// convertContents maps Gemini contents to alternating Messages turns.
+// Gemini function calls carry no IDs, so each call gets a synthetic
+// tool_use ID and the next response with the same name answers it.
+func convertContents(contents []api.Content) []messageParam {
+	var msgs []messageParam
+	pending := map[string][]string{} // function name -> unanswered tool_use IDs
+
+	for i, c := range contents {
+		role := "user"
+		if c.Role == "model" {
+			role = "assistant"
+		}
+		var blocks []block
+		for j, p := range c.Parts {
+			switch {
+			case p.FunctionCall != nil:
+				id := fmt.Sprintf("toolu_g%d_%d", i, j)
+				pending[p.FunctionCall.Name] = append(pending[p.FunctionCall.Name], id)
+				input, _ := json.Marshal(p.FunctionCall.Args)
+				if string(input) == "null" {
+					input = []byte("{}")
+				}
+				blocks = append(blocks, block{Type: "tool_use", ID: id, Name: p.FunctionCall.Name, Input: input})
+			case p.FunctionResp != nil:
+				ids := pending[p.FunctionResp.Name]
+				if len(ids) == 0 {
+					// Unmatched response: keep its content as text
+					data, _ := json.Marshal(p.FunctionResp.Response)
+					blocks = append(blocks, block{Type: "text", Text: fmt.Sprintf("[%s result] %s", p.FunctionResp.Name, data)})
+					continue
+				}
+				pending[p.FunctionResp.Name] = ids[1:]
+				data, _ := json.Marshal(p.FunctionResp.Response)
+				_, isErr := p.FunctionResp.Response["error"]
+				blocks = append(blocks, block{Type: "tool_result", ToolUseID: ids[0], Content: string(data), IsError: isErr})
+			case p.InlineData != nil:
+				blocks = append(blocks, mediaBlock(p.InlineData))
+			case p.Text != "":
+				blocks = append(blocks, block{Type: "text", Text: p.Text})
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(msgs); n > 0 && msgs[n-1].Role == role {
+			msgs[n-1].Content = append(msgs[n-1].Content, blocks...)
+		} else {
+			msgs = append(msgs, messageParam{Role: role, Content: blocks})
+		}
+	}
+
+	// tool_result blocks must lead their user message
+	for i := range msgs {
+		if msgs[i].Role == "user" {
+			sort.SliceStable(msgs[i].Content, func(a, b int) bool {
+				return msgs[i].Content[a].Type == "tool_result" && msgs[i].Content[b].Type != "tool_result"
+			})
+		}
+	}
+	return msgs
+}
+
+func mediaBlock(b *api.Blob) block {
+	switch b.MimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return block{Type: "image", Source: &mediaSource{Type: "base64", MediaType: b.MimeType, Data: b.Data}}
+	case "application/pdf":
+		return block{Type: "document", Source: &mediaSource{Type: "base64", MediaType: b.MimeType, Data: b.Data}}
+	}
+	return block{Type: "text", Text: fmt.Sprintf("[%s attachment omitted: unsupported by this model]", b.MimeType)}
+}
+
+// toGenerateResponse maps a Messages response onto a Gemini response.
+func (m *message) toGenerateResponse() *api.GenerateResponse {
+	var parts []api.Part
+	for _, b := range m.Content {
+		switch b.Type {
+		case "text":
+			if b.Text != "" {
+				parts = append(parts, api.Part{Text: b.Text})
+			}
+		case "tool_use":
+			parts = append(parts, api.Part{FunctionCall: toFunctionCall(b.Name, b.Input)})
+		}
+	}
+	return &api.GenerateResponse{
+		Response: api.InnerResponse{
+			Candidates: []api.Candidate{{
+				Content:      api.Content{Role: "model", Parts: parts},
+				FinishReason: finishReason(m.StopReason),
+			}},
+			UsageMetadata: m.Usage.metadata(),
+		},
+		TraceID: m.ID,
+	}
+}
+
+func toFunctionCall(name string, input json.RawMessage) *api.FunctionCall {
+	args := map[string]interface{}{}
+	if len(input) > 0 {
+		_ = json.Unmarshal(input, &args)
+	}
+	return &api.FunctionCall{Name: name, Args: args}
+}
+
+func (u usage) metadata() api.UsageMetadata {
+	return api.UsageMetadata{
+		PromptTokenCount:     u.InputTokens,
+		CandidatesTokenCount: u.OutputTokens,
+		TotalTokenCount:      u.InputTokens + u.OutputTokens,
+	}
+}
+
+// finishReason maps stop_reason to Gemini's finishReason values.
+func finishReason(stop string) string {
+	switch stop {
+	case "max_tokens":
+		return "MAX_TOKENS"
+	case "refusal":
+		return "SAFETY"
+	case "":
+		return ""
+	default: // end_turn, tool_use, stop_sequence, pause_turn
+		return "STOP"
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// streamEvent is any Messages streaming event; fields depend on Type.
+type streamEvent struct {
+	Type         string  `json:"type"`
+	Message      message `json:"message"`
+	Index        int     `json:"index"`
+	ContentBlock block   `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage usage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GenerateStream implements api.Provider. Text deltas are forwarded as
+// they arrive; tool calls are emitted once their input JSON is complete.
+func (c *Client) GenerateStream(ctx context.Context, req *api.GenerateRequest) (<-chan api.StreamEvent, error) {
+	resp, err := c.post(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan api.StreamEvent, api.StreamBuffer)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		send := func(ev api.StreamEvent) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if !send(api.StreamEvent{Type: "start", Model: req.Model}) {
+			return
+		}
+
+		var (
+			u           usage
+			stop        string
+			toolName    string
+			toolInput   strings.Builder
+			inToolBlock bool
+		)
+		reader := api.NewSSEReader(resp.Body)
+		for {
+			sse, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				send(api.StreamEvent{Type: "error", Error: err.Error()})
+				return
+			}
+			var ev streamEvent
+			if err := json.Unmarshal(sse.Data, &ev); err != nil {
+				continue
+			}
+
+			switch ev.Type {
+			case "message_start":
+				u.InputTokens = ev.Message.Usage.InputTokens
+			case "content_block_start":
+				if ev.ContentBlock.Type == "tool_use" {
+					inToolBlock = true
+					toolName = ev.ContentBlock.Name
+					toolInput.Reset()
+				}
+			case "content_block_delta":
+				switch ev.Delta.Type {
+				case "text_delta":
+					if ev.Delta.Text != "" && !send(api.StreamEvent{Type: "content", Text: ev.Delta.Text}) {
+						return
+					}
+				case "input_json_delta":
+					toolInput.WriteString(ev.Delta.PartialJSON)
+				}
+			case "content_block_stop":
+				if inToolBlock {
+					inToolBlock = false
+					call := toFunctionCall(toolName, json.RawMessage(toolInput.String()))
+					if !send(api.StreamEvent{Type: "tool_call", ToolCall: call}) {
+						return
+					}
+				}
+			case "message_delta":
+				if ev.Delta.StopReason != "" {
+					stop = ev.Delta.StopReason
+				}
+				if ev.Usage.OutputTokens > 0 {
+					u.OutputTokens = ev.Usage.OutputTokens
+				}
+			case "error":
+				send(api.StreamEvent{Type: "error", Error: ev.Error.Type + ": " + ev.Error.Message})
+				return
+			}
+		}
+
+		meta := u.metadata()
+		send(api.StreamEvent{Type: "done", Usage: &meta, FinishReason: finishReason(stop), RequestID: api.RequestID(resp.Header)})
+	}()
+	return events, nil
+}
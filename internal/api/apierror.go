@@ -0,0 +1,126 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxRawErrorLen bounds an error body shown as is when it cannot be parsed.
+const maxRawErrorLen = 500
+
+// APIError is an error response from the API. Google APIs return a
+// google.rpc.Status body; its status, ErrorInfo reason and help links are
+// used to explain the failure in a sentence rather than the raw JSON.
+type APIError struct {
+	StatusCode int
+	Status     string   // e.g. NOT_FOUND, PERMISSION_DENIED
+	Message    string   // the API's message, first line
+	Reason     string   // ErrorInfo reason, e.g. SERVICE_DISABLED
+	HelpLinks  []string // URLs from Help details
+	Model      string   // the model requested, when known
+	RequestID  string   // from the response headers, for support requests
+	Body       string   // the raw response body
+}
+
+// RequestID returns the request ID a server put in the response headers,
+// or "".
+func RequestID(h http.Header) string {
+	for _, name := range []string{"X-Request-Id", "Request-Id"} {
+		if id := h.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// newAPIError parses an error response whose body has been read. reqBody
+// is the request that failed, from which the model is taken.
+func newAPIError(resp *http.Response, body, reqBody []byte) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode, RequestID: RequestID(resp.Header), Body: string(body)}
+
+	var errResp struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				Reason string `json:"reason"`
+				Links  []struct {
+					URL string `json:"url"`
+				} `json:"links"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &errResp) == nil {
+		e.Status = errResp.Error.Status
+		e.Message = strings.TrimSpace(errResp.Error.Message)
+		if i := strings.IndexByte(e.Message, '\n'); i >= 0 {
+			e.Message = e.Message[:i]
+		}
+		for _, d := range errResp.Error.Details {
+			if d.Reason != "" && e.Reason == "" {
+				e.Reason = d.Reason
+			}
+			for _, l := range d.Links {
+				if l.URL != "" {
+					e.HelpLinks = append(e.HelpLinks, l.URL)
+				}
+			}
+		}
+	}
+
+	var req struct {
+		Model string `json:"model"`
+	}
+	if json.Unmarshal(reqBody, &req) == nil {
+		e.Model = req.Model
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = strings.TrimSpace(e.Body)
+		if len(msg) > maxRawErrorLen {
+			msg = strings.ToValidUTF8(msg[:maxRawErrorLen], "") + "..."
+		}
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	var s string
+	switch {
+	case e.Reason == "SERVICE_DISABLED":
+		s = "the Gemini for Google Cloud API is not enabled for this project: " + msg + " — enable it, or choose another project with --project"
+	case e.StatusCode == http.StatusNotFound && e.Model != "":
+		s = fmt.Sprintf("model not found: %s — check the --model name", e.Model)
+	case e.StatusCode == http.StatusNotFound:
+		s = "not found: " + msg
+	case e.StatusCode == http.StatusUnauthorized || e.Status == "UNAUTHENTICATED":
+		s = "authentication failed: " + msg + " — run 'gemini' to sign in again"
+	case e.StatusCode == http.StatusForbidden:
+		s = "permission denied: " + msg + " — check the project (--project or G_PROJECT) and that your account can use Gemini Code Assist"
+	case e.Status == "FAILED_PRECONDITION":
+		s = "account not set up: " + msg + " — run 'gemini' once to onboard, or pass --project"
+	case e.StatusCode == http.StatusBadRequest:
+		s = "invalid request: " + msg
+	case e.StatusCode == http.StatusTooManyRequests:
+		s = "rate limited: " + msg
+	case e.StatusCode >= 500:
+		s = fmt.Sprintf("the API is unavailable (status %d): %s — try again later", e.StatusCode, msg)
+	default:
+		s = fmt.Sprintf("API error (status %d): %s", e.StatusCode, msg)
+	}
+	if len(e.HelpLinks) > 0 {
+		s += " (see " + e.HelpLinks[0] + ")"
+	}
+	if e.RequestID != "" {
+		s += " [request ID " + e.RequestID + "]"
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAPIErrorMessage(t *testing.T) {
+	tests := []struct {
+		code int
+		body string
+		want string
+	}{
+		{404, `{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND"}}`,
+			"model not found: gemini-3.0 — check the --model name"},
+		{403, `{"error":{"code":403,"message":"Gemini for Google Cloud API has not been used in project p1 before or it is disabled.","status":"PERMISSION_DENIED",
+			"details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"SERVICE_DISABLED"},
+			{"@type":"type.googleapis.com/google.rpc.Help","links":[{"description":"Console","url":"https://console.developers.google.com/apis/api/cloudaicompanion.googleapis.com/overview?project=p1"}]}]}}`,
+			"the Gemini for Google Cloud API is not enabled for this project: Gemini for Google Cloud API has not been used in project p1 before or it is disabled. — enable it, or choose another project with --project (see https://console.developers.google.com/apis/api/cloudaicompanion.googleapis.com/overview?project=p1)"},
+		{400, `{"error":{"code":400,"message":"Invalid JSON payload received.\nUnknown name \"foo\"","status":"INVALID_ARGUMENT"}}`,
+			"invalid request: Invalid JSON payload received."},
+		{502, `<html>Bad Gateway</html>`,
+			"the API is unavailable (status 502): <html>Bad Gateway</html> — try again later"},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.code, Header: http.Header{}}
+		err := newAPIError(resp, []byte(tt.body), []byte(`{"model":"gemini-3.0"}`))
+		if got := err.Error(); got != tt.want {
+			t.Errorf("status %d:\n got %q\nwant %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestAPIErrorRequestID(t *testing.T) {
+	resp := &http.Response{StatusCode: 500, Header: http.Header{"X-Request-Id": {"req-123"}}}
+	err := newAPIError(resp, []byte(`{"error":{"message":"Internal error encountered.","status":"INTERNAL"}}`), nil)
+	want := "the API is unavailable (status 500): Internal error encountered. — try again later [request ID req-123]"
+	if got := err.Error(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
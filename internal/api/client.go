@@ -4,7 +4,6 @@
 package api
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -13,7 +12,6 @@ import (
 	"math"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 )
 
@@ -38,9 +36,9 @@ func NewClient(httpClient *http.Client) *Client {
 }
 
 const (
-	maxRetries       = 5
-	baseRetryDelay   = 500 * time.Millisecond
-	maxRetryDelay    = 30 * time.Second
+	maxRetries     = 5
+	baseRetryDelay = 500 * time.Millisecond
+	maxRetryDelay  = 30 * time.Second
 )
 
 // doRequestWithRetry executes an HTTP request with retry on 429 (rate limit).
@@ -76,12 +74,18 @@ func (c *Client) doRequestWithRetry(ctx context.Context, httpReq *http.Request,
 		resp.Body.Close()
 
 		if resp.StatusCode != http.StatusTooManyRequests {
-			return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+			return nil, newAPIError(resp, respBody, bodyBytes)
 		}
 
 		// 429: Rate limited — calculate retry delay
 		delay := retryDelay(respBody, resp.Header, attempt)
-		lastErr = fmt.Errorf("API error (status 429): %s", string(respBody))
+		lastErr = newAPIError(resp, respBody, bodyBytes)
+		if qe := quotaExhausted(respBody, delay); qe != nil {
+			return nil, qe // retrying cannot help
+		}
+		if attempt < maxRetries {
+			ReportProgress(ctx, "retry", retryNote(respBody, attempt, delay))
+		}
 
 		select {
 		case <-ctx.Done():
@@ -170,10 +174,31 @@ type Content struct {
 
 // Part represents a content part
 type Part struct {
-	Text             string        `json:"text,omitempty"`
-	FunctionCall     *FunctionCall `json:"functionCall,omitempty"`
-	FunctionResp     *FunctionResp `json:"functionResponse,omitempty"`
-	ThoughtSignature string        `json:"thoughtSignature,omitempty"`
+	Text                string               `json:"text,omitempty"`
+	FunctionCall        *FunctionCall        `json:"functionCall,omitempty"`
+	FunctionResp        *FunctionResp        `json:"functionResponse,omitempty"`
+	InlineData          *Blob                `json:"inlineData,omitempty"`
+	ExecutableCode      *ExecutableCode      `json:"executableCode,omitempty"`
+	CodeExecutionResult *CodeExecutionResult `json:"codeExecutionResult,omitempty"`
+	ThoughtSignature    string               `json:"thoughtSignature,omitempty"`
+}
+
+// ExecutableCode is code the model ran with the code execution tool
+type ExecutableCode struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// CodeExecutionResult is the outcome of running ExecutableCode
+type CodeExecutionResult struct {
+	Outcome string `json:"outcome"` // OUTCOME_OK, OUTCOME_FAILED, OUTCOME_DEADLINE_EXCEEDED
+	Output  string `json:"output,omitempty"`
+}
+
+// Blob holds inline binary data such as an image
+type Blob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"` // base64-encoded
 }
 
 // FunctionCall represents a tool call
@@ -190,21 +215,34 @@ type FunctionResp struct {
 
 // GenerationConfig holds generation parameters
 type GenerationConfig struct {
-	Temperature     float64 `json:"temperature,omitempty"`
-	TopP            float64 `json:"topP,omitempty"`
-	TopK            int     `json:"topK,omitempty"`
-	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+	Temperature        float64         `json:"temperature,omitempty"`
+	TopP               float64         `json:"topP,omitempty"`
+	TopK               int             `json:"topK,omitempty"`
+	MaxOutputTokens    int             `json:"maxOutputTokens,omitempty"`
+	StopSequences      []string        `json:"stopSequences,omitempty"`
+	CandidateCount     int             `json:"candidateCount,omitempty"`
+	ThinkingConfig     *ThinkingConfig `json:"thinkingConfig,omitempty"`
+	ResponseModalities []string        `json:"responseModalities,omitempty"`
+	ImageConfig        *ImageConfig    `json:"imageConfig,omitempty"`
 }
 
 // Tool represents a tool definition
 type Tool struct {
 	FunctionDeclarations []FunctionDecl `json:"functionDeclarations,omitempty"`
 	GoogleSearch         *GoogleSearch  `json:"googleSearch,omitempty"`
+	URLContext           *URLContext    `json:"urlContext,omitempty"`
+	CodeExecution        *CodeExecution `json:"codeExecution,omitempty"`
 }
 
 // GoogleSearch enables Google Search grounding
 type GoogleSearch struct{}
 
+// URLContext lets the model fetch URLs mentioned in the prompt server-side
+type URLContext struct{}
+
+// CodeExecution lets the model run Python in the API's sandbox
+type CodeExecution struct{}
+
 // FunctionDecl represents a function declaration
 type FunctionDecl struct {
 	Name        string          `json:"name"`
@@ -214,8 +252,9 @@ type FunctionDecl struct {
 
 // GenerateResponse is a response from generate content (Code Assist API format)
 type GenerateResponse struct {
-	Response InnerResponse `json:"response"`
-	TraceID  string        `json:"traceId,omitempty"`
+	Response  InnerResponse `json:"response"`
+	TraceID   string        `json:"traceId,omitempty"`
+	RequestID string        `json:"-"` // from the HTTP response headers
 }
 
 // InnerResponse is the inner response structure for Code Assist API
@@ -226,9 +265,9 @@ type InnerResponse struct {
 
 // Candidate represents a response candidate
 type Candidate struct {
-	Content            Content             `json:"content"`
-	FinishReason       string              `json:"finishReason"`
-	GroundingMetadata  *GroundingMetadata  `json:"groundingMetadata,omitempty"`
+	Content           Content            `json:"content"`
+	FinishReason      string             `json:"finishReason"`
+	GroundingMetadata *GroundingMetadata `json:"groundingMetadata,omitempty"`
 }
 
 // GroundingMetadata holds grounding (web search) metadata
@@ -250,8 +289,8 @@ type GroundingChunkWeb struct {
 
 // GroundingSupport represents inline citation support
 type GroundingSupport struct {
-	Segment              *GroundingSegment `json:"segment,omitempty"`
-	GroundingChunkIndices []int            `json:"groundingChunkIndices,omitempty"`
+	Segment               *GroundingSegment `json:"segment,omitempty"`
+	GroundingChunkIndices []int             `json:"groundingChunkIndices,omitempty"`
 }
 
 // GroundingSegment represents a text segment with citation
@@ -293,6 +332,7 @@ func (c *Client) Generate(ctx context.Context, req *GenerateRequest) (*GenerateR
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	result.RequestID = RequestID(resp.Header)
 
 	return &result, nil
 }
@@ -318,15 +358,22 @@ func (c *Client) WebSearch(ctx context.Context, project, model, query string) (*
 
 // StreamEvent represents a streaming event
 type StreamEvent struct {
-	Type             string         `json:"type"`
-	Model            string         `json:"model,omitempty"`
-	Text             string         `json:"text,omitempty"`
-	ToolCall         *FunctionCall  `json:"tool_call,omitempty"`
-	ToolResult       *ToolResult    `json:"tool_result,omitempty"`
-	Usage            *UsageMetadata `json:"usage,omitempty"`
-	Error            string         `json:"error,omitempty"`
-	FinishReason     string         `json:"finish_reason,omitempty"`
-	ThoughtSignature string         `json:"thought_signature,omitempty"`
+	Type             string               `json:"type"`
+	Model            string               `json:"model,omitempty"`
+	Text             string               `json:"text,omitempty"`
+	ToolCall         *FunctionCall        `json:"tool_call,omitempty"`
+	ToolResult       *ToolResult          `json:"tool_result,omitempty"`
+	Code             *ExecutableCode      `json:"code,omitempty"`
+	CodeResult       *CodeExecutionResult `json:"code_result,omitempty"`
+	Usage            *UsageMetadata       `json:"usage,omitempty"`
+	Error            string               `json:"error,omitempty"`
+	FinishReason     string               `json:"finish_reason,omitempty"`
+	ThoughtSignature string               `json:"thought_signature,omitempty"`
+	Sources          []Source             `json:"sources,omitempty"`
+	Stage            string               `json:"stage,omitempty"`      // progress events
+	Detail           string               `json:"detail,omitempty"`     // progress events
+	TraceID          string               `json:"trace_id,omitempty"`   // done events: the API's trace ID
+	RequestID        string               `json:"request_id,omitempty"` // done events: the HTTP request ID
 }
 
 // ToolResult represents a tool execution result
@@ -402,7 +449,7 @@ func (c *Client) LoadCodeAssist(ctx context.Context) (*LoadCodeAssistResponse, e
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(resp, bodyBytes, body)
 	}
 
 	var result LoadCodeAssistResponse
@@ -414,95 +461,17 @@ func (c *Client) LoadCodeAssist(ctx context.Context) (*LoadCodeAssistResponse, e
 }
 
 // GenerateStream sends a streaming generate request with automatic 429 retry.
+// A stream that drops before the response is complete is resumed; see
+// pumpStream.
 func (c *Client) GenerateStream(ctx context.Context, req *GenerateRequest) (<-chan StreamEvent, error) {
-	endpoint := fmt.Sprintf("%s/%s:streamGenerateContent?alt=sse", c.baseURL, apiVersion)
-
-	body, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "text/event-stream")
-
-	resp, err := c.doRequestWithRetry(ctx, httpReq, body)
+	resp, err := c.openStream(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	events := make(chan StreamEvent)
-
-	go func() {
-		defer close(events)
-		defer resp.Body.Close()
-
-		// Send start event
-		events <- StreamEvent{Type: "start", Model: req.Model}
-
-		reader := bufio.NewReader(resp.Body)
-		var usage *UsageMetadata
-		var finishReason string
-
-		for {
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				if err != io.EOF {
-					events <- StreamEvent{Type: "error", Error: err.Error()}
-				}
-				break
-			}
-
-			line = strings.TrimSpace(line)
-			if line == "" || !strings.HasPrefix(line, "data: ") {
-				continue
-			}
-
-			data := strings.TrimPrefix(line, "data: ")
-			if data == "[DONE]" {
-				break
-			}
-
-			var chunk GenerateResponse
-			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-				continue
-			}
-
-			// Store usage for final event
-			if chunk.Response.UsageMetadata.TotalTokenCount > 0 {
-				usage = &chunk.Response.UsageMetadata
-			}
-
-			// Extract text and tool calls from candidates
-			for _, candidate := range chunk.Response.Candidates {
-				if candidate.FinishReason != "" {
-					finishReason = candidate.FinishReason
-				}
-				for _, part := range candidate.Content.Parts {
-					if part.Text != "" {
-						events <- StreamEvent{
-							Type:             "content",
-							Text:             part.Text,
-							ThoughtSignature: part.ThoughtSignature,
-						}
-					}
-					if part.FunctionCall != nil {
-						events <- StreamEvent{
-							Type:             "tool_call",
-							ToolCall:         part.FunctionCall,
-							ThoughtSignature: part.ThoughtSignature,
-						}
-					}
-				}
-			}
-		}
-
-		// Send done event
-		events <- StreamEvent{Type: "done", Usage: usage, FinishReason: finishReason}
-	}()
-
+	// Decoded events are buffered so a slow consumer does not stall the
+	// connection; reading pauses only once the buffer is full.
+	events := make(chan StreamEvent, StreamBuffer)
+	go c.pumpStream(ctx, req, resp, events)
 	return events, nil
 }
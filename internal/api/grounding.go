@@ -0,0 +1,186 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// groundingRedirectHost serves the links in grounding chunks, which
+// redirect to the cited page.
+const groundingRedirectHost = "vertexaisearch.cloud.google.com"
+
+// resolveTimeout bounds resolving all the redirect links of a response.
+const resolveTimeout = 5 * time.Second
+
+// Source is a web page a grounded answer is based on.
+type Source struct {
+	Title string `json:"title,omitempty"`
+	URI   string `json:"uri"`
+}
+
+// Sources returns the web sources cited by the response's candidates.
+func (r *GenerateResponse) Sources() []Source {
+	var sources []Source
+	for _, c := range r.Response.Candidates {
+		sources = addSources(sources, c.GroundingMetadata)
+	}
+	return sources
+}
+
+// addSources appends the web sources in gm that are not in sources yet.
+// Streamed responses repeat the metadata in several chunks.
+func addSources(sources []Source, gm *GroundingMetadata) []Source {
+	if gm == nil {
+		return sources
+	}
+	for _, chunk := range gm.GroundingChunks {
+		if chunk.Web == nil || chunk.Web.URI == "" {
+			continue
+		}
+		uri := chunk.Web.URI
+		if slices.ContainsFunc(sources, func(s Source) bool { return s.URI == uri }) {
+			continue
+		}
+		sources = append(sources, Source{Title: chunk.Web.Title, URI: chunk.Web.URI})
+	}
+	return sources
+}
+
+// ResolveGroundingURIs replaces the redirect links in gm's chunks with the
+// pages they lead to, so that sources can be quoted and deduplicated.
+// Links that cannot be resolved in time are kept.
+func ResolveGroundingURIs(ctx context.Context, gm *GroundingMetadata) {
+	if gm == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	var wg sync.WaitGroup
+	for _, chunk := range gm.GroundingChunks {
+		web := chunk.Web
+		if web == nil {
+			continue
+		}
+		if u, err := url.Parse(web.URI); err != nil || u.Host != groundingRedirectHost {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if dest := redirectTarget(ctx, client, web.URI); dest != "" {
+				web.URI = dest
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// redirectTarget returns where uri redirects to, or "".
+func redirectTarget(ctx context.Context, client *http.Client, uri string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, uri, nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	loc, err := resp.Location()
+	if err != nil {
+		return ""
+	}
+	return loc.String()
+}
+
+// Cite returns text with citation markers such as "[1][3]" after each
+// segment gm's supports attribute to sources, and the sources the numbers
+// refer to. Chunks with the same link are one source.
+func Cite(text string, gm *GroundingMetadata) (string, []Source) {
+	if gm == nil {
+		return text, nil
+	}
+	var sources []Source
+	number := map[int]int{} // chunk index to source number
+	for i, chunk := range gm.GroundingChunks {
+		if chunk.Web == nil || chunk.Web.URI == "" {
+			continue
+		}
+		n := slices.IndexFunc(sources, func(s Source) bool { return s.URI == chunk.Web.URI })
+		if n < 0 {
+			sources = append(sources, Source{Title: chunk.Web.Title, URI: chunk.Web.URI})
+			n = len(sources) - 1
+		}
+		number[i] = n + 1
+	}
+
+	// Markers by the byte offset they go at
+	markers := map[int][]int{}
+	for _, sup := range gm.GroundingSupports {
+		end := segmentEnd(text, sup.Segment)
+		if end < 0 {
+			continue
+		}
+		for _, idx := range sup.GroundingChunkIndices {
+			if n, ok := number[idx]; ok && !slices.Contains(markers[end], n) {
+				markers[end] = append(markers[end], n)
+			}
+		}
+	}
+	offsets := make([]int, 0, len(markers))
+	for off := range markers {
+		offsets = append(offsets, off)
+	}
+	// Insert from the end, so earlier offsets stay valid
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	for _, off := range offsets {
+		nums := markers[off]
+		if len(nums) == 0 {
+			continue
+		}
+		sort.Ints(nums)
+		var b strings.Builder
+		for _, n := range nums {
+			fmt.Fprintf(&b, "[%d]", n)
+		}
+		text = text[:off] + b.String() + text[off:]
+	}
+	return text, sources
+}
+
+// segmentEnd returns the byte offset in text where seg ends, or -1. The
+// API's offsets are in bytes; a segment whose offset does not match its
+// text is looked up by the text instead.
+func segmentEnd(text string, seg *GroundingSegment) int {
+	if seg == nil {
+		return -1
+	}
+	end := seg.EndIndex
+	if seg.Text != "" && (end < 0 || end > len(text) || !strings.HasSuffix(text[:end], seg.Text)) {
+		i := strings.Index(text, seg.Text)
+		if i < 0 {
+			return -1
+		}
+		end = i + len(seg.Text)
+	}
+	if end <= 0 || end > len(text) {
+		return -1
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	return end
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCite(t *testing.T) {
+	text := "Go 1.22 was released in February 2024. Its loop variables are per-iteration — «new»."
+	gm := &GroundingMetadata{
+		GroundingChunks: []GroundingChunk{
+			{Web: &GroundingChunkWeb{URI: "https://go.dev/blog/go1.22", Title: "go.dev"}},
+			{Web: &GroundingChunkWeb{URI: "https://go.dev/doc/go1.22", Title: "go.dev"}},
+			{Web: &GroundingChunkWeb{URI: "https://go.dev/blog/go1.22", Title: "go.dev"}},
+		},
+		GroundingSupports: []GroundingSupport{
+			{Segment: &GroundingSegment{EndIndex: 38, Text: "Go 1.22 was released in February 2024."}, GroundingChunkIndices: []int{0, 2}},
+			// Offsets that do not match the text fall back to the segment text
+			{Segment: &GroundingSegment{StartIndex: 39, EndIndex: 80, Text: "Its loop variables are per-iteration — «new»."}, GroundingChunkIndices: []int{1, 0}},
+		},
+	}
+	got, sources := Cite(text, gm)
+	want := "Go 1.22 was released in February 2024.[1] Its loop variables are per-iteration — «new».[1][2]"
+	if got != want {
+		t.Errorf("Cite text =\n%q\nwant\n%q", got, want)
+	}
+	if len(sources) != 2 || sources[0].URI != "https://go.dev/blog/go1.22" || sources[1].URI != "https://go.dev/doc/go1.22" {
+		t.Errorf("sources = %+v", sources)
+	}
+
+	if got, sources := Cite("plain", nil); got != "plain" || sources != nil {
+		t.Errorf("Cite without metadata = %q, %v", got, sources)
+	}
+}
+
+func TestRedirectTarget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://example.com/article", http.StatusFound)
+	}))
+	defer srv.Close()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	if got := redirectTarget(context.Background(), client, srv.URL+"/grounding-api-redirect/abc"); got != "https://example.com/article" {
+		t.Errorf("redirectTarget = %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// DefaultImageModel is used by GenerateImage when no model is given.
+const DefaultImageModel = "gemini-2.5-flash-image"
+
+// AspectRatios are the aspect ratios the image models accept.
+var AspectRatios = []string{"1:1", "2:3", "3:2", "3:4", "4:3", "4:5", "5:4", "9:16", "16:9", "21:9"}
+
+// ImageConfig controls generated images. ImageSize ("1K", "2K", "4K") is
+// only supported by some models.
+type ImageConfig struct {
+	AspectRatio string `json:"aspectRatio,omitempty"`
+	ImageSize   string `json:"imageSize,omitempty"`
+}
+
+// Image is a generated image.
+type Image struct {
+	MimeType string
+	Data     []byte
+}
+
+// GenerateImage asks an image model for pictures of prompt. It returns the
+// images and any text the model added.
+func (c *Client) GenerateImage(ctx context.Context, project, model, prompt string, cfg ImageConfig) ([]Image, string, error) {
+	if model == "" {
+		model = DefaultImageModel
+	}
+	req := &GenerateRequest{
+		Model:   model,
+		Project: project,
+		Request: InnerRequest{
+			Contents: []Content{{
+				Role:  "user",
+				Parts: []Part{{Text: prompt}},
+			}},
+			Config: GenerationConfig{
+				ResponseModalities: []string{"TEXT", "IMAGE"},
+				ImageConfig:        &cfg,
+			},
+		},
+	}
+	if cfg == (ImageConfig{}) {
+		req.Request.Config.ImageConfig = nil
+	}
+	resp, err := c.Generate(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var images []Image
+	var text strings.Builder
+	for _, cand := range resp.Response.Candidates {
+		for _, part := range cand.Content.Parts {
+			text.WriteString(part.Text)
+			if part.InlineData == nil || !strings.HasPrefix(part.InlineData.MimeType, "image/") {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to decode image: %w", err)
+			}
+			images = append(images, Image{MimeType: part.InlineData.MimeType, Data: data})
+		}
+	}
+	if len(images) == 0 {
+		reason := strings.TrimSpace(text.String())
+		if reason == "" {
+			reason = "no candidates"
+			if len(resp.Response.Candidates) > 0 {
+				reason = "finish reason " + resp.Response.Candidates[0].FinishReason
+			}
+		}
+		return nil, "", fmt.Errorf("the model returned no image (%s)", reason)
+	}
+	return images, strings.TrimSpace(text.String()), nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// How a model's reasoning is controlled (see Thinking).
+const (
+	ThinkingNone   = "none"   // the model does not think, or g cannot control it
+	ThinkingBudget = "budget" // a token budget (Gemini 2.5)
+	ThinkingLevel  = "level"  // a level (Gemini 3)
+)
+
+// Capabilities describes the limits of a model.
+type Capabilities struct {
+	ContextWindow   int    // input tokens
+	MaxOutputTokens int    // the largest maxOutputTokens accepted
+	Thinking        string // ThinkingNone, ThinkingBudget or ThinkingLevel
+}
+
+// models holds the known models. A model name matches the longest entry it
+// starts with, so dated and preview versions share their family's limits.
+var models = map[string]Capabilities{
+	"gemini-2.0-flash":      {ContextWindow: 1_048_576, MaxOutputTokens: 8192, Thinking: ThinkingNone},
+	"gemini-2.0-flash-lite": {ContextWindow: 1_048_576, MaxOutputTokens: 8192, Thinking: ThinkingNone},
+	"gemini-2.5-pro":        {ContextWindow: 1_048_576, MaxOutputTokens: 65_536, Thinking: ThinkingBudget},
+	"gemini-2.5-flash":      {ContextWindow: 1_048_576, MaxOutputTokens: 65_536, Thinking: ThinkingBudget},
+	"gemini-2.5-flash-lite": {ContextWindow: 1_048_576, MaxOutputTokens: 65_536, Thinking: ThinkingBudget},
+	"gemini-3":              {ContextWindow: 1_048_576, MaxOutputTokens: 65_536, Thinking: ThinkingLevel},
+	"claude-":               {ContextWindow: 200_000, MaxOutputTokens: 64_000, Thinking: ThinkingNone},
+	"claude-opus-4-1":       {ContextWindow: 200_000, MaxOutputTokens: 32_000, Thinking: ThinkingNone},
+	"claude-opus-4-0":       {ContextWindow: 200_000, MaxOutputTokens: 32_000, Thinking: ThinkingNone},
+	"claude-3-5-haiku":      {ContextWindow: 200_000, MaxOutputTokens: 8192, Thinking: ThinkingNone},
+}
+
+// geminiModels are the models a Client serves, as /v1/models lists them.
+var geminiModels = []string{"gemini-2.5-pro", "gemini-2.5-flash", "gemini-2.5-flash-lite"}
+
+// Models implements ModelLister.
+func (c *Client) Models() []string {
+	return append([]string(nil), geminiModels...)
+}
+
+// defaultModel is assumed for models not in the table.
+var defaultModel = Capabilities{ContextWindow: 1_048_576, MaxOutputTokens: 65_536, Thinking: ThinkingBudget}
+
+var (
+	modelsMu  sync.RWMutex
+	overrides = map[string]Capabilities{}
+)
+
+// Model returns the capabilities of model. Settings given to
+// OverrideModels take precedence, field by field, over the built-in entry
+// unless that entry is a closer match: an override for gemini-2.5-flash
+// does not change gemini-2.5-flash-lite.
+func Model(model string) Capabilities {
+	caps, n := lookup(models, model)
+	if n < 0 {
+		caps = defaultModel
+	}
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+	o, m := lookup(overrides, model)
+	if m < n {
+		return caps
+	}
+	if o.ContextWindow > 0 {
+		caps.ContextWindow = o.ContextWindow
+	}
+	if o.MaxOutputTokens > 0 {
+		caps.MaxOutputTokens = o.MaxOutputTokens
+	}
+	if o.Thinking != "" {
+		caps.Thinking = o.Thinking
+	}
+	return caps
+}
+
+// lookup returns the entry of table that is the longest prefix of model,
+// and its length, or -1 if there is none.
+func lookup(table map[string]Capabilities, model string) (Capabilities, int) {
+	var caps Capabilities
+	best := -1
+	for name, c := range table {
+		if strings.HasPrefix(model, name) && len(name) > best {
+			caps, best = c, len(name)
+		}
+	}
+	return caps, best
+}
+
+// OverrideModels replaces the model settings layered over the built-in
+// table. Names match like the table's, as prefixes of model names. Zero
+// fields keep the built-in value.
+func OverrideModels(m map[string]Capabilities) error {
+	for name, caps := range m {
+		switch caps.Thinking {
+		case "", ThinkingNone, ThinkingBudget, ThinkingLevel:
+		default:
+			return fmt.Errorf("model %s: unknown thinking %q (use %s, %s or %s)", name, caps.Thinking, ThinkingNone, ThinkingBudget, ThinkingLevel)
+		}
+		if caps.ContextWindow < 0 || caps.MaxOutputTokens < 0 {
+			return fmt.Errorf("model %s: token limits must be positive", name)
+		}
+	}
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	overrides = m
+	return nil
+}
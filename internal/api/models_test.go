@@ -0,0 +1,56 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import "testing"
+
+func TestModel(t *testing.T) {
+	tests := []struct {
+		model     string
+		window    int
+		maxOutput int
+		thinking  string
+	}{
+		{"gemini-2.5-flash", 1_048_576, 65_536, ThinkingBudget},
+		{"gemini-2.5-flash-lite-preview-09-2025", 1_048_576, 65_536, ThinkingBudget},
+		{"gemini-2.0-flash-001", 1_048_576, 8192, ThinkingNone},
+		{"gemini-3-pro-preview", 1_048_576, 65_536, ThinkingLevel},
+		{"claude-sonnet-4-5", 200_000, 64_000, ThinkingNone},
+		{"claude-opus-4-1-20250805", 200_000, 32_000, ThinkingNone},
+		{"some-new-model", 1_048_576, 65_536, ThinkingBudget},
+	}
+	for _, tt := range tests {
+		got := Model(tt.model)
+		if got.ContextWindow != tt.window || got.MaxOutputTokens != tt.maxOutput || got.Thinking != tt.thinking {
+			t.Errorf("Model(%q) = %+v", tt.model, got)
+		}
+	}
+
+	if _, err := Thinking("gemini-2.0-flash", "high"); err == nil {
+		t.Error("thinking level accepted for a model without thinking")
+	}
+}
+
+func TestOverrideModels(t *testing.T) {
+	defer OverrideModels(nil)
+	err := OverrideModels(map[string]Capabilities{
+		"gemini-2.5-flash": {ContextWindow: 200_000},
+		"my-tuned-":        {MaxOutputTokens: 4096, Thinking: ThinkingNone},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Model("gemini-2.5-flash"); got.ContextWindow != 200_000 || got.MaxOutputTokens != 65_536 {
+		t.Errorf("overridden flash = %+v", got)
+	}
+	if got := Model("gemini-2.5-flash-lite"); got.ContextWindow != 1_048_576 {
+		t.Errorf("flash-lite took the flash override: %+v", got)
+	}
+	if got := Model("my-tuned-model"); got.MaxOutputTokens != 4096 || got.Thinking != ThinkingNone || got.ContextWindow != 1_048_576 {
+		t.Errorf("custom model = %+v", got)
+	}
+
+	if err := OverrideModels(map[string]Capabilities{"x": {Thinking: "always"}}); err == nil {
+		t.Error("unknown thinking kind accepted")
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import "context"
+
+// ProgressFunc receives notes about why a request is taking long, such as
+// a wait before retrying a rate-limited call. stage is a short keyword
+// ("retry", "init", "history") and detail a human-readable sentence.
+type ProgressFunc func(stage, detail string)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose requests report progress to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress sends a progress note to the function attached to ctx by
+// WithProgress, if any.
+func ReportProgress(ctx context.Context, stage, detail string) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		fn(stage, detail)
+	}
+}
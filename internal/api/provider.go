@@ -0,0 +1,87 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Provider generates model output for a request. Client (Code Assist)
+// implements it; other backends translate to and from the Gemini content
+// model used throughout g.
+type Provider interface {
+	Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error)
+	GenerateStream(ctx context.Context, req *GenerateRequest) (<-chan StreamEvent, error)
+}
+
+// ModelLister is implemented by providers that know the models they serve.
+type ModelLister interface {
+	Models() []string
+}
+
+// Models returns the models p serves, or nil if it does not say.
+func Models(p Provider) []string {
+	if l, ok := p.(ModelLister); ok {
+		return l.Models()
+	}
+	return nil
+}
+
+// Router dispatches requests to a provider chosen by model name prefix.
+type Router struct {
+	Default Provider
+	routes  []route
+}
+
+type route struct {
+	prefix   string
+	provider Provider
+}
+
+// Route sends requests for models starting with prefix to p.
+func (r *Router) Route(prefix string, p Provider) {
+	r.routes = append(r.routes, route{prefix: prefix, provider: p})
+}
+
+// For returns the provider that serves model.
+func (r *Router) For(model string) Provider {
+	for _, rt := range r.routes {
+		if strings.HasPrefix(model, rt.prefix) {
+			return rt.provider
+		}
+	}
+	return r.Default
+}
+
+// Generate implements Provider.
+func (r *Router) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	p := r.For(req.Model)
+	if p == nil {
+		return nil, fmt.Errorf("no provider configured for model %q", req.Model)
+	}
+	return p.Generate(ctx, req)
+}
+
+// GenerateStream implements Provider.
+func (r *Router) GenerateStream(ctx context.Context, req *GenerateRequest) (<-chan StreamEvent, error) {
+	p := r.For(req.Model)
+	if p == nil {
+		return nil, fmt.Errorf("no provider configured for model %q", req.Model)
+	}
+	return p.GenerateStream(ctx, req)
+}
+
+// Models implements ModelLister: the models of the routed providers, then
+// those of the default one.
+func (r *Router) Models() []string {
+	var models []string
+	for _, rt := range r.routes {
+		models = append(models, Models(rt.provider)...)
+	}
+	if r.Default != nil {
+		models = append(models, Models(r.Default)...)
+	}
+	return models
+}
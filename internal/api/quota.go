@@ -0,0 +1,117 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxTransientDelay is the longest retry delay still treated as a
+// transient rate limit; a longer one means the quota is used up.
+const maxTransientDelay = 2 * time.Minute
+
+// QuotaExhaustedError reports that a model's quota (typically the daily
+// one) is used up. Retrying before ResetIn is pointless.
+type QuotaExhaustedError struct {
+	Message string
+	ResetIn time.Duration // zero if unknown
+}
+
+func (e *QuotaExhaustedError) Error() string {
+	msg := "quota exhausted"
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.ResetIn > 0 {
+		return fmt.Sprintf("%s (resets in about %s)", msg, roundDuration(e.ResetIn))
+	}
+	return msg
+}
+
+// roundDuration keeps a reset time readable: "5h3m0s" rather than
+// "5h3m12.25s".
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Hour {
+		return d.Round(time.Minute)
+	}
+	return d.Round(time.Second)
+}
+
+// quotaExhausted tells a used-up quota from a transient 429: the API marks
+// it with ErrorInfo reason QUOTA_EXHAUSTED or a per-day quota violation, or
+// asks to wait longer than maxTransientDelay. It returns nil for transient
+// rate limits.
+func quotaExhausted(body []byte, delay time.Duration) *QuotaExhaustedError {
+	var errResp struct {
+		Error struct {
+			Details []struct {
+				Reason     string `json:"reason"`
+				Violations []struct {
+					QuotaID string `json:"quotaId"`
+				} `json:"violations"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.Unmarshal(body, &errResp)
+
+	exhausted := delay > maxTransientDelay
+	for _, d := range errResp.Error.Details {
+		if d.Reason == "QUOTA_EXHAUSTED" {
+			exhausted = true
+		}
+		for _, v := range d.Violations {
+			if strings.Contains(v.QuotaID, "PerDay") {
+				exhausted = true
+			}
+		}
+	}
+	if !exhausted {
+		return nil
+	}
+	e := &QuotaExhaustedError{Message: quotaMessage(body)}
+	if delay > baseRetryDelay {
+		e.ResetIn = delay
+	}
+	return e
+}
+
+// Fallback moves a conversation to another model once its model's quota is
+// exhausted. The switch is made on the request itself, so the rest of the
+// conversation stays on the fallback model.
+type Fallback struct {
+	Provider
+	Model string
+}
+
+// Generate implements Provider.
+func (f *Fallback) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	resp, err := f.Provider.Generate(ctx, req)
+	if f.switchModel(ctx, req, err) {
+		return f.Provider.Generate(ctx, req)
+	}
+	return resp, err
+}
+
+// GenerateStream implements Provider.
+func (f *Fallback) GenerateStream(ctx context.Context, req *GenerateRequest) (<-chan StreamEvent, error) {
+	events, err := f.Provider.GenerateStream(ctx, req)
+	if f.switchModel(ctx, req, err) {
+		return f.Provider.GenerateStream(ctx, req)
+	}
+	return events, err
+}
+
+func (f *Fallback) switchModel(ctx context.Context, req *GenerateRequest, err error) bool {
+	var qe *QuotaExhaustedError
+	if !errors.As(err, &qe) || f.Model == "" || req.Model == f.Model {
+		return false
+	}
+	ReportProgress(ctx, "fallback", fmt.Sprintf("%s: %v; switching to %s", req.Model, qe, f.Model))
+	req.Model = f.Model
+	return true
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuotaExhausted(t *testing.T) {
+	daily := []byte(`{"error": {"code": 429, "message": "Quota exceeded.", "details": [
+		{"@type": "type.googleapis.com/google.rpc.QuotaFailure", "violations": [{"quotaId": "GenerateRequestsPerDayPerProjectPerModel-FreeTier", "quotaValue": "250"}]}]}}`)
+	qe := quotaExhausted(daily, 5*time.Hour+3*time.Minute+12*time.Second)
+	if qe == nil {
+		t.Fatal("per-day quota violation not detected")
+	}
+	if got, want := qe.Error(), "quota exhausted: Quota exceeded. (quota GenerateRequestsPerDayPerProjectPerModel-FreeTier, limit 250) (resets in about 5h3m0s)"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	reason := []byte(`{"error": {"details": [{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "QUOTA_EXHAUSTED"}]}}`)
+	if quotaExhausted(reason, baseRetryDelay) == nil {
+		t.Error("QUOTA_EXHAUSTED reason not detected")
+	}
+	if quotaExhausted([]byte(`{"error": {"message": "slow down"}}`), 12*time.Second) != nil {
+		t.Error("a short rate limit is transient")
+	}
+	if quotaExhausted([]byte(`{}`), time.Hour) == nil {
+		t.Error("an hour-long retry delay means the quota is exhausted")
+	}
+}
+
+type quotaProvider struct{ models []string }
+
+func (p *quotaProvider) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	p.models = append(p.models, req.Model)
+	if req.Model == "gemini-2.5-pro" {
+		return nil, &QuotaExhaustedError{}
+	}
+	return &GenerateResponse{}, nil
+}
+
+func (p *quotaProvider) GenerateStream(ctx context.Context, req *GenerateRequest) (<-chan StreamEvent, error) {
+	return nil, nil
+}
+
+func TestFallback(t *testing.T) {
+	p := &quotaProvider{}
+	f := &Fallback{Provider: p, Model: "gemini-2.5-flash"}
+	req := &GenerateRequest{Model: "gemini-2.5-pro"}
+	if _, err := f.Generate(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	f.Generate(context.Background(), req)
+	if got := strings.Join(p.models, ","); got != "gemini-2.5-pro,gemini-2.5-flash,gemini-2.5-flash" {
+		t.Errorf("models used = %s", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxQuotaMessageLen bounds the API message quoted in retry notes.
+const maxQuotaMessageLen = 200
+
+// retryNote describes a rate-limit wait for the user, e.g. "rate limited
+// (retry 2/5): Resource has been exhausted (quota ..., limit 10); retrying
+// in 12s".
+func retryNote(body []byte, attempt int, delay time.Duration) string {
+	note := fmt.Sprintf("rate limited (retry %d/%d)", attempt+1, maxRetries)
+	if msg := quotaMessage(body); msg != "" {
+		note += ": " + msg
+	}
+	return fmt.Sprintf("%s; retrying in %s", note, delay.Round(100*time.Millisecond))
+}
+
+// quotaMessage extracts a short explanation from a 429 error body: the API's
+// message and, when present, the quota that was exceeded and its limit.
+func quotaMessage(body []byte) string {
+	var errResp struct {
+		Error struct {
+			Message string `json:"message"`
+			Details []struct {
+				Violations []struct {
+					QuotaID     string `json:"quotaId"`
+					QuotaValue  string `json:"quotaValue"`
+					Description string `json:"description"`
+				} `json:"violations"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &errResp) != nil {
+		return ""
+	}
+	msg := strings.TrimSpace(errResp.Error.Message)
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		msg = msg[:i]
+	}
+	if len(msg) > maxQuotaMessageLen {
+		msg = strings.ToValidUTF8(msg[:maxQuotaMessageLen], "") + "..."
+	}
+	for _, d := range errResp.Error.Details {
+		for _, v := range d.Violations {
+			var quota []string
+			if v.QuotaID != "" {
+				quota = append(quota, "quota "+v.QuotaID)
+			} else if v.Description != "" && v.Description != msg {
+				quota = append(quota, v.Description)
+			}
+			if v.QuotaValue != "" {
+				quota = append(quota, "limit "+v.QuotaValue)
+			}
+			if len(quota) > 0 {
+				return strings.TrimSpace(msg + " (" + strings.Join(quota, ", ") + ")")
+			}
+		}
+	}
+	return msg
+}
@@ -0,0 +1,25 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryNote(t *testing.T) {
+	body := []byte(`{"error": {"code": 429, "message": "You exceeded your current quota.\nPlease check your plan.", "status": "RESOURCE_EXHAUSTED",
+		"details": [
+			{"@type": "type.googleapis.com/google.rpc.QuotaFailure", "violations": [{"quotaMetric": "generativelanguage.googleapis.com/generate_content_free_tier_requests", "quotaId": "GenerateRequestsPerMinutePerProjectPerModel-FreeTier", "quotaValue": "10"}]},
+			{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "12s"}
+		]}}`)
+	got := retryNote(body, 1, 12*time.Second)
+	want := "rate limited (retry 2/5): You exceeded your current quota. (quota GenerateRequestsPerMinutePerProjectPerModel-FreeTier, limit 10); retrying in 12s"
+	if got != want {
+		t.Errorf("retryNote =\n%q, want\n%q", got, want)
+	}
+
+	if got, want := retryNote([]byte("not json"), 0, 500*time.Millisecond), "rate limited (retry 1/5); retrying in 500ms"; got != want {
+		t.Errorf("retryNote = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+const (
+	sseInitialBuffer = 64 * 1024
+	sseMaxLine       = 64 * 1024 * 1024 // a single field line may be this long
+)
+
+// StreamBuffer is how many decoded events a stream holds for a slow
+// consumer before reading from the connection pauses.
+const StreamBuffer = 256
+
+// SSEEvent is one server-sent event. Data is only valid until the next call
+// to Next.
+type SSEEvent struct {
+	Event string // the "event:" field, empty for the default "message" type
+	ID    string
+	Data  []byte // "data:" lines joined with newlines
+}
+
+// SSEReader decodes a text/event-stream: LF, CRLF and CR line endings,
+// comments, multi-line data fields and lines of any length up to 64MB.
+// Buffers are reused between events.
+type SSEReader struct {
+	scanner *bufio.Scanner
+	data    bytes.Buffer
+	event   SSEEvent
+}
+
+// NewSSEReader returns a decoder reading from r.
+func NewSSEReader(r io.Reader) *SSEReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, sseInitialBuffer), sseMaxLine)
+	scanner.Split(scanSSELines)
+	return &SSEReader{scanner: scanner}
+}
+
+// Next returns the next event with data. It returns io.EOF at the end of the
+// stream; an event not terminated by a blank line is still returned first.
+func (s *SSEReader) Next() (*SSEEvent, error) {
+	s.data.Reset()
+	s.event.Event = ""
+	hasData := false
+	for s.scanner.Scan() {
+		line := s.scanner.Bytes()
+		if len(line) == 0 {
+			// A blank line dispatches the event; events without data are
+			// ignored, as in browsers.
+			if hasData {
+				return s.dispatch(), nil
+			}
+			s.event.Event = ""
+			continue
+		}
+		if line[0] == ':' {
+			continue // comment, often used as a keep-alive
+		}
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			if len(value) > 0 && value[0] == ' ' {
+				value = value[1:]
+			}
+		}
+		switch string(field) {
+		case "data":
+			if hasData {
+				s.data.WriteByte('\n')
+			}
+			s.data.Write(value)
+			hasData = true
+		case "event":
+			s.event.Event = string(value)
+		case "id":
+			s.event.ID = string(value)
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if hasData {
+		return s.dispatch(), nil
+	}
+	return nil, io.EOF
+}
+
+func (s *SSEReader) dispatch() *SSEEvent {
+	s.event.Data = s.data.Bytes()
+	return &s.event
+}
+
+// scanSSELines splits on LF, CRLF or a lone CR.
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil // a CR at the end of the buffer may be followed by LF
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSSEReader(t *testing.T) {
+	long := strings.Repeat("x", 200*1024)
+	stream := ": keep-alive\r\n" +
+		"event: update\r\ndata: one\r\ndata:two\r\n\r\n" +
+		"data: {\"a\":1}\r\rid: 7\n\n" +
+		"event: empty\n\n" +
+		"data: " + long + "\n\n" +
+		"data: unterminated"
+
+	r := NewSSEReader(strings.NewReader(stream))
+	want := []struct{ event, data string }{
+		{"update", "one\ntwo"},
+		{"", `{"a":1}`},
+		{"", long},
+		{"", "unterminated"},
+	}
+	for i, w := range want {
+		ev, err := r.Next()
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if ev.Event != w.event || string(ev.Data) != w.data {
+			t.Errorf("event %d = %q %q, want %q %q", i, ev.Event, truncateForTest(ev.Data), w.event, truncateForTest([]byte(w.data)))
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func truncateForTest(b []byte) string {
+	if len(b) > 20 {
+		return string(b[:20]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxStreamResumes bounds how often one response is resumed after its
+// connection dropped.
+const maxStreamResumes = 3
+
+// continuePrompt asks the model to pick up an interrupted response.
+const continuePrompt = "Your previous response was cut off by a network error. Continue exactly where it stopped, without repeating any text."
+
+// errIncompleteStream means the connection closed before the response
+// finished, e.g. an idle proxy timed out.
+var errIncompleteStream = errors.New("stream ended before the response was complete")
+
+// openStream starts a streamGenerateContent request.
+func (c *Client) openStream(ctx context.Context, req *GenerateRequest) (*http.Response, error) {
+	endpoint := fmt.Sprintf("%s/%s:streamGenerateContent?alt=sse", c.baseURL, apiVersion)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	return c.doRequestWithRetry(ctx, httpReq, body)
+}
+
+// streamState accumulates what a response has produced so far, across
+// resumed connections.
+type streamState struct {
+	text         strings.Builder
+	signature    string
+	toolCalls    int
+	usage        *UsageMetadata
+	finishReason string
+	sources      []Source
+	traceID      string
+	requestID    string
+}
+
+// ids describes the response for an error message: its trace and request
+// IDs, if any.
+func (st *streamState) ids() string {
+	var ids []string
+	if st.traceID != "" {
+		ids = append(ids, "trace ID "+st.traceID)
+	}
+	if st.requestID != "" {
+		ids = append(ids, "request ID "+st.requestID)
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(ids, ", ") + "]"
+}
+
+// pumpStream forwards the response's events to events. If the connection
+// drops mid-response, the request is re-issued with the partial text as a
+// model turn and a request to continue, so the caller sees one uninterrupted
+// answer. Once a tool call has arrived the response is ended instead; the
+// agent runs the calls and the model carries on in its next turn.
+func (c *Client) pumpStream(ctx context.Context, req *GenerateRequest, resp *http.Response, events chan<- StreamEvent) {
+	defer close(events)
+
+	send := func(ev StreamEvent) bool {
+		select {
+		case events <- ev:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// Send start event
+	if !send(StreamEvent{Type: "start", Model: req.Model}) {
+		resp.Body.Close()
+		return
+	}
+
+	var st streamState
+	for resumes := 0; ; resumes++ {
+		if id := RequestID(resp.Header); id != "" {
+			st.requestID = id
+		}
+		err := st.read(resp.Body, send)
+		resp.Body.Close()
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if st.toolCalls > 0 {
+			break
+		}
+		if resumes == maxStreamResumes {
+			send(StreamEvent{Type: "error", Error: fmt.Sprintf("stream interrupted %d times: %v%s", resumes+1, err, st.ids())})
+			return
+		}
+		resp, err = c.openStream(ctx, continuation(req, &st))
+		if err != nil {
+			send(StreamEvent{Type: "error", Error: err.Error()})
+			return
+		}
+	}
+
+	// Send done event
+	send(StreamEvent{Type: "done", Usage: st.usage, FinishReason: st.finishReason, Sources: st.sources, TraceID: st.traceID, RequestID: st.requestID})
+}
+
+// read forwards the events of one connection. It returns nil once the
+// response is complete, ctx's error if send was aborted, and otherwise the
+// error that ended the connection early.
+func (st *streamState) read(body io.Reader, send func(StreamEvent) bool) error {
+	reader := NewSSEReader(body)
+	aborted := errors.New("send aborted")
+	for {
+		ev, err := reader.Next()
+		if err == io.EOF {
+			if st.finishReason == "" {
+				return errIncompleteStream
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if string(ev.Data) == "[DONE]" {
+			return nil
+		}
+
+		var chunk GenerateResponse
+		if err := json.Unmarshal(ev.Data, &chunk); err != nil {
+			continue
+		}
+
+		if chunk.TraceID != "" {
+			st.traceID = chunk.TraceID
+		}
+
+		// Store usage for final event
+		if chunk.Response.UsageMetadata.TotalTokenCount > 0 {
+			usage := chunk.Response.UsageMetadata
+			st.usage = &usage
+		}
+
+		// Extract text and tool calls from candidates
+		for _, candidate := range chunk.Response.Candidates {
+			if candidate.FinishReason != "" {
+				st.finishReason = candidate.FinishReason
+			}
+			st.sources = addSources(st.sources, candidate.GroundingMetadata)
+			for _, part := range candidate.Content.Parts {
+				if part.Text != "" {
+					st.text.WriteString(part.Text)
+					if part.ThoughtSignature != "" {
+						st.signature = part.ThoughtSignature
+					}
+					if !send(StreamEvent{
+						Type:             "content",
+						Text:             part.Text,
+						ThoughtSignature: part.ThoughtSignature,
+					}) {
+						return aborted
+					}
+				}
+				if part.ExecutableCode != nil || part.CodeExecutionResult != nil {
+					st.toolCalls++ // a resumed request would not include the code
+					if !send(StreamEvent{
+						Type:             "code",
+						Code:             part.ExecutableCode,
+						CodeResult:       part.CodeExecutionResult,
+						ThoughtSignature: part.ThoughtSignature,
+					}) {
+						return aborted
+					}
+				}
+				if part.FunctionCall != nil {
+					st.toolCalls++
+					if !send(StreamEvent{
+						Type:             "tool_call",
+						ToolCall:         part.FunctionCall,
+						ThoughtSignature: part.ThoughtSignature,
+					}) {
+						return aborted
+					}
+				}
+			}
+		}
+	}
+}
+
+// continuation returns req extended with the partial response and a request
+// to continue it. Without partial text the original request is repeated.
+func continuation(req *GenerateRequest, st *streamState) *GenerateRequest {
+	if st.text.Len() == 0 {
+		return req
+	}
+	next := *req
+	next.Request.Contents = append(append([]Content{}, req.Request.Contents...),
+		Content{Role: "model", Parts: []Part{{Text: st.text.String(), ThoughtSignature: st.signature}}},
+		Content{Role: "user", Parts: []Part{{Text: continuePrompt}}},
+	)
+	return &next
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerateStreamResumesAfterDrop(t *testing.T) {
+	var requests []GenerateRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		requests = append(requests, req)
+		w.Header().Set("Content-Type", "text/event-stream")
+		if len(requests) == 1 {
+			// The connection ends without a finish reason.
+			io.WriteString(w, `data: {"response":{"candidates":[{"content":{"parts":[{"text":"Hello "}]}}]}}`+"\n\n")
+			return
+		}
+		io.WriteString(w, `data: {"response":{"candidates":[{"content":{"parts":[{"text":"world"}]},"groundingMetadata":{"groundingChunks":[{"web":{"uri":"https://a","title":"A"}}]}}]}}`+"\n\n")
+		io.WriteString(w, `data: {"response":{"candidates":[{"content":{"parts":[]},"finishReason":"STOP","groundingMetadata":{"groundingChunks":[{"web":{"uri":"https://a","title":"A"}},{"web":{"uri":"https://b"}}]}}]}}`+"\n\n")
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.Client())
+	c.baseURL = srv.URL
+	req := &GenerateRequest{Model: "m", Request: InnerRequest{Contents: []Content{{Role: "user", Parts: []Part{{Text: "hi"}}}}}}
+	events, err := c.GenerateStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text, finish string
+	var sources []Source
+	for ev := range events {
+		switch ev.Type {
+		case "content":
+			text += ev.Text
+		case "error":
+			t.Fatalf("error event: %s", ev.Error)
+		case "done":
+			finish, sources = ev.FinishReason, ev.Sources
+		}
+	}
+	if text != "Hello world" || finish != "STOP" {
+		t.Errorf("text = %q, finish = %q", text, finish)
+	}
+	if len(sources) != 2 || sources[0] != (Source{Title: "A", URI: "https://a"}) || sources[1].URI != "https://b" {
+		t.Errorf("sources = %+v", sources)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	resumed := requests[1].Request.Contents
+	if len(resumed) != 3 || resumed[1].Role != "model" || resumed[1].Parts[0].Text != "Hello " || resumed[2].Parts[0].Text != continuePrompt {
+		t.Errorf("resumed request contents = %+v", resumed)
+	}
+	if len(req.Request.Contents) != 1 {
+		t.Error("original request was modified")
+	}
+}
+
+func TestGenerateStreamCodeExecution(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `data: {"response":{"candidates":[{"content":{"parts":[{"executableCode":{"language":"PYTHON","code":"print(6*7)"}}]}}]}}`+"\n\n")
+		io.WriteString(w, `data: {"response":{"candidates":[{"content":{"parts":[{"codeExecutionResult":{"outcome":"OUTCOME_OK","output":"42\n"}},{"text":"It is 42."}]},"finishReason":"STOP"}]}}`+"\n\n")
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.Client())
+	c.baseURL = srv.URL
+	events, err := c.GenerateStream(context.Background(), &GenerateRequest{Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for ev := range events {
+		switch {
+		case ev.Code != nil:
+			got = append(got, "code:"+ev.Code.Code)
+		case ev.CodeResult != nil:
+			got = append(got, "result:"+ev.CodeResult.Output)
+		case ev.Type == "content":
+			got = append(got, "text:"+ev.Text)
+		}
+	}
+	want := []string{"code:print(6*7)", "result:42\n", "text:It is 42."}
+	if len(got) != len(want) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGenerateStreamReportsIDs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("X-Request-Id", "req-7")
+		io.WriteString(w, `data: {"response":{"candidates":[{"content":{"parts":[{"text":"ok"}]},"finishReason":"STOP"}]},"traceId":"abc123"}`+"\n\n")
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.Client())
+	c.baseURL = srv.URL
+	events, err := c.GenerateStream(context.Background(), &GenerateRequest{Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var done StreamEvent
+	for ev := range events {
+		if ev.Type == "done" {
+			done = ev
+		}
+	}
+	if done.TraceID != "abc123" || done.RequestID != "req-7" {
+		t.Errorf("done event IDs = %q, %q", done.TraceID, done.RequestID)
+	}
+}
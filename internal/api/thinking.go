@@ -0,0 +1,56 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ThinkingConfig controls how much a model reasons before answering.
+// Gemini 2.5 models take a token budget, Gemini 3 models a level.
+type ThinkingConfig struct {
+	ThinkingBudget *int   `json:"thinkingBudget,omitempty"`
+	ThinkingLevel  string `json:"thinkingLevel,omitempty"`
+}
+
+// ThinkingLevels are the levels accepted by Thinking.
+var ThinkingLevels = []string{"off", "low", "medium", "high"}
+
+// Thinking returns the thinking config for level on model. An empty level
+// returns nil, leaving the model's default. Models that cannot stop thinking
+// get their minimum for "off"; models whose thinking cannot be controlled
+// (see Model) return an error.
+func Thinking(model, level string) (*ThinkingConfig, error) {
+	level = strings.ToLower(level)
+	if level == "" {
+		return nil, nil
+	}
+	valid := false
+	for _, l := range ThinkingLevels {
+		valid = valid || l == level
+	}
+	if !valid {
+		return nil, fmt.Errorf("unknown thinking level %q (use %s)", level, strings.Join(ThinkingLevels, ", "))
+	}
+
+	switch Model(model).Thinking {
+	case ThinkingNone:
+		return nil, fmt.Errorf("%s does not support setting the thinking level", model)
+	case ThinkingLevel:
+		// Gemini 3 only knows low and high
+		if level == "off" || level == "low" {
+			return &ThinkingConfig{ThinkingLevel: "low"}, nil
+		}
+		return &ThinkingConfig{ThinkingLevel: "high"}, nil
+	}
+
+	// Budgets for Gemini 2.5 Flash and Flash-Lite; Pro cannot turn thinking
+	// off and allows a larger maximum.
+	budgets := map[string]int{"off": 0, "low": 1024, "medium": 8192, "high": 24576}
+	if strings.Contains(model, "-pro") {
+		budgets["off"], budgets["high"] = 128, 32768
+	}
+	budget := budgets[level]
+	return &ThinkingConfig{ThinkingBudget: &budget}, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import "testing"
+
+func TestThinking(t *testing.T) {
+	tests := []struct {
+		model, level string
+		budget       int
+		thinkLevel   string
+	}{
+		{"gemini-2.5-flash", "off", 0, ""},
+		{"gemini-2.5-flash", "HIGH", 24576, ""},
+		{"gemini-2.5-pro", "off", 128, ""},
+		{"gemini-2.5-pro", "high", 32768, ""},
+		{"gemini-2.5-pro", "medium", 8192, ""},
+		{"gemini-3-pro-preview", "off", 0, "low"},
+		{"gemini-3-pro-preview", "medium", 0, "high"},
+	}
+	for _, tt := range tests {
+		cfg, err := Thinking(tt.model, tt.level)
+		if err != nil {
+			t.Fatalf("Thinking(%q, %q): %v", tt.model, tt.level, err)
+		}
+		if tt.thinkLevel != "" {
+			if cfg.ThinkingLevel != tt.thinkLevel || cfg.ThinkingBudget != nil {
+				t.Errorf("Thinking(%q, %q) = %+v, want level %s", tt.model, tt.level, cfg, tt.thinkLevel)
+			}
+			continue
+		}
+		if cfg.ThinkingBudget == nil || *cfg.ThinkingBudget != tt.budget {
+			t.Errorf("Thinking(%q, %q) = %+v, want budget %d", tt.model, tt.level, cfg, tt.budget)
+		}
+	}
+
+	if cfg, err := Thinking("gemini-2.5-flash", ""); cfg != nil || err != nil {
+		t.Errorf("empty level = %+v, %v; want model default", cfg, err)
+	}
+	if _, err := Thinking("gemini-2.5-flash", "max"); err == nil {
+		t.Error("unknown level accepted")
+	}
+}
@@ -0,0 +1,235 @@
+// Package approval provides user confirmation for tool execution.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package approval
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Decision is the user's answer to a confirmation request.
+type Decision int
+
+const (
+	Deny Decision = iota
+	AllowOnce
+	AllowSession // allow this tool for the rest of the session
+	AllowAlways  // allow this tool now and in future sessions
+	TrustServer  // allow every tool of the same MCP server for the session
+)
+
+// Request describes a tool call awaiting confirmation.
+type Request struct {
+	Tool    string                 // tool name as seen by the model
+	Server  string                 // MCP server name, if any
+	Args    map[string]interface{} // call arguments
+	Summary string                 // one-line description of what will run
+	Reason  string                 // why confirmation is needed (e.g. a risk warning)
+	Options []Decision             // decisions to offer; Deny is always allowed
+}
+
+// Prompter asks the user to approve a tool call.
+type Prompter interface {
+	Confirm(ctx context.Context, req Request) (Decision, error)
+}
+
+// ErrNoTerminal is returned when no interactive terminal is available.
+var ErrNoTerminal = errors.New("no interactive terminal available for confirmation")
+
+// TTYPrompter asks on the controlling terminal, so it works even when stdin
+// is a pipe.
+type TTYPrompter struct {
+	mu sync.Mutex
+}
+
+// NewTTYPrompter creates a prompter that talks to the controlling terminal.
+func NewTTYPrompter() *TTYPrompter {
+	return &TTYPrompter{}
+}
+
+// Confirm implements Prompter.
+func (p *TTYPrompter) Confirm(ctx context.Context, req Request) (Decision, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	in, out, err := openTerminal()
+	if err != nil {
+		return Deny, ErrNoTerminal
+	}
+	defer in.Close()
+	if out != in {
+		defer out.Close()
+	}
+
+	return ask(ctx, in, out, req)
+}
+
+var choiceLabels = map[Decision]string{
+	AllowOnce:    "[y] yes, once",
+	AllowSession: "[s] yes, for this session",
+	AllowAlways:  "[a] always allow",
+	TrustServer:  "[t] trust this server for the session",
+}
+
+var choiceKeys = map[string]Decision{
+	"y": AllowOnce, "yes": AllowOnce,
+	"s": AllowSession,
+	"a": AllowAlways, "always": AllowAlways,
+	"t": TrustServer,
+}
+
+// ask renders the request to out and reads the answer from in.
+func ask(ctx context.Context, in io.Reader, out io.Writer, req Request) (Decision, error) {
+	options := req.Options
+	if len(options) == 0 {
+		options = []Decision{AllowOnce}
+	}
+
+	fmt.Fprintf(out, "\n? Allow %s", req.Tool)
+	if req.Server != "" {
+		fmt.Fprintf(out, " (MCP server %q)", req.Server)
+	}
+	fmt.Fprintln(out, "?")
+	if req.Summary != "" {
+		fmt.Fprintf(out, "  %s\n", req.Summary)
+	}
+	if req.Reason != "" {
+		fmt.Fprintf(out, "  ⚠ %s\n", req.Reason)
+	}
+
+	var labels []string
+	allowed := map[Decision]bool{Deny: true}
+	for _, d := range options {
+		labels = append(labels, choiceLabels[d])
+		allowed[d] = true
+	}
+	labels = append(labels, "[n] no")
+	fmt.Fprintf(out, "  %s: ", strings.Join(labels, "  "))
+
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(in).ReadString('\n')
+		answer <- strings.ToLower(strings.TrimSpace(line))
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(out)
+		return Deny, ctx.Err()
+	case a := <-answer:
+		if d, ok := choiceKeys[a]; ok && allowed[d] {
+			return d, nil
+		}
+		return Deny, nil
+	}
+}
+
+// Choice is one possible answer to a question asked with Ask.
+type Choice struct {
+	Key   string // what the user types, e.g. "m"
+	Label string // e.g. "merge"
+}
+
+// Ask asks a multiple-choice question on the controlling terminal and
+// returns the key of the chosen answer, or "" if the answer matches none.
+func Ask(ctx context.Context, question string, choices []Choice) (string, error) {
+	in, out, err := openTerminal()
+	if err != nil {
+		return "", ErrNoTerminal
+	}
+	defer in.Close()
+	if out != in {
+		defer out.Close()
+	}
+
+	var labels []string
+	for _, c := range choices {
+		labels = append(labels, fmt.Sprintf("[%s] %s", c.Key, c.Label))
+	}
+	fmt.Fprintf(out, "\n? %s\n  %s: ", question, strings.Join(labels, "  "))
+
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(in).ReadString('\n')
+		answer <- strings.ToLower(strings.TrimSpace(line))
+	}()
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(out)
+		return "", ctx.Err()
+	case a := <-answer:
+		for _, c := range choices {
+			if a == c.Key || a == strings.ToLower(c.Label) {
+				return c.Key, nil
+			}
+		}
+		return "", nil
+	}
+}
+
+// Question asks a free-form question on the controlling terminal and
+// returns the trimmed answer. An empty answer returns def.
+func Question(ctx context.Context, question, def string) (string, error) {
+	in, out, err := openTerminal()
+	if err != nil {
+		return "", ErrNoTerminal
+	}
+	defer in.Close()
+	if out != in {
+		defer out.Close()
+	}
+	return readAnswer(ctx, in, out, question, def)
+}
+
+func readAnswer(ctx context.Context, in io.Reader, out io.Writer, question, def string) (string, error) {
+	fmt.Fprintf(out, "\n? %s\n", question)
+	if def != "" {
+		fmt.Fprintf(out, "  (default: %s)\n", def)
+	}
+	fmt.Fprint(out, "  > ")
+
+	answer := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(in).ReadString('\n')
+		answer <- strings.TrimSpace(line)
+	}()
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(out)
+		return "", ctx.Err()
+	case a := <-answer:
+		if a == "" {
+			return def, nil
+		}
+		return a, nil
+	}
+}
+
+// openTerminal opens the controlling terminal for reading and writing.
+func openTerminal() (in *os.File, out *os.File, err error) {
+	if runtime.GOOS == "windows" {
+		in, err = os.OpenFile("CONIN$", os.O_RDWR, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err = os.OpenFile("CONOUT$", os.O_RDWR, 0)
+		if err != nil {
+			in.Close()
+			return nil, nil, err
+		}
+		return in, out, nil
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tty, tty, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package approval
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsk(t *testing.T) {
+	all := []Decision{AllowOnce, AllowSession, AllowAlways}
+	tests := []struct {
+		name    string
+		answer  string
+		options []Decision
+		want    Decision
+	}{
+		{"yes", "y\n", nil, AllowOnce},
+		{"yes spelled out", "YES\n", nil, AllowOnce},
+		{"session", "s\n", all, AllowSession},
+		{"always", "always\n", all, AllowAlways},
+		{"no", "n\n", all, Deny},
+		{"empty answer denies", "\n", all, Deny},
+		{"end of input denies", "", all, Deny},
+		{"unknown answer denies", "sure\n", all, Deny},
+		{"always when not offered denies", "a\n", nil, Deny},
+		{"trust when not offered denies", "t\n", all, Deny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			got, err := ask(context.Background(), strings.NewReader(tt.answer), &out, Request{Tool: "write_file", Options: tt.options})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ask(%q) = %v, want %v", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAskShowsRequest(t *testing.T) {
+	var out strings.Builder
+	ask(context.Background(), strings.NewReader("n\n"), &out, Request{
+		Tool:    "db__query",
+		Server:  "db",
+		Summary: "args: map[sql:drop table users]",
+		Reason:  "destructive",
+		Options: []Decision{AllowSession, TrustServer},
+	})
+	for _, want := range []string{"Allow db__query", `MCP server "db"`, "drop table users", "⚠ destructive", "[s] yes, for this session", "[t] trust this server", "[n] no"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("prompt %q lacks %q", out.String(), want)
+		}
+	}
+	if strings.Contains(out.String(), "[a] always") {
+		t.Errorf("prompt %q offers a decision not in Options", out.String())
+	}
+}
+
+func TestAskCancelled(t *testing.T) {
+	in, w := io.Pipe() // never answered
+	defer w.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	got, err := ask(ctx, in, io.Discard, Request{Tool: "write_file"})
+	if got != Deny || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ask = %v, %v; want Deny, deadline exceeded", got, err)
+	}
+}
+
+func TestReadAnswerDefault(t *testing.T) {
+	got, err := readAnswer(context.Background(), strings.NewReader("\n"), io.Discard, "Which branch?", "main")
+	if err != nil || got != "main" {
+		t.Errorf("empty answer = %q, %v; want the default", got, err)
+	}
+	got, _ = readAnswer(context.Background(), strings.NewReader("  dev \n"), io.Discard, "Which branch?", "main")
+	if got != "dev" {
+		t.Errorf("answer = %q, want dev", got)
+	}
+}
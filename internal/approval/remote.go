@@ -0,0 +1,196 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package approval
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/k-sub1995/g/internal/redact"
+)
+
+// Escalation is a tool approval or ask_user question sent to an external
+// approver.
+type Escalation struct {
+	ID   string `json:"id"`
+	Type string `json:"type"` // "approval" or "question"
+
+	// Approvals
+	Tool    string                 `json:"tool,omitempty"`
+	Server  string                 `json:"server,omitempty"`
+	Args    map[string]interface{} `json:"args,omitempty"`
+	Summary string                 `json:"summary,omitempty"`
+	Reason  string                 `json:"reason,omitempty"`
+	Options []string               `json:"options,omitempty"` // decisions besides "deny"
+
+	// Questions
+	Question string `json:"question,omitempty"`
+	Default  string `json:"default,omitempty"`
+}
+
+// Reply is an external approver's answer. Decision answers approvals and
+// Answer answers questions; ID, when set, must match the escalation.
+type Reply struct {
+	ID       string `json:"id,omitempty"`
+	Decision string `json:"decision,omitempty"`
+	Answer   string `json:"answer,omitempty"`
+}
+
+var decisionNames = map[Decision]string{
+	Deny:         "deny",
+	AllowOnce:    "allow_once",
+	AllowSession: "allow_session",
+	AllowAlways:  "allow_always",
+	TrustServer:  "trust_server",
+}
+
+// Remote routes approvals and questions to a human outside the terminal,
+// for headless runs. It implements Prompter.
+type Remote struct {
+	// Redactor masks secrets in what is sent; nil sends it as is
+	Redactor *redact.Redactor
+
+	mu   sync.Mutex
+	send func(ctx context.Context, e Escalation) (Reply, error)
+	seq  int
+}
+
+// NewWebhook returns a Remote that POSTs each escalation as JSON to url and
+// expects the Reply as the response body. The endpoint may hold the request
+// open until a human decides.
+func NewWebhook(url string) *Remote {
+	client := &http.Client{}
+	return &Remote{send: func(ctx context.Context, e Escalation) (Reply, error) {
+		body, err := json.Marshal(e)
+		if err != nil {
+			return Reply{}, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return Reply{}, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return Reply{}, fmt.Errorf("approval webhook: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return Reply{}, fmt.Errorf("approval webhook: %s", resp.Status)
+		}
+		var r Reply
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&r); err != nil {
+			return Reply{}, fmt.Errorf("approval webhook: invalid reply: %w", err)
+		}
+		if r.ID != "" && r.ID != e.ID {
+			return Reply{}, fmt.Errorf("approval webhook: reply is for %s, not %s", r.ID, e.ID)
+		}
+		return r, nil
+	}}
+}
+
+// NewFIFO returns a Remote that writes each escalation as a JSON line to
+// notify and reads the Reply as a JSON line from the named pipe at path,
+// e.g. echo '{"decision":"allow_once"}' > path.
+func NewFIFO(path string, notify io.Writer) *Remote {
+	return &Remote{send: func(ctx context.Context, e Escalation) (Reply, error) {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return Reply{}, err
+		}
+		fmt.Fprintf(notify, "%s\n", line)
+
+		// Opened for writing too, the pipe neither waits for a writer nor
+		// ends when one leaves, and a deadline can stop the read
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return Reply{}, fmt.Errorf("approval fifo: %w", err)
+		}
+		defer f.Close()
+		stop := context.AfterFunc(ctx, func() {
+			if f.SetReadDeadline(time.Now()) != nil {
+				f.Close()
+			}
+		})
+		defer stop()
+
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var r Reply
+			if json.Unmarshal(sc.Bytes(), &r) == nil && (r.ID == "" || r.ID == e.ID) {
+				return r, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return Reply{}, ctx.Err()
+		}
+		if err := sc.Err(); err != nil {
+			return Reply{}, fmt.Errorf("approval fifo: %w", err)
+		}
+		return Reply{}, fmt.Errorf("approval fifo: %s closed", path)
+	}}
+}
+
+func (r *Remote) escalate(ctx context.Context, e Escalation) (Reply, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	e.ID = fmt.Sprintf("%d-%d", time.Now().Unix(), r.seq)
+	e.Args = r.Redactor.Map(e.Args)
+	e.Summary = r.Redactor.String(e.Summary)
+	e.Reason = r.Redactor.String(e.Reason)
+	e.Question = r.Redactor.String(e.Question)
+	return r.send(ctx, e)
+}
+
+// Confirm implements Prompter. Unknown decisions and decisions not offered
+// count as Deny.
+func (r *Remote) Confirm(ctx context.Context, req Request) (Decision, error) {
+	options := req.Options
+	if len(options) == 0 {
+		options = []Decision{AllowOnce}
+	}
+	var names []string
+	for _, d := range options {
+		names = append(names, decisionNames[d])
+	}
+	reply, err := r.escalate(ctx, Escalation{
+		Type:    "approval",
+		Tool:    req.Tool,
+		Server:  req.Server,
+		Args:    req.Args,
+		Summary: req.Summary,
+		Reason:  req.Reason,
+		Options: names,
+	})
+	if err != nil {
+		return Deny, err
+	}
+	for _, d := range options {
+		if decisionNames[d] == reply.Decision {
+			return d, nil
+		}
+	}
+	return Deny, nil
+}
+
+// Question asks a free-form question and returns the answer. An empty
+// answer returns def.
+func (r *Remote) Question(ctx context.Context, question, def string) (string, error) {
+	reply, err := r.escalate(ctx, Escalation{Type: "question", Question: question, Default: def})
+	if err != nil {
+		return "", err
+	}
+	if reply.Answer == "" {
+		return def, nil
+	}
+	return reply.Answer, nil
+}
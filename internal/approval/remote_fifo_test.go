@@ -0,0 +1,80 @@
+//go:build !windows
+
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package approval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFIFO(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approvals")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skip(err)
+	}
+	notifyR, notifyW := io.Pipe()
+	remote := NewFIFO(path, notifyW)
+
+	// The approver answers each escalation it is notified of, after a
+	// stale reply for another one
+	go func() {
+		sc := bufio.NewScanner(notifyR)
+		for sc.Scan() {
+			var e Escalation
+			json.Unmarshal(sc.Bytes(), &e)
+			f, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(f, "{\"id\":\"0-0\",\"decision\":\"allow_always\"}\n{\"id\":%q,\"decision\":\"allow_session\"}\n", e.ID)
+			f.Close()
+		}
+	}()
+
+	d, err := remote.Confirm(context.Background(), Request{Tool: "mcp__deploy", Options: []Decision{AllowSession, AllowAlways}})
+	if err != nil || d != AllowSession {
+		t.Fatalf("Confirm = %v, %v; want AllowSession", d, err)
+	}
+	notifyW.Close()
+}
+
+func TestFIFOCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approvals")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Skip(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// No one answers: Confirm returns when ctx ends, leaving nothing
+	// reading the pipe
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewFIFO(path, io.Discard).Confirm(ctx, Request{Tool: "write_file"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Confirm = %v, want deadline exceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Confirm did not return when its context ended")
+	}
+	// A writer now finds no reader
+	if f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+		f.Close()
+		t.Error("the pipe is still open for reading")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/redact"
+)
+
+func TestWebhook(t *testing.T) {
+	var got []Escalation
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Escalation
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Error(err)
+			return
+		}
+		got = append(got, e)
+		switch e.Type {
+		case "approval":
+			json.NewEncoder(w).Encode(Reply{ID: e.ID, Decision: "allow_session"})
+		case "question":
+			json.NewEncoder(w).Encode(Reply{})
+		}
+	}))
+	defer srv.Close()
+	remote := NewWebhook(srv.URL)
+
+	d, err := remote.Confirm(context.Background(), Request{Tool: "mcp__deploy", Options: []Decision{AllowSession, AllowAlways}})
+	if err != nil || d != AllowSession {
+		t.Fatalf("Confirm = %v, %v", d, err)
+	}
+	d, _ = remote.Confirm(context.Background(), Request{Tool: "run_shell_command"})
+	if d != Deny {
+		t.Errorf("decision not offered should deny, got %v", d)
+	}
+	answer, err := remote.Question(context.Background(), "Which branch?", "main")
+	if err != nil || answer != "main" {
+		t.Fatalf("Question = %q, %v", answer, err)
+	}
+
+	if len(got) != 3 || got[0].Options[1] != "allow_always" || got[2].Question != "Which branch?" || got[0].ID == got[1].ID {
+		t.Errorf("escalations = %+v", got)
+	}
+}
+
+func TestWebhookReplyForAnotherEscalation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Reply{ID: "1-1", Decision: "allow_once"})
+	}))
+	defer srv.Close()
+
+	d, err := NewWebhook(srv.URL).Confirm(context.Background(), Request{Tool: "write_file"})
+	if err == nil || d != Deny {
+		t.Errorf("Confirm = %v, %v; want Deny and an error", d, err)
+	}
+}
+
+func TestRemoteRedacts(t *testing.T) {
+	var got Escalation
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(Reply{Decision: "allow_once"})
+	}))
+	defer srv.Close()
+
+	remote := NewWebhook(srv.URL)
+	remote.Redactor, _ = redact.New(nil)
+	secret := "sk-ant-api03-" + strings.Repeat("x", 40)
+	remote.Confirm(context.Background(), Request{
+		Tool:    "run_shell_command",
+		Args:    map[string]interface{}{"command": "curl -H 'x-api-key: " + secret + "' https://api.anthropic.com"},
+		Summary: "$ curl -H 'x-api-key: " + secret + "'",
+	})
+	if data, _ := json.Marshal(got); strings.Contains(string(data), secret) || got.Tool != "run_shell_command" {
+		t.Errorf("escalation sent = %s", data)
+	}
+}
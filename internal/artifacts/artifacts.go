@@ -0,0 +1,31 @@
+// Package artifacts locates the directory where a run saves deliverables
+// that are not part of the codebase (reports, generated images, exports)
+// and lists what was saved there.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package artifacts
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// Dir returns the artifacts directory of a session, relative to workDir:
+// .g/artifacts/<session>.
+func Dir(session string) string {
+	return filepath.Join(".g", "artifacts", session)
+}
+
+// List returns the files under dir, sorted. A missing dir has none.
+func List(dir string) []string {
+	var files []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,25 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), Dir("sess-1"))
+	if got := List(dir); got != nil {
+		t.Errorf("List of a missing dir = %v", got)
+	}
+	os.MkdirAll(filepath.Join(dir, "charts"), 0755)
+	os.WriteFile(filepath.Join(dir, "report.md"), []byte("# Report"), 0644)
+	os.WriteFile(filepath.Join(dir, "charts", "usage.png"), []byte("png"), 0644)
+
+	want := []string{filepath.Join(dir, "charts", "usage.png"), filepath.Join(dir, "report.md")}
+	if got := List(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("List = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,348 @@
+// Package audit records tool executions in a tamper-evident log.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+const logFile = "g_audit.jsonl"
+
+// Entry is one tool execution. Each entry stores the hash of the previous
+// one, so editing or deleting a line breaks the chain from that point on.
+type Entry struct {
+	Seq      int64           `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Session  string          `json:"session,omitempty"`
+	Tool     string          `json:"tool"`
+	Args     json.RawMessage `json:"args,omitempty"`
+	CWD      string          `json:"cwd,omitempty"`
+	Status   string          `json:"status"` // "ok", "error" or "denied"
+	ExitCode *int            `json:"exitCode,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Approval string          `json:"approval,omitempty"`
+	Tokens   int             `json:"tokens,omitempty"` // estimated size of the result sent to the model
+	Empty    bool            `json:"empty,omitempty"`  // the result listed no matches, files or entries
+	PrevHash string          `json:"prevHash"`
+	Hash     string          `json:"hash"`
+}
+
+// DefaultPath returns ~/.gemini/g_audit.jsonl.
+func DefaultPath() (string, error) {
+	dir, err := config.GeminiDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, logFile), nil
+}
+
+// DefaultMaxSize is the size past which a log file is rotated.
+const DefaultMaxSize = 10 << 20
+
+// rotatedTime names rotated files: path + "." + the time of the rotation,
+// which sorts them oldest first.
+const rotatedTime = "20060102T150405.000000000Z"
+
+// Log appends entries to an audit file. When the file grows past MaxSize
+// it is renamed with the time appended and a new one is started, whose
+// first entry is chained to the last of the old one.
+type Log struct {
+	path    string
+	session string
+
+	// MaxSize is the size past which the file is rotated: 0 means
+	// DefaultMaxSize, a negative size never rotates.
+	MaxSize int64
+
+	mu   sync.Mutex
+	last *Entry      // the final entry of the log
+	file os.FileInfo // the file last was read from; nil before the first Record
+	size int64       // the size of file up to and including last
+}
+
+// Open returns a log that appends to path, tagging entries with session.
+func Open(path, session string) *Log {
+	return &Log{path: path, session: session}
+}
+
+// Record appends e to the log, filling in sequence, time and hashes.
+// A nil Log discards the entry.
+func (l *Log) Record(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	f, info, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		unlockFile(f)
+		f.Close()
+	}()
+
+	last, err := l.lastEntry(f, info)
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		e.Seq = last.Seq + 1
+		e.PrevHash = last.Hash
+	} else {
+		e.Seq = 1
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Session == "" {
+		e.Session = l.session
+	}
+	e.Hash = hashEntry(e)
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	maxSize := l.MaxSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxSize > 0 && l.size > 0 && l.size+int64(len(data)) > maxSize {
+		next, err := l.rotate()
+		if err != nil {
+			return err
+		}
+		// The old file stays locked until the new one is, so no other
+		// process appends to either in between
+		unlockFile(f)
+		f.Close()
+		f = next
+		if info, err = f.Stat(); err != nil {
+			return err
+		}
+		l.file, l.size = info, 0
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	l.last = &e
+	l.size += int64(len(data))
+	return nil
+}
+
+// lock opens and locks the log file. Another process may have rotated it
+// while this one waited for the lock, so the file is opened again until
+// the locked one is the one at the path.
+func (l *Log) lock() (*os.File, os.FileInfo, error) {
+	for {
+		f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := lockFile(f); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			unlockFile(f)
+			f.Close()
+			return nil, nil, err
+		}
+		if current, err := os.Stat(l.path); err == nil && os.SameFile(info, current) {
+			return f, info, nil
+		}
+		unlockFile(f)
+		f.Close()
+	}
+}
+
+// rotate renames the log file and returns the new one, locked.
+func (l *Log) rotate() (*os.File, error) {
+	if err := os.Rename(l.path, l.path+"."+time.Now().UTC().Format(rotatedTime)); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// lastEntry returns the final entry of the log, whose file f is locked. Only
+// what other processes appended since the previous call is read, unless f
+// is another file than then; a new, empty file continues the newest
+// rotated one.
+func (l *Log) lastEntry(f *os.File, info os.FileInfo) (*Entry, error) {
+	from := int64(0)
+	if l.file != nil && os.SameFile(l.file, info) && info.Size() >= l.size {
+		from = l.size
+	} else {
+		l.last = nil
+		if info.Size() == 0 {
+			files, err := rotated(l.path)
+			if err != nil {
+				return nil, err
+			}
+			if len(files) > 0 {
+				err := scanFile(files[len(files)-1], func(_ int, e *Entry) error {
+					l.last = e
+					return nil
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	err := scanReader(io.NewSectionReader(f, from, info.Size()-from), func(_ int, e *Entry) error {
+		l.last = e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.file, l.size = info, info.Size()
+	return l.last, nil
+}
+
+// hashEntry returns the SHA-256 of the entry's JSON with Hash cleared.
+func hashEntry(e Entry) string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// rotated returns the rotated files of the log at path, oldest first.
+func rotated(path string) ([]string, error) {
+	names, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(path) + "."
+	var files []string
+	for _, n := range names {
+		name := n.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, err := time.Parse(rotatedTime, name[len(prefix):]); err == nil {
+			files = append(files, filepath.Join(filepath.Dir(path), name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// scan calls fn for every entry in the log, its rotated files first, with
+// its 1-based line number. Errors name the file when there are several.
+func scan(path string, fn func(line int, e *Entry) error) error {
+	files, err := rotated(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil || len(files) == 0 {
+		files = append(files, path)
+	}
+	for _, file := range files {
+		if err := scanFile(file, fn); err != nil {
+			if len(files) > 1 && !os.IsNotExist(err) {
+				return fmt.Errorf("%s: %w", filepath.Base(file), err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// scanFile calls fn for every entry in one file of the log.
+func scanFile(path string, fn func(line int, e *Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return scanReader(f, fn)
+}
+
+func scanReader(r io.Reader, fn func(line int, e *Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("line %d: invalid entry: %w", line, err)
+		}
+		if err := fn(line, &e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Read returns all entries of the log.
+func Read(path string) ([]Entry, error) {
+	var entries []Entry
+	err := scan(path, func(_ int, e *Entry) error {
+		entries = append(entries, *e)
+		return nil
+	})
+	return entries, err
+}
+
+// Verify checks the hash chain through the log and its rotated files and
+// returns the number of valid entries. The error names the first line that
+// does not match.
+func Verify(path string) (int, error) {
+	count := 0
+	prev := ""
+	var prevSeq int64
+	err := scan(path, func(line int, e *Entry) error {
+		if e.PrevHash != prev {
+			return fmt.Errorf("line %d: chain broken (previous entry missing or modified)", line)
+		}
+		if e.Seq != prevSeq+1 {
+			return fmt.Errorf("line %d: sequence %d follows %d", line, e.Seq, prevSeq)
+		}
+		if hashEntry(*e) != e.Hash {
+			return fmt.Errorf("line %d: entry has been modified", line)
+		}
+		prev = e.Hash
+		prevSeq = e.Seq
+		count++
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := Open(path, "s1")
+	code := 0
+	for _, tool := range []string{"read_file", "run_shell_command", "write_file"} {
+		if err := log.Record(Entry{Tool: tool, Status: "ok", ExitCode: &code, Approval: "auto"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := Verify(path)
+	if err != nil || n != 3 {
+		t.Fatalf("Verify = %d, %v; want 3, nil", n, err)
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries[2].Seq != 3 || entries[2].PrevHash != entries[1].Hash || entries[0].Session != "s1" {
+		t.Fatalf("unexpected chain: %+v", entries)
+	}
+
+	// Tamper with the second entry
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), "run_shell_command", "read_many_files", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected tampering at line 2, got %v", err)
+	}
+
+	// Delete the first entry
+	lines := strings.SplitAfter(string(data), "\n")
+	if err := os.WriteFile(path, []byte(strings.Join(lines[1:], "")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(path); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("expected broken chain at line 1, got %v", err)
+	}
+}
+
+func TestRecordConcurrentLogs(t *testing.T) {
+	// Two processes appending to one file continue each other's chain
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	a, b := Open(path, "a"), Open(path, "b")
+	for _, log := range []*Log{a, b, a, a, b} {
+		if err := log.Record(Entry{Tool: "read_file", Status: "ok"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := Verify(path); err != nil || n != 5 {
+		t.Fatalf("Verify = %d, %v; want 5, nil", n, err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	log := Open(path, "s1")
+	log.MaxSize = 1000
+	for i := 0; i < 20; i++ {
+		if err := log.Record(Entry{Tool: "read_file", Status: "ok"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := rotated(path)
+	if err != nil || len(files) < 2 {
+		t.Fatalf("rotated = %v, %v; want several files", files, err)
+	}
+	for _, f := range append(files, path) {
+		if info, err := os.Stat(f); err != nil || info.Size() > 1000 {
+			t.Errorf("%s: %v, %v", f, info, err)
+		}
+	}
+	n, err := Verify(path)
+	if err != nil || n != 20 {
+		t.Fatalf("Verify = %d, %v; want 20, nil", n, err)
+	}
+	entries, err := Read(path)
+	if err != nil || len(entries) != 20 || entries[19].Seq != 20 {
+		t.Fatalf("Read = %d entries, %v", len(entries), err)
+	}
+
+	// Another process continues the chain in the current file
+	if err := Open(path, "s2").Record(Entry{Tool: "glob", Status: "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Verify(path); err != nil || n != 21 {
+		t.Fatalf("Verify = %d, %v; want 21, nil", n, err)
+	}
+
+	// Removing a rotated file breaks the chain
+	if err := os.Remove(files[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(path); err == nil || !strings.Contains(err.Error(), filepath.Base(files[2])) {
+		t.Fatalf("expected broken chain in %s, got %v", filepath.Base(files[2]), err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := Open(path, "s1")
+	record := func(e Entry) {
+		t.Helper()
+		if err := log.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		record(Entry{Tool: "grep_search", Status: "ok", Tokens: 100, Empty: i < 4})
+	}
+	record(Entry{Tool: "run_shell_command", Status: "error", Tokens: 50})
+	record(Entry{Tool: "run_shell_command", Status: "denied", Tokens: 10})
+	record(Entry{Tool: "read_file", Status: "ok", Session: "s2"})
+
+	stats, err := Stats(path, Filter{Session: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || stats[0].Tool != "grep_search" {
+		t.Fatalf("Stats = %+v; want grep_search then run_shell_command", stats)
+	}
+	grep, shell := stats[0], stats[1]
+	if grep.Calls != 10 || grep.Empty != 4 || grep.EmptyRate() != 0.4 || grep.AvgTokens() != 100 {
+		t.Errorf("grep_search stats = %+v", grep)
+	}
+	if shell.Calls != 2 || shell.Errors != 1 || shell.Denied != 1 || shell.ErrorRate() != 1 {
+		t.Errorf("run_shell_command stats = %+v", shell)
+	}
+
+	recs := Recommendations(stats)
+	if len(recs) != 1 || !strings.HasPrefix(recs[0], "grep_search returned 0 matches 40% of the time (4 of 10 calls)") {
+		t.Errorf("Recommendations = %q", recs)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package audit
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock so concurrent g processes do not fork
+// the hash chain.
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+func unlockFile(f *os.File) {
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
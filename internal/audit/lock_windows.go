@@ -0,0 +1,41 @@
+//go:build windows
+
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package audit
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const lockfileExclusiveLock = 0x2
+
+// lockRegion returns the byte range that is locked. Windows locks are
+// mandatory, so it lies far beyond the end of the log, where it keeps
+// writers out of each other's way without stopping anyone from reading
+// the entries.
+func lockRegion() *syscall.Overlapped {
+	return &syscall.Overlapped{Offset: 0xFFFFFFFF, OffsetHigh: 0x7FFFFFFF}
+}
+
+// lockFile takes an exclusive lock so concurrent g processes do not fork
+// the hash chain.
+func lockFile(f *os.File) error {
+	r, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(lockRegion())))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func unlockFile(f *os.File) {
+	procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(lockRegion())))
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ToolStats summarizes the logged calls of one tool.
+type ToolStats struct {
+	Tool    string `json:"tool"`
+	Calls   int    `json:"calls"`
+	Errors  int    `json:"errors"`
+	Denied  int    `json:"denied"`
+	Empty   int    `json:"empty"`
+	Tokens  int    `json:"tokens"`
+	Counted int    `json:"-"` // calls logged with a result size; older entries have none
+}
+
+// ErrorRate is the share of calls that failed, denials excluded.
+func (s ToolStats) ErrorRate() float64 { return ratio(s.Errors, s.Calls-s.Denied) }
+
+// EmptyRate is the share of successful calls that found nothing.
+func (s ToolStats) EmptyRate() float64 { return ratio(s.Empty, s.Calls-s.Errors-s.Denied) }
+
+// AvgTokens is the average estimated result size.
+func (s ToolStats) AvgTokens() int {
+	if s.Counted == 0 {
+		return 0
+	}
+	return s.Tokens / s.Counted
+}
+
+func ratio(n, of int) float64 {
+	if of <= 0 {
+		return 0
+	}
+	return float64(n) / float64(of)
+}
+
+// Filter selects the entries Stats counts. Zero fields match everything.
+type Filter struct {
+	Session string
+	Since   time.Time
+}
+
+func (f Filter) match(e *Entry) bool {
+	if f.Session != "" && e.Session != f.Session {
+		return false
+	}
+	return f.Since.IsZero() || !e.Time.Before(f.Since)
+}
+
+// Stats aggregates the entries of the log matching f per tool, the tools
+// with the most result tokens first.
+func Stats(path string, f Filter) ([]ToolStats, error) {
+	byTool := map[string]*ToolStats{}
+	err := scan(path, func(_ int, e *Entry) error {
+		if !f.match(e) {
+			return nil
+		}
+		s := byTool[e.Tool]
+		if s == nil {
+			s = &ToolStats{Tool: e.Tool}
+			byTool[e.Tool] = s
+		}
+		s.Calls++
+		switch e.Status {
+		case "error":
+			s.Errors++
+		case "denied":
+			s.Denied++
+		}
+		if e.Empty {
+			s.Empty++
+		}
+		if e.Tokens > 0 {
+			s.Tokens += e.Tokens
+			s.Counted++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]ToolStats, 0, len(byTool))
+	for _, s := range byTool {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Tokens != stats[j].Tokens {
+			return stats[i].Tokens > stats[j].Tokens
+		}
+		return stats[i].Tool < stats[j].Tool
+	})
+	return stats, nil
+}
+
+// Thresholds for Recommendations. Tools called fewer than minCalls times
+// are too rare to judge.
+const (
+	minCalls       = 5
+	emptyRateHigh  = 0.3
+	errorRateHigh  = 0.25
+	avgTokensHigh  = 5000
+	tokenShareHigh = 0.5
+)
+
+// Recommendations points out tools that often find nothing, often fail or
+// dominate the tokens spent on results, with a hint for each.
+func Recommendations(stats []ToolStats) []string {
+	total := 0
+	for _, s := range stats {
+		total += s.Tokens
+	}
+	var recs []string
+	for _, s := range stats {
+		if s.Calls < minCalls {
+			continue
+		}
+		if r := s.EmptyRate(); r >= emptyRateHigh {
+			recs = append(recs, fmt.Sprintf("%s returned 0 matches %.0f%% of the time (%d of %d calls). %s",
+				s.Tool, r*100, s.Empty, s.Calls-s.Errors-s.Denied, emptyHint(s.Tool)))
+		}
+		if r := s.ErrorRate(); r >= errorRateHigh {
+			recs = append(recs, fmt.Sprintf("%s failed %.0f%% of the time (%d of %d calls). Run 'g audit show' to see the errors; instructions in GEMINI.md on how to build, test or call it may help.",
+				s.Tool, r*100, s.Errors, s.Calls-s.Denied))
+		}
+		if share := ratio(s.Tokens, total); share >= tokenShareHigh && s.AvgTokens() >= avgTokensHigh {
+			recs = append(recs, fmt.Sprintf("%s results make up %.0f%% of the result tokens, ~%d per call. Add large generated or vendored files to .geminiignore, or lower tools.limits.",
+				s.Tool, share*100, s.AvgTokens()))
+		}
+	}
+	return recs
+}
+
+func emptyHint(tool string) string {
+	switch tool {
+	case "grep_search", "glob":
+		return "The model may be guessing names or searching ignored files; describe the layout in GEMINI.md, or check .gitignore and .geminiignore do not hide what it looks for."
+	case "list_directory":
+		return "The model may be guessing paths; describe the layout in GEMINI.md."
+	}
+	return "Say in the prompt or GEMINI.md where things are."
+}
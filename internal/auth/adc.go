@@ -0,0 +1,206 @@
+// Application Default Credentials for headless use
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const (
+	// ADCEnv names the credentials file for Application Default Credentials.
+	ADCEnv = "GOOGLE_APPLICATION_CREDENTIALS"
+
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	jwtBearerGrant     = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+)
+
+// adcFile is a service account key or a gcloud user credentials file.
+type adcFile struct {
+	Type string `json:"type"` // "service_account" or "authorized_user"
+
+	// service_account
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+
+	// authorized_user (gcloud auth application-default login)
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ADCPath returns the Application Default Credentials file: $ADCEnv when
+// set, otherwise gcloud's well-known file if it exists, otherwise "".
+func ADCPath() string {
+	if path := os.Getenv(ADCEnv); path != "" {
+		return path
+	}
+	dir := os.Getenv("CLOUDSDK_CONFIG")
+	if dir == "" {
+		if runtime.GOOS == "windows" {
+			dir = filepath.Join(os.Getenv("APPDATA"), "gcloud")
+		} else if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, ".config", "gcloud")
+		}
+	}
+	path := filepath.Join(dir, "application_default_credentials.json")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// ADCAccount returns the service account email of the credentials file at
+// path, or "" for gcloud user credentials.
+func ADCAccount(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var f adcFile
+	if json.Unmarshal(data, &f) != nil {
+		return ""
+	}
+	return f.ClientEmail
+}
+
+// ADCHTTPClient returns an HTTP client authenticated with the service
+// account key or gcloud user credentials at path. The first token is
+// fetched with the first request and refreshed in the background.
+func ADCHTTPClient(path string) (*http.Client, error) {
+	refresh, err := adcTokenSource(path)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: &refreshingTransport{
+			refresh: func(*Credentials) (*Credentials, error) { return refresh() },
+			creds:   &Credentials{},
+			base:    http.DefaultTransport,
+		},
+	}, nil
+}
+
+// adcTokenSource reads the credentials file at path and returns a function
+// that obtains a new access token from it.
+func adcTokenSource(path string) (func() (*Credentials, error), error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("application default credentials: %w", err)
+	}
+	var f adcFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("application default credentials: failed to parse %s: %w", path, err)
+	}
+
+	switch f.Type {
+	case "service_account":
+		key, err := parsePrivateKey(f.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("service account %s: %w", f.ClientEmail, err)
+		}
+		tokenURI := f.TokenURI
+		if tokenURI == "" {
+			tokenURI = tokenEndpoint
+		}
+		return func() (*Credentials, error) {
+			assertion, err := signJWT(key, f.PrivateKeyID, map[string]interface{}{
+				"iss":   f.ClientEmail,
+				"scope": cloudPlatformScope,
+				"aud":   tokenURI,
+				"iat":   time.Now().Unix(),
+				"exp":   time.Now().Add(time.Hour).Unix(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			form := url.Values{}
+			form.Set("grant_type", jwtBearerGrant)
+			form.Set("assertion", assertion)
+			creds, err := requestToken(tokenURI, form)
+			if err != nil {
+				return nil, fmt.Errorf("service account %s: %w", f.ClientEmail, err)
+			}
+			return creds, nil
+		}, nil
+
+	case "authorized_user":
+		if f.RefreshToken == "" {
+			return nil, fmt.Errorf("application default credentials: %s has no refresh token", path)
+		}
+		return func() (*Credentials, error) {
+			form := url.Values{}
+			form.Set("grant_type", "refresh_token")
+			form.Set("refresh_token", f.RefreshToken)
+			form.Set("client_id", f.ClientID)
+			form.Set("client_secret", f.ClientSecret)
+			creds, err := requestToken(tokenEndpoint, form)
+			if err != nil {
+				return nil, fmt.Errorf("application default credentials: %w: run 'gcloud auth application-default login'", err)
+			}
+			return creds, nil
+		}, nil
+	}
+	return nil, fmt.Errorf("application default credentials: unsupported credential type %q in %s", f.Type, path)
+}
+
+// parsePrivateKey decodes a PEM-encoded PKCS#8 or PKCS#1 RSA key.
+func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("private_key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("private_key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private_key: %w", err)
+	}
+	return key, nil
+}
+
+// signJWT returns an RS256-signed JWT carrying claims.
+func signJWT(key *rsa.PrivateKey, keyID string, claims map[string]interface{}) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	if keyID != "" {
+		header["kid"] = keyID
+	}
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(h) + "." + enc.EncodeToString(c)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestADCServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			gotAuth = r.Header.Get("Authorization")
+			return
+		}
+		r.ParseForm()
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if r.Form.Get("grant_type") != jwtBearerGrant || len(parts) != 3 {
+			http.Error(w, "bad grant", http.StatusBadRequest)
+			return
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig) != nil {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		if !strings.Contains(string(claims), `"iss":"ci@example.iam.gserviceaccount.com"`) {
+			http.Error(w, "bad claims", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "sa-token", "expires_in": 3600, "token_type": "Bearer"})
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "sa.json")
+	data, _ := json.Marshal(adcFile{
+		Type:         "service_account",
+		ClientEmail:  "ci@example.iam.gserviceaccount.com",
+		PrivateKeyID: "k1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:     srv.URL + "/token",
+	})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ADCEnv, path)
+	if got := ADCPath(); got != path {
+		t.Fatalf("ADCPath() = %q, want %q", got, path)
+	}
+
+	client, err := ADCHTTPClient(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL + "/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if gotAuth != "Bearer sa-token" {
+		t.Errorf("Authorization = %q, want the service account token", gotAuth)
+	}
+}
+
+func TestADCUnsupportedType(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "creds.json")
+	os.WriteFile(path, []byte(`{"type":"external_account"}`), 0600)
+	if _, err := ADCHTTPClient(path); err == nil || !strings.Contains(err.Error(), "external_account") {
+		t.Errorf("err = %v, want unsupported type", err)
+	}
+}
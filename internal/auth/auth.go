@@ -7,12 +7,14 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/k-sub1995/g/internal/config"
@@ -62,16 +64,46 @@ func NewManager() (*Manager, error) {
 	return &Manager{geminiDir: geminiDir}, nil
 }
 
-// LoadCredentials loads OAuth credentials from file or keychain
+// LoadCredentials loads OAuth credentials from the Gemini CLI's keychain
+// item (macOS), g's own keyring entry, or oauth_creds.json. When several
+// exist, the one that expires last is the most recently refreshed.
 func (m *Manager) LoadCredentials() (*Credentials, error) {
-	// Try keychain first (macOS only)
-	creds, err := m.loadFromKeychain()
-	if err == nil && creds != nil {
-		return creds, nil
+	var best *Credentials
+	for _, load := range []func() (*Credentials, error){m.loadFromKeychain, m.loadFromKeyring} {
+		if creds, err := load(); err == nil && creds != nil && creds.RefreshToken != "" {
+			if best == nil || creds.ExpiryDate > best.ExpiryDate {
+				best = creds
+			}
+		}
+	}
+
+	creds, err := m.loadFromFile()
+	if err != nil {
+		if best != nil {
+			return best, nil
+		}
+		return nil, err
 	}
+	if best == nil || creds.ExpiryDate > best.ExpiryDate {
+		best = creds
+	}
+	return best, nil
+}
 
-	// Fall back to file
-	return m.loadFromFile()
+// Account returns the email of the Google account the Gemini CLI is signed
+// in with, or "" when it is unknown.
+func (m *Manager) Account() string {
+	data, err := os.ReadFile(filepath.Join(m.geminiDir, "google_accounts.json"))
+	if err != nil {
+		return ""
+	}
+	var accounts struct {
+		Active string `json:"active"`
+	}
+	if json.Unmarshal(data, &accounts) != nil {
+		return ""
+	}
+	return accounts.Active
 }
 
 // loadFromFile reads credentials from oauth_creds.json
@@ -105,18 +137,44 @@ func (m *Manager) RefreshToken(creds *Credentials) (*Credentials, error) {
 	data.Set("client_id", clientID)
 	data.Set("client_secret", clientSecret)
 
+	refreshed, err := requestToken(tokenEndpoint, data)
+	if err != nil {
+		var status *tokenStatusError
+		if errors.As(err, &status) {
+			return nil, fmt.Errorf("token refresh failed (status %d): run 'gemini' to re-authenticate", status.code)
+		}
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	refreshed.RefreshToken = creds.RefreshToken
+	// Keep the new token for later runs; failing to save only costs a refresh
+	_ = m.SaveCredentials(refreshed)
+	return refreshed, nil
+}
+
+// tokenStatusError is a token endpoint's rejection of a grant.
+type tokenStatusError struct {
+	code int
+}
+
+func (e *tokenStatusError) Error() string {
+	return fmt.Sprintf("token request failed (status %d)", e.code)
+}
+
+// requestToken posts an OAuth grant to endpoint and returns the access
+// token it issues.
+func requestToken(endpoint string, form url.Values) (*Credentials, error) {
 	resp, err := http.Post(
-		tokenEndpoint,
+		endpoint,
 		"application/x-www-form-urlencoded",
-		strings.NewReader(data.Encode()),
+		strings.NewReader(form.Encode()),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to refresh token: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token refresh failed (status %d): run 'gemini' to re-authenticate", resp.StatusCode)
+		return nil, &tokenStatusError{code: resp.StatusCode}
 	}
 
 	var tokenResp struct {
@@ -129,10 +187,9 @@ func (m *Manager) RefreshToken(creds *Credentials) (*Credentials, error) {
 	}
 
 	return &Credentials{
-		AccessToken:  tokenResp.AccessToken,
-		RefreshToken: creds.RefreshToken,
-		TokenType:    tokenResp.TokenType,
-		ExpiryDate:   time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second).UnixMilli(),
+		AccessToken: tokenResp.AccessToken,
+		TokenType:   tokenResp.TokenType,
+		ExpiryDate:  time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second).UnixMilli(),
 	}, nil
 }
 
@@ -146,6 +203,21 @@ func (m *Manager) HTTPClient(creds *Credentials) *http.Client {
 	}
 }
 
+// RefreshingHTTPClient returns an HTTP client that refreshes the access
+// token in the background shortly before it expires, so REPL sessions and
+// long agent runs never send an expired token.
+func (m *Manager) RefreshingHTTPClient(creds *Credentials) *http.Client {
+	t := &refreshingTransport{
+		refresh: m.RefreshToken,
+		creds:   creds,
+		base:    http.DefaultTransport,
+	}
+	t.mu.Lock()
+	t.schedule()
+	t.mu.Unlock()
+	return &http.Client{Transport: t}
+}
+
 // authTransport adds Authorization header to requests
 type authTransport struct {
 	token string
@@ -156,3 +228,68 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.Header.Set("Authorization", "Bearer "+t.token)
 	return t.base.RoundTrip(req)
 }
+
+const (
+	// refreshAhead is how long before expiry the background refresh runs,
+	// ahead of IsExpired's 5 minute margin so requests don't wait for it.
+	refreshAhead = 10 * time.Minute
+	// refreshRetry is the wait after a failed background refresh.
+	refreshRetry = time.Minute
+)
+
+// refreshingTransport adds the Authorization header. It refreshes the token
+// on a timer before it expires, and before a request if that failed.
+type refreshingTransport struct {
+	refresh func(*Credentials) (*Credentials, error)
+	base    http.RoundTripper
+
+	mu    sync.Mutex
+	creds *Credentials
+	timer *time.Timer
+}
+
+// schedule arms the background refresh for the current token. The caller
+// holds t.mu.
+func (t *refreshingTransport) schedule() {
+	if t.creds.AccessToken == "" || t.creds.ExpiryDate == 0 {
+		return
+	}
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	wait := time.Until(time.UnixMilli(t.creds.ExpiryDate).Add(-refreshAhead))
+	t.timer = time.AfterFunc(max(wait, 0), t.backgroundRefresh)
+}
+
+func (t *refreshingTransport) backgroundRefresh() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	creds, err := t.refresh(t.creds)
+	if err != nil {
+		// Offline or a transient error: try again, and RoundTrip still
+		// refreshes once the token is about to expire
+		t.timer = time.AfterFunc(refreshRetry, t.backgroundRefresh)
+		return
+	}
+	t.creds = creds
+	t.schedule()
+}
+
+func (t *refreshingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	if t.creds.AccessToken == "" || t.creds.IsExpired() {
+		creds, err := t.refresh(t.creds)
+		if err != nil {
+			t.mu.Unlock()
+			return nil, err
+		}
+		t.creds = creds
+		t.schedule()
+	}
+	token := t.creds.AccessToken
+	t.mu.Unlock()
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRefreshingTransportRefreshesBeforeExpiry(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	refreshed := make(chan struct{}, 1)
+	tr := &refreshingTransport{
+		refresh: func(c *Credentials) (*Credentials, error) {
+			refreshed <- struct{}{}
+			return &Credentials{AccessToken: "new", ExpiryDate: time.Now().Add(time.Hour).UnixMilli()}, nil
+		},
+		// Inside the background window but not yet expired for RoundTrip
+		creds: &Credentials{AccessToken: "old", ExpiryDate: time.Now().Add(8 * time.Minute).UnixMilli()},
+		base:  http.DefaultTransport,
+	}
+	tr.mu.Lock()
+	tr.schedule()
+	tr.mu.Unlock()
+
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("token was not refreshed in the background")
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got != "Bearer new" {
+		t.Errorf("Authorization = %q, want the refreshed token", got)
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.timer == nil || !tr.timer.Stop() {
+		t.Error("no refresh scheduled for the new token")
+	}
+}
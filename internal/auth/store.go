@@ -0,0 +1,65 @@
+// Credential storage in the OS keychain
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/k-sub1995/g/internal/keyring"
+)
+
+// g keeps refreshed tokens under its own keyring entry, so the Gemini CLI's
+// files and keychain items are never modified by a refresh.
+const (
+	credsService = "g-oauth"
+	credsAccount = "default"
+)
+
+// loadFromKeyring reads the credentials g saved with SaveCredentials.
+func (m *Manager) loadFromKeyring() (*Credentials, error) {
+	data, err := keyring.Get(credsService, credsAccount)
+	if err != nil {
+		return nil, err
+	}
+	var creds Credentials
+	if err := json.Unmarshal([]byte(data), &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// SaveCredentials stores creds in the OS keychain, or in a 0600 file under
+// ~/.gemini when no keychain is available.
+func (m *Manager) SaveCredentials(creds *Credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(credsService, credsAccount, string(data))
+}
+
+// MoveToKeychain copies the credentials in oauth_creds.json to the OS
+// keychain and deletes the file. It fails, leaving the file alone, when no
+// keychain is available. It returns the removed file's path.
+func (m *Manager) MoveToKeychain() (string, error) {
+	path := filepath.Join(m.geminiDir, oauthFile)
+	creds, err := m.loadFromFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return "", err
+	}
+	if err := keyring.SetNative(credsService, credsAccount, string(data)); err != nil {
+		return "", fmt.Errorf("no OS keychain available (%v); %s was left in place", err, path)
+	}
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
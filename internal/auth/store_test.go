@@ -0,0 +1,131 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/keyring"
+)
+
+// fakeKeychain stands in for the OS keychain.
+type fakeKeychain map[string]string
+
+func (k fakeKeychain) Get(service, account string) (string, error) {
+	if v, ok := k[service+"/"+account]; ok {
+		return v, nil
+	}
+	return "", keyring.ErrNotFound
+}
+
+func (k fakeKeychain) Set(service, account, secret string) error {
+	k[service+"/"+account] = secret
+	return nil
+}
+
+func (k fakeKeychain) Delete(service, account string) error {
+	delete(k, service+"/"+account)
+	return nil
+}
+
+// storeTest returns a manager with its own home and the given keychain
+// (nil for none).
+func storeTest(t *testing.T, k keyring.Backend) *Manager {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	saved := keyring.Native
+	keyring.Native = k
+	t.Cleanup(func() { keyring.Native = saved })
+	dir := filepath.Join(home, ".gemini")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return &Manager{geminiDir: dir}
+}
+
+func writeOAuthFile(t *testing.T, m *Manager, creds *Credentials) string {
+	t.Helper()
+	data, _ := json.Marshal(creds)
+	path := filepath.Join(m.geminiDir, oauthFile)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCredentialsFromKeychain(t *testing.T) {
+	k := fakeKeychain{}
+	m := storeTest(t, k)
+	writeOAuthFile(t, m, &Credentials{AccessToken: "file", RefreshToken: "r", ExpiryDate: 100})
+
+	// A refresh saved to the keychain is newer than the Gemini CLI's file
+	if err := m.SaveCredentials(&Credentials{AccessToken: "keychain", RefreshToken: "r", ExpiryDate: 200}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := k[credsService+"/"+credsAccount]; !ok {
+		t.Fatal("SaveCredentials did not use the keychain")
+	}
+	if _, err := os.Stat(filepath.Join(m.geminiDir, "g_secrets.json")); err == nil {
+		t.Error("SaveCredentials wrote the fallback file although a keychain is available")
+	}
+	creds, err := m.LoadCredentials()
+	if err != nil || creds.AccessToken != "keychain" {
+		t.Fatalf("LoadCredentials = %+v, %v; want the keychain's", creds, err)
+	}
+
+	// Without the file the keychain alone is enough
+	os.Remove(filepath.Join(m.geminiDir, oauthFile))
+	if creds, err := m.LoadCredentials(); err != nil || creds.AccessToken != "keychain" {
+		t.Fatalf("LoadCredentials without file = %+v, %v", creds, err)
+	}
+}
+
+func TestLoadCredentialsFileFallback(t *testing.T) {
+	m := storeTest(t, nil)
+	if err := m.SaveCredentials(&Credentials{AccessToken: "saved", RefreshToken: "r", ExpiryDate: 300}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(m.geminiDir, "g_secrets.json"))
+	if err != nil {
+		t.Fatalf("no fallback file without a keychain: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 && runtime.GOOS != "windows" {
+		t.Errorf("fallback file mode = %v, want 0600", perm)
+	}
+	writeOAuthFile(t, m, &Credentials{AccessToken: "file", RefreshToken: "r", ExpiryDate: 100})
+	if creds, err := m.LoadCredentials(); err != nil || creds.AccessToken != "saved" {
+		t.Fatalf("LoadCredentials = %+v, %v; want the saved refresh", creds, err)
+	}
+}
+
+func TestMoveToKeychain(t *testing.T) {
+	// Without a keychain the file is left alone
+	m := storeTest(t, nil)
+	path := writeOAuthFile(t, m, &Credentials{AccessToken: "file", RefreshToken: "r", ExpiryDate: 100})
+	if _, err := m.MoveToKeychain(); err == nil {
+		t.Fatal("MoveToKeychain succeeded without a keychain")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("the file was removed although the move failed: %v", err)
+	}
+
+	k := fakeKeychain{}
+	m = storeTest(t, k)
+	path = writeOAuthFile(t, m, &Credentials{AccessToken: "file", RefreshToken: "r", ExpiryDate: 100})
+	removed, err := m.MoveToKeychain()
+	if err != nil || removed != path {
+		t.Fatalf("MoveToKeychain = %q, %v", removed, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("oauth_creds.json is still there")
+	}
+	if creds, err := m.LoadCredentials(); err != nil || creds.AccessToken != "file" || creds.RefreshToken != "r" {
+		t.Fatalf("LoadCredentials after the move = %+v, %v", creds, err)
+	}
+}
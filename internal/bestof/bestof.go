@@ -0,0 +1,122 @@
+// Package bestof picks one of several response candidates.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package bestof
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// Mode selects how candidates are presented.
+type Mode string
+
+const (
+	All      Mode = "all"      // show every candidate
+	Shortest Mode = "shortest" // the shortest text
+	Longest  Mode = "longest"  // the longest text
+	Best     Mode = "best"     // ask the model to rank them
+)
+
+// ParseMode parses a --pick value.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case All, Shortest, Longest, Best:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown selection %q (use all, shortest, longest or best)", s)
+}
+
+// Texts returns the text of each candidate in resp.
+func Texts(resp *api.GenerateResponse) []string {
+	texts := make([]string, len(resp.Response.Candidates))
+	for i, c := range resp.Response.Candidates {
+		var b strings.Builder
+		for _, p := range c.Content.Parts {
+			b.WriteString(p.Text)
+		}
+		texts[i] = strings.TrimSpace(b.String())
+	}
+	return texts
+}
+
+// Select returns the index of the candidate chosen by mode. Best sends the
+// request's last user message and the candidates to p with the request's
+// model and asks which answers it best. Empty candidates are never chosen
+// while others exist.
+func Select(ctx context.Context, p api.Provider, req *api.GenerateRequest, texts []string, mode Mode) (int, error) {
+	if len(texts) == 0 {
+		return 0, fmt.Errorf("no candidates")
+	}
+	pick := 0
+	switch mode {
+	case Shortest, Longest:
+		for i, t := range texts {
+			switch {
+			case t == "":
+			case texts[pick] == "",
+				mode == Shortest && len(t) < len(texts[pick]),
+				mode == Longest && len(t) > len(texts[pick]):
+				pick = i
+			}
+		}
+	case Best:
+		return rank(ctx, p, req, texts)
+	}
+	return pick, nil
+}
+
+var firstNumber = regexp.MustCompile(`\d+`)
+
+func rank(ctx context.Context, p api.Provider, req *api.GenerateRequest, texts []string) (int, error) {
+	var b strings.Builder
+	b.WriteString("Several answers were generated for the request below. Pick the one that best fulfils it: correct, complete and fitting the requested form.\n\n<request>\n")
+	b.WriteString(lastUserText(req.Request.Contents))
+	b.WriteString("\n</request>\n")
+	for i, t := range texts {
+		fmt.Fprintf(&b, "\n<answer number=\"%d\">\n%s\n</answer>\n", i+1, t)
+	}
+	b.WriteString("\nReply with only the number of the best answer.")
+
+	resp, err := p.Generate(ctx, &api.GenerateRequest{
+		Model:        req.Model,
+		Project:      req.Project,
+		UserPromptID: req.UserPromptID,
+		Request: api.InnerRequest{
+			Contents: []api.Content{{Role: "user", Parts: []api.Part{{Text: b.String()}}}},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ranking candidates: %w", err)
+	}
+	reply := Texts(resp)
+	if len(reply) == 0 {
+		return 0, fmt.Errorf("ranking candidates: empty reply")
+	}
+	n, err := strconv.Atoi(firstNumber.FindString(reply[0]))
+	if err != nil || n < 1 || n > len(texts) {
+		return 0, fmt.Errorf("ranking candidates: unexpected reply %q", reply[0])
+	}
+	return n - 1, nil
+}
+
+func lastUserText(contents []api.Content) string {
+	for i := len(contents) - 1; i >= 0; i-- {
+		if contents[i].Role != "user" {
+			continue
+		}
+		var parts []string
+		for _, p := range contents[i].Parts {
+			if p.Text != "" {
+				parts = append(parts, p.Text)
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package bestof
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+type fakeProvider struct {
+	reply string
+	got   *api.GenerateRequest
+}
+
+func (f *fakeProvider) Generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	f.got = req
+	resp := &api.GenerateResponse{}
+	resp.Response.Candidates = []api.Candidate{{Content: api.Content{Parts: []api.Part{{Text: f.reply}}}}}
+	return resp, nil
+}
+
+func (f *fakeProvider) GenerateStream(ctx context.Context, req *api.GenerateRequest) (<-chan api.StreamEvent, error) {
+	panic("not used")
+}
+
+func TestSelect(t *testing.T) {
+	texts := []string{"medium one", "", "a", "the longest answer"}
+	req := &api.GenerateRequest{Model: "m", Request: api.InnerRequest{Contents: []api.Content{
+		{Role: "user", Parts: []api.Part{{Text: "name my cat"}}},
+	}}}
+
+	for mode, want := range map[Mode]int{All: 0, Shortest: 2, Longest: 3} {
+		if got, err := Select(context.Background(), nil, req, texts, mode); err != nil || got != want {
+			t.Errorf("Select(%s) = %d, %v; want %d", mode, got, err, want)
+		}
+	}
+
+	p := &fakeProvider{reply: "Answer 4 is best."}
+	got, err := Select(context.Background(), p, req, texts, Best)
+	if err != nil || got != 3 {
+		t.Fatalf("Select(best) = %d, %v; want 3", got, err)
+	}
+	prompt := p.got.Request.Contents[0].Parts[0].Text
+	if !strings.Contains(prompt, "name my cat") || !strings.Contains(prompt, `<answer number="4">`) {
+		t.Errorf("ranking prompt = %q", prompt)
+	}
+
+	p.reply = "7"
+	if _, err := Select(context.Background(), p, req, texts, Best); err == nil {
+		t.Error("out-of-range ranking accepted")
+	}
+}
@@ -0,0 +1,209 @@
+// Package bootstrap generates starter project context (GEMINI.md and
+// .geminiignore) for a repository.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// Ecosystem is a build system detected from a marker file.
+type Ecosystem struct {
+	Name    string   // e.g. "Go"
+	Marker  string   // file that identified it, e.g. "go.mod"
+	Ignores []string // .geminiignore patterns for its build output
+}
+
+var ecosystems = []Ecosystem{
+	{"Go", "go.mod", []string{"vendor/"}},
+	{"Node.js", "package.json", []string{"node_modules/", "dist/", "build/", "coverage/", ".next/", "*.min.js"}},
+	{"Rust", "Cargo.toml", []string{"target/"}},
+	{"Python", "pyproject.toml", []string{"__pycache__/", "*.pyc", ".venv/", "venv/", ".tox/", "*.egg-info/", ".pytest_cache/"}},
+	{"Python", "requirements.txt", []string{"__pycache__/", "*.pyc", ".venv/", "venv/"}},
+	{"Java (Maven)", "pom.xml", []string{"target/"}},
+	{"Java (Gradle)", "build.gradle", []string{"build/", ".gradle/"}},
+	{"Kotlin (Gradle)", "build.gradle.kts", []string{"build/", ".gradle/"}},
+	{"Ruby", "Gemfile", []string{"vendor/bundle/", ".bundle/"}},
+	{"PHP", "composer.json", []string{"vendor/"}},
+	{".NET", "*.csproj", []string{"bin/", "obj/"}},
+	{"C/C++ (CMake)", "CMakeLists.txt", []string{"build/", "cmake-build-*/"}},
+	{"Make", "Makefile", nil},
+	{"Docker", "Dockerfile", nil},
+}
+
+// commonIgnores apply to every project.
+var commonIgnores = []string{".git/", ".DS_Store", "*.log", ".env", ".env.*"}
+
+// Detect returns the ecosystems whose marker files exist in dir.
+func Detect(dir string) []Ecosystem {
+	var found []Ecosystem
+	for _, e := range ecosystems {
+		matches, _ := filepath.Glob(filepath.Join(dir, e.Marker))
+		if len(matches) > 0 {
+			e.Marker = filepath.Base(matches[0])
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// IgnoreFile renders a .geminiignore from the detected ecosystems and any
+// extra patterns, without duplicates.
+func IgnoreFile(found []Ecosystem, extra []string) string {
+	seen := map[string]bool{}
+	var b strings.Builder
+	b.WriteString("# Files and directories g and Gemini CLI should not read.\n")
+	b.WriteString("# Uses .gitignore syntax.\n")
+	section := func(title string, patterns []string) {
+		var fresh []string
+		for _, p := range patterns {
+			p = strings.TrimSpace(p)
+			if p == "" || strings.HasPrefix(p, "#") || seen[p] {
+				continue
+			}
+			seen[p] = true
+			fresh = append(fresh, p)
+		}
+		if len(fresh) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n# %s\n%s\n", title, strings.Join(fresh, "\n"))
+	}
+	section("Common", commonIgnores)
+	for _, e := range found {
+		section(e.Name, e.Ignores)
+	}
+	section("Project specific", extra)
+	return b.String()
+}
+
+// Prompt builds the user message for the analysis run.
+func Prompt(dir string, found []Ecosystem) string {
+	var b strings.Builder
+	b.WriteString("Analyze this repository and write a GEMINI.md that gives an AI coding agent the context it needs to work here.\n\n")
+	if len(found) > 0 {
+		b.WriteString("Detected build files:\n")
+		for _, e := range found {
+			fmt.Fprintf(&b, "- %s (%s)\n", e.Marker, e.Name)
+		}
+		b.WriteString("\n")
+	}
+	if entries := topLevel(dir); len(entries) > 0 {
+		b.WriteString("Top-level entries: " + strings.Join(entries, ", ") + "\n\n")
+	}
+	b.WriteString(`Use the read-only tools to inspect the build files, README, CI configuration and a few representative source and test files. Keep the investigation short. Then call write_project_context once.
+
+GEMINI.md should be concise Markdown (roughly 40-120 lines) with these sections:
+- Project overview: what it is, main languages and frameworks
+- Building and running: exact commands
+- Testing: exact commands, including how to run a single test
+- Project structure: the important directories and what lives there
+- Conventions: code style, error handling, naming, test layout and anything else a contributor must follow
+
+Only state facts you verified in the repository; do not invent commands.
+`)
+	return b.String()
+}
+
+func topLevel(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if name == ".git" {
+			continue
+		}
+		if e.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 60 {
+		names = append(names[:60], "...")
+	}
+	return names
+}
+
+// Context is what the model produced.
+type Context struct {
+	GeminiMD       string   `json:"gemini_md"`
+	IgnorePatterns []string `json:"ignore_patterns"`
+}
+
+// ContextTool is the tool the model calls to submit the generated files.
+type ContextTool struct {
+	mu       sync.Mutex
+	result   Context
+	reported bool
+}
+
+// Result returns the submitted context and whether the model submitted one.
+func (t *ContextTool) Result() (Context, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.result, t.reported
+}
+
+func (t *ContextTool) Name() string { return "write_project_context" }
+
+func (t *ContextTool) Declaration() api.FunctionDecl {
+	params, _ := json.Marshal(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"gemini_md": map[string]interface{}{
+				"type":        "string",
+				"description": "Full Markdown content of GEMINI.md.",
+			},
+			"ignore_patterns": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Extra .gitignore-style patterns for generated, vendored or large files specific to this project (standard build directories are added automatically).",
+			},
+		},
+		"required": []string{"gemini_md"},
+	})
+	return api.FunctionDecl{
+		Name:        "write_project_context",
+		Description: "Submit the generated GEMINI.md and .geminiignore patterns. Call exactly once when the analysis is done.",
+		Parameters:  params,
+	}
+}
+
+func (t *ContextTool) Execute(ctx context.Context, args map[string]interface{}) (*tools.ToolResult, error) {
+	md, _ := args["gemini_md"].(string)
+	if strings.TrimSpace(md) == "" {
+		return &tools.ToolResult{Content: map[string]interface{}{"error": "gemini_md is required"}, IsError: true}, nil
+	}
+	var patterns []string
+	if list, ok := args["ignore_patterns"].([]interface{}); ok {
+		for _, p := range list {
+			if s, ok := p.(string); ok {
+				patterns = append(patterns, s)
+			}
+		}
+	}
+
+	t.mu.Lock()
+	t.result = Context{GeminiMD: strings.TrimSpace(md) + "\n", IgnorePatterns: patterns}
+	t.reported = true
+	t.mu.Unlock()
+
+	return &tools.ToolResult{Content: map[string]interface{}{
+		"message": "Project context recorded. You are done; do not call any more tools.",
+	}}, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package bootstrap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectAndIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "package.json", "App.csproj"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found := Detect(dir)
+	var names []string
+	for _, e := range found {
+		names = append(names, e.Marker)
+	}
+	if got := strings.Join(names, ","); got != "go.mod,package.json,App.csproj" {
+		t.Fatalf("Detect = %s", got)
+	}
+
+	ignore := IgnoreFile(found, []string{"testdata/golden/", "node_modules/"})
+	for _, want := range []string{"vendor/", "node_modules/", "obj/", "testdata/golden/", ".env"} {
+		if !strings.Contains(ignore, want+"\n") {
+			t.Errorf("ignore file missing %q:\n%s", want, ignore)
+		}
+	}
+	if strings.Count(ignore, "node_modules/") != 1 {
+		t.Errorf("duplicate pattern in ignore file:\n%s", ignore)
+	}
+}
@@ -7,8 +7,10 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 const (
@@ -22,11 +24,56 @@ type Config struct {
 	MCPServers map[string]MCPServerConfig `json:"mcpServers"`
 	General    GeneralConfig              `json:"general"`
 	Output     OutputConfig               `json:"output"`
+	Tools      ToolsConfig                `json:"tools"`
+	Network    NetworkConfig              `json:"network"`
+	Sessions   SessionsConfig             `json:"sessions"`
+	History    HistoryConfig              `json:"history"`
+	Models     map[string]ModelConfig     `json:"models,omitempty"`
+	Context    ContextConfig              `json:"context"`
+	Verify     VerifyConfig               `json:"verify"`
+
+	// UntrustedMCPServers names the servers in the project settings that
+	// were not loaded because the folder is not trusted (see FolderTrusted).
+	UntrustedMCPServers []string `json:"-"`
 }
 
 // SecurityConfig holds security-related settings
 type SecurityConfig struct {
-	Auth AuthConfig `json:"auth"`
+	Auth      AuthConfig      `json:"auth"`
+	Redaction RedactionConfig `json:"redaction"`
+	Audit     AuditConfig     `json:"audit"`
+	DLP       DLPConfig       `json:"dlp"`
+}
+
+// DLPConfig filters everything sent to the model API: prompts, attached
+// and read files, tool output and the system prompt. A request with text
+// matching a deny pattern is not sent; text matching a mask pattern is
+// replaced. When command is set, each text is also piped through it: its
+// output replaces the text, and exit status 2 (or any failure) blocks the
+// request with stderr as the reason.
+type DLPConfig struct {
+	Deny    []string `json:"deny,omitempty"`
+	Mask    []string `json:"mask,omitempty"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Timeout int      `json:"timeout,omitempty"` // milliseconds per text, default 10000
+}
+
+// AuditConfig controls the tool execution audit log. It is on unless
+// enabled is false; path defaults to ~/.gemini/g_audit.jsonl. The file is
+// rotated when it grows past maxSize bytes (default 10 MB; -1 never).
+type AuditConfig struct {
+	Enabled *bool  `json:"enabled,omitempty"`
+	Path    string `json:"path,omitempty"`
+	MaxSize int64  `json:"maxSize,omitempty"`
+}
+
+// RedactionConfig controls masking of secrets in tool output. Redaction is
+// on unless enabled is false; patterns are regular expressions added to the
+// built-in ones (a group named "secret" limits what is replaced).
+type RedactionConfig struct {
+	Enabled  *bool    `json:"enabled,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
 }
 
 // AuthConfig holds authentication settings
@@ -41,17 +88,150 @@ type MCPServerConfig struct {
 	Args    []string          `json:"args,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
 	CWD     string            `json:"cwd,omitempty"`
+	EnvFile string            `json:"envFile,omitempty"` // .env file for ${env:NAME} references and a stdio server's environment
 
 	// HTTP/SSE transport
 	URL     string            `json:"url,omitempty"`
-	Type    string            `json:"type,omitempty"` // "sse" | "http"
+	HTTPURL string            `json:"httpUrl,omitempty"` // Streamable HTTP (gemini-cli compatible)
+	Type    string            `json:"type,omitempty"`    // "sse" | "http"
 	Headers map[string]string `json:"headers,omitempty"`
+	OAuth   *MCPOAuthConfig   `json:"oauth,omitempty"`
 
 	// Common
 	Timeout      int      `json:"timeout,omitempty"`
 	Trust        bool     `json:"trust,omitempty"`
+	Lazy         bool     `json:"lazy,omitempty"` // defer launch until first tool call
 	IncludeTools []string `json:"includeTools,omitempty"`
 	ExcludeTools []string `json:"excludeTools,omitempty"`
+	Keywords     []string `json:"keywords,omitempty"` // words in a prompt that need the server's tools, with tools.selectMcpTools
+}
+
+// MCPOAuthConfig holds OAuth settings for a remote MCP server. All fields are
+// optional: endpoints are discovered from the server and clients are
+// registered dynamically when not provided.
+type MCPOAuthConfig struct {
+	Enabled          *bool    `json:"enabled,omitempty"` // true: open the browser when the server answers 401; false: send no tokens
+	ClientID         string   `json:"clientId,omitempty"`
+	ClientSecret     string   `json:"clientSecret,omitempty"`
+	AuthorizationURL string   `json:"authorizationUrl,omitempty"`
+	TokenURL         string   `json:"tokenUrl,omitempty"`
+	Scopes           []string `json:"scopes,omitempty"`
+	RedirectURI      string   `json:"redirectUri,omitempty"`
+}
+
+// ToolsConfig holds tool execution settings
+type ToolsConfig struct {
+	// Allowed lists tools (MCP tools as "server__tool") that run without
+	// asking for confirmation.
+	Allowed []string `json:"allowed,omitempty"`
+
+	// Shell is run_shell_command's shell on Windows: powershell (default),
+	// pwsh, cmd or git-bash.
+	Shell string `json:"shell,omitempty"`
+
+	Limits ToolLimitsConfig `json:"limits"`
+
+	// Timeouts sets how long a tool may run, in milliseconds by tool name
+	// (run_shell_command, web_fetch, extension tools). A tool that hits it
+	// returns the output it produced until then.
+	Timeouts map[string]int `json:"timeouts,omitempty"`
+
+	// Declarations is how tools are described to the model: "full"
+	// (default) or "compact", which keeps the first sentence of each
+	// description and drops schema fields that only document.
+	Declarations string `json:"declarations,omitempty"`
+
+	// SelectMCPTools sends the declarations of an MCP server's tools only
+	// with prompts that plausibly need them (see mcp.Router), and with
+	// every prompt after one of them was called.
+	SelectMCPTools bool `json:"selectMcpTools,omitempty"`
+}
+
+// ToolLimitsConfig caps tool output sent to the model; zero keeps the
+// default. Shell output beyond its limit keeps its head and tail, and the
+// results of one turn together stay within turnOutputTokens.
+type ToolLimitsConfig struct {
+	ShellOutputBytes int `json:"shellOutputBytes,omitempty"` // per stream, default 102400
+	ReadFileLines    int `json:"readFileLines,omitempty"`    // default 2000
+	GrepMatches      int `json:"grepMatches,omitempty"`      // default 100
+	WebFetchBytes    int `json:"webFetchBytes,omitempty"`    // default 524288
+	TurnOutputTokens int `json:"turnOutputTokens,omitempty"` // default 100000
+}
+
+// NetworkConfig restricts network access by tools. With allowedHosts set,
+// web_fetch and remote MCP servers may only reach those hosts (and their
+// subdomains) and google_web_search is disabled; disabled removes all
+// network tools.
+type NetworkConfig struct {
+	Disabled     bool     `json:"disabled,omitempty"`
+	AllowedHosts []string `json:"allowedHosts,omitempty"`
+}
+
+// SessionsConfig controls the conversation store. Sessions are saved
+// unless enabled is false; sessions older than maxAgeDays (default 30) or
+// beyond maxSizeMB in total (default 200) are removed automatically.
+type SessionsConfig struct {
+	Enabled    *bool `json:"enabled,omitempty"`
+	MaxAgeDays int   `json:"maxAgeDays,omitempty"`
+	MaxSizeMB  int   `json:"maxSizeMB,omitempty"`
+}
+
+// HistoryConfig bounds the conversation sent to the model. Beyond
+// maxTokens (estimated; default 60% of the model's context window) old
+// tool results and intermediate replies are shortened. compressModel is the model the REPL's /compress
+// summarizes with (default: a small model of the session's provider).
+type HistoryConfig struct {
+	MaxTokens     int    `json:"maxTokens,omitempty"`
+	CompressModel string `json:"compressModel,omitempty"`
+}
+
+// ContextConfig chooses the memory files added to the system prompt.
+// fileName lists the names looked up in the project, its .gemini directory
+// and ~/.gemini (default GEMINI.md and AGENTS.md); memoryFile is where
+// save_memory writes (default ~/.gemini/ and the first name), with ~ and
+// $VARs such as $XDG_CONFIG_HOME expanded. save_memory refuses to grow
+// memoryFile beyond memoryMaxBytes (default 16384).
+type ContextConfig struct {
+	FileName       StringList `json:"fileName,omitempty"`
+	MemoryFile     string     `json:"memoryFile,omitempty"`
+	MemoryMaxBytes int        `json:"memoryMaxBytes,omitempty"`
+}
+
+// VerifyConfig is the check --verify runs once the agent is done, such as
+// "go test ./...". While it fails, its output goes back to the agent for up
+// to retries more turns (default 3). timeout is in seconds per run (default
+// 600).
+type VerifyConfig struct {
+	Command string `json:"command,omitempty"`
+	Retries *int   `json:"retries,omitempty"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+// StringList is a list of strings that may also be written as a single
+// string in settings.json.
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = StringList{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected a string or a list of strings: %w", err)
+	}
+	*l = list
+	return nil
+}
+
+// ModelConfig overrides the built-in limits of the models whose name
+// starts with its key. Zero fields keep the built-in value; thinking is
+// "none", "budget" or "level".
+type ModelConfig struct {
+	ContextWindow   int    `json:"contextWindow,omitempty"`
+	MaxOutputTokens int    `json:"maxOutputTokens,omitempty"`
+	Thinking        string `json:"thinking,omitempty"`
 }
 
 // GeneralConfig holds general settings
@@ -106,18 +286,151 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
-	// Load project settings (optional, overrides global)
+	// Load project settings (optional, overrides global; in the home
+	// directory they are the global settings)
 	cwd, err := os.Getwd()
-	if err == nil {
+	if err == nil && filepath.Join(cwd, geminiDir) != geminiPath {
 		projectPath := filepath.Join(cwd, geminiDir, settingsFile)
+		servers := cfg.MCPServers
+		cfg.MCPServers = nil
 		if err := loadFile(projectPath, cfg); err != nil && !os.IsNotExist(err) {
 			return nil, err
 		}
+		projectServers := cfg.MCPServers
+		cfg.MCPServers = servers
+		if err := addProjectMCPServers(cfg, cwd, projectServers); err != nil {
+			return nil, err
+		}
 	}
 
 	return cfg, nil
 }
 
+// addProjectMCPServers adds the MCP servers from the settings of the
+// project at dir, which a cloned repository controls, only when the folder
+// is trusted. Otherwise their names are kept in cfg.UntrustedMCPServers.
+func addProjectMCPServers(cfg *Config, dir string, servers map[string]MCPServerConfig) error {
+	if len(servers) == 0 {
+		return nil
+	}
+	trusted, err := FolderTrusted(dir)
+	if err != nil {
+		return err
+	}
+	if cfg.MCPServers == nil {
+		cfg.MCPServers = make(map[string]MCPServerConfig)
+	}
+	for name, server := range servers {
+		if trusted {
+			cfg.MCPServers[name] = server
+		} else {
+			cfg.UntrustedMCPServers = append(cfg.UntrustedMCPServers, name)
+		}
+	}
+	sort.Strings(cfg.UntrustedMCPServers)
+	return nil
+}
+
+// SettingsPath returns the settings.json path for a scope: "user"
+// (~/.gemini/settings.json) or "project" (./.gemini/settings.json).
+func SettingsPath(scope string) (string, error) {
+	switch scope {
+	case "", "user":
+		geminiPath, err := GeminiDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(geminiPath, settingsFile), nil
+	case "project":
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(cwd, geminiDir, settingsFile), nil
+	default:
+		return "", fmt.Errorf("unknown settings scope %q (use user or project)", scope)
+	}
+}
+
+// SetMCPServer adds or replaces an MCP server in the settings file at path,
+// preserving all other settings.
+func SetMCPServer(path, name string, server MCPServerConfig) error {
+	return updateFile(path, func(raw map[string]interface{}) error {
+		servers, _ := raw["mcpServers"].(map[string]interface{})
+		if servers == nil {
+			servers = map[string]interface{}{}
+		}
+		data, err := json.Marshal(server)
+		if err != nil {
+			return err
+		}
+		var entry interface{}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+		servers[name] = entry
+		raw["mcpServers"] = servers
+		return nil
+	})
+}
+
+// RemoveMCPServer deletes an MCP server from the settings file at path.
+func RemoveMCPServer(path, name string) error {
+	return updateFile(path, func(raw map[string]interface{}) error {
+		servers, _ := raw["mcpServers"].(map[string]interface{})
+		if _, ok := servers[name]; !ok {
+			return fmt.Errorf("MCP server %q not found in %s", name, path)
+		}
+		delete(servers, name)
+		return nil
+	})
+}
+
+// AllowTool adds a tool to tools.allowed in the settings file at path.
+func AllowTool(path, name string) error {
+	return updateFile(path, func(raw map[string]interface{}) error {
+		toolsCfg, _ := raw["tools"].(map[string]interface{})
+		if toolsCfg == nil {
+			toolsCfg = map[string]interface{}{}
+		}
+		allowed, _ := toolsCfg["allowed"].([]interface{})
+		for _, v := range allowed {
+			if v == name {
+				return nil
+			}
+		}
+		toolsCfg["allowed"] = append(allowed, name)
+		raw["tools"] = toolsCfg
+		return nil
+	})
+}
+
+// updateFile applies fn to the raw JSON settings at path and writes the
+// result back. A missing file is treated as empty settings.
+func updateFile(path string, fn func(raw map[string]interface{}) error) error {
+	raw := map[string]interface{}{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if err := fn(raw); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
+
 func loadFile(path string, cfg *Config) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -128,10 +441,50 @@ func loadFile(path string, cfg *Config) error {
 
 // CachedState represents cached state for geminimini
 type CachedState struct {
+	// Cached before projects were kept per account
+	ProjectID string `json:"projectId,omitempty"`
+	UserTier  string `json:"userTier,omitempty"`
+
+	Accounts map[string]AccountState `json:"accounts,omitempty"`
+}
+
+// AccountState is the Code Assist project cached for one account.
+type AccountState struct {
 	ProjectID string `json:"projectId,omitempty"`
 	UserTier  string `json:"userTier,omitempty"`
 }
 
+// Account returns the state cached for account. The project cached before
+// projects were kept per account is claimed by the first account to ask.
+func (s *CachedState) Account(account string) AccountState {
+	if st, ok := s.Accounts[account]; ok {
+		return st
+	}
+	if s.ProjectID != "" {
+		st := AccountState{ProjectID: s.ProjectID, UserTier: s.UserTier}
+		s.SetAccount(account, st)
+		s.ProjectID, s.UserTier = "", ""
+		return st
+	}
+	return AccountState{}
+}
+
+// SetAccount records the state for account.
+func (s *CachedState) SetAccount(account string, st AccountState) {
+	if s.Accounts == nil {
+		s.Accounts = make(map[string]AccountState)
+	}
+	s.Accounts[account] = st
+}
+
+// ResetAccount forgets the state cached for account, reporting whether
+// there was any.
+func (s *CachedState) ResetAccount(account string) bool {
+	st := s.Account(account)
+	delete(s.Accounts, account)
+	return st != AccountState{}
+}
+
 // LoadCachedState loads the cached state from gmn_state.json
 func LoadCachedState() (*CachedState, error) {
 	geminiPath, err := GeminiDir()
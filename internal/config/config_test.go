@@ -0,0 +1,68 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCachedStatePerAccount(t *testing.T) {
+	// A project cached before per-account caching goes to the first account
+	s := &CachedState{ProjectID: "old-project", UserTier: "free-tier"}
+	if got := s.Account("a@example.com").ProjectID; got != "old-project" {
+		t.Fatalf("first account project = %q, want the legacy project", got)
+	}
+	if got := s.Account("b@example.com").ProjectID; got != "" {
+		t.Errorf("second account project = %q, want none", got)
+	}
+
+	s.SetAccount("b@example.com", AccountState{ProjectID: "b-project"})
+	if got := s.Account("b@example.com").ProjectID; got != "b-project" {
+		t.Errorf("second account project = %q, want b-project", got)
+	}
+	if !s.ResetAccount("a@example.com") || s.ResetAccount("a@example.com") {
+		t.Error("ResetAccount should report a cached project only once")
+	}
+	if got := s.Account("a@example.com").ProjectID; got != "" {
+		t.Errorf("reset account project = %q, want none", got)
+	}
+}
+
+func TestProjectMCPServersNeedTrust(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	os.MkdirAll(filepath.Join(home, ".gemini"), 0o755)
+	os.WriteFile(filepath.Join(home, ".gemini", "settings.json"), []byte(`{"mcpServers":{"global":{"command":"a"}}}`), 0o644)
+
+	project := t.TempDir()
+	os.MkdirAll(filepath.Join(project, ".gemini"), 0o755)
+	os.WriteFile(filepath.Join(project, ".gemini", "settings.json"), []byte(`{"mcpServers":{"repo":{"command":"b"}}}`), 0o644)
+	wd, _ := os.Getwd()
+	os.Chdir(project)
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.MCPServers["repo"]; ok || len(cfg.UntrustedMCPServers) != 1 {
+		t.Errorf("untrusted folder: servers %v, untrusted %v", cfg.MCPServers, cfg.UntrustedMCPServers)
+	}
+	if _, ok := cfg.MCPServers["global"]; !ok {
+		t.Error("global server missing")
+	}
+
+	if err := SetFolderTrust(filepath.Dir(project), TrustFolder); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ = Load(); cfg.MCPServers["repo"].Command != "b" || len(cfg.UntrustedMCPServers) != 0 {
+		t.Errorf("trusted parent: servers %v, untrusted %v", cfg.MCPServers, cfg.UntrustedMCPServers)
+	}
+
+	SetFolderTrust(project, DoNotTrust)
+	if trusted, _ := FolderTrusted(project); trusted {
+		t.Error("DO_NOT_TRUST inside a trusted folder should win")
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const trustedFoldersFile = "trustedFolders.json"
+
+// Trust levels in ~/.gemini/trustedFolders.json, as the Gemini CLI writes
+// them.
+const (
+	TrustFolder = "TRUST_FOLDER" // the folder and everything under it
+	TrustParent = "TRUST_PARENT" // the folder's parent and everything under it
+	DoNotTrust  = "DO_NOT_TRUST"
+)
+
+// FolderTrusted reports whether dir is trusted to run what its
+// .gemini/settings.json configures, such as MCP servers. The rule for the
+// closest folder containing dir decides; folders without a rule are not
+// trusted.
+func FolderTrusted(dir string) (bool, error) {
+	rules, err := loadTrustedFolders()
+	if err != nil {
+		return false, err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	trusted, best := false, -1
+	for path, level := range rules {
+		root := filepath.Clean(path)
+		if level == TrustParent {
+			root = filepath.Dir(root)
+		}
+		if !isUnder(dir, root) || len(root) < best {
+			continue
+		}
+		// For the same folder, DO_NOT_TRUST wins
+		if len(root) > best || level == DoNotTrust {
+			trusted = level != DoNotTrust
+		}
+		best = len(root)
+	}
+	return trusted, nil
+}
+
+// SetFolderTrust records level for dir in ~/.gemini/trustedFolders.json;
+// an empty level removes dir's rule.
+func SetFolderTrust(dir, level string) error {
+	switch level {
+	case "", TrustFolder, TrustParent, DoNotTrust:
+	default:
+		return fmt.Errorf("unknown trust level %q", level)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	path, err := trustedFoldersPath()
+	if err != nil {
+		return err
+	}
+	return updateFile(path, func(raw map[string]interface{}) error {
+		if level == "" {
+			delete(raw, dir)
+		} else {
+			raw[dir] = level
+		}
+		return nil
+	})
+}
+
+// TrustedFolders returns the folders with a trust rule, sorted, and their
+// levels.
+func TrustedFolders() ([]string, map[string]string, error) {
+	rules, err := loadTrustedFolders()
+	if err != nil {
+		return nil, nil, err
+	}
+	dirs := make([]string, 0, len(rules))
+	for dir := range rules {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, rules, nil
+}
+
+func trustedFoldersPath() (string, error) {
+	geminiPath, err := GeminiDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(geminiPath, trustedFoldersFile), nil
+}
+
+func loadTrustedFolders() (map[string]string, error) {
+	path, err := trustedFoldersPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rules := map[string]string{}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// isUnder reports whether path is root or inside it.
+func isUnder(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
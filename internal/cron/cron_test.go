@@ -0,0 +1,105 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "deps.md"), []byte("Update dependencies."), 0644)
+	path := filepath.Join(dir, "nightly.yaml")
+	os.WriteFile(path, []byte(`
+name: nightly
+timeout: 10m
+tasks:
+  - name: deps
+    promptFile: deps.md
+    tools: all
+    excludeTools: [web_fetch]
+    output:
+      file: reports/{{task}}-{{date}}.md
+  - prompt: Summarize yesterday's commits.
+    model: gemini-2.5-pro
+`), 0644)
+
+	tf, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tf.Lock != path+".lock" || tf.Model != defaultModel {
+		t.Errorf("defaults not applied: %+v", tf)
+	}
+	deps, report := tf.Tasks[0], tf.Tasks[1]
+	if deps.Prompt != "Update dependencies." || deps.Timeout != Duration(10*time.Minute) || deps.Output.File != filepath.Join(dir, "reports/{{task}}-{{date}}.md") {
+		t.Errorf("deps = %+v", deps)
+	}
+	if report.Name != "task-2" || report.Tools != ToolsReadOnly || report.Model != "gemini-2.5-pro" || report.WorkDir != dir {
+		t.Errorf("report = %+v", report)
+	}
+	if _, err := tf.Select([]string{"missing"}); err == nil {
+		t.Error("expected unknown task error")
+	}
+
+	os.WriteFile(path, []byte("tasks:\n  - prompt: x\n    tool: all\n"), 0644)
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.lock")
+	l, err := AcquireLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AcquireLock(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("second AcquireLock = %v, want ErrLocked", err)
+	}
+	l.Release()
+	l, err = AcquireLock(path)
+	if err != nil {
+		t.Fatalf("lock not released: %v", err)
+	}
+	l.Release()
+}
+
+func TestDeliver(t *testing.T) {
+	var got Result
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+	t.Setenv("HOOK_TOKEN", "s3cret")
+
+	dir := t.TempDir()
+	started := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	r := NewResult("nightly", "deps", started, "All up to date.\n", nil)
+	err := Deliver(context.Background(), Output{
+		File:    filepath.Join(dir, "{{task}}-{{date}}.md"),
+		Webhook: srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer $HOOK_TOKEN"},
+	}, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "deps-2026-03-01.md"))
+	if err != nil || !strings.Contains(string(data), "All up to date.") {
+		t.Errorf("report file = %q, %v", data, err)
+	}
+	if got.Task != "deps" || got.Status != "ok" || auth != "Bearer s3cret" {
+		t.Errorf("webhook got %+v auth %q", got, auth)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Result is the outcome of one task run.
+type Result struct {
+	Taskfile string    `json:"taskfile"`
+	Task     string    `json:"task"`
+	Status   string    `json:"status"` // "ok" or "error"
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Output   string    `json:"output"`
+	Error    string    `json:"error,omitempty"`
+	// Text duplicates a short summary for chat webhooks (Slack, Google Chat)
+	Text string `json:"text"`
+}
+
+// NewResult builds the result of a finished run.
+func NewResult(taskfile, task string, started time.Time, output string, err error) Result {
+	r := Result{
+		Taskfile: taskfile,
+		Task:     task,
+		Status:   "ok",
+		Started:  started,
+		Finished: time.Now(),
+		Output:   strings.TrimSpace(output),
+	}
+	if err != nil {
+		r.Status = "error"
+		r.Error = err.Error()
+	}
+	r.Text = fmt.Sprintf("g cron %s/%s: %s (%s)", taskfile, task, r.Status, r.Finished.Sub(started).Round(time.Second))
+	if r.Error != "" {
+		r.Text += "\n" + r.Error
+	}
+	return r
+}
+
+// Deliver writes the result to the task's file and webhook.
+func Deliver(ctx context.Context, out Output, r Result) error {
+	var errs []string
+	if out.File != "" {
+		if err := writeFile(expandPath(out.File, r), r); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if out.Webhook != "" {
+		if err := postWebhook(ctx, out, r); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("delivery failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// expandPath fills in {{task}}, {{date}} and {{time}}.
+func expandPath(path string, r Result) string {
+	return strings.NewReplacer(
+		"{{task}}", r.Task,
+		"{{date}}", r.Started.Format("2006-01-02"),
+		"{{time}}", r.Started.Format("150405"),
+	).Replace(path)
+}
+
+func writeFile(path string, r Result) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	var data []byte
+	if strings.HasSuffix(path, ".json") {
+		var err error
+		if data, err = json.MarshalIndent(r, "", "  "); err != nil {
+			return err
+		}
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, "<!-- g cron %s/%s %s at %s -->\n\n", r.Taskfile, r.Task, r.Status, r.Started.Format(time.RFC3339))
+		if r.Error != "" {
+			fmt.Fprintf(&b, "**Error:** %s\n\n", r.Error)
+		}
+		b.WriteString(r.Output)
+		b.WriteString("\n")
+		data = []byte(b.String())
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func postWebhook(ctx context.Context, out Output, r Result) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", os.ExpandEnv(out.Webhook), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range out.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrLocked is returned when another run holds the lock.
+var ErrLocked = errors.New("another run holds the lock")
+
+// Lock prevents overlapping runs of the same task file.
+type Lock struct {
+	f *os.File
+}
+
+// AcquireLock takes the lock at path without waiting.
+func AcquireLock(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := tryLock(f); err != nil {
+		data, _ := os.ReadFile(path)
+		f.Close()
+		if pid := strings.TrimSpace(string(data)); pid != "" {
+			return nil, fmt.Errorf("%w (pid %s, %s)", ErrLocked, pid, path)
+		}
+		return nil, fmt.Errorf("%w (%s)", ErrLocked, path)
+	}
+	_ = f.Truncate(0)
+	_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return &Lock{f: f}, nil
+}
+
+// Release frees the lock.
+func (l *Lock) Release() {
+	_ = l.f.Truncate(0)
+	unlock(l.f)
+	l.f.Close()
+}
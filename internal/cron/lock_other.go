@@ -0,0 +1,20 @@
+//go:build !windows
+
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cron
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLock takes a non-blocking exclusive lock. The kernel releases it if
+// the process dies, so a crashed run never leaves a stale lock behind.
+func tryLock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+func unlock(f *os.File) {
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
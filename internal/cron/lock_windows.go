@@ -0,0 +1,12 @@
+//go:build windows
+
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cron
+
+import "os"
+
+// tryLock is a no-op on Windows; overlapping runs are not prevented there.
+func tryLock(f *os.File) error { return nil }
+
+func unlock(f *os.File) {}
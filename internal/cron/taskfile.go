@@ -0,0 +1,212 @@
+// Package cron runs task files headlessly: each task is a prompt with its
+// own tool policy whose result is delivered to a file or webhook.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package cron
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultModel    = "gemini-2.5-flash"
+	defaultTimeout  = 30 * time.Minute
+	defaultMaxTurns = 25
+)
+
+// Tool policies, as for g serve --tools.
+const (
+	ToolsNone     = "none"
+	ToolsReadOnly = "read-only"
+	ToolsAll      = "all"
+)
+
+// Duration is a time.Duration written as "30m" in YAML.
+type Duration time.Duration
+
+// UnmarshalYAML parses a Go duration string.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q", node.Line, s)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Taskfile is a set of tasks run together.
+type Taskfile struct {
+	Name     string   `yaml:"name"`
+	Model    string   `yaml:"model"`
+	Timeout  Duration `yaml:"timeout"`  // default per-task timeout
+	MaxTurns int      `yaml:"maxTurns"` // default per-task turn limit
+	Lock     string   `yaml:"lock"`     // lock file; defaults to <taskfile>.lock
+	WorkDir  string   `yaml:"workDir"`  // relative to the task file
+	Tasks    []Task   `yaml:"tasks"`
+
+	path string
+}
+
+// Task is one headless agent run.
+type Task struct {
+	Name         string   `yaml:"name"`
+	Prompt       string   `yaml:"prompt"`
+	PromptFile   string   `yaml:"promptFile"` // relative to the task file
+	Model        string   `yaml:"model"`
+	Tools        string   `yaml:"tools"` // none, read-only (default) or all
+	IncludeTools []string `yaml:"includeTools"`
+	ExcludeTools []string `yaml:"excludeTools"`
+	Yolo         bool     `yaml:"yolo"`
+	MaxTurns     int      `yaml:"maxTurns"`
+	Timeout      Duration `yaml:"timeout"`
+	WorkDir      string   `yaml:"workDir"`
+	Output       Output   `yaml:"output"`
+}
+
+// Output says where a task's result is delivered.
+type Output struct {
+	File    string            `yaml:"file"`    // supports {{task}}, {{date}} and {{time}}
+	Webhook string            `yaml:"webhook"` // $VAR references are expanded
+	Headers map[string]string `yaml:"headers"` // $VAR references are expanded
+}
+
+// Load reads and validates a task file, filling in defaults.
+func Load(path string) (*Taskfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tf Taskfile
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&tf); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	tf.path = abs
+	if err := tf.normalize(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &tf, nil
+}
+
+func (tf *Taskfile) normalize() error {
+	base := filepath.Dir(tf.path)
+	if tf.Model == "" {
+		tf.Model = defaultModel
+	}
+	if tf.Timeout == 0 {
+		tf.Timeout = Duration(defaultTimeout)
+	}
+	if tf.MaxTurns == 0 {
+		tf.MaxTurns = defaultMaxTurns
+	}
+	if tf.Lock == "" {
+		tf.Lock = tf.path + ".lock"
+	}
+	tf.Lock = resolve(base, tf.Lock)
+	tf.WorkDir = resolve(base, tf.WorkDir)
+	if len(tf.Tasks) == 0 {
+		return fmt.Errorf("no tasks defined")
+	}
+
+	seen := make(map[string]bool)
+	for i := range tf.Tasks {
+		t := &tf.Tasks[i]
+		if t.Name == "" {
+			t.Name = fmt.Sprintf("task-%d", i+1)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("duplicate task name %q", t.Name)
+		}
+		seen[t.Name] = true
+
+		if t.PromptFile != "" {
+			if t.Prompt != "" {
+				return fmt.Errorf("task %q: set prompt or promptFile, not both", t.Name)
+			}
+			data, err := os.ReadFile(resolve(base, t.PromptFile))
+			if err != nil {
+				return fmt.Errorf("task %q: %w", t.Name, err)
+			}
+			t.Prompt = string(data)
+		}
+		if strings.TrimSpace(t.Prompt) == "" {
+			return fmt.Errorf("task %q: prompt is required", t.Name)
+		}
+		switch t.Tools {
+		case "":
+			t.Tools = ToolsReadOnly
+		case ToolsNone, ToolsReadOnly, ToolsAll:
+		default:
+			return fmt.Errorf("task %q: unknown tools policy %q (use none, read-only or all)", t.Name, t.Tools)
+		}
+		if t.Model == "" {
+			t.Model = tf.Model
+		}
+		if t.MaxTurns == 0 {
+			t.MaxTurns = tf.MaxTurns
+		}
+		if t.Timeout == 0 {
+			t.Timeout = tf.Timeout
+		}
+		if t.WorkDir == "" {
+			t.WorkDir = tf.WorkDir
+		} else {
+			t.WorkDir = resolve(base, t.WorkDir)
+		}
+		if t.Output.File != "" {
+			t.Output.File = resolve(base, t.Output.File)
+		}
+	}
+	return nil
+}
+
+// Select returns the named tasks, or all tasks when names is empty.
+func (tf *Taskfile) Select(names []string) ([]Task, error) {
+	if len(names) == 0 {
+		return tf.Tasks, nil
+	}
+	var out []Task
+	for _, name := range names {
+		found := false
+		for _, t := range tf.Tasks {
+			if t.Name == name {
+				out = append(out, t)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("task %q not found", name)
+		}
+	}
+	return out, nil
+}
+
+func resolve(base, path string) string {
+	if path == "" {
+		return base
+	}
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
@@ -0,0 +1,93 @@
+// Package diskcache keeps small JSON caches in ~/.gemini whose entries are
+// valid while the files they were built from are unchanged. A cache that
+// is missing, unreadable or of another version is treated as empty, and
+// failures to write one only cost the next run the work it saves.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package diskcache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+// Stamp identifies a version of a file or directory.
+type Stamp struct {
+	ModTime int64 `json:"mtime"`
+	Size    int64 `json:"size"`
+}
+
+// StampOf returns the stamp of info.
+func StampOf(info os.FileInfo) Stamp {
+	return Stamp{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+}
+
+// Stat returns the stamp of the file at path, following symlinks, and
+// false if it cannot be read.
+func Stat(path string) (Stamp, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Stamp{}, false
+	}
+	return StampOf(info), true
+}
+
+// file is what a cache file holds.
+type file struct {
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Load reads the cache called name into v if it was saved with version,
+// and returns the path to save it to, or "" when there is no ~/.gemini.
+func Load(name string, version int, v interface{}) string {
+	geminiDir, err := config.GeminiDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(geminiDir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path
+	}
+	var f file
+	if json.Unmarshal(data, &f) == nil && f.Version == version && len(f.Data) > 0 {
+		json.Unmarshal(f.Data, v)
+	}
+	return path
+}
+
+// Save writes v as the cache at path with version, replacing the file at
+// once so concurrent runs never read half of it or a mix of two saves.
+func Save(path string, version int, v interface{}) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	data, err = json.Marshal(file{Version: version, Data: data})
+	if err != nil {
+		return
+	}
+	// Each run writes its own temporary file, so concurrent saves never
+	// mix; the last rename wins
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
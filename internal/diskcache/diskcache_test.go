@@ -0,0 +1,45 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package diskcache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSaveConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.json")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			Save(path, 1, map[string]string{"writer": strings.Repeat(string(rune('a'+i)), 10000)})
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f struct {
+		Version int
+		Data    map[string]string
+	}
+	if err := json.Unmarshal(data, &f); err != nil || f.Version != 1 || len(f.Data["writer"]) != 10000 {
+		t.Fatalf("saved cache is not one writer's: %v", err)
+	}
+	if w := f.Data["writer"]; strings.Count(w, w[:1]) != len(w) {
+		t.Error("saved cache mixes writers")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("left behind %d files", len(entries)-1)
+	}
+}
@@ -0,0 +1,299 @@
+// Package dlp applies an organization's data-loss-prevention rules to
+// everything sent to the model API.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package dlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/config"
+)
+
+// Placeholder replaces text matching a mask pattern.
+const Placeholder = "[FILTERED]"
+
+// defaultTimeout bounds one run of the filter command.
+const defaultTimeout = 10 * time.Second
+
+// BlockedError is returned for a request that the rules do not allow.
+type BlockedError struct {
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return "request blocked by data-loss-prevention rules: " + e.Reason
+}
+
+// Filter checks and rewrites outbound text. Results are cached by content,
+// since every request repeats the conversation so far.
+type Filter struct {
+	deny    []*regexp.Regexp
+	mask    []*regexp.Regexp
+	command string
+	args    []string
+	timeout time.Duration
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]result
+}
+
+type result struct {
+	text string
+	err  error
+}
+
+// New creates a filter from the settings, or returns nil when no rules are
+// configured.
+func New(cfg config.DLPConfig) (*Filter, error) {
+	if len(cfg.Deny) == 0 && len(cfg.Mask) == 0 && cfg.Command == "" {
+		return nil, nil
+	}
+	f := &Filter{
+		command: cfg.Command,
+		args:    cfg.Args,
+		timeout: defaultTimeout,
+		cache:   map[[sha256.Size]byte]result{},
+	}
+	if cfg.Timeout > 0 {
+		f.timeout = time.Duration(cfg.Timeout) * time.Millisecond
+	}
+	var err error
+	if f.deny, err = compile(cfg.Deny); err != nil {
+		return nil, err
+	}
+	if f.mask, err = compile(cfg.Mask); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func compile(patterns []string) ([]*regexp.Regexp, error) {
+	var out []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dlp pattern %q: %w", p, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// Text returns s as it may be sent, or a *BlockedError. A nil Filter
+// returns s.
+func (f *Filter) Text(ctx context.Context, s string) (string, error) {
+	if f == nil || strings.TrimSpace(s) == "" {
+		return s, nil
+	}
+	key := sha256.Sum256([]byte(s))
+	f.mu.Lock()
+	r, ok := f.cache[key]
+	f.mu.Unlock()
+	if ok {
+		return r.text, r.err
+	}
+
+	r.text, r.err = f.apply(ctx, s)
+	if ctx.Err() == nil {
+		f.mu.Lock()
+		f.cache[key] = r
+		f.mu.Unlock()
+	}
+	return r.text, r.err
+}
+
+func (f *Filter) apply(ctx context.Context, s string) (string, error) {
+	for _, re := range f.deny {
+		if re.MatchString(s) {
+			return "", &BlockedError{Reason: fmt.Sprintf("content matches deny pattern %q", re.String())}
+		}
+	}
+	for _, re := range f.mask {
+		s = re.ReplaceAllLiteralString(s, Placeholder)
+	}
+	if f.command == "" {
+		return s, nil
+	}
+	return f.run(ctx, s)
+}
+
+// run pipes s through the filter command.
+func (f *Filter) run(ctx context.Context, s string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, f.command, f.args...)
+	cmd.Stdin = strings.NewReader(s)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err == nil {
+		return stdout.String(), nil
+	}
+	reason := strings.TrimSpace(stderr.String())
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 && reason != "" {
+		return "", &BlockedError{Reason: reason}
+	}
+	if reason == "" {
+		reason = err.Error()
+	}
+	// The rules cannot be checked, so nothing is sent
+	return "", &BlockedError{Reason: fmt.Sprintf("filter command %s failed: %s", f.command, reason)}
+}
+
+// Request returns a copy of req with every text filtered. The conversation
+// in req is not modified, so the local history keeps the original text.
+// Inline binary data (images, PDFs) is passed through.
+func (f *Filter) Request(ctx context.Context, req *api.GenerateRequest) (*api.GenerateRequest, error) {
+	if f == nil {
+		return req, nil
+	}
+	out := *req
+	out.Request.Contents = make([]api.Content, len(req.Request.Contents))
+	for i, c := range req.Request.Contents {
+		fc, err := f.content(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		out.Request.Contents[i] = fc
+	}
+	if si := req.Request.SystemInstruction; si != nil {
+		fc, err := f.content(ctx, *si)
+		if err != nil {
+			return nil, err
+		}
+		out.Request.SystemInstruction = &fc
+	}
+	return &out, nil
+}
+
+func (f *Filter) content(ctx context.Context, c api.Content) (api.Content, error) {
+	parts := make([]api.Part, len(c.Parts))
+	for i, p := range c.Parts {
+		var err error
+		if p.Text, err = f.Text(ctx, p.Text); err != nil {
+			return c, err
+		}
+		if fc := p.FunctionCall; fc != nil {
+			args, err := f.value(ctx, fc.Args)
+			if err != nil {
+				return c, err
+			}
+			p.FunctionCall = &api.FunctionCall{Name: fc.Name, Args: args.(map[string]interface{})}
+		}
+		if fr := p.FunctionResp; fr != nil {
+			resp, err := f.value(ctx, fr.Response)
+			if err != nil {
+				return c, err
+			}
+			p.FunctionResp = &api.FunctionResp{Name: fr.Name, Response: resp.(map[string]interface{})}
+		}
+		if ec := p.ExecutableCode; ec != nil {
+			code, err := f.Text(ctx, ec.Code)
+			if err != nil {
+				return c, err
+			}
+			p.ExecutableCode = &api.ExecutableCode{Language: ec.Language, Code: code}
+		}
+		if r := p.CodeExecutionResult; r != nil {
+			cr := *r
+			if cr.Output, err = f.Text(ctx, r.Output); err != nil {
+				return c, err
+			}
+			p.CodeExecutionResult = &cr
+		}
+		parts[i] = p
+	}
+	c.Parts = parts
+	return c, nil
+}
+
+// value filters the strings inside v, descending into maps and slices.
+func (f *Filter) value(ctx context.Context, v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		return f.Text(ctx, val)
+	case map[string]interface{}:
+		if val == nil {
+			return val, nil
+		}
+		out := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			fe, err := f.value(ctx, e)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = fe
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, e := range val {
+			fe, err := f.value(ctx, e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = fe
+		}
+		return out, nil
+	case []string:
+		out := make([]string, len(val))
+		for i, e := range val {
+			fe, err := f.Text(ctx, e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = fe
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// Wrap returns a provider that filters each request before p sends it.
+// With a nil filter p is returned unchanged.
+func Wrap(p api.Provider, f *Filter) api.Provider {
+	if f == nil {
+		return p
+	}
+	return &provider{next: p, filter: f}
+}
+
+type provider struct {
+	next   api.Provider
+	filter *Filter
+}
+
+func (p *provider) Generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	filtered, err := p.filter.Request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return p.next.Generate(ctx, filtered)
+}
+
+// Models implements api.ModelLister for the wrapped provider.
+func (p *provider) Models() []string {
+	return api.Models(p.next)
+}
+
+func (p *provider) GenerateStream(ctx context.Context, req *api.GenerateRequest) (<-chan api.StreamEvent, error) {
+	filtered, err := p.filter.Request(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return p.next.GenerateStream(ctx, filtered)
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package dlp
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/config"
+)
+
+func TestRequest(t *testing.T) {
+	f, err := New(config.DLPConfig{
+		Deny: []string{`(?i)project nightingale`},
+		Mask: []string{`\b\d{3}-\d{2}-\d{4}\b`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &api.GenerateRequest{Request: api.InnerRequest{
+		SystemInstruction: &api.Content{Parts: []api.Part{{Text: "You help with code."}}},
+		Contents: []api.Content{
+			{Role: "user", Parts: []api.Part{{Text: "Look up 123-45-6789"}}},
+			{Role: "user", Parts: []api.Part{{FunctionResp: &api.FunctionResp{Name: "read_file", Response: map[string]interface{}{
+				"content": "ssn: 987-65-4321",
+				"lines":   []interface{}{"ok", "111-22-3333"},
+			}}}}},
+		},
+	}}
+
+	out, err := f.Request(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Request.Contents[0].Parts[0].Text; got != "Look up [FILTERED]" {
+		t.Errorf("prompt = %q", got)
+	}
+	resp := out.Request.Contents[1].Parts[0].FunctionResp.Response
+	if resp["content"] != "ssn: [FILTERED]" || resp["lines"].([]interface{})[1] != "[FILTERED]" {
+		t.Errorf("tool result = %v", resp)
+	}
+	if req.Request.Contents[0].Parts[0].Text != "Look up 123-45-6789" {
+		t.Error("the original request was modified")
+	}
+
+	req.Request.Contents = append(req.Request.Contents, api.Content{Role: "user", Parts: []api.Part{{Text: "Status of Project Nightingale?"}}})
+	var blocked *BlockedError
+	if _, err := f.Request(context.Background(), req); !errors.As(err, &blocked) {
+		t.Errorf("deny pattern: err = %v, want BlockedError", err)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	script := `input=$(cat); case "$input" in *internal.example.com*) echo "internal host" >&2; exit 2;; esac; printf '%s' "$input" | tr a-z A-Z`
+	f, err := New(config.DLPConfig{Command: "sh", Args: []string{"-c", script}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := f.Text(context.Background(), "hello"); err != nil || got != "HELLO" {
+		t.Errorf("Text = %q, %v", got, err)
+	}
+	_, err = f.Text(context.Background(), "curl https://internal.example.com")
+	var blocked *BlockedError
+	if !errors.As(err, &blocked) || blocked.Reason != "internal host" {
+		t.Errorf("blocked text: err = %v", err)
+	}
+
+	if f, _ := New(config.DLPConfig{}); f != nil {
+		t.Error("New without rules should return nil")
+	}
+}
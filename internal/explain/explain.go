@@ -0,0 +1,251 @@
+// Package explain gathers the context needed to explain a file or symbol:
+// its source, what it imports and where it is used.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package explain
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Depth selects how thorough the explanation is.
+type Depth string
+
+const (
+	Quick Depth = "quick"
+	Deep  Depth = "deep"
+)
+
+// ParseDepth validates a --depth value.
+func ParseDepth(s string) (Depth, error) {
+	switch d := Depth(strings.ToLower(s)); d {
+	case Quick, Deep:
+		return d, nil
+	}
+	return "", fmt.Errorf("invalid depth %q (use quick or deep)", s)
+}
+
+const (
+	maxSourceBytes = 48 * 1024
+	maxFileBytes   = 1024 * 1024
+	maxDefinitions = 5
+	maxReferences  = 25
+)
+
+// Location is a line in the workspace.
+type Location struct {
+	File string // relative to the workspace
+	Line int
+	Text string
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d: %s", l.File, l.Line, l.Text)
+}
+
+// Target is what is being explained.
+type Target struct {
+	Query       string
+	File        string // relative path, for file targets
+	Symbol      string // for symbol targets
+	Source      string // file contents (truncated)
+	Imports     []string
+	Definitions []Location
+	References  []Location
+}
+
+// Resolve interprets query as a path relative to workDir or, failing that,
+// as a symbol name ("Name", "pkg.Name" or "Type.Method"), and collects its
+// context.
+func Resolve(workDir, query string) (*Target, error) {
+	t := &Target{Query: query}
+	path := query
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory; name a file or symbol", query)
+		}
+		rel, err := filepath.Rel(workDir, path)
+		if err != nil {
+			rel = path
+		}
+		t.File = filepath.ToSlash(rel)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		t.Source = truncate(string(data), maxSourceBytes)
+		t.Imports = imports(t.File, string(data))
+		if re := referencePattern(workDir, t.File); re != nil {
+			t.References = search(workDir, re, t.File, maxReferences)
+		}
+		return t, nil
+	}
+
+	name := query
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if !regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`).MatchString(name) {
+		return nil, fmt.Errorf("%q is neither a file nor a symbol name", query)
+	}
+	t.Symbol = name
+	def := definitionPattern(name)
+	t.Definitions = search(workDir, def, "", maxDefinitions)
+	if len(t.Definitions) == 0 {
+		return nil, fmt.Errorf("no file or definition of %q found", query)
+	}
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	for _, loc := range search(workDir, word, "", maxReferences+maxDefinitions) {
+		if !def.MatchString(loc.Text) && len(t.References) < maxReferences {
+			t.References = append(t.References, loc)
+		}
+	}
+	// The first definition's file gives the surrounding code
+	if data, err := os.ReadFile(filepath.Join(workDir, t.Definitions[0].File)); err == nil {
+		t.File = t.Definitions[0].File
+		t.Source = truncate(string(data), maxSourceBytes)
+		t.Imports = imports(t.File, string(data))
+	}
+	return t, nil
+}
+
+// definitionPattern matches common definition forms across languages.
+func definitionPattern(name string) *regexp.Regexp {
+	n := regexp.QuoteMeta(name)
+	return regexp.MustCompile(`(?:^|\s)(?:` +
+		`func\s+(?:\([^)]*\)\s*)?` + n + `\b` + // Go functions and methods
+		`|type\s+` + n + `\b` + // Go, TS types
+		`|(?:def|class|interface|enum|struct|trait|fn|function)\s+` + n + `\b` +
+		`|(?:const|let|var)\s+` + n + `\s*[=:]` +
+		`)`)
+}
+
+// referencePattern matches imports of the file from elsewhere.
+func referencePattern(workDir, rel string) *regexp.Regexp {
+	if strings.HasSuffix(rel, ".go") {
+		mod := goModule(workDir)
+		if mod == "" {
+			return nil
+		}
+		pkg := mod
+		if dir := filepath.ToSlash(filepath.Dir(rel)); dir != "." {
+			pkg += "/" + dir
+		}
+		return regexp.MustCompile(`"` + regexp.QuoteMeta(pkg) + `"`)
+	}
+	base := strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	return regexp.MustCompile(`(?:import|require|from)\b.*\b` + regexp.QuoteMeta(base) + `\b`)
+}
+
+func goModule(workDir string) string {
+	data, err := os.ReadFile(filepath.Join(workDir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	m := regexp.MustCompile(`(?m)^module\s+(\S+)`).FindSubmatch(data)
+	if m == nil {
+		return ""
+	}
+	return string(m[1])
+}
+
+var importPatterns = map[string]*regexp.Regexp{
+	".go": regexp.MustCompile(`(?m)^\s*(?:import\s+)?(?:[\w.]+\s+)?"([^"]+)"\s*$`),
+	".js": regexp.MustCompile(`(?m)(?:from\s+|require\(\s*|^import\s+)['"]([^'"]+)['"]`),
+	".py": regexp.MustCompile(`(?m)^\s*(?:from\s+(\S+)\s+import|import\s+(\S+))`),
+}
+
+// imports lists the modules a source file imports.
+func imports(rel, src string) []string {
+	ext := filepath.Ext(rel)
+	switch ext {
+	case ".ts", ".tsx", ".jsx", ".mjs", ".cjs":
+		ext = ".js"
+	}
+	re, ok := importPatterns[ext]
+	if !ok {
+		return nil
+	}
+	if ext == ".go" {
+		// Only the import block, not string literals in the body
+		if end := strings.Index(src, "\nfunc "); end >= 0 {
+			src = src[:end]
+		}
+	}
+	seen := make(map[string]bool)
+	var out []string
+	for _, m := range re.FindAllStringSubmatch(src, -1) {
+		for _, g := range m[1:] {
+			if g != "" && !seen[g] {
+				seen[g] = true
+				out = append(out, g)
+			}
+		}
+	}
+	return out
+}
+
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, ".svn": true,
+	"__pycache__": true, "dist": true, "build": true, "target": true, ".venv": true,
+}
+
+// search returns up to limit lines matching re in text files under
+// workDir, skipping the file exclude.
+func search(workDir string, re *regexp.Regexp, exclude string, limit int) []Location {
+	var found []Location
+	_ = filepath.WalkDir(workDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || len(found) >= limit {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path != workDir && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(workDir, path)
+		rel = filepath.ToSlash(rel)
+		if rel == exclude {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFileBytes {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(data[:min(len(data), 512)], 0) >= 0 {
+			return nil
+		}
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		for line := 1; sc.Scan(); line++ {
+			if re.MatchString(sc.Text()) {
+				found = append(found, Location{File: rel, Line: line, Text: truncate(strings.TrimSpace(sc.Text()), 200)})
+				if len(found) >= limit {
+					break
+				}
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "\n... [truncated]"
+}
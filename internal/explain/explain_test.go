@@ -0,0 +1,63 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package explain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":            "module example.com/app\n\ngo 1.22\n",
+		"store/store.go":    "package store\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\n// Open opens the store.\nfunc Open(path string) error {\n\t_, err := os.Stat(path)\n\treturn fmt.Errorf(\"%w\", err)\n}\n",
+		"cmd/main.go":       "package main\n\nimport \"example.com/app/store\"\n\nfunc main() {\n\tstore.Open(\"db\")\n}\n",
+		"node_modules/x.js": "function Open() {}\n",
+	})
+
+	file, err := Resolve(dir, "store/store.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(file.Imports, ",") != "fmt,os" {
+		t.Errorf("imports = %v", file.Imports)
+	}
+	if len(file.References) != 1 || file.References[0].File != "cmd/main.go" {
+		t.Errorf("references = %v", file.References)
+	}
+
+	sym, err := Resolve(dir, "store.Open")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sym.Definitions) != 1 || sym.Definitions[0].File != "store/store.go" || sym.Definitions[0].Line != 9 {
+		t.Errorf("definitions = %v", sym.Definitions)
+	}
+	// The doc comment and the call site, but not the definition itself
+	if len(sym.References) != 2 {
+		t.Errorf("references = %v", sym.References)
+	}
+	if p := Prompt(sym, Deep); !strings.Contains(p, "store/store.go:9") || !strings.Contains(p, "Place in the system") {
+		t.Errorf("prompt missing context:\n%s", p)
+	}
+
+	if _, err := Resolve(dir, "Missing"); err == nil {
+		t.Error("expected error for unknown symbol")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package explain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prompt builds the user message asking for an explanation of t.
+func Prompt(t *Target, depth Depth) string {
+	var b strings.Builder
+	if t.Symbol != "" {
+		fmt.Fprintf(&b, "Explain the symbol `%s` in this repository.\n\n", t.Query)
+		b.WriteString("Definitions:\n")
+		for _, d := range t.Definitions {
+			fmt.Fprintf(&b, "- %s\n", d)
+		}
+		b.WriteString("\n")
+	} else {
+		fmt.Fprintf(&b, "Explain the file `%s` in this repository.\n\n", t.File)
+	}
+
+	if len(t.Imports) > 0 {
+		fmt.Fprintf(&b, "`%s` imports: %s\n\n", t.File, strings.Join(t.Imports, ", "))
+	}
+	if len(t.References) > 0 {
+		b.WriteString("Used from:\n")
+		for _, r := range t.References {
+			fmt.Fprintf(&b, "- %s\n", r)
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("No uses were found elsewhere in the repository.\n\n")
+	}
+
+	if t.Source != "" {
+		fmt.Fprintf(&b, "Contents of `%s`:\n\n```\n%s\n```\n\n", t.File, t.Source)
+	}
+
+	switch depth {
+	case Deep:
+		b.WriteString(`Use the read-only tools to read the callers and the local packages it depends on where that helps. Then write an architecture-aware explanation in Markdown covering:
+- Purpose: what it does and why it exists
+- How it works: the main flow, data structures and invariants
+- Place in the system: who calls it, what it depends on, and how data moves between them
+- Design decisions and trade-offs visible in the code
+- Pitfalls: edge cases, error handling and anything surprising for a new contributor
+
+Reference code as path:line. Only state what the code shows.
+`)
+	default:
+		b.WriteString(`Answer from the context above without further investigation unless something essential is missing. Write a short Markdown explanation: one paragraph on its purpose and role, then a bullet list of the key functions or types and what they do. Reference code as path:line.
+`)
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package extension
+
+import (
+	"path/filepath"
+
+	"github.com/k-sub1995/g/internal/diskcache"
+)
+
+// cacheFileName holds parsed manifests and the enablement config between
+// runs, so one-shot invocations skip re-reading and re-parsing them.
+// Context files need no cache of their own: an extension's are found with
+// its manifest and kept with it, and memory.Files looks in a fixed handful
+// of directories instead of walking the tree, so a cache would have to
+// stat as many files to be validated as the lookup does.
+const (
+	cacheFileName = "g_extensions_cache.json"
+	cacheVersion  = 2
+)
+
+type cachedExtension struct {
+	Manifest  diskcache.Stamp `json:"manifest"`
+	Dir       diskcache.Stamp `json:"dir"` // changes when context files are added or removed
+	Extension Extension       `json:"extension"`
+}
+
+type cachedEnablement struct {
+	Stamp  diskcache.Stamp  `json:"stamp"`
+	Config enablementConfig `json:"config"`
+}
+
+// cache is the on-disk cache. Entries are valid while the stamps of the
+// files they were built from are unchanged. Extensions that declare
+// variables are never cached, so secrets are not written to disk.
+type cache struct {
+	Enablement *cachedEnablement          `json:"enablement,omitempty"`
+	Extensions map[string]cachedExtension `json:"extensions"`
+
+	path  string
+	seen  map[string]bool
+	dirty bool
+}
+
+func openCache() *cache {
+	c := &cache{seen: map[string]bool{}}
+	c.path = diskcache.Load(cacheFileName, cacheVersion, c)
+	if c.Extensions == nil {
+		c.Extensions = map[string]cachedExtension{}
+	}
+	return c
+}
+
+// enablement returns the parsed enablement config at path.
+func (c *cache) enablement(path string) enablementConfig {
+	st, ok := diskcache.Stat(path)
+	if !ok {
+		if c.Enablement != nil {
+			c.Enablement, c.dirty = nil, true
+		}
+		return nil
+	}
+	if c.Enablement != nil && c.Enablement.Stamp == st {
+		return c.Enablement.Config
+	}
+	cfg := loadEnablementConfig(path)
+	c.Enablement, c.dirty = &cachedEnablement{Stamp: st, Config: cfg}, true
+	return cfg
+}
+
+// extension returns the extension in extDir, loading it if the cached copy
+// is missing or stale.
+func (c *cache) extension(extDir string) (*Extension, error) {
+	c.seen[extDir] = true
+	manifest, okManifest := diskcache.Stat(filepath.Join(extDir, "gemini-extension.json"))
+	dir, okDir := diskcache.Stat(extDir)
+	if entry, ok := c.Extensions[extDir]; ok && okManifest && okDir && entry.Manifest == manifest && entry.Dir == dir {
+		ext := entry.Extension
+		return &ext, nil
+	}
+
+	ext, err := loadExtension(extDir)
+	if err != nil {
+		return nil, err
+	}
+	if okManifest && okDir && len(ext.Variables) == 0 {
+		c.Extensions[extDir] = cachedExtension{Manifest: manifest, Dir: dir, Extension: *ext}
+		c.dirty = true
+	}
+	return ext, nil
+}
+
+// save drops entries for extensions that no longer exist and writes the
+// cache if anything changed.
+func (c *cache) save() {
+	for extDir := range c.Extensions {
+		if !c.seen[extDir] {
+			delete(c.Extensions, extDir)
+			c.dirty = true
+		}
+	}
+	if c.dirty {
+		diskcache.Save(c.path, cacheVersion, c)
+	}
+}
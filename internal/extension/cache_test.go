@@ -0,0 +1,64 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package extension
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadAllCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	extDir := filepath.Join(home, ".gemini", "extensions", "demo")
+	if err := os.MkdirAll(extDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(extDir, "gemini-extension.json")
+	write := func(version string) {
+		data := `{"name": "demo", "version": "` + version + `", "mcpServers": {"s": {"command": "${extensionPath}/bin"}}}`
+		if err := os.WriteFile(manifest, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("1.0.0")
+
+	exts, err := LoadAll(home)
+	if err != nil || len(exts) != 1 || exts[0].Version != "1.0.0" {
+		t.Fatalf("LoadAll = %+v, %v", exts, err)
+	}
+	cacheData, err := os.ReadFile(filepath.Join(home, ".gemini", cacheFileName))
+	if err != nil || !strings.Contains(string(cacheData), extDir) {
+		t.Fatalf("cache not written: %s, %v", cacheData, err)
+	}
+
+	// A cached load returns the same extension.
+	exts, err = LoadAll(home)
+	if err != nil || len(exts) != 1 || exts[0].MCPServers["s"].Command != extDir+"/bin" {
+		t.Fatalf("cached LoadAll = %+v, %v", exts, err)
+	}
+
+	// Editing the manifest invalidates the entry.
+	write("2.0.0")
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(manifest, future, future); err != nil {
+		t.Fatal(err)
+	}
+	exts, _ = LoadAll(home)
+	if len(exts) != 1 || exts[0].Version != "2.0.0" {
+		t.Fatalf("stale cache entry used: %+v", exts)
+	}
+
+	// Disabling it through the enablement config takes effect immediately.
+	enablement := filepath.Join(home, ".gemini", "extensions", "extension-enablement.json")
+	if err := os.WriteFile(enablement, []byte(`{"demo": {"overrides": ["!/*/"]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if exts, _ = LoadAll(home); len(exts) != 0 {
+		t.Fatalf("disabled extension loaded: %+v", exts)
+	}
+}
@@ -21,6 +21,29 @@ type Manifest struct {
 	Version         string                            `json:"version"`
 	ContextFileName interface{}                       `json:"contextFileName"` // string or []string
 	MCPServers      map[string]config.MCPServerConfig `json:"mcpServers"`
+	Tools           []ToolConfig                      `json:"tools"`
+	Variables       []VariableConfig                  `json:"variables"`
+}
+
+// VariableConfig declares a user-supplied value (e.g. an API token) that is
+// requested at install time and substituted for ${var:NAME} in the manifest.
+type VariableConfig struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// ToolConfig declares a standalone executable tool provided by an extension.
+// The executable receives the call arguments as a JSON object on stdin and
+// is expected to print a JSON object to stdout.
+type ToolConfig struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+	Command     string          `json:"command"`
+	Args        []string        `json:"args,omitempty"`
+	Timeout     int             `json:"timeout,omitempty"` // milliseconds
 }
 
 // Extension holds a parsed, variable-hydrated extension ready for use.
@@ -30,6 +53,8 @@ type Extension struct {
 	Path         string // absolute path to extension directory
 	MCPServers   map[string]config.MCPServerConfig
 	ContextFiles []string // absolute paths to existing context files
+	Tools        []ToolConfig
+	Variables    []VariableConfig
 }
 
 // enablementConfig maps extension name to enablement rules.
@@ -39,19 +64,20 @@ type enablementConfig map[string]struct {
 
 // LoadAll discovers and loads all enabled extensions from ~/.gemini/extensions/.
 // currentPath is the current working directory, used for enablement matching.
+// Parsed manifests are cached (see cache) and reused while unchanged.
 func LoadAll(currentPath string) ([]Extension, error) {
-	geminiDir, err := config.GeminiDir()
+	extensionsDir, err := Dir()
 	if err != nil {
 		return nil, err
 	}
-	extensionsDir := filepath.Join(geminiDir, "extensions")
 
 	info, err := os.Stat(extensionsDir)
 	if err != nil || !info.IsDir() {
 		return nil, nil
 	}
 
-	enablement := loadEnablementConfig(filepath.Join(extensionsDir, "extension-enablement.json"))
+	cache := openCache()
+	enablement := cache.enablement(filepath.Join(extensionsDir, "extension-enablement.json"))
 
 	entries, err := os.ReadDir(extensionsDir)
 	if err != nil {
@@ -60,11 +86,11 @@ func LoadAll(currentPath string) ([]Extension, error) {
 
 	var extensions []Extension
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 		extDir := filepath.Join(extensionsDir, entry.Name())
-		ext, err := loadExtension(extDir)
+		ext, err := cache.extension(extDir)
 		if err != nil {
 			continue // skip broken extensions
 		}
@@ -73,6 +99,7 @@ func LoadAll(currentPath string) ([]Extension, error) {
 		}
 		extensions = append(extensions, *ext)
 	}
+	cache.save()
 	return extensions, nil
 }
 
@@ -85,8 +112,9 @@ func loadExtension(extDir string) (*Extension, error) {
 
 	// Pre-validate name/version before hydration
 	var raw struct {
-		Name    string `json:"name"`
-		Version string `json:"version"`
+		Name      string           `json:"name"`
+		Version   string           `json:"version"`
+		Variables []VariableConfig `json:"variables"`
 	}
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, err
@@ -97,11 +125,17 @@ func loadExtension(extDir string) (*Extension, error) {
 
 	// Hydrate variables on raw JSON string
 	absExtDir, _ := filepath.Abs(extDir)
-	hydrated := hydrateVariables(string(data), map[string]string{
+	vars := map[string]string{
 		"extensionPath": absExtDir,
 		"/":             string(filepath.Separator),
 		"pathSeparator": string(filepath.Separator),
-	})
+	}
+	for _, v := range raw.Variables {
+		if val, ok := lookupVariable(raw.Name, v); ok {
+			vars["var:"+v.Name] = jsonEscape(val)
+		}
+	}
+	hydrated := hydrateVariables(string(data), vars)
 
 	var manifest Manifest
 	if err := json.Unmarshal([]byte(hydrated), &manifest); err != nil {
@@ -118,12 +152,27 @@ func loadExtension(extDir string) (*Extension, error) {
 		}
 	}
 
+	// Keep only well-formed tool declarations; relative commands resolve
+	// against the extension directory.
+	var tools []ToolConfig
+	for _, tool := range manifest.Tools {
+		if tool.Name == "" || tool.Command == "" {
+			continue
+		}
+		if !filepath.IsAbs(tool.Command) && strings.ContainsRune(tool.Command, filepath.Separator) {
+			tool.Command = filepath.Join(absExtDir, tool.Command)
+		}
+		tools = append(tools, tool)
+	}
+
 	return &Extension{
 		Name:         manifest.Name,
 		Version:      manifest.Version,
 		Path:         absExtDir,
 		MCPServers:   manifest.MCPServers,
 		ContextFiles: contextFiles,
+		Tools:        tools,
+		Variables:    manifest.Variables,
 	}, nil
 }
 
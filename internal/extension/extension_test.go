@@ -432,3 +432,82 @@ func TestLoadEnablementConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadExtension_Tools(t *testing.T) {
+	tmpDir := t.TempDir()
+	extDir := filepath.Join(tmpDir, "ext")
+	os.MkdirAll(extDir, 0o755)
+
+	manifest := map[string]interface{}{
+		"name":    "tool-ext",
+		"version": "1.0.0",
+		"tools": []interface{}{
+			map[string]interface{}{
+				"name":        "lookup",
+				"description": "Look something up",
+				"command":     "bin/lookup",
+				"parameters":  map[string]interface{}{"type": "object"},
+			},
+			map[string]interface{}{
+				"name":    "on-path",
+				"command": "python3",
+			},
+			map[string]interface{}{
+				"name": "missing-command",
+			},
+		},
+	}
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	os.WriteFile(filepath.Join(extDir, "gemini-extension.json"), data, 0o644)
+
+	ext, err := loadExtension(extDir)
+	if err != nil {
+		t.Fatalf("loadExtension failed: %v", err)
+	}
+	if len(ext.Tools) != 2 {
+		t.Fatalf("Tools len = %d, want 2", len(ext.Tools))
+	}
+
+	absExtDir, _ := filepath.Abs(extDir)
+	if want := filepath.Join(absExtDir, "bin", "lookup"); ext.Tools[0].Command != want {
+		t.Errorf("Tools[0].Command = %q, want %q", ext.Tools[0].Command, want)
+	}
+	if ext.Tools[1].Command != "python3" {
+		t.Errorf("Tools[1].Command = %q, want %q", ext.Tools[1].Command, "python3")
+	}
+}
+
+func TestLoadExtension_Variables(t *testing.T) {
+	tmpDir := t.TempDir()
+	extDir := filepath.Join(tmpDir, "ext")
+	os.MkdirAll(extDir, 0o755)
+
+	manifest := map[string]interface{}{
+		"name":    "var-ext",
+		"version": "1.0.0",
+		"variables": []interface{}{
+			map[string]interface{}{"name": "G_TEST_TOKEN", "secret": true},
+		},
+		"mcpServers": map[string]interface{}{
+			"svc": map[string]interface{}{
+				"command": "svc",
+				"env":     map[string]interface{}{"TOKEN": "${var:G_TEST_TOKEN}"},
+			},
+		},
+	}
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	os.WriteFile(filepath.Join(extDir, "gemini-extension.json"), data, 0o644)
+
+	t.Setenv("G_TEST_TOKEN", `s3cr"et`)
+
+	ext, err := loadExtension(extDir)
+	if err != nil {
+		t.Fatalf("loadExtension failed: %v", err)
+	}
+	if got := ext.MCPServers["svc"].Env["TOKEN"]; got != `s3cr"et` {
+		t.Errorf("TOKEN = %q, want %q", got, `s3cr"et`)
+	}
+	if len(ext.Variables) != 1 || !ext.Variables[0].Secret {
+		t.Errorf("Variables = %+v, want one secret variable", ext.Variables)
+	}
+}
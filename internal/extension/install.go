@@ -0,0 +1,197 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package extension
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/config"
+	"github.com/k-sub1995/g/internal/keyring"
+)
+
+// Dir returns the path to ~/.gemini/extensions.
+func Dir() (string, error) {
+	geminiDir, err := config.GeminiDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(geminiDir, "extensions"), nil
+}
+
+// validName is what an extension may be called: its directory name in
+// ~/.gemini/extensions.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// installPath returns where the extension called name is installed, and
+// an error for names that are not a single directory in extensionsDir.
+func installPath(extensionsDir, name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid extension name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	dest := filepath.Join(extensionsDir, name)
+	if rel, err := filepath.Rel(extensionsDir, dest); err != nil || rel != name {
+		return "", fmt.Errorf("invalid extension name %q", name)
+	}
+	return dest, nil
+}
+
+// Install copies (or git-clones) an extension from source into
+// ~/.gemini/extensions/<name>. The returned extension is loaded before any
+// variables are set, so callers should prompt for Variables afterwards.
+func Install(source string) (*Extension, error) {
+	extensionsDir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(extensionsDir, 0755); err != nil {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp(extensionsDir, ".install-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	if isGitSource(source) {
+		cmd := exec.Command("git", "clone", "--depth", "1", source, staging)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("git clone failed: %w", err)
+		}
+		os.RemoveAll(filepath.Join(staging, ".git"))
+	} else if err := copyDir(source, staging); err != nil {
+		return nil, fmt.Errorf("failed to copy extension: %w", err)
+	}
+
+	ext, err := loadExtension(staging)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extension at %s: %w", source, err)
+	}
+
+	dest, err := installPath(extensionsDir, ext.Name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return nil, fmt.Errorf("extension %q is already installed at %s", ext.Name, dest)
+	}
+	if err := os.Rename(staging, dest); err != nil {
+		return nil, err
+	}
+	return loadExtension(dest)
+}
+
+// Uninstall removes an installed extension and its stored variables.
+func Uninstall(name string) error {
+	extensionsDir, err := Dir()
+	if err != nil {
+		return err
+	}
+	dest, err := installPath(extensionsDir, name)
+	if err != nil {
+		return err
+	}
+	ext, err := loadExtension(dest)
+	if err != nil {
+		return fmt.Errorf("extension %q is not installed", name)
+	}
+	for _, v := range ext.Variables {
+		_ = keyring.Delete(variableService(ext.Name), v.Name)
+	}
+	return os.RemoveAll(dest)
+}
+
+// SetVariable stores the value of a manifest-declared variable.
+func SetVariable(extName, name, value string) error {
+	return keyring.Set(variableService(extName), name, value)
+}
+
+// HasVariable reports whether a value is stored (or provided via the
+// environment) for the given variable.
+func HasVariable(extName string, v VariableConfig) bool {
+	_, ok := lookupVariable(extName, v)
+	return ok
+}
+
+func variableService(extName string) string {
+	return "g-extension:" + extName
+}
+
+// lookupVariable resolves a variable from the environment, the keyring, and
+// finally the manifest default, in that order.
+func lookupVariable(extName string, v VariableConfig) (string, bool) {
+	if v.Name == "" {
+		return "", false
+	}
+	if val, ok := os.LookupEnv(v.Name); ok {
+		return val, true
+	}
+	if val, err := keyring.Get(variableService(extName), v.Name); err == nil {
+		return val, true
+	}
+	if v.Default != "" {
+		return v.Default, true
+	}
+	return "", false
+}
+
+// jsonEscape escapes s for safe substitution inside a JSON string literal.
+func jsonEscape(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data[1 : len(data)-1])
+}
+
+func isGitSource(source string) bool {
+	return strings.HasPrefix(source, "https://") ||
+		strings.HasPrefix(source, "git@") ||
+		strings.HasSuffix(source, ".git")
+}
+
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package extension
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallRejectsUnsafeNames(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	for _, name := range []string{"../escape", "a/b", "..", ".hidden", `a\b`, ""} {
+		src := t.TempDir()
+		manifest := `{"name": ` + jsonQuote(name) + `, "version": "1.0.0"}`
+		if err := os.WriteFile(filepath.Join(src, "gemini-extension.json"), []byte(manifest), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if ext, err := Install(src); err == nil {
+			t.Errorf("Install with name %q = %s, want an error", name, ext.Path)
+		}
+		if err := Uninstall(name); err == nil {
+			t.Errorf("Uninstall(%q) succeeded", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(home, ".gemini", "escape")); err == nil {
+		t.Error("an extension was installed outside the extensions directory")
+	}
+
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "gemini-extension.json"), []byte(`{"name": "demo.tools", "version": "1.0.0"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Install(src); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if err := Uninstall("demo.tools"); err != nil {
+		t.Fatalf("Uninstall: %v", err)
+	}
+}
+
+func jsonQuote(s string) string {
+	return `"` + jsonEscape(s) + `"`
+}
@@ -0,0 +1,170 @@
+// Package filewatch notices workspace files that changed between agent
+// turns, such as edits the user made in their editor.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package filewatch
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxFiles bounds the files tracked, so huge trees stay cheap to scan.
+// Changes to files beyond it are missed, and Changes says so.
+var maxFiles = 20000
+
+// maxListed bounds the files named in a note.
+const maxListed = 20
+
+var skipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	".svn":         true,
+	"__pycache__":  true,
+}
+
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher compares the workspace against the state recorded by the last
+// Snapshot. Scanning happens only on Snapshot and Changes, so nothing runs
+// in the background.
+type Watcher struct {
+	root   string
+	files  map[string]stamp
+	capped bool // files stopped at maxFiles
+}
+
+// New creates a watcher for root and records its current state.
+func New(root string) *Watcher {
+	w := &Watcher{root: root}
+	w.Snapshot()
+	return w
+}
+
+// Snapshot records the current state, typically at the end of a turn so
+// the agent's own edits are not reported.
+func (w *Watcher) Snapshot() {
+	w.files, w.capped = w.scan()
+}
+
+// Changes holds workspace-relative paths, sorted.
+type Changes struct {
+	Modified []string
+	Created  []string
+	Deleted  []string
+
+	// Incomplete is set when the workspace has more files than are
+	// tracked: changes to the others are missing, and files pushed past
+	// the limit may be listed as deleted.
+	Incomplete bool
+}
+
+// Empty reports whether nothing changed.
+func (c Changes) Empty() bool {
+	return len(c.Modified)+len(c.Created)+len(c.Deleted) == 0
+}
+
+// Changes returns what differs from the last snapshot and records the new
+// state.
+func (w *Watcher) Changes() Changes {
+	c, now, capped := w.diff()
+	w.files, w.capped = now, capped
+	return c
+}
+
+// Diff returns what differs from the last snapshot, leaving it as it is.
+func (w *Watcher) Diff() Changes {
+	c, _, _ := w.diff()
+	return c
+}
+
+func (w *Watcher) diff() (Changes, map[string]stamp, bool) {
+	now, capped := w.scan()
+	c := Changes{Incomplete: capped || w.capped}
+	for path, s := range now {
+		old, ok := w.files[path]
+		switch {
+		case !ok:
+			c.Created = append(c.Created, path)
+		case old != s:
+			c.Modified = append(c.Modified, path)
+		}
+	}
+	for path := range w.files {
+		if _, ok := now[path]; !ok {
+			c.Deleted = append(c.Deleted, path)
+		}
+	}
+	sort.Strings(c.Modified)
+	sort.Strings(c.Created)
+	sort.Strings(c.Deleted)
+	return c, now, capped
+}
+
+// scan returns the stamps of the workspace's files and whether it stopped
+// at maxFiles.
+func (w *Watcher) scan() (map[string]stamp, bool) {
+	files := map[string]stamp{}
+	capped := false
+	filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(files) >= maxFiles {
+			capped = true
+			return filepath.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			return nil
+		}
+		files[filepath.ToSlash(rel)] = stamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files, capped
+}
+
+// Note renders changes as a message for the model, or "" if there are none.
+func (c Changes) Note() string {
+	if c.Empty() {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("[These files changed on disk since your last turn (edited outside the agent). Re-read them before editing.]\n")
+	listed := 0
+	for _, group := range []struct {
+		label string
+		paths []string
+	}{
+		{"modified", c.Modified},
+		{"created", c.Created},
+		{"deleted", c.Deleted},
+	} {
+		for _, p := range group.paths {
+			if listed == maxListed {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(c.Modified)+len(c.Created)+len(c.Deleted)-listed)
+				return strings.TrimRight(b.String(), "\n")
+			}
+			fmt.Fprintf(&b, "- %s (%s)\n", p, group.label)
+			listed++
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
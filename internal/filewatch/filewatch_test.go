@@ -0,0 +1,73 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChanges(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main")
+	write("old.txt", "x")
+	write("node_modules/dep/index.js", "1")
+
+	w := New(dir)
+	if c := w.Changes(); !c.Empty() {
+		t.Fatalf("unexpected changes %+v", c)
+	}
+
+	write("main.go", "package main\n\nfunc main() {}")
+	os.Chtimes(filepath.Join(dir, "main.go"), time.Now(), time.Now().Add(time.Second))
+	write("sub/new.go", "package sub")
+	os.Remove(filepath.Join(dir, "old.txt"))
+	write("node_modules/dep/index.js", "2")
+
+	c := w.Changes()
+	if strings.Join(c.Modified, ",") != "main.go" || strings.Join(c.Created, ",") != "sub/new.go" || strings.Join(c.Deleted, ",") != "old.txt" {
+		t.Fatalf("changes = %+v", c)
+	}
+	note := c.Note()
+	if !strings.Contains(note, "- main.go (modified)") || !strings.Contains(note, "- old.txt (deleted)") {
+		t.Errorf("note = %q", note)
+	}
+	if c := w.Changes(); !c.Empty() {
+		t.Errorf("changes reported twice: %+v", c)
+	}
+}
+
+func TestChangesCapped(t *testing.T) {
+	defer func(n int) { maxFiles = n }(maxFiles)
+	maxFiles = 2
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("1"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := New(dir)
+	os.WriteFile(filepath.Join(dir, "c"), []byte("22"), 0644)
+	if c := w.Diff(); !c.Empty() || !c.Incomplete {
+		t.Errorf("changes past the limit = %+v, want none and Incomplete", c)
+	}
+
+	maxFiles = 10
+	if c := w.Changes(); !c.Incomplete || strings.Join(c.Created, ",") != "c" {
+		t.Errorf("changes after a capped snapshot = %+v", c)
+	}
+	if c := w.Changes(); c.Incomplete {
+		t.Errorf("changes below the limit = %+v", c)
+	}
+}
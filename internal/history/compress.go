@@ -0,0 +1,101 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// maxTranscriptPart bounds each tool call, tool result and code block in
+// the transcript that is summarized; the model's reply usually says what
+// mattered in them.
+const maxTranscriptPart = 2000
+
+const summaryPrompt = `Summarize the conversation below between a user and a coding assistant, so that the assistant can continue the work from the summary alone. Keep:
+- the user's goals, requirements and preferences, and any decisions made
+- files read or changed, with what changed, and commands run with their outcome
+- facts learned about the code base, errors met and how they were resolved
+- what is done and what remains to do, including the last request
+Leave out greetings and anything superseded. Write plain text with short sections; do not address the user.
+
+<conversation>
+%s
+</conversation>`
+
+// Summarize asks model to summarize the conversation in req, for
+// Compressed. The request's tools and system instruction are not sent.
+func Summarize(ctx context.Context, p api.Provider, req *api.GenerateRequest, model string) (string, error) {
+	resp, err := p.Generate(ctx, &api.GenerateRequest{
+		Model:        model,
+		Project:      req.Project,
+		UserPromptID: req.UserPromptID,
+		Request: api.InnerRequest{
+			Contents: []api.Content{{Role: "user", Parts: []api.Part{{Text: fmt.Sprintf(summaryPrompt, transcript(req.Request.Contents))}}}},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarizing the conversation: %w", err)
+	}
+	var b strings.Builder
+	if len(resp.Response.Candidates) > 0 {
+		for _, part := range resp.Response.Candidates[0].Content.Parts {
+			b.WriteString(part.Text)
+		}
+	}
+	summary := strings.TrimSpace(b.String())
+	if summary == "" {
+		return "", fmt.Errorf("summarizing the conversation: empty reply")
+	}
+	return summary, nil
+}
+
+// Compressed returns the history that replaces a conversation summarized
+// as summary: the summary as a user message and a short model reply, so
+// that the next user message keeps the roles alternating.
+func Compressed(summary string) []api.Content {
+	return []api.Content{
+		{Role: "user", Parts: []api.Part{{Text: "Summary of our conversation so far, replacing the full history:\n\n" + summary}}},
+		{Role: "model", Parts: []api.Part{{Text: "Understood. I will continue from this summary."}}},
+	}
+}
+
+// transcript renders contents as plain text. Tool calls, results and code
+// are shortened to maxTranscriptPart bytes and attachments are named only.
+func transcript(contents []api.Content) string {
+	var b strings.Builder
+	for _, c := range contents {
+		for _, p := range c.Parts {
+			switch {
+			case p.Text != "" && c.Role == "model":
+				fmt.Fprintf(&b, "Assistant: %s\n\n", p.Text)
+			case p.Text != "":
+				fmt.Fprintf(&b, "User: %s\n\n", p.Text)
+			case p.FunctionCall != nil:
+				args, _ := json.Marshal(p.FunctionCall.Args)
+				fmt.Fprintf(&b, "Tool call %s: %s\n\n", p.FunctionCall.Name, shorten(string(args)))
+			case p.FunctionResp != nil:
+				resp, _ := json.Marshal(p.FunctionResp.Response)
+				fmt.Fprintf(&b, "Tool result %s: %s\n\n", p.FunctionResp.Name, shorten(string(resp)))
+			case p.ExecutableCode != nil:
+				fmt.Fprintf(&b, "Code run:\n%s\n\n", shorten(p.ExecutableCode.Code))
+			case p.CodeExecutionResult != nil:
+				fmt.Fprintf(&b, "Code output (%s): %s\n\n", p.CodeExecutionResult.Outcome, shorten(p.CodeExecutionResult.Output))
+			case p.InlineData != nil:
+				fmt.Fprintf(&b, "[%s attachment]\n\n", p.InlineData.MimeType)
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func shorten(s string) string {
+	if len(s) <= maxTranscriptPart {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxTranscriptPart], "") + " [...]"
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package history
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+type fakeProvider struct {
+	reply string
+	got   *api.GenerateRequest
+}
+
+func (f *fakeProvider) Generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	f.got = req
+	resp := &api.GenerateResponse{}
+	resp.Response.Candidates = []api.Candidate{{Content: api.Content{Parts: []api.Part{{Text: f.reply}}}}}
+	return resp, nil
+}
+
+func (f *fakeProvider) GenerateStream(ctx context.Context, req *api.GenerateRequest) (<-chan api.StreamEvent, error) {
+	panic("not used")
+}
+
+func TestSummarize(t *testing.T) {
+	req := &api.GenerateRequest{Model: "gemini-2.5-pro", Project: "p1"}
+	req.Request.Tools = []api.Tool{{}}
+	req.Request.Contents = []api.Content{
+		{Role: "user", Parts: []api.Part{{Text: "fix the parser"}}},
+		{Role: "model", Parts: []api.Part{{FunctionCall: &api.FunctionCall{Name: "read_file", Args: map[string]interface{}{"file_path": "parse.go"}}}}},
+		toolResult("read_file", 10_000),
+		{Role: "model", Parts: []api.Part{{Text: "Fixed the off-by-one in parse.go."}}},
+	}
+	p := &fakeProvider{reply: "  The user asked to fix the parser; done.\n"}
+
+	summary, err := Summarize(context.Background(), p, req, "gemini-2.5-flash-lite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary != "The user asked to fix the parser; done." {
+		t.Errorf("summary = %q", summary)
+	}
+	if p.got.Model != "gemini-2.5-flash-lite" || p.got.Project != "p1" || len(p.got.Request.Tools) != 0 {
+		t.Errorf("request: model %q, project %q, %d tools", p.got.Model, p.got.Project, len(p.got.Request.Tools))
+	}
+	prompt := p.got.Request.Contents[0].Parts[0].Text
+	for _, want := range []string{"User: fix the parser", "Tool call read_file: {\"file_path\":\"parse.go\"}", "Assistant: Fixed the off-by-one"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q", want)
+		}
+	}
+	if len(prompt) > 2*maxTranscriptPart+len(summaryPrompt) {
+		t.Errorf("tool result not shortened: prompt is %d bytes", len(prompt))
+	}
+
+	compressed := Compressed(summary)
+	if len(compressed) != 2 || compressed[0].Role != "user" || compressed[1].Role != "model" ||
+		!strings.Contains(compressed[0].Parts[0].Text, summary) {
+		t.Errorf("compressed history = %+v", compressed)
+	}
+	if EstimateTokens(compressed) >= EstimateTokens(req.Request.Contents) {
+		t.Error("compressed history is not smaller")
+	}
+}
+
+func TestProposeNotes(t *testing.T) {
+	req := &api.GenerateRequest{Model: "gemini-2.5-pro"}
+	req.Request.Contents = []api.Content{{Role: "user", Parts: []api.Part{{Text: "run the tests"}}}}
+	p := &fakeProvider{reply: "Here is what I found:\n- Tests run with `go test ./...`.\n* Fixtures live in testdata/.\n-\nNONE"}
+	notes, err := ProposeNotes(context.Background(), p, req, "gemini-2.5-pro", "# Project\nUse Go 1.22.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 || notes[0] != "Tests run with `go test ./...`." || notes[1] != "Fixtures live in testdata/." {
+		t.Errorf("notes = %q", notes)
+	}
+	prompt := p.got.Request.Contents[0].Parts[0].Text
+	if !strings.Contains(prompt, "Use Go 1.22.") || !strings.Contains(prompt, "User: run the tests") {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	p.reply = "NONE"
+	if notes, _ := ProposeNotes(context.Background(), p, req, "gemini-2.5-pro", ""); len(notes) != 0 {
+		t.Errorf("notes for NONE = %q", notes)
+	}
+}
@@ -0,0 +1,222 @@
+// Package history keeps a conversation within a context-size budget.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// DefaultMaxTokens is the budget used when neither a budget nor the
+// model's context window is known.
+const DefaultMaxTokens = 600_000
+
+// contextShare is the part of a model's context window the conversation
+// may fill before it is shortened, leaving room for the system prompt,
+// tool declarations and the reply.
+const contextShare = 0.6
+
+// Budget returns the default budget for a model with the given context
+// window in tokens.
+func Budget(contextWindow int) int {
+	if contextWindow <= 0 {
+		return DefaultMaxTokens
+	}
+	return int(float64(contextWindow) * contextShare)
+}
+
+// bytesPerToken is a rough estimate for JSON-encoded conversation text.
+const bytesPerToken = 4
+
+// Tool results longer than minEvictLen are replaced by their first
+// previewLen bytes.
+const (
+	minEvictLen = 1024
+	previewLen  = 200
+)
+
+// Manager shrinks the oldest parts of a conversation once it exceeds
+// MaxTokens, in this order: inline blobs (images, PDFs) in tool results,
+// tool results themselves (replaced by a short preview), then the text of
+// intermediate model replies. User messages, the final reply of each turn
+// and pinned messages are never changed, and neither is the newest message,
+// which the model has not seen yet. Messages are shrunk in place and never
+// removed, so indices (and pins) stay valid.
+type Manager struct {
+	MaxTokens int
+
+	mu     sync.Mutex
+	pinned map[int]bool
+}
+
+// New creates a manager; maxTokens <= 0 selects DefaultMaxTokens.
+func New(maxTokens int) *Manager {
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+	return &Manager{MaxTokens: maxTokens, pinned: map[int]bool{}}
+}
+
+// Pin protects the message at index i from eviction.
+func (m *Manager) Pin(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pinned[i] = true
+}
+
+// UnpinAll removes every pin.
+func (m *Manager) UnpinAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pinned = map[int]bool{}
+}
+
+// Pinned returns the number of pinned messages.
+func (m *Manager) Pinned() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pinned)
+}
+
+// EstimateTokens estimates the size of contents in tokens.
+func EstimateTokens(contents []api.Content) int {
+	total := 0
+	for _, c := range contents {
+		total += size(c)
+	}
+	return total / bytesPerToken
+}
+
+// EstimateText estimates the tokens of plain text.
+func EstimateText(s string) int {
+	return (len(s) + bytesPerToken - 1) / bytesPerToken
+}
+
+func size(c api.Content) int {
+	data, _ := json.Marshal(c)
+	return len(data)
+}
+
+// Fit shrinks contents in place until they fit the budget or nothing more
+// may be evicted, and returns the number of parts it changed. A nil Manager
+// does nothing.
+func (m *Manager) Fit(contents []api.Content) int {
+	if m == nil || len(contents) < 2 {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sizes := make([]int, len(contents))
+	total := 0
+	for i, c := range contents {
+		sizes[i] = size(c)
+		total += sizes[i]
+	}
+	budget := m.MaxTokens * bytesPerToken
+	if total <= budget {
+		return 0
+	}
+
+	final := finalReplies(contents)
+	changed := 0
+	for _, pass := range []struct {
+		role  string
+		evict func(*api.Part) bool
+	}{
+		{"user", evictBlob},
+		{"user", evictToolResult},
+		{"model", evictModelText},
+	} {
+		for i := 0; i < len(contents)-1 && total > budget; i++ {
+			c := contents[i]
+			if c.Role != pass.role || m.pinned[i] || final[i] || isUserMessage(c) {
+				continue
+			}
+			n := 0
+			for j := range c.Parts {
+				if pass.evict(&c.Parts[j]) {
+					n++
+				}
+			}
+			if n > 0 {
+				changed += n
+				newSize := size(c)
+				total += newSize - sizes[i]
+				sizes[i] = newSize
+			}
+		}
+	}
+	return changed
+}
+
+// isUserMessage reports whether c is something the user typed, as opposed
+// to tool results sent back with the user role.
+func isUserMessage(c api.Content) bool {
+	if c.Role != "user" {
+		return false
+	}
+	for _, p := range c.Parts {
+		if p.FunctionResp != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// finalReplies marks the last model message before each user message and at
+// the end: the answers the user actually saw.
+func finalReplies(contents []api.Content) map[int]bool {
+	final := map[int]bool{}
+	for i, c := range contents {
+		if c.Role != "model" {
+			continue
+		}
+		if i == len(contents)-1 || isUserMessage(contents[i+1]) {
+			final[i] = true
+		}
+	}
+	return final
+}
+
+func evictBlob(p *api.Part) bool {
+	if p.InlineData == nil {
+		return false
+	}
+	*p = api.Part{Text: fmt.Sprintf("[%s attachment removed from history]", p.InlineData.MimeType)}
+	return true
+}
+
+func evictToolResult(p *api.Part) bool {
+	if p.FunctionResp == nil || p.FunctionResp.Response["evicted"] == true {
+		return false
+	}
+	data, _ := json.Marshal(p.FunctionResp.Response)
+	if len(data) <= minEvictLen {
+		return false
+	}
+	p.FunctionResp = &api.FunctionResp{
+		Name: p.FunctionResp.Name,
+		Response: map[string]interface{}{
+			"evicted": true,
+			"preview": strings.ToValidUTF8(string(data[:previewLen]), ""),
+			"note":    "This result was removed from history to save space; run the tool again if you need it.",
+		},
+	}
+	return true
+}
+
+// evictModelText shortens the text of an intermediate model reply. Parts
+// with a thought signature are left alone, since the API validates them.
+func evictModelText(p *api.Part) bool {
+	if len(p.Text) <= minEvictLen || p.ThoughtSignature != "" {
+		return false
+	}
+	p.Text = strings.ToValidUTF8(p.Text[:previewLen], "") + " [...]"
+	return true
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package history
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+func toolResult(name string, n int) api.Content {
+	return api.Content{Role: "user", Parts: []api.Part{{FunctionResp: &api.FunctionResp{
+		Name:     name,
+		Response: map[string]interface{}{"output": strings.Repeat("x", n)},
+	}}}}
+}
+
+func TestFit(t *testing.T) {
+	call := func(name string) api.Content {
+		return api.Content{Role: "model", Parts: []api.Part{
+			{Text: strings.Repeat("thinking ", 300)},
+			{FunctionCall: &api.FunctionCall{Name: name}},
+		}}
+	}
+	contents := []api.Content{
+		{Role: "user", Parts: []api.Part{{Text: "first question"}}},
+		call("read_file"),
+		toolResult("read_file", 40_000),
+		{Role: "model", Parts: []api.Part{{Text: strings.Repeat("answer ", 500)}}},
+		{Role: "user", Parts: []api.Part{{Text: "second question"}}},
+		call("grep_search"),
+		toolResult("grep_search", 40_000),
+		call("read_file"),
+		{Role: "user", Parts: []api.Part{
+			{FunctionResp: &api.FunctionResp{Name: "read_file", Response: map[string]interface{}{"output": "img"}}},
+			{InlineData: &api.Blob{MimeType: "image/png", Data: strings.Repeat("A", 40_000)}},
+		}},
+	}
+	before := EstimateTokens(contents)
+
+	m := New(before - 5_000) // room for everything but one large result
+	m.Pin(6)
+	if n := m.Fit(contents); n == 0 {
+		t.Fatal("nothing evicted")
+	}
+	if EstimateTokens(contents) > m.MaxTokens {
+		t.Errorf("still over budget: %d > %d", EstimateTokens(contents), m.MaxTokens)
+	}
+	if contents[2].Parts[0].FunctionResp.Response["evicted"] != true {
+		t.Error("oldest tool result not evicted")
+	}
+	if contents[6].Parts[0].FunctionResp.Response["evicted"] == true {
+		t.Error("pinned tool result evicted")
+	}
+	if contents[8].Parts[1].InlineData == nil {
+		t.Error("newest message changed")
+	}
+	if contents[1].Parts[0].Text != strings.Repeat("thinking ", 300) {
+		t.Error("model text shortened although evicting tool results was enough")
+	}
+
+	// A tight budget shrinks intermediate model text but never user
+	// messages or final answers.
+	m = New(1)
+	m.Fit(contents)
+	if !strings.HasSuffix(contents[1].Parts[0].Text, "[...]") {
+		t.Error("intermediate model text not shortened")
+	}
+	if contents[0].Parts[0].Text != "first question" || contents[3].Parts[0].Text != strings.Repeat("answer ", 500) {
+		t.Error("user message or final answer changed")
+	}
+}
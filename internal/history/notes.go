@@ -0,0 +1,66 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package history
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// maxNotes bounds the notes proposed after one conversation.
+const maxNotes = 10
+
+const notesPrompt = `Below are a project's memory file, which is sent with every conversation about the project, and a conversation between a user and a coding assistant working in it. List the durable facts about the project that the conversation established and the memory file does not already say, which would help in future conversations:
+- build, test, lint and run commands that worked, with any flags or setup they need
+- where code, tests and configuration live and how they are laid out
+- conventions the code follows, and pitfalls that cost time
+Leave out the task itself and its progress, anything temporary or only guessed, and secrets. Reply with at most %d short Markdown bullets ("- "), one fact each, or NONE when there is nothing worth adding.
+
+<memory_file>
+%s
+</memory_file>
+
+<conversation>
+%s
+</conversation>`
+
+// ProposeNotes asks model which facts about the project the conversation
+// in req found that memoryText, the project's memory files, lacks. It
+// returns no notes when there is nothing worth adding.
+func ProposeNotes(ctx context.Context, p api.Provider, req *api.GenerateRequest, model, memoryText string) ([]string, error) {
+	resp, err := p.Generate(ctx, &api.GenerateRequest{
+		Model:        model,
+		Project:      req.Project,
+		UserPromptID: req.UserPromptID,
+		Request: api.InnerRequest{
+			Contents: []api.Content{{Role: "user", Parts: []api.Part{{Text: fmt.Sprintf(notesPrompt, maxNotes, memoryText, transcript(req.Request.Contents))}}}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("proposing project notes: %w", err)
+	}
+	var b strings.Builder
+	if len(resp.Response.Candidates) > 0 {
+		for _, part := range resp.Response.Candidates[0].Content.Parts {
+			b.WriteString(part.Text)
+		}
+	}
+	return parseNotes(b.String()), nil
+}
+
+// parseNotes returns the bullets of a reply to notesPrompt.
+func parseNotes(reply string) []string {
+	var notes []string
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		for _, bullet := range []string{"- ", "* "} {
+			if note, ok := strings.CutPrefix(line, bullet); ok && strings.TrimSpace(note) != "" && len(notes) < maxNotes {
+				notes = append(notes, strings.TrimSpace(note))
+			}
+		}
+	}
+	return notes
+}
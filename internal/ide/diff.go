@@ -0,0 +1,74 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package ide
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileState is a file's content before an edit; exists is false for a file
+// the edit creates.
+type fileState struct {
+	path    string
+	content string
+	exists  bool
+}
+
+func readFileState(path string) fileState {
+	data, err := os.ReadFile(path)
+	return fileState{path: path, content: string(data), exists: err == nil}
+}
+
+// toolPath returns the absolute file a tool call works on, or "".
+func toolPath(cwd string, args map[string]interface{}) string {
+	p, _ := args["file_path"].(string)
+	if p == "" {
+		return ""
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(cwd, p)
+	}
+	return filepath.Clean(p)
+}
+
+// diffContent is ACP tool call content that editors render as a diff. A
+// nil oldText marks a new file.
+func diffContent(before fileState, newText string) map[string]interface{} {
+	var oldText interface{}
+	if before.exists {
+		oldText = before.content
+	}
+	return map[string]interface{}{
+		"type":    "diff",
+		"path":    before.path,
+		"oldText": oldText,
+		"newText": newText,
+	}
+}
+
+// proposedText predicts the file content an edit tool call produces, so the
+// editor can show the diff when asking for permission. ok is false when it
+// cannot be predicted; the exact diff follows with the result.
+func proposedText(name string, before fileState, args map[string]interface{}) (string, bool) {
+	switch name {
+	case "write_file":
+		content, _ := args["content"].(string)
+		return content, true
+	case "replace":
+		oldString, _ := args["old_string"].(string)
+		newString, _ := args["new_string"].(string)
+		if !before.exists || oldString == "" || !strings.Contains(before.content, oldString) {
+			return "", false
+		}
+		if _, ranged := args["start_line"]; ranged {
+			return "", false
+		}
+		if _, ranged := args["end_line"]; ranged {
+			return "", false
+		}
+		return strings.ReplaceAll(before.content, oldString, newString), true
+	}
+	return "", false
+}
@@ -0,0 +1,513 @@
+// Package ide serves g to editors such as Zed over stdio using the Agent
+// Client Protocol (ACP): newline-delimited JSON-RPC 2.0 with session/new,
+// session/prompt and session/cancel requests from the editor, session/update
+// notifications for streamed text and tool calls (with diffs for file
+// edits), and session/request_permission requests for tool approval.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package ide
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/approval"
+	"github.com/k-sub1995/g/internal/output"
+)
+
+// ProtocolVersion is the ACP version this package implements.
+const ProtocolVersion = 1
+
+// Options configures a Server.
+type Options struct {
+	// NewSession creates the agent loop and initial request for a session
+	// rooted at cwd. The loop must report through f and ask p before
+	// running tools that need approval.
+	NewSession func(cwd string, f output.Formatter, p approval.Prompter) (*agent.Loop, *api.GenerateRequest, error)
+
+	Debug bool
+}
+
+// Server handles one editor connection.
+type Server struct {
+	opts Options
+	conn *conn
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// New creates a server.
+func New(opts Options) *Server {
+	return &Server{opts: opts, sessions: map[string]*session{}}
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until r is closed or ctx is done. Requests run concurrently so that a
+// long prompt does not block cancellation or permission responses.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newConn(w)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	scanner := newScanner(r)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var m message
+		if err := json.Unmarshal(line, &m); err != nil {
+			null := json.RawMessage("null")
+			_ = s.conn.reply(&null, nil, &rpcError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+		switch {
+		case m.Method == "" && m.ID != nil:
+			s.conn.deliver(&m)
+		case m.ID == nil:
+			s.handleNotification(&m)
+		default:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := s.handleRequest(ctx, &m)
+				if err := s.conn.reply(m.ID, result, err); err != nil && s.opts.Debug {
+					fmt.Fprintf(os.Stderr, "[ide] failed to reply to %s: %v\n", m.Method, err)
+				}
+			}()
+		}
+	}
+	cancel()
+	s.conn.closePending()
+	wg.Wait()
+	return scanner.Err()
+}
+
+func (s *Server) handleRequest(ctx context.Context, m *message) (interface{}, error) {
+	if s.opts.Debug {
+		fmt.Fprintf(os.Stderr, "[ide] <- %s\n", m.Method)
+	}
+	switch m.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"agentCapabilities": map[string]interface{}{
+				"loadSession": false,
+				"promptCapabilities": map[string]interface{}{
+					"image":           true,
+					"embeddedContext": true,
+				},
+			},
+			"authMethods": []interface{}{},
+		}, nil
+	case "authenticate":
+		// Credentials come from the Gemini CLI login or ANTHROPIC_API_KEY.
+		return map[string]interface{}{}, nil
+	case "session/new":
+		return s.newSession(m.Params)
+	case "session/prompt":
+		return s.prompt(ctx, m.Params)
+	case "session/cancel":
+		s.handleNotification(m)
+		return nil, nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + m.Method}
+	}
+}
+
+func (s *Server) handleNotification(m *message) {
+	switch m.Method {
+	case "session/cancel":
+		var p struct {
+			SessionID string `json:"sessionId"`
+		}
+		if json.Unmarshal(m.Params, &p) == nil {
+			if sess := s.session(p.SessionID); sess != nil {
+				sess.stop()
+			}
+		}
+	default:
+		if s.opts.Debug {
+			fmt.Fprintf(os.Stderr, "[ide] ignoring notification %s\n", m.Method)
+		}
+	}
+}
+
+func (s *Server) session(id string) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[id]
+}
+
+func (s *Server) newSession(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Cwd string `json:"cwd"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	if !filepath.IsAbs(p.Cwd) {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "cwd must be an absolute path"}
+	}
+	if info, err := os.Stat(p.Cwd); err != nil || !info.IsDir() {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "cwd is not a directory: " + p.Cwd}
+	}
+
+	sess := &session{id: newSessionID(), cwd: p.Cwd, conn: s.conn}
+	loop, req, err := s.opts.NewSession(p.Cwd, sess, sess)
+	if err != nil {
+		return nil, err
+	}
+	sess.loop, sess.req = loop, req
+
+	s.mu.Lock()
+	s.sessions[sess.id] = sess
+	s.mu.Unlock()
+	return map[string]string{"sessionId": sess.id}, nil
+}
+
+func (s *Server) prompt(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p struct {
+		SessionID string         `json:"sessionId"`
+		Prompt    []contentBlock `json:"prompt"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	sess := s.session(p.SessionID)
+	if sess == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown session: " + p.SessionID}
+	}
+	parts, err := convertPrompt(p.Prompt)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	if !sess.running.TryLock() {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "a prompt is already running in this session"}
+	}
+	defer sess.running.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sess.mu.Lock()
+	sess.cancel = cancel
+	sess.mu.Unlock()
+
+	req := sess.req
+	n := len(req.Request.Contents)
+	req.Request.Contents = append(req.Request.Contents, api.Content{Role: "user", Parts: parts})
+	if err := sess.loop.Run(ctx, req); err != nil {
+		// Drop the unfinished exchange so the history stays valid.
+		req.Request.Contents = req.Request.Contents[:n]
+		if ctx.Err() != nil {
+			return map[string]string{"stopReason": "cancelled"}, nil
+		}
+		return nil, err
+	}
+	return map[string]string{"stopReason": "end_turn"}, nil
+}
+
+// contentBlock is an ACP prompt content block.
+type contentBlock struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	URI      string            `json:"uri,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Resource *embeddedResource `json:"resource,omitempty"`
+}
+
+type embeddedResource struct {
+	URI      string `json:"uri"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// convertPrompt turns ACP content blocks into request parts. Linked files
+// are named so the model can read them with its tools; embedded resources
+// are inlined.
+func convertPrompt(blocks []contentBlock) ([]api.Part, error) {
+	var parts []api.Part
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			parts = append(parts, api.Part{Text: b.Text})
+		case "image":
+			parts = append(parts, api.Part{InlineData: &api.Blob{MimeType: b.MimeType, Data: b.Data}})
+		case "resource_link":
+			parts = append(parts, api.Part{Text: "Referenced file: " + uriPath(b.URI)})
+		case "resource":
+			if b.Resource == nil {
+				return nil, fmt.Errorf("resource block without resource")
+			}
+			if b.Resource.Blob != "" {
+				parts = append(parts, api.Part{InlineData: &api.Blob{MimeType: b.Resource.MimeType, Data: b.Resource.Blob}})
+				continue
+			}
+			parts = append(parts, api.Part{Text: fmt.Sprintf("Content of %s:\n```\n%s\n```", uriPath(b.Resource.URI), b.Resource.Text)})
+		default:
+			return nil, fmt.Errorf("unsupported content block type %q", b.Type)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty prompt")
+	}
+	return parts, nil
+}
+
+func uriPath(uri string) string {
+	return strings.TrimPrefix(uri, "file://")
+}
+
+// session is one editor conversation. It is the output.Formatter and
+// approval.Prompter of its agent loop, turning loop output into
+// session/update notifications and approvals into permission requests.
+type session struct {
+	id   string
+	cwd  string
+	conn *conn
+	loop *agent.Loop
+	req  *api.GenerateRequest
+
+	running sync.Mutex // held while a prompt runs
+
+	mu         sync.Mutex
+	cancel     context.CancelFunc
+	toolCalls  int
+	toolCallID string // the tool call being approved or run
+	toolCall   map[string]interface{}
+	before     *fileState // the file an edit tool call changes
+}
+
+// stop cancels the running prompt, if any.
+func (s *session) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+func (s *session) update(update map[string]interface{}) {
+	_ = s.conn.notify("session/update", map[string]interface{}{
+		"sessionId": s.id,
+		"update":    update,
+	})
+}
+
+func (s *session) text(text string) {
+	if text == "" {
+		return
+	}
+	s.update(map[string]interface{}{
+		"sessionUpdate": "agent_message_chunk",
+		"content":       map[string]string{"type": "text", "text": text},
+	})
+}
+
+func (s *session) WriteResponse(resp *api.GenerateResponse) error {
+	for _, cand := range resp.Response.Candidates {
+		for _, part := range cand.Content.Parts {
+			s.text(part.Text)
+		}
+	}
+	return nil
+}
+
+func (s *session) WriteStreamEvent(event *api.StreamEvent) error {
+	if event.Type == "content" {
+		s.text(event.Text)
+	}
+	return nil
+}
+
+func (s *session) WriteError(err error) error {
+	s.text("\nError: " + err.Error())
+	return nil
+}
+
+func (s *session) WriteToolCall(name string, args map[string]interface{}) error {
+	s.mu.Lock()
+	s.toolCalls++
+	s.toolCallID = fmt.Sprintf("call_%d", s.toolCalls)
+	s.toolCall = map[string]interface{}{
+		"toolCallId": s.toolCallID,
+		"title":      toolTitle(name, args),
+		"kind":       toolKind(name),
+		"status":     "pending",
+		"rawInput":   args,
+	}
+	s.before = nil
+	if path := toolPath(s.cwd, args); path != "" {
+		// Editors follow along by opening the file
+		s.toolCall["locations"] = []interface{}{map[string]string{"path": path}}
+		if toolKind(name) == "edit" {
+			before := readFileState(path)
+			s.before = &before
+			if newText, ok := proposedText(name, before, args); ok {
+				s.toolCall["content"] = []interface{}{diffContent(before, newText)}
+			}
+		}
+	}
+	update := map[string]interface{}{"sessionUpdate": "tool_call"}
+	for k, v := range s.toolCall {
+		update[k] = v
+	}
+	s.mu.Unlock()
+	s.update(update)
+	return nil
+}
+
+func (s *session) WriteToolResult(name string, result map[string]interface{}, isError bool) error {
+	s.mu.Lock()
+	id, before := s.toolCallID, s.before
+	s.mu.Unlock()
+
+	update := map[string]interface{}{
+		"sessionUpdate": "tool_call_update",
+		"toolCallId":    id,
+		"status":        "completed",
+		"rawOutput":     result,
+	}
+	if !isError && before != nil {
+		// The exact change, which the proposal may only have predicted
+		if after, err := os.ReadFile(before.path); err == nil {
+			update["content"] = []interface{}{diffContent(*before, string(after))}
+		}
+	}
+	if isError {
+		update["status"] = "failed"
+		if msg, ok := result["error"].(string); ok {
+			update["content"] = []interface{}{map[string]interface{}{
+				"type":    "content",
+				"content": map[string]string{"type": "text", "text": msg},
+			}}
+		}
+	}
+	s.update(update)
+	return nil
+}
+
+// WriteProgress shows waits as thought chunks, which editors render apart
+// from the answer.
+func (s *session) WriteProgress(stage, detail string) error {
+	s.update(map[string]interface{}{
+		"sessionUpdate": "agent_thought_chunk",
+		"content":       map[string]string{"type": "text", "text": detail + "\n"},
+	})
+	return nil
+}
+
+// permissionOptions maps approval decisions to ACP permission options.
+var permissionOptions = map[approval.Decision]map[string]string{
+	approval.AllowOnce:    {"optionId": "allow_once", "name": "Allow once", "kind": "allow_once"},
+	approval.AllowSession: {"optionId": "allow_session", "name": "Allow for this session", "kind": "allow_always"},
+	approval.AllowAlways:  {"optionId": "allow_always", "name": "Always allow", "kind": "allow_always"},
+	approval.TrustServer:  {"optionId": "trust_server", "name": "Trust this server for the session", "kind": "allow_always"},
+	approval.Deny:         {"optionId": "reject", "name": "Reject", "kind": "reject_once"},
+}
+
+// Confirm implements approval.Prompter with a session/request_permission
+// request to the editor.
+func (s *session) Confirm(ctx context.Context, req approval.Request) (approval.Decision, error) {
+	decisions := append([]approval.Decision{}, req.Options...)
+	if len(decisions) == 0 {
+		decisions = []approval.Decision{approval.AllowOnce}
+	}
+	decisions = append(decisions, approval.Deny)
+	var options []map[string]string
+	byID := map[string]approval.Decision{}
+	for _, d := range decisions {
+		opt := permissionOptions[d]
+		options = append(options, opt)
+		byID[opt["optionId"]] = d
+	}
+
+	s.mu.Lock()
+	toolCall := map[string]interface{}{}
+	for k, v := range s.toolCall {
+		toolCall[k] = v
+	}
+	s.mu.Unlock()
+	if req.Reason != "" {
+		content, _ := toolCall["content"].([]interface{})
+		toolCall["content"] = append([]interface{}{map[string]interface{}{
+			"type":    "content",
+			"content": map[string]string{"type": "text", "text": "⚠ " + req.Reason},
+		}}, content...)
+	}
+
+	var resp struct {
+		Outcome struct {
+			Outcome  string `json:"outcome"`
+			OptionID string `json:"optionId"`
+		} `json:"outcome"`
+	}
+	err := s.conn.call(ctx, "session/request_permission", map[string]interface{}{
+		"sessionId": s.id,
+		"toolCall":  toolCall,
+		"options":   options,
+	}, &resp)
+	if err != nil {
+		var rerr *rpcError
+		if errors.As(err, &rerr) && rerr.Code == codeMethodNotFound {
+			return approval.Deny, approval.ErrNoTerminal
+		}
+		return approval.Deny, err
+	}
+	if resp.Outcome.Outcome != "selected" {
+		return approval.Deny, nil
+	}
+	return byID[resp.Outcome.OptionID], nil
+}
+
+// toolTitle is a short human-readable label for a tool call.
+func toolTitle(name string, args map[string]interface{}) string {
+	for _, key := range []string{"command", "file_path", "dir_path", "pattern", "url", "query", "skill_name"} {
+		if v, ok := args[key].(string); ok && v != "" {
+			return name + ": " + v
+		}
+	}
+	return name
+}
+
+// toolKind classifies a tool for the editor's UI.
+func toolKind(name string) string {
+	switch name {
+	case "read_file", "read_many_files", "get_outline", "list_directory":
+		return "read"
+	case "write_file", "replace", "insert":
+		return "edit"
+	case "glob", "grep_search", "google_web_search":
+		return "search"
+	case "run_shell_command":
+		return "execute"
+	case "web_fetch":
+		return "fetch"
+	default:
+		return "other"
+	}
+}
+
+func newSessionID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "sess_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package ide
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/approval"
+	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// scriptedProvider asks to write a file on the first turn and answers with
+// text once the tool result is in the history.
+type scriptedProvider struct{}
+
+func (scriptedProvider) Generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (scriptedProvider) GenerateStream(ctx context.Context, req *api.GenerateRequest) (<-chan api.StreamEvent, error) {
+	ch := make(chan api.StreamEvent, 2)
+	last := req.Request.Contents[len(req.Request.Contents)-1]
+	if last.Parts[0].FunctionResp != nil {
+		ch <- api.StreamEvent{Type: "content", Text: "done"}
+	} else {
+		ch <- api.StreamEvent{Type: "tool_call", ToolCall: &api.FunctionCall{
+			Name: "write_file",
+			Args: map[string]interface{}{"file_path": "out.txt", "content": "hi"},
+		}}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+	srv := New(Options{
+		NewSession: func(cwd string, f output.Formatter, p approval.Prompter) (*agent.Loop, *api.GenerateRequest, error) {
+			registry := tools.NewRegistry(tools.RegistryOptions{WorkDir: cwd})
+			loop := agent.NewLoop(scriptedProvider{}, registry, nil, f, agent.Config{
+				MaxTurns:     5,
+				Streaming:    true,
+				Prompter:     p,
+				ConfirmTools: []string{"write_file"},
+			})
+			return loop, &api.GenerateRequest{Model: "test"}, nil
+		},
+	})
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go func() {
+		_ = srv.Serve(ctx, serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	send := func(v string) {
+		if _, err := io.WriteString(clientOut, v+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := bufio.NewScanner(clientIn)
+	next := func() map[string]interface{} {
+		if !lines.Scan() {
+			t.Fatalf("connection closed: %v", lines.Err())
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(lines.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":1}}`)
+	if m := next(); m["result"].(map[string]interface{})["protocolVersion"] != float64(ProtocolVersion) {
+		t.Fatalf("initialize: %v", m)
+	}
+
+	params, _ := json.Marshal(map[string]string{"cwd": dir})
+	send(fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"session/new","params":%s}`, params))
+	sessionID := next()["result"].(map[string]interface{})["sessionId"].(string)
+
+	send(fmt.Sprintf(`{"jsonrpc":"2.0","id":3,"method":"session/prompt","params":{"sessionId":%q,"prompt":[{"type":"text","text":"write it"}]}}`, sessionID))
+
+	var updates []string
+	for {
+		m := next()
+		switch m["method"] {
+		case "session/update":
+			update := m["params"].(map[string]interface{})["update"].(map[string]interface{})
+			updates = append(updates, update["sessionUpdate"].(string))
+		case "session/request_permission":
+			p := m["params"].(map[string]interface{})
+			toolCall := p["toolCall"].(map[string]interface{})
+			if toolCall["toolCallId"] != "call_1" {
+				t.Errorf("permission for unexpected tool call: %v", p)
+			}
+			// The proposed new file is shown as a diff against nothing
+			content, _ := toolCall["content"].([]interface{})
+			if len(content) != 1 {
+				t.Fatalf("permission content = %v, want a diff", toolCall["content"])
+			}
+			diff := content[0].(map[string]interface{})
+			if diff["type"] != "diff" || diff["path"] != filepath.Join(dir, "out.txt") || diff["oldText"] != nil || diff["newText"] != "hi" {
+				t.Errorf("proposed diff = %v", diff)
+			}
+			id, _ := json.Marshal(m["id"])
+			send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":{"outcome":{"outcome":"selected","optionId":"allow_once"}}}`, id))
+		default:
+			if m["id"] != float64(3) {
+				t.Fatalf("unexpected message: %v", m)
+			}
+			if reason := m["result"].(map[string]interface{})["stopReason"]; reason != "end_turn" {
+				t.Fatalf("stopReason = %v, error = %v", reason, m["error"])
+			}
+			want := []string{"tool_call", "tool_call_update", "agent_message_chunk"}
+			if fmt.Sprint(updates) != fmt.Sprint(want) {
+				t.Errorf("updates = %v, want %v", updates, want)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+			if err != nil || string(data) != "hi" {
+				t.Errorf("file not written after approval: %q, %v", data, err)
+			}
+			return
+		}
+	}
+}
+
+func TestConvertPrompt(t *testing.T) {
+	parts, err := convertPrompt([]contentBlock{
+		{Type: "text", Text: "Explain"},
+		{Type: "resource_link", URI: "file:///src/main.go", Name: "main.go"},
+		{Type: "resource", Resource: &embeddedResource{URI: "file:///a.txt", Text: "abc"}},
+		{Type: "image", MimeType: "image/png", Data: "AAAA"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 4 || parts[1].Text != "Referenced file: /src/main.go" || parts[3].InlineData == nil {
+		t.Errorf("unexpected parts: %+v", parts)
+	}
+	if _, err := convertPrompt([]contentBlock{{Type: "audio"}}); err == nil {
+		t.Error("expected error for unsupported block")
+	}
+}
+
+func TestProposedText(t *testing.T) {
+	before := fileState{path: "/a.go", content: "x := 1\ny := 1\n", exists: true}
+	got, ok := proposedText("replace", before, map[string]interface{}{"old_string": "y := 1", "new_string": "y := 2"})
+	if !ok || got != "x := 1\ny := 2\n" {
+		t.Errorf("replace proposal = %q, %v", got, ok)
+	}
+	if _, ok := proposedText("replace", before, map[string]interface{}{"old_string": "z", "new_string": "w"}); ok {
+		t.Error("proposal for old_string that is not in the file")
+	}
+	if _, ok := proposedText("insert", before, map[string]interface{}{"content": "z"}); ok {
+		t.Error("insert proposals are not predicted")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package ide
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxMessageBytes bounds a single JSON-RPC message (images are inline).
+const maxMessageBytes = 32 << 20
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// message is any JSON-RPC 2.0 message: a request (ID and Method), a
+// notification (Method only) or a response (ID with Result or Error).
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// handlerFunc answers a request. The returned value is sent as the result;
+// an *rpcError is sent as-is and other errors as internal errors.
+type handlerFunc func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// conn is a JSON-RPC connection over newline-delimited JSON. Both sides may
+// send requests; responses are matched to outgoing requests by ID.
+type conn struct {
+	w  io.Writer
+	mu sync.Mutex // serializes writes
+
+	pendingMu sync.Mutex
+	nextID    int64
+	pending   map[int64]chan *message
+}
+
+func newConn(w io.Writer) *conn {
+	return &conn{w: w, pending: map[int64]chan *message{}}
+}
+
+// write sends one message followed by a newline.
+func (c *conn) write(m *message) error {
+	m.JSONRPC = "2.0"
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.w.Write(append(data, '\n'))
+	return err
+}
+
+// notify sends a notification.
+func (c *conn) notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(&message{Method: method, Params: raw})
+}
+
+// call sends a request to the client and waits for its response.
+func (c *conn) call(ctx context.Context, method string, params, result interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	ch := make(chan *message, 1)
+	c.pendingMu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}()
+
+	idRaw := json.RawMessage(fmt.Sprintf("%d", id))
+	if err := c.write(&message{ID: &idRaw, Method: method, Params: raw}); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case resp, ok := <-ch:
+		if !ok {
+			return io.ErrUnexpectedEOF
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	}
+}
+
+// deliver routes a response to the call waiting for it.
+func (c *conn) deliver(m *message) {
+	var id int64
+	if err := json.Unmarshal(*m.ID, &id); err != nil {
+		return
+	}
+	c.pendingMu.Lock()
+	ch, ok := c.pending[id]
+	c.pendingMu.Unlock()
+	if ok {
+		ch <- m
+	}
+}
+
+// closePending fails every outstanding call once the client has gone away.
+func (c *conn) closePending() {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+}
+
+// reply sends the response to a request.
+func (c *conn) reply(id *json.RawMessage, result interface{}, err error) error {
+	m := &message{ID: id}
+	if err != nil {
+		rerr, ok := err.(*rpcError)
+		if !ok {
+			rerr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		m.Error = rerr
+	} else {
+		raw, merr := json.Marshal(result)
+		if merr != nil {
+			return merr
+		}
+		m.Result = raw
+	}
+	return c.write(m)
+}
+
+// newScanner returns a line scanner sized for large messages.
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+	return scanner
+}
@@ -8,6 +8,8 @@ import (
 	"io"
 	"os"
 	"strings"
+
+	"github.com/k-sub1995/g/internal/history"
 )
 
 // ReadStdin reads from stdin if available
@@ -29,52 +31,72 @@ func ReadStdin() (string, error) {
 	return "", nil
 }
 
+// Source is one part of the prepared input and its estimated size.
+type Source struct {
+	Name   string // "stdin", a file path or "prompt"
+	Tokens int
+}
+
 // ReadFiles reads content from multiple files
 func ReadFiles(paths []string) (string, error) {
+	content, _, err := readFiles(paths)
+	return content, err
+}
+
+// readFiles is ReadFiles, also returning each file as a Source.
+func readFiles(paths []string) (string, []Source, error) {
 	if len(paths) == 0 {
-		return "", nil
+		return "", nil, nil
 	}
 
 	var builder strings.Builder
+	var sources []Source
 	for _, path := range paths {
 		content, err := os.ReadFile(path)
 		if err != nil {
-			return "", fmt.Errorf("failed to read file %s: %w", path, err)
+			return "", nil, fmt.Errorf("failed to read file %s: %w", path, err)
 		}
+		start := builder.Len()
 		builder.WriteString(fmt.Sprintf("=== %s ===\n", path))
 		builder.Write(content)
 		builder.WriteString("\n\n")
+		sources = append(sources, Source{Name: path, Tokens: history.EstimateText(builder.String()[start:])})
 	}
 
-	return builder.String(), nil
+	return builder.String(), sources, nil
 }
 
-// PrepareInput combines stdin, files, and prompt into a single input
-func PrepareInput(prompt string, files []string) (string, error) {
+// PrepareInput combines stdin, files, and prompt into a single input. The
+// sources list the parts with their estimated tokens.
+func PrepareInput(prompt string, files []string) (string, []Source, error) {
 	var parts []string
+	var sources []Source
 
 	// Read stdin
 	stdin, err := ReadStdin()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	if stdin != "" {
 		parts = append(parts, stdin)
+		sources = append(sources, Source{Name: "stdin", Tokens: history.EstimateText(stdin)})
 	}
 
 	// Read files
-	filesContent, err := ReadFiles(files)
+	filesContent, fileSources, err := readFiles(files)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	if filesContent != "" {
 		parts = append(parts, filesContent)
+		sources = append(sources, fileSources...)
 	}
 
 	// Add prompt
 	if prompt != "" {
 		parts = append(parts, prompt)
+		sources = append(sources, Source{Name: "prompt", Tokens: history.EstimateText(prompt)})
 	}
 
-	return strings.Join(parts, "\n\n"), nil
+	return strings.Join(parts, "\n\n"), sources, nil
 }
@@ -0,0 +1,29 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrepareInputSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.txt")
+	os.WriteFile(path, []byte(strings.Repeat("x", 4000)), 0644)
+
+	text, sources, err := PrepareInput("summarize", []string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "=== "+path+" ===") || !strings.HasSuffix(text, "summarize") {
+		t.Errorf("input = %.60q...", text)
+	}
+	if len(sources) != 2 || sources[0].Name != path || sources[1].Name != "prompt" {
+		t.Fatalf("sources = %+v", sources)
+	}
+	if tokens := sources[0].Tokens; tokens < 1000 || tokens > 1100 {
+		t.Errorf("file tokens = %d, want about 1000", tokens)
+	}
+}
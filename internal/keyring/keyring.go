@@ -0,0 +1,185 @@
+// Package keyring provides secret storage for g.
+// Secrets are kept in the OS keychain when one is available, falling back
+// to a 0600 file under ~/.gemini.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package keyring
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+const fallbackFile = "g_secrets.json"
+
+// ErrNotFound is returned when no secret exists for the requested key.
+var ErrNotFound = errors.New("secret not found")
+
+var fileMu sync.Mutex
+
+// Backend is an OS keychain.
+type Backend interface {
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+	Delete(service, account string) error
+}
+
+// Native is the OS keychain Get and Set try before the file: the macOS
+// Keychain, Windows Credential Manager or the Secret Service on Linux.
+// Tests replace it.
+var Native Backend = osKeychain{}
+
+// osKeychain is the platform's keychain.
+type osKeychain struct{}
+
+var errNoKeychain = errors.New("no OS keychain")
+
+func nativeGet(service, account string) (string, error) {
+	if Native == nil {
+		return "", errNoKeychain
+	}
+	return Native.Get(service, account)
+}
+
+func nativeSet(service, account, secret string) error {
+	if Native == nil {
+		return errNoKeychain
+	}
+	return Native.Set(service, account, secret)
+}
+
+func nativeDelete(service, account string) error {
+	if Native == nil {
+		return errNoKeychain
+	}
+	return Native.Delete(service, account)
+}
+
+// Get returns the secret stored for service/account.
+func Get(service, account string) (string, error) {
+	if v, err := nativeGet(service, account); err == nil {
+		return v, nil
+	}
+	return fileGet(service, account)
+}
+
+// Set stores a secret for service/account.
+func Set(service, account, secret string) error {
+	if err := nativeSet(service, account, secret); err == nil {
+		// Drop any stale copy left in the fallback file.
+		_ = fileDelete(service, account)
+		return nil
+	}
+	return fileSet(service, account, secret)
+}
+
+// SetNative stores a secret in the OS keychain only, failing when none is
+// available instead of falling back to the file.
+func SetNative(service, account, secret string) error {
+	if err := nativeSet(service, account, secret); err != nil {
+		return err
+	}
+	_ = fileDelete(service, account)
+	return nil
+}
+
+// Delete removes the secret for service/account from every backend.
+func Delete(service, account string) error {
+	nativeErr := nativeDelete(service, account)
+	fileErr := fileDelete(service, account)
+	if nativeErr != nil && fileErr != nil {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func filePath() (string, error) {
+	dir, err := config.GeminiDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fallbackFile), nil
+}
+
+// fileStore maps service -> account -> secret.
+type fileStore map[string]map[string]string
+
+func readFileStore() (fileStore, string, error) {
+	path, err := filePath()
+	if err != nil {
+		return nil, "", err
+	}
+	store := fileStore{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, path, nil
+		}
+		return nil, "", err
+	}
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, "", err
+	}
+	return store, path, nil
+}
+
+func writeFileStore(path string, store fileStore) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func fileGet(service, account string) (string, error) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	store, _, err := readFileStore()
+	if err != nil {
+		return "", err
+	}
+	v, ok := store[service][account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func fileSet(service, account, secret string) error {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	store, path, err := readFileStore()
+	if err != nil {
+		return err
+	}
+	if store[service] == nil {
+		store[service] = map[string]string{}
+	}
+	store[service][account] = secret
+	return writeFileStore(path, store)
+}
+
+func fileDelete(service, account string) error {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	store, path, err := readFileStore()
+	if err != nil {
+		return err
+	}
+	if _, ok := store[service][account]; !ok {
+		return ErrNotFound
+	}
+	delete(store[service], account)
+	if len(store[service]) == 0 {
+		delete(store, service)
+	}
+	return writeFileStore(path, store)
+}
@@ -0,0 +1,50 @@
+//go:build darwin
+
+// Keychain backend for macOS
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package keyring
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func (osKeychain) Get(service, account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password",
+		"-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", ErrNotFound
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// Set runs add-generic-password in security's interactive mode,
+// which reads the command from stdin, so the secret is not in the argument
+// list other users can see. -X gives it in hex, which needs no quoting; -U
+// updates the item if it already exists.
+func (osKeychain) Set(service, account, secret string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+		quote(service), quote(account), hex.EncodeToString([]byte(secret))))
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	// Interactive mode exits 0 even when the command failed
+	if got, err := (osKeychain{}).Get(service, account); err != nil || got != secret {
+		return fmt.Errorf("security: the keychain item was not stored")
+	}
+	return nil
+}
+
+// quote quotes an argument for security's interactive mode.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (osKeychain) Delete(service, account string) error {
+	return exec.Command("security", "delete-generic-password",
+		"-s", service, "-a", account).Run()
+}
@@ -0,0 +1,54 @@
+//go:build !darwin && !windows
+
+// Secret Service backend for Linux and other Unix systems (via
+// secret-tool); without it secrets go to the file fallback.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package keyring
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+var errUnsupported = errors.New("keyring not supported on this platform")
+
+func secretTool() (string, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return "", errUnsupported
+	}
+	return path, nil
+}
+
+func (osKeychain) Get(service, account string) (string, error) {
+	bin, err := secretTool()
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(bin, "lookup", "service", service, "account", account).Output()
+	if err != nil || len(out) == 0 {
+		return "", ErrNotFound
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (osKeychain) Set(service, account, secret string) error {
+	bin, err := secretTool()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(bin, "store", "--label", service+" ("+account+")",
+		"service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	return cmd.Run()
+}
+
+func (osKeychain) Delete(service, account string) error {
+	bin, err := secretTool()
+	if err != nil {
+		return err
+	}
+	return exec.Command(bin, "clear", "service", service, "account", account).Run()
+}
@@ -4,23 +4,19 @@
 package mcp
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"os"
-	"os/exec"
 	"sync"
 	"sync/atomic"
+
+	"github.com/k-sub1995/g/internal/config"
 )
 
-// Client is an MCP client using stdio transport
+// Client is an MCP client. The underlying transport is stdio for local
+// servers and Streamable HTTP or SSE for remote ones.
 type Client struct {
-	cmd       *exec.Cmd
-	stdin     io.WriteCloser
-	stdout    io.ReadCloser
-	scanner   *bufio.Scanner
+	transport transport
 	requestID atomic.Int64
 	mu        sync.Mutex
 
@@ -48,6 +44,7 @@ type jsonRPCRequest struct {
 type jsonRPCResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      int64           `json:"id"`
+	Method  string          `json:"method,omitempty"` // set on server-initiated messages
 	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *jsonRPCError   `json:"error,omitempty"`
 }
@@ -57,46 +54,61 @@ type jsonRPCError struct {
 	Message string `json:"message"`
 }
 
-// NewClient creates a new MCP client
-func NewClient(command string, args []string, env map[string]string, cwd string) (*Client, error) {
-	cmd := exec.Command(command, args...)
-
-	// Set working directory
-	if cwd != "" {
-		cmd.Dir = cwd
-	}
-
-	// Set environment
-	cmd.Env = os.Environ()
-	for k, v := range env {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
-	}
+// transport moves JSON-RPC messages between the client and a server.
+type transport interface {
+	// call sends a request and returns the raw response with the given id.
+	call(ctx context.Context, id int64, msg []byte) ([]byte, error)
+	// notify sends a message that expects no response.
+	notify(ctx context.Context, msg []byte) error
+	close() error
+}
 
-	stdin, err := cmd.StdinPipe()
+// NewClient creates a new MCP client using stdio transport
+func NewClient(command string, args []string, env map[string]string, cwd string) (*Client, error) {
+	t, err := newStdioTransport(command, args, env, cwd)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stdin pipe: %w", err)
+		return nil, err
 	}
+	return &Client{transport: t}, nil
+}
 
-	stdout, err := cmd.StdoutPipe()
+// NewClientFromConfig creates a client for a configured server, selecting
+// stdio, Streamable HTTP, or SSE transport from the config.
+func NewClientFromConfig(serverName string, cfg config.MCPServerConfig) (*Client, error) {
+	cfg, err := interpolate(serverName, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stdout pipe: %w", err)
+		return nil, err
+	}
+	if cfg.Command != "" {
+		return NewClient(cfg.Command, cfg.Args, cfg.Env, cfg.CWD)
 	}
 
-	// Redirect stderr to our stderr for debugging
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start MCP server: %w", err)
+	url := cfg.HTTPURL
+	kind := "http"
+	if url == "" {
+		url = cfg.URL
+		kind = cfg.Type
+		if kind == "" {
+			kind = "sse"
+		}
+	}
+	if url == "" {
+		return nil, fmt.Errorf("server %s: no command or url configured", serverName)
 	}
 
-	client := &Client{
-		cmd:     cmd,
-		stdin:   stdin,
-		stdout:  stdout,
-		scanner: bufio.NewScanner(stdout),
+	var auth *oauthProvider
+	if cfg.OAuth == nil || cfg.OAuth.Enabled == nil || *cfg.OAuth.Enabled {
+		auth = newOAuthProvider(serverName, url, cfg.OAuth)
 	}
 
-	return client, nil
+	switch kind {
+	case "http":
+		return &Client{transport: newHTTPTransport(url, cfg.Headers, auth)}, nil
+	case "sse":
+		return &Client{transport: newSSETransport(url, cfg.Headers, auth)}, nil
+	default:
+		return nil, fmt.Errorf("server %s: unknown transport type %q", serverName, kind)
+	}
 }
 
 // Initialize performs the MCP initialization handshake
@@ -154,8 +166,47 @@ func (c *Client) Initialize(ctx context.Context) error {
 	return nil
 }
 
-// CallTool calls an MCP tool
+// CallResult is the decoded result of a tool call. Text blocks (and text
+// resources) are concatenated into Text; binary content is kept in Blobs.
+type CallResult struct {
+	Text  string
+	Blobs []Blob
+}
+
+// Blob is a binary content block (image, audio, or blob resource).
+type Blob struct {
+	MimeType string
+	Data     string // base64-encoded
+	URI      string // set for embedded resources
+}
+
+// contentBlock is an MCP tool result content item.
+type contentBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Resource *struct {
+		URI      string `json:"uri"`
+		MimeType string `json:"mimeType,omitempty"`
+		Text     string `json:"text,omitempty"`
+		Blob     string `json:"blob,omitempty"`
+	} `json:"resource,omitempty"`
+}
+
+// CallTool calls an MCP tool and returns its text content
 func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (string, error) {
+	result, err := c.CallToolResult(ctx, name, args)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// CallToolResult calls an MCP tool and returns text and binary content
+func (c *Client) CallToolResult(ctx context.Context, name string, args map[string]interface{}) (*CallResult, error) {
 	params := map[string]interface{}{
 		"name":      name,
 		"arguments": args,
@@ -163,44 +214,58 @@ func (c *Client) CallTool(ctx context.Context, name string, args map[string]inte
 
 	result, err := c.call(ctx, "tools/call", params)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var callResult struct {
-		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text,omitempty"`
-		} `json:"content"`
-		IsError bool `json:"isError,omitempty"`
+		Content []contentBlock `json:"content"`
+		IsError bool           `json:"isError,omitempty"`
 	}
 
 	if err := json.Unmarshal(result, &callResult); err != nil {
-		return "", fmt.Errorf("failed to parse tool result: %w", err)
+		return nil, fmt.Errorf("failed to parse tool result: %w", err)
 	}
 
 	if callResult.IsError {
 		if len(callResult.Content) > 0 {
-			return "", fmt.Errorf("tool error: %s", callResult.Content[0].Text)
+			return nil, fmt.Errorf("tool error: %s", callResult.Content[0].Text)
 		}
-		return "", fmt.Errorf("tool returned error")
+		return nil, fmt.Errorf("tool returned error")
 	}
 
-	// Concatenate text content
-	var text string
+	out := &CallResult{}
 	for _, content := range callResult.Content {
-		if content.Type == "text" {
-			text += content.Text
+		switch content.Type {
+		case "text":
+			out.Text += content.Text
+		case "image", "audio":
+			if content.Data != "" {
+				out.Blobs = append(out.Blobs, Blob{MimeType: content.MimeType, Data: content.Data})
+			}
+		case "resource":
+			if content.Resource == nil {
+				continue
+			}
+			if content.Resource.Blob != "" {
+				out.Blobs = append(out.Blobs, Blob{
+					MimeType: content.Resource.MimeType,
+					Data:     content.Resource.Blob,
+					URI:      content.Resource.URI,
+				})
+			} else if content.Resource.Text != "" {
+				out.Text += content.Resource.Text
+			}
+		case "resource_link":
+			out.Text += fmt.Sprintf("[resource: %s %s]", content.Name, content.URI)
 		}
 	}
 
-	return text, nil
+	return out, nil
 }
 
 // Close shuts down the MCP client
 func (c *Client) Close() error {
-	c.stdin.Close()
-	c.stdout.Close()
-	return c.cmd.Wait()
+	return c.transport.close()
 }
 
 func (c *Client) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
@@ -221,21 +286,13 @@ func (c *Client) call(ctx context.Context, method string, params interface{}) (j
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Write request
-	if _, err := c.stdin.Write(append(data, '\n')); err != nil {
-		return nil, fmt.Errorf("failed to write request: %w", err)
-	}
-
-	// Read response
-	if !c.scanner.Scan() {
-		if err := c.scanner.Err(); err != nil {
-			return nil, fmt.Errorf("failed to read response: %w", err)
-		}
-		return nil, fmt.Errorf("EOF while reading response")
+	raw, err := c.transport.call(ctx, id, data)
+	if err != nil {
+		return nil, err
 	}
 
 	var resp jsonRPCResponse
-	if err := json.Unmarshal(c.scanner.Bytes(), &resp); err != nil {
+	if err := json.Unmarshal(raw, &resp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
@@ -266,9 +323,20 @@ func (c *Client) notify(method string, params interface{}) error {
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 
-	if _, err := c.stdin.Write(append(data, '\n')); err != nil {
+	if err := c.transport.notify(context.Background(), data); err != nil {
 		return fmt.Errorf("failed to write notification: %w", err)
 	}
 
 	return nil
 }
+
+// isResponseTo reports whether a raw JSON-RPC message is the response to
+// the request with the given id (as opposed to a server notification or
+// server-initiated request).
+func isResponseTo(raw []byte, id int64) bool {
+	var msg jsonRPCResponse
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return false
+	}
+	return msg.Method == "" && msg.ID == id
+}
@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+// fakeHTTPServer answers MCP requests over Streamable HTTP. tools/list
+// responses are sent as an SSE stream preceded by a notification.
+func fakeHTTPServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req jsonRPCRequest
+		json.Unmarshal(body, &req)
+
+		switch req.Method {
+		case "initialize":
+			w.Header().Set(sessionHeader, "sess-1")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"serverInfo":{"name":"fake","version":"0.1"}}}`, req.ID)
+		case "notifications/initialized":
+			w.WriteHeader(http.StatusAccepted)
+		case "tools/list":
+			if r.Header.Get(sessionHeader) != "sess-1" {
+				http.Error(w, "missing session", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":%d,\"result\":{\"tools\":[{\"name\":\"echo\"}]}}\n\n", req.ID)
+		default:
+			http.Error(w, "unexpected method", http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestHTTPTransport_Initialize(t *testing.T) {
+	srv := fakeHTTPServer(t)
+	defer srv.Close()
+
+	disabled := false
+	client, err := NewClientFromConfig("fake", config.MCPServerConfig{
+		HTTPURL: srv.URL,
+		OAuth:   &config.MCPOAuthConfig{Enabled: &disabled},
+	})
+	if err != nil {
+		t.Fatalf("NewClientFromConfig failed: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if client.ServerName != "fake" {
+		t.Errorf("ServerName = %q, want %q", client.ServerName, "fake")
+	}
+	if len(client.Tools) != 1 || client.Tools[0].Name != "echo" {
+		t.Errorf("Tools = %+v, want [echo]", client.Tools)
+	}
+}
+
+func TestReadSSEResponse_SkipsOtherMessages(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"jsonrpc":"2.0","id":1,"result":{}}`,
+		"",
+		"event: ping",
+		"data: {}",
+		"",
+		`data: {"jsonrpc":"2.0","id":2,`,
+		`data: "result":{"ok":true}}`,
+		"",
+	}, "\n")
+
+	got, err := readSSEResponse(strings.NewReader(stream), 2)
+	if err != nil {
+		t.Fatalf("readSSEResponse failed: %v", err)
+	}
+	var resp jsonRPCResponse
+	if err := json.Unmarshal(got, &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", got, err)
+	}
+	if resp.ID != 2 || string(resp.Result) != `{"ok":true}` {
+		t.Errorf("got id=%d result=%s", resp.ID, resp.Result)
+	}
+}
+
+func TestOAuthDiscover_ResourceMetadata(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/prm":
+			fmt.Fprintf(w, `{"authorization_servers":["%s/auth"]}`, srvURL)
+		case "/.well-known/oauth-authorization-server/auth":
+			fmt.Fprintf(w, `{"authorization_endpoint":"%[1]s/auth/authorize","token_endpoint":"%[1]s/auth/token","registration_endpoint":"%[1]s/auth/register"}`, srvURL)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	p := newOAuthProvider("s", srv.URL+"/mcp", nil)
+	meta, err := p.discover(context.Background(), `Bearer resource_metadata="`+srv.URL+`/prm"`)
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+	if meta.TokenEndpoint != srv.URL+"/auth/token" {
+		t.Errorf("TokenEndpoint = %q", meta.TokenEndpoint)
+	}
+	if meta.RegistrationEndpoint != srv.URL+"/auth/register" {
+		t.Errorf("RegistrationEndpoint = %q", meta.RegistrationEndpoint)
+	}
+}
+
+func TestFilterTools(t *testing.T) {
+	all := []Tool{{Name: "read"}, {Name: "write"}, {Name: "delete"}}
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		expect  []string
+	}{
+		{"no filters", nil, nil, []string{"read", "write", "delete"}},
+		{"include only", []string{"read", "write"}, nil, []string{"read", "write"}},
+		{"exclude only", nil, []string{"delete"}, []string{"read", "write"}},
+		{"exclude wins", []string{"read", "delete"}, []string{"delete"}, []string{"read"}},
+		{"argument suffix ignored", []string{"write(foo.txt)"}, nil, []string{"write"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterTools(all, config.MCPServerConfig{IncludeTools: tt.include, ExcludeTools: tt.exclude})
+			var names []string
+			for _, tool := range got {
+				names = append(names, tool.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expect, ",") {
+				t.Errorf("got %v, want %v", names, tt.expect)
+			}
+		})
+	}
+}
+
+func TestCallToolResult_BinaryContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req jsonRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":{"content":[
+			{"type":"text","text":"shot "},
+			{"type":"image","data":"iVBORw0KGgo=","mimeType":"image/png"},
+			{"type":"resource","resource":{"uri":"file:///a.txt","text":"done"}},
+			{"type":"resource","resource":{"uri":"file:///b.pdf","mimeType":"application/pdf","blob":"JVBERg=="}}
+		]}}`, req.ID)
+	}))
+	defer srv.Close()
+
+	disabled := false
+	client, err := NewClientFromConfig("s", config.MCPServerConfig{
+		HTTPURL: srv.URL,
+		OAuth:   &config.MCPOAuthConfig{Enabled: &disabled},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := client.CallToolResult(context.Background(), "screenshot", nil)
+	if err != nil {
+		t.Fatalf("CallToolResult failed: %v", err)
+	}
+	if result.Text != "shot done" {
+		t.Errorf("Text = %q, want %q", result.Text, "shot done")
+	}
+	if len(result.Blobs) != 2 {
+		t.Fatalf("Blobs len = %d, want 2", len(result.Blobs))
+	}
+	if result.Blobs[0].MimeType != "image/png" || result.Blobs[1].URI != "file:///b.pdf" {
+		t.Errorf("unexpected blobs: %+v", result.Blobs)
+	}
+}
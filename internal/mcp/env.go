@@ -0,0 +1,110 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+// envRef matches a ${env:NAME} reference in a server setting.
+var envRef = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolate returns cfg with ${env:NAME} references replaced, so that
+// secrets can stay out of settings.json. Values come from the environment
+// or else the server's envFile, whose variables are also passed to a stdio
+// server. A reference to a variable set in neither is an error.
+func interpolate(serverName string, cfg config.MCPServerConfig) (config.MCPServerConfig, error) {
+	fileVars := map[string]string{}
+	if cfg.EnvFile != "" {
+		path := cfg.EnvFile
+		if !filepath.IsAbs(path) && cfg.CWD != "" {
+			path = filepath.Join(cfg.CWD, path)
+		}
+		var err error
+		if fileVars, err = readEnvFile(path); err != nil {
+			return cfg, fmt.Errorf("server %s: %w", serverName, err)
+		}
+	}
+
+	var missing []string
+	expand := func(s string) string {
+		return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+			name := envRef.FindStringSubmatch(ref)[1]
+			if v, ok := os.LookupEnv(name); ok {
+				return v
+			}
+			if v, ok := fileVars[name]; ok {
+				return v
+			}
+			missing = append(missing, name)
+			return ""
+		})
+	}
+
+	out := cfg
+	out.Command = expand(cfg.Command)
+	out.CWD = expand(cfg.CWD)
+	out.URL = expand(cfg.URL)
+	out.HTTPURL = expand(cfg.HTTPURL)
+	out.Args = make([]string, len(cfg.Args))
+	for i, a := range cfg.Args {
+		out.Args[i] = expand(a)
+	}
+	out.Env = map[string]string{}
+	if cfg.Command != "" {
+		for k, v := range fileVars {
+			out.Env[k] = v
+		}
+	}
+	for k, v := range cfg.Env {
+		out.Env[k] = expand(v)
+	}
+	out.Headers = map[string]string{}
+	for k, v := range cfg.Headers {
+		out.Headers[k] = expand(v)
+	}
+	if len(missing) > 0 {
+		return cfg, fmt.Errorf("server %s: environment variable %s is not set (referenced as ${env:%s})", serverName, missing[0], missing[0])
+	}
+	return out, nil
+}
+
+// readEnvFile parses a .env file: NAME=value lines, optionally prefixed
+// with "export", with # comments and values optionally in quotes.
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("envFile: %w", err)
+	}
+	defer f.Close()
+
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("envFile %s:%d: expected NAME=value", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		vars[name] = value
+	}
+	return vars, scanner.Err()
+}
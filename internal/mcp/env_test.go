@@ -0,0 +1,43 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+func TestInterpolate(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	os.WriteFile(envFile, []byte("# secrets\nexport API_KEY=\"from file\"\nREGION=eu # comment\n"), 0o600)
+	t.Setenv("G_TEST_TOKEN", "tok")
+
+	cfg, err := interpolate("gh", config.MCPServerConfig{
+		Command: "server",
+		Args:    []string{"--region=${env:REGION}"},
+		Env:     map[string]string{"TOKEN": "${env:G_TEST_TOKEN}"},
+		Headers: map[string]string{"Authorization": "Bearer ${env:G_TEST_TOKEN}"},
+		CWD:     dir,
+		EnvFile: ".env",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Args[0] != "--region=eu" {
+		t.Errorf("args = %q", cfg.Args)
+	}
+	if cfg.Env["TOKEN"] != "tok" || cfg.Env["API_KEY"] != "from file" {
+		t.Errorf("env = %v", cfg.Env)
+	}
+	if cfg.Headers["Authorization"] != "Bearer tok" {
+		t.Errorf("headers = %v", cfg.Headers)
+	}
+
+	_, err = interpolate("gh", config.MCPServerConfig{URL: "https://example.com/${env:G_TEST_UNSET}"})
+	if err == nil || !strings.Contains(err.Error(), "G_TEST_UNSET") {
+		t.Errorf("missing variable: err = %v", err)
+	}
+}
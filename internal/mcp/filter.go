@@ -0,0 +1,54 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"strings"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+// ToolEnabled reports whether a server's includeTools/excludeTools settings
+// allow the named tool. Exclusions win over inclusions, and an empty
+// include list allows everything. Entries may carry a parenthesised suffix
+// (e.g. "run(git status)") as in gemini-cli; only the name part is compared.
+func ToolEnabled(cfg config.MCPServerConfig, name string) bool {
+	for _, pattern := range cfg.ExcludeTools {
+		if toolPatternName(pattern) == name {
+			return false
+		}
+	}
+	if len(cfg.IncludeTools) == 0 {
+		return true
+	}
+	for _, pattern := range cfg.IncludeTools {
+		if toolPatternName(pattern) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolName returns the name a server's tool is declared to the model and
+// allowed in the settings under.
+func ToolName(server, tool string) string {
+	return server + "__" + tool
+}
+
+// FilterTools returns the subset of tools allowed by the server config.
+func FilterTools(tools []Tool, cfg config.MCPServerConfig) []Tool {
+	var out []Tool
+	for _, tool := range tools {
+		if ToolEnabled(cfg, tool.Name) {
+			out = append(out, tool)
+		}
+	}
+	return out
+}
+
+func toolPatternName(pattern string) string {
+	if i := strings.IndexByte(pattern, '('); i >= 0 {
+		pattern = pattern[:i]
+	}
+	return strings.TrimSpace(pattern)
+}
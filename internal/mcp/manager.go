@@ -0,0 +1,264 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+const (
+	defaultInitTimeout = 30 * time.Second
+	toolCacheFile      = "g_mcp_tools.json"
+)
+
+// ManagerOptions configures a Manager.
+type ManagerOptions struct {
+	Debug bool
+}
+
+// Manager owns the MCP servers configured for a session. Servers are
+// started concurrently; servers marked lazy whose tool list is cached from
+// an earlier run are not launched until one of their tools is called.
+type Manager struct {
+	servers map[string]*managedServer
+	opts    ManagerOptions
+}
+
+type managedServer struct {
+	name string
+	cfg  config.MCPServerConfig
+
+	mu      sync.Mutex
+	client  *Client
+	err     error
+	tools   []Tool
+	started bool
+}
+
+// NewManager creates a manager for the given servers without starting them.
+func NewManager(servers map[string]config.MCPServerConfig, opts ManagerOptions) *Manager {
+	m := &Manager{
+		servers: make(map[string]*managedServer, len(servers)),
+		opts:    opts,
+	}
+	for name, cfg := range servers {
+		m.servers[name] = &managedServer{name: name, cfg: cfg}
+	}
+	return m
+}
+
+// Start launches all eager servers in parallel and waits for them to
+// initialize or time out. Failures are logged in debug mode and the server
+// is left out of Tools.
+func (m *Manager) Start(ctx context.Context) {
+	cache := readToolCache()
+
+	var wg sync.WaitGroup
+	for _, s := range m.servers {
+		if s.cfg.Lazy {
+			if tools, ok := cache[cacheKey(s.name, s.cfg)]; ok {
+				s.tools = tools
+				if m.opts.Debug {
+					fmt.Fprintf(os.Stderr, "[mcp] %s: deferred (using %d cached tools)\n", s.name, len(tools))
+				}
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(s *managedServer) {
+			defer wg.Done()
+			m.start(ctx, s)
+		}(s)
+	}
+	wg.Wait()
+
+	m.saveToolCache(cache)
+}
+
+// start launches and initializes one server. It is safe to call repeatedly;
+// only the first call does any work.
+func (m *Manager) start(ctx context.Context, s *managedServer) (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return s.client, s.err
+	}
+	s.started = true
+
+	begin := time.Now()
+	timeout := defaultInitTimeout
+	if s.cfg.Timeout > 0 {
+		timeout = time.Duration(s.cfg.Timeout) * time.Millisecond
+	}
+	initCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client, err := NewClientFromConfig(s.name, s.cfg)
+	if err != nil {
+		s.err = fmt.Errorf("failed to create client for %s: %w", s.name, err)
+	} else if err := initializeWithContext(initCtx, client); err != nil {
+		client.Close()
+		s.err = fmt.Errorf("failed to initialize %s: %w", s.name, err)
+	} else {
+		s.client = client
+		s.tools = client.Tools
+	}
+
+	if m.opts.Debug {
+		if s.err != nil {
+			fmt.Fprintf(os.Stderr, "[mcp] %v\n", s.err)
+		} else {
+			fmt.Fprintf(os.Stderr, "[mcp] %s: ready in %s (%d tools)\n", s.name, time.Since(begin).Round(time.Millisecond), len(s.tools))
+		}
+	}
+	return s.client, s.err
+}
+
+// initializeWithContext runs Initialize but gives up when ctx is done, even
+// if the transport itself is blocked (e.g. a stdio server that never answers).
+func initializeWithContext(ctx context.Context, client *Client) error {
+	done := make(chan error, 1)
+	go func() { done <- client.Initialize(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	}
+}
+
+// Tools returns the (unfiltered) tools of every available server, keyed by
+// server name, in a deterministic order.
+func (m *Manager) Tools() (names []string, tools map[string][]Tool) {
+	tools = make(map[string][]Tool)
+	for name, s := range m.servers {
+		s.mu.Lock()
+		ok := s.err == nil && s.tools != nil
+		if ok {
+			tools[name] = s.tools
+		}
+		s.mu.Unlock()
+		if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, tools
+}
+
+// Config returns the configuration of a managed server.
+func (m *Manager) Config(name string) (config.MCPServerConfig, bool) {
+	s, ok := m.servers[name]
+	if !ok {
+		return config.MCPServerConfig{}, false
+	}
+	return s.cfg, true
+}
+
+// Client returns a connected client for the server, launching it first if
+// it was deferred.
+func (m *Manager) Client(ctx context.Context, name string) (*Client, error) {
+	s, ok := m.servers[name]
+	if !ok {
+		return nil, fmt.Errorf("MCP server %q not configured", name)
+	}
+	client, err := m.start(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Close shuts down every running server concurrently and waits for them to
+// exit. A closed server is restarted if one of its tools is called again.
+func (m *Manager) Close() {
+	var wg sync.WaitGroup
+	for _, s := range m.servers {
+		s.mu.Lock()
+		client := s.client
+		s.client = nil
+		s.started = false
+		s.mu.Unlock()
+		if client == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, c *Client) {
+			defer wg.Done()
+			if err := c.Close(); err != nil && m.opts.Debug {
+				fmt.Fprintf(os.Stderr, "[mcp] %s: shutdown: %v\n", name, err)
+			}
+		}(s.name, client)
+	}
+	wg.Wait()
+}
+
+// saveToolCache records the tool lists of lazy servers that were started,
+// so later runs can defer launching them.
+func (m *Manager) saveToolCache(cache map[string][]Tool) {
+	changed := false
+	for _, s := range m.servers {
+		if !s.cfg.Lazy || s.client == nil {
+			continue
+		}
+		cache[cacheKey(s.name, s.cfg)] = s.tools
+		changed = true
+	}
+	if changed {
+		writeToolCache(cache)
+	}
+}
+
+// cacheKey identifies a server by name and configuration, so editing the
+// config invalidates its cached tool list.
+func cacheKey(name string, cfg config.MCPServerConfig) string {
+	data, _ := json.Marshal(cfg)
+	sum := sha256.Sum256(append([]byte(name+"\x00"), data...))
+	return name + ":" + hex.EncodeToString(sum[:8])
+}
+
+func toolCachePath() (string, error) {
+	dir, err := config.GeminiDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, toolCacheFile), nil
+}
+
+func readToolCache() map[string][]Tool {
+	cache := map[string][]Tool{}
+	path, err := toolCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	_ = json.Unmarshal(data, &cache)
+	return cache
+}
+
+func writeToolCache(cache map[string][]Tool) {
+	path, err := toolCachePath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	_ = os.WriteFile(path, data, 0644)
+}
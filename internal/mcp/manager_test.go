@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+// TestMain lets the test binary double as a stdio MCP server when
+// G_MCP_HELPER is set.
+func TestMain(m *testing.M) {
+	if os.Getenv("G_MCP_HELPER") == "1" {
+		runHelperServer()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func runHelperServer() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req jsonRPCRequest
+		if json.Unmarshal(scanner.Bytes(), &req) != nil || req.ID == 0 {
+			continue
+		}
+		var result string
+		switch req.Method {
+		case "initialize":
+			result = `{"serverInfo":{"name":"helper","version":"1"}}`
+		case "tools/list":
+			result = `{"tools":[{"name":"ping"}]}`
+		case "tools/call":
+			result = `{"content":[{"type":"text","text":"pong"}]}`
+		default:
+			result = `{}`
+		}
+		fmt.Printf("{\"jsonrpc\":\"2.0\",\"id\":%d,\"result\":%s}\n", req.ID, result)
+	}
+}
+
+func helperServerConfig(lazy bool) config.MCPServerConfig {
+	return config.MCPServerConfig{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^$"},
+		Env:     map[string]string{"G_MCP_HELPER": "1"},
+		Lazy:    lazy,
+	}
+}
+
+func TestManager_LazyServerUsesCachedTools(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	servers := map[string]config.MCPServerConfig{"helper": helperServerConfig(true)}
+
+	// First run: no cache yet, so the lazy server is started and cached.
+	first := NewManager(servers, ManagerOptions{})
+	first.Start(ctx)
+	if first.servers["helper"].client == nil {
+		t.Fatal("expected lazy server without cache to be started")
+	}
+	first.Close()
+
+	// Second run: the tool list comes from the cache and nothing is launched.
+	second := NewManager(servers, ManagerOptions{})
+	second.Start(ctx)
+	defer second.Close()
+	if second.servers["helper"].started {
+		t.Fatal("expected lazy server with cached tools to be deferred")
+	}
+	names, tools := second.Tools()
+	if len(names) != 1 || len(tools["helper"]) != 1 || tools["helper"][0].Name != "ping" {
+		t.Fatalf("Tools() = %v %v, want helper/ping", names, tools)
+	}
+
+	// First call launches it.
+	client, err := second.Client(ctx, "helper")
+	if err != nil {
+		t.Fatalf("Client failed: %v", err)
+	}
+	out, err := client.CallTool(ctx, "ping", nil)
+	if err != nil || out != "pong" {
+		t.Fatalf("CallTool = %q, %v; want pong", out, err)
+	}
+}
+
+func TestManager_InitFailureIsIsolated(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	servers := map[string]config.MCPServerConfig{
+		"good": helperServerConfig(false),
+		"bad":  {Command: "/nonexistent/mcp-server"},
+	}
+	m := NewManager(servers, ManagerOptions{})
+	m.Start(context.Background())
+	defer m.Close()
+
+	names, _ := m.Tools()
+	if len(names) != 1 || names[0] != "good" {
+		t.Errorf("Tools() names = %v, want [good]", names)
+	}
+	if _, err := m.Client(context.Background(), "bad"); err == nil {
+		t.Error("expected error for failed server")
+	}
+}
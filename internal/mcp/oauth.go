@@ -0,0 +1,565 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+const (
+	oauthTokenFile   = "mcp-oauth-tokens.json"
+	oauthCallback    = "/oauth/callback"
+	oauthWaitTimeout = 5 * time.Minute
+)
+
+// oauthEntry is the persisted OAuth state for one MCP server.
+type oauthEntry struct {
+	ServerURL    string `json:"serverUrl"`
+	ClientID     string `json:"clientId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
+	TokenURL     string `json:"tokenUrl,omitempty"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	TokenType    string `json:"tokenType,omitempty"`
+	ExpiresAt    int64  `json:"expiresAt,omitempty"` // unix milliseconds
+}
+
+func (e *oauthEntry) expired() bool {
+	if e.ExpiresAt == 0 {
+		return false
+	}
+	return time.Now().Add(time.Minute).After(time.UnixMilli(e.ExpiresAt))
+}
+
+// oauthProvider authorizes requests to one remote MCP server using OAuth 2.0
+// authorization code flow with PKCE and dynamic client registration. Stored
+// tokens are always sent, but the browser is only opened on a 401 for
+// servers whose oauth.enabled is true; others are authorized with
+// g mcp auth.
+type oauthProvider struct {
+	serverName  string
+	serverURL   string
+	cfg         config.MCPOAuthConfig
+	interactive bool // oauth.enabled: authorize in the browser on a 401
+	httpClient  *http.Client
+
+	mu     sync.Mutex
+	entry  *oauthEntry
+	loaded bool
+}
+
+func newOAuthProvider(serverName, serverURL string, cfg *config.MCPOAuthConfig) *oauthProvider {
+	p := &oauthProvider{
+		serverName: serverName,
+		serverURL:  serverURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	if cfg != nil {
+		p.cfg = *cfg
+		p.interactive = cfg.Enabled != nil && *cfg.Enabled
+	}
+	return p
+}
+
+// apply adds the stored access token to req, refreshing it if expired.
+// Requests are sent unauthenticated when no token is stored yet.
+func (p *oauthProvider) apply(ctx context.Context, req *http.Request) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry := p.load()
+	if entry == nil || entry.AccessToken == "" {
+		return nil
+	}
+	if entry.expired() && entry.RefreshToken != "" && entry.TokenURL != "" {
+		if err := p.refresh(ctx, entry); err != nil {
+			// Fall through with the stale token; the server's 401 will
+			// trigger a fresh authorization.
+			entry.AccessToken = ""
+			return nil
+		}
+	}
+	tokenType := entry.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	req.Header.Set("Authorization", tokenType+" "+entry.AccessToken)
+	return nil
+}
+
+// unauthorized handles a 401 from the server: it runs the browser flow when
+// the server opted in, and otherwise explains how to authorize.
+func (p *oauthProvider) unauthorized(ctx context.Context, challenge string) error {
+	if !p.interactive {
+		return fmt.Errorf("server %s requires authorization; run 'g mcp auth %s' or set \"oauth\": {\"enabled\": true} for it", p.serverName, p.serverName)
+	}
+	return p.authorize(ctx, challenge)
+}
+
+// authorize runs the interactive browser flow and stores the new token.
+// challenge is the WWW-Authenticate header from the 401 response, if any.
+func (p *oauthProvider) authorize(ctx context.Context, challenge string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	meta, err := p.discover(ctx, challenge)
+	if err != nil {
+		return err
+	}
+
+	entry := p.load()
+	if entry == nil {
+		entry = &oauthEntry{}
+	}
+	entry.ServerURL = p.serverURL
+	entry.TokenURL = meta.TokenEndpoint
+	if p.cfg.ClientID != "" {
+		entry.ClientID = p.cfg.ClientID
+		entry.ClientSecret = p.cfg.ClientSecret
+	}
+
+	listener, redirectURI, err := p.listen()
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	if entry.ClientID == "" {
+		if meta.RegistrationEndpoint == "" {
+			return fmt.Errorf("server %s does not support dynamic client registration; set oauth.clientId in settings", p.serverName)
+		}
+		if err := p.register(ctx, meta.RegistrationEndpoint, redirectURI, entry); err != nil {
+			return fmt.Errorf("client registration failed: %w", err)
+		}
+	}
+
+	verifier := randomString(32)
+	state := randomString(16)
+	sum := sha256.Sum256([]byte(verifier))
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", entry.ClientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:]))
+	q.Set("code_challenge_method", "S256")
+	q.Set("state", state)
+	q.Set("resource", p.serverURL)
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = meta.ScopesSupported
+	}
+	if len(scopes) > 0 {
+		q.Set("scope", strings.Join(scopes, " "))
+	}
+	authURL := meta.AuthorizationEndpoint
+	if strings.Contains(authURL, "?") {
+		authURL += "&" + q.Encode()
+	} else {
+		authURL += "?" + q.Encode()
+	}
+
+	result := make(chan callbackResult, 1)
+	srv := &http.Server{Handler: callbackHandler(state, result)}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	fmt.Fprintf(os.Stderr, "MCP server %q requires authorization. Opening browser:\n  %s\n", p.serverName, authURL)
+	openBrowser(authURL)
+
+	var code string
+	select {
+	case r := <-result:
+		if r.err != nil {
+			return r.err
+		}
+		code = r.code
+	case <-time.After(oauthWaitTimeout):
+		return fmt.Errorf("timed out waiting for authorization")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+	form.Set("resource", p.serverURL)
+	if err := p.tokenRequest(ctx, entry, form); err != nil {
+		return err
+	}
+	return p.save(entry)
+}
+
+// callbackResult is what the authorization server's redirect delivered.
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// callbackHandler receives the redirect with the authorization code and
+// sends the first one's result; later requests, e.g. a reloaded page, are
+// answered without waiting for anyone to receive them.
+func callbackHandler(state string, result chan<- callbackResult) http.Handler {
+	var once sync.Once
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != oauthCallback {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		var res callbackResult
+		switch {
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("%s: %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("state") != state:
+			res.err = fmt.Errorf("state mismatch in OAuth callback")
+		default:
+			res.code = q.Get("code")
+		}
+		once.Do(func() { result <- res })
+		fmt.Fprintln(w, "Authentication complete. You can close this window and return to g.")
+	})
+}
+
+func (p *oauthProvider) refresh(ctx context.Context, entry *oauthEntry) error {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", entry.RefreshToken)
+	form.Set("resource", p.serverURL)
+	if err := p.tokenRequest(ctx, entry, form); err != nil {
+		return err
+	}
+	return p.save(entry)
+}
+
+func (p *oauthProvider) tokenRequest(ctx context.Context, entry *oauthEntry, form url.Values) error {
+	form.Set("client_id", entry.ClientID)
+	if entry.ClientSecret != "" {
+		form.Set("client_secret", entry.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", entry.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token request failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tok struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return fmt.Errorf("token response did not include an access token")
+	}
+	entry.AccessToken = tok.AccessToken
+	entry.TokenType = tok.TokenType
+	if tok.RefreshToken != "" {
+		entry.RefreshToken = tok.RefreshToken
+	}
+	entry.ExpiresAt = 0
+	if tok.ExpiresIn > 0 {
+		entry.ExpiresAt = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second).UnixMilli()
+	}
+	return nil
+}
+
+func (p *oauthProvider) register(ctx context.Context, endpoint, redirectURI string, entry *oauthEntry) error {
+	body, _ := json.Marshal(map[string]interface{}{
+		"client_name":                "g",
+		"redirect_uris":              []string{redirectURI},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	})
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+	var reg struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := json.Unmarshal(respBody, &reg); err != nil {
+		return err
+	}
+	if reg.ClientID == "" {
+		return fmt.Errorf("registration response did not include a client_id")
+	}
+	entry.ClientID = reg.ClientID
+	entry.ClientSecret = reg.ClientSecret
+	return nil
+}
+
+// listen opens the loopback listener that receives the authorization code.
+func (p *oauthProvider) listen() (net.Listener, string, error) {
+	addr := "127.0.0.1:0"
+	if p.cfg.RedirectURI != "" {
+		u, err := url.Parse(p.cfg.RedirectURI)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid oauth.redirectUri: %w", err)
+		}
+		addr = u.Host
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start OAuth callback listener: %w", err)
+	}
+	if p.cfg.RedirectURI != "" {
+		return l, p.cfg.RedirectURI, nil
+	}
+	return l, fmt.Sprintf("http://%s%s", l.Addr().String(), oauthCallback), nil
+}
+
+// authServerMetadata is the subset of RFC 8414 metadata used by g.
+type authServerMetadata struct {
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	RegistrationEndpoint  string   `json:"registration_endpoint"`
+	ScopesSupported       []string `json:"scopes_supported"`
+}
+
+var resourceMetadataRe = regexp.MustCompile(`resource_metadata="([^"]+)"`)
+
+// discover locates the authorization server for the MCP server via
+// protected resource metadata (RFC 9728) and authorization server metadata
+// (RFC 8414), falling back to the default endpoints from the MCP spec.
+func (p *oauthProvider) discover(ctx context.Context, challenge string) (*authServerMetadata, error) {
+	u, err := url.Parse(p.serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL: %w", err)
+	}
+	origin := u.Scheme + "://" + u.Host
+	path := strings.TrimSuffix(u.Path, "/")
+
+	var prmURLs []string
+	if m := resourceMetadataRe.FindStringSubmatch(challenge); m != nil {
+		prmURLs = append(prmURLs, m[1])
+	}
+	if path != "" {
+		prmURLs = append(prmURLs, origin+"/.well-known/oauth-protected-resource"+path)
+	}
+	prmURLs = append(prmURLs, origin+"/.well-known/oauth-protected-resource")
+
+	issuer := origin
+	for _, prmURL := range prmURLs {
+		var prm struct {
+			AuthorizationServers []string `json:"authorization_servers"`
+		}
+		if p.getJSON(ctx, prmURL, &prm) == nil && len(prm.AuthorizationServers) > 0 {
+			issuer = strings.TrimSuffix(prm.AuthorizationServers[0], "/")
+			break
+		}
+	}
+
+	iu, err := url.Parse(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization server %q: %w", issuer, err)
+	}
+	issuerOrigin := iu.Scheme + "://" + iu.Host
+	issuerPath := strings.TrimSuffix(iu.Path, "/")
+
+	meta := &authServerMetadata{}
+	for _, metaURL := range []string{
+		issuerOrigin + "/.well-known/oauth-authorization-server" + issuerPath,
+		issuerOrigin + "/.well-known/openid-configuration" + issuerPath,
+		issuer + "/.well-known/openid-configuration",
+	} {
+		if p.getJSON(ctx, metaURL, meta) == nil && meta.AuthorizationEndpoint != "" {
+			break
+		}
+	}
+
+	if meta.AuthorizationEndpoint == "" {
+		meta.AuthorizationEndpoint = issuerOrigin + "/authorize"
+		meta.TokenEndpoint = issuerOrigin + "/token"
+		meta.RegistrationEndpoint = issuerOrigin + "/register"
+	}
+	if p.cfg.AuthorizationURL != "" {
+		meta.AuthorizationEndpoint = p.cfg.AuthorizationURL
+	}
+	if p.cfg.TokenURL != "" {
+		meta.TokenEndpoint = p.cfg.TokenURL
+	}
+	return meta, nil
+}
+
+func (p *oauthProvider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// load returns the stored entry for this server, reading the token file on
+// first use. Entries stored for a different URL are ignored.
+func (p *oauthProvider) load() *oauthEntry {
+	if !p.loaded {
+		p.loaded = true
+		if store, err := readOAuthStore(); err == nil {
+			if e, ok := store[p.serverName]; ok && e.ServerURL == p.serverURL {
+				p.entry = &e
+			}
+		}
+	}
+	return p.entry
+}
+
+func (p *oauthProvider) save(entry *oauthEntry) error {
+	p.entry = entry
+	p.loaded = true
+	store, err := readOAuthStore()
+	if err != nil {
+		store = map[string]oauthEntry{}
+	}
+	store[p.serverName] = *entry
+	return writeOAuthStore(store)
+}
+
+// Authenticate runs the OAuth flow for a remote server ahead of first use.
+func Authenticate(ctx context.Context, serverName string, cfg config.MCPServerConfig) error {
+	serverURL := cfg.HTTPURL
+	if serverURL == "" {
+		serverURL = cfg.URL
+	}
+	if serverURL == "" {
+		return fmt.Errorf("server %s is not a remote (HTTP/SSE) server", serverName)
+	}
+	return newOAuthProvider(serverName, serverURL, cfg.OAuth).authorize(ctx, "")
+}
+
+// Logout removes any stored OAuth token for the server.
+func Logout(serverName string) error {
+	store, err := readOAuthStore()
+	if err != nil {
+		return err
+	}
+	if _, ok := store[serverName]; !ok {
+		return nil
+	}
+	delete(store, serverName)
+	return writeOAuthStore(store)
+}
+
+func oauthStorePath() (string, error) {
+	dir, err := config.GeminiDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, oauthTokenFile), nil
+}
+
+func readOAuthStore() (map[string]oauthEntry, error) {
+	path, err := oauthStorePath()
+	if err != nil {
+		return nil, err
+	}
+	store := map[string]oauthEntry{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func writeOAuthStore(store map[string]oauthEntry) error {
+	path, err := oauthStorePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// openBrowser tries to open u in the user's browser. Failures are ignored;
+// the URL is always printed as well.
+func openBrowser(u string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	_ = cmd.Start()
+}
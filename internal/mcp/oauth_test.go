@@ -0,0 +1,69 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k-sub1995/g/internal/config"
+)
+
+func TestUnauthorizedWithoutOptIn(t *testing.T) {
+	off := false
+	for _, cfg := range []*config.MCPOAuthConfig{nil, {ClientID: "id"}, {Enabled: &off}} {
+		p := newOAuthProvider("remote", "http://127.0.0.1:1/mcp", cfg)
+		err := p.unauthorized(context.Background(), "")
+		if err == nil || !strings.Contains(err.Error(), "g mcp auth remote") {
+			t.Errorf("unauthorized(%+v) = %v, want a pointer to g mcp auth", cfg, err)
+		}
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	result := make(chan callbackResult, 1)
+	h := callbackHandler("s1", result)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, target := range []string{
+			"/oauth/callback?state=s1&code=abc",
+			"/oauth/callback?state=s1&code=again", // a reloaded page
+			"/oauth/callback?state=other&code=x",
+		} {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+			if w.Code != 200 {
+				t.Errorf("%s: status %d", target, w.Code)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a later callback blocked the handler")
+	}
+
+	r := <-result
+	if r.err != nil || r.code != "abc" {
+		t.Errorf("result = %+v, want the first code", r)
+	}
+	select {
+	case r := <-result:
+		t.Errorf("second result %+v delivered", r)
+	default:
+	}
+}
+
+func TestCallbackHandlerState(t *testing.T) {
+	result := make(chan callbackResult, 1)
+	h := callbackHandler("s1", result)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/oauth/callback?state=forged&code=abc", nil))
+	if r := <-result; r.err == nil {
+		t.Errorf("forged state accepted: %+v", r)
+	}
+}
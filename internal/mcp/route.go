@@ -0,0 +1,129 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Router picks the MCP servers whose tools a prompt plausibly needs, so the
+// declarations of the others can be left out of the request. It matches
+// words: a server is picked when the prompt shares one with the server's
+// name, its tool names, its keywords setting or, for words no other server
+// uses, its tool descriptions.
+type Router struct {
+	servers []routedServer
+}
+
+type routedServer struct {
+	name  string
+	terms map[string]bool
+}
+
+// genericWords say nothing about which server a prompt needs: they are in
+// the descriptions of all kinds of tools and in most requests.
+var genericWords = map[string]bool{
+	"about": true, "after": true, "all": true, "also": true, "and": true, "any": true, "are": true,
+	"argument": true, "arguments": true, "available": true, "call": true, "can": true, "check": true,
+	"code": true, "create": true, "current": true, "data": true, "default": true, "delete": true,
+	"does": true, "each": true, "explain": true, "file": true, "files": true, "find": true, "for": true,
+	"from": true, "function": true, "get": true, "given": true, "has": true, "have": true, "how": true,
+	"input": true, "into": true, "its": true, "list": true, "make": true, "may": true, "more": true,
+	"name": true, "new": true, "not": true, "one": true, "optional": true, "output": true, "please": true,
+	"read": true, "result": true, "results": true, "return": true, "returns": true, "run": true,
+	"set": true, "should": true, "show": true, "specified": true, "string": true, "that": true,
+	"the": true, "them": true, "then": true, "this": true, "tool": true, "tools": true, "update": true,
+	"use": true, "used": true, "using": true, "value": true, "values": true, "what": true, "when": true,
+	"which": true, "why": true, "will": true, "with": true, "write": true, "you": true, "your": true,
+}
+
+// NewRouter returns a router for the servers in names, described by their
+// tools and the keywords each server's settings give.
+func NewRouter(names []string, tools map[string][]Tool, keywords map[string][]string) *Router {
+	// Description words used by several servers do not tell them apart
+	users := map[string]int{}
+	descTerms := make([]map[string]bool, len(names))
+	for i, name := range names {
+		descTerms[i] = map[string]bool{}
+		for _, t := range tools[name] {
+			for _, w := range words(t.Description) {
+				descTerms[i][w] = true
+			}
+		}
+		for w := range descTerms[i] {
+			users[w]++
+		}
+	}
+
+	r := &Router{}
+	for i, name := range names {
+		s := routedServer{name: name, terms: map[string]bool{}}
+		named := append([]string{name}, keywords[name]...)
+		for _, t := range tools[name] {
+			named = append(named, t.Name)
+		}
+		for _, n := range named {
+			for _, w := range words(n) {
+				s.terms[w] = true
+			}
+		}
+		for w := range descTerms[i] {
+			if len(names) == 1 || users[w] < len(names) {
+				s.terms[w] = true
+			}
+		}
+		r.servers = append(r.servers, s)
+	}
+	return r
+}
+
+// Route returns the servers, in the order given to NewRouter, whose tools
+// the prompt plausibly needs.
+func (r *Router) Route(prompt string) []string {
+	promptWords := words(prompt)
+	var picked []string
+	for _, s := range r.servers {
+		for _, w := range promptWords {
+			if s.matches(w) {
+				picked = append(picked, s.name)
+				break
+			}
+		}
+	}
+	return picked
+}
+
+// matches reports whether a prompt word is one of the server's terms, or
+// differs from one only by a short ending, as "tables" from "table" or
+// "browser" from "browse".
+func (s routedServer) matches(w string) bool {
+	if s.terms[w] {
+		return true
+	}
+	for t := range s.terms {
+		short, long := t, w
+		if len(short) > len(long) {
+			short, long = long, short
+		}
+		if len(short) >= 4 && len(long)-len(short) <= 3 && strings.HasPrefix(long, short) {
+			return true
+		}
+	}
+	return false
+}
+
+// words returns the lower-cased words of s that can identify a server:
+// names and descriptions are split at anything but letters and digits, and
+// short and generic words are dropped.
+func words(s string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) >= 3 && !genericWords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
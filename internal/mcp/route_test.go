@@ -0,0 +1,43 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	names := []string{"github", "playwright", "postgres"}
+	tools := map[string][]Tool{
+		"github": {
+			{Name: "create_issue", Description: "Create a new issue in a GitHub repository."},
+			{Name: "list_pull_requests", Description: "List pull requests of a repository."},
+		},
+		"playwright": {
+			{Name: "browser_navigate", Description: "Navigate the browser to a URL."},
+			{Name: "browser_take_screenshot", Description: "Take a screenshot of the current page."},
+			{Name: "browser_evaluate", Description: "Evaluate a JavaScript function on the page."},
+		},
+		"postgres": {
+			{Name: "query", Description: "Run a read-only SQL query against the database and return the rows."},
+		},
+	}
+	r := NewRouter(names, tools, map[string][]string{"postgres": {"tables", "schema"}})
+
+	for prompt, want := range map[string][]string{
+		"explain this function":                       nil,
+		"Explain what parseConfig does in config.go":  nil,
+		"open the login page and take a screenshot":   {"playwright"},
+		"How many rows are in the users table?":       {"postgres"},
+		"Which tables does the schema have":           {"postgres"},
+		"file an issue about the flaky test":          {"github"},
+		"review open pull requests then query orders": {"github", "postgres"},
+		"use postgres__query to count users":          {"postgres"},
+		"check the dashboard in the browser":          {"playwright"},
+	} {
+		if got := r.Route(prompt); !reflect.DeepEqual(got, want) {
+			t.Errorf("Route(%q) = %v, want %v", prompt, got, want)
+		}
+	}
+}
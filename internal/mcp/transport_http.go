@@ -0,0 +1,189 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const sessionHeader = "Mcp-Session-Id"
+
+// httpTransport implements the MCP Streamable HTTP transport: each message
+// is POSTed and the response arrives either as JSON or as an SSE stream.
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	auth    *oauthProvider
+	client  *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+func newHTTPTransport(url string, headers map[string]string, auth *oauthProvider) *httpTransport {
+	return &httpTransport{
+		url:     url,
+		headers: headers,
+		auth:    auth,
+		client:  &http.Client{},
+	}
+}
+
+func (t *httpTransport) call(ctx context.Context, id int64, msg []byte) ([]byte, error) {
+	resp, err := t.post(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readSSEResponse(resp.Body, id)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+func (t *httpTransport) notify(ctx context.Context, msg []byte) error {
+	resp, err := t.post(ctx, msg)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// post sends a message, performing OAuth authorization and retrying once if
+// the server answers 401.
+func (t *httpTransport) post(ctx context.Context, msg []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", t.url, bytes.NewReader(msg))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		for k, v := range t.headers {
+			req.Header.Set(k, v)
+		}
+		t.mu.Lock()
+		if t.sessionID != "" {
+			req.Header.Set(sessionHeader, t.sessionID)
+		}
+		t.mu.Unlock()
+		if err := t.auth.apply(ctx, req); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && t.auth != nil && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if err := t.auth.unauthorized(ctx, challenge); err != nil {
+				return nil, fmt.Errorf("OAuth authorization failed: %w", err)
+			}
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("MCP HTTP error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		if sid := resp.Header.Get(sessionHeader); sid != "" {
+			t.mu.Lock()
+			t.sessionID = sid
+			t.mu.Unlock()
+		}
+		return resp, nil
+	}
+}
+
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sid := t.sessionID
+	t.mu.Unlock()
+	if sid != "" {
+		// Best-effort session termination
+		if req, err := http.NewRequest("DELETE", t.url, nil); err == nil {
+			req.Header.Set(sessionHeader, sid)
+			if resp, err := t.client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// sseEvent is a single server-sent event.
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// readSSE calls fn for each event in r until fn returns false or the stream ends.
+func readSSE(r io.Reader, fn func(sseEvent) bool) error {
+	reader := bufio.NewReader(r)
+	var ev sseEvent
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" && (len(data) > 0 || ev.Event != "") {
+			ev.Data = strings.Join(data, "\n")
+			if !fn(ev) {
+				return nil
+			}
+			ev = sseEvent{}
+			data = nil
+		} else if strings.HasPrefix(line, "data:") {
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		} else if strings.HasPrefix(line, "event:") {
+			ev.Event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// readSSEResponse reads an SSE stream until the response with the given id.
+func readSSEResponse(r io.Reader, id int64) ([]byte, error) {
+	var result []byte
+	err := readSSE(r, func(ev sseEvent) bool {
+		if ev.Event != "" && ev.Event != "message" {
+			return true
+		}
+		if isResponseTo([]byte(ev.Data), id) {
+			result = []byte(ev.Data)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event stream: %w", err)
+	}
+	if result == nil {
+		return nil, fmt.Errorf("event stream ended before response")
+	}
+	return result, nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// sseTransport implements the legacy HTTP+SSE transport: the client holds a
+// GET event stream open and POSTs messages to the endpoint the server
+// announces on it. Responses arrive on the stream.
+type sseTransport struct {
+	url     string
+	headers map[string]string
+	auth    *oauthProvider
+	client  *http.Client
+
+	connectOnce sync.Once
+	connectErr  error
+	endpoint    string
+	stream      io.Closer
+
+	mu      sync.Mutex
+	pending map[int64]chan []byte
+	done    chan struct{}
+}
+
+func newSSETransport(url string, headers map[string]string, auth *oauthProvider) *sseTransport {
+	return &sseTransport{
+		url:     url,
+		headers: headers,
+		auth:    auth,
+		client:  &http.Client{},
+		pending: make(map[int64]chan []byte),
+		done:    make(chan struct{}),
+	}
+}
+
+func (t *sseTransport) connect(ctx context.Context) error {
+	t.connectOnce.Do(func() {
+		t.connectErr = t.openStream(ctx)
+	})
+	return t.connectErr
+}
+
+func (t *sseTransport) openStream(ctx context.Context) error {
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		// The stream outlives ctx, so it is bound to the transport instead.
+		req, err := http.NewRequest("GET", t.url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		for k, v := range t.headers {
+			req.Header.Set(k, v)
+		}
+		if err := t.auth.apply(ctx, req); err != nil {
+			return err
+		}
+		resp, err = t.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to open event stream: %w", err)
+		}
+		if resp.StatusCode == http.StatusUnauthorized && t.auth != nil && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if err := t.auth.unauthorized(ctx, challenge); err != nil {
+				return fmt.Errorf("OAuth authorization failed: %w", err)
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return fmt.Errorf("MCP SSE error (status %d): %s", resp.StatusCode, string(body))
+		}
+		break
+	}
+
+	endpointCh := make(chan string, 1)
+	t.stream = resp.Body
+	go func() {
+		defer close(t.done)
+		defer resp.Body.Close()
+		readSSE(resp.Body, func(ev sseEvent) bool {
+			switch ev.Event {
+			case "endpoint":
+				select {
+				case endpointCh <- ev.Data:
+				default:
+				}
+			case "", "message":
+				var msg jsonRPCResponse
+				if json.Unmarshal([]byte(ev.Data), &msg) != nil || msg.Method != "" {
+					return true
+				}
+				t.mu.Lock()
+				ch, ok := t.pending[msg.ID]
+				delete(t.pending, msg.ID)
+				t.mu.Unlock()
+				if ok {
+					ch <- []byte(ev.Data)
+				}
+			}
+			return true
+		})
+	}()
+
+	select {
+	case ep := <-endpointCh:
+		base, err := url.Parse(t.url)
+		if err != nil {
+			return err
+		}
+		ref, err := url.Parse(ep)
+		if err != nil {
+			return fmt.Errorf("invalid endpoint %q: %w", ep, err)
+		}
+		t.endpoint = base.ResolveReference(ref).String()
+		return nil
+	case <-t.done:
+		return fmt.Errorf("event stream closed before endpoint was announced")
+	case <-ctx.Done():
+		resp.Body.Close()
+		return ctx.Err()
+	}
+}
+
+func (t *sseTransport) call(ctx context.Context, id int64, msg []byte) ([]byte, error) {
+	if err := t.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan []byte, 1)
+	t.mu.Lock()
+	t.pending[id] = ch
+	t.mu.Unlock()
+
+	if err := t.post(ctx, msg); err != nil {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+		return nil, err
+	}
+
+	select {
+	case raw := <-ch:
+		return raw, nil
+	case <-t.done:
+		return nil, fmt.Errorf("event stream closed while waiting for response")
+	case <-ctx.Done():
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (t *sseTransport) notify(ctx context.Context, msg []byte) error {
+	if err := t.connect(ctx); err != nil {
+		return err
+	}
+	return t.post(ctx, msg)
+}
+
+func (t *sseTransport) post(ctx context.Context, msg []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", t.endpoint, bytes.NewReader(msg))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if err := t.auth.apply(ctx, req); err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("MCP HTTP error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func (t *sseTransport) close() error {
+	if t.stream != nil {
+		t.stream.Close()
+	}
+	t.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Tomohiro Owada
+// SPDX-License-Identifier: Apache-2.0
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// stdioTransport talks newline-delimited JSON-RPC to a child process.
+type stdioTransport struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  io.ReadCloser
+	scanner *bufio.Scanner
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// stdioShutdownGrace is how long a server gets to exit after each step of
+// the shutdown sequence.
+const stdioShutdownGrace = 2 * time.Second
+
+func newStdioTransport(command string, args []string, env map[string]string, cwd string) (*stdioTransport, error) {
+	cmd := exec.Command(command, args...)
+
+	// Set working directory
+	if cwd != "" {
+		cmd.Dir = cwd
+	}
+
+	// Set environment
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdin pipe: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+
+	// Redirect stderr to our stderr for debugging
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	return &stdioTransport{
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  stdout,
+		scanner: scanner,
+	}, nil
+}
+
+func (t *stdioTransport) call(ctx context.Context, id int64, msg []byte) ([]byte, error) {
+	// Write request
+	if _, err := t.stdin.Write(append(msg, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+
+	// Read until the matching response, skipping server notifications
+	for {
+		if !t.scanner.Scan() {
+			if err := t.scanner.Err(); err != nil {
+				return nil, fmt.Errorf("failed to read response: %w", err)
+			}
+			return nil, fmt.Errorf("EOF while reading response")
+		}
+		line := t.scanner.Bytes()
+		if isResponseTo(line, id) {
+			return append([]byte(nil), line...), nil
+		}
+	}
+}
+
+func (t *stdioTransport) notify(ctx context.Context, msg []byte) error {
+	_, err := t.stdin.Write(append(msg, '\n'))
+	return err
+}
+
+// close shuts the server down gracefully: stdin is closed first, then the
+// process gets SIGTERM, and finally it is killed if it still hasn't exited.
+func (t *stdioTransport) close() error {
+	t.closeOnce.Do(func() {
+		t.stdin.Close()
+
+		done := make(chan error, 1)
+		go func() { done <- t.cmd.Wait() }()
+
+		select {
+		case t.closeErr = <-done:
+			return
+		case <-time.After(stdioShutdownGrace):
+		}
+
+		// Signal is unsupported on Windows; fall straight through to Kill.
+		if err := t.cmd.Process.Signal(syscall.SIGTERM); err == nil {
+			select {
+			case t.closeErr = <-done:
+				return
+			case <-time.After(stdioShutdownGrace):
+			}
+		}
+
+		t.cmd.Process.Kill()
+		t.closeErr = <-done
+	})
+	return t.closeErr
+}
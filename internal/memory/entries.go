@@ -0,0 +1,216 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Section is the heading save_memory adds entries under, as the Gemini CLI
+// does. Text elsewhere in the file is the user's and left alone.
+const Section = "## Gemini Added Memories"
+
+// DefaultMaxBytes bounds the user memory file unless the settings say
+// otherwise, since all of it is sent with every prompt.
+const DefaultMaxBytes = 16 * 1024
+
+// dateLayout is how entries are dated.
+const dateLayout = "2006-01-02"
+
+// ErrFull is returned by Add when the entry would take the user memory
+// file over its size limit.
+var ErrFull = errors.New("memory file is full")
+
+// Entry is one remembered fact: "- [2026-01-02 #tag] text". Entries saved
+// before dates and tags were kept are plain "- text" bullets.
+type Entry struct {
+	Date time.Time // zero when unknown
+	Tags []string
+	Text string
+}
+
+var (
+	entryLine = regexp.MustCompile(`^- (?:\[(\d{4}-\d{2}-\d{2})((?: #[\w-]+)*)\] )?(.*)$`)
+	tagChars  = regexp.MustCompile(`[^\w-]+`)
+)
+
+func (e Entry) String() string {
+	var meta []string
+	if !e.Date.IsZero() {
+		meta = append(meta, e.Date.Format(dateLayout))
+	}
+	for _, t := range e.Tags {
+		meta = append(meta, "#"+t)
+	}
+	if len(meta) == 0 {
+		return "- " + e.Text
+	}
+	return fmt.Sprintf("- [%s] %s", strings.Join(meta, " "), e.Text)
+}
+
+// HasTag reports whether e is tagged tag.
+func (e Entry) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(tagChars.ReplaceAllString(strings.TrimPrefix(strings.TrimSpace(tag), "#"), "-"))
+}
+
+func parseEntry(line string) (Entry, bool) {
+	m := entryLine.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false
+	}
+	e := Entry{Text: m[3]}
+	if m[1] != "" {
+		e.Date, _ = time.Parse(dateLayout, m[1])
+	}
+	for _, t := range strings.Fields(m[2]) {
+		e.Tags = append(e.Tags, strings.TrimPrefix(t, "#"))
+	}
+	return e, true
+}
+
+// section returns the line range [start, end) of the entries below
+// Section in lines, or -1s when there is no such heading.
+func section(lines []string) (int, int) {
+	return sectionOf(lines, Section)
+}
+
+// sectionOf returns the line range [start, end) below heading, up to the
+// next heading of level one or two, or -1s when there is no such heading.
+func sectionOf(lines []string, heading string) (int, int) {
+	for i, line := range lines {
+		if strings.TrimSpace(line) != heading {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !strings.HasPrefix(lines[end], "# ") && !strings.HasPrefix(lines[end], "## ") {
+			end++
+		}
+		return i + 1, end
+	}
+	return -1, -1
+}
+
+// Entries returns the entries saved in the memory file at path.
+func Entries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(data), "\n")
+	start, end := section(lines)
+	var entries []Entry
+	for i := start; i >= 0 && i < end; i++ {
+		if e, ok := parseEntry(lines[i]); ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// Add saves text as an entry dated now in the user memory file and returns
+// the file's path. Newlines in text are joined into one line. An error
+// wrapping ErrFull is returned when the file would exceed its size limit.
+func Add(text string, tags []string, now time.Time) (string, error) {
+	path, err := UserFile()
+	if err != nil {
+		return "", err
+	}
+	e := Entry{Date: now, Text: strings.Join(strings.Fields(text), " ")}
+	for _, t := range tags {
+		if t = normalizeTag(t); t != "" {
+			e.Tags = append(e.Tags, t)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return path, err
+	}
+	out := appendToSection(string(data), Section, e.String())
+
+	if max := MaxBytes(); len(out) > max {
+		return path, fmt.Errorf("%w: %s would grow to %d bytes, over the limit of %d", ErrFull, path, len(out), max)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return path, err
+	}
+	return path, os.WriteFile(path, []byte(out), 0644)
+}
+
+// appendToSection adds lines at the end of the section below heading in
+// text, which is created at the end of the text when missing.
+func appendToSection(text, heading string, add ...string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if text == "" {
+		lines = nil
+	}
+	start, end := sectionOf(lines, heading)
+	if start < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, heading)
+		start, end = len(lines), len(lines)
+	}
+	// After the section's last line, before any blank lines
+	at := end
+	for at > start && strings.TrimSpace(lines[at-1]) == "" {
+		at--
+	}
+	lines = append(lines[:at], append(append([]string{}, add...), lines[at:]...)...)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Remove deletes the entries of the user memory file for which match
+// returns true and reports how many it removed.
+func Remove(match func(Entry) bool) (int, error) {
+	path, err := UserFile()
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	lines := strings.Split(string(data), "\n")
+	start, end := section(lines)
+	if start < 0 {
+		return 0, nil
+	}
+	kept := append([]string(nil), lines[:start]...)
+	removed := 0
+	for _, line := range lines[start:end] {
+		if e, ok := parseEntry(line); ok && match(e) {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	kept = append(kept, lines[end:]...)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, os.WriteFile(path, []byte(strings.Join(kept, "\n")), 0644)
+}
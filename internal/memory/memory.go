@@ -0,0 +1,162 @@
+// Package memory locates the memory files (GEMINI.md and the like) that
+// are added to the system prompt and that save_memory writes to.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package memory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultFileNames are the memory files looked up when the settings name
+// none. AGENTS.md is read for projects that keep their instructions there.
+var DefaultFileNames = []string{"GEMINI.md", "AGENTS.md"}
+
+var (
+	mu        sync.RWMutex
+	fileNames = DefaultFileNames
+	userFile  string // "" for ~/.gemini/<first file name>
+	maxBytes  = DefaultMaxBytes
+)
+
+// Configure sets the memory file names looked up in the project and in
+// ~/.gemini, the file save_memory writes to and that file's size limit.
+// Zero values keep the defaults. In file, a leading ~ and $VARs are
+// expanded, with XDG_CONFIG_HOME and XDG_DATA_HOME defaulting as the XDG
+// spec says.
+func Configure(names []string, file string, max int) error {
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid memory file name %q (use a plain file name such as AGENTS.md)", name)
+		}
+	}
+	if len(names) == 0 {
+		names = DefaultFileNames
+	}
+	if file != "" {
+		var err error
+		if file, err = expandPath(file); err != nil {
+			return err
+		}
+	}
+	if max <= 0 {
+		max = DefaultMaxBytes
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	fileNames, userFile, maxBytes = names, file, max
+	return nil
+}
+
+// MaxBytes returns the size limit of the user memory file.
+func MaxBytes() int {
+	mu.RLock()
+	defer mu.RUnlock()
+	return maxBytes
+}
+
+// FileNames returns the memory file names, in the order they are read.
+func FileNames() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return fileNames
+}
+
+// UserFile returns the file save_memory appends to.
+func UserFile() (string, error) {
+	mu.RLock()
+	file, names := userFile, fileNames
+	mu.RUnlock()
+	if file != "" {
+		return file, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".gemini", names[0]), nil
+}
+
+// Files returns the memory files for a session in workDir that exist and
+// are not empty, in the order they are added to the prompt: each file name
+// in workDir, then in workDir/.gemini, then in ~/.gemini, then the user
+// file when it is elsewhere. A file reached twice, say through a symlink
+// from AGENTS.md to GEMINI.md, is listed once.
+func Files(workDir string) []string {
+	var candidates []string
+	dirs := []string{workDir, filepath.Join(workDir, ".gemini")}
+	if home, _ := os.UserHomeDir(); home != "" {
+		dirs = append(dirs, filepath.Join(home, ".gemini"))
+	}
+	for _, dir := range dirs {
+		for _, name := range FileNames() {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+	if file, err := UserFile(); err == nil {
+		candidates = append(candidates, file)
+	}
+
+	var files []string
+	seen := map[string]bool{}
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Size() == 0 {
+			continue
+		}
+		real, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			real = path
+		}
+		if !seen[real] {
+			seen[real] = true
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// Load returns the contents of Files(workDir), separated by blank lines.
+func Load(workDir string) string {
+	var parts []string
+	for _, path := range Files(workDir) {
+		data, _ := os.ReadFile(path)
+		if s := strings.TrimSpace(string(data)); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// expandPath expands a leading ~ and environment variables in path.
+func expandPath(path string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	path = os.Expand(path, func(name string) string {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+		switch name {
+		case "XDG_CONFIG_HOME":
+			return filepath.Join(home, ".config")
+		case "XDG_DATA_HOME":
+			return filepath.Join(home, ".local", "share")
+		case "HOME":
+			return home
+		}
+		return ""
+	})
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("memory file %q must be an absolute path", path)
+	}
+	return filepath.Clean(path), nil
+}
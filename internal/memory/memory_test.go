@@ -0,0 +1,124 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package memory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFiles(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Cleanup(func() { Configure(nil, "", 0) })
+	write := func(path, content string) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	write(filepath.Join(project, "GEMINI.md"), "project")
+	os.Symlink("GEMINI.md", filepath.Join(project, "AGENTS.md"))
+	write(filepath.Join(project, ".gemini", "AGENTS.md"), "team")
+	write(filepath.Join(home, ".gemini", "GEMINI.md"), "user")
+	write(filepath.Join(home, ".gemini", "empty.md"), "")
+
+	want := []string{
+		filepath.Join(project, "GEMINI.md"),
+		filepath.Join(project, ".gemini", "AGENTS.md"),
+		filepath.Join(home, ".gemini", "GEMINI.md"),
+	}
+	if got := Files(project); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files = %v, want %v", got, want)
+	}
+	if got := Load(project); got != "project\n\nteam\n\nuser" {
+		t.Errorf("Load = %q", got)
+	}
+
+	if err := Configure([]string{"AGENTS.md"}, "$XDG_CONFIG_HOME/g/memory.md", 0); err != nil {
+		t.Fatal(err)
+	}
+	userFile := filepath.Join(home, ".config", "g", "memory.md")
+	if got, _ := UserFile(); got != userFile {
+		t.Errorf("UserFile = %s, want %s", got, userFile)
+	}
+	write(userFile, "saved")
+	want = []string{filepath.Join(project, "AGENTS.md"), filepath.Join(project, ".gemini", "AGENTS.md"), userFile}
+	if got := Files(project); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files with AGENTS.md = %v, want %v", got, want)
+	}
+
+	if err := Configure([]string{"docs/AGENTS.md"}, "", 0); err == nil {
+		t.Error("a file name with a directory should be rejected")
+	}
+}
+
+func TestAddAndRemove(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "memory.md")
+	os.WriteFile(path, []byte("# My notes\n\nUse tabs.\n\n## Gemini Added Memories\n- Likes Go\n\n## Later\nkept\n"), 0644)
+	if err := Configure(nil, path, 200); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Configure(nil, "", 0) })
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := Add("Prefers table-driven\ntests", []string{"Go", "#style"}, day); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := Entries(path)
+	want := []Entry{{Text: "Likes Go"}, {Date: day.Truncate(24 * time.Hour), Tags: []string{"go", "style"}, Text: "Prefers table-driven tests"}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "- Likes Go\n- [2026-03-01 #go #style] Prefers table-driven tests\n\n## Later\nkept\n") {
+		t.Errorf("file = %q", data)
+	}
+
+	if _, err := Add(strings.Repeat("x", 200), nil, day); !errors.Is(err, ErrFull) {
+		t.Errorf("Add over the limit: err = %v, want ErrFull", err)
+	}
+
+	n, err := Remove(func(e Entry) bool { return e.HasTag("style") })
+	if err != nil || n != 1 {
+		t.Fatalf("Remove = %d, %v", n, err)
+	}
+	data, _ = os.ReadFile(path)
+	if string(data) != "# My notes\n\nUse tabs.\n\n## Gemini Added Memories\n- Likes Go\n\n## Later\nkept\n" {
+		t.Errorf("after Remove: %q", data)
+	}
+}
+
+func TestAddProjectNotes(t *testing.T) {
+	dir := t.TempDir()
+	if got := ProjectFile(dir); got != filepath.Join(dir, "GEMINI.md") {
+		t.Errorf("ProjectFile without a memory file = %s", got)
+	}
+	os.MkdirAll(filepath.Join(dir, ".gemini"), 0755)
+	path := filepath.Join(dir, ".gemini", "AGENTS.md")
+	os.WriteFile(path, []byte("# Project\n\nRun `make test` before committing.\n"), 0644)
+	if got := ProjectFile(dir); got != path {
+		t.Errorf("ProjectFile = %s, want %s", got, path)
+	}
+
+	added, err := AddProjectNotes(path, []string{"Run `make test` before committing.", "Integration tests\n need Docker.", "Integration tests need docker."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(added, []string{"Integration tests need Docker."}) {
+		t.Errorf("added = %q", added)
+	}
+	if _, err := AddProjectNotes(path, []string{"Generated code lives in gen/."}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "# Project\n\nRun `make test` before committing.\n\n" + ProjectSection + "\n- Integration tests need Docker.\n- Generated code lives in gen/.\n"
+	if string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectSection is the heading project notes are added under by
+// /memory refresh.
+const ProjectSection = "## Project Notes"
+
+// ProjectFile returns the project memory file in workDir that notes are
+// added to: the first of FileNames() that exists there or in
+// workDir/.gemini, or else a new file by the first name in workDir.
+func ProjectFile(workDir string) string {
+	for _, dir := range []string{workDir, filepath.Join(workDir, ".gemini")} {
+		for _, name := range FileNames() {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				return path
+			}
+		}
+	}
+	return filepath.Join(workDir, FileNames()[0])
+}
+
+// NewNotes returns the notes that text does not already contain, with
+// whitespace collapsed and without repeats.
+func NewNotes(text string, notes []string) []string {
+	known := strings.ToLower(text)
+	var fresh []string
+	for _, n := range notes {
+		n = strings.Join(strings.Fields(n), " ")
+		if n == "" || strings.Contains(known, strings.ToLower(n)) {
+			continue
+		}
+		known += "\n" + strings.ToLower(n)
+		fresh = append(fresh, n)
+	}
+	return fresh
+}
+
+// AddProjectNotes appends notes as bullets to the ProjectSection of the
+// file at path, leaving out those it already contains. It returns the
+// notes it added.
+func AddProjectNotes(path string, notes []string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	added := NewNotes(string(data), notes)
+	if len(added) == 0 {
+		return nil, nil
+	}
+	bullets := make([]string, len(added))
+	for i, n := range added {
+		bullets[i] = "- " + n
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return added, os.WriteFile(path, []byte(appendToSection(string(data), ProjectSection, bullets...)), 0644)
+}
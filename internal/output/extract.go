@@ -0,0 +1,177 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// ExtractModes are the parts of a response --extract can print.
+var ExtractModes = []string{"code", "last-block", "json"}
+
+// ExtractFormatter prints only part of the final response once a turn is
+// over, for shell pipelines that want a payload without the prose around
+// it. Tool calls, progress and errors are shown as in text output.
+type ExtractFormatter struct {
+	*TextFormatter
+	mode string
+	out  io.Writer
+	text strings.Builder // the model's text since the last tool call
+}
+
+// NewExtractFormatter returns a formatter that writes what Extract finds
+// for mode to w when Flush is called.
+func NewExtractFormatter(mode string, w, errW io.Writer, sanitize bool) (*ExtractFormatter, error) {
+	switch mode {
+	case "code", "last-block", "json":
+	default:
+		return nil, fmt.Errorf("unknown extract mode %q (use %s)", mode, strings.Join(ExtractModes, ", "))
+	}
+	return &ExtractFormatter{
+		TextFormatter: &TextFormatter{w: io.Discard, errW: errW, sanitize: sanitize},
+		mode:          mode,
+		out:           w,
+	}, nil
+}
+
+func (f *ExtractFormatter) WriteResponse(resp *api.GenerateResponse) error {
+	if len(resp.Response.Candidates) > 0 {
+		for _, part := range resp.Response.Candidates[0].Content.Parts {
+			f.text.WriteString(sanitizeText(part.Text, f.sanitize))
+		}
+		f.text.WriteString("\n")
+	}
+	return f.TextFormatter.WriteResponse(resp)
+}
+
+func (f *ExtractFormatter) WriteStreamEvent(event *api.StreamEvent) error {
+	if event.Type == "content" {
+		f.text.WriteString(sanitizeText(event.Text, f.sanitize))
+	}
+	return f.TextFormatter.WriteStreamEvent(event)
+}
+
+// WriteToolCall starts over: only text after the last tool call is the
+// final response.
+func (f *ExtractFormatter) WriteToolCall(name string, args map[string]interface{}) error {
+	f.text.Reset()
+	return f.TextFormatter.WriteToolCall(name, args)
+}
+
+// Flush writes the extracted part of the response collected so far and
+// starts over for the next turn.
+func (f *ExtractFormatter) Flush() error {
+	text := f.text.String()
+	f.text.Reset()
+	out, err := Extract(f.mode, text)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(f.out, out)
+	return err
+}
+
+// Extract returns part of a response: the first fenced code block for
+// "code", the last for "last-block", and for "json" the response itself
+// when it is JSON, else the first code block or {...} or [...] span that
+// is valid JSON.
+func Extract(mode, text string) (string, error) {
+	blocks := codeBlocks(text)
+	switch mode {
+	case "code", "last-block":
+		if len(blocks) == 0 {
+			return "", fmt.Errorf("the response has no fenced code block")
+		}
+		if mode == "code" {
+			return blocks[0].body, nil
+		}
+		return blocks[len(blocks)-1].body, nil
+	case "json":
+		if s := strings.TrimSpace(text); json.Valid([]byte(s)) && s != "" {
+			return s, nil
+		}
+		// Blocks marked as JSON first
+		for _, b := range blocks {
+			if b.lang == "json" && json.Valid([]byte(b.body)) {
+				return b.body, nil
+			}
+		}
+		for _, b := range blocks {
+			if json.Valid([]byte(b.body)) && strings.ContainsAny(b.body, "{[") {
+				return b.body, nil
+			}
+		}
+		if s, ok := firstJSONValue(text); ok {
+			return s, nil
+		}
+		return "", fmt.Errorf("the response contains no valid JSON")
+	}
+	return "", fmt.Errorf("unknown extract mode %q (use %s)", mode, strings.Join(ExtractModes, ", "))
+}
+
+type codeBlock struct {
+	lang string
+	body string
+}
+
+// codeBlocks returns the fenced code blocks of Markdown text. A block left
+// open at the end, as in a cut-off response, runs to the end.
+func codeBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	var fence string
+	var cur codeBlock
+	var body []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" {
+			if f := fenceOf(trimmed); f != "" {
+				fence = f
+				cur = codeBlock{lang: strings.ToLower(strings.TrimSpace(strings.TrimLeft(trimmed, f[:1])))}
+				body = nil
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			cur.body = strings.Join(body, "\n")
+			blocks = append(blocks, cur)
+			fence = ""
+			continue
+		}
+		body = append(body, line)
+	}
+	if fence != "" {
+		cur.body = strings.TrimRight(strings.Join(body, "\n"), "\n")
+		blocks = append(blocks, cur)
+	}
+	return blocks
+}
+
+// fenceOf returns the ``` or ~~~ run that opens a code block on line, or "".
+func fenceOf(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// firstJSONValue finds the first object or array in text that parses.
+func firstJSONValue(text string) (string, bool) {
+	for i := 0; i < len(text); i++ {
+		if text[i] != '{' && text[i] != '[' {
+			continue
+		}
+		var v json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(text[i:])).Decode(&v); err == nil {
+			return string(v), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+func TestExtract(t *testing.T) {
+	response := "Here is the script:\n\n```bash\necho one\n```\n\nAnd the config:\n\n~~~json\n{\"a\": 1}\n~~~\n\nDone."
+	tests := []struct {
+		mode, text, want string
+		wantErr          bool
+	}{
+		{"code", response, "echo one", false},
+		{"last-block", response, "{\"a\": 1}", false},
+		{"json", response, "{\"a\": 1}", false},
+		{"json", "  [1, 2]\n", "[1, 2]", false},
+		{"json", "The result is {\"ok\": true} as requested.", "{\"ok\": true}", false},
+		{"json", "```\nnot json\n```\n{\"b\": [2]}", "{\"b\": [2]}", false},
+		{"code", "```go\nfunc f() {\n\treturn\n}\n", "func f() {\n\treturn\n}", false},
+		{"code", "No code here.", "", true},
+		{"json", "{broken", "", true},
+	}
+	for _, tt := range tests {
+		got, err := Extract(tt.mode, tt.text)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Extract(%q, %q) = %q, %v; want %q", tt.mode, tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestExtractFormatterFinalResponse(t *testing.T) {
+	var out, errOut bytes.Buffer
+	f, err := NewExtractFormatter("code", &out, &errOut, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteStreamEvent(&api.StreamEvent{Type: "content", Text: "Let me look.\n```\nearlier\n```\n"})
+	f.WriteToolCall("read_file", map[string]interface{}{"file_path": "a.go"})
+	f.WriteStreamEvent(&api.StreamEvent{Type: "content", Text: "Fixed:\n```go\n"})
+	f.WriteStreamEvent(&api.StreamEvent{Type: "content", Text: "x := 1\n```\n"})
+	f.WriteStreamEvent(&api.StreamEvent{Type: "done"})
+	if out.Len() != 0 {
+		t.Fatalf("output before Flush: %q", out.String())
+	}
+	if err := f.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "x := 1\n" {
+		t.Errorf("output = %q, want %q", out.String(), "x := 1\n")
+	}
+}
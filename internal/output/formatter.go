@@ -7,9 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 
 	"github.com/acarl005/stripansi"
 	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/summary"
 )
 
 // Formatter is the interface for output formatters
@@ -19,6 +22,9 @@ type Formatter interface {
 	WriteError(err error) error
 	WriteToolCall(name string, args map[string]interface{}) error
 	WriteToolResult(name string, result map[string]interface{}, isError bool) error
+	// WriteProgress explains a wait, e.g. a rate-limit retry (see
+	// api.ProgressFunc for stage and detail).
+	WriteProgress(stage, detail string) error
 }
 
 // NewFormatter creates a formatter for the given format
@@ -47,29 +53,101 @@ func sanitizeText(text string, sanitize bool) string {
 type TextFormatter struct {
 	w        io.Writer
 	errW     io.Writer
+	events   io.Writer // tool calls and progress; nil: errW
 	sanitize bool
+	midLine  bool // streamed text has not ended with a newline
+}
+
+// SetEventWriter sends tool calls, tool results and progress to w instead
+// of stderr.
+func (f *TextFormatter) SetEventWriter(w io.Writer) { f.events = w }
+
+func (f *TextFormatter) eventOut() io.Writer {
+	if f.events != nil {
+		return f.events
+	}
+	return f.errW
 }
 
 func (f *TextFormatter) WriteResponse(resp *api.GenerateResponse) error {
 	if len(resp.Response.Candidates) > 0 && len(resp.Response.Candidates[0].Content.Parts) > 0 {
-		text := sanitizeText(resp.Response.Candidates[0].Content.Parts[0].Text, f.sanitize)
-		_, err := fmt.Fprintln(f.w, text)
-		return err
+		for _, part := range resp.Response.Candidates[0].Content.Parts {
+			if err := f.writeCode(part.ExecutableCode, part.CodeExecutionResult); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprint(f.w, sanitizeText(part.Text, f.sanitize)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(f.w); err != nil {
+			return err
+		}
+		return f.writeSources(resp.Sources())
 	}
 	return nil
 }
 
 func (f *TextFormatter) WriteStreamEvent(event *api.StreamEvent) error {
+	if event.Code != nil || event.CodeResult != nil {
+		return f.writeCode(event.Code, event.CodeResult)
+	}
 	if event.Text != "" {
 		text := sanitizeText(event.Text, f.sanitize)
+		f.midLine = !strings.HasSuffix(text, "\n")
 		_, err := fmt.Fprint(f.w, text)
 		return err
 	}
 	if event.Type == "done" {
+		f.midLine = false
 		// Add final newline
-		_, err := fmt.Fprintln(f.w)
+		if _, err := fmt.Fprintln(f.w); err != nil {
+			return err
+		}
+		return f.writeSources(event.Sources)
+	}
+	return nil
+}
+
+// writeCode shows code run by the API's code execution tool, or its result.
+func (f *TextFormatter) writeCode(code *api.ExecutableCode, result *api.CodeExecutionResult) error {
+	if code != nil {
+		lang := strings.ToLower(code.Language)
+		if lang == "" || lang == "language_unspecified" {
+			lang = "python"
+		}
+		if _, err := fmt.Fprintf(f.w, "\n```%s\n%s\n```\n", lang, strings.TrimRight(sanitizeText(code.Code, f.sanitize), "\n")); err != nil {
+			return err
+		}
+	}
+	if result != nil {
+		label := "Output"
+		if result.Outcome != "" && result.Outcome != "OUTCOME_OK" {
+			label = "Failed (" + strings.ToLower(strings.TrimPrefix(result.Outcome, "OUTCOME_")) + ")"
+		}
+		if _, err := fmt.Fprintf(f.w, "%s:\n```\n%s\n```\n\n", label, strings.TrimRight(sanitizeText(result.Output, f.sanitize), "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSources lists the web pages a grounded answer cites.
+func (f *TextFormatter) writeSources(sources []api.Source) error {
+	if len(sources) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(f.w, "\nSources:"); err != nil {
 		return err
 	}
+	for i, src := range sources {
+		title := src.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		if _, err := fmt.Fprintf(f.w, "[%d] %s (%s)\n", i+1, sanitizeText(title, f.sanitize), src.URI); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -79,25 +157,78 @@ func (f *TextFormatter) WriteError(err error) error {
 }
 
 func (f *TextFormatter) WriteToolCall(name string, args map[string]interface{}) error {
-	_, err := fmt.Fprintf(f.errW, "⚡ %s\n", name)
+	_, err := fmt.Fprintf(f.eventOut(), "⚡ %s\n", name)
 	return err
 }
 
 func (f *TextFormatter) WriteToolResult(name string, result map[string]interface{}, isError bool) error {
 	if isError {
 		if errMsg, ok := result["error"]; ok {
-			_, err := fmt.Fprintf(f.errW, "✗ %s: %v\n", name, errMsg)
+			_, err := fmt.Fprintf(f.eventOut(), "✗ %s: %v\n", name, errMsg)
 			return err
 		}
 	}
+	if saved, ok := result["saved_files"].([]string); ok {
+		for _, path := range saved {
+			if _, err := fmt.Fprintf(f.eventOut(), "  ↳ saved %s\n", path); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+func (f *TextFormatter) WriteProgress(stage, detail string) error {
+	_, err := fmt.Fprintf(f.eventOut(), "⏳ %s\n", detail)
+	return err
+}
+
+// WriteArtifacts lists the files the session saved in its artifacts
+// directory.
+func (f *TextFormatter) WriteArtifacts(files []string) error {
+	if _, err := fmt.Fprintln(f.errW, "📦 Artifacts:"); err != nil {
+		return err
+	}
+	for _, path := range files {
+		if _, err := fmt.Fprintf(f.errW, "  %s\n", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSummary shows what the run did on stderr.
+func (f *TextFormatter) WriteSummary(s *summary.Run) error {
+	_, err := fmt.Fprintf(f.errW, "📋 %s\n", s.Text())
+	return err
+}
+
+// WriteCancelled ends a reply cut off by cancellation: the partial text
+// gets its final newline and a note goes to stderr.
+func (f *TextFormatter) WriteCancelled() error {
+	if f.midLine {
+		f.midLine = false
+		if _, err := fmt.Fprintln(f.w); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(f.errW, "✗ Cancelled")
+	return err
+}
+
 // JSONFormatter outputs structured JSON (non-streaming)
 type JSONFormatter struct {
 	w        io.Writer
 	errW     io.Writer
 	sanitize bool
+	written  bool                 // a response has been written
+	events   *StreamJSONFormatter // tool calls and progress as NDJSON; nil: not shown
+
+	// Artifacts, when set, lists the session's artifact files for the
+	// response's "artifacts" field.
+	Artifacts func() []string
+	// Summary, when set, describes the run for the "summary" field.
+	Summary func() *summary.Run
 }
 
 // JSONResponse is the JSON output structure
@@ -106,6 +237,22 @@ type JSONResponse struct {
 	Response     string             `json:"response"`
 	Usage        *api.UsageMetadata `json:"usage,omitempty"`
 	FinishReason string             `json:"finishReason,omitempty"`
+	Sources      []api.Source       `json:"sources,omitempty"`
+	Code         []CodeRun          `json:"code,omitempty"`
+	Candidates   []string           `json:"candidates,omitempty"` // all texts when several were requested
+	Artifacts    []string           `json:"artifacts,omitempty"`  // files saved in the artifacts directory
+	TraceID      string             `json:"traceId,omitempty"`    // for reports to the API provider
+	RequestID    string             `json:"requestId,omitempty"`
+	Cancelled    bool               `json:"cancelled,omitempty"` // the run was cancelled before the reply finished
+	Summary      *summary.Run       `json:"summary,omitempty"`   // files changed, commands run and tokens used
+}
+
+// CodeRun is code the model ran with the code execution tool
+type CodeRun struct {
+	Language string `json:"language,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Outcome  string `json:"outcome,omitempty"`
+	Output   string `json:"output,omitempty"`
 }
 
 // JSONError is the JSON error structure
@@ -116,22 +263,64 @@ type JSONError struct {
 }
 
 func (f *JSONFormatter) WriteResponse(resp *api.GenerateResponse) error {
-	out := JSONResponse{}
+	out := JSONResponse{TraceID: resp.TraceID, RequestID: resp.RequestID}
 	if resp.Response.UsageMetadata.TotalTokenCount > 0 {
 		out.Usage = &resp.Response.UsageMetadata
 	}
+	out.Sources = resp.Sources()
 	if len(resp.Response.Candidates) > 0 {
 		out.FinishReason = resp.Response.Candidates[0].FinishReason
-		if len(resp.Response.Candidates[0].Content.Parts) > 0 {
-			out.Response = sanitizeText(resp.Response.Candidates[0].Content.Parts[0].Text, f.sanitize)
+		var text strings.Builder
+		for _, part := range resp.Response.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+			if c := part.ExecutableCode; c != nil {
+				out.Code = append(out.Code, CodeRun{Language: c.Language, Code: c.Code})
+			}
+			if r := part.CodeExecutionResult; r != nil {
+				if len(out.Code) == 0 || out.Code[len(out.Code)-1].Outcome != "" {
+					out.Code = append(out.Code, CodeRun{})
+				}
+				run := &out.Code[len(out.Code)-1]
+				run.Outcome, run.Output = r.Outcome, sanitizeText(r.Output, f.sanitize)
+			}
 		}
+		out.Response = sanitizeText(text.String(), f.sanitize)
 	}
+	if len(resp.Response.Candidates) > 1 {
+		for _, c := range resp.Response.Candidates {
+			var text strings.Builder
+			for _, part := range c.Content.Parts {
+				text.WriteString(part.Text)
+			}
+			out.Candidates = append(out.Candidates, sanitizeText(text.String(), f.sanitize))
+		}
+	}
+	return f.encode(out)
+}
 
+func (f *JSONFormatter) encode(out JSONResponse) error {
+	if f.Artifacts != nil {
+		out.Artifacts = f.Artifacts()
+	}
+	if f.Summary != nil {
+		out.Summary = f.Summary()
+	}
+	f.written = true
 	enc := json.NewEncoder(f.w)
 	enc.SetIndent("", "  ")
 	return enc.Encode(out)
 }
 
+// WriteCancelled writes a response marked "cancelled" when the run was
+// cancelled before one was written, so that the output is still a valid
+// document.
+func (f *JSONFormatter) WriteCancelled() error {
+	if f.written {
+		return nil
+	}
+	return f.encode(JSONResponse{Cancelled: true})
+}
+
 func (f *JSONFormatter) WriteStreamEvent(event *api.StreamEvent) error {
 	// JSONFormatter collects all events, not used directly
 	return nil
@@ -146,19 +335,100 @@ func (f *JSONFormatter) WriteError(err error) error {
 	return enc.Encode(out)
 }
 
+// SetEventWriter writes tool calls, tool results and progress to w as
+// stream-json events, which JSON output otherwise leaves out.
+func (f *JSONFormatter) SetEventWriter(w io.Writer) {
+	f.events = &StreamJSONFormatter{w: w, errW: f.errW, sanitize: f.sanitize}
+}
+
+// SetInclude limits the events sent to the event writer to types.
+func (f *JSONFormatter) SetInclude(types []string) error {
+	if f.events == nil {
+		return fmt.Errorf("JSON output has no events to filter without --event-stream")
+	}
+	return f.events.SetInclude(types)
+}
+
 func (f *JSONFormatter) WriteToolCall(name string, args map[string]interface{}) error {
-	return nil // JSON formatter doesn't show intermediate tool calls
+	if f.events == nil {
+		return nil // JSON formatter doesn't show intermediate tool calls
+	}
+	return f.events.WriteToolCall(name, args)
 }
 
 func (f *JSONFormatter) WriteToolResult(name string, result map[string]interface{}, isError bool) error {
-	return nil // JSON formatter doesn't show intermediate tool results
+	if f.events == nil {
+		return nil // JSON formatter doesn't show intermediate tool results
+	}
+	return f.events.WriteToolResult(name, result, isError)
 }
 
+func (f *JSONFormatter) WriteProgress(stage, detail string) error {
+	if f.events == nil {
+		return nil // JSON formatter only writes the final result
+	}
+	return f.events.WriteProgress(stage, detail)
+}
+
+// StreamEventTypes are the event types stream-json output may contain.
+// "usage" is not emitted unless selected with SetInclude.
+var StreamEventTypes = []string{"start", "content", "code", "tool_call", "tool_result", "plan", "progress", "edit_proposal", "artifacts", "summary", "usage", "done", "cancelled", "error"}
+
 // StreamJSONFormatter outputs NDJSON (streaming)
 type StreamJSONFormatter struct {
 	w        io.Writer
 	errW     io.Writer
+	events   io.Writer // tool calls, results and progress; nil: w
 	sanitize bool
+	include  map[string]bool // event types written; nil: all but "usage"
+}
+
+// SetEventWriter sends the tool_call, tool_result, plan and progress
+// events to w, leaving the model's output on w alone.
+func (f *StreamJSONFormatter) SetEventWriter(w io.Writer) { f.events = w }
+
+// SetInclude limits the output to the given event types, for consumers
+// that would otherwise parse every content delta. "usage" adds an event
+// with the token counts of each model response, taken from its "done".
+// Errors are always written.
+func (f *StreamJSONFormatter) SetInclude(types []string) error {
+	include := map[string]bool{}
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if !slices.Contains(StreamEventTypes, t) {
+			return fmt.Errorf("unknown stream event type %q (use %s)", t, strings.Join(StreamEventTypes, ", "))
+		}
+		include[t] = true
+	}
+	f.include = include
+	return nil
+}
+
+func (f *StreamJSONFormatter) included(typ string) bool {
+	if f.include == nil {
+		return typ != "usage"
+	}
+	return f.include[typ]
+}
+
+func (f *StreamJSONFormatter) eventOut() io.Writer {
+	if f.events != nil {
+		return f.events
+	}
+	return f.w
+}
+
+// emit writes v as one line to w if events of type typ are included.
+func (f *StreamJSONFormatter) emit(w io.Writer, typ string, v interface{}) error {
+	if !f.included(typ) {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
 }
 
 func (f *StreamJSONFormatter) WriteResponse(resp *api.GenerateResponse) error {
@@ -171,46 +441,84 @@ func (f *StreamJSONFormatter) WriteStreamEvent(event *api.StreamEvent) error {
 	if e.Text != "" {
 		e.Text = sanitizeText(e.Text, f.sanitize)
 	}
-	data, err := json.Marshal(e)
-	if err != nil {
-		return err
+	if e.Type == "done" && e.Usage != nil {
+		if err := f.emit(f.w, "usage", api.StreamEvent{Type: "usage", Model: e.Model, Usage: e.Usage}); err != nil {
+			return err
+		}
 	}
-	_, err = f.w.Write(append(data, '\n'))
-	return err
+	return f.emit(f.w, e.Type, e)
 }
 
+// WriteError writes an error event whatever SetInclude selected.
 func (f *StreamJSONFormatter) WriteError(err error) error {
-	event := api.StreamEvent{Type: "error", Error: err.Error()}
-	data, _ := json.Marshal(event)
+	data, _ := json.Marshal(api.StreamEvent{Type: "error", Error: err.Error()})
 	_, writeErr := f.errW.Write(append(data, '\n'))
 	return writeErr
 }
 
 func (f *StreamJSONFormatter) WriteToolCall(name string, args map[string]interface{}) error {
-	event := map[string]interface{}{
+	return f.emit(f.eventOut(), "tool_call", map[string]interface{}{
 		"type": "tool_call",
 		"name": name,
 		"args": args,
-	}
-	data, err := json.Marshal(event)
-	if err != nil {
-		return err
-	}
-	_, err = f.w.Write(append(data, '\n'))
-	return err
+	})
 }
 
 func (f *StreamJSONFormatter) WriteToolResult(name string, result map[string]interface{}, isError bool) error {
-	event := map[string]interface{}{
+	err := f.emit(f.eventOut(), "tool_result", map[string]interface{}{
 		"type":     "tool_result",
 		"name":     name,
 		"result":   result,
 		"is_error": isError,
-	}
-	data, err := json.Marshal(event)
+	})
 	if err != nil {
 		return err
 	}
-	_, err = f.w.Write(append(data, '\n'))
-	return err
+
+	// The current todo list, for UIs that render a live checklist
+	if todos, ok := result["todos"]; ok && name == "write_todos" && !isError {
+		return f.emit(f.eventOut(), "plan", map[string]interface{}{
+			"type":  "plan",
+			"todos": todos,
+		})
+	}
+	return nil
+}
+
+// WriteEditProposal emits a file change that was not written, for an editor
+// to review and apply. oldContent is nil for a new file.
+func (f *StreamJSONFormatter) WriteEditProposal(path string, oldContent *string, newContent string) error {
+	return f.emit(f.w, "edit_proposal", map[string]interface{}{
+		"type":        "edit_proposal",
+		"file_path":   path,
+		"old_content": oldContent,
+		"new_content": newContent,
+	})
+}
+
+// WriteArtifacts emits the files the session saved in its artifacts
+// directory, for automation to collect.
+func (f *StreamJSONFormatter) WriteArtifacts(files []string) error {
+	return f.emit(f.w, "artifacts", map[string]interface{}{
+		"type":  "artifacts",
+		"files": files,
+	})
+}
+
+// WriteSummary emits what the run did as a "summary" event.
+func (f *StreamJSONFormatter) WriteSummary(s *summary.Run) error {
+	return f.emit(f.w, "summary", map[string]interface{}{
+		"type":    "summary",
+		"summary": s,
+	})
+}
+
+// WriteCancelled emits the terminal "cancelled" event of a run that was
+// cancelled, in place of "done".
+func (f *StreamJSONFormatter) WriteCancelled() error {
+	return f.emit(f.w, "cancelled", api.StreamEvent{Type: "cancelled"})
+}
+
+func (f *StreamJSONFormatter) WriteProgress(stage, detail string) error {
+	return f.emit(f.eventOut(), "progress", api.StreamEvent{Type: "progress", Stage: stage, Detail: detail})
 }
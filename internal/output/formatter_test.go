@@ -0,0 +1,188 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+func TestStreamJSONPlanEvent(t *testing.T) {
+	var out bytes.Buffer
+	f, err := NewFormatter("stream-json", &out, &out, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	todos := []map[string]interface{}{
+		{"id": "1", "title": "Write tests", "status": "completed"},
+		{"id": "2", "title": "Fix bug", "status": "in_progress"},
+	}
+	if err := f.WriteToolResult("write_todos", map[string]interface{}{"message": "updated", "todos": todos}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.WriteToolResult("read_file", map[string]interface{}{"todos": "not a plan"}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d events, want 3:\n%s", len(lines), out.String())
+	}
+	var plan struct {
+		Type  string `json:"type"`
+		Todos []struct {
+			Title  string `json:"title"`
+			Status string `json:"status"`
+		} `json:"todos"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &plan); err != nil {
+		t.Fatal(err)
+	}
+	if plan.Type != "plan" || len(plan.Todos) != 2 || plan.Todos[1].Status != "in_progress" {
+		t.Errorf("plan event = %s", lines[1])
+	}
+}
+
+func TestWriteProgress(t *testing.T) {
+	var out, errOut bytes.Buffer
+	f, _ := NewFormatter("stream-json", &out, &errOut, true)
+	f.WriteProgress("retry", "rate limited, retrying in 12s")
+	if got, want := out.String(), `{"type":"progress","stage":"retry","detail":"rate limited, retrying in 12s"}`+"\n"; got != want {
+		t.Errorf("stream-json progress = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	f, _ = NewFormatter("text", &out, &errOut, true)
+	f.WriteProgress("retry", "rate limited, retrying in 12s")
+	if out.Len() != 0 || !strings.Contains(errOut.String(), "rate limited, retrying in 12s") {
+		t.Errorf("text progress: stdout %q, stderr %q", out.String(), errOut.String())
+	}
+}
+
+func TestWriteEditProposal(t *testing.T) {
+	var out bytes.Buffer
+	f := &StreamJSONFormatter{w: &out, errW: &out}
+	old := "a\n"
+	f.WriteEditProposal("/src/a.txt", &old, "b\n")
+	f.WriteEditProposal("/src/new.txt", nil, "c\n")
+	want := `{"file_path":"/src/a.txt","new_content":"b\n","old_content":"a\n","type":"edit_proposal"}` + "\n" +
+		`{"file_path":"/src/new.txt","new_content":"c\n","old_content":null,"type":"edit_proposal"}` + "\n"
+	if out.String() != want {
+		t.Errorf("edit proposals =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteArtifacts(t *testing.T) {
+	var out, errOut bytes.Buffer
+	files := []string{"/src/.g/artifacts/s1/report.md"}
+
+	sf := &StreamJSONFormatter{w: &out, errW: &errOut}
+	sf.WriteArtifacts(files)
+	if want := `{"files":["/src/.g/artifacts/s1/report.md"],"type":"artifacts"}` + "\n"; out.String() != want {
+		t.Errorf("stream-json artifacts = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	jf := &JSONFormatter{w: &out, errW: &errOut, Artifacts: func() []string { return files }}
+	jf.WriteResponse(&api.GenerateResponse{})
+	var resp JSONResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil || len(resp.Artifacts) != 1 || resp.Artifacts[0] != files[0] {
+		t.Errorf("json artifacts = %s (%v)", out.String(), err)
+	}
+}
+
+func TestWriteCancelled(t *testing.T) {
+	var out, errOut bytes.Buffer
+	tf := &TextFormatter{w: &out, errW: &errOut}
+	tf.WriteStreamEvent(&api.StreamEvent{Type: "content", Text: "partial ans"})
+	tf.WriteCancelled()
+	if out.String() != "partial ans\n" || !strings.Contains(errOut.String(), "Cancelled") {
+		t.Errorf("text: stdout %q, stderr %q", out.String(), errOut.String())
+	}
+
+	out.Reset()
+	sf := &StreamJSONFormatter{w: &out, errW: &errOut}
+	sf.WriteCancelled()
+	if want := `{"type":"cancelled"}` + "\n"; out.String() != want {
+		t.Errorf("stream-json = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	jf := &JSONFormatter{w: &out, errW: &errOut}
+	jf.WriteCancelled()
+	var resp JSONResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil || !resp.Cancelled {
+		t.Errorf("json = %s (%v)", out.String(), err)
+	}
+
+	// A response already written is not followed by a second document
+	out.Reset()
+	jf = &JSONFormatter{w: &out, errW: &errOut}
+	jf.WriteResponse(&api.GenerateResponse{})
+	n := out.Len()
+	jf.WriteCancelled()
+	if out.Len() != n {
+		t.Errorf("json wrote again after the response: %s", out.String())
+	}
+}
+
+func TestSetEventWriter(t *testing.T) {
+	for _, format := range []string{"text", "json", "stream-json"} {
+		var out, errOut, events bytes.Buffer
+		f, _ := NewFormatter(format, &out, &errOut, true)
+		f.(interface{ SetEventWriter(io.Writer) }).SetEventWriter(&events)
+		f.WriteToolCall("read_file", map[string]interface{}{"file_path": "a.go"})
+		f.WriteToolResult("read_file", map[string]interface{}{"error": "not found"}, true)
+		f.WriteProgress("retry", "rate limited")
+		f.WriteStreamEvent(&api.StreamEvent{Type: "content", Text: "answer"})
+
+		if strings.Contains(out.String(), "read_file") {
+			t.Errorf("%s: events on stdout: %q", format, out.String())
+		}
+		if strings.Contains(errOut.String(), "read_file") {
+			t.Errorf("%s: events on stderr: %q", format, errOut.String())
+		}
+		if got := events.String(); !strings.Contains(got, "read_file") || !strings.Contains(got, "not found") || !strings.Contains(got, "rate limited") {
+			t.Errorf("%s: events = %q", format, got)
+		}
+		if format != "json" && !strings.Contains(out.String(), "answer") {
+			t.Errorf("%s: model text not on stdout: %q", format, out.String())
+		}
+	}
+}
+
+func TestStreamInclude(t *testing.T) {
+	var out bytes.Buffer
+	f := &StreamJSONFormatter{w: &out, errW: &out}
+	if err := f.SetInclude([]string{"tool_call", "usage"}); err != nil {
+		t.Fatal(err)
+	}
+	f.WriteStreamEvent(&api.StreamEvent{Type: "content", Text: "Hello"})
+	f.WriteToolCall("read_file", map[string]interface{}{"file_path": "a.go"})
+	f.WriteToolResult("read_file", map[string]interface{}{"content": "x"}, false)
+	f.WriteStreamEvent(&api.StreamEvent{Type: "done", Usage: &api.UsageMetadata{TotalTokenCount: 42}})
+
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var e api.StreamEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, e.Type)
+		if e.Type == "usage" && (e.Usage == nil || e.Usage.TotalTokenCount != 42) {
+			t.Errorf("usage event = %s", line)
+		}
+	}
+	if strings.Join(types, ",") != "tool_call,usage" {
+		t.Errorf("event types = %v, want tool_call,usage", types)
+	}
+
+	if err := f.SetInclude([]string{"deltas"}); err == nil {
+		t.Error("SetInclude accepted an unknown event type")
+	}
+}
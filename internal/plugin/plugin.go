@@ -0,0 +1,243 @@
+// Package plugin discovers executable tools in the user's tools.d
+// directory. Each executable describes itself when run with --describe and
+// is then called like an extension tool: arguments as a JSON object on
+// stdin, a JSON object (or plain text) on stdout.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k-sub1995/g/internal/diskcache"
+)
+
+// DescribeFlag is the argument that asks an executable to describe itself.
+const DescribeFlag = "--describe"
+
+// describeTimeout bounds the handshake, which runs at startup.
+const describeTimeout = 5 * time.Second
+
+// validName is what the model APIs accept as a function name.
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]{0,63}$`)
+
+// Plugin is an executable tool and the description it gave of itself.
+type Plugin struct {
+	Path        string          `json:"path"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"` // JSON Schema
+	Timeout     int             `json:"timeout,omitempty"`    // milliseconds
+}
+
+// Problem is an executable in tools.d that could not be used as a tool.
+type Problem struct {
+	Path string
+	Err  error
+}
+
+func (p Problem) Error() string {
+	return fmt.Sprintf("%s: %v", p.Path, p.Err)
+}
+
+// Dir returns the tools.d directory: $XDG_CONFIG_HOME/g/tools.d, by
+// default ~/.config/g/tools.d.
+func Dir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "g", "tools.d"), nil
+}
+
+// Discover describes the executables in dir, sorted by name. Descriptions
+// are cached until an executable changes, so only new and updated plugins
+// are run at startup. A missing directory has no plugins.
+func Discover(dir string) ([]Plugin, []Problem) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	c := openCache()
+	defer c.save()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		found    []Plugin
+		problems []Problem
+	)
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		info, err := os.Stat(path) // follows symlinks
+		if err != nil || !info.Mode().IsRegular() || strings.HasPrefix(e.Name(), ".") || !executable(info) {
+			continue
+		}
+		st := diskcache.StampOf(info)
+		if p, ok := c.get(path, st); ok {
+			mu.Lock()
+			found = append(found, p)
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := describe(path)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				problems = append(problems, Problem{Path: path, Err: err})
+				return
+			}
+			c.put(path, st, *p)
+			found = append(found, *p)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	var unique []Plugin
+	for i, p := range found {
+		if i > 0 && p.Name == found[i-1].Name {
+			problems = append(problems, Problem{Path: p.Path, Err: fmt.Errorf("tool %q is also provided by %s", p.Name, found[i-1].Path)})
+			continue
+		}
+		unique = append(unique, p)
+	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return unique, problems
+}
+
+// describe runs the handshake. The name defaults to the file name without
+// its extension.
+func describe(path string) (*Plugin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, DescribeFlag)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%s did not answer within %s", DescribeFlag, describeTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s failed: %v: %s", DescribeFlag, err, msg)
+		}
+		return nil, fmt.Errorf("%s failed: %v", DescribeFlag, err)
+	}
+
+	p := &Plugin{}
+	if err := json.Unmarshal(stdout.Bytes(), p); err != nil {
+		return nil, fmt.Errorf("%s output is not a JSON description: %v", DescribeFlag, err)
+	}
+	p.Path = path
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if !validName.MatchString(p.Name) {
+		return nil, fmt.Errorf("invalid tool name %q: use letters, digits, _ and -", p.Name)
+	}
+	if strings.TrimSpace(p.Description) == "" {
+		return nil, fmt.Errorf("tool %q has no description", p.Name)
+	}
+	if len(p.Parameters) > 0 {
+		var schema map[string]interface{}
+		if err := json.Unmarshal(p.Parameters, &schema); err != nil {
+			return nil, fmt.Errorf("tool %q: parameters must be a JSON Schema object", p.Name)
+		}
+	}
+	return p, nil
+}
+
+// executable reports whether a file in tools.d can be run: on Windows by
+// its extension, elsewhere by its permission bits.
+func executable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".exe", ".bat", ".cmd", ".com":
+			return true
+		}
+		return false
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// cacheFileName holds descriptions between runs.
+const (
+	cacheFileName = "g_plugins_cache.json"
+	cacheVersion  = 2
+)
+
+type cachedPlugin struct {
+	Stamp  diskcache.Stamp `json:"stamp"`
+	Plugin Plugin          `json:"plugin"`
+}
+
+// cache is the on-disk cache of descriptions, valid while the stamp of the
+// executable is unchanged. Its methods are safe for concurrent use.
+type cache struct {
+	Plugins map[string]cachedPlugin `json:"plugins"`
+
+	path  string
+	mu    sync.Mutex
+	seen  map[string]bool
+	dirty bool
+}
+
+func openCache() *cache {
+	c := &cache{seen: map[string]bool{}}
+	c.path = diskcache.Load(cacheFileName, cacheVersion, c)
+	if c.Plugins == nil {
+		c.Plugins = map[string]cachedPlugin{}
+	}
+	return c
+}
+
+func (c *cache) get(path string, st diskcache.Stamp) (Plugin, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen[path] = true
+	entry, ok := c.Plugins[path]
+	return entry.Plugin, ok && entry.Stamp == st
+}
+
+func (c *cache) put(path string, st diskcache.Stamp, p Plugin) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Plugins[path] = cachedPlugin{Stamp: st, Plugin: p}
+	c.dirty = true
+}
+
+// save drops entries for executables that are gone and writes the cache
+// if anything changed.
+func (c *cache) save() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for path := range c.Plugins {
+		if !c.seen[path] {
+			delete(c.Plugins, path)
+			c.dirty = true
+		}
+	}
+	if c.dirty {
+		diskcache.Save(c.path, cacheVersion, c)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	if err := os.MkdirAll(filepath.Join(home, ".gemini"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	write := func(name, script string, mode os.FileMode) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("jira.sh", `echo '{"description":"Looks up a ticket.","parameters":{"type":"object","properties":{"id":{"type":"string"}}}}'`, 0o755)
+	write("weather", `echo '{"name":"get_weather","description":"Returns the weather.","timeout":2000}'`, 0o755)
+	write("broken", "exit 3\n", 0o755)
+	write("bad name", `echo '{"name":"bad name","description":"x"}'`, 0o755)
+	write("notes.txt", "not a plugin\n", 0o644)
+
+	found, problems := Discover(dir)
+	var names []string
+	for _, p := range found {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "get_weather,jira" {
+		t.Errorf("plugins = %s", got)
+	}
+	if len(problems) != 2 || !strings.Contains(problems[0].Error(), "invalid tool name") || !strings.Contains(problems[1].Error(), "--describe failed") {
+		t.Errorf("problems = %v", problems)
+	}
+	if found[0].Timeout != 2000 || found[1].Path != filepath.Join(dir, "jira.sh") || len(found[1].Parameters) == 0 {
+		t.Errorf("plugins = %+v", found)
+	}
+
+	// The next run reads unchanged descriptions from the cache
+	write("weather", `echo '{"name":"get_weather","description":"Returns the weather."}'`, 0o755)
+	os.Remove(filepath.Join(dir, "broken"))
+	os.Remove(filepath.Join(dir, "bad name"))
+	c := openCache()
+	if _, ok := c.Plugins[filepath.Join(dir, "jira.sh")]; !ok {
+		t.Fatalf("jira.sh is not cached: %v", c.Plugins)
+	}
+	found, _ = Discover(dir)
+	if len(found) != 2 || found[0].Timeout != 0 {
+		t.Errorf("changed plugin was not described again: %+v", found)
+	}
+}
@@ -15,13 +15,20 @@ import (
 	"time"
 
 	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/memory"
+	"github.com/k-sub1995/g/internal/skills"
+	"github.com/k-sub1995/g/internal/subagent"
 )
 
 // Options configures system prompt generation.
 type Options struct {
 	WorkDir           string
 	Shell             string
-	ExtensionContexts []string // absolute paths to extension context files
+	ExtensionContexts []string              // absolute paths to extension context files
+	Skills            []skills.Skill        // skills the model can activate
+	Agents            []subagent.Definition // agents the task tool can run
+	Agent             *subagent.Definition  // custom agent this session runs as
+	ArtifactsDir      string                // where to save reports and exports ("": no convention)
 }
 
 // BuildSystemInstruction constructs the system prompt following gemini-cli patterns.
@@ -38,11 +45,27 @@ func BuildSystemInstruction(opts Options) *api.Content {
 		sections = append(sections, renderGitRepo())
 	}
 
+	if opts.ArtifactsDir != "" {
+		sections = append(sections, renderArtifacts(opts.ArtifactsDir))
+	}
+
+	if summary := skills.Summary(opts.Skills); summary != "" {
+		sections = append(sections, summary)
+	}
+
+	if summary := subagent.Summary(opts.Agents); summary != "" {
+		sections = append(sections, summary)
+	}
+
 	sections = append(sections, renderFinalReminder())
 
+	if opts.Agent != nil {
+		sections = append(sections, fmt.Sprintf("# Your Role: %s\n\n%s", opts.Agent.Name, opts.Agent.Prompt))
+	}
+
 	// Load user memory
-	if memory := loadUserMemory(opts.WorkDir); memory != "" {
-		sections = append(sections, "---\n\n"+memory)
+	if mem := memory.Load(opts.WorkDir); mem != "" {
+		sections = append(sections, "---\n\n"+mem)
 	}
 
 	// Load extension context files
@@ -194,6 +217,12 @@ func renderGitRepo() string {
 - Never push changes to a remote repository without being asked explicitly by the user.`
 }
 
+func renderArtifacts(dir string) string {
+	return `# Artifacts
+- Save generated deliverables that are not part of the codebase (reports, summaries, generated images, data exports) under ` + "`" + filepath.ToSlash(dir) + "/`" + `, creating it as needed. Never put them there if the user asked for a change to the project itself.
+- Files in this directory are listed to the user when the session ends, so choose descriptive file names and mention the important ones in your answer.`
+}
+
 func renderFinalReminder() string {
 	return `# Final Reminder
 Your core function is efficient and safe assistance. Balance extreme conciseness with the crucial need for clarity, especially regarding safety and potential system modifications. Always prioritize user control and project conventions. Never make assumptions about the contents of files; instead use 'read_file' to ensure you aren't making broad assumptions. Finally, you are an agent - please keep going until the user's query is completely resolved.`
@@ -206,22 +235,14 @@ func isGitRepo(dir string) bool {
 	return err == nil
 }
 
-func loadUserMemory(workDir string) string {
-	candidates := []string{
-		filepath.Join(workDir, "GEMINI.md"),
-		filepath.Join(workDir, ".gemini", "GEMINI.md"),
-	}
-	home, _ := os.UserHomeDir()
-	if home != "" {
-		candidates = append(candidates, filepath.Join(home, ".gemini", "GEMINI.md"))
-	}
-
-	var parts []string
-	for _, path := range candidates {
-		data, err := os.ReadFile(path)
-		if err == nil && len(data) > 0 {
-			parts = append(parts, strings.TrimSpace(string(data)))
+// ContextFiles returns the memory and extension context files that
+// BuildSystemInstruction adds to the prompt for opts, in order.
+func ContextFiles(opts Options) []string {
+	files := memory.Files(opts.WorkDir)
+	for _, ctxFile := range opts.ExtensionContexts {
+		if info, err := os.Stat(ctxFile); err == nil && info.Size() > 0 {
+			files = append(files, ctxFile)
 		}
 	}
-	return strings.Join(parts, "\n\n")
+	return files
 }
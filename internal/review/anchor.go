@@ -0,0 +1,221 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package review
+
+import (
+	"strconv"
+	"strings"
+)
+
+// hunk is a run of consecutive lines of a new file shown in a diff, both
+// added and context lines.
+type hunk struct {
+	start int // new-file line number of lines[0]
+	lines []string
+}
+
+func (h hunk) contains(line int) bool {
+	return line >= h.start && line < h.start+len(h.lines)
+}
+
+// parseDiff returns the hunks of each file in a unified diff, keyed by the
+// path of the new file. Deleted files have no entry. Hunks end after the
+// line counts in their header, so a truncated diff yields what it shows.
+func parseDiff(diff string) map[string][]hunk {
+	files := map[string][]hunk{}
+	var path string
+	var cur *hunk
+	var oldLeft, newLeft int
+	flush := func() {
+		if cur != nil && path != "" && len(cur.lines) > 0 {
+			files[path] = append(files[path], *cur)
+		}
+		cur = nil
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if cur == nil {
+			switch {
+			case strings.HasPrefix(line, "diff "):
+				path = ""
+			case strings.HasPrefix(line, "+++ "):
+				path = diffPath(line[4:])
+			case strings.HasPrefix(line, "@@ "):
+				if start, oldCount, newCount, ok := hunkHeader(line); ok {
+					cur, oldLeft, newLeft = &hunk{start: start}, oldCount, newCount
+				}
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "+"):
+			cur.lines = append(cur.lines, line[1:])
+			newLeft--
+		case strings.HasPrefix(line, "-"):
+			oldLeft--
+		case strings.HasPrefix(line, " "), line == "":
+			// a context line; an empty one may have lost its space
+			cur.lines = append(cur.lines, strings.TrimPrefix(line, " "))
+			oldLeft--
+			newLeft--
+		case strings.HasPrefix(line, `\`): // "\ No newline at end of file"
+		default:
+			flush()
+			continue
+		}
+		if oldLeft <= 0 && newLeft <= 0 {
+			flush()
+		}
+	}
+	flush()
+	return files
+}
+
+// diffPath extracts the repository path from a "+++" header: b/dir/file,
+// possibly quoted, or /dev/null for a deleted file.
+func diffPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	if strings.HasPrefix(s, `"`) {
+		if u, err := strconv.Unquote(s); err == nil {
+			s = u
+		}
+	}
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, "b/")
+}
+
+// hunkHeader parses "@@ -a,b +c,d @@" into the new-file start line and the
+// old and new line counts.
+func hunkHeader(header string) (start, oldCount, newCount int, ok bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, false
+	}
+	_, oldCount, ok1 := hunkRange(fields[1][1:])
+	start, newCount, ok2 := hunkRange(fields[2][1:])
+	return start, oldCount, newCount, ok1 && ok2
+}
+
+// hunkRange parses "start,count"; the count defaults to 1.
+func hunkRange(s string) (start, count int, ok bool) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
+
+// Anchor checks each finding's line against the diff it was made on, so
+// that annotations land where the model meant them to. A finding that quotes
+// code is moved to where that code is in the diff, nearest the line it
+// claimed. A line that is not shown in the diff and cannot be placed by the
+// quoted code is dropped, leaving a file-level finding. Findings on files
+// outside the diff are left as they are.
+func Anchor(r *Result, diff string) {
+	files := parseDiff(diff)
+	for i := range r.Findings {
+		f := &r.Findings[i]
+		hunks, ok := files[f.File]
+		if !ok || f.Line <= 0 && f.Code == "" {
+			continue
+		}
+		if line, ok := locate(hunks, f.Code, f.Line); ok {
+			f.Line = line
+			continue
+		}
+		if !inHunks(hunks, f.Line) {
+			f.Line = 0
+		}
+	}
+}
+
+// locate finds where the quoted code starts in the hunks. A match that
+// covers claimed keeps it; otherwise the nearest match wins.
+func locate(hunks []hunk, code string, claimed int) (int, bool) {
+	want := quotedLines(code)
+	if len(want) == 0 {
+		return 0, false
+	}
+	best, found := 0, false
+	for _, h := range hunks {
+		for i := 0; i+len(want) <= len(h.lines); i++ {
+			if !matchAt(h.lines[i:], want) {
+				continue
+			}
+			start := h.start + i
+			if claimed >= start && claimed < start+len(want) {
+				return claimed, true
+			}
+			if !found || distance(start, claimed) < distance(best, claimed) {
+				best, found = start, true
+			}
+		}
+	}
+	return best, found
+}
+
+func matchAt(lines, want []string) bool {
+	for j, w := range want {
+		if normalizeSpace(lines[j]) != w {
+			return false
+		}
+	}
+	return true
+}
+
+// quotedLines splits quoted code into whitespace-normalized lines, without
+// surrounding blank lines or the "+" markers of code copied from the diff.
+func quotedLines(code string) []string {
+	lines := strings.Split(strings.Trim(code, "\r\n"), "\n")
+	marked := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" && !strings.HasPrefix(l, "+") {
+			marked = false
+		}
+	}
+	var out []string
+	for _, l := range lines {
+		if marked {
+			l = strings.TrimPrefix(l, "+")
+		}
+		out = append(out, normalizeSpace(l))
+	}
+	for len(out) > 0 && out[0] == "" {
+		out = out[1:]
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func inHunks(hunks []hunk, line int) bool {
+	for _, h := range hunks {
+		if h.contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func distance(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package review
+
+import "testing"
+
+const anchorDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -10,3 +10,5 @@ func main() {
+ 	cfg := load()
+-	run(cfg)
++	if err := run(cfg); err != nil {
++		log.Print(err)
++	}
+ }
+@@ -40,2 +41,3 @@ func run(cfg *Config) error {
+ 	f, _ := os.Open(cfg.Path)
++	defer f.Close()
+ 	return nil
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,1 +0,0 @@
+-package main
+`
+
+func TestParseDiff(t *testing.T) {
+	files := parseDiff(anchorDiff)
+	hunks := files["main.go"]
+	if len(files) != 1 || len(hunks) != 2 {
+		t.Fatalf("parseDiff = %+v", files)
+	}
+	if h := hunks[1]; h.start != 41 || len(h.lines) != 3 || h.lines[1] != "\tdefer f.Close()" {
+		t.Errorf("second hunk = %+v", h)
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	r := Result{Findings: []Finding{
+		{File: "main.go", Line: 11, Code: "if err := run(cfg); err != nil {"}, // right
+		{File: "main.go", Line: 40, Code: "+\tdefer f.Close()"},               // off by two
+		{File: "main.go", Line: 13, Code: "log.Print(err)\n}"},                // inside a quoted block
+		{File: "main.go", Line: 30, Code: "panic(err)"},                       // no such code
+		{File: "main.go", Line: 12},                                           // no quote, shown in the diff
+		{File: "main.go", Line: 100},                                          // no quote, not in the diff
+		{File: "util.go", Line: 7, Code: "x := 1"},                            // outside the diff
+	}}
+	Anchor(&r, anchorDiff)
+
+	want := []int{11, 42, 13, 0, 12, 0, 7}
+	for i, f := range r.Findings {
+		if f.Line != want[i] {
+			t.Errorf("finding %d: line %d, want %d", i, f.Line, want[i])
+		}
+	}
+}
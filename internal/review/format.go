@@ -0,0 +1,200 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{"text", "json", "sarif", "github"}
+
+// Write renders r to w in the given format.
+func Write(w io.Writer, format string, r Result) error {
+	switch format {
+	case "text":
+		return writeText(w, r)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if r.Findings == nil {
+			r.Findings = []Finding{}
+		}
+		return enc.Encode(r)
+	case "sarif":
+		return writeSARIF(w, r)
+	case "github":
+		return writeGitHub(w, r)
+	default:
+		return fmt.Errorf("unknown format %q (use %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+func writeText(w io.Writer, r Result) error {
+	if r.Summary != "" {
+		fmt.Fprintf(w, "%s\n\n", r.Summary)
+	}
+	if len(r.Findings) == 0 {
+		fmt.Fprintln(w, "No findings.")
+		return nil
+	}
+	for _, f := range r.Findings {
+		loc := f.File
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Fprintf(w, "[%s] %s", strings.ToUpper(f.Severity), f.Title)
+		if loc != "" {
+			fmt.Fprintf(w, " (%s)", loc)
+		}
+		fmt.Fprintln(w)
+		if f.Message != "" {
+			fmt.Fprintf(w, "  %s\n", indent(f.Message))
+		}
+		if f.Suggestion != "" {
+			fmt.Fprintf(w, "  Suggestion: %s\n", indent(f.Suggestion))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d finding(s)\n", len(r.Findings))
+	return nil
+}
+
+func indent(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n  ")
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(s Severity) string {
+	switch {
+	case s >= SeverityHigh:
+		return "error"
+	case s == SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func writeSARIF(w io.Writer, r Result) error {
+	type region struct {
+		StartLine int `json:"startLine,omitempty"`
+	}
+	type physicalLocation struct {
+		ArtifactLocation map[string]string `json:"artifactLocation"`
+		Region           *region           `json:"region,omitempty"`
+	}
+	type location struct {
+		PhysicalLocation physicalLocation `json:"physicalLocation"`
+	}
+	type message struct {
+		Text string `json:"text"`
+	}
+	type result struct {
+		RuleID     string                 `json:"ruleId"`
+		Level      string                 `json:"level"`
+		Message    message                `json:"message"`
+		Locations  []location             `json:"locations,omitempty"`
+		Properties map[string]interface{} `json:"properties,omitempty"`
+	}
+
+	results := []result{}
+	rules := []map[string]interface{}{}
+	seenRules := map[string]bool{}
+	for _, f := range r.Findings {
+		ruleID := f.Category
+		if ruleID == "" {
+			ruleID = "review"
+		}
+		if !seenRules[ruleID] {
+			seenRules[ruleID] = true
+			rules = append(rules, map[string]interface{}{"id": ruleID})
+		}
+		text := f.Title
+		if f.Message != "" {
+			text += "\n\n" + f.Message
+		}
+		if f.Suggestion != "" {
+			text += "\n\nSuggestion: " + f.Suggestion
+		}
+		res := result{
+			RuleID:     ruleID,
+			Level:      sarifLevel(f.Level()),
+			Message:    message{Text: text},
+			Properties: map[string]interface{}{"severity": f.Severity},
+		}
+		if f.File != "" {
+			loc := location{PhysicalLocation: physicalLocation{ArtifactLocation: map[string]string{"uri": f.File}}}
+			if f.Line > 0 {
+				loc.PhysicalLocation.Region = &region{StartLine: f.Line}
+			}
+			res.Locations = []location{loc}
+		}
+		results = append(results, res)
+	}
+
+	doc := map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "g review",
+						"informationUri": "https://github.com/k-sub1995/g",
+						"rules":          rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// writeGitHub emits GitHub Actions workflow commands, which show up as
+// annotations on the pull request.
+func writeGitHub(w io.Writer, r Result) error {
+	for _, f := range r.Findings {
+		command := "notice"
+		switch {
+		case f.Level() >= SeverityHigh:
+			command = "error"
+		case f.Level() == SeverityMedium:
+			command = "warning"
+		}
+		var props []string
+		if f.File != "" {
+			props = append(props, "file="+escapeProperty(f.File))
+			if f.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", f.Line))
+			}
+		}
+		props = append(props, "title="+escapeProperty(fmt.Sprintf("[%s] %s", f.Severity, f.Title)))
+		text := f.Message
+		if text == "" {
+			text = f.Title
+		}
+		if f.Suggestion != "" {
+			text += "\n\nSuggestion: " + f.Suggestion
+		}
+		fmt.Fprintf(w, "::%s %s::%s\n", command, strings.Join(props, ","), escapeData(text))
+	}
+	if r.Summary != "" {
+		fmt.Fprintf(w, "::notice title=g review::%s\n", escapeData(r.Summary))
+	}
+	return nil
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
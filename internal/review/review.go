@@ -0,0 +1,213 @@
+// Package review implements structured code review of a change set.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// Severity ranks a finding. Higher is more severe.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = []string{"info", "low", "medium", "high", "critical"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return "info"
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity converts a severity name; unknown names are an error.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return Severity(i), nil
+		}
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q (use %s)", name, strings.Join(severityNames, ", "))
+}
+
+// Finding is a single review comment.
+type Finding struct {
+	Severity   string `json:"severity"`
+	Category   string `json:"category,omitempty"` // bug, security, performance, ...
+	File       string `json:"file,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Code       string `json:"code,omitempty"` // the code at Line, quoted to check the line (see Anchor)
+	Title      string `json:"title"`
+	Message    string `json:"message,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Level returns the parsed severity of f, treating unknown values as info.
+func (f Finding) Level() Severity {
+	s, _ := ParseSeverity(f.Severity)
+	return s
+}
+
+// Result is the outcome of a review.
+type Result struct {
+	Summary  string    `json:"summary"`
+	Findings []Finding `json:"findings"`
+}
+
+// CountAtLeast returns how many findings are at least as severe as min.
+func (r *Result) CountAtLeast(min Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Level() >= min {
+			n++
+		}
+	}
+	return n
+}
+
+// Collector receives findings from the model through the report tool.
+type Collector struct {
+	mu       sync.Mutex
+	result   Result
+	reported bool
+}
+
+// Result returns the collected findings and whether the model reported.
+func (c *Collector) Result() (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.result, c.reported
+}
+
+// ReportTool is the tool the model calls to submit its findings.
+type ReportTool struct {
+	c *Collector
+}
+
+// NewReportTool creates the report_findings tool backed by c.
+func NewReportTool(c *Collector) *ReportTool {
+	return &ReportTool{c: c}
+}
+
+func (t *ReportTool) Name() string { return "report_findings" }
+
+func (t *ReportTool) Declaration() api.FunctionDecl {
+	return api.FunctionDecl{
+		Name:        "report_findings",
+		Description: "Submit the final code review. Call exactly once, after investigating the change, with every finding (an empty list if the change looks good).",
+		Parameters: mustMarshal(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"summary": map[string]interface{}{
+					"type":        "string",
+					"description": "One-paragraph overall assessment of the change.",
+				},
+				"findings": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"severity": map[string]interface{}{
+								"type": "string",
+								"enum": severityNames,
+							},
+							"category": map[string]interface{}{
+								"type":        "string",
+								"description": "bug, security, performance, error-handling, tests, maintainability or style.",
+							},
+							"file": map[string]interface{}{
+								"type":        "string",
+								"description": "Path relative to the repository root.",
+							},
+							"line": map[string]interface{}{
+								"type":        "integer",
+								"description": "Line number in the new version of the file.",
+							},
+							"code": map[string]interface{}{
+								"type":        "string",
+								"description": "The code at that line copied verbatim from the new version (without diff markers), used to place the comment exactly.",
+							},
+							"title":      map[string]interface{}{"type": "string", "description": "Short one-line description."},
+							"message":    map[string]interface{}{"type": "string", "description": "Explanation of the problem."},
+							"suggestion": map[string]interface{}{"type": "string", "description": "Concrete fix, optionally as replacement code."},
+						},
+						"required": []string{"severity", "title"},
+					},
+				},
+			},
+			"required": []string{"summary", "findings"},
+		}),
+	}
+}
+
+func (t *ReportTool) Execute(ctx context.Context, args map[string]interface{}) (*tools.ToolResult, error) {
+	var result Result
+	if err := remarshal(args, &result); err != nil {
+		return &tools.ToolResult{Content: map[string]interface{}{"error": fmt.Sprintf("invalid findings: %v", err)}, IsError: true}, nil
+	}
+	for i := range result.Findings {
+		f := &result.Findings[i]
+		f.Severity = f.Level().String()
+		f.File = strings.TrimPrefix(f.File, "./")
+	}
+
+	t.c.mu.Lock()
+	t.c.result = result
+	t.c.reported = true
+	t.c.mu.Unlock()
+
+	return &tools.ToolResult{Content: map[string]interface{}{
+		"recorded": len(result.Findings),
+		"message":  "Findings recorded. The review is complete; do not call any more tools.",
+	}}, nil
+}
+
+// Prompt builds the user message asking for a review of diff.
+func Prompt(source, diff string) string {
+	var b strings.Builder
+	b.WriteString("You are reviewing a code change (" + source + ") in the current repository.\n\n")
+	b.WriteString("Review it like a careful senior engineer: look for bugs, security problems, missing error handling, race conditions, performance issues, missing tests and unclear code. ")
+	b.WriteString("Use the read-only tools to inspect surrounding code when the diff alone is not enough. ")
+	b.WriteString("Only report issues introduced or exposed by this change, with file paths relative to the repository root and line numbers from the new version of the file. ")
+	b.WriteString("Quote the code each finding is about in its code field; comments are placed where that code is. ")
+	b.WriteString("Do not report purely subjective style preferences.\n\n")
+	b.WriteString("When done, call report_findings exactly once.\n\n")
+	b.WriteString("```diff\n")
+	b.WriteString(diff)
+	if !strings.HasSuffix(diff, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("```\n")
+	return b.String()
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// remarshal converts loosely typed tool arguments into out.
+func remarshal(in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
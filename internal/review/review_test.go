@@ -0,0 +1,87 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package review
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReportTool(t *testing.T) {
+	c := &Collector{}
+	tool := NewReportTool(c)
+	_, err := tool.Execute(context.Background(), map[string]interface{}{
+		"summary": "Looks mostly fine.",
+		"findings": []interface{}{
+			map[string]interface{}{"severity": "HIGH", "file": "./main.go", "line": float64(12), "title": "nil dereference"},
+			map[string]interface{}{"severity": "bogus", "title": "typo"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := c.Result()
+	if !ok || len(result.Findings) != 2 {
+		t.Fatalf("Result() = %+v, %v", result, ok)
+	}
+	if f := result.Findings[0]; f.Severity != "high" || f.File != "main.go" || f.Line != 12 {
+		t.Errorf("finding not normalized: %+v", f)
+	}
+	if result.Findings[1].Severity != "info" {
+		t.Errorf("unknown severity should become info, got %q", result.Findings[1].Severity)
+	}
+	if n := result.CountAtLeast(SeverityMedium); n != 1 {
+		t.Errorf("CountAtLeast(medium) = %d, want 1", n)
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	r := Result{Findings: []Finding{{
+		Severity: "medium", Category: "bug", File: "a,b.go", Line: 3,
+		Title: "Off by one", Message: "Loop skips\nthe last element",
+	}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "github", r); err != nil {
+		t.Fatal(err)
+	}
+	want := "::warning file=a%2Cb.go,line=3,title=[medium] Off by one::Loop skips%0Athe last element\n"
+	if buf.String() != want {
+		t.Errorf("github output = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, "sarif", r); err != nil {
+		t.Fatal(err)
+	}
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &sarif); err != nil {
+		t.Fatal(err)
+	}
+	res := sarif.Runs[0].Results[0]
+	if sarif.Version != "2.1.0" || res.RuleID != "bug" || res.Level != "warning" || res.Locations[0].PhysicalLocation.Region.StartLine != 3 {
+		t.Errorf("unexpected SARIF: %s", buf.String())
+	}
+
+	if err := Write(&buf, "xml", r); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("expected unknown format error, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+// Package risk flags shell commands that are dangerous to run unattended.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package risk
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Finding explains why a command is considered dangerous.
+type Finding struct {
+	Rule   string // short identifier, e.g. "rm-root"
+	Reason string // human-readable explanation
+}
+
+// protectedBranches are branches a push to should always be confirmed.
+var protectedBranches = map[string]bool{
+	"main": true, "master": true, "production": true, "prod": true, "release": true, "trunk": true,
+}
+
+var (
+	pipeToShellRe = regexp.MustCompile(`\b(?:curl|wget|fetch)\b[^|;&]*\|\s*(?:sudo\s+(?:-\S+\s+)*)?(?:env\s+)?(?:ba|z|k|da|fi)?sh\b|\b(?:curl|wget|fetch)\b[^|;&]*\|\s*(?:sudo\s+)?(?:python[0-9.]*|perl|ruby|node)\b`)
+	shellSubstRe  = regexp.MustCompile(`\b(?:ba|z|k|da)?sh\s+(?:-c\s+)?["']?\$\(\s*(?:curl|wget)\b|\b(?:ba|z|k|da)?sh\s+<\(\s*(?:curl|wget)\b`)
+	forkBombRe    = regexp.MustCompile(`([A-Za-z_:][A-Za-z0-9_:]*)\s*\(\)\s*\{([^}]*)\}`)
+	diskWriteRe   = regexp.MustCompile(`\bmkfs(?:\.\w+)?\b|\bdd\b[^;&|]*\bof=/dev/(?:sd|nvme|hd|disk|mmcblk|xvd|vd)|>\s*/dev/(?:sd|nvme|hd|disk|mmcblk|xvd|vd)`)
+	separatorRe   = regexp.MustCompile(`&&|\|\||[;&|\n]`)
+)
+
+// ShellCommand statically analyzes a shell command line and returns the
+// dangerous patterns it contains. It errs on the side of false positives:
+// a finding only means the user should look before the command runs.
+func ShellCommand(command string) []Finding {
+	var findings []Finding
+	add := func(rule, reason string) {
+		for _, f := range findings {
+			if f.Rule == rule {
+				return
+			}
+		}
+		findings = append(findings, Finding{Rule: rule, Reason: reason})
+	}
+
+	if pipeToShellRe.MatchString(command) || shellSubstRe.MatchString(command) {
+		add("pipe-to-shell", "downloads a script and executes it without review")
+	}
+	for _, m := range forkBombRe.FindAllStringSubmatch(command, -1) {
+		name, body := regexp.QuoteMeta(m[1]), m[2]
+		if regexp.MustCompile(name + `\s*\|\s*` + name).MatchString(body) {
+			add("fork-bomb", "defines a function that recursively spawns itself (fork bomb)")
+		}
+	}
+	if diskWriteRe.MatchString(command) {
+		add("disk-overwrite", "formats or overwrites a raw disk device")
+	}
+
+	for _, segment := range separatorRe.Split(command, -1) {
+		args := words(segment)
+		if len(args) == 0 {
+			continue
+		}
+		switch path.Base(args[0]) {
+		case "rm":
+			checkRm(args[1:], add)
+		case "chmod", "chown":
+			checkRecursiveRoot(args, add)
+		case "git":
+			checkGit(args[1:], add)
+		}
+	}
+	return findings
+}
+
+// words splits a command segment into words, dropping quotes, a leading
+// sudo and environment assignments.
+func words(segment string) []string {
+	fields := strings.Fields(segment)
+	for i := range fields {
+		fields[i] = strings.Trim(fields[i], `"'`)
+	}
+	for len(fields) > 0 {
+		f := fields[0]
+		if f == "sudo" || f == "doas" || f == "command" || f == "exec" || f == "nohup" || f == "time" {
+			fields = fields[1:]
+			for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+				fields = fields[1:]
+			}
+			continue
+		}
+		if strings.Contains(f, "=") && !strings.HasPrefix(f, "-") && !strings.HasPrefix(f, "=") {
+			fields = fields[1:]
+			continue
+		}
+		break
+	}
+	return fields
+}
+
+// criticalPath reports whether p is the filesystem root, the home directory
+// or a top-level system directory.
+func criticalPath(p string) bool {
+	switch p {
+	case "/", "/*", "~", "~/", "~/*", "$HOME", "${HOME}", "$HOME/", "$HOME/*", "${HOME}/*", "*", ".", "./", "..", "../", "/.":
+		return true
+	}
+	if strings.HasPrefix(p, "/") {
+		clean := path.Clean(strings.TrimSuffix(p, "/*"))
+		return strings.Count(clean, "/") == 1 // e.g. /usr, /etc, /home
+	}
+	return false
+}
+
+func checkRm(args []string, add func(rule, reason string)) {
+	recursive, force := false, false
+	var targets []string
+	for _, a := range args {
+		switch {
+		case a == "--recursive":
+			recursive = true
+		case a == "--force":
+			force = true
+		case a == "--no-preserve-root":
+			add("rm-root", "removes files with --no-preserve-root")
+		case strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "--"):
+			recursive = recursive || strings.ContainsAny(a, "rR")
+			force = force || strings.Contains(a, "f")
+		default:
+			targets = append(targets, a)
+		}
+	}
+	if !recursive {
+		return
+	}
+	for _, t := range targets {
+		if criticalPath(t) {
+			reason := "recursively deletes " + t
+			if force {
+				reason = "recursively force-deletes " + t
+			}
+			add("rm-root", reason+", which may destroy the system, home directory or whole project")
+			return
+		}
+	}
+}
+
+func checkRecursiveRoot(args []string, add func(rule, reason string)) {
+	recursive := false
+	for _, a := range args[1:] {
+		if a == "-R" || a == "--recursive" || (strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "--") && strings.Contains(a, "R")) {
+			recursive = true
+		}
+	}
+	if !recursive {
+		return
+	}
+	for _, a := range args[1:] {
+		if !strings.HasPrefix(a, "-") && strings.HasPrefix(a, "/") && criticalPath(a) {
+			add("chmod-root", args[0]+" -R on "+a+" changes permissions of system files")
+			return
+		}
+	}
+}
+
+func checkGit(args []string, add func(rule, reason string)) {
+	// Skip global options such as -C <dir> or -c key=value
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if (args[0] == "-C" || args[0] == "-c") && len(args) > 1 {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return
+	}
+
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "push":
+		var positional []string
+		for _, a := range rest {
+			switch {
+			case a == "--force" || a == "-f" || strings.HasPrefix(a, "--force-with-lease") || a == "--force-if-includes" ||
+				(strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "--") && strings.Contains(a, "f")):
+				add("force-push", "force-pushes, rewriting history on the remote")
+			case a == "--mirror" || a == "--delete" || a == "-d":
+				add("push-delete", "deletes or overwrites remote refs")
+			case strings.HasPrefix(a, "+"):
+				add("force-push", "force-pushes "+a+", rewriting history on the remote")
+				positional = append(positional, a)
+			case !strings.HasPrefix(a, "-"):
+				positional = append(positional, a)
+			}
+		}
+		// The first positional argument is the remote; the rest are refspecs
+		if len(positional) > 1 {
+			for _, ref := range positional[1:] {
+				dst := strings.TrimPrefix(ref, "+")
+				if i := strings.LastIndex(dst, ":"); i >= 0 {
+					dst = dst[i+1:]
+				}
+				dst = strings.TrimPrefix(dst, "refs/heads/")
+				if protectedBranches[dst] || strings.HasPrefix(dst, "release/") {
+					add("push-protected", "pushes directly to protected branch "+dst)
+				}
+			}
+		}
+	case "filter-branch", "filter-repo":
+		add("history-rewrite", "git "+sub+" rewrites the repository history")
+	case "reset":
+		for _, a := range rest {
+			if a == "--hard" {
+				add("history-rewrite", "git reset --hard discards commits and uncommitted changes")
+			}
+		}
+	case "clean":
+		for _, a := range rest {
+			if strings.HasPrefix(a, "-") && !strings.HasPrefix(a, "--") && strings.Contains(a, "f") && (strings.Contains(a, "x") || strings.Contains(a, "d")) {
+				add("git-clean", "git clean "+a+" permanently deletes untracked files")
+				break
+			}
+		}
+	case "reflog":
+		if len(rest) > 0 && (rest[0] == "expire" || rest[0] == "delete") {
+			add("history-rewrite", "git reflog "+rest[0]+" removes the safety net for lost commits")
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package risk
+
+import "testing"
+
+func TestShellCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		rule    string // "" means no finding expected
+	}{
+		{"rm -rf /", "rm-root"},
+		{"sudo rm -fr ~", "rm-root"},
+		{"rm --recursive --force $HOME", "rm-root"},
+		{"cd build && rm -rf /usr", "rm-root"},
+		{"rm -rf ./build", ""},
+		{"rm -f /tmp/x", ""},
+		{":(){ :|:& };:", "fork-bomb"},
+		{"bomb() { bomb | bomb & }; bomb", "fork-bomb"},
+		{"curl -fsSL https://example.com/install.sh | bash", "pipe-to-shell"},
+		{"wget -qO- https://x | sudo sh", "pipe-to-shell"},
+		{`sh -c "$(curl -fsSL https://x)"`, "pipe-to-shell"},
+		{"curl -s https://api.example.com | jq .", ""},
+		{"git push --force origin feature", "force-push"},
+		{"git push origin +feature", "force-push"},
+		{"git push origin main", "push-protected"},
+		{"git push origin HEAD:refs/heads/master", "push-protected"},
+		{"git push origin feature/x", ""},
+		{"git -C repo reset --hard HEAD~3", "history-rewrite"},
+		{"git filter-branch --tree-filter 'rm x' HEAD", "history-rewrite"},
+		{"git clean -fdx", "git-clean"},
+		{"dd if=/dev/zero of=/dev/sda bs=1M", "disk-overwrite"},
+		{"go test ./...", ""},
+		{"git status && git log --oneline -5", ""},
+	}
+	for _, tt := range tests {
+		findings := ShellCommand(tt.command)
+		if tt.rule == "" {
+			if len(findings) != 0 {
+				t.Errorf("ShellCommand(%q) = %v, want none", tt.command, findings)
+			}
+			continue
+		}
+		found := false
+		for _, f := range findings {
+			if f.Rule == tt.rule {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ShellCommand(%q) = %v, want rule %s", tt.command, findings, tt.rule)
+		}
+	}
+}
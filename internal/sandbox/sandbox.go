@@ -0,0 +1,137 @@
+// Package sandbox runs shell commands inside a container.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// DefaultImage is used when no image is configured.
+const DefaultImage = "docker.io/library/ubuntu:24.04"
+
+// Options configures a container sandbox.
+type Options struct {
+	Runtime string // "docker" or "podman"
+	Image   string
+	WorkDir string // bind-mounted read-write at the same path
+	Network bool   // allow network access (off by default)
+	Debug   bool
+}
+
+// Container is a long-lived container that shell commands are executed in.
+// It is started on first use and removed by Close.
+type Container struct {
+	opts Options
+
+	mu  sync.Mutex
+	id  string
+	err error
+}
+
+// New validates opts and returns a container that has not been started yet.
+func New(opts Options) (*Container, error) {
+	switch opts.Runtime {
+	case "docker", "podman":
+	default:
+		return nil, fmt.Errorf("unsupported sandbox runtime %q (use docker or podman)", opts.Runtime)
+	}
+	if _, err := exec.LookPath(opts.Runtime); err != nil {
+		return nil, fmt.Errorf("sandbox runtime %s not found in PATH", opts.Runtime)
+	}
+	if opts.Image == "" {
+		opts.Image = DefaultImage
+	}
+	abs, err := filepath.Abs(opts.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	opts.WorkDir = abs
+	return &Container{opts: opts}, nil
+}
+
+// Describe returns a short description of the sandbox for the model.
+func (c *Container) Describe() string {
+	if c.opts.Network {
+		return "an isolated " + c.opts.Runtime + " container"
+	}
+	return "an isolated " + c.opts.Runtime + " container without network access"
+}
+
+// Command returns a command that runs the shell command in dir inside the
+// container. dir must be inside the workspace.
+func (c *Container) Command(ctx context.Context, dir, command string) (*exec.Cmd, error) {
+	rel, err := filepath.Rel(c.opts.WorkDir, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("sandbox: %s is outside the mounted workspace %s", dir, c.opts.WorkDir)
+	}
+	id, err := c.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return exec.CommandContext(ctx, c.opts.Runtime, "exec", "-i", "-w", dir, id, "bash", "-c", command), nil
+}
+
+func (c *Container) start(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.id != "" || c.err != nil {
+		return c.id, c.err
+	}
+
+	args := runArgs(c.opts, os.Getuid(), os.Getgid())
+	if c.opts.Debug {
+		fmt.Fprintf(os.Stderr, "[sandbox] %s %s\n", c.opts.Runtime, strings.Join(args, " "))
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.opts.Runtime, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		c.err = fmt.Errorf("failed to start %s sandbox: %v: %s", c.opts.Runtime, err, strings.TrimSpace(stderr.String()))
+		return "", c.err
+	}
+	c.id = strings.TrimSpace(stdout.String())
+	return c.id, nil
+}
+
+// runArgs builds the arguments that start the sandbox container detached.
+func runArgs(opts Options, uid, gid int) []string {
+	args := []string{"run", "-d", "--rm", "--init",
+		"-v", opts.WorkDir + ":" + opts.WorkDir,
+		"-w", opts.WorkDir,
+		"--label", "g-sandbox=1",
+	}
+	if !opts.Network {
+		args = append(args, "--network", "none")
+	}
+	if runtime.GOOS != "windows" && uid >= 0 {
+		// Keep files created in the workspace owned by the user
+		if opts.Runtime == "podman" {
+			args = append(args, "--userns=keep-id")
+		} else {
+			args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+		}
+	}
+	return append(args, opts.Image, "sleep", "infinity")
+}
+
+// Close removes the container if it was started.
+func (c *Container) Close() error {
+	c.mu.Lock()
+	id := c.id
+	c.id = ""
+	c.mu.Unlock()
+	if id == "" {
+		return nil
+	}
+	return exec.Command(c.opts.Runtime, "rm", "-f", id).Run()
+}
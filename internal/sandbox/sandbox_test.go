@@ -0,0 +1,33 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package sandbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunArgs(t *testing.T) {
+	args := strings.Join(runArgs(Options{Runtime: "docker", Image: "img", WorkDir: "/w"}, 1000, 1000), " ")
+	for _, want := range []string{"-v /w:/w", "--network none", "--user 1000:1000", "img sleep infinity"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args %q missing %q", args, want)
+		}
+	}
+
+	args = strings.Join(runArgs(Options{Runtime: "podman", Image: "img", WorkDir: "/w", Network: true}, 1000, 1000), " ")
+	if strings.Contains(args, "--network") {
+		t.Errorf("network should be enabled: %q", args)
+	}
+	if !strings.Contains(args, "--userns=keep-id") {
+		t.Errorf("podman should keep user id: %q", args)
+	}
+}
+
+func TestCommandOutsideWorkspace(t *testing.T) {
+	c := &Container{opts: Options{Runtime: "docker", WorkDir: "/w"}}
+	if _, err := c.Command(context.Background(), "/etc", "ls"); err == nil {
+		t.Fatal("expected error for directory outside workspace")
+	}
+}
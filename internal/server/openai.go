@@ -0,0 +1,262 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// chatRequest is the subset of the OpenAI chat completions request g uses.
+type chatRequest struct {
+	Model               string          `json:"model"`
+	Messages            []chatMessage   `json:"messages"`
+	Stream              bool            `json:"stream"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
+	MaxTokens           int             `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Stop                json.RawMessage `json:"stop,omitempty"`
+	Tools               []chatTool      `json:"tools,omitempty"`
+}
+
+type chatMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	ToolCalls  []chatToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+type chatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Parameters  json.RawMessage `json:"parameters,omitempty"`
+	} `json:"function"`
+}
+
+type chatToolCall struct {
+	Index    *int   `json:"index,omitempty"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type contentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL *struct {
+		URL string `json:"url"`
+	} `json:"image_url,omitempty"`
+}
+
+type chatResponse struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage,omitempty"`
+}
+
+type chatChoice struct {
+	Index        int          `json:"index"`
+	Message      *chatMessage `json:"message,omitempty"`
+	Delta        *chatDelta   `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+type chatDelta struct {
+	Role      string         `json:"role,omitempty"`
+	Content   string         `json:"content,omitempty"`
+	ToolCalls []chatToolCall `json:"tool_calls,omitempty"`
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// conversation is a chat request translated to the Gemini request shape.
+type conversation struct {
+	System   string
+	Contents []api.Content
+}
+
+// convertMessages translates OpenAI messages into Gemini contents. System
+// and developer messages are collected separately.
+func convertMessages(messages []chatMessage) (*conversation, error) {
+	conv := &conversation{}
+	var system []string
+	toolNames := map[string]string{} // tool_call_id -> function name
+
+	appendParts := func(role string, parts []api.Part) {
+		if len(parts) == 0 {
+			return
+		}
+		// Gemini expects alternating turns; merge consecutive ones
+		if n := len(conv.Contents); n > 0 && conv.Contents[n-1].Role == role {
+			conv.Contents[n-1].Parts = append(conv.Contents[n-1].Parts, parts...)
+			return
+		}
+		conv.Contents = append(conv.Contents, api.Content{Role: role, Parts: parts})
+	}
+
+	for i, m := range messages {
+		switch m.Role {
+		case "system", "developer":
+			text, _, err := messageContent(m.Content)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+			system = append(system, text)
+		case "user":
+			_, parts, err := messageContent(m.Content)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+			appendParts("user", parts)
+		case "assistant":
+			_, parts, err := messageContent(m.Content)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+			for _, tc := range m.ToolCalls {
+				args := map[string]interface{}{}
+				if tc.Function.Arguments != "" {
+					if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+						return nil, fmt.Errorf("messages[%d]: invalid tool call arguments: %w", i, err)
+					}
+				}
+				toolNames[tc.ID] = tc.Function.Name
+				parts = append(parts, api.Part{
+					FunctionCall:     &api.FunctionCall{Name: tc.Function.Name, Args: args},
+					ThoughtSignature: agent.SyntheticThoughtSignature,
+				})
+			}
+			appendParts("model", parts)
+		case "tool", "function":
+			text, _, err := messageContent(m.Content)
+			if err != nil {
+				return nil, fmt.Errorf("messages[%d]: %w", i, err)
+			}
+			name := toolNames[m.ToolCallID]
+			if name == "" {
+				name = m.Name
+			}
+			if name == "" {
+				return nil, fmt.Errorf("messages[%d]: tool message does not match a previous tool call", i)
+			}
+			var response map[string]interface{}
+			if json.Unmarshal([]byte(text), &response) != nil {
+				response = map[string]interface{}{"result": text}
+			}
+			appendParts("user", []api.Part{{FunctionResp: &api.FunctionResp{Name: name, Response: response}}})
+		default:
+			return nil, fmt.Errorf("messages[%d]: unsupported role %q", i, m.Role)
+		}
+	}
+
+	if len(conv.Contents) == 0 {
+		return nil, fmt.Errorf("messages must contain at least one user message")
+	}
+	conv.System = strings.Join(system, "\n\n")
+	return conv, nil
+}
+
+// messageContent decodes a message's content, which is either a string or
+// an array of text and image parts.
+func messageContent(raw json.RawMessage) (string, []api.Part, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if text == "" {
+			return "", nil, nil
+		}
+		return text, []api.Part{{Text: text}}, nil
+	}
+
+	var items []contentPart
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return "", nil, fmt.Errorf("content must be a string or an array of parts")
+	}
+	var texts []string
+	var parts []api.Part
+	for _, item := range items {
+		switch item.Type {
+		case "text":
+			texts = append(texts, item.Text)
+			parts = append(parts, api.Part{Text: item.Text})
+		case "image_url":
+			if item.ImageURL == nil {
+				continue
+			}
+			blob, err := dataURLBlob(item.ImageURL.URL)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, api.Part{InlineData: blob})
+		default:
+			return "", nil, fmt.Errorf("unsupported content part type %q", item.Type)
+		}
+	}
+	return strings.Join(texts, "\n"), parts, nil
+}
+
+// dataURLBlob converts a base64 data: URL to an inline blob. Remote image
+// URLs are not fetched.
+func dataURLBlob(url string) (*api.Blob, error) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return nil, fmt.Errorf("only data: image URLs are supported")
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	if !ok || !strings.HasSuffix(meta, ";base64") {
+		return nil, fmt.Errorf("image data URL must be base64-encoded")
+	}
+	return &api.Blob{MimeType: strings.TrimSuffix(meta, ";base64"), Data: data}, nil
+}
+
+// stopSequences decodes the "stop" field, a string or an array of strings.
+func stopSequences(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		if one == "" {
+			return nil
+		}
+		return []string{one}
+	}
+	var many []string
+	_ = json.Unmarshal(raw, &many)
+	return many
+}
+
+// finishReason maps a Gemini finish reason to the OpenAI vocabulary.
+func finishReason(reason string, toolCalls bool) string {
+	if toolCalls {
+		return "tool_calls"
+	}
+	switch reason {
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
@@ -0,0 +1,513 @@
+// Package server exposes g over HTTP with an OpenAI-compatible API.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/output"
+)
+
+// maxRequestBytes bounds request bodies (images are sent inline).
+const maxRequestBytes = 20 << 20
+
+// Options configures a Server.
+type Options struct {
+	Client       api.Provider
+	Project      string
+	DefaultModel string
+	Models       []string // advertised by /v1/models
+	Token        string   // if set, requests must send "Authorization: Bearer <token>"; if not, they must come from a local program (see localClient)
+	Timeout      time.Duration
+
+	// SystemInstruction is used for agent requests; client system messages
+	// are appended to it.
+	SystemInstruction *api.Content
+
+	// NewAgent creates an agent loop that reports through f and returns
+	// the declarations of the tools it runs, which are offered to the
+	// model. If nil, requests are answered by the model without g's tools.
+	NewAgent func(f output.Formatter) (*agent.Loop, []api.FunctionDecl)
+
+	Debug bool
+}
+
+// Server handles HTTP requests.
+type Server struct {
+	opts Options
+}
+
+// New creates a server.
+func New(opts Options) *Server {
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Minute
+	}
+	return &Server{opts: opts}
+}
+
+// Handler returns the HTTP handler with all routes.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/v1/models", s.auth(s.handleModels))
+	mux.HandleFunc("/v1/chat/completions", s.auth(s.handleChatCompletions))
+	mux.HandleFunc("/v1/g/run", s.auth(s.handleRun))
+	return mux
+}
+
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.Token != "" {
+			got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(got), []byte(s.opts.Token)) != 1 {
+				writeError(w, http.StatusUnauthorized, "invalid or missing bearer token")
+				return
+			}
+		} else if err := localClient(r); err != nil {
+			writeError(w, http.StatusForbidden, err.Error()+" (set a token to accept such requests)")
+			return
+		}
+		if s.opts.Debug {
+			fmt.Fprintf(os.Stderr, "[serve] %s %s\n", r.Method, r.URL.Path)
+		}
+		next(w, r)
+	}
+}
+
+// localClient checks that a request without a token comes from a program
+// on this machine, not from a web page the user visits: browsers send an
+// Origin header with cross-site requests, a page that rebinds its domain
+// to 127.0.0.1 sends its own Host, and only a "simple" text/plain or form
+// POST gets past the browser without a preflight.
+func localClient(r *http.Request) error {
+	if r.Header.Get("Origin") != "" {
+		return fmt.Errorf("requests from web pages are not allowed")
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if ip := net.ParseIP(host); !strings.EqualFold(host, "localhost") && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("host %q is not a loopback address", r.Host)
+	}
+	if r.Method == http.MethodPost {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			return fmt.Errorf("the request body must be application/json")
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	type model struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		OwnedBy string `json:"owned_by"`
+	}
+	var data []model
+	for _, id := range s.opts.Models {
+		owner := "google"
+		if strings.HasPrefix(id, "claude-") {
+			owner = "anthropic"
+		}
+		data = append(data, model{ID: id, Object: "model", OwnedBy: owner})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"object": "list", "data": data})
+}
+
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	var req chatRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	conv, err := convertMessages(req.Messages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Client-defined tools are returned to the client as tool_calls; only
+	// requests without them run g's own agent loop.
+	useAgent := len(req.Tools) == 0 && s.opts.NewAgent != nil
+	var sink *chatSink
+	var loop *agent.Loop
+	var decls []api.FunctionDecl
+	if useAgent {
+		// g's own tool calls are internal and not reported as tool_calls
+		loop, decls = s.opts.NewAgent(&eventFormatter{emit: func(ev api.StreamEvent) {
+			if ev.Type != "tool_call" && ev.Type != "tool_result" {
+				sink.event(ev)
+			}
+		}})
+	}
+	greq := s.generateRequest(req.Model, conv, useAgent, decls)
+	cfg := &greq.Request.Config
+	if req.Temperature != nil {
+		cfg.Temperature = *req.Temperature
+	}
+	if req.TopP != nil {
+		cfg.TopP = *req.TopP
+	}
+	if req.MaxCompletionTokens > 0 {
+		cfg.MaxOutputTokens = req.MaxCompletionTokens
+	} else if req.MaxTokens > 0 {
+		cfg.MaxOutputTokens = req.MaxTokens
+	}
+	if limit := api.Model(greq.Model).MaxOutputTokens; cfg.MaxOutputTokens > limit {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("max_tokens %d exceeds the %d output tokens %s allows", cfg.MaxOutputTokens, limit, greq.Model))
+		return
+	}
+	cfg.StopSequences = stopSequences(req.Stop)
+	if len(req.Tools) > 0 {
+		for _, t := range req.Tools {
+			params := t.Function.Parameters
+			if len(params) == 0 {
+				params = json.RawMessage(`{"type":"object","properties":{}}`)
+			}
+			decls = append(decls, api.FunctionDecl{Name: t.Function.Name, Description: t.Function.Description, Parameters: params})
+		}
+		greq.Request.Tools = []api.Tool{{FunctionDeclarations: decls}}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.opts.Timeout)
+	defer cancel()
+
+	sink = newChatSink(w, greq.Model, req.Stream)
+	if useAgent {
+		err = loop.Run(ctx, greq)
+	} else {
+		err = s.stream(ctx, greq, sink.event)
+	}
+	sink.finish(err)
+}
+
+// handleRun is g's native endpoint: it runs the agent and streams every
+// event (text, tool calls and tool results) as newline-delimited JSON.
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	var req struct {
+		Model    string        `json:"model"`
+		Prompt   string        `json:"prompt"`
+		Messages []chatMessage `json:"messages"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	messages := req.Messages
+	if req.Prompt != "" {
+		content, _ := json.Marshal(req.Prompt)
+		messages = append(messages, chatMessage{Role: "user", Content: content})
+	}
+	conv, err := convertMessages(messages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.opts.Timeout)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	emit := func(ev api.StreamEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(ev)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if s.opts.NewAgent != nil {
+		loop, decls := s.opts.NewAgent(&eventFormatter{emit: emit})
+		err = loop.Run(ctx, s.generateRequest(req.Model, conv, true, decls))
+	} else {
+		err = s.stream(ctx, s.generateRequest(req.Model, conv, false, nil), emit)
+	}
+	if err != nil {
+		emit(api.StreamEvent{Type: "error", Error: err.Error()})
+	}
+}
+
+// generateRequest builds the backend request for a conversation. Agent
+// requests get g's system instruction and the agent's tools.
+func (s *Server) generateRequest(model string, conv *conversation, useAgent bool, tools []api.FunctionDecl) *api.GenerateRequest {
+	if model == "" {
+		model = s.opts.DefaultModel
+	}
+	var system *api.Content
+	if useAgent && s.opts.SystemInstruction != nil {
+		copied := *s.opts.SystemInstruction
+		copied.Parts = append([]api.Part{}, copied.Parts...)
+		system = &copied
+	}
+	if conv.System != "" {
+		if system == nil {
+			system = &api.Content{Role: "user"}
+		}
+		system.Parts = append(system.Parts, api.Part{Text: conv.System})
+	}
+	greq := &api.GenerateRequest{
+		Model:        model,
+		Project:      s.opts.Project,
+		UserPromptID: "g-serve-" + randomID(),
+		Request: api.InnerRequest{
+			Contents:          conv.Contents,
+			SystemInstruction: system,
+			Config: api.GenerationConfig{
+				Temperature:     1.0,
+				TopP:            0.95,
+				MaxOutputTokens: api.Model(model).MaxOutputTokens,
+			},
+		},
+	}
+	if len(tools) > 0 {
+		greq.Request.Tools = []api.Tool{{FunctionDeclarations: tools}}
+	}
+	return greq
+}
+
+// stream calls the model once and forwards its events.
+func (s *Server) stream(ctx context.Context, req *api.GenerateRequest, emit func(api.StreamEvent)) error {
+	events, err := s.opts.Client.GenerateStream(ctx, req)
+	if err != nil {
+		return err
+	}
+	for ev := range events {
+		if ev.Type == "error" {
+			return fmt.Errorf("%s", ev.Error)
+		}
+		emit(ev)
+	}
+	return ctx.Err()
+}
+
+// chatSink turns stream events into an OpenAI response, either as SSE
+// chunks or as a single JSON body.
+type chatSink struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	stream  bool
+	id      string
+	model   string
+	created int64
+
+	mu        sync.Mutex
+	started   bool
+	text      strings.Builder
+	toolCalls []chatToolCall
+	reason    string
+	usage     chatUsage
+}
+
+func newChatSink(w http.ResponseWriter, model string, stream bool) *chatSink {
+	flusher, _ := w.(http.Flusher)
+	return &chatSink{
+		w:       w,
+		flusher: flusher,
+		stream:  stream,
+		id:      "chatcmpl-" + randomID(),
+		model:   model,
+		created: time.Now().Unix(),
+	}
+}
+
+func (c *chatSink) event(ev api.StreamEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch ev.Type {
+	case "content":
+		if ev.Text == "" {
+			return
+		}
+		c.text.WriteString(ev.Text)
+		c.chunk(&chatDelta{Content: ev.Text}, nil)
+	case "tool_call":
+		if ev.ToolCall == nil {
+			return
+		}
+		args, _ := json.Marshal(ev.ToolCall.Args)
+		index := len(c.toolCalls)
+		tc := chatToolCall{Index: &index, ID: "call_" + randomID(), Type: "function"}
+		tc.Function.Name = ev.ToolCall.Name
+		tc.Function.Arguments = string(args)
+		c.toolCalls = append(c.toolCalls, tc)
+		c.chunk(&chatDelta{ToolCalls: []chatToolCall{tc}}, nil)
+	case "done":
+		if ev.FinishReason != "" {
+			c.reason = ev.FinishReason
+		}
+		// The agent loop makes one call per turn; report the total
+		if ev.Usage != nil {
+			c.usage.PromptTokens += ev.Usage.PromptTokenCount
+			c.usage.CompletionTokens += ev.Usage.CandidatesTokenCount
+			c.usage.TotalTokens += ev.Usage.TotalTokenCount
+		}
+	}
+}
+
+// chunk writes one SSE chunk in streaming mode; c.mu must be held.
+func (c *chatSink) chunk(delta *chatDelta, finish *string) {
+	if !c.stream {
+		return
+	}
+	if !c.started {
+		c.started = true
+		c.w.Header().Set("Content-Type", "text/event-stream")
+		c.w.Header().Set("Cache-Control", "no-cache")
+		c.w.WriteHeader(http.StatusOK)
+		if delta != nil {
+			delta.Role = "assistant"
+		}
+	}
+	resp := chatResponse{
+		ID:      c.id,
+		Object:  "chat.completion.chunk",
+		Created: c.created,
+		Model:   c.model,
+		Choices: []chatChoice{{Delta: delta, FinishReason: finish}},
+	}
+	if finish != nil {
+		resp.Usage = &c.usage
+	}
+	data, _ := json.Marshal(resp)
+	fmt.Fprintf(c.w, "data: %s\n\n", data)
+	if c.flusher != nil {
+		c.flusher.Flush()
+	}
+}
+
+// finish completes the response. Errors before any output become an HTTP
+// error; errors mid-stream are sent as a final SSE event.
+func (c *chatSink) finish(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if !c.started {
+			writeError(c.w, http.StatusBadGateway, err.Error())
+			return
+		}
+		data, _ := json.Marshal(map[string]interface{}{"error": map[string]string{"message": err.Error(), "type": "server_error"}})
+		fmt.Fprintf(c.w, "data: %s\n\ndata: [DONE]\n\n", data)
+		return
+	}
+
+	reason := finishReason(c.reason, len(c.toolCalls) > 0)
+	if c.stream {
+		c.chunk(&chatDelta{}, &reason)
+		fmt.Fprint(c.w, "data: [DONE]\n\n")
+		if c.flusher != nil {
+			c.flusher.Flush()
+		}
+		return
+	}
+
+	content, _ := json.Marshal(c.text.String())
+	msg := &chatMessage{Role: "assistant", Content: content}
+	for _, tc := range c.toolCalls {
+		tc.Index = nil
+		msg.ToolCalls = append(msg.ToolCalls, tc)
+	}
+	usage := c.usage
+	writeJSON(c.w, http.StatusOK, chatResponse{
+		ID:      c.id,
+		Object:  "chat.completion",
+		Created: c.created,
+		Model:   c.model,
+		Choices: []chatChoice{{Message: msg, FinishReason: &reason}},
+		Usage:   &usage,
+	})
+}
+
+// eventFormatter adapts the agent loop's output to a stream of events.
+type eventFormatter struct {
+	emit func(api.StreamEvent)
+}
+
+func (f *eventFormatter) WriteResponse(resp *api.GenerateResponse) error {
+	for _, cand := range resp.Response.Candidates {
+		for _, part := range cand.Content.Parts {
+			if part.Text != "" {
+				f.emit(api.StreamEvent{Type: "content", Text: part.Text})
+			}
+		}
+	}
+	return nil
+}
+
+func (f *eventFormatter) WriteStreamEvent(event *api.StreamEvent) error {
+	f.emit(*event)
+	return nil
+}
+
+func (f *eventFormatter) WriteError(err error) error {
+	f.emit(api.StreamEvent{Type: "error", Error: err.Error()})
+	return nil
+}
+
+func (f *eventFormatter) WriteToolCall(name string, args map[string]interface{}) error {
+	f.emit(api.StreamEvent{Type: "tool_call", ToolCall: &api.FunctionCall{Name: name, Args: args}})
+	return nil
+}
+
+func (f *eventFormatter) WriteToolResult(name string, result map[string]interface{}, isError bool) error {
+	ev := api.StreamEvent{Type: "tool_result", ToolResult: &api.ToolResult{Name: name, Result: result}}
+	if isError {
+		ev.Error = "tool failed"
+	}
+	f.emit(ev)
+	return nil
+}
+
+func (f *eventFormatter) WriteProgress(stage, detail string) error {
+	f.emit(api.StreamEvent{Type: "progress", Stage: stage, Detail: detail})
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{"message": msg, "type": "invalid_request_error"},
+	})
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
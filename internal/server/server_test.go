@@ -0,0 +1,202 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/output"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+func TestConvertMessages(t *testing.T) {
+	var messages []chatMessage
+	err := json.Unmarshal([]byte(`[
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "content": [{"type": "text", "text": "Weather?"}, {"type": "image_url", "image_url": {"url": "data:image/png;base64,AAAA"}}]},
+		{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Tokyo\"}"}}]},
+		{"role": "tool", "tool_call_id": "call_1", "content": "{\"temp\": 21}"},
+		{"role": "tool", "tool_call_id": "call_1", "content": "sunny"}
+	]`), &messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conv, err := convertMessages(messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conv.System != "Be brief." {
+		t.Errorf("System = %q", conv.System)
+	}
+	if len(conv.Contents) != 3 {
+		t.Fatalf("got %d contents, want 3 (user, model, merged tool responses)", len(conv.Contents))
+	}
+	user := conv.Contents[0]
+	if user.Parts[1].InlineData == nil || user.Parts[1].InlineData.MimeType != "image/png" {
+		t.Errorf("image part not converted: %+v", user.Parts[1])
+	}
+	call := conv.Contents[1].Parts[0].FunctionCall
+	if call == nil || call.Name != "get_weather" || call.Args["city"] != "Tokyo" {
+		t.Errorf("tool call not converted: %+v", conv.Contents[1])
+	}
+	resp := conv.Contents[2].Parts
+	if len(resp) != 2 || resp[0].FunctionResp.Response["temp"] != float64(21) || resp[1].FunctionResp.Response["result"] != "sunny" {
+		t.Errorf("tool responses not converted: %+v", resp)
+	}
+
+	if _, err := convertMessages([]chatMessage{{Role: "tool", ToolCallID: "nope"}}); err == nil {
+		t.Error("expected error for unmatched tool message")
+	}
+}
+
+func TestChatSink(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sink := newChatSink(rec, "gemini-2.5-flash", false)
+	sink.event(api.StreamEvent{Type: "content", Text: "Hel"})
+	sink.event(api.StreamEvent{Type: "content", Text: "lo"})
+	sink.event(api.StreamEvent{Type: "done", FinishReason: "STOP", Usage: &api.UsageMetadata{PromptTokenCount: 3, CandidatesTokenCount: 2, TotalTokenCount: 5}})
+	sink.finish(nil)
+
+	var resp struct {
+		Choices []struct {
+			Message      struct{ Content string } `json:"message"`
+			FinishReason string                   `json:"finish_reason"`
+		} `json:"choices"`
+		Usage chatUsage `json:"usage"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Choices[0].Message.Content != "Hello" || resp.Choices[0].FinishReason != "stop" || resp.Usage.TotalTokens != 5 {
+		t.Errorf("unexpected response: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	sink = newChatSink(rec, "m", true)
+	sink.event(api.StreamEvent{Type: "tool_call", ToolCall: &api.FunctionCall{Name: "f", Args: map[string]interface{}{"a": 1}}})
+	sink.finish(nil)
+	body := rec.Body.String()
+	if !strings.Contains(body, `"tool_calls":[{"index":0`) || !strings.Contains(body, `"finish_reason":"tool_calls"`) || !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Errorf("unexpected stream: %s", body)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	srv := New(Options{Token: "secret", Models: []string{"gemini-2.5-pro"}}).Handler()
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/models", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d", rec.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "gemini-2.5-pro") {
+		t.Errorf("models = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLocalClient(t *testing.T) {
+	srv := New(Options{Models: []string{"gemini-2.5-pro"}}).Handler()
+	for _, tc := range []struct {
+		name   string
+		method string
+		host   string
+		header map[string]string
+		want   int
+	}{
+		{"local program", "GET", "127.0.0.1:8080", nil, http.StatusOK},
+		{"localhost", "GET", "localhost:8080", nil, http.StatusOK},
+		{"ipv6 loopback", "GET", "[::1]:8080", nil, http.StatusOK},
+		{"web page", "GET", "127.0.0.1:8080", map[string]string{"Origin": "https://evil.example"}, http.StatusForbidden},
+		{"rebound domain", "GET", "evil.example:8080", nil, http.StatusForbidden},
+		{"simple text post", "POST", "127.0.0.1:8080", map[string]string{"Content-Type": "text/plain"}, http.StatusForbidden},
+		{"form post", "POST", "127.0.0.1:8080", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusForbidden},
+		{"json post", "POST", "127.0.0.1:8080", map[string]string{"Content-Type": "application/json; charset=utf-8"}, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tc.method, "/v1/models", strings.NewReader("{"))
+		if tc.method == "POST" {
+			req = httptest.NewRequest(tc.method, "/v1/chat/completions", strings.NewReader("{"))
+		}
+		req.Host = tc.host
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+}
+
+// recordingProvider answers every request with text and keeps the tools
+// each one declared.
+type recordingProvider struct {
+	mu    sync.Mutex
+	tools [][]string
+}
+
+func (p *recordingProvider) Generate(ctx context.Context, req *api.GenerateRequest) (*api.GenerateResponse, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (p *recordingProvider) GenerateStream(ctx context.Context, req *api.GenerateRequest) (<-chan api.StreamEvent, error) {
+	var names []string
+	for _, t := range req.Request.Tools {
+		for _, d := range t.FunctionDeclarations {
+			names = append(names, d.Name)
+		}
+	}
+	p.mu.Lock()
+	p.tools = append(p.tools, names)
+	p.mu.Unlock()
+	ch := make(chan api.StreamEvent, 1)
+	ch <- api.StreamEvent{Type: "content", Text: "ok"}
+	close(ch)
+	return ch, nil
+}
+
+func TestAgentToolsDeclared(t *testing.T) {
+	provider := &recordingProvider{}
+	srv := New(Options{
+		Client:       provider,
+		DefaultModel: "gemini-2.5-flash",
+		Token:        "secret",
+		NewAgent: func(f output.Formatter) (*agent.Loop, []api.FunctionDecl) {
+			registry := tools.NewRegistry(tools.RegistryOptions{WorkDir: t.TempDir(), ReadOnly: true})
+			loop := agent.NewLoop(provider, registry, nil, f, agent.Config{MaxTurns: 2, Streaming: true})
+			return loop, registry.AllDeclarations()
+		},
+	}).Handler()
+
+	for path, body := range map[string]string{
+		"/v1/chat/completions": `{"messages":[{"role":"user","content":"hi"}]}`,
+		"/v1/g/run":            `{"prompt":"hi"}`,
+	} {
+		provider.tools = nil
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", path, rec.Code, rec.Body)
+		}
+		if len(provider.tools) != 1 || !strings.Contains(strings.Join(provider.tools[0], ","), "read_file") {
+			t.Errorf("%s declared %v, want g's tools", path, provider.tools)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportFormats lists the supported export formats.
+var ExportFormats = []string{"markdown", "json", "gemini-cli"}
+
+// Export writes the session to w in the given format.
+func Export(w io.Writer, s *Session, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case "markdown", "md":
+		return exportMarkdown(w, s)
+	case "gemini-cli":
+		return exportGeminiCLI(w, s)
+	default:
+		return fmt.Errorf("unknown export format %q (use %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+func exportMarkdown(w io.Writer, s *Session) error {
+	fmt.Fprintf(w, "# %s\n\n", orDefault(s.Title, s.ID))
+	fmt.Fprintf(w, "- Session: %s\n- Model: %s\n- Directory: %s\n- Updated: %s\n\n",
+		s.ID, s.Model, s.WorkDir, s.Updated.Format("2006-01-02 15:04"))
+
+	for _, c := range s.Contents {
+		for _, p := range c.Parts {
+			switch {
+			case p.Text != "":
+				role := "User"
+				if c.Role == "model" {
+					role = "Model"
+				}
+				fmt.Fprintf(w, "## %s\n\n%s\n\n", role, strings.TrimSpace(p.Text))
+			case p.FunctionCall != nil:
+				args, _ := json.MarshalIndent(p.FunctionCall.Args, "", "  ")
+				fmt.Fprintf(w, "### Tool call: %s\n\n```json\n%s\n```\n\n", p.FunctionCall.Name, args)
+			case p.FunctionResp != nil:
+				resp, _ := json.MarshalIndent(p.FunctionResp.Response, "", "  ")
+				fmt.Fprintf(w, "### Tool result: %s\n\n```json\n%s\n```\n\n", p.FunctionResp.Name, truncate(string(resp), 4000))
+			case p.InlineData != nil:
+				fmt.Fprintf(w, "_[%s attachment]_\n\n", p.InlineData.MimeType)
+			}
+		}
+	}
+	return nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return cut(s, n) + "\n... [truncated]"
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package session
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/config"
+)
+
+// The official CLI starts every chat with this exchange, which describes
+// its environment. Checkpoints keep it, and /chat resume does not show it.
+const (
+	geminiCLIContextPrefix = "This is the Gemini CLI. We are setting up the context for our chat."
+	geminiCLIContextReply  = "Got it. Thanks for the context!"
+)
+
+// ImportFormats lists the formats Import reads.
+var ImportFormats = []string{"gemini-cli", "json"}
+
+// checkpoint is a chat saved by the official CLI's /chat save. Older
+// versions wrote the history as a bare array.
+type checkpoint struct {
+	History []api.Content `json:"history"`
+}
+
+// CheckpointPath returns where the official CLI keeps the chat saved as
+// tag for the project in workDir: ~/.gemini/tmp/<sha256 of the path>/
+// checkpoint-<tag>.json.
+func CheckpointPath(workDir, tag string) (string, error) {
+	if tag == "" {
+		return "", fmt.Errorf("empty checkpoint tag")
+	}
+	dir, err := config.GeminiDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(workDir))
+	return filepath.Join(dir, "tmp", hex.EncodeToString(sum[:]), "checkpoint-"+encodeTag(tag)+".json"), nil
+}
+
+// encodeTag escapes a tag like JavaScript's encodeURIComponent, which the
+// official CLI uses for checkpoint file names.
+func encodeTag(tag string) string {
+	var b strings.Builder
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-_.!~*'()", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// exportGeminiCLI writes the conversation as a checkpoint /chat resume can
+// load, behind the context exchange the official CLI expects first.
+func exportGeminiCLI(w io.Writer, s *Session) error {
+	history := []api.Content{
+		{Role: "user", Parts: []api.Part{{Text: geminiCLIContextPrefix + "\nThis conversation was exported from g. Working directory: " + s.WorkDir}}},
+		{Role: "model", Parts: []api.Part{{Text: geminiCLIContextReply}}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(checkpoint{History: append(history, s.Contents...)})
+}
+
+// Import reads a conversation exported in format and returns it as a new,
+// unsaved session in workDir. Sessions in g's own JSON keep their title,
+// model and directory.
+func Import(r io.Reader, format, workDir, model string) (*Session, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := New(workDir, model)
+	switch format {
+	case "gemini-cli":
+		if s.Contents, err = importGeminiCLI(data); err != nil {
+			return nil, err
+		}
+	case "json":
+		var stored Session
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, fmt.Errorf("not a g session export: %w", err)
+		}
+		s.Title, s.Contents = stored.Title, stored.Contents
+		if stored.WorkDir != "" {
+			s.WorkDir = stored.WorkDir
+		}
+		if stored.Model != "" {
+			s.Model = stored.Model
+		}
+	default:
+		return nil, fmt.Errorf("unknown import format %q (use %s)", format, strings.Join(ImportFormats, ", "))
+	}
+	if len(s.Contents) == 0 {
+		return nil, fmt.Errorf("the conversation is empty")
+	}
+	return s, nil
+}
+
+// importGeminiCLI returns the conversation in a checkpoint without the
+// official CLI's context exchange and the model's thoughts, which g's
+// system prompt and the API replace.
+func importGeminiCLI(data []byte) ([]api.Content, error) {
+	var raw struct {
+		History []struct {
+			Role  string `json:"role"`
+			Parts []struct {
+				api.Part
+				Thought bool `json:"thought"`
+			} `json:"parts"`
+		} `json:"history"`
+	}
+	var err error
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &raw.History)
+	} else {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("not a Gemini CLI checkpoint: %w", err)
+	}
+
+	var contents []api.Content
+	for _, c := range raw.History {
+		content := api.Content{Role: c.Role}
+		for _, p := range c.Parts {
+			if !p.Thought {
+				content.Parts = append(content.Parts, p.Part)
+			}
+		}
+		if len(content.Parts) > 0 {
+			contents = append(contents, content)
+		}
+	}
+	if len(contents) >= 2 && strings.HasPrefix(contents[0].Parts[0].Text, geminiCLIContextPrefix) &&
+		contents[1].Role == "model" && strings.TrimSpace(contents[1].Parts[0].Text) == geminiCLIContextReply {
+		contents = contents[2:]
+	}
+	return contents, nil
+}
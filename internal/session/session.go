@@ -0,0 +1,269 @@
+// Package session persists conversations so they can be listed, exported
+// and resumed.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/config"
+)
+
+const sessionsDir = "g_sessions"
+
+// Session is a persisted conversation.
+type Session struct {
+	ID       string        `json:"id"`
+	Created  time.Time     `json:"created"`
+	Updated  time.Time     `json:"updated"`
+	WorkDir  string        `json:"workDir"`
+	Model    string        `json:"model"`
+	Title    string        `json:"title"`
+	Contents []api.Content `json:"contents"`
+}
+
+// Info summarizes a stored session.
+type Info struct {
+	ID       string
+	Created  time.Time
+	Updated  time.Time
+	WorkDir  string
+	Model    string
+	Title    string
+	Messages int
+	Size     int64
+}
+
+// Dir returns the session store directory (~/.gemini/g_sessions).
+func Dir() (string, error) {
+	dir, err := config.GeminiDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionsDir), nil
+}
+
+// New creates an unsaved session.
+func New(workDir, model string) *Session {
+	now := time.Now()
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return &Session{
+		ID:      now.Format("20060102-150405") + "-" + hex.EncodeToString(b),
+		Created: now,
+		WorkDir: workDir,
+		Model:   model,
+	}
+}
+
+// Save writes the session with the given conversation. Sessions without
+// any content are not written.
+func (s *Session) Save(contents []api.Content) error {
+	if len(contents) == 0 {
+		return nil
+	}
+	s.Contents = contents
+	s.Updated = time.Now()
+	if s.Title == "" {
+		s.Title = title(contents)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	// Write atomically so an interrupted save never corrupts the session
+	path := filepath.Join(dir, s.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// title returns the first line of the first user message.
+func title(contents []api.Content) string {
+	for _, c := range contents {
+		if c.Role != "user" {
+			continue
+		}
+		for _, p := range c.Parts {
+			if t := strings.TrimSpace(p.Text); t != "" {
+				t, _, _ = strings.Cut(t, "\n")
+				if len(t) > 80 {
+					t = cut(t, 77) + "..."
+				}
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// Load reads a session by ID, unique ID prefix, or "latest".
+func Load(id string) (*Session, error) {
+	path, err := resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", id, err)
+	}
+	return &s, nil
+}
+
+// Delete removes a session by ID or unique prefix.
+func Delete(id string) (string, error) {
+	path, err := resolve(id)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(filepath.Base(path), ".json"), os.Remove(path)
+}
+
+// resolve maps an ID, prefix or "latest" to a session file.
+func resolve(id string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if id == "latest" {
+		infos, err := List()
+		if err != nil {
+			return "", err
+		}
+		if len(infos) == 0 {
+			return "", fmt.Errorf("no sessions found")
+		}
+		return filepath.Join(dir, infos[0].ID+".json"), nil
+	}
+	if strings.ContainsAny(id, `/\`) || id == "" {
+		return "", fmt.Errorf("invalid session ID %q", id)
+	}
+	// Compare names rather than glob, so IDs with *, ? or [ match literally
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	var matches []string
+	for _, e := range entries {
+		name := e.Name()
+		if name == id+".json" {
+			return filepath.Join(dir, name), nil
+		}
+		if strings.HasSuffix(name, ".json") && strings.HasPrefix(name, id) {
+			matches = append(matches, filepath.Join(dir, name))
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("session %q not found", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("session prefix %q is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+// cut returns at most the first n bytes of s, ending on a rune boundary.
+func cut(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// List returns all stored sessions, most recently updated first.
+func List() ([]Info, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var infos []Info
+	for _, path := range paths {
+		st, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var s Session
+		if json.Unmarshal(data, &s) != nil {
+			continue
+		}
+		infos = append(infos, Info{
+			ID:       s.ID,
+			Created:  s.Created,
+			Updated:  s.Updated,
+			WorkDir:  s.WorkDir,
+			Model:    s.Model,
+			Title:    s.Title,
+			Messages: len(s.Contents),
+			Size:     st.Size(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Updated.After(infos[j].Updated) })
+	return infos, nil
+}
+
+// GC removes sessions last updated more than maxAge ago, then the oldest
+// sessions until the store fits in maxBytes. Zero disables a limit. The
+// session named keep is never removed.
+func GC(maxAge time.Duration, maxBytes int64, keep string) ([]string, error) {
+	infos, err := List()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	var total int64
+	cutoff := time.Now().Add(-maxAge)
+	for _, info := range infos { // newest first
+		expired := maxAge > 0 && info.Updated.Before(cutoff)
+		overSize := maxBytes > 0 && total+info.Size > maxBytes
+		if info.ID != keep && (expired || overSize) {
+			if err := os.Remove(filepath.Join(dir, info.ID+".json")); err == nil {
+				removed = append(removed, info.ID)
+			}
+			continue
+		}
+		total += info.Size
+	}
+	return removed, nil
+}
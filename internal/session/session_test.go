@@ -0,0 +1,133 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+func TestSaveLoadGC(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	contents := []api.Content{
+		{Role: "user", Parts: []api.Part{{Text: "Fix the flaky test\nin pkg/foo"}}},
+		{Role: "model", Parts: []api.Part{{Text: "Done."}}},
+	}
+	old := New("/work", "gemini-2.5-flash")
+	old.ID = "20260101-000000-aaaaaa"
+	recent := New("/work", "gemini-2.5-flash")
+	recent.ID = "20260102-000000-bbbbbb"
+	for _, s := range []*Session{old, recent} {
+		if err := s.Save(contents); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Load("20260101")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Fix the flaky test" || len(got.Contents) != 2 {
+		t.Errorf("Load = %+v", got)
+	}
+	if _, err := Load("2026"); err == nil {
+		t.Error("expected an error for an ambiguous prefix")
+	}
+	for _, id := range []string{"*", "2026*", "[2]0260101"} {
+		if _, err := Load(id); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Load(%q) = %v, want not found", id, err)
+		}
+	}
+
+	// Backdate the first session past the age limit
+	dir, _ := Dir()
+	old.Updated = time.Now().Add(-48 * time.Hour)
+	data, _ := json.Marshal(old)
+	if err := os.WriteFile(filepath.Join(dir, old.ID+".json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if latest, err := Load("latest"); err != nil || latest.ID != recent.ID {
+		t.Errorf("Load(latest) = %v, %v", latest, err)
+	}
+
+	removed, err := GC(24*time.Hour, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != old.ID {
+		t.Errorf("GC removed %v, want [%s]", removed, old.ID)
+	}
+
+	removed, _ = GC(0, 1, recent.ID)
+	if len(removed) != 0 {
+		t.Errorf("GC removed the kept session: %v", removed)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	long := strings.Repeat("日本語", 30)
+	got := title([]api.Content{{Role: "user", Parts: []api.Part{{Text: long}}}})
+	if !utf8.ValidString(got) || !strings.HasSuffix(got, "...") || len(got) > 80 {
+		t.Errorf("title = %q", got)
+	}
+	if got := cut("aé", 2); got != "a" {
+		t.Errorf(`cut("aé", 2) = %q`, got)
+	}
+}
+
+func TestGeminiCLICheckpoint(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := New("/work", "gemini-2.5-pro")
+	s.Contents = []api.Content{
+		{Role: "user", Parts: []api.Part{{Text: "List the files"}}},
+		{Role: "model", Parts: []api.Part{{FunctionCall: &api.FunctionCall{Name: "list_directory", Args: map[string]interface{}{"path": "."}}}}},
+		{Role: "user", Parts: []api.Part{{FunctionResp: &api.FunctionResp{Name: "list_directory", Response: map[string]interface{}{"count": 1.0}}}}},
+		{Role: "model", Parts: []api.Part{{Text: "One file."}}},
+	}
+	var buf bytes.Buffer
+	if err := Export(&buf, s, "gemini-cli"); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := Import(&buf, "gemini-cli", "/other", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(imported.Contents, s.Contents) || imported.WorkDir != "/other" || imported.ID == s.ID {
+		t.Errorf("round trip = %+v", imported)
+	}
+
+	// Checkpoints of older versions are bare arrays, with thoughts
+	legacy := `[{"role":"user","parts":[{"text":"This is the Gemini CLI. We are setting up the context for our chat.\nToday is..."}]},
+		{"role":"model","parts":[{"text":"Got it. Thanks for the context!"}]},
+		{"role":"user","parts":[{"text":"hi"}]},
+		{"role":"model","parts":[{"text":"planning","thought":true},{"text":"hello"}]}]`
+	imported, err = Import(strings.NewReader(legacy), "gemini-cli", "/work", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []api.Content{
+		{Role: "user", Parts: []api.Part{{Text: "hi"}}},
+		{Role: "model", Parts: []api.Part{{Text: "hello"}}},
+	}
+	if !reflect.DeepEqual(imported.Contents, want) {
+		t.Errorf("legacy import = %+v", imported.Contents)
+	}
+
+	path, err := CheckpointPath("/work", "my chat/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "checkpoint-my%20chat%2Fv2.json" || filepath.Base(filepath.Dir(path)) != "0c9a453fad615c83c57d57c2b2be05fa938f0791d7624db43827a033227bf78b" {
+		t.Errorf("CheckpointPath = %s", path)
+	}
+}
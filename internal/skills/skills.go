@@ -0,0 +1,220 @@
+// Package skills discovers and validates agent skills: directories under
+// .gemini/skills/ containing a SKILL.md with name/description frontmatter.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package skills
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// FileName is the skill definition file inside a skill directory.
+	FileName = "SKILL.md"
+
+	maxDescriptionLen = 1024
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Skill is a discovered skill.
+type Skill struct {
+	Name        string
+	Description string
+	Scope       string // "project" or "user"
+	Dir         string
+	Body        string // instructions after the frontmatter
+}
+
+// Path returns the skill's SKILL.md path.
+func (s Skill) Path() string {
+	return filepath.Join(s.Dir, FileName)
+}
+
+// Problem describes a skill that failed validation.
+type Problem struct {
+	Path string
+	Err  error
+}
+
+func (p Problem) Error() string {
+	return fmt.Sprintf("%s: %v", p.Path, p.Err)
+}
+
+// Dirs returns the project and user skill directories, in priority order.
+func Dirs(workDir string) []struct{ Scope, Dir string } {
+	dirs := []struct{ Scope, Dir string }{
+		{"project", filepath.Join(workDir, ".gemini", "skills")},
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, struct{ Scope, Dir string }{"user", filepath.Join(home, ".gemini", "skills")})
+	}
+	return dirs
+}
+
+// Discover returns the valid skills visible from workDir, sorted by name,
+// and the problems found in invalid ones. Project skills shadow user
+// skills of the same name.
+func Discover(workDir string) ([]Skill, []Problem) {
+	seen := make(map[string]bool)
+	var found []Skill
+	var problems []Problem
+	for _, d := range Dirs(workDir) {
+		entries, err := os.ReadDir(d.Dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			dir := filepath.Join(d.Dir, e.Name())
+			s, err := Load(dir)
+			if err != nil {
+				problems = append(problems, Problem{Path: filepath.Join(dir, FileName), Err: err})
+				continue
+			}
+			if seen[s.Name] {
+				continue
+			}
+			seen[s.Name] = true
+			s.Scope = d.Scope
+			found = append(found, *s)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found, problems
+}
+
+// Find returns the named skill visible from workDir.
+func Find(workDir, name string) (*Skill, error) {
+	found, _ := Discover(workDir)
+	for i := range found {
+		if found[i].Name == name {
+			return &found[i], nil
+		}
+	}
+	return nil, fmt.Errorf("skill %q not found", name)
+}
+
+// Load reads and validates the skill in dir.
+func Load(dir string) (*Skill, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, err
+	}
+	fields, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, err
+	}
+	s := &Skill{
+		Name:        fields["name"],
+		Description: fields["description"],
+		Dir:         dir,
+		Body:        strings.TrimSpace(body),
+	}
+	if err := Validate(s.Name, s.Description); err != nil {
+		return nil, err
+	}
+	if base := filepath.Base(dir); base != s.Name {
+		return nil, fmt.Errorf("name %q does not match directory %q", s.Name, base)
+	}
+	return s, nil
+}
+
+// Validate checks a skill's name and description.
+func Validate(name, description string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("frontmatter is missing name")
+	case !namePattern.MatchString(name):
+		return fmt.Errorf("name %q must be lowercase letters, digits and hyphens", name)
+	case strings.TrimSpace(description) == "":
+		return fmt.Errorf("frontmatter is missing description")
+	case len(description) > maxDescriptionLen:
+		return fmt.Errorf("description is longer than %d characters", maxDescriptionLen)
+	}
+	return nil
+}
+
+// ParseFrontmatter splits a leading "---" block of "key: value" lines from
+// the document body.
+func ParseFrontmatter(doc string) (map[string]string, string, error) {
+	doc = strings.TrimPrefix(doc, "\ufeff")
+	doc = strings.ReplaceAll(doc, "\r\n", "\n")
+	if !strings.HasPrefix(doc, "---\n") {
+		return nil, "", fmt.Errorf("missing frontmatter (the file must start with ---)")
+	}
+	head, body, ok := strings.Cut(doc[4:], "\n---")
+	if !ok {
+		return nil, "", fmt.Errorf("unterminated frontmatter")
+	}
+	// Drop the rest of the closing delimiter line
+	if _, rest, ok := strings.Cut(body, "\n"); ok {
+		body = rest
+	} else {
+		body = ""
+	}
+
+	fields := make(map[string]string)
+	for i, line := range strings.Split(head, "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, "", fmt.Errorf("frontmatter line %d: expected key: value", i+2)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		fields[strings.TrimSpace(key)] = value
+	}
+	return fields, body, nil
+}
+
+// Create writes a new skill skeleton under dir and returns its path.
+func Create(dir, name, description string) (string, error) {
+	if err := Validate(name, description); err != nil {
+		return "", err
+	}
+	skillDir := filepath.Join(dir, name)
+	path := filepath.Join(skillDir, FileName)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("skill %q already exists at %s", name, path)
+	}
+	if err := os.MkdirAll(skillDir, 0755); err != nil {
+		return "", err
+	}
+	content := fmt.Sprintf(`---
+name: %s
+description: %s
+---
+
+# %s
+
+Describe when this skill applies and the steps the agent should follow.
+Reference helper files in this directory by relative path.
+`, name, description, name)
+	return path, os.WriteFile(path, []byte(content), 0644)
+}
+
+// Summary renders the system prompt section listing available skills.
+func Summary(found []Skill) string {
+	if len(found) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("# Available Skills\n\n")
+	b.WriteString("The following skills provide specialized instructions. When a task matches a skill's description, call 'activate_skill' with its name before starting.\n\n")
+	for _, s := range found {
+		fmt.Fprintf(&b, "- **%s**: %s\n", s.Name, s.Description)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
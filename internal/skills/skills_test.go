@@ -0,0 +1,70 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSkill(t *testing.T, root, dir, content string) {
+	t.Helper()
+	path := filepath.Join(root, ".gemini", "skills", dir, FileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	home := t.TempDir()
+	work := t.TempDir()
+	t.Setenv("HOME", home)
+
+	writeSkill(t, work, "release-notes", "---\nname: release-notes\ndescription: \"Draft release notes from git history\"\n---\n\nRun git log.\n")
+	writeSkill(t, home, "release-notes", "---\nname: release-notes\ndescription: user copy\n---\n")
+	writeSkill(t, home, "db-migrate", "---\nname: db-migrate\ndescription: Write a database migration\n---\nSteps.")
+	writeSkill(t, work, "broken", "no frontmatter")
+	writeSkill(t, work, "mismatch", "---\nname: other\ndescription: x\n---\n")
+
+	found, problems := Discover(work)
+	if len(found) != 2 || found[0].Name != "db-migrate" || found[1].Name != "release-notes" {
+		t.Fatalf("found %+v", found)
+	}
+	if found[1].Scope != "project" || found[1].Description != "Draft release notes from git history" || found[1].Body != "Run git log." {
+		t.Errorf("project skill should shadow user skill: %+v", found[1])
+	}
+	if len(problems) != 2 {
+		t.Errorf("problems = %v, want 2", problems)
+	}
+
+	summary := Summary(found)
+	if !strings.Contains(summary, "- **db-migrate**: Write a database migration") {
+		t.Errorf("summary missing skill:\n%s", summary)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Create(dir, "Bad Name", "x"); err == nil {
+		t.Error("expected invalid name error")
+	}
+	path, err := Create(dir, "lint-fix", "Fix lint errors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("created skill does not validate: %v", err)
+	}
+	if s.Description != "Fix lint errors" {
+		t.Errorf("description = %q", s.Description)
+	}
+	if _, err := Create(dir, "lint-fix", "again"); err == nil {
+		t.Error("expected already exists error")
+	}
+}
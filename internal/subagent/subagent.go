@@ -0,0 +1,162 @@
+// Package subagent loads custom agent definitions: Markdown files under
+// .gemini/agents/ whose frontmatter names the agent, describes when to use
+// it and limits its tools and model, and whose body is its system prompt.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package subagent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/skills"
+)
+
+// Definition is a custom agent.
+type Definition struct {
+	Name         string
+	Description  string
+	Prompt       string   // system prompt (the file body)
+	Tools        []string // tools it may use, MCP tools as server__tool; empty means all
+	Model        string   // empty means the caller's model
+	Declarations string   // "full" or "compact" tool declarations; empty means the settings'
+	Scope        string   // "project" or "user"
+	Path         string
+}
+
+// Problem describes an agent file that failed to load.
+type Problem struct {
+	Path string
+	Err  error
+}
+
+func (p Problem) Error() string {
+	return fmt.Sprintf("%s: %v", p.Path, p.Err)
+}
+
+// Dirs returns the project and user agent directories, in priority order.
+func Dirs(workDir string) []struct{ Scope, Dir string } {
+	dirs := []struct{ Scope, Dir string }{
+		{"project", filepath.Join(workDir, ".gemini", "agents")},
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, struct{ Scope, Dir string }{"user", filepath.Join(home, ".gemini", "agents")})
+	}
+	return dirs
+}
+
+// Discover returns the valid agents visible from workDir, sorted by name,
+// and the problems found in invalid ones. Project agents shadow user agents
+// of the same name.
+func Discover(workDir string) ([]Definition, []Problem) {
+	seen := make(map[string]bool)
+	var found []Definition
+	var problems []Problem
+	for _, d := range Dirs(workDir) {
+		paths, _ := filepath.Glob(filepath.Join(d.Dir, "*.md"))
+		for _, path := range paths {
+			def, err := Load(path)
+			if err != nil {
+				problems = append(problems, Problem{Path: path, Err: err})
+				continue
+			}
+			if seen[def.Name] {
+				continue
+			}
+			seen[def.Name] = true
+			def.Scope = d.Scope
+			found = append(found, *def)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found, problems
+}
+
+// Find returns the named agent visible from workDir.
+func Find(workDir, name string) (*Definition, error) {
+	found, problems := Discover(workDir)
+	for i := range found {
+		if found[i].Name == name {
+			return &found[i], nil
+		}
+	}
+	for _, p := range problems {
+		if strings.TrimSuffix(filepath.Base(p.Path), ".md") == name {
+			return nil, p
+		}
+	}
+	return nil, fmt.Errorf("agent %q not found in .gemini/agents", name)
+}
+
+// Load reads and validates the agent file at path. The name defaults to
+// the file name without .md; tools is a comma-separated list, optionally in
+// brackets.
+func Load(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fields, body, err := skills.ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(filepath.Base(path), ".md")
+	def := &Definition{
+		Name:         fields["name"],
+		Description:  fields["description"],
+		Prompt:       strings.TrimSpace(body),
+		Tools:        splitList(fields["tools"]),
+		Model:        fields["model"],
+		Declarations: fields["declarations"],
+		Path:         path,
+	}
+	if def.Name == "" {
+		def.Name = base
+	}
+	if err := skills.Validate(def.Name, def.Description); err != nil {
+		return nil, err
+	}
+	if def.Name != base {
+		return nil, fmt.Errorf("name %q does not match file name %q", def.Name, base+".md")
+	}
+	if def.Prompt == "" {
+		return nil, fmt.Errorf("the file has no system prompt after the frontmatter")
+	}
+	switch def.Declarations {
+	case "", "full", "compact":
+	default:
+		return nil, fmt.Errorf("invalid declarations %q (use full or compact)", def.Declarations)
+	}
+	return def, nil
+}
+
+func splitList(s string) []string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.Trim(strings.TrimSpace(item), `"'`)
+		if item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// Summary renders the system prompt section listing the agents the task
+// tool can delegate to.
+func Summary(found []Definition) string {
+	if len(found) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("# Available Agents\n\n")
+	b.WriteString("These specialized agents can take on a self-contained task. When a task matches an agent's description, delegate it with the 'task' tool and include everything the agent needs to know, since it does not see this conversation.\n\n")
+	for _, d := range found {
+		fmt.Fprintf(&b, "- **%s**: %s\n", d.Name, d.Description)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package subagent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeAgent(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, ".gemini", "agents", name+".md")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	home := t.TempDir()
+	work := t.TempDir()
+	t.Setenv("HOME", home)
+
+	writeAgent(t, work, "test-writer", "---\ndescription: Writes table-driven Go tests\ntools: [read_file, write_file, \"run_shell_command\"]\nmodel: gemini-2.5-pro\n---\n\nYou write tests.\n")
+	writeAgent(t, home, "test-writer", "---\ndescription: user copy\n---\nUser prompt.")
+	writeAgent(t, home, "security-auditor", "---\nname: security-auditor\ndescription: Reviews code for vulnerabilities\n---\nFind bugs.")
+	writeAgent(t, work, "empty", "---\ndescription: no prompt\n---\n")
+	writeAgent(t, work, "renamed", "---\nname: other\ndescription: x\n---\nPrompt.")
+
+	found, problems := Discover(work)
+	if len(found) != 2 || found[0].Name != "security-auditor" || found[1].Name != "test-writer" {
+		t.Fatalf("found %+v", found)
+	}
+	tw := found[1]
+	if tw.Scope != "project" || tw.Prompt != "You write tests." || tw.Model != "gemini-2.5-pro" {
+		t.Errorf("project agent should shadow user agent: %+v", tw)
+	}
+	if strings.Join(tw.Tools, ",") != "read_file,write_file,run_shell_command" {
+		t.Errorf("tools = %q", tw.Tools)
+	}
+	if len(found[0].Tools) != 0 {
+		t.Errorf("agent without tools should get all: %q", found[0].Tools)
+	}
+	if len(problems) != 2 {
+		t.Errorf("problems = %v, want 2", problems)
+	}
+
+	if _, err := Find(work, "empty"); err == nil || !strings.Contains(err.Error(), "no system prompt") {
+		t.Errorf("Find(empty) error = %v", err)
+	}
+	if !strings.Contains(Summary(found), "- **test-writer**: Writes table-driven Go tests") {
+		t.Errorf("summary:\n%s", Summary(found))
+	}
+}
@@ -0,0 +1,150 @@
+// Package summary describes what an agent run did: the files it changed,
+// the commands it ran and the tokens it used, so that wrappers can act on
+// the outcome without diffing the worktree themselves.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package summary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/k-sub1995/g/internal/filewatch"
+)
+
+// maxListed bounds the files and commands named in the text version.
+const maxListed = 10
+
+// Run is the summary of one run.
+type Run struct {
+	Turns     int       `json:"turns"` // model calls
+	ToolCalls int       `json:"toolCalls"`
+	Tokens    Tokens    `json:"tokens"`
+	Files     []File    `json:"files"`
+	Commands  []Command `json:"commands"`
+
+	// FilesIncomplete is set when the workspace had more files than are
+	// watched, so Files may miss some changes.
+	FilesIncomplete bool `json:"filesIncomplete,omitempty"`
+}
+
+// Tokens adds up the usage the API reported for each model call.
+type Tokens struct {
+	Input  int `json:"input"`
+	Output int `json:"output"`
+	Total  int `json:"total"`
+}
+
+// File is a workspace file the run created, modified or deleted. Path is
+// relative to the working directory, with forward slashes.
+type File struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`           // "created", "modified" or "deleted"
+	SHA256 string `json:"sha256,omitempty"` // of the content after the run
+}
+
+// Command is a shell command the run executed.
+type Command struct {
+	Command  string `json:"command"`
+	ExitCode *int   `json:"exitCode,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Files lists changes under root with the hashes of the files that exist.
+func Files(root string, c filewatch.Changes) []File {
+	files := []File{}
+	for _, group := range []struct {
+		change string
+		paths  []string
+	}{
+		{"created", c.Created},
+		{"modified", c.Modified},
+		{"deleted", c.Deleted},
+	} {
+		for _, p := range group.paths {
+			f := File{Path: p, Change: group.change}
+			if group.change != "deleted" {
+				f.SHA256 = hashFile(filepath.Join(root, filepath.FromSlash(p)))
+			}
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func hashFile(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Text is the short human version: one line of counts, then the changed
+// files and the commands that failed.
+func (r Run) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, %s, %s, %s tokens", plural(r.Turns, "turn"), plural(len(r.Files), "file")+" changed",
+		plural(len(r.Commands), "command"), formatTokens(r.Tokens.Total))
+	marks := map[string]string{"created": "+", "modified": "~", "deleted": "-"}
+	for i, f := range r.Files {
+		if i == maxListed {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(r.Files)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %s %s", marks[f.Change], f.Path)
+	}
+	if r.FilesIncomplete {
+		b.WriteString("\n  (too many files to watch; changes may be missing)")
+	}
+	failed := 0
+	for _, c := range r.Commands {
+		if c.ExitCode == nil || *c.ExitCode == 0 {
+			continue
+		}
+		if failed++; failed > maxListed {
+			continue
+		}
+		fmt.Fprintf(&b, "\n  $ %s (exit %d)", truncate(c.Command, 80), *c.ExitCode)
+	}
+	if failed > maxListed {
+		fmt.Fprintf(&b, "\n  ... and %d more failed commands", failed-maxListed)
+	}
+	return b.String()
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+func formatTokens(n int) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/filewatch"
+)
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "new.txt"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := Files(dir, filewatch.Changes{Created: []string{"sub/new.txt"}, Deleted: []string{"old.txt"}})
+	want := []File{
+		{Path: "sub/new.txt", Change: "created", SHA256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"},
+		{Path: "old.txt", Change: "deleted"},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("Files = %+v, want %+v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("Files[%d] = %+v, want %+v", i, files[i], want[i])
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	one := 1
+	r := Run{
+		Turns:  3,
+		Tokens: Tokens{Total: 12345},
+		Files:  []File{{Path: "a.go", Change: "modified"}, {Path: "b.go", Change: "created"}},
+		Commands: []Command{
+			{Command: "go build ./...", ExitCode: new(int)},
+			{Command: "go test\n./...", ExitCode: &one},
+		},
+	}
+	want := "3 turns, 2 files changed, 2 commands, 12.3k tokens\n  ~ a.go\n  + b.go\n  $ go test ./... (exit 1)"
+	if got := r.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if got := (Run{Turns: 1}).Text(); !strings.HasPrefix(got, "1 turn, 0 files changed, 0 commands, 0 tokens") {
+		t.Errorf("Text() = %q", got)
+	}
+	if got := (Run{FilesIncomplete: true}).Text(); !strings.Contains(got, "changes may be missing") {
+		t.Errorf("Text() of an incomplete run = %q", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("aé", 2); got != "a..." {
+		t.Errorf(`truncate("aé", 2) = %q`, got)
+	}
+}
@@ -0,0 +1,85 @@
+// Package termimage shows images inline in terminals that support it.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package termimage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Protocol is an inline image protocol.
+type Protocol int
+
+const (
+	None   Protocol = iota
+	Kitty           // kitty graphics protocol (kitty, Ghostty)
+	ITerm2          // iTerm2 inline images (iTerm2, WezTerm)
+)
+
+// kittyChunk is the largest payload of one kitty graphics escape.
+const kittyChunk = 4096
+
+// Detect returns the protocol the terminal running us supports, judged from
+// the environment. Inside tmux or screen escapes are not passed through, so
+// None is returned there.
+func Detect() Protocol {
+	return detect(os.Getenv)
+}
+
+func detect(getenv func(string) string) Protocol {
+	if getenv("TMUX") != "" || strings.HasPrefix(getenv("TERM"), "screen") {
+		return None
+	}
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", getenv("TERM") == "xterm-kitty", getenv("TERM_PROGRAM") == "ghostty":
+		return Kitty
+	case getenv("TERM_PROGRAM") == "iTerm.app", getenv("TERM_PROGRAM") == "WezTerm", getenv("LC_TERMINAL") == "iTerm2":
+		return ITerm2
+	}
+	return None
+}
+
+// Write shows a PNG or JPEG image on w using p. Kitty only accepts PNG;
+// other formats are skipped without error.
+func Write(w io.Writer, p Protocol, mimeType string, data []byte) error {
+	enc := base64.StdEncoding.EncodeToString(data)
+	switch p {
+	case Kitty:
+		if mimeType != "image/png" {
+			return nil
+		}
+		for first := true; ; first = false {
+			chunk := enc
+			if len(chunk) > kittyChunk {
+				chunk = enc[:kittyChunk]
+			}
+			enc = enc[len(chunk):]
+			more := 0
+			if enc != "" {
+				more = 1
+			}
+			ctrl := fmt.Sprintf("m=%d", more)
+			if first {
+				ctrl = "a=T,f=100," + ctrl
+			}
+			if _, err := fmt.Fprintf(w, "\x1b_G%s;%s\x1b\\", ctrl, chunk); err != nil {
+				return err
+			}
+			if enc == "" {
+				break
+			}
+		}
+	case ITerm2:
+		if _, err := fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a", len(data), enc); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package termimage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want Protocol
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, None},
+		{map[string]string{"TERM": "xterm-kitty"}, Kitty},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ITerm2},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TMUX": "/tmp/tmux-0/default"}, None},
+	}
+	for _, tt := range tests {
+		if got := detect(func(k string) string { return tt.env[k] }); got != tt.want {
+			t.Errorf("detect(%v) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestWriteKittyChunks(t *testing.T) {
+	var buf bytes.Buffer
+	data := bytes.Repeat([]byte{0xff}, 4000) // 5336 base64 bytes: two chunks
+	if err := Write(&buf, Kitty, "image/png", data); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n := strings.Count(out, "\x1b_G"); n != 2 {
+		t.Fatalf("got %d escapes, want 2", n)
+	}
+	if !strings.HasPrefix(out, "\x1b_Ga=T,f=100,m=1;") || !strings.Contains(out, "\x1b_Gm=0;") {
+		t.Errorf("unexpected control data: %q", out[:40])
+	}
+
+	buf.Reset()
+	if err := Write(&buf, Kitty, "image/jpeg", data); err != nil || buf.Len() != 0 {
+		t.Errorf("jpeg on kitty wrote %d bytes, err %v", buf.Len(), err)
+	}
+}
@@ -6,10 +6,16 @@ package tools
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/k-sub1995/g/internal/api"
 )
 
+// askTimeout bounds how long ask_user waits for an answer.
+const askTimeout = 5 * time.Minute
+
 type AskUserTool struct {
 	opts RegistryOptions
 }
@@ -23,7 +29,7 @@ func (t *AskUserTool) Name() string { return "ask_user" }
 func (t *AskUserTool) Declaration() api.FunctionDecl {
 	return api.FunctionDecl{
 		Name:        "ask_user",
-		Description: "Ask the user a question and get their response. In non-interactive mode, this tool will indicate that user input is not available.",
+		Description: fmt.Sprintf("Ask the user a question and get their response. If the user gives no answer within %s, the default is used. In non-interactive mode, this tool will indicate that user input is not available.", askTimeout),
 		Parameters: mustMarshalJSON(map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -31,6 +37,10 @@ func (t *AskUserTool) Declaration() api.FunctionDecl {
 					"type":        "string",
 					"description": "The question to ask the user.",
 				},
+				"default": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: the answer to use if the user just presses Enter or does not answer in time.",
+				},
 			},
 			"required": []string{"question"},
 		}),
@@ -42,13 +52,33 @@ func (t *AskUserTool) Execute(ctx context.Context, args map[string]interface{})
 	if question == "" {
 		return errorResult("question is required"), nil
 	}
+	def, _ := args["default"].(string)
 
 	// Non-interactive mode: cannot ask questions
-	return &ToolResult{
-		Content: map[string]interface{}{
-			"message":  "Running in non-interactive mode. Cannot ask user questions. Please make your best judgment and proceed, or explain what you need in the output.",
-			"question": question,
-		},
-		IsError: true,
-	}, nil
+	if t.opts.Ask == nil {
+		return &ToolResult{
+			Content: map[string]interface{}{
+				"message":  "Running in non-interactive mode. Cannot ask user questions. Please make your best judgment and proceed, or explain what you need in the output.",
+				"question": question,
+			},
+			IsError: true,
+		}, nil
+	}
+
+	askCtx, cancel := context.WithTimeout(ctx, askTimeout)
+	defer cancel()
+	answer, err := t.opts.Ask(askCtx, question, def)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			if def != "" {
+				return &ToolResult{Content: map[string]interface{}{
+					"answer":  def,
+					"message": fmt.Sprintf("The user did not answer within %s; using the default.", askTimeout),
+				}}, nil
+			}
+			return errorResult(fmt.Sprintf("the user did not answer within %s; make your best judgment and proceed", askTimeout)), nil
+		}
+		return errorResult(fmt.Sprintf("could not ask the user: %v", err)), nil
+	}
+	return &ToolResult{Content: map[string]interface{}{"answer": answer}}, nil
 }
@@ -0,0 +1,36 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAskUser(t *testing.T) {
+	args := map[string]interface{}{"question": "Which branch?", "default": "main"}
+
+	res, _ := NewAskUserTool(RegistryOptions{}).Execute(context.Background(), args)
+	if !res.IsError {
+		t.Fatalf("non-interactive ask_user should fail: %v", res.Content)
+	}
+
+	ask := func(ctx context.Context, question, def string) (string, error) {
+		if question != "Which branch?" || def != "main" {
+			t.Errorf("Ask(%q, %q)", question, def)
+		}
+		return "develop", nil
+	}
+	res, _ = NewAskUserTool(RegistryOptions{Ask: ask}).Execute(context.Background(), args)
+	if res.IsError || res.Content["answer"] != "develop" {
+		t.Fatalf("result = %v", res.Content)
+	}
+
+	timeout := func(ctx context.Context, question, def string) (string, error) {
+		return "", context.DeadlineExceeded
+	}
+	res, _ = NewAskUserTool(RegistryOptions{Ask: timeout}).Execute(context.Background(), args)
+	if res.IsError || res.Content["answer"] != "main" {
+		t.Fatalf("timed out result = %v", res.Content)
+	}
+}
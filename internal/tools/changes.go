@@ -0,0 +1,107 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrChangeLimit is returned by the edit tools when a change would take
+// the run over its ChangeBudget and no one allowed it. It ends the run.
+var ErrChangeLimit = errors.New("change limit reached")
+
+// ChangeBudget bounds how many files and bytes write_file, replace and
+// insert change in one run, across the main agent and its subagents,
+// to limit what a misbehaving autonomous run can do.
+type ChangeBudget struct {
+	baseFiles int
+	baseBytes int64
+
+	mu       sync.Mutex
+	maxFiles int   // 0: no limit
+	maxBytes int64 // 0: no limit
+	files    map[string]bool
+	bytes    int64
+}
+
+// NewChangeBudget returns a budget of maxFiles changed files and maxBytes
+// changed bytes; zero means no limit.
+func NewChangeBudget(maxFiles int, maxBytes int64) *ChangeBudget {
+	return &ChangeBudget{
+		baseFiles: maxFiles,
+		baseBytes: maxBytes,
+		maxFiles:  maxFiles,
+		maxBytes:  maxBytes,
+		files:     map[string]bool{},
+	}
+}
+
+// charge records a change of n bytes to path. When it takes the run over a
+// limit, ask is asked whether to go on; a yes allows as much again before
+// the next question. Otherwise the error wraps ErrChangeLimit.
+func (b *ChangeBudget) charge(ctx context.Context, ask AskFunc, path string, n int64) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	files := len(b.files)
+	if !b.files[path] {
+		files++
+	}
+	bytes := b.bytes + n
+	var over []string
+	if b.maxFiles > 0 && files > b.maxFiles {
+		over = append(over, fmt.Sprintf("%d changed files (limit %d)", files, b.maxFiles))
+	}
+	if b.maxBytes > 0 && bytes > b.maxBytes {
+		over = append(over, fmt.Sprintf("%d changed bytes (limit %d)", bytes, b.maxBytes))
+	}
+	if len(over) > 0 {
+		reason := fmt.Sprintf("changing %s would take this run to %s", path, strings.Join(over, " and "))
+		if ask == nil {
+			return fmt.Errorf("%w: %s; nothing was written", ErrChangeLimit, reason)
+		}
+		answer, err := ask(ctx, fmt.Sprintf("Change limit: %s. Allow more changes? (yes/no)", reason), "no")
+		if err != nil || !strings.HasPrefix(strings.ToLower(answer), "y") {
+			return fmt.Errorf("%w: %s; nothing was written", ErrChangeLimit, reason)
+		}
+		for b.maxFiles > 0 && files > b.maxFiles {
+			b.maxFiles += b.baseFiles
+		}
+		for b.maxBytes > 0 && bytes > b.maxBytes {
+			b.maxBytes += b.baseBytes
+		}
+	}
+	b.files[path] = true
+	b.bytes = bytes
+	return nil
+}
+
+// changedBytes measures a change as the longer of the old and new text
+// between their common prefix and suffix.
+func changedBytes(old, new []byte) int64 {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+	return int64(max(len(old)-prefix-suffix, len(new)-prefix-suffix))
+}
+
+// editFailed reports a failed write to the model, except that a change
+// limit is returned as an error, which ends the run.
+func editFailed(err error) (*ToolResult, error) {
+	if errors.Is(err, ErrChangeLimit) {
+		return nil, err
+	}
+	return errorResult(err.Error()), nil
+}
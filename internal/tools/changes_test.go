@@ -0,0 +1,87 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangeBudget(t *testing.T) {
+	dir := t.TempDir()
+	var asked int
+	answer := "no"
+	opts := RegistryOptions{
+		WorkDir: dir,
+		Changes: NewChangeBudget(2, 0),
+		Ask: func(ctx context.Context, question, def string) (string, error) {
+			asked++
+			return answer, nil
+		},
+	}
+	r := NewRegistry(opts)
+	write := func(name string) error {
+		tool, _ := r.Get("write_file")
+		res, err := tool.Execute(context.Background(), map[string]interface{}{"file_path": name, "content": "x\n"})
+		if err == nil && res.IsError {
+			t.Fatalf("write %s: %v", name, res.Content)
+		}
+		return err
+	}
+
+	for _, name := range []string{"a.txt", "b.txt", "a.txt"} {
+		if err := write(name); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := write("c.txt"); !errors.Is(err, ErrChangeLimit) || asked != 1 {
+		t.Fatalf("third file: err = %v after %d questions; want ErrChangeLimit after 1", err, asked)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.txt")); !os.IsNotExist(err) {
+		t.Error("c.txt was written over the limit")
+	}
+
+	// Allowing it doubles the limit
+	answer = "yes"
+	for _, name := range []string{"c.txt", "d.txt"} {
+		if err := write(name); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if asked != 2 {
+		t.Errorf("asked %d times, want 2", asked)
+	}
+
+	// Without anyone to ask, the run stops
+	opts.Ask = nil
+	opts.Changes = NewChangeBudget(0, 10)
+	r = NewRegistry(opts)
+	if err := write("e.txt"); err != nil {
+		t.Fatal(err)
+	}
+	tool, _ := r.Get("replace")
+	_, err := tool.Execute(context.Background(), map[string]interface{}{"file_path": "a.txt", "old_string": "x", "new_string": "a much longer line"})
+	if !errors.Is(err, ErrChangeLimit) {
+		t.Errorf("replace over the byte limit: err = %v", err)
+	}
+}
+
+func TestChangedBytes(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     int64
+	}{
+		{"", "hello\n", 6},
+		{"a := 1\nb := 2\n", "a := 10\nb := 2\n", 1},
+		{"abc", "abc", 0},
+		{"one two three", "one three", 4},
+	}
+	for _, tt := range tests {
+		if got := changedBytes([]byte(tt.old), []byte(tt.new)); got != tt.want {
+			t.Errorf("changedBytes(%q, %q) = %d, want %d", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
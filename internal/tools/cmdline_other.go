@@ -0,0 +1,10 @@
+//go:build !windows
+
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import "os/exec"
+
+// setCmdLine is only needed for cmd.exe on Windows.
+func setCmdLine(cmd *exec.Cmd, line string) {}
@@ -0,0 +1,15 @@
+//go:build windows
+
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setCmdLine passes line to the process as its command line, unescaped.
+func setCmdLine(cmd *exec.Cmd, line string) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: line}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// DeclarationModes are the ways tool declarations can be sent: "full", as
+// the tools describe themselves, or "compact", which sends every turn
+// fewer tokens.
+var DeclarationModes = []string{"full", "compact"}
+
+// droppedSchemaKeys are JSON Schema keywords that document a schema
+// without changing the calls the model can make.
+var droppedSchemaKeys = []string{"$schema", "$id", "$comment", "title", "examples", "example", "default", "deprecated", "readOnly", "writeOnly"}
+
+// Declarations returns decls as mode sends them.
+func Declarations(mode string, decls []api.FunctionDecl) ([]api.FunctionDecl, error) {
+	switch mode {
+	case "", "full":
+		return decls, nil
+	case "compact":
+		out := make([]api.FunctionDecl, len(decls))
+		for i, d := range decls {
+			out[i] = compactDeclaration(d)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unknown tool declaration mode %q (use %s)", mode, strings.Join(DeclarationModes, " or "))
+	}
+}
+
+// compactDeclaration keeps the first sentence of each description and
+// removes schema keywords that only document. A schema that is not a JSON
+// object is left as it is.
+func compactDeclaration(d api.FunctionDecl) api.FunctionDecl {
+	d.Description = firstSentence(d.Description)
+	var schema map[string]interface{}
+	if len(d.Parameters) == 0 || json.Unmarshal(d.Parameters, &schema) != nil {
+		return d
+	}
+	compactSchema(schema)
+	if data, err := json.Marshal(schema); err == nil {
+		d.Parameters = data
+	}
+	return d
+}
+
+func compactSchema(schema map[string]interface{}) {
+	for _, key := range droppedSchemaKeys {
+		delete(schema, key)
+	}
+	if extra, ok := schema["additionalProperties"].(bool); ok && !extra {
+		delete(schema, "additionalProperties")
+	}
+	if desc, ok := schema["description"].(string); ok {
+		// Optional parameters are the ones not listed as required
+		desc = strings.TrimPrefix(strings.TrimPrefix(desc, "Optional: "), "(Optional) ")
+		if desc = firstSentence(desc); desc == "" {
+			delete(schema, "description")
+		} else {
+			schema["description"] = desc
+		}
+	}
+	for _, key := range []string{"properties", "$defs", "definitions", "patternProperties"} {
+		if props, ok := schema[key].(map[string]interface{}); ok {
+			for _, p := range props {
+				if p, ok := p.(map[string]interface{}); ok {
+					compactSchema(p)
+				}
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		if sub, ok := schema[key].(map[string]interface{}); ok {
+			compactSchema(sub)
+		}
+	}
+	for _, key := range []string{"anyOf", "oneOf", "allOf", "prefixItems"} {
+		if subs, ok := schema[key].([]interface{}); ok {
+			for _, sub := range subs {
+				if sub, ok := sub.(map[string]interface{}); ok {
+					compactSchema(sub)
+				}
+			}
+		}
+	}
+}
+
+// firstSentence returns the first sentence of s: up to the first period,
+// question or exclamation mark followed by a space or line break, or the
+// first line. Abbreviations such as "e.g." do not end it.
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if line, _, ok := strings.Cut(s, "\n"); ok {
+		s = strings.TrimSpace(line)
+	}
+	for i := 0; i < len(s)-1; i++ {
+		if strings.IndexByte(".!?", s[i]) < 0 || s[i+1] != ' ' {
+			continue
+		}
+		word := s[strings.LastIndexByte(s[:i], ' ')+1 : i]
+		switch strings.ToLower(word) {
+		case "e.g", "i.e", "etc", "vs", "approx":
+			continue
+		}
+		return s[:i+1]
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+func TestCompactDeclarations(t *testing.T) {
+	decl := api.FunctionDecl{
+		Name:        "search",
+		Description: "Searches files, e.g. Go sources. Returns matching lines.\nUse it before reading.",
+		Parameters: json.RawMessage(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"title": "Args",
+			"additionalProperties": false,
+			"properties": {
+				"pattern": {"type": "string", "description": "The regex. Uses RE2 syntax.", "examples": ["foo.*"]},
+				"limit": {"type": "integer", "description": "Optional: maximum matches.", "default": 100},
+				"paths": {"type": "array", "items": {"type": "string", "title": "Path", "description": "A path. Relative to the root."}},
+				"mode": {"anyOf": [{"type": "string", "deprecated": true}, {"type": "null"}]}
+			},
+			"required": ["pattern"]
+		}`),
+	}
+
+	full, err := Declarations("full", []api.FunctionDecl{decl})
+	if err != nil || string(full[0].Parameters) != string(decl.Parameters) || full[0].Description != decl.Description {
+		t.Fatalf("full mode changed the declaration: %+v, %v", full, err)
+	}
+
+	out, err := Declarations("compact", []api.FunctionDecl{decl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out[0].Description; got != "Searches files, e.g. Go sources." {
+		t.Errorf("description = %q", got)
+	}
+	want := `{"properties":{"limit":{"description":"maximum matches.","type":"integer"},` +
+		`"mode":{"anyOf":[{"type":"string"},{"type":"null"}]},` +
+		`"paths":{"items":{"description":"A path.","type":"string"},"type":"array"},` +
+		`"pattern":{"description":"The regex.","type":"string"}},"required":["pattern"],"type":"object"}`
+	if got := string(out[0].Parameters); got != want {
+		t.Errorf("parameters =\n%s\nwant\n%s", got, want)
+	}
+	if !strings.Contains(string(decl.Parameters), `"$schema"`) {
+		t.Error("compact mode modified the original declaration")
+	}
+
+	if _, err := Declarations("tiny", nil); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
+
+func TestCompactBuiltinDeclarations(t *testing.T) {
+	decls := NewRegistry(RegistryOptions{WorkDir: t.TempDir()}).AllDeclarations()
+	compact, err := Declarations("compact", decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := func(d []api.FunctionDecl) int {
+		data, _ := json.Marshal(d)
+		return len(data)
+	}
+	if size(compact) >= size(decls) {
+		t.Errorf("compact declarations are %d bytes, full %d", size(compact), size(decls))
+	}
+	for i, d := range compact {
+		if d.Name != decls[i].Name || d.Description == "" {
+			t.Errorf("compact %s: %+v", decls[i].Name, d)
+		}
+	}
+}
@@ -7,8 +7,6 @@ package tools
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/k-sub1995/g/internal/api"
@@ -27,7 +25,7 @@ func (t *EditTool) Name() string { return "replace" }
 func (t *EditTool) Declaration() api.FunctionDecl {
 	return api.FunctionDecl{
 		Name:        "replace",
-		Description: "Replaces text within a file. By default, replaces a single occurrence, but can replace multiple occurrences when `expected_replacements` is specified. Always use the read_file tool to examine the file's current content before attempting a text replacement.",
+		Description: "Replaces text within a file. By default, replaces a single occurrence, but can replace multiple occurrences when `expected_replacements` is specified. When old_string occurs more than once, `start_line`/`end_line` limit the replacement to occurrences starting in that line range instead of quoting more context. Always use the read_file tool to examine the file's current content before attempting a text replacement.",
 		Parameters: mustMarshalJSON(map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -48,6 +46,16 @@ func (t *EditTool) Declaration() api.FunctionDecl {
 					"description": "Number of replacements expected. Defaults to 1 if not specified.",
 					"minimum":     1,
 				},
+				"start_line": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: only replace occurrences starting at or after this 1-based line (as reported by grep_search; read_file offset N is line N+1).",
+					"minimum":     1,
+				},
+				"end_line": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: only replace occurrences starting at or before this 1-based line.",
+					"minimum":     1,
+				},
 			},
 			"required": []string{"file_path", "old_string", "new_string"},
 		}),
@@ -59,6 +67,8 @@ func (t *EditTool) Execute(ctx context.Context, args map[string]interface{}) (*T
 	oldString, _ := args["old_string"].(string)
 	newString, _ := args["new_string"].(string)
 	expectedReplacements := intArg(args, "expected_replacements", 1)
+	startLine := intArg(args, "start_line", 0)
+	endLine := intArg(args, "end_line", 0)
 
 	if filePath == "" {
 		return errorResult("file_path is required"), nil
@@ -66,49 +76,94 @@ func (t *EditTool) Execute(ctx context.Context, args map[string]interface{}) (*T
 	if oldString == "" {
 		return errorResult("old_string is required"), nil
 	}
+	if endLine > 0 && startLine > endLine {
+		return errorResult("start_line must not be after end_line"), nil
+	}
 
-	absPath := t.resolvePath(filePath)
+	absPath := t.opts.resolvePath(filePath)
 
-	if t.opts.Sandbox {
-		if !isPathUnder(absPath, t.opts.WorkDir) {
-			return errorResult(fmt.Sprintf("sandbox: cannot edit files outside working directory %s", t.opts.WorkDir)), nil
-		}
+	if t.opts.outsideSandbox(absPath) {
+		return errorResult(fmt.Sprintf("sandbox: cannot edit files outside working directory %s", t.opts.WorkDir)), nil
 	}
 
-	data, err := os.ReadFile(absPath)
+	if res := t.opts.reads.stale(absPath); res != nil {
+		return res, nil
+	}
+	data, err := readForEdit(t.opts, absPath)
 	if err != nil {
 		return errorResult(fmt.Sprintf("failed to read file: %v", err)), nil
 	}
 
-	content := string(data)
-	count := strings.Count(content, oldString)
+	// Edit legacy encodings as text and write them back unchanged
+	content, enc := decodeText(data)
+	matches := findOccurrences(content, oldString, startLine, endLine)
+	count := len(matches)
+	where := absPath
+	if startLine > 0 || endLine > 0 {
+		where = fmt.Sprintf("%s within the given lines", absPath)
+	}
 
 	if count == 0 {
-		return errorResult(fmt.Sprintf("old_string not found in %s. Make sure you have the exact text including whitespace and indentation.", absPath)), nil
+		return errorResult(fmt.Sprintf("old_string not found in %s. Make sure you have the exact text including whitespace and indentation.", where)), nil
 	}
 
 	if expectedReplacements > 0 && count != expectedReplacements {
-		return errorResult(fmt.Sprintf("expected %d replacement(s) but found %d occurrence(s) of old_string in %s", expectedReplacements, count, absPath)), nil
+		return errorResult(fmt.Sprintf("expected %d replacement(s) but found %d occurrence(s) of old_string in %s", expectedReplacements, count, where)), nil
 	}
 
-	newContent := strings.Replace(content, oldString, newString, expectedReplacements)
+	var b strings.Builder
+	last := 0
+	for _, i := range matches {
+		b.WriteString(content[last:i])
+		b.WriteString(newString)
+		last = i + len(oldString)
+	}
+	b.WriteString(content[last:])
+	out := []byte(b.String())
+	if enc != nil {
+		if out, err = enc.NewEncoder().Bytes(out); err != nil {
+			return errorResult(fmt.Sprintf("new_string cannot be written in the file's %s encoding: %v", enc.Name, err)), nil
+		}
+	}
 
-	if err := os.WriteFile(absPath, []byte(newContent), 0644); err != nil {
-		return errorResult(fmt.Sprintf("failed to write file: %v", err)), nil
+	proposed, err := writeEdit(ctx, t.opts, absPath, out)
+	if err != nil {
+		return editFailed(err)
 	}
 
+	message := fmt.Sprintf("Successfully replaced %d occurrence(s) in %s", count, absPath)
+	if proposed {
+		message = proposedMessage(absPath)
+	}
 	return &ToolResult{
 		Content: map[string]interface{}{
-			"message":      fmt.Sprintf("Successfully replaced %d occurrence(s) in %s", expectedReplacements, absPath),
+			"message":      message,
 			"file_path":    absPath,
-			"replacements": expectedReplacements,
+			"replacements": count,
 		},
 	}, nil
 }
 
-func (t *EditTool) resolvePath(path string) string {
-	if filepath.IsAbs(path) {
-		return path
+// findOccurrences returns the byte offsets of the non-overlapping
+// occurrences of old in content that start within lines [start, end]
+// (1-based; zero leaves that side open).
+func findOccurrences(content, old string, start, end int) []int {
+	var matches []int
+	line, counted := 1, 0
+	for i := 0; ; {
+		j := strings.Index(content[i:], old)
+		if j < 0 {
+			return matches
+		}
+		i += j
+		line += strings.Count(content[counted:i], "\n")
+		counted = i
+		if end > 0 && line > end {
+			return matches
+		}
+		if line >= start {
+			matches = append(matches, i)
+		}
+		i += len(old)
 	}
-	return filepath.Join(t.opts.WorkDir, path)
 }
@@ -0,0 +1,46 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditLineAnchors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("return nil\nx\nreturn nil\ny\nreturn nil\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	edit := NewEditTool(RegistryOptions{WorkDir: dir})
+	ctx := context.Background()
+
+	res, _ := edit.Execute(ctx, map[string]interface{}{"file_path": "a.txt", "old_string": "return nil", "new_string": "return err"})
+	if !res.IsError {
+		t.Fatal("ambiguous old_string without anchors should fail")
+	}
+
+	res, _ = edit.Execute(ctx, map[string]interface{}{
+		"file_path": "a.txt", "old_string": "return nil", "new_string": "return err",
+		"start_line": float64(2), "end_line": float64(3),
+	})
+	if res.IsError {
+		t.Fatalf("anchored edit: %v", res.Content)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "return nil\nx\nreturn err\ny\nreturn nil\n"; string(data) != want {
+		t.Errorf("content = %q, want %q", data, want)
+	}
+
+	res, _ = edit.Execute(ctx, map[string]interface{}{
+		"file_path": "a.txt", "old_string": "return nil", "new_string": "return err",
+		"start_line": float64(4), "expected_replacements": float64(1),
+	})
+	data, _ = os.ReadFile(path)
+	if res.IsError || string(data) != "return nil\nx\nreturn err\ny\nreturn err\n" {
+		t.Errorf("open-ended anchor: %v, content %q", res.Content, data)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// sniffLen is how much of a file is examined to detect its encoding.
+const sniffLen = 64 * 1024
+
+// textEncoding is a non-UTF-8 encoding detected in a file.
+type textEncoding struct {
+	Name string
+	encoding.Encoding
+}
+
+var (
+	utf8BOM = textEncoding{"UTF-8 with BOM", xunicode.UTF8BOM}
+	utf16LE = textEncoding{"UTF-16LE", xunicode.UTF16(xunicode.LittleEndian, xunicode.ExpectBOM)}
+	utf16BE = textEncoding{"UTF-16BE", xunicode.UTF16(xunicode.BigEndian, xunicode.ExpectBOM)}
+	sjis    = textEncoding{"Shift_JIS", japanese.ShiftJIS}
+	latin1  = textEncoding{"ISO-8859-1", charmap.ISO8859_1}
+	cp1252  = textEncoding{"windows-1252", charmap.Windows1252}
+)
+
+// detectEncoding guesses the encoding of text from a sample of its start
+// (complete is false if the file continues). It returns nil for UTF-8 and
+// for binary data, which are read as is. Byte order marks identify UTF-8
+// and UTF-16; otherwise invalid UTF-8 is taken as Shift_JIS if it decodes
+// cleanly to Japanese text, and as Latin-1 (or windows-1252, which also
+// uses 0x80-0x9F) failing that.
+func detectEncoding(sample []byte, complete bool) *textEncoding {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return &utf8BOM
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return &utf16LE
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return &utf16BE
+	case bytes.IndexByte(sample, 0) >= 0:
+		return nil
+	}
+	if validUTF8Prefix(sample, complete) {
+		return nil
+	}
+
+	if decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(sample); err == nil {
+		s := string(decoded)
+		if !complete {
+			s = strings.TrimSuffix(s, "\ufffd") // a character cut by the sample
+		}
+		if !strings.ContainsRune(s, '\ufffd') && strings.IndexFunc(s, isJapanese) >= 0 {
+			return &sjis
+		}
+	}
+	for _, b := range sample {
+		if b >= 0x80 && b <= 0x9F {
+			return &cp1252
+		}
+	}
+	return &latin1
+}
+
+// validUTF8Prefix reports whether sample is valid UTF-8, allowing a rune
+// cut off at its end when the sample is incomplete.
+func validUTF8Prefix(sample []byte, complete bool) bool {
+	if utf8.Valid(sample) {
+		return true
+	}
+	if complete {
+		return false
+	}
+	for i := 1; i < utf8.UTFMax && i < len(sample); i++ {
+		if utf8.Valid(sample[:len(sample)-i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isJapanese(r rune) bool {
+	return unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han)
+}
+
+// decodeText converts data to UTF-8, returning the detected encoding (nil
+// when data was already UTF-8 or binary).
+func decodeText(data []byte) (string, *textEncoding) {
+	enc := detectEncoding(data[:min(len(data), sniffLen)], len(data) <= sniffLen)
+	if enc == nil {
+		return string(data), nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data), nil
+	}
+	return string(decoded), enc
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"utf8", []byte("こんにちは, héllo"), ""},
+		{"binary", []byte{0x89, 'P', 'N', 'G', 0, 0}, ""},
+		{"utf8 bom", []byte("\xEF\xBB\xBFhello"), "UTF-8 with BOM"},
+		{"utf16le", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, "UTF-16LE"},
+		{"shift_jis", []byte{0x82, 0xB1, 0x82, 0xF1, 0x82, 0xC9, 0x82, 0xBF, 0x82, 0xCD}, "Shift_JIS"},
+		{"latin1", []byte("caf\xe9 cr\xe8me"), "ISO-8859-1"},
+		{"windows-1252", []byte("\x93quoted\x94"), "windows-1252"},
+	}
+	for _, tt := range tests {
+		got := ""
+		if enc := detectEncoding(tt.data, true); enc != nil {
+			got = enc.Name
+		}
+		if got != tt.want {
+			t.Errorf("%s: detectEncoding = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadAndEditShiftJIS(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.txt")
+	// "こんにちは\n" in Shift_JIS
+	sjisHello := []byte{0x82, 0xB1, 0x82, 0xF1, 0x82, 0xC9, 0x82, 0xBF, 0x82, 0xCD, '\n'}
+	if err := os.WriteFile(path, sjisHello, 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := RegistryOptions{WorkDir: dir}
+	ctx := context.Background()
+
+	res, _ := NewReadFileTool(opts).Execute(ctx, map[string]interface{}{"file_path": "hello.txt"})
+	if res.Content["content"] != "こんにちは" || res.Content["encoding"] != "Shift_JIS" {
+		t.Fatalf("read_file = %v", res.Content)
+	}
+
+	res, _ = NewEditTool(opts).Execute(ctx, map[string]interface{}{"file_path": "hello.txt", "old_string": "こんにちは", "new_string": "こんばんは"})
+	if res.IsError {
+		t.Fatalf("replace: %v", res.Content)
+	}
+	data, _ := os.ReadFile(path)
+	want := []byte{0x82, 0xB1, 0x82, 0xF1, 0x82, 0xCE, 0x82, 0xF1, 0x82, 0xCD, '\n'}
+	if string(data) != string(want) {
+		t.Errorf("file = % x, want % x", data, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Package tools provides tool implementations used by the Gemini agent.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+const extensionToolTimeout = 60 * time.Second
+
+// ExtensionToolSpec describes an executable tool declared in an extension manifest.
+type ExtensionToolSpec struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+	Command     string
+	Args        []string
+	Dir         string // working directory for the executable (extension path)
+	Timeout     time.Duration
+}
+
+// ExtensionTool runs an extension-provided executable. Arguments are written
+// to stdin as a JSON object and the executable's stdout is parsed as JSON.
+type ExtensionTool struct {
+	spec ExtensionToolSpec
+}
+
+// NewExtensionTool returns the tool spec declares. A timeout in timeouts
+// for its name replaces the spec's.
+func NewExtensionTool(spec ExtensionToolSpec, timeouts Timeouts) *ExtensionTool {
+	if len(spec.Parameters) == 0 {
+		spec.Parameters = mustMarshalJSON(map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		})
+	}
+	if spec.Timeout <= 0 {
+		spec.Timeout = extensionToolTimeout
+	}
+	spec.Timeout = timeouts.get(spec.Name, spec.Timeout)
+	return &ExtensionTool{spec: spec}
+}
+
+func (t *ExtensionTool) Name() string { return t.spec.Name }
+
+func (t *ExtensionTool) Declaration() api.FunctionDecl {
+	return api.FunctionDecl{
+		Name:        t.spec.Name,
+		Description: t.spec.Description,
+		Parameters:  t.spec.Parameters,
+	}
+}
+
+func (t *ExtensionTool) Execute(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to encode arguments: %v", err)), nil
+	}
+
+	timeout := t.spec.Timeout
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, t.spec.Command, t.spec.Args...)
+	cmd.Dir = t.spec.Dir
+	cmd.Stdin = bytes.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = killWaitDelay
+
+	runErr := cmd.Run()
+	if cmdCtx.Err() == context.DeadlineExceeded {
+		content := map[string]interface{}{
+			"error":     fmt.Sprintf("tool %s timed out after %s and was killed; output and stderr hold what it wrote until then", t.spec.Name, timeout),
+			"timed_out": true,
+		}
+		if out := stdout.String(); out != "" {
+			content["output"] = truncateString(out, maxOutputBytes)
+		}
+		if msg := stderr.String(); msg != "" {
+			content["stderr"] = truncateString(msg, maxOutputBytes)
+		}
+		return &ToolResult{Content: content, IsError: true}, nil
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	var content map[string]interface{}
+	if len(out) > 0 && json.Unmarshal(out, &content) != nil {
+		// Not a JSON object: pass the raw text through.
+		content = map[string]interface{}{"output": truncateString(string(out), maxOutputBytes)}
+	}
+	if content == nil {
+		content = map[string]interface{}{}
+	}
+
+	if runErr != nil {
+		if _, ok := content["error"]; !ok {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				msg = runErr.Error()
+			}
+			content["error"] = truncateString(msg, maxOutputBytes)
+		}
+		return &ToolResult{Content: content, IsError: true}, nil
+	}
+
+	_, isErr := content["error"]
+	return &ToolResult{Content: content, IsError: isErr}, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptTool returns an extension tool that runs script with sh.
+func scriptTool(t *testing.T, script string, timeouts Timeouts) *ExtensionTool {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	return NewExtensionTool(ExtensionToolSpec{
+		Name:    "ext_tool",
+		Command: "sh",
+		Args:    []string{"-c", script},
+		Dir:     t.TempDir(),
+	}, timeouts)
+}
+
+func TestExtensionToolExecute(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    map[string]interface{}
+		isError bool
+	}{
+		{
+			name:   "arguments on stdin, JSON result",
+			script: "cat",
+			want:   map[string]interface{}{"city": "Kyoto", "days": float64(3)},
+		},
+		{
+			name:   "text output",
+			script: "echo 'not json'",
+			want:   map[string]interface{}{"output": "not json"},
+		},
+		{
+			name:    "error in the result",
+			script:  `echo '{"error":"no such city"}'`,
+			want:    map[string]interface{}{"error": "no such city"},
+			isError: true,
+		},
+		{
+			name:    "non-zero exit reports stderr",
+			script:  "echo partial; echo 'bad city' >&2; exit 3",
+			want:    map[string]interface{}{"output": "partial", "error": "bad city"},
+			isError: true,
+		},
+		{
+			name:    "non-zero exit keeps the tool's own error",
+			script:  `echo '{"error":"quota"}'; echo ignored >&2; exit 1`,
+			want:    map[string]interface{}{"error": "quota"},
+			isError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := scriptTool(t, tt.script, nil)
+			result, err := tool.Execute(context.Background(), map[string]interface{}{"city": "Kyoto", "days": 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.IsError != tt.isError || len(result.Content) != len(tt.want) {
+				t.Fatalf("result = %+v, want %v (error %v)", result, tt.want, tt.isError)
+			}
+			for k, v := range tt.want {
+				if result.Content[k] != v {
+					t.Errorf("%s = %#v, want %#v", k, result.Content[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestExtensionToolExitWithoutStderr(t *testing.T) {
+	result, err := scriptTool(t, "exit 7", nil).Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := result.Content["error"].(string); !result.IsError || !strings.Contains(msg, "exit status 7") {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestExtensionToolTimeout(t *testing.T) {
+	// The tool's entry in tools.timeouts replaces the default minute
+	tool := scriptTool(t, "echo started; exec sleep 10", Timeouts{"ext_tool": 100 * time.Millisecond})
+	begin := time.Now()
+	result, err := tool.Execute(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(begin) > 5*time.Second {
+		t.Errorf("the tool ran for %s", time.Since(begin))
+	}
+	if !result.IsError || result.Content["timed_out"] != true || result.Content["output"] != "started\n" {
+		t.Errorf("result = %+v", result)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// readTracker remembers each file as the model last saw it, so replace and
+// write_file can refuse to overwrite changes it has not read, such as the
+// user's concurrent edits. Files the model never read are not checked.
+type readTracker struct {
+	mu    sync.Mutex
+	files map[string]fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
+}
+
+func newReadTracker() *readTracker {
+	return &readTracker{files: map[string]fileState{}}
+}
+
+// record notes that the model has seen path with content hashing to sum.
+// A nil tracker does nothing.
+func (r *readTracker) record(path string, sum [sha256.Size]byte) {
+	if r == nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[path] = fileState{modTime: info.ModTime(), size: info.Size(), sum: sum}
+}
+
+// recordContent is record for content the caller has in memory.
+func (r *readTracker) recordContent(path string, data []byte) {
+	r.record(path, sha256.Sum256(data))
+}
+
+// seen reports whether the model read or wrote path this session.
+func (r *readTracker) seen(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.files[path]
+	return ok
+}
+
+// stale returns an error result if path changed since the model last read
+// it, or nil. Only a content change counts; touching a file does not.
+func (r *readTracker) stale(path string) *ToolResult {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	st, ok := r.files[path]
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || (info.ModTime().Equal(st.modTime) && info.Size() == st.size) {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || sha256.Sum256(data) == st.sum {
+		return nil
+	}
+	return errorResult(fmt.Sprintf("%s changed on disk since you last read it (edited by the user or a command). Read it again with read_file before modifying it, so their changes are not lost.", path))
+}
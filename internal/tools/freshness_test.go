@@ -0,0 +1,73 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEditRefusesStaleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nvar x = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := RegistryOptions{WorkDir: dir, reads: newReadTracker()}
+	read, edit := NewReadFileTool(opts), NewEditTool(opts)
+	ctx := context.Background()
+
+	if res, _ := read.Execute(ctx, map[string]interface{}{"file_path": "main.go"}); res.IsError {
+		t.Fatalf("read: %v", res.Content)
+	}
+	replace := map[string]interface{}{"file_path": "main.go", "old_string": "x = 1", "new_string": "x = 2"}
+	if res, _ := edit.Execute(ctx, replace); res.IsError {
+		t.Fatalf("edit after read: %v", res.Content)
+	}
+
+	// The user edits the file; the next edit must re-read first
+	os.WriteFile(path, []byte("package main\n\nvar x = 2 // keep\n"), 0644)
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	replace = map[string]interface{}{"file_path": "main.go", "old_string": "x = 2", "new_string": "x = 3"}
+	if res, _ := edit.Execute(ctx, replace); !res.IsError {
+		t.Fatal("edit of a file changed since the last read should fail")
+	}
+	read.Execute(ctx, map[string]interface{}{"file_path": "main.go"})
+	if res, _ := edit.Execute(ctx, replace); res.IsError {
+		t.Fatalf("edit after re-read: %v", res.Content)
+	}
+}
+
+func TestWriteFileProtectsUnreadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	os.WriteFile(path, []byte("keep me\n"), 0644)
+	opts := RegistryOptions{WorkDir: dir, reads: newReadTracker()}
+	read, write := NewReadFileTool(opts), NewWriteFileTool(opts)
+	ctx := context.Background()
+
+	args := map[string]interface{}{"file_path": "notes.txt", "content": "new\n"}
+	if res, _ := write.Execute(ctx, args); !res.IsError {
+		t.Fatal("writing an unread file should require overwrite")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep me\n" {
+		t.Fatalf("file was written: %q", data)
+	}
+
+	args["overwrite"] = true
+	if res, _ := write.Execute(ctx, args); res.IsError {
+		t.Fatalf("overwrite: %v", res.Content)
+	}
+
+	// A file that was read, or a new one, needs no flag
+	os.WriteFile(filepath.Join(dir, "other.txt"), []byte("x\n"), 0644)
+	read.Execute(ctx, map[string]interface{}{"file_path": "other.txt"})
+	for _, name := range []string{"other.txt", "new.txt"} {
+		if res, _ := write.Execute(ctx, map[string]interface{}{"file_path": name, "content": "y\n"}); res.IsError {
+			t.Errorf("write %s: %v", name, res.Content)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+type GetOutlineTool struct {
+	opts RegistryOptions
+}
+
+func NewGetOutlineTool(opts RegistryOptions) *GetOutlineTool {
+	return &GetOutlineTool{opts: opts}
+}
+
+func (t *GetOutlineTool) Name() string { return "get_outline" }
+
+func (t *GetOutlineTool) Declaration() api.FunctionDecl {
+	return api.FunctionDecl{
+		Name:        "get_outline",
+		Description: "Returns the functions, methods, classes and types declared in a file, with the lines each one spans (line to end_line, 1-based), instead of the file's content. Use it on large files to find the declarations you need, then read only those lines with read_file (offset = line - 1, limit = end_line - line + 1). Go files are parsed exactly; other languages are outlined with simple patterns and may miss unusual declarations.",
+		Parameters: mustMarshalJSON(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "The path to the file to outline.",
+				},
+			},
+			"required": []string{"file_path"},
+		}),
+	}
+}
+
+func (t *GetOutlineTool) Execute(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	filePath, _ := args["file_path"].(string)
+	if filePath == "" {
+		return errorResult("file_path is required"), nil
+	}
+	absPath := t.opts.resolvePath(filePath)
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to access file: %v", err)), nil
+	}
+	if info.IsDir() {
+		return errorResult("path is a directory, not a file. Use list_directory instead."), nil
+	}
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to read file: %v", err)), nil
+	}
+	content, _ := decodeText(data)
+
+	result := map[string]interface{}{
+		"file_path": absPath,
+		"lines":     strings.Count(strings.TrimSuffix(content, "\n"), "\n") + 1,
+	}
+	var symbols []symbol
+	if strings.EqualFold(filepath.Ext(absPath), ".go") {
+		if symbols, err = goOutline(absPath, data); err != nil {
+			// Fall back to patterns for a file that does not parse
+			result["parse_error"] = err.Error()
+			symbols = nil
+		}
+	}
+	if symbols == nil {
+		if _, ok := outlineLangs[strings.ToLower(filepath.Ext(absPath))]; !ok {
+			return errorResult(fmt.Sprintf("outlines are not supported for %s files; use read_file or grep_search instead", filepath.Ext(absPath))), nil
+		}
+		symbols = outline(absPath, content)
+	}
+	if symbols == nil {
+		symbols = []symbol{}
+	}
+	result["symbols"] = symbols
+	if len(symbols) >= maxOutlineSymbols {
+		result["message"] = fmt.Sprintf("Only the first %d declarations are listed; use grep_search to find others.", maxOutlineSymbols)
+	}
+	return &ToolResult{Content: result}, nil
+}
+
+// goOutline lists the top-level functions, methods and types of a Go file
+// from its syntax tree.
+func goOutline(path string, src []byte) ([]symbol, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	span := func(from, to token.Pos) (int, int) {
+		return fset.Position(from).Line, fset.Position(to).Line
+	}
+	symbols := []symbol{}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			s := symbol{Name: d.Name.Name, Kind: "function"}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				s.Kind, s.Parent = "method", receiverType(d.Recv.List[0].Type)
+			}
+			s.Line, s.EndLine = span(d.Pos(), d.End())
+			symbols = append(symbols, s)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				s := symbol{Name: ts.Name.Name, Kind: "type"}
+				if _, ok := ts.Type.(*ast.InterfaceType); ok {
+					s.Kind = "interface"
+				}
+				if d.Lparen.IsValid() {
+					s.Line, s.EndLine = span(ts.Pos(), ts.End())
+				} else {
+					s.Line, s.EndLine = span(d.Pos(), d.End())
+				}
+				symbols = append(symbols, s)
+			}
+		}
+		if len(symbols) >= maxOutlineSymbols {
+			break
+		}
+	}
+	return symbols, nil
+}
+
+// receiverType returns the type name of a method receiver such as
+// *Server or List[T].
+func receiverType(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
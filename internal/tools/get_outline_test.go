@@ -0,0 +1,102 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetOutline(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"list.go": `package list
+
+// List is a generic list.
+type List[T any] struct {
+	items []T
+}
+
+type (
+	ID     int
+	Lister interface{ List() }
+)
+
+func (l *List[T]) Push(v T) {
+	l.items = append(l.items, v)
+}
+
+func New[T any]() *List[T] { return &List[T]{} }
+`,
+		"shapes.py": `class Shape:
+    def area(
+        self,
+    ):
+        return 0
+
+    def name(self):
+        return "shape"
+
+
+def main():
+    print(Shape().area())
+`,
+		"app.ts": `export class App {
+  start(port: number): void {
+    if (port) {
+      listen("}", port);
+    }
+  }
+}
+
+export const handler = async (req: Request) => {
+  return 'ok';
+};
+`,
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	tool := NewGetOutlineTool(RegistryOptions{WorkDir: dir})
+	get := func(name string) []symbol {
+		t.Helper()
+		res, _ := tool.Execute(context.Background(), map[string]interface{}{"file_path": name})
+		if res.IsError {
+			t.Fatalf("get_outline(%s): %v", name, res.Content)
+		}
+		return res.Content["symbols"].([]symbol)
+	}
+
+	for name, want := range map[string][]symbol{
+		"list.go": {
+			{Name: "List", Kind: "type", Line: 4, EndLine: 6},
+			{Name: "ID", Kind: "type", Line: 9, EndLine: 9},
+			{Name: "Lister", Kind: "interface", Line: 10, EndLine: 10},
+			{Name: "Push", Kind: "method", Parent: "List", Line: 13, EndLine: 15},
+			{Name: "New", Kind: "function", Line: 17, EndLine: 17},
+		},
+		"shapes.py": {
+			{Name: "Shape", Kind: "class", Line: 1, EndLine: 8},
+			{Name: "area", Kind: "method", Parent: "Shape", Line: 2, EndLine: 5},
+			{Name: "name", Kind: "method", Parent: "Shape", Line: 7, EndLine: 8},
+			{Name: "main", Kind: "function", Line: 11, EndLine: 12},
+		},
+		"app.ts": {
+			{Name: "App", Kind: "class", Line: 1, EndLine: 7},
+			{Name: "start", Kind: "method", Parent: "App", Line: 2, EndLine: 6},
+			{Name: "handler", Kind: "function", Line: 9, EndLine: 11},
+		},
+	} {
+		if got := get(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s outline:\n got %+v\nwant %+v", name, got, want)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "data.bin"), []byte{0, 1}, 0644)
+	if res, _ := tool.Execute(context.Background(), map[string]interface{}{"file_path": "data.bin"}); !res.IsError {
+		t.Error("unsupported file type should be an error")
+	}
+}
@@ -56,9 +56,7 @@ func (t *GlobTool) Execute(ctx context.Context, args map[string]interface{}) (*T
 	}
 
 	dirPath := stringArg(args, "dir_path", t.opts.WorkDir)
-	if !filepath.IsAbs(dirPath) {
-		dirPath = filepath.Join(t.opts.WorkDir, dirPath)
-	}
+	dirPath = t.opts.resolvePath(dirPath)
 
 	fullPattern := filepath.Join(dirPath, pattern)
 
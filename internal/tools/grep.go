@@ -8,16 +8,41 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/k-sub1995/g/internal/api"
 )
 
-const maxGrepMatches = 100
+const (
+	maxGrepMatches  = 100
+	maxGrepFileSize = 1024 * 1024 // larger files are skipped
+)
+
+// grepMatch is one matching line.
+type grepMatch struct {
+	File    string
+	Line    int
+	Content string
+}
+
+// grepFile is a candidate file with its position in walk order.
+type grepFile struct {
+	index int
+	path  string
+}
+
+// grepResult holds the matches found in the file at index.
+type grepResult struct {
+	index   int
+	matches []grepMatch
+}
 
 type GrepTool struct {
 	opts RegistryOptions
@@ -32,7 +57,7 @@ func (t *GrepTool) Name() string { return "grep_search" }
 func (t *GrepTool) Declaration() api.FunctionDecl {
 	return api.FunctionDecl{
 		Name:        "grep_search",
-		Description: "Searches for a regular expression pattern within file contents. Returns matching lines with file paths and line numbers. Max 100 matches.",
+		Description: fmt.Sprintf("Searches for a regular expression pattern within file contents. Returns matching lines with file paths and line numbers. Max %d matches.", t.opts.Limits.grepMatches()),
 		Parameters: mustMarshalJSON(map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -66,75 +91,13 @@ func (t *GrepTool) Execute(ctx context.Context, args map[string]interface{}) (*T
 	}
 
 	dirPath := stringArg(args, "dir_path", t.opts.WorkDir)
-	if !filepath.IsAbs(dirPath) {
-		dirPath = filepath.Join(t.opts.WorkDir, dirPath)
-	}
+	dirPath = t.opts.resolvePath(dirPath)
 
 	include := stringArg(args, "include", "")
 
-	type match struct {
-		File    string `json:"file"`
-		Line    int    `json:"line"`
-		Content string `json:"content"`
-	}
-	var matches []match
-	truncated := false
-
-	err = filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // skip errors
-		}
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-		if info.IsDir() {
-			name := info.Name()
-			if name == ".git" || name == "node_modules" || name == ".svn" || name == "__pycache__" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Skip binary/large files
-		if info.Size() > 1024*1024 { // 1MB
-			return nil
-		}
-
-		// Apply include filter
-		if include != "" {
-			matched, _ := doublestar.Match(include, info.Name())
-			if !matched {
-				return nil
-			}
-		}
-
-		f, err := os.Open(path)
-		if err != nil {
-			return nil
-		}
-		defer f.Close()
-
-		scanner := bufio.NewScanner(f)
-		lineNum := 0
-		for scanner.Scan() {
-			lineNum++
-			line := scanner.Text()
-			if re.MatchString(line) {
-				matches = append(matches, match{
-					File:    path,
-					Line:    lineNum,
-					Content: truncateString(strings.TrimSpace(line), 200),
-				})
-				if len(matches) >= maxGrepMatches {
-					truncated = true
-					return fmt.Errorf("max matches reached")
-				}
-			}
-		}
-		return nil
-	})
-
-	if err != nil && err.Error() != "max matches reached" && ctx.Err() == nil {
+	limit := t.opts.Limits.grepMatches()
+	matches, truncated, err := grepTree(ctx, dirPath, re, include, limit)
+	if err != nil && ctx.Err() == nil {
 		return errorResult(fmt.Sprintf("search error: %v", err)), nil
 	}
 
@@ -150,12 +113,134 @@ func (t *GrepTool) Execute(ctx context.Context, args map[string]interface{}) (*T
 	}
 	if truncated {
 		result["truncated"] = true
-		result["message"] = fmt.Sprintf("Results limited to %d matches. Refine your search.", maxGrepMatches)
+		result["message"] = fmt.Sprintf("Results limited to %d matches. Refine your search.", limit)
 	}
 
 	return &ToolResult{Content: result}, nil
 }
 
+// grepTree searches the files under dir whose names match include. The walk
+// feeds candidate files to a pool of workers; their results are reassembled
+// in walk order, so the output is the same as a sequential search. The search
+// stops once limit matches are known.
+func grepTree(ctx context.Context, dir string, re *regexp.Regexp, include string, limit int) ([]grepMatch, bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := runtime.GOMAXPROCS(0)
+	files := make(chan grepFile, workers*4)
+	results := make(chan grepResult, workers*4)
+
+	var walkErr error
+	go func() {
+		defer close(files)
+		index := 0
+		walkErr = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // skip errors
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if name == ".git" || name == "node_modules" || name == ".svn" || name == "__pycache__" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if include != "" {
+				if matched, _ := doublestar.Match(include, d.Name()); !matched {
+					return nil
+				}
+			}
+			select {
+			case files <- grepFile{index: index, path: path}:
+				index++
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range files {
+				select {
+				case results <- grepResult{index: f.index, matches: grepOneFile(f.path, re, limit)}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Emit results in walk order as soon as every earlier file is done.
+	var matches []grepMatch
+	truncated := false
+	pending := map[int][]grepMatch{}
+	next := 0
+	for r := range results {
+		pending[r.index] = r.matches
+		for !truncated {
+			found, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			for _, m := range found {
+				matches = append(matches, m)
+				if len(matches) >= limit {
+					truncated = true
+					cancel()
+					break
+				}
+			}
+		}
+	}
+	if truncated {
+		return matches, true, nil
+	}
+	return matches, false, walkErr
+}
+
+// grepOneFile returns up to limit matching lines of the file.
+func grepOneFile(path string, re *regexp.Regexp, limit int) []grepMatch {
+	// Stat follows symlinks, so linked files are searched but pipes are not
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() || info.Size() > maxGrepFileSize {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var matches []grepMatch
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if re.MatchString(line) {
+			matches = append(matches, grepMatch{
+				File:    path,
+				Line:    lineNum,
+				Content: truncateString(strings.TrimSpace(line), 200),
+			})
+			if len(matches) >= limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -0,0 +1,53 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGrepOrderAndLimit(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 60; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("d%02d", i%7))
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf("needle %d\nhay\nneedle again\n", i)
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("f%02d.txt", i)), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "skip.go"), []byte("needle\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewGrepTool(RegistryOptions{WorkDir: dir})
+	var first string
+	for run := 0; run < 5; run++ {
+		res, err := tool.Execute(context.Background(), map[string]interface{}{"pattern": "needle", "include": "*.txt"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Content["count"] != maxGrepMatches || res.Content["truncated"] != true {
+			t.Fatalf("count = %v, truncated = %v", res.Content["count"], res.Content["truncated"])
+		}
+		out := res.Content["matches"].(string)
+		if strings.Contains(out, "skip.go") {
+			t.Error("include filter ignored")
+		}
+		if run == 0 {
+			first = out
+			if !strings.HasPrefix(out, filepath.Join(dir, "d00", "f00.txt")+":1: needle 0") {
+				t.Errorf("results not in walk order:\n%s", out[:200])
+			}
+		} else if out != first {
+			t.Fatal("results differ between runs")
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignoreFiles are read in every directory walked; .geminiignore uses the
+// same syntax as .gitignore.
+var ignoreFiles = []string{".gitignore", ".geminiignore"}
+
+type ignoreRule struct {
+	base    string // slash path of the ignore file's directory, relative to the walk root
+	pattern string
+	negate  bool
+	dirOnly bool
+	rooted  bool // contains a slash: matched against the path from base
+}
+
+// ignoreSet is the subset of .gitignore semantics tools need: comments,
+// negation, trailing-slash directory patterns, anchored patterns and **.
+// Later rules win, as in git.
+type ignoreSet struct {
+	rules []ignoreRule
+}
+
+// load adds the rules of the ignore files in dir, whose path relative to
+// the walk root is rel ("" or "." for the root).
+func (s *ignoreSet) load(dir, rel string) {
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+	for _, name := range ignoreFiles {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := strings.TrimRight(sc.Text(), " \t")
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			r := ignoreRule{base: rel}
+			if strings.HasPrefix(line, "!") {
+				r.negate = true
+				line = line[1:]
+			}
+			if strings.HasSuffix(line, "/") {
+				r.dirOnly = true
+				line = strings.TrimSuffix(line, "/")
+			}
+			r.rooted = strings.Contains(line, "/")
+			r.pattern = strings.TrimPrefix(line, "/")
+			if r.pattern != "" {
+				s.rules = append(s.rules, r)
+			}
+		}
+		f.Close()
+	}
+}
+
+// ignored reports whether rel (slash-separated, relative to the walk root)
+// is ignored.
+func (s *ignoreSet) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range s.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		p := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			p = rel[len(r.base)+1:]
+		}
+		var ok bool
+		if r.rooted {
+			ok, _ = doublestar.Match(r.pattern, p)
+		} else {
+			ok, _ = doublestar.Match(r.pattern, path.Base(p))
+		}
+		if ok {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
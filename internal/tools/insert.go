@@ -0,0 +1,162 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// InsertTool adds text to a file without resending its content.
+type InsertTool struct {
+	opts RegistryOptions
+}
+
+func NewInsertTool(opts RegistryOptions) *InsertTool {
+	return &InsertTool{opts: opts}
+}
+
+func (t *InsertTool) Name() string { return "insert" }
+
+func (t *InsertTool) Declaration() api.FunctionDecl {
+	return api.FunctionDecl{
+		Name:        "insert",
+		Description: "Adds text to a file without rewriting it: at the end (append), at the start (prepend), or after a given line (after_line). Cheaper than write_file for adding to large files. Append creates the file if it does not exist.",
+		Parameters: mustMarshalJSON(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "The path to the file to modify.",
+				},
+				"content": map[string]interface{}{
+					"type":        "string",
+					"description": "The text to insert. A trailing newline is added if missing.",
+				},
+				"position": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"append", "prepend", "after_line"},
+					"description": "Where to insert the text.",
+				},
+				"line": map[string]interface{}{
+					"type":        "number",
+					"description": "For after_line: the 1-based line to insert after (0 inserts at the start).",
+					"minimum":     0,
+				},
+			},
+			"required": []string{"file_path", "content", "position"},
+		}),
+	}
+}
+
+func (t *InsertTool) Execute(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	filePath, _ := args["file_path"].(string)
+	text, _ := args["content"].(string)
+	position, _ := args["position"].(string)
+
+	if filePath == "" {
+		return errorResult("file_path is required"), nil
+	}
+	if text == "" {
+		return errorResult("content is required"), nil
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	absPath := t.opts.resolvePath(filePath)
+	if t.opts.outsideSandbox(absPath) {
+		return errorResult(fmt.Sprintf("sandbox: cannot edit files outside working directory %s", t.opts.WorkDir)), nil
+	}
+	if res := t.opts.reads.stale(absPath); res != nil {
+		return res, nil
+	}
+
+	data, err := readForEdit(t.opts, absPath)
+	if err != nil && !(os.IsNotExist(err) && position == "append") {
+		return errorResult(fmt.Sprintf("failed to read file: %v", err)), nil
+	}
+	content, enc := decodeText(data)
+
+	var newContent string
+	var at int // 1-based line the text starts at
+	switch position {
+	case "append":
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		at = strings.Count(content, "\n") + 1
+		newContent = content + text
+	case "prepend":
+		at = 1
+		newContent = text + content
+	case "after_line":
+		line := intArg(args, "line", -1)
+		offset, ok := lineOffset(content, line)
+		if !ok {
+			total := strings.Count(content, "\n")
+			if content != "" && !strings.HasSuffix(content, "\n") {
+				total++
+			}
+			return errorResult(fmt.Sprintf("line must be between 0 and %d", total)), nil
+		}
+		if offset == len(content) && content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+			offset++
+		}
+		at = line + 1
+		newContent = content[:offset] + text + content[offset:]
+	default:
+		return errorResult("position must be append, prepend or after_line"), nil
+	}
+
+	out := []byte(newContent)
+	if enc != nil {
+		if out, err = enc.NewEncoder().Bytes(out); err != nil {
+			return errorResult(fmt.Sprintf("content cannot be written in the file's %s encoding: %v", enc.Name, err)), nil
+		}
+	}
+	proposed, err := writeEdit(ctx, t.opts, absPath, out)
+	if err != nil {
+		return editFailed(err)
+	}
+
+	lines := strings.Count(text, "\n")
+	message := fmt.Sprintf("Inserted %d line(s) at line %d of %s", lines, at, absPath)
+	if proposed {
+		message = proposedMessage(absPath)
+	}
+	return &ToolResult{
+		Content: map[string]interface{}{
+			"message":    message,
+			"file_path":  absPath,
+			"start_line": at,
+			"end_line":   at + lines - 1,
+		},
+	}, nil
+}
+
+// lineOffset returns the byte offset just past line n (1-based) of content;
+// n == 0 is the start. A last line without a newline ends at len(content).
+func lineOffset(content string, n int) (int, bool) {
+	if n < 0 {
+		return 0, false
+	}
+	offset := 0
+	for i := 0; i < n; i++ {
+		if offset == len(content) {
+			return 0, false
+		}
+		j := strings.IndexByte(content[offset:], '\n')
+		if j < 0 {
+			offset = len(content)
+			continue
+		}
+		offset += j + 1
+	}
+	return offset, true
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInsert(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list.txt")
+	insert := NewInsertTool(RegistryOptions{WorkDir: dir})
+	run := func(args map[string]interface{}) {
+		t.Helper()
+		args["file_path"] = "list.txt"
+		if res, _ := insert.Execute(context.Background(), args); res.IsError {
+			t.Fatalf("insert(%v): %v", args, res.Content)
+		}
+	}
+
+	run(map[string]interface{}{"content": "b", "position": "append"})
+	run(map[string]interface{}{"content": "d\n", "position": "append"})
+	run(map[string]interface{}{"content": "a", "position": "prepend"})
+	run(map[string]interface{}{"content": "c", "position": "after_line", "line": float64(2)})
+
+	data, _ := os.ReadFile(path)
+	if want := "a\nb\nc\nd\n"; string(data) != want {
+		t.Errorf("content = %q, want %q", data, want)
+	}
+
+	res, _ := insert.Execute(context.Background(), map[string]interface{}{"file_path": "list.txt", "content": "x", "position": "after_line", "line": float64(5)})
+	if !res.IsError {
+		t.Error("inserting past the end should fail")
+	}
+}
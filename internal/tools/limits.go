@@ -0,0 +1,61 @@
+// Package tools provides tool implementations used by the Gemini agent.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits caps how much output tools return to the model. Zero fields keep
+// the defaults.
+type Limits struct {
+	ShellOutputBytes int // per stream; default 100KB
+	ReadFileLines    int // default 2000
+	GrepMatches      int // default 100
+	WebFetchBytes    int // default 512KB
+}
+
+func (l Limits) shellOutputBytes() int { return orDefault(l.ShellOutputBytes, maxOutputBytes) }
+func (l Limits) readFileLines() int    { return orDefault(l.ReadFileLines, defaultReadLimit) }
+func (l Limits) grepMatches() int      { return orDefault(l.GrepMatches, maxGrepMatches) }
+func (l Limits) webFetchBytes() int    { return orDefault(l.WebFetchBytes, maxFetchBytes) }
+
+// Timeouts sets how long tools may run, by tool name. Tools without an
+// entry keep their default: run_shell_command 120s, web_fetch 30s and
+// extension tools 60s or their manifest's timeout.
+type Timeouts map[string]time.Duration
+
+func (t Timeouts) get(tool string, def time.Duration) time.Duration {
+	if d := t[tool]; d > 0 {
+		return d
+	}
+	return def
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+// TruncateMiddle shortens s to about max bytes by keeping its head and its
+// tail, so that errors at the end of a long log survive.
+func TruncateMiddle(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	head, tail := max/2, max-max/2
+	// Keep whole UTF-8 sequences on both sides of the cut
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	start := len(s) - tail
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return fmt.Sprintf("%s\n... [%d bytes truncated] ...\n%s", s[:head], start-head, s[start:])
+}
@@ -7,13 +7,22 @@ package tools
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
 
 	"github.com/k-sub1995/g/internal/api"
 )
 
+// maxListEntries bounds a listing, which matters for recursive ones.
+const maxListEntries = 1000
+
 type LsTool struct {
 	opts RegistryOptions
 }
@@ -27,7 +36,7 @@ func (t *LsTool) Name() string { return "list_directory" }
 func (t *LsTool) Declaration() api.FunctionDecl {
 	return api.FunctionDecl{
 		Name:        "list_directory",
-		Description: "Lists the names of files and subdirectories directly within a specified directory path.",
+		Description: fmt.Sprintf("Lists the files and subdirectories within a directory, directly or recursively, with their type, size and modification time, sorted by path. Files ignored by .gitignore or .geminiignore are skipped by default. At most %d entries are returned.", maxListEntries),
 		Parameters: mustMarshalJSON(map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -35,45 +44,168 @@ func (t *LsTool) Declaration() api.FunctionDecl {
 					"type":        "string",
 					"description": "The path to the directory to list.",
 				},
+				"recursive": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: also list the contents of subdirectories.",
+				},
+				"max_depth": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: with recursive, how many levels to descend (1 lists only dir_path itself). Unlimited by default.",
+					"minimum":     1,
+				},
+				"include": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: glob that listed files must match, e.g. '*.go' or 'src/**/*.ts' (relative to dir_path). Directories are still listed.",
+				},
+				"exclude": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: glob of files and directories to leave out, e.g. '*_test.go' or 'testdata'.",
+				},
+				"respect_gitignore": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: skip files ignored by .gitignore and .geminiignore. Defaults to true.",
+				},
 			},
 			"required": []string{"dir_path"},
 		}),
 	}
 }
 
+// listEntry is one file or directory in a listing.
+type listEntry struct {
+	Path     string // relative to dir_path; directories end in "/"
+	Type     string // "file", "dir" or "symlink"
+	Size     int64
+	Modified string
+}
+
 func (t *LsTool) Execute(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
 	dirPath, _ := args["dir_path"].(string)
 	if dirPath == "" {
 		dirPath = t.opts.WorkDir
 	}
-	if !filepath.IsAbs(dirPath) {
-		dirPath = filepath.Join(t.opts.WorkDir, dirPath)
+	dirPath = t.opts.resolvePath(dirPath)
+	recursive, _ := args["recursive"].(bool)
+	maxDepth := 1
+	if recursive {
+		maxDepth = intArg(args, "max_depth", 0)
+	}
+	include, _ := args["include"].(string)
+	exclude, _ := args["exclude"].(string)
+	respectIgnore := true
+	if v, ok := args["respect_gitignore"].(bool); ok {
+		respectIgnore = v
+	}
+	for _, g := range []string{include, exclude} {
+		if g != "" && !doublestar.ValidatePattern(g) {
+			return errorResult(fmt.Sprintf("invalid glob pattern %q", g)), nil
+		}
 	}
 
-	entries, err := os.ReadDir(dirPath)
+	info, err := os.Stat(dirPath)
 	if err != nil {
 		return errorResult(fmt.Sprintf("failed to list directory: %v", err)), nil
 	}
+	if !info.IsDir() {
+		return errorResult(fmt.Sprintf("failed to list directory: %s is not a directory", dirPath)), nil
+	}
 
-	var lines []string
-	for _, entry := range entries {
-		name := entry.Name()
-		if entry.IsDir() {
-			name += "/"
+	var ignores ignoreSet
+	var entries []listEntry
+	truncated := false
+	err = filepath.WalkDir(dirPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dirPath {
+				return err
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rel, _ := filepath.Rel(dirPath, p)
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			if respectIgnore {
+				ignores.load(p, "")
+			}
+			return nil
+		}
+		isDir := d.IsDir()
+		if isDir && d.Name() == ".git" {
+			return filepath.SkipDir
 		}
-		info, err := entry.Info()
-		if err == nil {
-			lines = append(lines, fmt.Sprintf("%s  (%d bytes)", name, info.Size()))
-		} else {
-			lines = append(lines, name)
+		if (respectIgnore && ignores.ignored(rel, isDir)) || (exclude != "" && globMatch(exclude, rel)) {
+			if isDir {
+				return filepath.SkipDir
+			}
+			return nil
 		}
+		if !isDir && include != "" && !globMatch(include, rel) {
+			return nil
+		}
+		if len(entries) == maxListEntries {
+			truncated = true
+			return filepath.SkipAll
+		}
+		entries = append(entries, newListEntry(rel, d))
+
+		depth := strings.Count(rel, "/") + 1
+		if isDir {
+			if maxDepth > 0 && depth >= maxDepth {
+				return filepath.SkipDir
+			}
+			if respectIgnore {
+				ignores.load(p, rel)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errorResult(fmt.Sprintf("failed to list directory: %v", err)), nil
 	}
 
-	return &ToolResult{
-		Content: map[string]interface{}{
-			"entries":  strings.Join(lines, "\n"),
-			"dir_path": dirPath,
-			"count":    len(entries),
-		},
-	}, nil
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	list := make([]interface{}, len(entries))
+	for i, e := range entries {
+		list[i] = map[string]interface{}{"path": e.Path, "type": e.Type, "size": e.Size, "modified": e.Modified}
+	}
+	result := map[string]interface{}{
+		"entries":  list,
+		"dir_path": dirPath,
+		"count":    len(entries),
+	}
+	if truncated {
+		result["truncated"] = true
+		result["message"] = fmt.Sprintf("Listing stopped at %d entries. Narrow it with max_depth, include or exclude, or list a subdirectory.", maxListEntries)
+	}
+	return &ToolResult{Content: result}, nil
+}
+
+func newListEntry(rel string, d fs.DirEntry) listEntry {
+	e := listEntry{Path: rel, Type: "file"}
+	switch {
+	case d.IsDir():
+		e.Path += "/"
+		e.Type = "dir"
+	case d.Type()&fs.ModeSymlink != 0:
+		e.Type = "symlink"
+	}
+	if info, err := d.Info(); err == nil {
+		if !d.IsDir() {
+			e.Size = info.Size()
+		}
+		e.Modified = info.ModTime().UTC().Format(time.RFC3339)
+	}
+	return e
+}
+
+// globMatch matches a pattern with a slash against the whole relative
+// path and one without against the base name, like .gitignore.
+func globMatch(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		rel = path.Base(rel)
+	}
+	ok, _ := doublestar.Match(pattern, rel)
+	return ok
 }
@@ -0,0 +1,63 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		".gitignore":          "build/\n*.log\n!keep.log\n",
+		"main.go":             "package main",
+		"main_test.go":        "package main",
+		"debug.log":           "x",
+		"keep.log":            "x",
+		"build/out.bin":       "x",
+		"pkg/util/util.go":    "package util",
+		"pkg/util/.gitignore": "gen.go\n",
+		"pkg/util/gen.go":     "package util",
+		"pkg/util/deep/a.go":  "package deep",
+		"docs/readme.md":      "# docs",
+	} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ls := NewLsTool(RegistryOptions{WorkDir: dir})
+	list := func(args map[string]interface{}) string {
+		t.Helper()
+		args["dir_path"] = "."
+		res, _ := ls.Execute(context.Background(), args)
+		if res.IsError {
+			t.Fatalf("list_directory(%v): %v", args, res.Content)
+		}
+		var paths []string
+		for _, e := range res.Content["entries"].([]interface{}) {
+			paths = append(paths, e.(map[string]interface{})["path"].(string))
+		}
+		return strings.Join(paths, " ")
+	}
+
+	if got, want := list(map[string]interface{}{}), ".gitignore docs/ keep.log main.go main_test.go pkg/"; got != want {
+		t.Errorf("flat listing = %q, want %q", got, want)
+	}
+	if got, want := list(map[string]interface{}{"recursive": true, "include": "*.go", "exclude": "*_test.go"}),
+		"docs/ main.go pkg/ pkg/util/ pkg/util/deep/ pkg/util/deep/a.go pkg/util/util.go"; got != want {
+		t.Errorf("recursive listing = %q, want %q", got, want)
+	}
+	if got, want := list(map[string]interface{}{"recursive": true, "max_depth": float64(2), "include": "**/*.go"}),
+		"docs/ main.go main_test.go pkg/ pkg/util/"; got != want {
+		t.Errorf("max_depth listing = %q, want %q", got, want)
+	}
+	if got := list(map[string]interface{}{"respect_gitignore": false}); !strings.Contains(got, "build/") || !strings.Contains(got, "debug.log") {
+		t.Errorf("listing without gitignore = %q", got)
+	}
+}
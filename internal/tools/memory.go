@@ -6,11 +6,15 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/memory"
 )
 
 type MemoryTool struct {
@@ -26,13 +30,18 @@ func (t *MemoryTool) Name() string { return "save_memory" }
 func (t *MemoryTool) Declaration() api.FunctionDecl {
 	return api.FunctionDecl{
 		Name:        "save_memory",
-		Description: "Saves important information to a persistent memory file (~/.gemini/GEMINI.md) for future sessions. Use this to remember user preferences, project context, or important facts.",
+		Description: fmt.Sprintf("Saves important information to a persistent memory file (%s) for future sessions. Use this to remember user preferences, project context, or important facts. Each fact is saved as one dated line; keep it short and self-contained.", memoryFileName()),
 		Parameters: mustMarshalJSON(map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
 				"content": map[string]interface{}{
 					"type":        "string",
-					"description": "The content to save to memory.",
+					"description": "The fact to remember, as one short sentence.",
+				},
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Optional: Short topic tags such as \"preferences\" or a project name, so the user can review or clear related memories together.",
 				},
 			},
 			"required": []string{"content"},
@@ -42,27 +51,23 @@ func (t *MemoryTool) Declaration() api.FunctionDecl {
 
 func (t *MemoryTool) Execute(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
 	content, _ := args["content"].(string)
-	if content == "" {
+	if strings.TrimSpace(content) == "" {
 		return errorResult("content is required"), nil
 	}
-
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return errorResult(fmt.Sprintf("failed to get home directory: %v", err)), nil
+	var tags []string
+	if raw, ok := args["tags"].([]interface{}); ok {
+		for _, v := range raw {
+			if s, ok := v.(string); ok {
+				tags = append(tags, s)
+			}
+		}
 	}
 
-	memPath := filepath.Join(home, ".gemini", "GEMINI.md")
-	if err := os.MkdirAll(filepath.Dir(memPath), 0755); err != nil {
-		return errorResult(fmt.Sprintf("failed to create directory: %v", err)), nil
+	memPath, err := memory.Add(content, tags, time.Now())
+	if errors.Is(err, memory.ErrFull) {
+		return errorResult(fmt.Sprintf("%v. Nothing was saved. Tell the user; they can review and remove memories with 'g memory show' and 'g memory clear'.", err)), nil
 	}
-
-	f, err := os.OpenFile(memPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return errorResult(fmt.Sprintf("failed to open memory file: %v", err)), nil
-	}
-	defer f.Close()
-
-	if _, err := fmt.Fprintf(f, "\n%s\n", content); err != nil {
 		return errorResult(fmt.Sprintf("failed to write memory: %v", err)), nil
 	}
 
@@ -73,3 +78,18 @@ func (t *MemoryTool) Execute(ctx context.Context, args map[string]interface{}) (
 		},
 	}, nil
 }
+
+// memoryFileName shows the memory file in the tool description, with the
+// home directory as ~.
+func memoryFileName() string {
+	path, err := memory.UserFile()
+	if err != nil {
+		return "~/.gemini/GEMINI.md"
+	}
+	if home, _ := os.UserHomeDir(); home != "" {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(filepath.Join("~", rel))
+		}
+	}
+	return path
+}
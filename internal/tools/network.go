@@ -0,0 +1,67 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// NetworkPolicy restricts which hosts network tools may reach. The zero
+// value allows everything.
+type NetworkPolicy struct {
+	Disabled     bool     // remove all network tools
+	AllowedHosts []string // if non-empty, only these hosts (and their subdomains)
+}
+
+// Restricted reports whether the policy limits egress at all.
+func (p NetworkPolicy) Restricted() bool {
+	return p.Disabled || len(p.AllowedHosts) > 0
+}
+
+// AllowsHost reports whether host may be contacted. Entries match the host
+// itself and its subdomains; a leading "*." is accepted for clarity. IP
+// addresses match only the same address.
+func (p NetworkPolicy) AllowsHost(host string) bool {
+	if p.Disabled {
+		return false
+	}
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+	ip := net.ParseIP(host)
+	for _, entry := range p.AllowedHosts {
+		entry = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(strings.Trim(entry, "[]"), "*"), "."))
+		if ip != nil {
+			if ip.Equal(net.ParseIP(entry)) {
+				return true
+			}
+			continue
+		}
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckURL returns an error if rawURL points to a host outside the policy.
+func (p NetworkPolicy) CheckURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %v", err)
+	}
+	if !p.AllowsHost(u.Host) {
+		if p.Disabled {
+			return fmt.Errorf("network access is disabled by settings (network.disabled)")
+		}
+		return fmt.Errorf("host %s is not in network.allowedHosts", u.Hostname())
+	}
+	return nil
+}
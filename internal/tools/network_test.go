@@ -0,0 +1,94 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import "testing"
+
+func TestNetworkPolicyAllowsHost(t *testing.T) {
+	allowed := []string{"example.com", "*.github.com", ".Docs.Go.Dev", "10.0.0.1", "::1", "[fe80::1]"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		// exact
+		{"example.com", true},
+		{"EXAMPLE.com", true},
+		{"example.com.", true},
+		{"example.com:8443", true},
+		{"example.org", false},
+		// subdomains, with or without a wildcard in the entry
+		{"api.example.com", true},
+		{"a.b.example.com", true},
+		{"github.com", true},
+		{"raw.github.com", true},
+		{"pkg.docs.go.dev", true},
+		{"go.dev", false},
+		// a suffix that is not a subdomain
+		{"evilexample.com", false},
+		{"example.com.evil.net", false},
+		{"notgithub.com", false},
+		// IP literals match only the same address
+		{"10.0.0.1", true},
+		{"10.0.0.1:80", true},
+		{"110.0.0.1", false},
+		{"10.0.0.10", false},
+		{"[::1]", true},
+		{"[::1]:8080", true},
+		{"[0:0::1]", true},
+		{"fe80::1", true},
+		{"[::2]", false},
+		{"127.0.0.1", false},
+	}
+	p := NetworkPolicy{AllowedHosts: allowed}
+	for _, tt := range tests {
+		if got := p.AllowsHost(tt.host); got != tt.want {
+			t.Errorf("AllowsHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestNetworkPolicyDisabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy NetworkPolicy
+		host   string
+		want   bool
+	}{
+		{"no policy", NetworkPolicy{}, "example.com", true},
+		{"disabled", NetworkPolicy{Disabled: true}, "example.com", false},
+		{"disabled wins over allowed", NetworkPolicy{Disabled: true, AllowedHosts: []string{"example.com"}}, "example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.AllowsHost(tt.host); got != tt.want {
+				t.Errorf("AllowsHost(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+			if got := tt.policy.Restricted(); got == tt.want {
+				t.Errorf("Restricted() = %v", got)
+			}
+		})
+	}
+}
+
+func TestNetworkPolicyCheckURL(t *testing.T) {
+	p := NetworkPolicy{AllowedHosts: []string{"example.com"}}
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://example.com/path", false},
+		{"https://sub.example.com:8443/x?y=z", false},
+		{"https://example.com@evil.net/", true}, // userinfo is not the host
+		{"https://evil.net/?u=https://example.com", true},
+		{"http://[::1]/", true},
+		{"://bad", true},
+	}
+	for _, tt := range tests {
+		if err := p.CheckURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("CheckURL(%q) = %v, want error %v", tt.url, err, tt.wantErr)
+		}
+	}
+	if err := (NetworkPolicy{Disabled: true}).CheckURL("https://example.com"); err == nil {
+		t.Error("CheckURL allowed a URL with the network disabled")
+	}
+}
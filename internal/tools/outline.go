@@ -0,0 +1,308 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxOutlineSymbols bounds the outline of one file.
+const maxOutlineSymbols = 200
+
+// symbol is a declaration in a file's outline.
+type symbol struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`               // function, method, class, type, ...
+	Parent  string `json:"parent,omitempty"`   // the enclosing class, or a Go method's receiver type
+	Line    int    `json:"line"`               // 1-based
+	EndLine int    `json:"end_line,omitempty"` // last line, when it could be found
+}
+
+// outlineRule finds one kind of declaration in a line. The pattern's
+// "name" group is the symbol's name; an indented match is reported as
+// nested kind when nested is set, e.g. a Python def inside a class.
+type outlineRule struct {
+	re     *regexp.Regexp
+	kind   string
+	nested string
+}
+
+func rule(pattern, kind, nested string) outlineRule {
+	return outlineRule{re: regexp.MustCompile(pattern), kind: kind, nested: nested}
+}
+
+var (
+	goRules = []outlineRule{
+		rule(`^func\s+\([^)]*\)\s*(?P<name>\w+)`, "method", ""),
+		rule(`^func\s+(?P<name>\w+)`, "function", ""),
+		rule(`^type\s+(?P<name>\w+)\s+interface\b`, "interface", ""),
+		rule(`^type\s+(?P<name>\w+)`, "type", ""),
+	}
+	pythonRules = []outlineRule{
+		rule(`^\s*class\s+(?P<name>\w+)`, "class", ""),
+		rule(`^\s*(async\s+)?def\s+(?P<name>\w+)`, "function", "method"),
+	}
+	jsRules = []outlineRule{
+		rule(`^\s*(export\s+)?(default\s+)?(abstract\s+)?class\s+(?P<name>\w+)`, "class", ""),
+		rule(`^\s*(export\s+)?(default\s+)?(async\s+)?function\s*\*?\s*(?P<name>\w+)`, "function", ""),
+		rule(`^\s*(export\s+)?interface\s+(?P<name>\w+)`, "interface", ""),
+		rule(`^\s*(export\s+)?(declare\s+)?(const\s+)?enum\s+(?P<name>\w+)`, "enum", ""),
+		rule(`^\s*(export\s+)?type\s+(?P<name>\w+)\s*(<[^=]*>)?\s*=`, "type", ""),
+		rule(`^\s*(export\s+)?(const|let|var)\s+(?P<name>\w+)\s*(:[^=]+)?=\s*(async\s+)?(function\b|(\([^)]*\)|\w+)\s*(:[^=]+)?=>)`, "function", ""),
+		rule(`^\s+((public|private|protected|static|readonly|async|override|get|set)\s+)*(?P<name>\w+)\s*(<[^>]*>)?\([^;]*\)\s*(:[^{;]+)?\{\s*$`, "method", ""),
+	}
+	rustRules = []outlineRule{
+		rule(`^\s*(pub(\([^)]*\))?\s+)?(const\s+)?(async\s+)?(unsafe\s+)?(extern\s+"\w+"\s+)?fn\s+(?P<name>\w+)`, "function", "method"),
+		rule(`^\s*(pub(\([^)]*\))?\s+)?struct\s+(?P<name>\w+)`, "struct", ""),
+		rule(`^\s*(pub(\([^)]*\))?\s+)?enum\s+(?P<name>\w+)`, "enum", ""),
+		rule(`^\s*(pub(\([^)]*\))?\s+)?(unsafe\s+)?trait\s+(?P<name>\w+)`, "trait", ""),
+		rule(`^\s*(pub(\([^)]*\))?\s+)?mod\s+(?P<name>\w+)`, "module", ""),
+		rule(`^\s*(unsafe\s+)?impl\b(<[^>]*>)?\s+(?P<name>[\w:]+(\s+for\s+[\w:]+)?)`, "impl", ""),
+	}
+	javaRules = []outlineRule{
+		rule(`^\s*(@\w+\s+)*((public|private|protected|internal|static|final|abstract|sealed|open|data|inner|partial)\s+)*(class|record|object)\s+(?P<name>\w+)`, "class", ""),
+		rule(`^\s*((public|private|protected|internal|static|sealed|partial)\s+)*(@)?interface\s+(?P<name>\w+)`, "interface", ""),
+		rule(`^\s*((public|private|protected|internal|static)\s+)*enum\s+(class\s+)?(?P<name>\w+)`, "enum", ""),
+		rule(`^\s*((public|private|protected|internal|override|open|suspend|inline)\s+)*fun\s+(<[^>]*>\s*)?([\w.]+\.)?(?P<name>\w+)`, "function", "method"),
+		rule(`^\s+((public|private|protected|internal|static|final|abstract|synchronized|override|virtual|async|native)\s+)+[\w<>\[\],.? ]+\s+(?P<name>\w+)\s*\(`, "method", ""),
+	}
+	rubyRules = []outlineRule{
+		rule(`^\s*class\s+(?P<name>[\w:]+)`, "class", ""),
+		rule(`^\s*module\s+(?P<name>[\w:]+)`, "module", ""),
+		rule(`^\s*def\s+(?P<name>(self\.)?\w+[?!=]?)`, "method", ""),
+	}
+	cRules = []outlineRule{
+		rule(`^(typedef\s+)?(struct|union)\s+(?P<name>\w+)\s*\{?\s*$`, "struct", ""),
+		rule(`^(typedef\s+)?enum\s+(class\s+)?(?P<name>\w+)`, "enum", ""),
+		rule(`^(template\s*<[^>]*>\s*)?class\s+(?P<name>\w+)`, "class", ""),
+		rule(`^namespace\s+(?P<name>[\w:]+)`, "namespace", ""),
+		rule(`^[A-Za-z_][\w\s\*&:<>,]*?[\s\*&](?P<name>[A-Za-z_][\w:~]*)\s*\([^;]*$`, "function", ""),
+	}
+	shellRules = []outlineRule{
+		rule(`^\s*(function\s+)?(?P<name>[\w-]+)\s*\(\)\s*\{?`, "function", ""),
+		rule(`^\s*function\s+(?P<name>[\w-]+)`, "function", ""),
+	}
+	markdownRules = []outlineRule{
+		rule(`^#{1,6}\s+(?P<name>.+?)\s*#*$`, "heading", ""),
+	}
+)
+
+// outlineLang is how declarations are found in one language and where
+// their bodies end.
+type outlineLang struct {
+	rules []outlineRule
+	end   func(lines []string, i int) int // 0-based line of the last line of the declaration at i, or -1
+}
+
+var (
+	braceLang    = func(rules []outlineRule) outlineLang { return outlineLang{rules, braceEnd} }
+	outlineLangs = map[string]outlineLang{
+		".go":    braceLang(goRules),
+		".py":    {pythonRules, indentEnd},
+		".pyi":   {pythonRules, indentEnd},
+		".js":    braceLang(jsRules),
+		".jsx":   braceLang(jsRules),
+		".mjs":   braceLang(jsRules),
+		".cjs":   braceLang(jsRules),
+		".ts":    braceLang(jsRules),
+		".tsx":   braceLang(jsRules),
+		".mts":   braceLang(jsRules),
+		".rs":    braceLang(rustRules),
+		".java":  braceLang(javaRules),
+		".kt":    braceLang(javaRules),
+		".kts":   braceLang(javaRules),
+		".cs":    braceLang(javaRules),
+		".scala": braceLang(javaRules),
+		".rb":    {rubyRules, rubyEnd},
+		".c":     braceLang(cRules),
+		".h":     braceLang(cRules),
+		".cc":    braceLang(cRules),
+		".cpp":   braceLang(cRules),
+		".cxx":   braceLang(cRules),
+		".hpp":   braceLang(cRules),
+		".sh":    braceLang(shellRules),
+		".bash":  braceLang(shellRules),
+		".zsh":   braceLang(shellRules),
+		".md":    {markdownRules, headingEnd},
+	}
+)
+
+// keywords start lines that look like function headers but are not.
+var keywords = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true, "else": true, "sizeof": true}
+
+// outline lists the declarations in content by matching each line against
+// simple per-language patterns chosen by path's extension, with the line
+// their body ends on and the declaration enclosing them. It is a cheap
+// approximation: it can miss or misname declarations written unusually.
+// Unknown languages have no outline.
+func outline(path, content string) []symbol {
+	ext := strings.ToLower(filepath.Ext(path))
+	lang, ok := outlineLangs[ext]
+	if !ok {
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	var symbols []symbol
+	inFence := false // in a Markdown code block
+	for i, line := range lines {
+		if ext == ".md" && strings.HasPrefix(line, "```") {
+			inFence = !inFence
+		}
+		if inFence {
+			continue
+		}
+		for _, r := range lang.rules {
+			m := r.re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			name := m[r.re.SubexpIndex("name")]
+			if keywords[name] {
+				break
+			}
+			kind := r.kind
+			if r.nested != "" && len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+				kind = r.nested
+			}
+			symbols = append(symbols, symbol{Name: name, Kind: kind, Line: i + 1, EndLine: lang.end(lines, i) + 1})
+			break
+		}
+		if len(symbols) == maxOutlineSymbols {
+			break
+		}
+	}
+	setParents(symbols)
+	return symbols
+}
+
+// setParents sets each symbol's parent to the closest symbol before it
+// whose range contains it.
+func setParents(symbols []symbol) {
+	for i := range symbols {
+		s := &symbols[i]
+		if s.Parent != "" || s.EndLine == 0 {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if p := symbols[j]; p.EndLine >= s.EndLine && p.Line < s.Line {
+				s.Parent = p.Name
+				break
+			}
+		}
+	}
+}
+
+// maxBodyLines bounds the search for the end of a declaration.
+const maxBodyLines = 5000
+
+// braceEnd finds where the braces opened on or after line i close again. A
+// declaration ending in ";" or a blank line before any brace has no body.
+func braceEnd(lines []string, i int) int {
+	depth, opened := 0, false
+	for j := i; j < len(lines) && j < i+maxBodyLines; j++ {
+		var quote rune
+		line := lines[j]
+	scan:
+		for k, c := range line {
+			switch {
+			case quote != 0:
+				if c == quote && (k == 0 || line[k-1] != '\\') {
+					quote = 0
+				}
+			case c == '"' || c == '`':
+				quote = c
+			case c == '\'' && strings.ContainsRune(line[k+1:], '\''):
+				// Not a Rust lifetime
+				quote = c
+			case c == '/' && strings.HasPrefix(line[k:], "//"):
+				break scan
+			case c == '{':
+				depth++
+				opened = true
+			case c == '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return j
+		}
+		switch trimmed := strings.TrimSpace(line); {
+		case !opened && strings.HasSuffix(trimmed, ";"):
+			return j
+		case !opened && trimmed == "":
+			// No body, as in "type ID int"
+			return j - 1
+		}
+	}
+	return -1
+}
+
+// indentEnd finds the last line indented deeper than line i, for Python.
+// Lines closing a bracket continue a multi-line signature.
+func indentEnd(lines []string, i int) int {
+	base, end := indentOf(lines[i]), i
+	for j := i + 1; j < len(lines) && j < i+maxBodyLines; j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if trimmed == "" {
+			continue
+		}
+		if indentOf(lines[j]) <= base && !strings.HasPrefix(trimmed, ")") && !strings.HasPrefix(trimmed, "]") {
+			break
+		}
+		end = j
+	}
+	return end
+}
+
+// rubyEnd is indentEnd including the "end" that closes the block.
+func rubyEnd(lines []string, i int) int {
+	end := indentEnd(lines, i)
+	for j := end + 1; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if strings.TrimSpace(lines[j]) == "end" && indentOf(lines[j]) == indentOf(lines[i]) {
+			return j
+		}
+		break
+	}
+	return end
+}
+
+// headingEnd finds the line before the next Markdown heading of the same or
+// a higher level.
+func headingEnd(lines []string, i int) int {
+	level := len(lines[i]) - len(strings.TrimLeft(lines[i], "#"))
+	inFence := false
+	for j := i + 1; j < len(lines); j++ {
+		if strings.HasPrefix(lines[j], "```") {
+			inFence = !inFence
+		}
+		if inFence {
+			continue
+		}
+		if l := len(lines[j]) - len(strings.TrimLeft(lines[j], "#")); l > 0 && l <= level && markdownRules[0].re.MatchString(lines[j]) {
+			return j - 1
+		}
+	}
+	return len(lines) - 1
+}
+
+// indentOf returns the width of line's leading whitespace, a tab counting
+// as four spaces.
+func indentOf(line string) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
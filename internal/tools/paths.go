@@ -0,0 +1,91 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// maxLinkDepth bounds symlink chains, so a link loop cannot recurse forever.
+const maxLinkDepth = 40
+
+// resolvePath turns a path argument into a clean absolute path. "~" expands
+// to the home directory, relative paths are taken from the working
+// directory, and on Windows forward slashes and Git Bash style drive paths
+// (/c/work) are accepted.
+func (o RegistryOptions) resolvePath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = home + p[1:]
+		}
+	}
+	if runtime.GOOS == "windows" {
+		p = windowsPath(p)
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(o.WorkDir, p)
+	}
+	return filepath.Clean(p)
+}
+
+// windowsPath rewrites /c/work to C:/work and converts slashes.
+func windowsPath(p string) string {
+	if len(p) >= 2 && p[0] == '/' && isLetter(p[1]) && (len(p) == 2 || p[2] == '/') {
+		p = strings.ToUpper(p[1:2]) + ":/" + strings.TrimPrefix(p[2:], "/")
+	}
+	return filepath.FromSlash(p)
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// outsideSandbox reports whether writing path is forbidden: with Sandbox
+// set, the file must be inside the working directory after following
+// symlinks, so a link in the project cannot lead writes out of it.
+func (o RegistryOptions) outsideSandbox(path string) bool {
+	return o.Sandbox && !isPathUnder(path, o.WorkDir)
+}
+
+// isPathUnder reports whether path is base or inside it once symlinks in
+// both are resolved.
+func isPathUnder(path, base string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(realPath(absBase, 0), realPath(absPath, 0))
+	if err != nil {
+		return false
+	}
+	return !filepath.IsAbs(rel) && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// realPath resolves the symlinks in p. For a path that does not exist yet
+// it resolves the deepest existing parent, and a dangling link resolves to
+// where writing through it would create the file.
+func realPath(p string, depth int) string {
+	if r, err := filepath.EvalSymlinks(p); err == nil {
+		return r
+	}
+	if depth < maxLinkDepth {
+		if target, err := os.Readlink(p); err == nil {
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(p), target)
+			}
+			return realPath(target, depth+1)
+		}
+	}
+	parent := filepath.Dir(p)
+	if parent == p {
+		return p
+	}
+	return filepath.Join(realPath(parent, depth), filepath.Base(p))
+}
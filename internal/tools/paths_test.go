@@ -0,0 +1,58 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	o := RegistryOptions{WorkDir: "/work/project"}
+	tests := []struct{ in, want string }{
+		{"main.go", "/work/project/main.go"},
+		{"./src/../main.go", "/work/project/main.go"},
+		{"/etc/hosts", "/etc/hosts"},
+		{"~", home},
+		{"~/notes.txt", filepath.Join(home, "notes.txt")},
+		{"~user/x", "/work/project/~user/x"},
+	}
+	for _, tt := range tests {
+		if got := o.resolvePath(tt.in); got != tt.want {
+			t.Errorf("resolvePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSandboxFollowsSymlinks(t *testing.T) {
+	work, outside := t.TempDir(), t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(work, "escape")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	// A dangling link whose target would be created outside
+	os.Symlink(filepath.Join(outside, "new.txt"), filepath.Join(work, "dangling"))
+
+	o := RegistryOptions{WorkDir: work, Sandbox: true}
+	for _, p := range []string{"escape/x.txt", "dangling", "../x.txt"} {
+		if !o.outsideSandbox(o.resolvePath(p)) {
+			t.Errorf("%s: write allowed outside the sandbox", p)
+		}
+	}
+	for _, p := range []string{"x.txt", "..x/y.txt", "new/dir/z.txt"} {
+		if o.outsideSandbox(o.resolvePath(p)) {
+			t.Errorf("%s: write refused inside the sandbox", p)
+		}
+	}
+
+	res, _ := NewWriteFileTool(o).Execute(context.Background(), map[string]interface{}{"file_path": "escape/x.txt", "content": "x"})
+	if !res.IsError || !strings.Contains(res.Content["error"].(string), "sandbox") {
+		t.Errorf("write through symlink: %v", res.Content)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "x.txt")); !os.IsNotExist(err) {
+		t.Error("file was written outside the sandbox")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// EditProposal is a file change offered for review instead of written.
+// OldContent is the file on disk, nil when the edit creates it; NewContent
+// includes every change proposed to the file so far.
+type EditProposal struct {
+	FilePath   string
+	OldContent *string
+	NewContent string
+}
+
+// ProposeEditFunc receives a proposed edit, typically to hand it to an
+// editor that shows and applies it.
+type ProposeEditFunc func(ctx context.Context, p EditProposal) error
+
+// proposalSet holds the content proposed for each file, so that later edits
+// to a file build on the earlier proposals.
+type proposalSet struct {
+	mu      sync.Mutex
+	pending map[string][]byte
+}
+
+func newProposalSet() *proposalSet {
+	return &proposalSet{pending: map[string][]byte{}}
+}
+
+// readForEdit returns the content the edit tools work on: the proposed
+// content when edits are proposed, otherwise the file.
+func readForEdit(opts RegistryOptions, path string) ([]byte, error) {
+	if opts.proposals != nil {
+		opts.proposals.mu.Lock()
+		data, ok := opts.proposals.pending[path]
+		opts.proposals.mu.Unlock()
+		if ok {
+			return data, nil
+		}
+	}
+	return os.ReadFile(path)
+}
+
+// writeEdit writes data to path, or hands it to opts.ProposeEdit. Files in
+// the artifacts directory are deliverables rather than code changes and are
+// always written, without counting against opts.Changes. proposed reports
+// which happened; errors are ready to show to the model, except those
+// wrapping ErrChangeLimit.
+func writeEdit(ctx context.Context, opts RegistryOptions, path string, data []byte) (proposed bool, err error) {
+	artifact := opts.ArtifactsDir != "" && isPathUnder(path, opts.resolvePath(opts.ArtifactsDir))
+	if opts.ProposeEdit == nil || artifact {
+		if opts.Changes != nil && !artifact {
+			old, _ := os.ReadFile(path)
+			if err := opts.Changes.charge(ctx, opts.Ask, path, changedBytes(old, data)); err != nil {
+				return false, err
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return false, fmt.Errorf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return false, fmt.Errorf("failed to write file: %v", err)
+		}
+		opts.reads.recordContent(path, data)
+		return false, nil
+	}
+
+	p := EditProposal{FilePath: path}
+	if old, err := os.ReadFile(path); err == nil {
+		text, _ := decodeText(old)
+		p.OldContent = &text
+	}
+	p.NewContent, _ = decodeText(data)
+	if err := opts.ProposeEdit(ctx, p); err != nil {
+		return false, fmt.Errorf("failed to propose edit: %v", err)
+	}
+	opts.proposals.mu.Lock()
+	opts.proposals.pending[path] = data
+	opts.proposals.mu.Unlock()
+	return true, nil
+}
+
+// proposedMessage tells the model that an edit awaits review.
+func proposedMessage(path string) string {
+	return fmt.Sprintf("Proposed the change to %s for review; it is not on disk yet. Further edits to this file build on the proposal.", path)
+}
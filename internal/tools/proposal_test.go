@@ -0,0 +1,58 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProposeEdits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("a := 1\nb := 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []EditProposal
+	r := NewRegistry(RegistryOptions{WorkDir: dir, ArtifactsDir: ".g/artifacts/s1", ProposeEdit: func(ctx context.Context, p EditProposal) error {
+		got = append(got, p)
+		return nil
+	}})
+	exec := func(name string, args map[string]interface{}) {
+		t.Helper()
+		tool, _ := r.Get(name)
+		res, err := tool.Execute(context.Background(), args)
+		if err != nil || res.IsError {
+			t.Fatalf("%s: %v %v", name, err, res.Content)
+		}
+	}
+	exec("replace", map[string]interface{}{"file_path": "main.go", "old_string": "a := 1", "new_string": "a := 10"})
+	// The second edit builds on the first proposal
+	exec("replace", map[string]interface{}{"file_path": "main.go", "old_string": "b := 2", "new_string": "b := 20"})
+	exec("write_file", map[string]interface{}{"file_path": "new.go", "content": "package main\n"})
+	// Artifacts are written, not proposed
+	exec("write_file", map[string]interface{}{"file_path": ".g/artifacts/s1/report.md", "content": "# Report\n"})
+
+	if len(got) != 3 {
+		t.Fatalf("got %d proposals, want 3", len(got))
+	}
+	if got[1].OldContent == nil || *got[1].OldContent != "a := 1\nb := 2\n" || got[1].NewContent != "a := 10\nb := 20\n" {
+		t.Errorf("second proposal = %q -> %q", *got[1].OldContent, got[1].NewContent)
+	}
+	if got[2].OldContent != nil || got[2].FilePath != filepath.Join(dir, "new.go") {
+		t.Errorf("new file proposal = %+v", got[2])
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "a := 1\nb := 2\n" {
+		t.Errorf("file was written: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.go")); !os.IsNotExist(err) {
+		t.Error("new file was created")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".g/artifacts/s1/report.md")); string(data) != "# Report\n" {
+		t.Errorf("artifact = %q", data)
+	}
+}
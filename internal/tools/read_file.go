@@ -7,9 +7,10 @@ package tools
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/k-sub1995/g/internal/api"
@@ -44,7 +45,7 @@ func (t *ReadFileTool) Declaration() api.FunctionDecl {
 				},
 				"limit": map[string]interface{}{
 					"type":        "number",
-					"description": "Optional: For text files, maximum number of lines to read. Use with 'offset' to paginate through large files. If omitted, reads up to 2000 lines.",
+					"description": fmt.Sprintf("Optional: For text files, maximum number of lines to read. Use with 'offset' to paginate through large files. If omitted, reads up to %d lines.", t.opts.Limits.readFileLines()),
 				},
 			},
 			"required": []string{"file_path"},
@@ -58,7 +59,7 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]interface{})
 		return errorResult("file_path is required"), nil
 	}
 
-	absPath := t.resolvePath(filePath)
+	absPath := t.opts.resolvePath(filePath)
 
 	info, err := os.Stat(absPath)
 	if err != nil {
@@ -75,9 +76,18 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]interface{})
 	defer f.Close()
 
 	offset := intArg(args, "offset", 0)
-	limit := intArg(args, "limit", defaultReadLimit)
-
-	scanner := bufio.NewScanner(f)
+	limit := intArg(args, "limit", t.opts.Limits.readFileLines())
+
+	// Transcode legacy encodings so the model does not see mojibake
+	h := sha256.New()
+	br := bufio.NewReaderSize(io.TeeReader(f, h), sniffLen)
+	sample, _ := br.Peek(sniffLen)
+	var r io.Reader = br
+	enc := detectEncoding(sample, len(sample) < sniffLen)
+	if enc != nil {
+		r = enc.NewDecoder().Reader(br)
+	}
+	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024) // 1MB buffer
 
 	var lines []string
@@ -98,6 +108,9 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]interface{})
 	if err := scanner.Err(); err != nil {
 		return errorResult(fmt.Sprintf("error reading file: %v", err)), nil
 	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	t.opts.reads.record(absPath, sum)
 
 	// Check if there are more lines
 	if lineNum > offset+limit {
@@ -110,6 +123,9 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]interface{})
 		"file_path":  absPath,
 		"line_count": len(lines),
 	}
+	if enc != nil {
+		result["encoding"] = enc.Name
+	}
 
 	if truncated {
 		result["truncated"] = true
@@ -121,13 +137,6 @@ func (t *ReadFileTool) Execute(ctx context.Context, args map[string]interface{})
 	return &ToolResult{Content: result}, nil
 }
 
-func (t *ReadFileTool) resolvePath(path string) string {
-	if filepath.IsAbs(path) {
-		return path
-	}
-	return filepath.Join(t.opts.WorkDir, path)
-}
-
 // Helper functions shared across tools
 
 func errorResult(msg string) *ToolResult {
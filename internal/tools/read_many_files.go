@@ -8,11 +8,17 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/k-sub1995/g/internal/api"
 )
 
+const (
+	defaultPreviewLines = 10
+	maxParallelReads    = 8
+)
+
 type ReadManyFilesTool struct {
 	opts RegistryOptions
 }
@@ -26,7 +32,7 @@ func (t *ReadManyFilesTool) Name() string { return "read_many_files" }
 func (t *ReadManyFilesTool) Declaration() api.FunctionDecl {
 	return api.FunctionDecl{
 		Name:        "read_many_files",
-		Description: "Reads and returns the content of multiple files simultaneously. More efficient than reading files one by one.",
+		Description: "Reads and returns the content of multiple files simultaneously. More efficient than reading files one by one. With preview, returns only the start, end and outline of each file.",
 		Parameters: mustMarshalJSON(map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -35,6 +41,14 @@ func (t *ReadManyFilesTool) Declaration() api.FunctionDecl {
 					"items":       map[string]interface{}{"type": "string"},
 					"description": "Array of file paths to read.",
 				},
+				"preview": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: Instead of full contents, return each file's line count, its first and last preview_lines lines and an outline of its functions, classes and types with line numbers. Use to triage many files cheaply, then read the relevant ones with read_file.",
+				},
+				"preview_lines": map[string]interface{}{
+					"type":        "number",
+					"description": fmt.Sprintf("Optional: With preview, the number of lines shown from the start and from the end of each file. Defaults to %d.", defaultPreviewLines),
+				},
 			},
 			"required": []string{"file_paths"},
 		}),
@@ -65,23 +79,30 @@ func (t *ReadManyFilesTool) Execute(ctx context.Context, args map[string]interfa
 		return errorResult("file_paths must not be empty"), nil
 	}
 
-	results := make(map[string]interface{})
-	for _, p := range paths {
-		absPath := p
-		if !filepath.IsAbs(p) {
-			absPath = filepath.Join(t.opts.WorkDir, p)
-		}
+	preview := boolArg(args, "preview", false)
+	previewLines := intArg(args, "preview_lines", defaultPreviewLines)
+	if previewLines < 1 {
+		previewLines = defaultPreviewLines
+	}
 
-		data, err := os.ReadFile(absPath)
-		if err != nil {
-			results[absPath] = map[string]interface{}{"error": fmt.Sprintf("failed to read: %v", err)}
-		} else {
-			content := string(data)
-			if len(content) > 100*1024 { // 100KB per file
-				content = content[:100*1024] + "\n... [truncated]"
-			}
-			results[absPath] = map[string]interface{}{"content": content}
-		}
+	// Files are read in parallel; each goroutine fills its own slot
+	entries := make([]map[string]interface{}, len(paths))
+	sem := make(chan struct{}, maxParallelReads)
+	var wg sync.WaitGroup
+	for i, p := range paths {
+		wg.Add(1)
+		go func(i int, absPath string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			entries[i] = t.readOne(absPath, preview, previewLines)
+		}(i, t.opts.resolvePath(p))
+	}
+	wg.Wait()
+
+	results := make(map[string]interface{}, len(paths))
+	for i, p := range paths {
+		results[t.opts.resolvePath(p)] = entries[i]
 	}
 
 	return &ToolResult{
@@ -91,3 +112,49 @@ func (t *ReadManyFilesTool) Execute(ctx context.Context, args map[string]interfa
 		},
 	}, nil
 }
+
+// readOne returns the result entry for one file: its content, or with
+// preview its head, tail and outline.
+func (t *ReadManyFilesTool) readOne(absPath string, preview bool, previewLines int) map[string]interface{} {
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return map[string]interface{}{"error": fmt.Sprintf("failed to read: %v", err)}
+	}
+	content, enc := decodeText(data)
+	var entry map[string]interface{}
+	if preview {
+		// Unless the file was short enough to show whole, the model must
+		// still read it before editing
+		entry = previewEntry(absPath, content, previewLines)
+		if _, whole := entry["content"]; whole {
+			t.opts.reads.recordContent(absPath, data)
+		}
+	} else {
+		t.opts.reads.recordContent(absPath, data)
+		if len(content) > 100*1024 { // 100KB per file
+			content = content[:100*1024] + "\n... [truncated]"
+		}
+		entry = map[string]interface{}{"content": content}
+	}
+	if enc != nil {
+		entry["encoding"] = enc.Name
+	}
+	return entry
+}
+
+// previewEntry summarizes a file as its line count, first and last n lines
+// and outline. A file of at most 2n lines is returned whole as content.
+func previewEntry(path, content string, n int) map[string]interface{} {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	entry := map[string]interface{}{"lines": len(lines)}
+	if len(lines) <= 2*n {
+		entry["content"] = content
+	} else {
+		entry["head"] = strings.Join(lines[:n], "\n")
+		entry["tail"] = strings.Join(lines[len(lines)-n:], "\n")
+	}
+	if symbols := outline(path, content); len(symbols) > 0 {
+		entry["outline"] = symbols
+	}
+	return entry
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadManyFilesPreview(t *testing.T) {
+	dir := t.TempDir()
+	var src strings.Builder
+	src.WriteString("package big\n\ntype Server struct{}\n\nfunc (s *Server) Start() error {\n\treturn nil\n}\n")
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&src, "\nfunc helper%d() {}\n", i)
+	}
+	os.WriteFile(filepath.Join(dir, "big.go"), []byte(src.String()), 0644)
+	os.WriteFile(filepath.Join(dir, "small.py"), []byte("class A:\n    def run(self):\n        pass\n"), 0644)
+
+	tool := NewReadManyFilesTool(RegistryOptions{WorkDir: dir})
+	res, _ := tool.Execute(context.Background(), map[string]interface{}{
+		"file_paths":    []interface{}{"big.go", "small.py", "missing.txt"},
+		"preview":       true,
+		"preview_lines": float64(3),
+	})
+	files := res.Content["files"].(map[string]interface{})
+
+	big := files[filepath.Join(dir, "big.go")].(map[string]interface{})
+	if big["head"] != "package big\n\ntype Server struct{}" || big["content"] != nil {
+		t.Errorf("big.go head = %q, content present: %v", big["head"], big["content"] != nil)
+	}
+	symbols := big["outline"].([]symbol)
+	if len(symbols) != 52 || symbols[0] != (symbol{Name: "Server", Kind: "type", Line: 3, EndLine: 3}) || symbols[1] != (symbol{Name: "Start", Kind: "method", Line: 5, EndLine: 7}) {
+		t.Errorf("big.go outline = %v", symbols[:2])
+	}
+
+	small := files[filepath.Join(dir, "small.py")].(map[string]interface{})
+	if small["content"] == nil || len(small["outline"].([]symbol)) != 2 || small["outline"].([]symbol)[1].Kind != "method" {
+		t.Errorf("small.py = %v", small)
+	}
+	if files[filepath.Join(dir, "missing.txt")].(map[string]interface{})["error"] == nil {
+		t.Error("missing file should report an error")
+	}
+}
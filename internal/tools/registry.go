@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/sandbox"
 )
 
 // ToolResult is the standard return value from tool execution.
@@ -28,6 +29,10 @@ type Tool interface {
 // WebSearchFunc is a callback for performing web searches via the API.
 type WebSearchFunc func(ctx context.Context, query string) (text string, sources []WebSource, err error)
 
+// AskFunc asks the user a question and returns the answer; an empty answer
+// returns defaultAnswer.
+type AskFunc func(ctx context.Context, question, defaultAnswer string) (string, error)
+
 // WebSource represents a web search result source.
 type WebSource struct {
 	Title string
@@ -36,11 +41,44 @@ type WebSource struct {
 
 // RegistryOptions configures tool behavior.
 type RegistryOptions struct {
-	WorkDir     string
-	AutoApprove bool
-	Sandbox     bool
-	Debug       bool
-	WebSearch   WebSearchFunc
+	WorkDir      string
+	AutoApprove  bool
+	Sandbox      bool
+	Container    *sandbox.Container // run shell commands in a container (nil: on the host)
+	WindowsShell WindowsShell       // run_shell_command's shell on Windows ("": powershell)
+	Debug        bool
+	WebSearch    WebSearchFunc
+	Network      NetworkPolicy
+	ReadOnly     bool // register only tools that read the workspace
+	Limits       Limits
+	Timeouts     Timeouts
+	Ask          AskFunc         // answers ask_user; nil when no one can answer
+	ProposeEdit  ProposeEditFunc // when set, file edits are proposed instead of written
+	ArtifactsDir string          // where the session saves reports and exports; written even when edits are proposed
+	Changes      *ChangeBudget   // limits the files and bytes edits change; nil: no limits
+
+	IncludeTools []string // when set, register only these built-in tools
+	ExcludeTools []string // built-in tools to leave out (wins over IncludeTools)
+
+	reads     *readTracker  // shared by the file tools of one registry
+	proposals *proposalSet  // edits proposed so far, when ProposeEdit is set
+	shell     *shellSession // run_shell_command's working directory
+}
+
+// readOnlyTools are the built-in tools that only read local files.
+var readOnlyTools = map[string]bool{
+	"read_file":       true,
+	"read_many_files": true,
+	"get_outline":     true,
+	"glob":            true,
+	"grep_search":     true,
+	"list_directory":  true,
+}
+
+// IsReadOnly reports whether name is a built-in tool that only reads local
+// files.
+func IsReadOnly(name string) bool {
+	return readOnlyTools[name]
 }
 
 // MCPToolRef tracks which MCP server owns a tool.
@@ -62,6 +100,15 @@ func NewRegistry(opts RegistryOptions) *Registry {
 		builtins: make(map[string]Tool),
 		mcp:      make(map[string]MCPToolRef),
 	}
+	if opts.reads == nil {
+		opts.reads = newReadTracker()
+	}
+	if opts.shell == nil {
+		opts.shell = newShellSession()
+	}
+	if opts.ProposeEdit != nil && opts.proposals == nil {
+		opts.proposals = newProposalSet()
+	}
 	r.registerBuiltins(opts)
 	return r
 }
@@ -71,11 +118,13 @@ func (r *Registry) registerBuiltins(opts RegistryOptions) {
 		NewReadFileTool(opts),
 		NewWriteFileTool(opts),
 		NewEditTool(opts),
+		NewInsertTool(opts),
 		NewShellTool(opts),
 		NewGlobTool(opts),
 		NewGrepTool(opts),
 		NewLsTool(opts),
 		NewReadManyFilesTool(opts),
+		NewGetOutlineTool(opts),
 		NewWebSearchTool(opts),
 		NewWebFetchTool(opts),
 		NewMemoryTool(opts),
@@ -87,14 +136,61 @@ func (r *Registry) registerBuiltins(opts RegistryOptions) {
 		NewInternalDocsTool(opts),
 	}
 	for _, t := range tools {
+		if opts.ReadOnly && !readOnlyTools[t.Name()] {
+			continue
+		}
+		if !toolEnabled(t.Name(), opts.IncludeTools, opts.ExcludeTools) {
+			continue
+		}
+		switch t.Name() {
+		case "google_web_search":
+			// Search results cannot be limited to an allowlist
+			if opts.Network.Restricted() {
+				continue
+			}
+		case "web_fetch":
+			if opts.Network.Disabled {
+				continue
+			}
+		}
 		r.builtins[t.Name()] = t
 		r.order = append(r.order, t.Name())
 	}
 }
 
-// RegisterMCPTool adds an MCP-backed tool to the registry.
-func (r *Registry) RegisterMCPTool(serverName, toolName string) {
-	r.mcp[toolName] = MCPToolRef{ServerName: serverName, ToolName: toolName}
+func toolEnabled(name string, include, exclude []string) bool {
+	for _, n := range exclude {
+		if n == name {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, n := range include {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Register adds a tool to the registry. Built-in tools take precedence:
+// registering a name that already exists returns false and leaves the
+// registry unchanged.
+func (r *Registry) Register(t Tool) bool {
+	if _, exists := r.builtins[t.Name()]; exists {
+		return false
+	}
+	r.builtins[t.Name()] = t
+	r.order = append(r.order, t.Name())
+	return true
+}
+
+// RegisterMCPTool adds an MCP-backed tool to the registry under name,
+// mapping it to toolName on the given server.
+func (r *Registry) RegisterMCPTool(serverName, name, toolName string) {
+	r.mcp[name] = MCPToolRef{ServerName: serverName, ToolName: toolName}
 }
 
 // Get returns a built-in tool by name.
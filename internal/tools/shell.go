@@ -7,8 +7,9 @@ package tools
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 
 const (
 	shellTimeout   = 120 * time.Second
+	killWaitDelay  = 2 * time.Second
 	maxOutputBytes = 100 * 1024 // 100KB
 )
 
@@ -32,8 +34,10 @@ func (t *ShellTool) Name() string { return "run_shell_command" }
 
 func (t *ShellTool) Declaration() api.FunctionDecl {
 	shellDesc := "This tool executes a given shell command as `bash -c <command>`. Use this to run system commands, build projects, run tests, and perform git operations."
-	if runtime.GOOS == "windows" {
-		shellDesc = "This tool executes a given shell command as `powershell.exe -NoProfile -Command <command>`. Use this to run system commands, build projects, run tests, and perform git operations."
+	if t.opts.Container != nil {
+		shellDesc = "This tool executes a given shell command as `bash -c <command>` inside " + t.opts.Container.Describe() + " with the project directory mounted. Use this to run system commands, build projects, run tests, and perform git operations."
+	} else if runtime.GOOS == "windows" {
+		shellDesc = t.opts.WindowsShell.describe()
 	}
 
 	return api.FunctionDecl{
@@ -52,7 +56,7 @@ func (t *ShellTool) Declaration() api.FunctionDecl {
 				},
 				"dir_path": map[string]interface{}{
 					"type":        "string",
-					"description": "Optional: The directory to run the command in. Defaults to the project root.",
+					"description": "Optional: The directory to run the command in. Defaults to the directory the previous command ended in (after `cd`), initially the project root.",
 				},
 				"is_background": map[string]interface{}{
 					"type":        "boolean",
@@ -70,48 +74,67 @@ func (t *ShellTool) Execute(ctx context.Context, args map[string]interface{}) (*
 		return errorResult("command is required"), nil
 	}
 
-	dirPath := stringArg(args, "dir_path", t.opts.WorkDir)
-	if !filepath.IsAbs(dirPath) {
-		dirPath = filepath.Join(t.opts.WorkDir, dirPath)
+	dirPath := stringArg(args, "dir_path", t.opts.shell.cwd(t.opts.WorkDir))
+	dirPath = t.opts.resolvePath(dirPath)
+
+	// bash reports where the command ends up, for the next call
+	tracksDir := t.opts.shell != nil && (runtime.GOOS != "windows" || t.opts.Container != nil || t.opts.WindowsShell == ShellGitBash)
+	if tracksDir {
+		command = t.opts.shell.wrap(command)
 	}
 
 	// Create command with timeout
-	cmdCtx, cancel := context.WithTimeout(ctx, shellTimeout)
+	timeout := t.opts.Timeouts.get(t.Name(), shellTimeout)
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(cmdCtx, "powershell.exe", "-NoProfile", "-Command", command)
+	if t.opts.Container != nil {
+		var err error
+		cmd, err = t.opts.Container.Command(cmdCtx, dirPath, command)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
+	} else if runtime.GOOS == "windows" {
+		var err error
+		cmd, err = t.opts.WindowsShell.Command(cmdCtx, command)
+		if err != nil {
+			return errorResult(err.Error()), nil
+		}
 	} else {
 		cmd = exec.CommandContext(cmdCtx, "bash", "-c", command)
 	}
-	cmd.Dir = dirPath
+	if t.opts.Container == nil {
+		cmd.Dir = dirPath
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	// Background processes the command started may hold its output open;
+	// do not wait for them once it is killed
+	cmd.WaitDelay = killWaitDelay
 
 	err := cmd.Run()
 
-	stdoutStr := stdout.String()
-	stderrStr := stderr.String()
-
-	// Truncate output if too large
-	if len(stdoutStr) > maxOutputBytes {
-		stdoutStr = stdoutStr[:maxOutputBytes] + "\n... [output truncated]"
-	}
-	if len(stderrStr) > maxOutputBytes {
-		stderrStr = stderrStr[:maxOutputBytes] + "\n... [output truncated]"
+	errOut := stderr.Bytes()
+	result := map[string]interface{}{}
+	if tracksDir {
+		var dir string
+		if errOut, dir = t.opts.shell.extract(errOut); dir != "" {
+			t.setShellDir(result, t.opts.resolvePath(dir))
+		}
 	}
 
-	result := map[string]interface{}{
-		"stdout": stdoutStr,
-		"stderr": stderrStr,
-	}
+	// Truncate large output to its head and tail, saving the rest to a file
+	limit := t.opts.Limits.shellOutputBytes()
+	truncateOutput(result, "stdout", stdout.Bytes(), limit)
+	truncateOutput(result, "stderr", errOut, limit)
 
 	if err != nil {
 		if cmdCtx.Err() == context.DeadlineExceeded {
-			result["error"] = "command timed out"
+			result["error"] = fmt.Sprintf("command timed out after %s and was killed; stdout and stderr hold its output up to then", timeout)
+			result["timed_out"] = true
 		} else {
 			result["exit_code"] = cmd.ProcessState.ExitCode()
 			result["error"] = err.Error()
@@ -122,3 +145,46 @@ func (t *ShellTool) Execute(ctx context.Context, args map[string]interface{}) (*
 	result["exit_code"] = 0
 	return &ToolResult{Content: result}, nil
 }
+
+// setShellDir makes dir the directory later commands run in and reports it
+// in result when it is not the project root. Sandboxed sessions stay in
+// the working directory.
+func (t *ShellTool) setShellDir(result map[string]interface{}, dir string) {
+	if (t.opts.Sandbox || t.opts.Container != nil) && !isPathUnder(dir, t.opts.WorkDir) {
+		t.opts.shell.set("")
+		result["cwd"] = t.opts.WorkDir
+		result["cwd_note"] = "Directories outside the working directory do not carry over in the sandbox; the next command runs in the working directory."
+		return
+	}
+	t.opts.shell.set(dir)
+	if dir != t.opts.WorkDir {
+		result["cwd"] = dir
+	}
+}
+
+// truncateOutput stores one output stream in result under key. A stream
+// longer than limit keeps its head and tail; the full stream is saved to a
+// temporary file whose path is stored under key+"_file", so the agent can
+// read the dropped part with read_file.
+func truncateOutput(result map[string]interface{}, key string, out []byte, limit int) {
+	s := string(out)
+	if len(s) <= limit {
+		result[key] = s
+		return
+	}
+	result[key] = TruncateMiddle(s, limit)
+	f, err := os.CreateTemp("", "g-shell-"+key+"-*.log")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(out)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	result[key+"_file"] = f.Name()
+	result["message"] = fmt.Sprintf("Output was longer than %d bytes; the middle was dropped. The full output is in the *_file paths (read them with read_file and offset/limit).", limit)
+}
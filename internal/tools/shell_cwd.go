@@ -0,0 +1,69 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// shellSession carries the working directory from one run_shell_command
+// call to the next, as in a terminal. The directory a command ends in is
+// reported on stderr after a marker line and stripped from the output.
+type shellSession struct {
+	marker string
+
+	mu  sync.Mutex
+	dir string // "" until a command leaves the working directory
+}
+
+func newShellSession() *shellSession {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return &shellSession{marker: "__g_cwd_" + hex.EncodeToString(b) + ":"}
+}
+
+// cwd returns the directory the next command runs in, falling back to
+// workDir when there is none or it has been removed.
+func (s *shellSession) cwd(workDir string) string {
+	if s == nil {
+		return workDir
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir == "" {
+		return workDir
+	}
+	if info, err := os.Stat(s.dir); err != nil || !info.IsDir() {
+		s.dir = ""
+		return workDir
+	}
+	return s.dir
+}
+
+// wrap makes a bash command report its final directory. The command's exit
+// status is kept; a command that exits the shell leaves the directory as
+// it was.
+func (s *shellSession) wrap(command string) string {
+	return fmt.Sprintf("%s\n__g_status=$?; printf '\\n%s%%s\\n' \"$PWD\" >&2; exit $__g_status", command, s.marker)
+}
+
+// extract removes the directory report from stderr and returns it.
+func (s *shellSession) extract(stderr []byte) ([]byte, string) {
+	i := strings.LastIndex(string(stderr), "\n"+s.marker)
+	if i < 0 {
+		return stderr, ""
+	}
+	dir := strings.TrimRight(string(stderr[i+1+len(s.marker):]), "\r\n")
+	return stderr[:i], dir
+}
+
+func (s *shellSession) set(dir string) {
+	s.mu.Lock()
+	s.dir = dir
+	s.mu.Unlock()
+}
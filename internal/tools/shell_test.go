@@ -0,0 +1,87 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTruncateOutputSavesFullStream(t *testing.T) {
+	out := []byte(strings.Repeat("a", 100) + strings.Repeat("b", 100) + "FAIL")
+	result := map[string]interface{}{}
+	truncateOutput(result, "stdout", out, 50)
+
+	got := result["stdout"].(string)
+	if !strings.HasPrefix(got, "aaa") || !strings.HasSuffix(got, "FAIL") || !strings.Contains(got, "bytes truncated") {
+		t.Fatalf("stdout = %q", got)
+	}
+	path, ok := result["stdout_file"].(string)
+	if !ok {
+		t.Fatalf("no stdout_file in %v", result)
+	}
+	defer os.Remove(path)
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != string(out) {
+		t.Fatalf("saved output = %q, %v", data, err)
+	}
+
+	short := map[string]interface{}{}
+	truncateOutput(short, "stderr", []byte("ok"), 50)
+	if short["stderr"] != "ok" || short["stderr_file"] != nil || short["message"] != nil {
+		t.Fatalf("short result = %v", short)
+	}
+}
+
+func TestShellKeepsWorkingDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses bash")
+	}
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	tool, _ := NewRegistry(RegistryOptions{WorkDir: dir}).Get("run_shell_command")
+	run := func(command string) map[string]interface{} {
+		t.Helper()
+		res, err := tool.Execute(context.Background(), map[string]interface{}{"command": command})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Content
+	}
+
+	if res := run("cd sub && echo oops >&2 && false"); res["exit_code"] != 1 || res["stderr"] != "oops\n" {
+		t.Errorf("exit status or stderr changed: %v", res)
+	}
+	res := run("pwd")
+	if got := strings.TrimSpace(res["stdout"].(string)); got != filepath.Join(dir, "sub") {
+		t.Errorf("second command ran in %s, want sub", got)
+	}
+	if res["cwd"] != filepath.Join(dir, "sub") {
+		t.Errorf("cwd = %v", res["cwd"])
+	}
+	if res := run("cd .. && pwd"); res["cwd"] != nil {
+		t.Errorf("cwd reported at the project root: %v", res)
+	}
+}
+
+func TestShellTimeoutKeepsPartialOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses bash")
+	}
+	tool := NewShellTool(RegistryOptions{WorkDir: t.TempDir(), Timeouts: Timeouts{"run_shell_command": 300 * time.Millisecond}})
+	res, err := tool.Execute(context.Background(), map[string]interface{}{"command": "echo started; sleep 5; echo done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError || res.Content["timed_out"] != true {
+		t.Fatalf("result = %v, want a timeout", res.Content)
+	}
+	if out := res.Content["stdout"].(string); out != "started\n" {
+		t.Errorf("stdout = %q, want the output before the timeout", out)
+	}
+}
@@ -7,10 +7,9 @@ package tools
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/skills"
 )
 
 type ActivateSkillTool struct {
@@ -26,7 +25,7 @@ func (t *ActivateSkillTool) Name() string { return "activate_skill" }
 func (t *ActivateSkillTool) Declaration() api.FunctionDecl {
 	return api.FunctionDecl{
 		Name:        "activate_skill",
-		Description: "Activates a skill by name, loading its instructions from the project's .gemini/skills/ directory.",
+		Description: "Activates a skill by name, loading its instructions from the project's or user's .gemini/skills/ directory.",
 		Parameters: mustMarshalJSON(map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -46,18 +45,18 @@ func (t *ActivateSkillTool) Execute(ctx context.Context, args map[string]interfa
 		return errorResult("skill_name is required"), nil
 	}
 
-	// Search for SKILL.md in .gemini/skills/<skill_name>/
-	skillPath := filepath.Join(t.opts.WorkDir, ".gemini", "skills", skillName, "SKILL.md")
-	data, err := os.ReadFile(skillPath)
+	// Project skills in .gemini/skills/<name>/ shadow ~/.gemini/skills/<name>/
+	skill, err := skills.Find(t.opts.WorkDir, skillName)
 	if err != nil {
-		return errorResult(fmt.Sprintf("skill '%s' not found at %s", skillName, skillPath)), nil
+		return errorResult(err.Error()), nil
 	}
 
 	return &ToolResult{
 		Content: map[string]interface{}{
-			"skill_name":   skillName,
-			"instructions": string(data),
-			"message":      fmt.Sprintf("Skill '%s' activated. Follow the instructions above.", skillName),
+			"skill_name":   skill.Name,
+			"instructions": skill.Body,
+			"directory":    skill.Dir,
+			"message":      fmt.Sprintf("Skill '%s' activated. Follow the instructions above.", skill.Name),
 		},
 	}, nil
 }
@@ -0,0 +1,93 @@
+// Package tools provides tool implementations used by the Gemini agent.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/k-sub1995/g/internal/api"
+)
+
+// AgentInfo names a sub-agent the task tool can delegate to.
+type AgentInfo struct {
+	Name        string
+	Description string
+}
+
+// RunAgentFunc runs the named sub-agent on task and returns its final
+// answer.
+type RunAgentFunc func(ctx context.Context, agent, task string) (string, error)
+
+// TaskTool delegates a self-contained task to a custom sub-agent, which
+// works in its own conversation with its own prompt and tools.
+type TaskTool struct {
+	agents []AgentInfo
+	run    RunAgentFunc
+}
+
+func NewTaskTool(agents []AgentInfo, run RunAgentFunc) *TaskTool {
+	return &TaskTool{agents: agents, run: run}
+}
+
+func (t *TaskTool) Name() string { return "task" }
+
+func (t *TaskTool) Declaration() api.FunctionDecl {
+	names := make([]string, len(t.agents))
+	var list strings.Builder
+	for i, a := range t.agents {
+		names[i] = a.Name
+		fmt.Fprintf(&list, "\n- %s: %s", a.Name, a.Description)
+	}
+	return api.FunctionDecl{
+		Name:        "task",
+		Description: "Delegates a self-contained task to a specialized agent and returns its final answer. The agent does not see this conversation, so the prompt must contain everything it needs. Available agents:" + list.String(),
+		Parameters: mustMarshalJSON(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"agent": map[string]interface{}{
+					"type":        "string",
+					"description": "The agent to run.",
+					"enum":        names,
+				},
+				"prompt": map[string]interface{}{
+					"type":        "string",
+					"description": "The task, with all context the agent needs.",
+				},
+			},
+			"required": []string{"agent", "prompt"},
+		}),
+	}
+}
+
+func (t *TaskTool) Execute(ctx context.Context, args map[string]interface{}) (*ToolResult, error) {
+	agent := stringArg(args, "agent", "")
+	task := stringArg(args, "prompt", "")
+	if agent == "" || task == "" {
+		return errorResult("agent and prompt are required"), nil
+	}
+	known := false
+	for _, a := range t.agents {
+		known = known || a.Name == agent
+	}
+	if !known {
+		return errorResult(fmt.Sprintf("unknown agent %q", agent)), nil
+	}
+
+	answer, err := t.run(ctx, agent, task)
+	if errors.Is(err, ErrChangeLimit) {
+		return nil, err // the budget is shared, so the whole run ends
+	}
+	if err != nil {
+		return errorResult(fmt.Sprintf("agent %s failed: %v", agent, err)), nil
+	}
+	return &ToolResult{
+		Content: map[string]interface{}{
+			"agent":  agent,
+			"result": answer,
+		},
+	}, nil
+}
@@ -61,8 +61,23 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 		url = "https://" + url
 	}
 
-	client := &http.Client{Timeout: webFetchTimeout}
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err := t.opts.Network.CheckURL(url); err != nil {
+		return errorResult(err.Error()), nil
+	}
+
+	timeout := t.opts.Timeouts.get(t.Name(), webFetchTimeout)
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after 10 redirects")
+			}
+			return t.opts.Network.CheckURL(req.URL.String())
+		},
+	}
+	req, err := http.NewRequestWithContext(fetchCtx, "GET", url, nil)
 	if err != nil {
 		return errorResult(fmt.Sprintf("invalid URL: %v", err)), nil
 	}
@@ -78,8 +93,10 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 		return errorResult(fmt.Sprintf("HTTP %d: %s", resp.StatusCode, resp.Status)), nil
 	}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes))
-	if err != nil {
+	// A body cut off by the timeout is returned as far as it arrived
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.opts.Limits.webFetchBytes())))
+	timedOut := err != nil && fetchCtx.Err() == context.DeadlineExceeded && len(body) > 0
+	if err != nil && !timedOut {
 		return errorResult(fmt.Sprintf("read failed: %v", err)), nil
 	}
 
@@ -90,14 +107,17 @@ func (t *WebFetchTool) Execute(ctx context.Context, args map[string]interface{})
 		content = stripHTMLTags(content)
 	}
 
-	return &ToolResult{
-		Content: map[string]interface{}{
-			"content":      content,
-			"url":          url,
-			"status":       resp.StatusCode,
-			"content_type": resp.Header.Get("Content-Type"),
-		},
-	}, nil
+	result := map[string]interface{}{
+		"content":      content,
+		"url":          url,
+		"status":       resp.StatusCode,
+		"content_type": resp.Header.Get("Content-Type"),
+	}
+	if timedOut {
+		result["timed_out"] = true
+		result["message"] = fmt.Sprintf("The download timed out after %s; content is only the part received by then.", timeout)
+	}
+	return &ToolResult{Content: result}, nil
 }
 
 // stripHTMLTags is a simple HTML tag remover for basic text extraction.
@@ -0,0 +1,147 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WindowsShell selects the shell run_shell_command uses on Windows.
+type WindowsShell string
+
+const (
+	ShellPowerShell WindowsShell = "powershell" // Windows PowerShell 5.1 (default)
+	ShellPwsh       WindowsShell = "pwsh"       // PowerShell 7
+	ShellCmd        WindowsShell = "cmd"
+	ShellGitBash    WindowsShell = "git-bash"
+)
+
+// ParseWindowsShell validates a tools.shell setting; "" is the default.
+func ParseWindowsShell(s string) (WindowsShell, error) {
+	switch sh := WindowsShell(strings.ToLower(s)); sh {
+	case "":
+		return ShellPowerShell, nil
+	case ShellPowerShell, ShellPwsh, ShellCmd, ShellGitBash:
+		return sh, nil
+	}
+	return "", fmt.Errorf("unknown shell %q: use powershell, pwsh, cmd or git-bash", s)
+}
+
+// Name is the shell as shown to the model in the system prompt.
+func (s WindowsShell) Name() string {
+	switch s {
+	case ShellPwsh:
+		return "pwsh (PowerShell 7)"
+	case ShellCmd:
+		return "cmd.exe"
+	case ShellGitBash:
+		return "bash (Git for Windows)"
+	}
+	return "powershell.exe (Windows PowerShell 5.1)"
+}
+
+// describe is the run_shell_command description for the shell, including
+// the syntax the model tends to get wrong.
+func (s WindowsShell) describe() string {
+	const use = " Use this to run system commands, build projects, run tests, and perform git operations."
+	switch s {
+	case ShellPwsh:
+		return "This tool executes a given shell command as `pwsh -NoProfile -Command <command>` (PowerShell 7)." + use +
+			" Use PowerShell syntax: `$env:NAME` for environment variables; `&&` and `||` chain commands."
+	case ShellCmd:
+		return "This tool executes a given shell command as `cmd.exe /d /s /c <command>`." + use +
+			" Use cmd syntax: `%NAME%` for environment variables, `&&` to chain commands, backslashes in paths and double quotes only."
+	case ShellGitBash:
+		return "This tool executes a given shell command as `bash -c <command>` using Git for Windows' bash." + use +
+			" Use POSIX shell syntax and forward slashes in paths (C:/work or /c/work); backslashes are escape characters."
+	}
+	return "This tool executes a given shell command as `powershell.exe -NoProfile -Command <command>` (Windows PowerShell 5.1)." + use +
+		" Use PowerShell syntax: `$env:NAME` for environment variables, `;` to separate commands. `a && b` runs b only if a succeeds; `||` is not supported."
+}
+
+// Command builds the process for command in the shell.
+func (s WindowsShell) Command(ctx context.Context, command string) (*exec.Cmd, error) {
+	switch s {
+	case ShellPwsh:
+		return exec.CommandContext(ctx, "pwsh", "-NoProfile", "-Command", command), nil
+	case ShellCmd:
+		// cmd.exe does not parse the backslash escaping Go applies to
+		// arguments, so it gets the command line verbatim
+		cmd := exec.CommandContext(ctx, "cmd.exe")
+		setCmdLine(cmd, `cmd.exe /d /s /c "`+command+`"`)
+		return cmd, nil
+	case ShellGitBash:
+		bash, err := gitBash()
+		if err != nil {
+			return nil, err
+		}
+		return exec.CommandContext(ctx, bash, "-c", command), nil
+	}
+	return exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-Command", translateAndAnd(command)), nil
+}
+
+// gitBash finds Git for Windows' bash.exe. The bash.exe on PATH is often
+// WSL's launcher in System32, which runs in a different filesystem.
+func gitBash() (string, error) {
+	var candidates []string
+	if git, err := exec.LookPath("git"); err == nil {
+		// <Git>\cmd\git.exe or <Git>\mingw64\bin\git.exe
+		root := filepath.Dir(filepath.Dir(git))
+		candidates = append(candidates,
+			filepath.Join(root, "bin", "bash.exe"),
+			filepath.Join(filepath.Dir(root), "bin", "bash.exe"))
+	}
+	for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)", "LocalAppData"} {
+		if dir := os.Getenv(env); dir != "" {
+			candidates = append(candidates, filepath.Join(dir, "Git", "bin", "bash.exe"))
+			candidates = append(candidates, filepath.Join(dir, "Programs", "Git", "bin", "bash.exe"))
+		}
+	}
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && !info.IsDir() {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("git-bash: bash.exe from Git for Windows not found; install Git or choose another tools.shell")
+}
+
+// translateAndAnd rewrites `a && b` chains, which Windows PowerShell 5.1
+// rejects, to `a; if ($?) { b }`. Commands with `||` or with `&&` only
+// inside quotes are returned unchanged.
+func translateAndAnd(command string) string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '|' && i+1 < len(command) && command[i+1] == '|':
+			return command
+		case c == '&' && i+1 < len(command) && command[i+1] == '&':
+			parts = append(parts, strings.TrimSpace(command[start:i]))
+			i++
+			start = i + 1
+		}
+	}
+	if len(parts) == 0 {
+		return command
+	}
+	parts = append(parts, strings.TrimSpace(command[start:]))
+
+	out := parts[len(parts)-1]
+	for i := len(parts) - 2; i >= 0; i-- {
+		out = parts[i] + "; if ($?) { " + out + " }"
+	}
+	return out
+}
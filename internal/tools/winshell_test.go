@@ -0,0 +1,32 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package tools
+
+import "testing"
+
+func TestTranslateAndAnd(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"go build ./...", "go build ./..."},
+		{"cd web && npm test", "cd web; if ($?) { npm test }"},
+		{"a && b && c", "a; if ($?) { b; if ($?) { c } }"},
+		{`echo "a && b"`, `echo "a && b"`},
+		{"a && b || c", "a && b || c"},
+	}
+	for _, tt := range tests {
+		if got := translateAndAnd(tt.in); got != tt.want {
+			t.Errorf("translateAndAnd(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseWindowsShell(t *testing.T) {
+	if sh, err := ParseWindowsShell(""); err != nil || sh != ShellPowerShell {
+		t.Errorf("default = %q, %v", sh, err)
+	}
+	if sh, err := ParseWindowsShell("Git-Bash"); err != nil || sh != ShellGitBash {
+		t.Errorf("git-bash = %q, %v", sh, err)
+	}
+	if _, err := ParseWindowsShell("zsh"); err == nil {
+		t.Error("expected an error for an unknown shell")
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/k-sub1995/g/internal/api"
 )
@@ -26,7 +25,7 @@ func (t *WriteFileTool) Name() string { return "write_file" }
 func (t *WriteFileTool) Declaration() api.FunctionDecl {
 	return api.FunctionDecl{
 		Name:        "write_file",
-		Description: "Writes content to a specified file in the local filesystem. Creates the file if it doesn't exist. Creates parent directories as needed.",
+		Description: "Writes content to a specified file in the local filesystem. Creates the file if it doesn't exist. Creates parent directories as needed. An existing file you have not read is only replaced with overwrite set to true.",
 		Parameters: mustMarshalJSON(map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -38,6 +37,10 @@ func (t *WriteFileTool) Declaration() api.FunctionDecl {
 					"type":        "string",
 					"description": "The content to write to the file.",
 				},
+				"overwrite": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: Replace an existing file you have not read in this session. Read the file first instead unless you are sure its content is not needed.",
+				},
 			},
 			"required": []string{"file_path", "content"},
 		}),
@@ -52,56 +55,59 @@ func (t *WriteFileTool) Execute(ctx context.Context, args map[string]interface{}
 		return errorResult("file_path is required"), nil
 	}
 
-	absPath := t.resolvePath(filePath)
+	absPath := t.opts.resolvePath(filePath)
 
-	if t.opts.Sandbox {
-		if !isPathUnder(absPath, t.opts.WorkDir) {
-			return errorResult(fmt.Sprintf("sandbox: cannot write outside working directory %s", t.opts.WorkDir)), nil
-		}
+	if t.opts.outsideSandbox(absPath) {
+		return errorResult(fmt.Sprintf("sandbox: cannot write outside working directory %s", t.opts.WorkDir)), nil
 	}
 
-	// Create parent directories
-	dir := filepath.Dir(absPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return errorResult(fmt.Sprintf("failed to create directory: %v", err)), nil
+	if res := t.opts.reads.stale(absPath); res != nil {
+		return res, nil
+	}
+	if overwrite, _ := args["overwrite"].(bool); !overwrite && t.unread(absPath) {
+		return &ToolResult{
+			Content: map[string]interface{}{
+				"error":     fmt.Sprintf("%s already exists and you have not read it; nothing was written. Read it with read_file first, or call write_file again with overwrite set to true to replace it.", absPath),
+				"file_path": absPath,
+			},
+			IsError: true,
+		}, nil
 	}
 
-	if err := os.WriteFile(absPath, []byte(content), 0644); err != nil {
-		return errorResult(fmt.Sprintf("failed to write file: %v", err)), nil
+	proposed, err := writeEdit(ctx, t.opts, absPath, []byte(content))
+	if err != nil {
+		return editFailed(err)
 	}
 
+	message := fmt.Sprintf("Successfully wrote to %s", absPath)
+	if proposed {
+		message = proposedMessage(absPath)
+	}
 	return &ToolResult{
 		Content: map[string]interface{}{
-			"message":   fmt.Sprintf("Successfully wrote to %s", absPath),
+			"message":   message,
 			"file_path": absPath,
 			"bytes":     len(content),
 		},
 	}, nil
 }
 
-func (t *WriteFileTool) resolvePath(path string) string {
-	if filepath.IsAbs(path) {
-		return path
-	}
-	return filepath.Join(t.opts.WorkDir, path)
-}
-
-func isPathUnder(path, base string) bool {
-	absPath, err := filepath.Abs(path)
-	if err != nil {
+// unread reports whether path is a non-empty file the model has neither
+// read, written nor proposed changes to, so writing it would discard content
+// the model never saw.
+func (t *WriteFileTool) unread(path string) bool {
+	if t.opts.reads == nil {
 		return false
 	}
-	absBase, err := filepath.Abs(base)
-	if err != nil {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 || t.opts.reads.seen(path) {
 		return false
 	}
-	rel, err := filepath.Rel(absBase, absPath)
-	if err != nil {
-		return false
+	if t.opts.proposals != nil {
+		t.opts.proposals.mu.Lock()
+		_, proposed := t.opts.proposals.pending[path]
+		t.opts.proposals.mu.Unlock()
+		return !proposed
 	}
-	return !filepath.IsAbs(rel) && rel != ".." && !startsWith(rel, "..")
-}
-
-func startsWith(s, prefix string) bool {
-	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+	return true
 }
@@ -0,0 +1,309 @@
+// Package upgrade replaces the running g binary with the latest GitHub
+// release.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package upgrade
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultAPIURL is the GitHub API endpoint for the latest release.
+	DefaultAPIURL = "https://api.github.com/repos/k-sub1995/g/releases/latest"
+
+	checksumsAsset = "checksums.txt"
+	signatureAsset = "checksums.txt.sig"
+	maxAssetBytes  = 200 << 20
+)
+
+// PublicKey is the base64 ed25519 key that signs checksums.txt. Release
+// builds set it with -ldflags; a build without it installs no release,
+// since checksums fetched from the same place as the archive prove nothing.
+var PublicKey = ""
+
+// Release is a GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release version without the leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// page returns where the release can be downloaded by hand.
+func (r *Release) page() string {
+	if r.HTMLURL != "" {
+		return r.HTMLURL
+	}
+	return "https://github.com/k-sub1995/g/releases"
+}
+
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Updater checks for and installs releases.
+type Updater struct {
+	HTTPClient *http.Client
+	APIURL     string
+}
+
+// New creates an Updater for the g repository.
+func New() *Updater {
+	return &Updater{HTTPClient: http.DefaultClient, APIURL: DefaultAPIURL}
+}
+
+// Latest fetches the latest published release.
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	data, err := u.get(ctx, u.APIURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for releases: %w", err)
+	}
+	var rel Release
+	if err := json.Unmarshal(data, &rel); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	if rel.TagName == "" {
+		return nil, fmt.Errorf("no published release found")
+	}
+	return &rel, nil
+}
+
+// AssetName returns the archive name goreleaser produces for a platform.
+func AssetName(version, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("g_%s_%s_%s.%s", strings.TrimPrefix(version, "v"), goos, goarch, ext)
+}
+
+// Download fetches the release archive for this platform, verifies it
+// against the release checksums and their signature, and returns the
+// extracted binary.
+func (u *Updater) Download(ctx context.Context, rel *Release) ([]byte, error) {
+	if PublicKey == "" {
+		return nil, fmt.Errorf("this build of g has no release signing key and cannot verify releases; download %s from %s instead", rel.TagName, rel.page())
+	}
+	name := AssetName(rel.Version(), runtime.GOOS, runtime.GOARCH)
+	archive, ok := rel.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no build for %s/%s", rel.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	sumsAsset, ok := rel.asset(checksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install unverified binary", rel.TagName, checksumsAsset)
+	}
+
+	sums, err := u.get(ctx, sumsAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+	sigAsset, ok := rel.asset(signatureAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install unverified binary", rel.TagName, signatureAsset)
+	}
+	sig, err := u.get(ctx, sigAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifySignature(PublicKey, sums, sig); err != nil {
+		return nil, err
+	}
+	want, err := Checksum(sums, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := u.get(ctx, archive.URL)
+	if err != nil {
+		return nil, err
+	}
+	got := sha256.Sum256(data)
+	if hex.EncodeToString(got[:]) != want {
+		return nil, fmt.Errorf("checksum mismatch for %s", name)
+	}
+	return Extract(data, name)
+}
+
+func (u *Updater) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "g-upgrade")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && strings.HasPrefix(url, "https://api.github.com/") {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxAssetBytes))
+}
+
+// Checksum returns the SHA-256 recorded for name in a checksums.txt file.
+func Checksum(sums []byte, name string) (string, error) {
+	sc := bufio.NewScanner(bytes.NewReader(sums))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// VerifySignature checks a base64 ed25519 signature over data.
+func VerifySignature(publicKey string, data, sig []byte) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release public key")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("invalid release signature: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, raw) {
+		return fmt.Errorf("release signature verification failed")
+	}
+	return nil
+}
+
+// Extract returns the g binary from a release archive.
+func Extract(archive []byte, name string) ([]byte, error) {
+	if strings.HasSuffix(name, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if path.Base(f.Name) == "g.exe" {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(io.LimitReader(rc, maxAssetBytes))
+			}
+		}
+		return nil, fmt.Errorf("g.exe not found in %s", name)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("g binary not found in %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == "g" {
+			return io.ReadAll(io.LimitReader(tr, maxAssetBytes))
+		}
+	}
+}
+
+// Replace atomically swaps the executable at exe for binary. The old
+// binary is moved aside first so a running Windows executable can be
+// replaced too.
+func Replace(exe string, binary []byte) error {
+	exe, err := filepath.EvalSymlinks(exe)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	newPath := exe + ".new"
+	oldPath := exe + ".old"
+	if err := os.WriteFile(newPath, binary, info.Mode().Perm()|0100); err != nil {
+		return fmt.Errorf("cannot write next to %s (try running with sufficient permissions): %w", exe, err)
+	}
+	_ = os.Remove(oldPath)
+	if err := os.Rename(exe, oldPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	if err := os.Rename(newPath, exe); err != nil {
+		// Put the original back
+		_ = os.Rename(oldPath, exe)
+		return err
+	}
+	// Fails on Windows while the old binary is running; it is cleaned up
+	// on the next upgrade.
+	_ = os.Remove(oldPath)
+	return nil
+}
+
+// Newer reports whether version a is newer than b. Versions are dotted
+// numbers with an optional "v" prefix; pre-release suffixes sort before
+// the release.
+func Newer(a, b string) bool {
+	pa, sa := parseVersion(a)
+	pb, sb := parseVersion(b)
+	for i := 0; i < 3; i++ {
+		if pa[i] != pb[i] {
+			return pa[i] > pb[i]
+		}
+	}
+	// 1.2.0 is newer than 1.2.0-rc1
+	if sa == "" || sb == "" {
+		return sa == "" && sb != ""
+	}
+	return sa > sb
+}
+
+func parseVersion(v string) ([3]int, string) {
+	var nums [3]int
+	v = strings.TrimPrefix(v, "v")
+	v, pre, _ := strings.Cut(v, "-")
+	for i, part := range strings.SplitN(v, ".", 3) {
+		nums[i], _ = strconv.Atoi(part)
+	}
+	return nums, pre
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package upgrade
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v1.2.0", "1.1.9", true},
+		{"1.10.0", "1.9.0", true},
+		{"1.2.0", "1.2.0", false},
+		{"1.2.0", "1.2.0-rc1", true},
+		{"1.2.0-rc1", "1.2.0", false},
+		{"1.1.0", "v1.2.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.a, tt.b); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func tarball(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{"README.md", []byte("readme")}, {name, data}} {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0755, Size: int64(len(f.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(f.data)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestDownloadVerifies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("tar.gz assets only")
+	}
+	name := AssetName("1.3.0", runtime.GOOS, runtime.GOARCH)
+	archive := tarball(t, "g", []byte("new binary"))
+	sum := sha256.Sum256(archive)
+	sums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(sums)))
+
+	var srv *httptest.Server
+	files := map[string][]byte{
+		"/" + name:           archive,
+		"/checksums.txt":     []byte(sums),
+		"/checksums.txt.sig": []byte(sig),
+	}
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest" {
+			rel := Release{TagName: "v1.3.0"}
+			for n := range files {
+				rel.Assets = append(rel.Assets, Asset{Name: n[1:], URL: srv.URL + n})
+			}
+			json.NewEncoder(w).Encode(rel)
+			return
+		}
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	u := &Updater{HTTPClient: srv.Client(), APIURL: srv.URL + "/latest"}
+	rel, err := u.Latest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	PublicKey = base64.StdEncoding.EncodeToString(pub)
+	defer func() { PublicKey = "" }()
+	got, err := u.Download(context.Background(), rel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new binary" {
+		t.Errorf("Download = %q", got)
+	}
+
+	// A tampered archive must be rejected
+	files["/"+name] = tarball(t, "g", []byte("evil"))
+	if _, err := u.Download(context.Background(), rel); err == nil {
+		t.Error("expected checksum mismatch")
+	}
+
+	// As must checksums signed by another key
+	files["/"+name] = archive
+	other, _, _ := ed25519.GenerateKey(nil)
+	PublicKey = base64.StdEncoding.EncodeToString(other)
+	if _, err := u.Download(context.Background(), rel); err == nil {
+		t.Error("expected signature failure")
+	}
+
+	// A build without a key, or a release without a signature, installs
+	// nothing
+	PublicKey = ""
+	if _, err := u.Download(context.Background(), rel); err == nil {
+		t.Error("expected a build without a key to refuse")
+	}
+	PublicKey = base64.StdEncoding.EncodeToString(pub)
+	delete(files, "/checksums.txt.sig")
+	rel, _ = u.Latest(context.Background())
+	if _, err := u.Download(context.Background(), rel); err == nil {
+		t.Error("expected an unsigned release to be refused")
+	}
+}
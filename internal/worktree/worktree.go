@@ -0,0 +1,150 @@
+// Package worktree runs the agent in an isolated git worktree so that its
+// changes land on a separate branch and can be merged, kept or discarded.
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package worktree
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BranchPrefix is prepended to the names of branches created for runs.
+const BranchPrefix = "g/"
+
+// Worktree is a checkout of a new branch created for one agent run.
+type Worktree struct {
+	RepoDir string // top level of the original checkout
+	Path    string // top level of the worktree
+	Branch  string
+	Base    string // commit the branch starts from
+	Dirty   bool   // the original checkout had uncommitted changes, which are not carried over
+}
+
+// Create adds a worktree on a new branch starting at HEAD of the repository
+// containing dir. Worktrees are placed under the repository's git directory
+// so they never show up in the original checkout.
+func Create(dir string) (*Worktree, error) {
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("--worktree needs a git repository: %w", err)
+	}
+	base, err := git(top, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("--worktree needs at least one commit: %w", err)
+	}
+	commonDir, err := git(top, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(top, commonDir)
+	}
+	status, err := git(top, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	name := time.Now().Format("20060102-150405")
+	w := &Worktree{
+		RepoDir: top,
+		Path:    filepath.Join(commonDir, "g-worktrees", name),
+		Branch:  BranchPrefix + name,
+		Base:    base,
+		Dirty:   status != "",
+	}
+	if _, err := git(top, "worktree", "add", "-b", w.Branch, w.Path, base); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Dir returns the directory in the worktree that corresponds to dir in the
+// original checkout.
+func (w *Worktree) Dir(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	rel, err := filepath.Rel(w.RepoDir, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return w.Path
+	}
+	target := filepath.Join(w.Path, rel)
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return w.Path
+	}
+	return target
+}
+
+// Checkpoint commits every change in the worktree with message and reports
+// whether there was anything to commit.
+func (w *Worktree) Checkpoint(message string) (bool, error) {
+	status, err := git(w.Path, "status", "--porcelain")
+	if err != nil || status == "" {
+		return false, err
+	}
+	if _, err := git(w.Path, "add", "-A"); err != nil {
+		return false, err
+	}
+	if _, err := git(w.Path, "commit", "--quiet", "--no-verify", "-m", message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Commits returns the number of commits on the branch since Base.
+func (w *Worktree) Commits() (int, error) {
+	out, err := git(w.Path, "rev-list", "--count", w.Base+".."+w.Branch)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// DiffStat summarizes the branch's changes against Base.
+func (w *Worktree) DiffStat() (string, error) {
+	return git(w.Path, "diff", "--stat", w.Base, w.Branch)
+}
+
+// Merge merges the branch into the branch checked out in the original
+// checkout and then removes the worktree and branch. On failure, such as a
+// conflict, the merge is aborted and the branch is left in place.
+func (w *Worktree) Merge() error {
+	if _, err := git(w.RepoDir, "merge", "--no-ff", "--no-edit", w.Branch); err != nil {
+		_, _ = git(w.RepoDir, "merge", "--abort")
+		return err
+	}
+	return w.Remove(true)
+}
+
+// Remove deletes the worktree and, if deleteBranch is set, its branch.
+func (w *Worktree) Remove(deleteBranch bool) error {
+	if _, err := git(w.RepoDir, "worktree", "remove", "--force", w.Path); err != nil {
+		return err
+	}
+	if deleteBranch {
+		if _, err := git(w.RepoDir, "branch", "-D", w.Branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// git runs a git command in dir and returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
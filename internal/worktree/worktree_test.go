@@ -0,0 +1,91 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package worktree
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, kv := range [][2]string{
+		{"GIT_AUTHOR_NAME", "test"}, {"GIT_AUTHOR_EMAIL", "test@example.com"},
+		{"GIT_COMMITTER_NAME", "test"}, {"GIT_COMMITTER_EMAIL", "test@example.com"},
+	} {
+		t.Setenv(kv[0], kv[1])
+	}
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"commit", "-q", "-m", "init"}} {
+		if _, err := git(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCheckpointAndMerge(t *testing.T) {
+	repo := newRepo(t)
+	wt, err := Create(filepath.Join(repo, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := wt.Dir(filepath.Join(repo, "sub"))
+	if filepath.Base(sub) != "sub" {
+		t.Errorf("Dir = %s, want the worktree's sub directory", sub)
+	}
+
+	if ok, err := wt.Checkpoint("nothing"); err != nil || ok {
+		t.Fatalf("Checkpoint on clean tree = %v, %v", ok, err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "b.txt"), []byte("b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := wt.Checkpoint("add b"); err != nil || !ok {
+		t.Fatalf("Checkpoint = %v, %v", ok, err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "sub", "b.txt")); !os.IsNotExist(err) {
+		t.Error("change leaked into the original checkout before merge")
+	}
+	if n, err := wt.Commits(); err != nil || n != 1 {
+		t.Fatalf("Commits = %d, %v", n, err)
+	}
+
+	if err := wt.Merge(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "sub", "b.txt")); err != nil {
+		t.Errorf("merged file missing: %v", err)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Error("worktree not removed after merge")
+	}
+	if _, err := git(repo, "rev-parse", "--verify", wt.Branch); err == nil {
+		t.Error("branch not deleted after merge")
+	}
+}
+
+func TestRemoveKeepsBranch(t *testing.T) {
+	repo := newRepo(t)
+	wt, err := Create(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.Remove(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := git(repo, "rev-parse", "--verify", wt.Branch); err != nil {
+		t.Errorf("branch deleted: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package agent lets other Go programs embed g's agent loop with their own
+// tools. It is the supported public surface of g: its types are defined
+// here and stay stable while the internal ones they are translated to
+// change. Bring a Tool, a Formatter or a Prompter; providers come from the
+// constructors for the model APIs g supports.
+//
+//	registry := agent.NewRegistry(agent.RegistryOptions{WorkDir: dir})
+//	registry.Register(myTool)
+//	formatter, _ := agent.NewFormatter("text", os.Stdout, os.Stderr)
+//	loop := agent.NewLoop(agent.AnthropicFromEnv(), registry, formatter, agent.Config{MaxTurns: 20})
+//	err := loop.Run(ctx, agent.NewRequest("claude-sonnet-4-5", "Fix the build", registry, dir))
+//
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/k-sub1995/g/internal/agent"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/audit"
+	"github.com/k-sub1995/g/internal/history"
+	"github.com/k-sub1995/g/internal/prompt"
+	"github.com/k-sub1995/g/internal/redact"
+)
+
+// Config configures a Loop.
+type Config struct {
+	MaxTurns  int  // bounds the model calls of a run
+	Streaming bool // stream the answer as it is generated
+	Debug     bool // trace the loop on stderr
+
+	RedactSecrets bool   // mask API keys, tokens and private keys in tool results
+	AuditLog      string // file that records every tool execution; "" disables
+	HistoryTokens int    // shorten old tool results once the conversation outgrows this; 0 disables
+
+	// ToolOutputTokens bounds the tool results of one turn (0: the default)
+	ToolOutputTokens int
+
+	// Tools in ConfirmTools run only when approved: by AutoApprove, by
+	// AllowedTools, or by Prompter, which is asked on first use. Without a
+	// Prompter they are refused.
+	AutoApprove  bool
+	AllowedTools []string
+	ConfirmTools []string
+	Prompter     Prompter
+	PersistAllow func(tool string) error // records an AllowAlways answer
+}
+
+// Loop calls the model and runs the tools it asks for until it answers.
+type Loop struct {
+	loop     *agent.Loop
+	provider *Provider
+}
+
+// NewLoop returns a loop that runs the tools in registry for provider's
+// models and reports to formatter.
+func NewLoop(provider *Provider, registry *Registry, formatter Formatter, config Config) *Loop {
+	c := agent.Config{
+		MaxTurns:         config.MaxTurns,
+		Streaming:        config.Streaming,
+		Debug:            config.Debug,
+		ToolOutputTokens: config.ToolOutputTokens,
+		AutoApprove:      config.AutoApprove,
+		AllowedTools:     config.AllowedTools,
+		ConfirmTools:     config.ConfirmTools,
+		PersistAllow:     config.PersistAllow,
+	}
+	if config.RedactSecrets {
+		c.Redactor, _ = redact.New(nil) // the built-in patterns compile
+	}
+	if config.AuditLog != "" {
+		c.Audit = audit.Open(config.AuditLog, fmt.Sprintf("g-%d", time.Now().UnixNano()))
+	}
+	if config.HistoryTokens > 0 {
+		c.History = history.New(config.HistoryTokens)
+	}
+	if config.Prompter != nil {
+		c.Prompter = prompter{config.Prompter}
+	}
+	var p api.Provider
+	if provider != nil {
+		p = provider.provider
+	}
+	return &Loop{
+		loop:     agent.NewLoop(p, registry.registry, nil, formatterOf(formatter), c),
+		provider: provider,
+	}
+}
+
+// Run continues the conversation in req until the model answers or
+// MaxTurns is reached. The model's messages and the tool results are
+// added to req, so it can be continued with Add and run again.
+func (l *Loop) Run(ctx context.Context, req *Request) error {
+	if l.provider == nil {
+		return fmt.Errorf("no model provider")
+	}
+	req.req.Project = l.provider.project
+	return l.loop.Run(ctx, req.req)
+}
+
+// Summary is what a loop's runs did.
+type Summary struct {
+	Turns        int // model calls
+	ToolCalls    int
+	InputTokens  int
+	OutputTokens int
+	TotalTokens  int
+}
+
+// Summary adds up the loop's runs so far.
+func (l *Loop) Summary() Summary {
+	s := l.loop.Summary()
+	return Summary{
+		Turns:        s.Turns,
+		ToolCalls:    s.ToolCalls,
+		InputTokens:  s.Tokens.Input,
+		OutputTokens: s.Tokens.Output,
+		TotalTokens:  s.Tokens.Total,
+	}
+}
+
+// Request is a conversation with a model.
+type Request struct {
+	req *api.GenerateRequest
+}
+
+// NewRequest starts a conversation: g's system prompt for workDir, the
+// prompt as the user's message and the registry's tools.
+func NewRequest(model, text string, registry *Registry, workDir string) *Request {
+	return &Request{req: &api.GenerateRequest{
+		Model:        model,
+		UserPromptID: fmt.Sprintf("g-%d", time.Now().UnixNano()),
+		Request: api.InnerRequest{
+			SystemInstruction: prompt.BuildSystemInstruction(prompt.Options{WorkDir: workDir}),
+			Contents: []api.Content{{
+				Role:  "user",
+				Parts: []api.Part{{Text: text}},
+			}},
+			Config: api.GenerationConfig{
+				Temperature:     1.0,
+				TopP:            0.95,
+				MaxOutputTokens: api.Model(model).MaxOutputTokens,
+			},
+			Tools: []api.Tool{{FunctionDeclarations: registry.registry.AllDeclarations()}},
+		},
+	}}
+}
+
+// Add adds a user message for the next Run.
+func (r *Request) Add(text string) {
+	r.req.Request.Contents = append(r.req.Request.Contents, api.Content{
+		Role:  "user",
+		Parts: []api.Part{{Text: text}},
+	})
+}
+
+// Reply returns the text of the model's last message, or "" before it has
+// answered.
+func (r *Request) Reply() string {
+	contents := r.req.Request.Contents
+	for i := len(contents) - 1; i >= 0; i-- {
+		if contents[i].Role != "model" {
+			continue
+		}
+		var text strings.Builder
+		for _, p := range contents[i].Parts {
+			text.WriteString(p.Text)
+		}
+		return text.String()
+	}
+	return ""
+}
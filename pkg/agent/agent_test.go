@@ -0,0 +1,186 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/k-sub1995/g/pkg/agent"
+)
+
+// weatherTool is a tool an embedding program brings.
+type weatherTool struct{ calls int }
+
+func (w *weatherTool) Name() string { return "get_weather" }
+
+func (w *weatherTool) Declaration() agent.FunctionDecl {
+	return agent.FunctionDecl{
+		Name:        "get_weather",
+		Description: "Returns the weather in a city.",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+	}
+}
+
+func (w *weatherTool) Execute(ctx context.Context, args map[string]interface{}) (*agent.ToolResult, error) {
+	w.calls++
+	return &agent.ToolResult{Content: map[string]interface{}{"city": args["city"], "forecast": "sunny"}}, nil
+}
+
+// recorder is a Formatter that keeps what it is given.
+type recorder struct {
+	text  strings.Builder
+	calls []string
+}
+
+func (r *recorder) Text(text string) error { r.text.WriteString(text); return nil }
+func (r *recorder) End() error             { return nil }
+func (r *recorder) ToolCall(name string, args map[string]interface{}) error {
+	r.calls = append(r.calls, name)
+	return nil
+}
+func (r *recorder) ToolResult(name string, result map[string]interface{}, isError bool) error {
+	return nil
+}
+func (r *recorder) Progress(stage, detail string) error { return nil }
+func (r *recorder) Error(err error) error               { return nil }
+
+// approver approves every call once and keeps what it was asked.
+type approver struct{ asked []agent.ConfirmRequest }
+
+func (a *approver) Confirm(ctx context.Context, req agent.ConfirmRequest) (agent.Decision, error) {
+	a.asked = append(a.asked, req)
+	return agent.AllowOnce, nil
+}
+
+// claude is a Messages API that calls get_weather, then answers with what
+// it got back.
+func claude(t *testing.T, requests *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		*requests = append(*requests, body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{`{"type":"message_start","message":{"usage":{"input_tokens":10}}}`}
+		if len(*requests) == 1 {
+			events = append(events,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":\"Kyoto\"}"}}`,
+				`{"type":"content_block_stop","index":0}`,
+				`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":5}}`)
+		} else {
+			events = append(events,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"It is sunny."}}`,
+				`{"type":"content_block_stop","index":0}`,
+				`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`)
+		}
+		for _, ev := range append(events, `{"type":"message_stop"}`) {
+			fmt.Fprintf(w, "event: x\ndata: %s\n\n", ev)
+		}
+	}))
+}
+
+func TestEmbeddedLoop(t *testing.T) {
+	var requests []map[string]interface{}
+	srv := claude(t, &requests)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	registry := agent.NewRegistry(agent.RegistryOptions{WorkDir: dir, IncludeTools: []string{"read_file"}})
+	weather := &weatherTool{}
+	if !registry.Register(weather) {
+		t.Fatal("Register refused get_weather")
+	}
+
+	out := &recorder{}
+	prompter := &approver{}
+	loop := agent.NewLoop(agent.Anthropic("key", srv.URL), registry, out, agent.Config{
+		MaxTurns:     5,
+		Streaming:    true,
+		ConfirmTools: []string{"get_weather"},
+		Prompter:     prompter,
+	})
+	req := agent.NewRequest("claude-sonnet-4-5", "What's the weather in Kyoto?", registry, dir)
+	if err := loop.Run(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	var declared []string
+	for _, tool := range requests[0]["tools"].([]interface{}) {
+		declared = append(declared, tool.(map[string]interface{})["name"].(string))
+	}
+	if got := strings.Join(declared, ","); got != "read_file,get_weather" {
+		t.Errorf("declared tools = %s", got)
+	}
+	if weather.calls != 1 || strings.Join(out.calls, ",") != "get_weather" {
+		t.Errorf("get_weather ran %d times, reported %v", weather.calls, out.calls)
+	}
+	if len(prompter.asked) != 1 || prompter.asked[0].Tool != "get_weather" || prompter.asked[0].Args["city"] != "Kyoto" {
+		t.Errorf("asked %+v", prompter.asked)
+	}
+	if len(requests) != 2 || !strings.Contains(fmt.Sprint(requests[1]["messages"]), "sunny") {
+		t.Errorf("tool result not sent back: %v", requests)
+	}
+	if out.text.String() != "It is sunny." || req.Reply() != "It is sunny." {
+		t.Errorf("output = %q, reply = %q", out.text.String(), req.Reply())
+	}
+	if s := loop.Summary(); s.Turns != 2 || s.ToolCalls != 1 || s.OutputTokens != 10 {
+		t.Errorf("summary = %+v", s)
+	}
+}
+
+func TestEmbeddedLoopWithoutProvider(t *testing.T) {
+	registry := agent.NewRegistry(agent.RegistryOptions{WorkDir: t.TempDir()})
+	loop := agent.NewLoop(nil, registry, &recorder{}, agent.Config{MaxTurns: 1})
+	if err := loop.Run(context.Background(), agent.NewRequest("claude-sonnet-4-5", "hi", registry, t.TempDir())); err == nil {
+		t.Error("Run without a provider succeeded")
+	}
+}
+
+// weatherStub is a get_weather that returns what it is given.
+type weatherStub struct {
+	weatherTool
+	result *agent.ToolResult
+	err    error
+}
+
+func (w *weatherStub) Execute(ctx context.Context, args map[string]interface{}) (*agent.ToolResult, error) {
+	w.calls++
+	return w.result, w.err
+}
+
+func TestEmbeddedToolResults(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		tool *weatherStub
+		sent string
+	}{
+		{"nil result", &weatherStub{}, "content:{} tool_use_id"},
+		{"nil content", &weatherStub{result: &agent.ToolResult{}}, "content:{} tool_use_id"},
+		{"error", &weatherStub{err: fmt.Errorf("no station in Kyoto")}, "no station in Kyoto"},
+	} {
+		var requests []map[string]interface{}
+		srv := claude(t, &requests)
+		dir := t.TempDir()
+		registry := agent.NewRegistry(agent.RegistryOptions{WorkDir: dir, IncludeTools: []string{"read_file"}})
+		registry.Register(tt.tool)
+		loop := agent.NewLoop(agent.Anthropic("key", srv.URL), registry, &recorder{}, agent.Config{MaxTurns: 5, Streaming: true})
+		req := agent.NewRequest("claude-sonnet-4-5", "What's the weather in Kyoto?", registry, dir)
+
+		// The model gets the result and the run goes on to its answer
+		if err := loop.Run(context.Background(), req); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if len(requests) != 2 || !strings.Contains(fmt.Sprint(requests[1]["messages"]), tt.sent) || req.Reply() != "It is sunny." {
+			t.Errorf("%s: sent %v, reply %q", tt.name, requests, req.Reply())
+		}
+		srv.Close()
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"context"
+
+	"github.com/k-sub1995/g/internal/approval"
+)
+
+// Decision is the answer to a ConfirmRequest.
+type Decision int
+
+const (
+	Deny         Decision = iota
+	AllowOnce             // run this call
+	AllowSession          // run this tool for the rest of the loop's runs
+	AllowAlways           // as AllowSession, and call Config.PersistAllow
+)
+
+// ConfirmRequest describes a tool call awaiting approval.
+type ConfirmRequest struct {
+	Tool    string
+	Args    map[string]interface{}
+	Summary string     // one line describing what will run
+	Reason  string     // why approval is needed, e.g. a risky shell command
+	Options []Decision // the decisions that may be given besides Deny
+}
+
+// Prompter approves tool calls, e.g. by asking the user.
+type Prompter interface {
+	Confirm(ctx context.Context, req ConfirmRequest) (Decision, error)
+}
+
+var decisions = map[approval.Decision]Decision{
+	approval.Deny:         Deny,
+	approval.AllowOnce:    AllowOnce,
+	approval.AllowSession: AllowSession,
+	approval.AllowAlways:  AllowAlways,
+}
+
+// prompter asks a Prompter in place of g's terminal prompt. A decision
+// that was not offered denies the call.
+type prompter struct {
+	p Prompter
+}
+
+func (p prompter) Confirm(ctx context.Context, req approval.Request) (approval.Decision, error) {
+	offered := map[Decision]approval.Decision{}
+	r := ConfirmRequest{Tool: req.Tool, Args: req.Args, Summary: req.Summary, Reason: req.Reason}
+	for _, o := range req.Options {
+		if d, ok := decisions[o]; ok {
+			r.Options = append(r.Options, d)
+			offered[d] = o
+		}
+	}
+	d, err := p.p.Confirm(ctx, r)
+	if err != nil {
+		return approval.Deny, err
+	}
+	if o, ok := offered[d]; ok {
+		return o, nil
+	}
+	return approval.Deny, nil
+}
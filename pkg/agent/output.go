@@ -0,0 +1,113 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"io"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/output"
+)
+
+// Formatter receives a run's output: the model's answer as it arrives,
+// the tools it calls and what they return, and why the run waits or fails.
+type Formatter interface {
+	Text(text string) error // the next piece of the model's answer
+	End() error             // the model finished its answer
+	ToolCall(name string, args map[string]interface{}) error
+	ToolResult(name string, result map[string]interface{}, isError bool) error
+	Progress(stage, detail string) error // e.g. a rate-limit retry
+	Error(err error) error
+}
+
+// NewFormatter returns g's formatter for format ("text", "json" or
+// "stream-json"), which writes output to w and diagnostics to errW.
+func NewFormatter(format string, w, errW io.Writer) (Formatter, error) {
+	f, err := output.NewFormatter(format, w, errW, true)
+	if err != nil {
+		return nil, err
+	}
+	return builtinFormatter{f}, nil
+}
+
+// builtinFormatter is one of g's formatters.
+type builtinFormatter struct {
+	f output.Formatter
+}
+
+func (b builtinFormatter) Text(text string) error {
+	return b.f.WriteStreamEvent(&api.StreamEvent{Type: "content", Text: text})
+}
+
+func (b builtinFormatter) End() error {
+	return b.f.WriteStreamEvent(&api.StreamEvent{Type: "done"})
+}
+
+func (b builtinFormatter) ToolCall(name string, args map[string]interface{}) error {
+	return b.f.WriteToolCall(name, args)
+}
+
+func (b builtinFormatter) ToolResult(name string, result map[string]interface{}, isError bool) error {
+	return b.f.WriteToolResult(name, result, isError)
+}
+
+func (b builtinFormatter) Progress(stage, detail string) error {
+	return b.f.WriteProgress(stage, detail)
+}
+
+func (b builtinFormatter) Error(err error) error {
+	return b.f.WriteError(err)
+}
+
+// formatterOf returns the formatter the loop writes to: g's own unchanged,
+// so it sees everything the model sends, or f's methods behind g's.
+func formatterOf(f Formatter) output.Formatter {
+	if b, ok := f.(builtinFormatter); ok {
+		return b.f
+	}
+	return formatter{f}
+}
+
+// formatter reports to a Formatter an embedding program brings.
+type formatter struct {
+	f Formatter
+}
+
+func (f formatter) WriteResponse(resp *api.GenerateResponse) error {
+	if len(resp.Response.Candidates) > 0 {
+		for _, p := range resp.Response.Candidates[0].Content.Parts {
+			if p.Text != "" {
+				if err := f.f.Text(p.Text); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return f.f.End()
+}
+
+func (f formatter) WriteStreamEvent(event *api.StreamEvent) error {
+	switch {
+	case event.Type == "content" && event.Text != "":
+		return f.f.Text(event.Text)
+	case event.Type == "done":
+		return f.f.End()
+	}
+	return nil
+}
+
+func (f formatter) WriteError(err error) error {
+	return f.f.Error(err)
+}
+
+func (f formatter) WriteToolCall(name string, args map[string]interface{}) error {
+	return f.f.ToolCall(name, args)
+}
+
+func (f formatter) WriteToolResult(name string, result map[string]interface{}, isError bool) error {
+	return f.f.ToolResult(name, result, isError)
+}
+
+func (f formatter) WriteProgress(stage, detail string) error {
+	return f.f.Progress(stage, detail)
+}
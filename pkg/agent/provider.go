@@ -0,0 +1,69 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/k-sub1995/g/internal/anthropic"
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/auth"
+)
+
+// Provider sends a loop's requests to a model API.
+type Provider struct {
+	provider api.Provider
+	project  string // the Code Assist project of Gemini requests
+}
+
+// Anthropic returns a provider for Claude models that authenticates with
+// apiKey. baseURL replaces the Anthropic API endpoint when set.
+func Anthropic(apiKey, baseURL string) *Provider {
+	if baseURL == "" {
+		baseURL = anthropic.DefaultBaseURL
+	}
+	return &Provider{provider: &anthropic.Client{HTTPClient: http.DefaultClient, BaseURL: baseURL, APIKey: apiKey}}
+}
+
+// AnthropicFromEnv returns a provider for Claude models configured from
+// ANTHROPIC_API_KEY and ANTHROPIC_BASE_URL, or nil when no key is set.
+func AnthropicFromEnv() *Provider {
+	if c := anthropic.NewFromEnv(); c != nil {
+		return &Provider{provider: c}
+	}
+	return nil
+}
+
+// Gemini returns a provider for Gemini models that signs in with the
+// Gemini CLI's login in ~/.gemini, refreshing its token in the background
+// for as long as the process runs. Requests go to project, or to the Code
+// Assist project of the account when it is empty.
+func Gemini(ctx context.Context, project string) (*Provider, error) {
+	m, err := auth.NewManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize auth: %w", err)
+	}
+	creds, err := m.LoadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if creds.IsExpired() {
+		if creds, err = m.RefreshToken(creds); err != nil {
+			return nil, err
+		}
+	}
+	c := api.NewClient(m.RefreshingHTTPClient(creds))
+	if project == "" {
+		resp, err := c.LoadCodeAssist(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Code Assist: %w", err)
+		}
+		if resp.CloudAICompanionProject == "" {
+			return nil, fmt.Errorf("unable to use Gemini: no project ID available. Please run 'gemini' to set up your account")
+		}
+		project = resp.CloudAICompanionProject
+	}
+	return &Provider{provider: c, project: project}, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 k-sub1995
+// SPDX-License-Identifier: Apache-2.0
+package agent
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/k-sub1995/g/internal/api"
+	"github.com/k-sub1995/g/internal/tools"
+)
+
+// Tool is implemented by every tool the model can call. Declaration
+// describes its parameters as JSON Schema; Execute returns the result the
+// model sees. An error is sent to the model as the call's result, as
+// {"error": message}, and the run goes on; a nil result is sent as an
+// empty one.
+type Tool interface {
+	Name() string
+	Declaration() FunctionDecl
+	Execute(ctx context.Context, args map[string]interface{}) (*ToolResult, error)
+}
+
+// FunctionDecl declares a tool to the model.
+type FunctionDecl struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage // JSON Schema of the arguments
+}
+
+// ToolResult is what a tool returns: Content goes back to the model, and
+// IsError marks a failure the model should react to.
+type ToolResult struct {
+	Content map[string]interface{}
+	IsError bool
+}
+
+// RegistryOptions selects and configures the built-in tools.
+type RegistryOptions struct {
+	WorkDir  string // where file tools and shell commands run
+	Sandbox  bool   // restrict file writes to WorkDir
+	ReadOnly bool   // register only tools that read the workspace
+	Debug    bool
+
+	// AllowedHosts, when set, limits the network tools to these hosts and
+	// their subdomains; NoNetwork removes them.
+	AllowedHosts []string
+	NoNetwork    bool
+
+	IncludeTools []string // when set, register only these built-in tools
+	ExcludeTools []string // built-in tools to leave out (wins over IncludeTools)
+}
+
+// Registry holds the tools of one run: the built-in ones selected by
+// RegistryOptions plus those added with Register.
+type Registry struct {
+	registry *tools.Registry
+}
+
+// NewRegistry returns a registry with the built-in tools opts selects.
+func NewRegistry(opts RegistryOptions) *Registry {
+	return &Registry{registry: tools.NewRegistry(tools.RegistryOptions{
+		WorkDir:  opts.WorkDir,
+		Sandbox:  opts.Sandbox,
+		ReadOnly: opts.ReadOnly,
+		Debug:    opts.Debug,
+		Network: tools.NetworkPolicy{
+			Disabled:     opts.NoNetwork,
+			AllowedHosts: opts.AllowedHosts,
+		},
+		IncludeTools: opts.IncludeTools,
+		ExcludeTools: opts.ExcludeTools,
+	})}
+}
+
+// Register adds a tool. Built-in tools take precedence: registering a name
+// that already exists returns false and leaves the registry unchanged.
+func (r *Registry) Register(t Tool) bool {
+	return r.registry.Register(tool{t})
+}
+
+// Declarations returns the declarations of the registry's tools, as the
+// model is offered them.
+func (r *Registry) Declarations() []FunctionDecl {
+	var decls []FunctionDecl
+	for _, d := range r.registry.AllDeclarations() {
+		decls = append(decls, FunctionDecl{Name: d.Name, Description: d.Description, Parameters: d.Parameters})
+	}
+	return decls
+}
+
+// tool runs a Tool as one of g's own.
+type tool struct {
+	t Tool
+}
+
+func (t tool) Name() string { return t.t.Name() }
+
+func (t tool) Declaration() api.FunctionDecl {
+	d := t.t.Declaration()
+	return api.FunctionDecl{Name: d.Name, Description: d.Description, Parameters: d.Parameters}
+}
+
+func (t tool) Execute(ctx context.Context, args map[string]interface{}) (*tools.ToolResult, error) {
+	result, err := t.t.Execute(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &ToolResult{}
+	}
+	content := result.Content
+	if content == nil {
+		content = map[string]interface{}{}
+	}
+	return &tools.ToolResult{Content: content, IsError: result.IsError}, nil
+}